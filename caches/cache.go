@@ -57,6 +57,19 @@ type Cacher interface {
 	ClearBeans(tableName string)
 }
 
+// CacheStats represents the statistics of a cacher
+type CacheStats struct {
+	Hits   uint64 // the number of the lookups which found the value
+	Misses uint64 // the number of the lookups which did not find the value
+	Ids    int    // the number of the cached sql-ids mappings
+	Beans  int    // the number of the cached beans
+}
+
+// StatsCacher represents a cacher which could report its statistics
+type StatsCacher interface {
+	Stats() CacheStats
+}
+
 func encodeIds(ids []schemas.PK) (string, error) {
 	buf := new(bytes.Buffer)
 	enc := gob.NewEncoder(buf)
@@ -93,7 +106,12 @@ func PutCacheSql(m Cacher, ids []schemas.PK, tableName, sql string, args interfa
 	return nil
 }
 
-// GenSqlKey generates cache key
+// GenSqlKey generates cache key, the SQL is length prefixed and the arguments are encoded as
+// the primary keys so that the keys of the different SQLs and arguments never collide
 func GenSqlKey(sql string, args interface{}) string {
-	return fmt.Sprintf("%v-%v", sql, args)
+	if params, ok := args.([]interface{}); ok {
+		pk := schemas.PK(params)
+		return fmt.Sprintf("%d:%s%s", len(sql), sql, pk.Key())
+	}
+	return fmt.Sprintf("%d:%s%v", len(sql), sql, args)
 }
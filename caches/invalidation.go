@@ -0,0 +1,96 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// InvalidationEvent represents the cache entries of a table which are changed by an instance,
+// the sql-ids mappings of the table are always invalidated
+type InvalidationEvent struct {
+	Origin string   // the instance which publishes the event, it's set by the manager
+	Table  string   // the table name
+	IDs    []string // the Key() of the primary keys of the changed beans
+	All    bool     // all the beans of the table are invalidated
+}
+
+// InvalidationBroker delivers the invalidation events between the instances, i.e. by Redis
+// pub/sub or PostgreSQL LISTEN/NOTIFY. An event published by an instance may be delivered
+// to itself, the manager ignores it. Subscribe returns a function which unsubscribes the handler.
+type InvalidationBroker interface {
+	Publish(event InvalidationEvent) error
+	Subscribe(handler func(event InvalidationEvent)) (unsubscribe func(), err error)
+}
+
+// SetInvalidationBroker publishes the invalidation events of this manager to the broker and
+// applies the events of the other instances to the local cachers, so that the local cachers
+// of several instances stay consistent. The previous broker is unsubscribed, and a nil broker
+// stops publishing and applying the events.
+func (mgr *Manager) SetInvalidationBroker(broker InvalidationBroker) error {
+	mgr.brokerLock.Lock()
+	defer mgr.brokerLock.Unlock()
+
+	mgr.cacherLock.Lock()
+	unsubscribe := mgr.unsubscribe
+	mgr.broker, mgr.origin, mgr.unsubscribe = nil, "", nil
+	mgr.cacherLock.Unlock()
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+	if broker == nil {
+		return nil
+	}
+
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return err
+	}
+	origin := hex.EncodeToString(b[:])
+	unsubscribe, err := broker.Subscribe(func(event InvalidationEvent) {
+		if event.Origin != origin {
+			mgr.applyInvalidation(event)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	mgr.cacherLock.Lock()
+	mgr.broker = broker
+	mgr.origin = origin
+	mgr.unsubscribe = unsubscribe
+	mgr.cacherLock.Unlock()
+	return nil
+}
+
+// PublishInvalidation publishes the invalidation event to the other instances if there is an
+// invalidation broker, the local cachers should be invalidated by the caller
+func (mgr *Manager) PublishInvalidation(event InvalidationEvent) error {
+	mgr.cacherLock.RLock()
+	broker, origin := mgr.broker, mgr.origin
+	mgr.cacherLock.RUnlock()
+	if broker == nil {
+		return nil
+	}
+	event.Origin = origin
+	return broker.Publish(event)
+}
+
+func (mgr *Manager) applyInvalidation(event InvalidationEvent) {
+	cacher := mgr.GetCacher(event.Table)
+	if cacher == nil {
+		return
+	}
+	cacher.ClearIds(event.Table)
+	if event.All {
+		cacher.ClearBeans(event.Table)
+		return
+	}
+	for _, id := range event.IDs {
+		cacher.DelBean(event.Table, id)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryBroker struct {
+	mutex    sync.Mutex
+	seq      int
+	handlers map[int]func(InvalidationEvent)
+}
+
+func (b *memoryBroker) Publish(event InvalidationEvent) error {
+	b.mutex.Lock()
+	handlers := make([]func(InvalidationEvent), 0, len(b.handlers))
+	for _, handler := range b.handlers {
+		handlers = append(handlers, handler)
+	}
+	b.mutex.Unlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(handler func(InvalidationEvent)) (func(), error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.handlers == nil {
+		b.handlers = make(map[int]func(InvalidationEvent))
+	}
+	b.seq++
+	id := b.seq
+	b.handlers[id] = handler
+	return func() {
+		b.mutex.Lock()
+		delete(b.handlers, id)
+		b.mutex.Unlock()
+	}, nil
+}
+
+func (b *memoryBroker) subscribers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.handlers)
+}
+
+func TestInvalidationBroker(t *testing.T) {
+	broker := new(memoryBroker)
+	newManager := func() (*Manager, Cacher) {
+		mgr := NewManager()
+		cacher := NewLRUCacher2(NewMemoryStore(), time.Hour, 100)
+		mgr.SetDefaultCacher(cacher)
+		assert.NoError(t, mgr.SetInvalidationBroker(broker))
+		return mgr, cacher
+	}
+	mgr1, cacher1 := newManager()
+	mgr2, cacher2 := newManager()
+	assert.EqualValues(t, 2, broker.subscribers())
+
+	// setting the broker again doesn't subscribe twice
+	assert.NoError(t, mgr2.SetInvalidationBroker(broker))
+	assert.EqualValues(t, 2, broker.subscribers())
+
+	for _, cacher := range []Cacher{cacher1, cacher2} {
+		cacher.PutIds("user", "SELECT id FROM user", "ids")
+		// the beans are looked up before they are put
+		assert.Nil(t, cacher.GetBean("user", "1"))
+		cacher.PutBean("user", "1", "bean1")
+		cacher.PutBean("user", "2", "bean2")
+	}
+
+	// the manager ignores its own events
+	assert.NoError(t, mgr1.PublishInvalidation(InvalidationEvent{Table: "user", IDs: []string{"1"}}))
+	assert.NotNil(t, cacher1.GetIds("user", "SELECT id FROM user"))
+	assert.NotNil(t, cacher1.GetBean("user", "1"))
+
+	assert.Nil(t, cacher2.GetIds("user", "SELECT id FROM user"))
+	assert.Nil(t, cacher2.GetBean("user", "1"))
+	assert.NotNil(t, cacher2.GetBean("user", "2"))
+
+	assert.NoError(t, mgr1.PublishInvalidation(InvalidationEvent{Table: "user", All: true}))
+	assert.Nil(t, cacher2.GetBean("user", "2"))
+	assert.NotNil(t, cacher1.GetBean("user", "2"))
+
+	assert.NoError(t, mgr1.SetInvalidationBroker(nil))
+	assert.EqualValues(t, 1, broker.subscribers())
+	cacher2.PutBean("user", "2", "bean2")
+	assert.NoError(t, mgr1.PublishInvalidation(InvalidationEvent{Table: "user", All: true}))
+	assert.NotNil(t, cacher2.GetBean("user", "2"))
+
+	// the unsubscribed manager doesn't apply the events of the others
+	cacher1.PutBean("user", "2", "bean2")
+	assert.NoError(t, mgr2.PublishInvalidation(InvalidationEvent{Table: "user", All: true}))
+	assert.NotNil(t, cacher1.GetBean("user", "2"))
+}
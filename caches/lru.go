@@ -22,6 +22,9 @@ type LRUCacher struct {
 	MaxElementSize int
 	Expired        time.Duration
 	GcInterval     time.Duration
+
+	hits   uint64
+	misses uint64
 }
 
 // NewLRUCacher creates a cacher
@@ -98,15 +101,18 @@ func (m *LRUCacher) GetIds(tableName, sql string) interface{} {
 			// if expired, remove the node and return nil
 			if time.Since(lastTime) > m.Expired {
 				m.delIds(tableName, sql)
+				m.misses++
 				return nil
 			}
 			m.sqlList.MoveToBack(el)
 			el.Value.(*sqlNode).lastVisit = time.Now()
 		}
+		m.hits++
 		return v
 	}
 
 	m.delIds(tableName, sql)
+	m.misses++
 	return nil
 }
 
@@ -124,6 +130,7 @@ func (m *LRUCacher) GetBean(tableName string, id string) interface{} {
 			// if expired, remove the node and return nil
 			if time.Since(lastTime) > m.Expired {
 				m.delBean(tableName, id)
+				m.misses++
 				return nil
 			}
 			m.idList.MoveToBack(el)
@@ -132,14 +139,28 @@ func (m *LRUCacher) GetBean(tableName string, id string) interface{} {
 			el = m.idList.PushBack(newIDNode(tableName, id))
 			m.idIndex[tableName][id] = el
 		}
+		m.hits++
 		return v
 	}
 
 	// store bean is not exist, then remove memory's index
 	m.delBean(tableName, id)
+	m.misses++
 	return nil
 }
 
+// Stats returns the hits, misses and the number of the cached elements
+func (m *LRUCacher) Stats() CacheStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return CacheStats{
+		Hits:   m.hits,
+		Misses: m.misses,
+		Ids:    m.sqlList.Len(),
+		Beans:  m.idList.Len(),
+	}
+}
+
 // clearIds clears all sql-ids mapping on table tableName from cache
 func (m *LRUCacher) clearIds(tableName string) {
 	if tis, ok := m.sqlIndex[tableName]; ok {
@@ -49,4 +49,10 @@ func TestLRUCache(t *testing.T) {
 		obj4 := cacher.GetBean(tableName, sid)
 		assert.Nil(t, obj4)
 	}
+
+	stats := cacher.Stats()
+	assert.EqualValues(t, 4, stats.Hits)
+	assert.EqualValues(t, 6, stats.Misses)
+	assert.EqualValues(t, 0, stats.Ids)
+	assert.EqualValues(t, 0, stats.Beans)
 }
@@ -13,6 +13,11 @@ type Manager struct {
 
 	cachers    map[string]Cacher
 	cacherLock sync.RWMutex
+
+	brokerLock  sync.Mutex
+	broker      InvalidationBroker
+	origin      string
+	unsubscribe func()
 }
 
 // NewManager creates a cache manager
@@ -58,3 +63,14 @@ func (mgr *Manager) SetDefaultCacher(cacher Cacher) {
 func (mgr *Manager) GetDefaultCacher() Cacher {
 	return mgr.cacher
 }
+
+// Cachers returns the cachers of the tables set by SetCacher
+func (mgr *Manager) Cachers() map[string]Cacher {
+	mgr.cacherLock.RLock()
+	defer mgr.cacherLock.RUnlock()
+	cachers := make(map[string]Cacher, len(mgr.cachers))
+	for tableName, cacher := range mgr.cachers {
+		cachers[tableName] = cacher
+	}
+	return cachers
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BitSet represents a bit string with a fixed length, the bit 0 is the leftmost bit
+// like the bit strings of PostgreSQL and the most significant bit of BIT(n) of MySQL.
+type BitSet struct {
+	bits []byte
+	n    int
+}
+
+// NewBitSet creates a bit set of n bits which are all 0
+func NewBitSet(n int) BitSet {
+	if n < 0 {
+		n = 0
+	}
+	return BitSet{bits: make([]byte, (n+7)/8), n: n}
+}
+
+// ParseBitSet parses a bit string like "0101"
+func ParseBitSet(s string) (BitSet, error) {
+	b := NewBitSet(len(s))
+	for i, c := range s {
+		switch c {
+		case '1':
+			b.Set(i)
+		case '0':
+		default:
+			return BitSet{}, fmt.Errorf("invalid bit string %q", s)
+		}
+	}
+	return b, nil
+}
+
+// BitSetFromBytes creates a bit set of n bits from the bytes, the bits are aligned to the
+// left of the bytes
+func BitSetFromBytes(bs []byte, n int) (BitSet, error) {
+	if len(bs) != (n+7)/8 {
+		return BitSet{}, fmt.Errorf("%d bytes could not be a bit set of %d bits", len(bs), n)
+	}
+	b := NewBitSet(n)
+	copy(b.bits, bs)
+	if n%8 != 0 {
+		b.bits[len(b.bits)-1] &= 0xff << (8 - n%8)
+	}
+	return b, nil
+}
+
+// BitSetFromUint64 creates a bit set of n bits from the value, the bit 0 is the most
+// significant bit of the n bits
+func BitSetFromUint64(v uint64, n int) BitSet {
+	b := NewBitSet(n)
+	for i := 0; i < n && i < 64; i++ {
+		if v&(1<<uint(i)) != 0 {
+			b.Set(n - 1 - i)
+		}
+	}
+	return b
+}
+
+// Len returns the number of the bits
+func (b BitSet) Len() int {
+	return b.n
+}
+
+// Test returns true if the bit i is 1
+func (b BitSet) Test(i int) bool {
+	if i < 0 || i >= b.n {
+		return false
+	}
+	return b.bits[i/8]&(0x80>>uint(i%8)) != 0
+}
+
+// Set sets the bit i to 1, it panics if i is out of range
+func (b *BitSet) Set(i int) {
+	if i < 0 || i >= b.n {
+		panic(fmt.Sprintf("bit %d is out of range [0, %d)", i, b.n))
+	}
+	b.bits[i/8] |= 0x80 >> uint(i%8)
+}
+
+// Clear sets the bit i to 0, it panics if i is out of range
+func (b *BitSet) Clear(i int) {
+	if i < 0 || i >= b.n {
+		panic(fmt.Sprintf("bit %d is out of range [0, %d)", i, b.n))
+	}
+	b.bits[i/8] &^= 0x80 >> uint(i%8)
+}
+
+// Bytes returns a copy of the bits which are aligned to the left of the bytes
+func (b BitSet) Bytes() []byte {
+	res := make([]byte, len(b.bits))
+	copy(res, b.bits)
+	return res
+}
+
+// Uint64 returns the bits as an integer, the bit 0 is the most significant bit.
+// Only the first 64 bits are returned if there are more than 64 bits.
+func (b BitSet) Uint64() uint64 {
+	var v uint64
+	for i := 0; i < b.n && i < 64; i++ {
+		v <<= 1
+		if b.Test(i) {
+			v |= 1
+		}
+	}
+	return v
+}
+
+// String returns the bit string like "0101"
+func (b BitSet) String() string {
+	var sb strings.Builder
+	sb.Grow(b.n)
+	for i := 0; i < b.n; i++ {
+		if b.Test(i) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitSet(t *testing.T) {
+	b, err := ParseBitSet("1010000001")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, b.Len())
+	assert.True(t, b.Test(0))
+	assert.False(t, b.Test(1))
+	assert.True(t, b.Test(9))
+	assert.False(t, b.Test(10))
+	assert.EqualValues(t, 0x281, b.Uint64())
+	assert.EqualValues(t, []byte{0xa0, 0x40}, b.Bytes())
+	assert.EqualValues(t, "1010000001", b.String())
+
+	assert.EqualValues(t, b, BitSetFromUint64(0x281, 10))
+
+	b2, err := BitSetFromBytes([]byte{0xa0, 0x7f}, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, b, b2)
+
+	b2.Clear(0)
+	b2.Set(1)
+	assert.EqualValues(t, "0110000001", b2.String())
+	assert.Panics(t, func() { b2.Set(10) })
+
+	_, err = BitSetFromBytes([]byte{0xa0}, 10)
+	assert.Error(t, err)
+	_, err = ParseBitSet("102")
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal represents an arbitrary-precision fixed-point decimal number which
+// could be used to map NUMERIC/DECIMAL columns without losing precision.
+// The zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns a decimal equals to unscaled * 10^-scale
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// ParseDecimal parses a decimal from a string like "-123.4500" or "1.5E+3"
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		str = str[:i]
+	}
+
+	var scale int64
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		scale = int64(len(str) - i - 1)
+		str = str[:i] + str[i+1:]
+	}
+	if str == "" || str == "-" || str == "+" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale -= exp
+	if scale < 0 {
+		unscaled.Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(-scale), nil))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: int32(scale)}, nil
+}
+
+// AsDecimal converts interface as Decimal
+func AsDecimal(src interface{}) (Decimal, error) {
+	switch v := src.(type) {
+	case nil:
+		return Decimal{}, nil
+	case Decimal:
+		return v, nil
+	case *Decimal:
+		return *v, nil
+	case []byte:
+		return ParseDecimal(string(v))
+	case string:
+		return ParseDecimal(v)
+	case float32:
+		return ParseDecimal(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		return ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case *big.Float:
+		return ParseDecimal(v.Text('f', -1))
+	}
+
+	i, err := AsInt64(src)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("unsupported value %T as Decimal", src)
+	}
+	return NewDecimal(i, 0), nil
+}
+
+func (d Decimal) unscaledValue() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale returns the unscaled value of the decimal with a greater scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.unscaledValue()
+	}
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-d.scale)), nil)
+	return factor.Mul(factor, d.unscaledValue())
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// IsZero returns true if the decimal equals to 0
+func (d Decimal) IsZero() bool {
+	return d.unscaledValue().Sign() == 0
+}
+
+// Add returns d + d2
+func (d Decimal) Add(d2 Decimal) Decimal {
+	scale := d.scale
+	if d2.scale > scale {
+		scale = d2.scale
+	}
+	return Decimal{
+		unscaled: new(big.Int).Add(d.rescale(scale), d2.rescale(scale)),
+		scale:    scale,
+	}
+}
+
+// Cmp compares d and d2 and returns -1, 0 or +1
+func (d Decimal) Cmp(d2 Decimal) int {
+	scale := d.scale
+	if d2.scale > scale {
+		scale = d2.scale
+	}
+	return d.rescale(scale).Cmp(d2.rescale(scale))
+}
+
+// Float64 returns the nearest float64 value of the decimal
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the decimal as a string with exactly Scale() fractional digits, the integer
+// is padded with -Scale() zeros if the scale is negative
+func (d Decimal) String() string {
+	unscaled := d.unscaledValue()
+	str := new(big.Int).Abs(unscaled).String()
+	if d.scale < 0 && unscaled.Sign() != 0 {
+		str += strings.Repeat("0", -int(d.scale))
+	} else if d.scale > 0 {
+		if len(str) <= int(d.scale) {
+			str = strings.Repeat("0", int(d.scale)-len(str)+1) + str
+		}
+		str = str[:len(str)-int(d.scale)] + "." + str[len(str)-int(d.scale):]
+	}
+	if unscaled.Sign() < 0 {
+		return "-" + str
+	}
+	return str
+}
+
+// Scan implements sql.Scanner
+func (d *Decimal) Scan(src interface{}) error {
+	v, err := AsDecimal(src)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements driver.Valuer
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := map[string]string{
+		"0":                        "0",
+		"12.3400":                  "12.3400",
+		"-0.05":                    "-0.05",
+		".5":                       "0.5",
+		"+7":                       "7",
+		"1.5E+3":                   "1500",
+		"1.5e-3":                   "0.0015",
+		"123456789012345678901.23": "123456789012345678901.23",
+	}
+	for s, expected := range cases {
+		d, err := ParseDecimal(s)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, d.String())
+	}
+
+	for _, s := range []string{"", "-", "1.2.3", "abc", "1e"} {
+		_, err := ParseDecimal(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a, err := ParseDecimal("0.1")
+	assert.NoError(t, err)
+	b, err := ParseDecimal("0.20")
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, "0.30", a.Add(b).String())
+	assert.EqualValues(t, -1, a.Cmp(b))
+	assert.EqualValues(t, 0, NewDecimal(30, 2).Cmp(a.Add(b)))
+	assert.True(t, Decimal{}.IsZero())
+	assert.EqualValues(t, "0", Decimal{}.String())
+	assert.EqualValues(t, "1200", NewDecimal(12, -2).String())
+	assert.EqualValues(t, "-1200", NewDecimal(-12, -2).String())
+	assert.EqualValues(t, "0", NewDecimal(0, -2).String())
+	assert.EqualValues(t, 1200.0, NewDecimal(12, -2).Float64())
+	assert.EqualValues(t, "1200.5", NewDecimal(12, -2).Add(NewDecimal(5, 1)).String())
+
+	var d Decimal
+	assert.NoError(t, d.Scan([]byte("99.99")))
+	assert.EqualValues(t, "99.99", d.String())
+	assert.NoError(t, d.Scan(int64(3)))
+	assert.EqualValues(t, "3", d.String())
+	assert.NoError(t, d.Scan(nil))
+	assert.True(t, d.IsZero())
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the lengths of the interval units of PostgreSQL, a month is 30 days and a year is 365.25 days
+var intervalUnits = map[string]time.Duration{
+	"year":        time.Hour * 24 * 36525 / 100,
+	"mon":         time.Hour * 24 * 30,
+	"month":       time.Hour * 24 * 30,
+	"day":         time.Hour * 24,
+	"hour":        time.Hour,
+	"min":         time.Minute,
+	"minute":      time.Minute,
+	"sec":         time.Second,
+	"second":      time.Second,
+	"millisecond": time.Millisecond,
+	"microsecond": time.Microsecond,
+}
+
+// ParseInterval parses the interval of PostgreSQL like "1 day 02:03:04.5" or the TIME of
+// MySQL like "-838:59:59.000000" as a duration
+func ParseInterval(s string) (time.Duration, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+
+	var res time.Duration
+	for i := 0; i < len(fields); i++ {
+		if strings.Contains(fields[i], ":") {
+			d, err := parseClock(fields[i])
+			if err != nil {
+				return 0, fmt.Errorf("invalid interval %q: %w", s, err)
+			}
+			res += d
+			continue
+		}
+
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("invalid interval %q", s)
+		}
+		unit, ok := intervalUnits[strings.TrimSuffix(strings.ToLower(fields[i+1]), "s")]
+		if !ok {
+			return 0, fmt.Errorf("invalid interval %q: unknown unit %s", s, fields[i+1])
+		}
+		n, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q: %w", s, err)
+		}
+		res += time.Duration(n * float64(unit))
+		i++
+	}
+	return res, nil
+}
+
+// parseClock parses the duration like [-]HH:MM:SS[.ffffff]
+func parseClock(s string) (time.Duration, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	var frac string
+	if idx := strings.Index(parts[len(parts)-1], "."); idx > -1 {
+		frac = parts[len(parts)-1][idx+1:]
+		parts[len(parts)-1] = parts[len(parts)-1][:idx]
+	}
+
+	var res time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second}[:len(parts)] {
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		res += time.Duration(n) * unit
+	}
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		n, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		res += time.Duration(n)
+	}
+	if neg {
+		res = -res
+	}
+	return res, nil
+}
+
+func parseDuration(s string, unit time.Duration) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(n) * unit, nil
+	}
+	return ParseInterval(s)
+}
+
+// AsDuration converts interface as time.Duration, the integers are multiplied by the unit
+// and the strings could be the integers, the intervals of PostgreSQL or the TIME of MySQL
+func AsDuration(src interface{}, unit time.Duration) (time.Duration, error) {
+	if unit <= 0 {
+		unit = time.Nanosecond
+	}
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return v, nil
+	case string:
+		return parseDuration(v, unit)
+	case []byte:
+		return parseDuration(string(v), unit)
+	case *sql.RawBytes:
+		return parseDuration(string(*v), unit)
+	case *sql.NullString:
+		return parseDuration(v.String, unit)
+	}
+
+	n, err := AsInt64(src)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInterval(t *testing.T) {
+	kases := []struct {
+		interval string
+		expected time.Duration
+	}{
+		{"00:00:01", time.Second},
+		{"26:03:04.5", 26*time.Hour + 3*time.Minute + 4*time.Second + 500*time.Millisecond},
+		{"-838:59:59.000000", -(838*time.Hour + 59*time.Minute + 59*time.Second)},
+		{"1 day 02:00:00", 26 * time.Hour},
+		{"-1 days -02:00:00", -26 * time.Hour},
+		{"1 mon 2 days", 32 * 24 * time.Hour},
+		{"3 hours 30 mins", 3*time.Hour + 30*time.Minute},
+	}
+	for _, kase := range kases {
+		d, err := ParseInterval(kase.interval)
+		assert.NoError(t, err, kase.interval)
+		assert.EqualValues(t, kase.expected, d, kase.interval)
+	}
+
+	_, err := ParseInterval("1 fortnight")
+	assert.Error(t, err)
+	_, err = ParseInterval("1:a:3")
+	assert.Error(t, err)
+}
+
+func TestAsDuration(t *testing.T) {
+	d, err := AsDuration(int64(1500), time.Millisecond)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1500*time.Millisecond, d)
+
+	d, err = AsDuration(&sql.NullString{String: "42", Valid: true}, time.Second)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 42*time.Second, d)
+
+	d, err = AsDuration([]byte("01:00:00"), 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, time.Hour, d)
+}
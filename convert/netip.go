@@ -0,0 +1,62 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// AsAddr converts interface as netip.Addr, the address of a prefix like
+// 192.168.0.1/24 which is returned by the inet type of PostgreSQL is also accepted
+func AsAddr(src interface{}) (netip.Addr, error) {
+	switch v := src.(type) {
+	case nil:
+		return netip.Addr{}, nil
+	case netip.Addr:
+		return v, nil
+	case *netip.Addr:
+		return *v, nil
+	}
+
+	s := strings.TrimSpace(AsString(src))
+	if s == "" {
+		return netip.Addr{}, nil
+	}
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		return prefix.Addr(), nil
+	}
+	return netip.ParseAddr(s)
+}
+
+// AsPrefix converts interface as netip.Prefix, an address without the prefix length
+// is converted as a single address prefix like 192.168.0.1/32
+func AsPrefix(src interface{}) (netip.Prefix, error) {
+	switch v := src.(type) {
+	case nil:
+		return netip.Prefix{}, nil
+	case netip.Prefix:
+		return v, nil
+	case *netip.Prefix:
+		return *v, nil
+	}
+
+	s := strings.TrimSpace(AsString(src))
+	if s == "" {
+		return netip.Prefix{}, nil
+	}
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
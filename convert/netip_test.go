@@ -0,0 +1,38 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"database/sql"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsAddrAndPrefix(t *testing.T) {
+	addr, err := AsAddr("192.168.0.1/24")
+	assert.NoError(t, err)
+	assert.EqualValues(t, netip.MustParseAddr("192.168.0.1"), addr)
+
+	addr, err = AsAddr([]byte("::1"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, netip.IPv6Loopback(), addr)
+
+	addr, err = AsAddr(&sql.NullString{})
+	assert.NoError(t, err)
+	assert.False(t, addr.IsValid())
+
+	prefix, err := AsPrefix("10.0.0.0/8")
+	assert.NoError(t, err)
+	assert.EqualValues(t, netip.MustParsePrefix("10.0.0.0/8"), prefix)
+
+	prefix, err = AsPrefix(&sql.NullString{String: "10.1.2.3", Valid: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, netip.MustParsePrefix("10.1.2.3/32"), prefix)
+
+	_, err = AsPrefix("10.1.2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/imkos/xorm/contexts"
+)
+
+var (
+	_ QueryExecuter = &Conn{}
+)
+
+// Conn represents a single dedicated connection of the pool
+type Conn struct {
+	*sql.Conn
+	db *DB
+}
+
+// SingleConn returns a single dedicated connection from the pool, the connection must be
+// closed to return it to the pool
+func (db *DB) SingleConn(ctx context.Context) (*Conn, error) {
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn, db}, nil
+}
+
+// BeginTx begins a transaction on the connection
+func (conn *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	hookCtx := contexts.NewContextHook(ctx, "BEGIN TRANSACTION", nil)
+	ctx, err := conn.db.beforeProcess(hookCtx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.Conn.BeginTx(ctx, opts)
+	hookCtx.End(ctx, nil, err)
+	if err := conn.db.afterProcess(hookCtx); err != nil {
+		return nil, err
+	}
+	return &Tx{tx, conn.db, ctx}, nil
+}
+
+// PrepareContext creates a prepare statement on the connection
+func (conn *Conn) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	names := make(map[string]int)
+	var i int
+	query = re.ReplaceAllStringFunc(query, func(src string) string {
+		names[src[1:]] = i
+		i++
+		return "?"
+	})
+	hookCtx := contexts.NewContextHook(ctx, "PREPARE", nil)
+	ctx, err := conn.db.beforeProcess(hookCtx)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := conn.Conn.PrepareContext(ctx, query)
+	hookCtx.End(ctx, nil, err)
+	if err := conn.db.afterProcess(hookCtx); err != nil {
+		return nil, err
+	}
+	return &Stmt{stmt, conn.db, names, query}, nil
+}
+
+// ExecContext executes a query with args on the connection
+func (conn *Conn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	hookCtx := contexts.NewContextHook(ctx, query, args)
+	ctx, err := conn.db.beforeProcess(hookCtx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := conn.Conn.ExecContext(ctx, query, args...)
+	hookCtx.End(ctx, res, err)
+	if err := conn.db.afterProcess(hookCtx); err != nil {
+		return nil, err
+	}
+	return res, err
+}
+
+// QueryContext queries with args on the connection
+func (conn *Conn) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	hookCtx := contexts.NewContextHook(ctx, query, args)
+	ctx, err := conn.db.beforeProcess(hookCtx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.Conn.QueryContext(ctx, query, args...)
+	hookCtx.End(ctx, nil, err)
+	if err := conn.db.afterProcess(hookCtx); err != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		return nil, err
+	}
+	return &Rows{Rows: rows, db: conn.db}, nil
+}
+
+// QueryRowContext queries a row with args on the connection
+func (conn *Conn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return NewRow(conn.QueryContext(ctx, query, args...))
+}
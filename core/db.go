@@ -111,6 +111,19 @@ func FromDB(db *sql.DB) *DB {
 	}
 }
 
+// Reopen opens a new database with the data source name which shares the mapper,
+// the logger and the hooks with db
+func (db *DB) Reopen(driverName, dataSourceName string) (*DB, error) {
+	newDB, err := Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	newDB.Mapper = db.Mapper
+	newDB.Logger = db.Logger
+	newDB.hooks = db.hooks
+	return newDB, nil
+}
+
 // NeedLogSQL returns true if need to log SQL
 func (db *DB) NeedLogSQL(ctx context.Context) bool {
 	if db.Logger == nil {
@@ -152,7 +165,7 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{
 		}
 		return nil, err
 	}
-	return &Rows{rows, db}, nil
+	return &Rows{Rows: rows, db: db}, nil
 }
 
 // Query overwrites sql.DB.Query
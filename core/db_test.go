@@ -8,10 +8,13 @@ import (
 	"errors"
 	"flag"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/imkos/xorm/names"
+	"github.com/imkos/xorm/schemas"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/mattn/go-sqlite3"
@@ -689,3 +692,91 @@ func BenchmarkExecStruct(b *testing.B) {
 		}
 	}
 }
+
+func TestRowsOnClose(t *testing.T) {
+	db, err := testOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var closed int
+	rows.OnClose(func() {
+		closed++
+	})
+	for rows.Next() {
+	}
+	if closed != 0 {
+		t.Fatal("the close functions should not be called before the rows are closed")
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if closed != 1 {
+		t.Fatalf("the close functions should be called once but %d times", closed)
+	}
+}
+
+func TestScanMapDecode(t *testing.T) {
+	db, err := testOpen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DROP TABLE IF EXISTS decode_value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("CREATE TABLE decode_value (`id` INTEGER, `name` TEXT, `score` REAL, `data` BLOB, " +
+		"`flag` BOOLEAN, `created` DATETIME, `memo` TEXT NULL, `updated` CLOCK)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO decode_value VALUES (1, 'xlw', 1.5, X'0102', 1, '2023-01-02 03:04:05', NULL, '2023-01-02 03:04:05')")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]int{
+		"INTEGER":  schemas.NUMERIC_TYPE,
+		"REAL":     schemas.NUMERIC_TYPE,
+		"TEXT":     schemas.TEXT_TYPE,
+		"BLOB":     schemas.BLOB_TYPE,
+		"BOOLEAN":  schemas.BOOL_TYPE,
+		"DATETIME": schemas.TIME_TYPE,
+		"CLOCK":    schemas.TIME_TYPE,
+	}
+	// the driver doesn't parse the times of CLOCK, so they are parsed in the given location
+	loc := time.FixedZone("UTC+8", 8*3600)
+	var m map[string]interface{}
+	err = db.QueryRow("SELECT * FROM decode_value").ScanMapDecode(&m, func(name string) int {
+		return kinds[strings.ToUpper(name)]
+	}, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"id":      int64(1),
+		"name":    "xlw",
+		"score":   1.5,
+		"data":    []byte{1, 2},
+		"flag":    true,
+		"created": time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		"memo":    nil,
+		"updated": time.Date(2023, 1, 2, 3, 4, 5, 0, loc),
+	}
+	for k, v := range expected {
+		if !reflect.DeepEqual(m[k], v) {
+			t.Errorf("%s should be %#v but %#v", k, v, m[k])
+		}
+	}
+}
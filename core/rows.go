@@ -7,14 +7,33 @@ package core
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 // Rows represents rows of table
 type Rows struct {
 	*sql.Rows
-	db *DB
+	db         *DB
+	closeFuncs []func()
+}
+
+// OnClose registers a function which will be called after the rows are closed, i.e. to release
+// the context of the query
+func (rs *Rows) OnClose(fn func()) {
+	rs.closeFuncs = append(rs.closeFuncs, fn)
+}
+
+// Close closes the rows and calls the functions registered by OnClose
+func (rs *Rows) Close() error {
+	err := rs.Rows.Close()
+	for _, fn := range rs.closeFuncs {
+		fn()
+	}
+	rs.closeFuncs = nil
+	return err
 }
 
 // ToMapString returns all records
@@ -189,6 +208,41 @@ func (rs *Rows) ScanMap(dest interface{}) error {
 	return nil
 }
 
+// ScanMapDecode scans the current row to a map's pointer and decodes the driver native values
+// according to the column types, so the values are the same Go types whatever the driver is:
+// string, []byte, int64 (uint64 if it overflows), float64, bool, time.Time or nil.
+// columnTypeKind returns the kind of a database type name, i.e. dialects.Dialect.ColumnTypeKind,
+// and the times without time zone are parsed in loc which is the time zone of the database.
+func (rs *Rows) ScanMapDecode(dest *map[string]interface{}, columnTypeKind func(string) int, loc *time.Location) error {
+	if dest == nil {
+		return errors.New("dest should be a map's pointer")
+	}
+	types, err := rs.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	newDest := make([]interface{}, len(types))
+	for i := range types {
+		newDest[i] = new(interface{})
+	}
+	if err := rs.Rows.Scan(newDest...); err != nil {
+		return err
+	}
+
+	if *dest == nil {
+		*dest = make(map[string]interface{}, len(types))
+	}
+	for i, tp := range types {
+		v, err := DecodeValue(*newDest[i].(*interface{}), tp, columnTypeKind(tp.DatabaseTypeName()), loc)
+		if err != nil {
+			return fmt.Errorf("decode column %s: %w", tp.Name(), err)
+		}
+		(*dest)[tp.Name()] = v
+	}
+	return nil
+}
+
 // Row reprents a row of  a tab
 type Row struct {
 	rows *Rows
@@ -329,6 +383,27 @@ func (row *Row) ScanMap(dest interface{}) error {
 	return row.rows.Close()
 }
 
+// ScanMapDecode scans data to a map's pointer and decodes the values according to the column types
+func (row *Row) ScanMapDecode(dest *map[string]interface{}, columnTypeKind func(string) int, loc *time.Location) error {
+	if row.err != nil {
+		return row.err
+	}
+	defer row.rows.Close()
+
+	if !row.rows.Next() {
+		if err := row.rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := row.rows.ScanMapDecode(dest, columnTypeKind, loc); err != nil {
+		return err
+	}
+
+	// Make sure the query can be processed to completion with no errors.
+	return row.rows.Close()
+}
+
 // ToMapString returns all clumns of this record
 func (row *Row) ToMapString() (map[string]string, error) {
 	cols, err := row.Columns()
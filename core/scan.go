@@ -5,9 +5,14 @@
 package core
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/imkos/xorm/schemas"
 )
 
 // NullTime defines a customize type NullTime
@@ -68,3 +73,144 @@ type EmptyScanner struct {
 func (EmptyScanner) Scan(src interface{}) error {
 	return nil
 }
+
+var decodeTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// DecodeValue converts a driver native value to the Go type of the column type kind, the
+// times without time zone are parsed in loc, or UTC if loc is nil
+func DecodeValue(v interface{}, tp *sql.ColumnType, kind int, loc *time.Location) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case schemas.TEXT_TYPE:
+		switch t := v.(type) {
+		case []byte:
+			return string(t), nil
+		case string:
+			return t, nil
+		case time.Time:
+			return t.Format(time.RFC3339Nano), nil
+		default:
+			return fmt.Sprint(t), nil
+		}
+	case schemas.BLOB_TYPE:
+		switch t := v.(type) {
+		case []byte:
+			return t, nil
+		case string:
+			return []byte(t), nil
+		}
+	case schemas.TIME_TYPE:
+		switch t := v.(type) {
+		case time.Time:
+			return t, nil
+		case []byte:
+			return decodeTime(string(t), loc)
+		case string:
+			return decodeTime(t, loc)
+		}
+	case schemas.BOOL_TYPE:
+		switch t := v.(type) {
+		case bool:
+			return t, nil
+		case int64:
+			return t != 0, nil
+		case []byte:
+			return strconv.ParseBool(string(t))
+		case string:
+			return strconv.ParseBool(t)
+		}
+	case schemas.NUMERIC_TYPE:
+		if isFloatColumn(tp) {
+			return decodeFloat(v)
+		}
+		return decodeInt(v)
+	default:
+		if t, ok := v.([]byte); ok {
+			return string(t), nil
+		}
+	}
+	return v, nil
+}
+
+// isFloatColumn returns true if the numeric column may contain fractions
+func isFloatColumn(tp *sql.ColumnType) bool {
+	if _, scale, ok := tp.DecimalSize(); ok {
+		return scale > 0
+	}
+	name := strings.ToUpper(tp.DatabaseTypeName())
+	for _, s := range []string{"FLOAT", "DOUBLE", "REAL", "DEC", "NUMERIC", "NUMBER", "MONEY"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeTime(s string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range decodeTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %s", s)
+}
+
+func decodeInt(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case int64:
+		return t, nil
+	case float64:
+		return int64(t), nil
+	case bool:
+		if t {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case []byte:
+		return decodeNumber(string(t), false)
+	case string:
+		return decodeNumber(t, false)
+	}
+	return v, nil
+}
+
+func decodeFloat(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case int64:
+		return float64(t), nil
+	case []byte:
+		return decodeNumber(string(t), true)
+	case string:
+		return decodeNumber(t, true)
+	}
+	return v, nil
+}
+
+func decodeNumber(s string, isFloat bool) (interface{}, error) {
+	if !isFloat {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		// unsigned big integers are kept as uint64
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return u, nil
+		}
+	}
+	return strconv.ParseFloat(s, 64)
+}
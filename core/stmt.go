@@ -113,7 +113,7 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...interface{}) (*Rows, er
 	if err := s.db.afterProcess(hookCtx); err != nil {
 		return nil, err
 	}
-	return &Rows{rows, s.db}, nil
+	return &Rows{Rows: rows, db: s.db}, nil
 }
 
 // Query query with args
@@ -162,7 +162,7 @@ func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{
 		}
 		return nil, err
 	}
-	return &Rows{rows, tx.db}, nil
+	return &Rows{Rows: rows, db: tx.db}, nil
 }
 
 // Query query with args
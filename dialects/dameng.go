@@ -518,6 +518,8 @@ var (
 
 type dameng struct {
 	Base
+	identity bool // use the identity columns rather than the sequences as the autoincrement columns
+	seqCache int  // the cache size of the sequences, 0 means NOCACHE
 }
 
 func (db *dameng) Init(uri *URI) error {
@@ -549,9 +551,67 @@ func (db *dameng) Version(ctx context.Context, queryer core.Queryer) (*schemas.V
 }
 
 func (db *dameng) Features() *DialectFeatures {
+	if db.identity {
+		return &DialectFeatures{
+			AutoincrMode:        IncrAutoincrMode,
+			MaxIdentifierLength: 128,
+		}
+	}
 	return &DialectFeatures{
-		AutoincrMode: SequenceAutoincrMode,
+		AutoincrMode:        SequenceAutoincrMode,
+		MaxIdentifierLength: 128,
+	}
+}
+
+// SetParams sets the params of dameng, AUTOINCR_MODE is SEQUENCE (the default) or IDENTITY and
+// SEQUENCE_CACHE is the cache size of the sequences created for the autoincrement columns
+func (db *dameng) SetParams(params map[string]string) {
+	db.identity = strings.EqualFold(params["AUTOINCR_MODE"], "IDENTITY")
+	db.seqCache, _ = strconv.Atoi(params["SEQUENCE_CACHE"])
+}
+
+// ownerCond returns the condition of the owner column, the current schema of the session
+// is used if there is no schema in the data source name
+func (db *dameng) ownerCond(column string) (string, []interface{}) {
+	if db.uri.Schema != "" {
+		return column + " = ?", []interface{}{db.uri.Schema}
 	}
+	return column + " = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')", nil
+}
+
+// commentSQL returns the COMMENT ON statements of the table and the columns which have comments
+func (db *dameng) commentSQL(tableName, tableComment string, cols ...*schemas.Column) string {
+	quoter := db.dialect.Quoter()
+	var b strings.Builder
+	if tableComment != "" {
+		fmt.Fprintf(&b, "; COMMENT ON TABLE %s IS '%s'", quoter.Quote(tableName), strings.ReplaceAll(tableComment, "'", "''"))
+	}
+	for _, col := range cols {
+		if col.Comment != "" {
+			fmt.Fprintf(&b, "; COMMENT ON COLUMN %s.%s IS '%s'", quoter.Quote(tableName), quoter.Quote(col.Name),
+				strings.ReplaceAll(col.Comment, "'", "''"))
+		}
+	}
+	return b.String()
+}
+
+// columnString returns the definition of the column, the identity clause follows the column type
+func (db *dameng) columnString(col *schemas.Column) string {
+	s, _ := ColumnString(db, col, false, false)
+	if !db.identity || !col.IsAutoIncrement {
+		return s
+	}
+	prefix := db.quoter.Quote(col.Name) + " " + db.SQLType(col)
+	return prefix + " " + db.AutoIncrStr() + s[len(prefix):]
+}
+
+func (db *dameng) CreateSequenceSQL(ctx context.Context, queryer core.Queryer, seqName string) (string, error) {
+	cache := "NOCACHE"
+	if db.seqCache > 0 {
+		cache = fmt.Sprintf("CACHE %d", db.seqCache)
+	}
+	return fmt.Sprintf("CREATE SEQUENCE %s MINVALUE 1 NOMAXVALUE START WITH 1 INCREMENT BY 1 NOCYCLE %s",
+		seqName, cache), nil
 }
 
 // DropIndexSQL returns a SQL to drop index
@@ -559,7 +619,7 @@ func (db *dameng) DropIndexSQL(tableName string, index *schemas.Index) string {
 	quote := db.dialect.Quoter().Quote
 	var name string
 	if index.IsRegular {
-		name = index.XName(tableName)
+		name = index.XName(tableName, db.dialect.Features().MaxIdentifierLength)
 	} else {
 		name = index.Name
 	}
@@ -575,13 +635,16 @@ func (db *dameng) SQLType(c *schemas.Column) string {
 		return "INTEGER"
 	case schemas.BigInt,
 		schemas.UnsignedBigInt, schemas.UnsignedBit, schemas.UnsignedInt,
-		schemas.Serial, schemas.BigSerial:
+		schemas.Serial, schemas.BigSerial, schemas.BitSet, schemas.VarBit:
 		return "BIGINT"
 	case schemas.Bit, schemas.Bool, schemas.Boolean:
 		return schemas.Bit
 	case schemas.Uuid:
 		res = schemas.Varchar
 		c.Length = 40
+	case schemas.Inet, schemas.Cidr:
+		res = "VARCHAR2"
+		c.Length = 64
 	case schemas.Binary:
 		if c.Length == 0 {
 			return schemas.Binary + "(MAX)"
@@ -657,10 +720,16 @@ func (db *dameng) DropTableSQL(tableName string) (string, bool) {
 	return fmt.Sprintf("DROP TABLE %s", db.quoter.Quote(tableName)), false
 }
 
+// AddColumnSQL returns a SQL to add a column and the comment of the column
+func (db *dameng) AddColumnSQL(tableName string, col *schemas.Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", db.quoter.Quote(tableName), db.columnString(col)) +
+		db.commentSQL(tableName, "", col)
+}
+
 // ModifyColumnSQL returns a SQL to modify SQL
 func (db *dameng) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	s, _ := ColumnString(db.dialect, col, false, false)
-	return fmt.Sprintf("ALTER TABLE %s MODIFY %s", db.quoter.Quote(tableName), s)
+	return fmt.Sprintf("ALTER TABLE %s MODIFY %s", db.quoter.Quote(tableName), s) + db.commentSQL(tableName, "", col)
 }
 
 func (db *dameng) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
@@ -692,8 +761,7 @@ func (db *dameng) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 			}
 		}
 
-		s, _ := ColumnString(db, col, false, false)
-		if _, err := b.WriteString(s); err != nil {
+		if _, err := b.WriteString(db.columnString(col)); err != nil {
 			return "", false, err
 		}
 		if i != len(table.ColumnsSeq())-1 {
@@ -729,6 +797,14 @@ func (db *dameng) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 		return "", false, err
 	}
 
+	cols := make([]*schemas.Column, 0, len(table.ColumnsSeq()))
+	for _, colName := range table.ColumnsSeq() {
+		cols = append(cols, table.GetColumn(colName))
+	}
+	if _, err := b.WriteString(db.commentSQL(tableName, table.Comment, cols...)); err != nil {
+		return "", false, err
+	}
+
 	return b.String(), false, nil
 }
 
@@ -750,18 +826,22 @@ func (db *dameng) SetQuotePolicy(quotePolicy QuotePolicy) {
 }
 
 func (db *dameng) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
-	args := []interface{}{tableName, idxName}
-	return `SELECT INDEX_NAME FROM USER_INDEXES ` +
-		`WHERE TABLE_NAME = ? AND INDEX_NAME = ?`, args
+	cond, args := db.ownerCond("TABLE_OWNER")
+	return `SELECT INDEX_NAME FROM ALL_INDEXES ` +
+		`WHERE ` + cond + ` AND TABLE_NAME = ? AND INDEX_NAME = ?`, append(args, tableName, idxName)
 }
 
 func (db *dameng) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
-	return db.HasRecords(queryer, ctx, `SELECT table_name FROM user_tables WHERE table_name = ?`, tableName)
+	cond, args := db.ownerCond("OWNER")
+	return db.HasRecords(queryer, ctx, `SELECT TABLE_NAME FROM ALL_TABLES WHERE `+cond+` AND TABLE_NAME = ?`,
+		append(args, tableName)...)
 }
 
 func (db *dameng) IsSequenceExist(ctx context.Context, queryer core.Queryer, seqName string) (bool, error) {
 	var cnt int
-	rows, err := queryer.QueryContext(ctx, "SELECT COUNT(*) FROM user_sequences WHERE sequence_name = ?", seqName)
+	cond, args := db.ownerCond("SEQUENCE_OWNER")
+	rows, err := queryer.QueryContext(ctx, "SELECT COUNT(*) FROM ALL_SEQUENCES WHERE "+cond+" AND SEQUENCE_NAME = ?",
+		append(args, seqName)...)
 	if err != nil {
 		return false, err
 	}
@@ -780,10 +860,10 @@ func (db *dameng) IsSequenceExist(ctx context.Context, queryer core.Queryer, seq
 }
 
 func (db *dameng) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
-	args := []interface{}{tableName, colName}
-	query := "SELECT column_name FROM USER_TAB_COLUMNS WHERE table_name = ?" +
-		" AND column_name = ?"
-	return db.HasRecords(queryer, ctx, query, args...)
+	cond, args := db.ownerCond("OWNER")
+	query := "SELECT COLUMN_NAME FROM ALL_TAB_COLUMNS WHERE " + cond + " AND TABLE_NAME = ?" +
+		" AND COLUMN_NAME = ?"
+	return db.HasRecords(queryer, ctx, query, append(args, tableName, colName)...)
 }
 
 var _ sql.Scanner = &dmClobScanner{}
@@ -858,10 +938,11 @@ func addSingleQuote(name string) string {
 }
 
 func (db *dameng) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
-	s := `select   column_name   from   user_cons_columns   
-  where   constraint_name   =   (select   constraint_name   from   user_constraints   
-			  where   table_name   =   ?  and   constraint_type   ='P')`
-	rows, err := queryer.QueryContext(ctx, s, tableName)
+	cond, args := db.ownerCond("c.OWNER")
+	s := `SELECT cc.COLUMN_NAME FROM ALL_CONSTRAINTS c
+		JOIN ALL_CONS_COLUMNS cc ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+		WHERE ` + cond + ` AND c.TABLE_NAME = ? AND c.CONSTRAINT_TYPE = 'P'`
+	rows, err := queryer.QueryContext(ctx, s, append(args, tableName)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -881,14 +962,14 @@ func (db *dameng) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 	}
 	rows.Close()
 
-	s = `SELECT USER_TAB_COLS.COLUMN_NAME, USER_TAB_COLS.DATA_DEFAULT, USER_TAB_COLS.DATA_TYPE, USER_TAB_COLS.DATA_LENGTH, 
-		USER_TAB_COLS.data_precision, USER_TAB_COLS.data_scale, USER_TAB_COLS.NULLABLE,
-		user_col_comments.comments
-		FROM USER_TAB_COLS 
-		LEFT JOIN user_col_comments on user_col_comments.TABLE_NAME=USER_TAB_COLS.TABLE_NAME 
-		AND user_col_comments.COLUMN_NAME=USER_TAB_COLS.COLUMN_NAME
-		WHERE USER_TAB_COLS.table_name = ?`
-	rows, err = queryer.QueryContext(ctx, s, tableName)
+	cond, args = db.ownerCond("t.OWNER")
+	s = `SELECT t.COLUMN_NAME, t.DATA_DEFAULT, t.DATA_TYPE, t.DATA_LENGTH,
+		t.DATA_PRECISION, t.DATA_SCALE, t.NULLABLE, c.COMMENTS
+		FROM ALL_TAB_COLUMNS t
+		LEFT JOIN ALL_COL_COMMENTS c ON c.OWNER = t.OWNER AND c.TABLE_NAME = t.TABLE_NAME
+		AND c.COLUMN_NAME = t.COLUMN_NAME
+		WHERE ` + cond + ` AND t.TABLE_NAME = ? ORDER BY t.COLUMN_ID`
+	rows, err = queryer.QueryContext(ctx, s, append(args, tableName)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -927,12 +1008,12 @@ func (db *dameng) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 			col.Nullable = false
 		}
 
-		if !comment.Valid {
+		if comment.Valid {
 			col.Comment = comment.String
 		}
 		if utils.IndexSlice(pkNames, col.Name) > -1 {
 			col.IsPrimaryKey = true
-			has, err := db.HasRecords(queryer, ctx, "SELECT * FROM USER_SEQUENCES WHERE SEQUENCE_NAME = ?", utils.SeqName(tableName))
+			has, err := db.IsSequenceExist(ctx, queryer, utils.SeqName(tableName))
 			if err != nil {
 				return nil, nil, err
 			}
@@ -1010,10 +1091,12 @@ func (db *dameng) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 }
 
 func (db *dameng) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
-	s := "SELECT table_name FROM user_tables WHERE temporary = 'N' AND table_name NOT LIKE ?"
-	args := []interface{}{strings.ToUpper(db.uri.User), "%$%"}
+	cond, args := db.ownerCond("t.OWNER")
+	s := "SELECT t.TABLE_NAME, c.COMMENTS FROM ALL_TABLES t" +
+		" LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = t.OWNER AND c.TABLE_NAME = t.TABLE_NAME" +
+		" WHERE " + cond + " AND t.TEMPORARY = 'N' AND t.TABLE_NAME NOT LIKE ?"
 
-	rows, err := queryer.QueryContext(ctx, s, args...)
+	rows, err := queryer.QueryContext(ctx, s, append(args, "%$%")...)
 	if err != nil {
 		return nil, err
 	}
@@ -1021,11 +1104,13 @@ func (db *dameng) GetTables(queryer core.Queryer, ctx context.Context) ([]*schem
 
 	tables := make([]*schemas.Table, 0)
 	for rows.Next() {
+		var comment sql.NullString
 		table := schemas.NewEmptyTable()
-		err = rows.Scan(&table.Name)
+		err = rows.Scan(&table.Name, &comment)
 		if err != nil {
 			return nil, err
 		}
+		table.Comment = comment.String
 
 		tables = append(tables, table)
 	}
@@ -1036,12 +1121,15 @@ func (db *dameng) GetTables(queryer core.Queryer, ctx context.Context) ([]*schem
 }
 
 func (db *dameng) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
-	args := []interface{}{tableName, tableName}
-	s := "SELECT t.column_name,i.uniqueness,i.index_name FROM user_ind_columns t,user_indexes i " +
-		"WHERE t.index_name = i.index_name and t.table_name = i.table_name and t.table_name =?" +
-		" AND t.index_name not in (SELECT index_name FROM ALL_CONSTRAINTS WHERE CONSTRAINT_TYPE='P' AND table_name = ?)"
-
-	rows, err := queryer.QueryContext(ctx, s, args...)
+	cond, args := db.ownerCond("i.TABLE_OWNER")
+	s := "SELECT t.COLUMN_NAME, i.UNIQUENESS, i.INDEX_NAME FROM ALL_IND_COLUMNS t" +
+		" JOIN ALL_INDEXES i ON t.INDEX_OWNER = i.OWNER AND t.INDEX_NAME = i.INDEX_NAME" +
+		" WHERE " + cond + " AND i.TABLE_NAME = ?" +
+		" AND NOT EXISTS (SELECT 1 FROM ALL_CONSTRAINTS c WHERE c.OWNER = i.TABLE_OWNER AND c.TABLE_NAME = i.TABLE_NAME" +
+		" AND c.CONSTRAINT_TYPE = 'P' AND c.INDEX_NAME = i.INDEX_NAME)" +
+		" ORDER BY i.INDEX_NAME, t.COLUMN_POSITION"
+
+	rows, err := queryer.QueryContext(ctx, s, append(args, tableName)...)
 	if err != nil {
 		return nil, err
 	}
@@ -1060,12 +1148,10 @@ func (db *dameng) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 		indexName = strings.Trim(indexName, `" `)
 
 		var isRegular bool
-		if strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName) {
-			indexName = indexName[5+len(tableName):]
-			isRegular = true
-		}
+		indexName, isRegular = regularIndexName(tableName, indexName)
 
-		if uniqueness == "UNIQUE" {
+		// the uniqueness is UNIQUE or NONUNIQUE
+		if strings.EqualFold(strings.TrimSpace(uniqueness), "UNIQUE") {
 			indexType = schemas.UniqueType
 		} else {
 			indexType = schemas.IndexType
@@ -1104,7 +1190,7 @@ func (d *damengDriver) Features() *DriverFeatures {
 }
 
 // Parse parse the datasource
-// dm://userName:password@ip:port
+// dm://userName:password@ip:port?schema=name
 func (d *damengDriver) Parse(driverName, dataSourceName string) (*URI, error) {
 	u, err := url.Parse(dataSourceName)
 	if err != nil {
@@ -1124,6 +1210,7 @@ func (d *damengDriver) Parse(driverName, dataSourceName string) (*URI, error) {
 		DBName: u.User.Username(),
 		User:   u.User.Username(),
 		Passwd: passwd,
+		Schema: u.Query().Get("schema"),
 	}, nil
 }
 
@@ -0,0 +1,71 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDamengConnStr(t *testing.T) {
+	uri, err := QueryDriver("dm").Parse("dm", "dm://SYSDBA:SYSDBA001@localhost:5236?schema=APP")
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.DAMENG, uri.DBType)
+	assert.EqualValues(t, "SYSDBA", uri.User)
+	assert.EqualValues(t, "APP", uri.Schema)
+
+	dialect, err := OpenDialect("dm", "dm://SYSDBA:SYSDBA001@localhost:5236?schema=APP")
+	assert.NoError(t, err)
+	sql, args := dialect.IndexCheckSQL("orders", "IDX_orders_name")
+	assert.EqualValues(t, "SELECT INDEX_NAME FROM ALL_INDEXES WHERE TABLE_OWNER = ? AND TABLE_NAME = ? AND INDEX_NAME = ?", sql)
+	assert.EqualValues(t, []interface{}{"APP", "orders", "IDX_orders_name"}, args)
+
+	dialect, err = OpenDialect("dm", "dm://SYSDBA:SYSDBA001@localhost:5236")
+	assert.NoError(t, err)
+	sql, args = dialect.IndexCheckSQL("orders", "IDX_orders_name")
+	assert.EqualValues(t, "SELECT INDEX_NAME FROM ALL_INDEXES WHERE TABLE_OWNER = SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')"+
+		" AND TABLE_NAME = ? AND INDEX_NAME = ?", sql)
+	assert.EqualValues(t, []interface{}{"orders", "IDX_orders_name"}, args)
+}
+
+func TestDamengCreateTableSQL(t *testing.T) {
+	dialect, err := OpenDialect("dm", "dm://SYSDBA:SYSDBA001@localhost:5236")
+	assert.NoError(t, err)
+
+	table := schemas.NewEmptyTable()
+	table.Name = "orders"
+	table.Comment = "the user's orders"
+	id := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false)
+	id.IsPrimaryKey = true
+	id.IsAutoIncrement = true
+	table.AddColumn(id)
+	table.PrimaryKeys = []string{"id"}
+	name := schemas.NewColumn("name", "", schemas.SQLType{Name: schemas.Varchar}, 100, 0, true)
+	name.Comment = "name"
+	table.AddColumn(name)
+
+	sql, _, err := dialect.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `CREATE TABLE "orders" ("id" BIGINT NOT NULL, "name" VARCHAR2(100) NULL, CONSTRAINT PK_orders PRIMARY KEY ("id"))`+
+		`; COMMENT ON TABLE "orders" IS 'the user''s orders'; COMMENT ON COLUMN "orders"."name" IS 'name'`, sql)
+	assert.EqualValues(t, SequenceAutoincrMode, dialect.Features().AutoincrMode)
+	seqSQL, err := dialect.CreateSequenceSQL(context.Background(), nil, "SEQ_orders")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE SEQUENCE SEQ_orders MINVALUE 1 NOMAXVALUE START WITH 1 INCREMENT BY 1 NOCYCLE NOCACHE", seqSQL)
+
+	dialect.SetParams(map[string]string{"AUTOINCR_MODE": "identity", "SEQUENCE_CACHE": "20"})
+	assert.EqualValues(t, IncrAutoincrMode, dialect.Features().AutoincrMode)
+	sql, _, err = dialect.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `CREATE TABLE "orders" ("id" BIGINT IDENTITY NOT NULL, "name" VARCHAR2(100) NULL, CONSTRAINT PK_orders PRIMARY KEY ("id"))`+
+		`; COMMENT ON TABLE "orders" IS 'the user''s orders'; COMMENT ON COLUMN "orders"."name" IS 'name'`, sql)
+	seqSQL, err = dialect.CreateSequenceSQL(context.Background(), nil, "SEQ_orders")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE SEQUENCE SEQ_orders MINVALUE 1 NOMAXVALUE START WITH 1 INCREMENT BY 1 NOCYCLE CACHE 20", seqSQL)
+}
@@ -0,0 +1,574 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imkos/xorm/core"
+	"github.com/imkos/xorm/schemas"
+)
+
+func init() {
+	RegisterDriver("go_ibm_db", &db2Driver{})
+	RegisterDialect(schemas.DB2, func() Dialect {
+		return &db2{}
+	})
+}
+
+var (
+	db2ReservedWords = map[string]bool{
+		"ADD":               true,
+		"AFTER":             true,
+		"ALL":               true,
+		"ALLOCATE":          true,
+		"ALTER":             true,
+		"AND":               true,
+		"ANY":               true,
+		"AS":                true,
+		"ASC":               true,
+		"BEFORE":            true,
+		"BEGIN":             true,
+		"BETWEEN":           true,
+		"BY":                true,
+		"CALL":              true,
+		"CASE":              true,
+		"CAST":              true,
+		"CHECK":             true,
+		"COLUMN":            true,
+		"COMMIT":            true,
+		"CONSTRAINT":        true,
+		"CREATE":            true,
+		"CROSS":             true,
+		"CURRENT":           true,
+		"CURRENT_DATE":      true,
+		"CURRENT_SCHEMA":    true,
+		"CURRENT_TIME":      true,
+		"CURRENT_TIMESTAMP": true,
+		"CURRENT_USER":      true,
+		"CURSOR":            true,
+		"DEFAULT":           true,
+		"DELETE":            true,
+		"DESC":              true,
+		"DISTINCT":          true,
+		"DROP":              true,
+		"ELSE":              true,
+		"END":               true,
+		"EXCEPT":            true,
+		"EXISTS":            true,
+		"FETCH":             true,
+		"FOR":               true,
+		"FOREIGN":           true,
+		"FROM":              true,
+		"FULL":              true,
+		"GRANT":             true,
+		"GROUP":             true,
+		"HAVING":            true,
+		"IN":                true,
+		"INDEX":             true,
+		"INNER":             true,
+		"INSERT":            true,
+		"INTERSECT":         true,
+		"INTO":              true,
+		"IS":                true,
+		"JOIN":              true,
+		"KEY":               true,
+		"LEFT":              true,
+		"LIKE":              true,
+		"NOT":               true,
+		"NULL":              true,
+		"OF":                true,
+		"OFFSET":            true,
+		"ON":                true,
+		"OR":                true,
+		"ORDER":             true,
+		"OUTER":             true,
+		"PRIMARY":           true,
+		"REFERENCES":        true,
+		"RIGHT":             true,
+		"ROLLBACK":          true,
+		"ROW":               true,
+		"ROWS":              true,
+		"SELECT":            true,
+		"SET":               true,
+		"SOME":              true,
+		"TABLE":             true,
+		"THEN":              true,
+		"TO":                true,
+		"UNION":             true,
+		"UNIQUE":            true,
+		"UPDATE":            true,
+		"USER":              true,
+		"USING":             true,
+		"VALUES":            true,
+		"VIEW":              true,
+		"WHEN":              true,
+		"WHERE":             true,
+		"WITH":              true,
+	}
+
+	db2Quoter = schemas.Quoter{
+		Prefix:     '"',
+		Suffix:     '"',
+		IsReserved: schemas.AlwaysReserve,
+	}
+)
+
+type db2 struct {
+	Base
+}
+
+func (db *db2) Init(uri *URI) error {
+	db.quoter = db2Quoter
+	return db.Base.Init(db, uri)
+}
+
+func (db *db2) Version(ctx context.Context, queryer core.Queryer) (*schemas.Version, error) {
+	rows, err := queryer.QueryContext(ctx, "SELECT SERVICE_LEVEL FROM SYSIBMADM.ENV_INST_INFO")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var version string
+	if !rows.Next() {
+		if rows.Err() != nil {
+			return nil, rows.Err()
+		}
+		return nil, errors.New("unknow version")
+	}
+
+	if err := rows.Scan(&version); err != nil {
+		return nil, err
+	}
+	return &schemas.Version{
+		Number:  strings.TrimPrefix(version, "DB2 "),
+		Edition: "db2",
+	}, nil
+}
+
+func (db *db2) Features() *DialectFeatures {
+	return &DialectFeatures{
+		AutoincrMode:        IncrAutoincrMode,
+		MaxIdentifierLength: 128,
+	}
+}
+
+func (db *db2) SetQuotePolicy(quotePolicy QuotePolicy) {
+	switch quotePolicy {
+	case QuotePolicyNone:
+		q := db2Quoter
+		q.IsReserved = schemas.AlwaysNoReserve
+		db.quoter = q
+	case QuotePolicyReserved:
+		q := db2Quoter
+		q.IsReserved = db.IsReserved
+		db.quoter = q
+	case QuotePolicyAlways:
+		fallthrough
+	default:
+		db.quoter = db2Quoter
+	}
+}
+
+func (db *db2) SQLType(c *schemas.Column) string {
+	switch t := c.SQLType.Name; t {
+	case schemas.Bool, schemas.Boolean:
+		return schemas.Boolean
+	case schemas.Bit, schemas.TinyInt, schemas.UnsignedTinyInt, schemas.SmallInt:
+		return schemas.SmallInt
+	case schemas.UnsignedSmallInt, schemas.MediumInt, schemas.UnsignedMediumInt, schemas.Int, schemas.Integer:
+		return schemas.Integer
+	case schemas.Serial:
+		c.IsAutoIncrement = true
+		c.Nullable = false
+		return schemas.Integer
+	case schemas.BigSerial:
+		c.IsAutoIncrement = true
+		c.Nullable = false
+		return schemas.BigInt
+	case schemas.UnsignedInt, schemas.BigInt, schemas.BitSet, schemas.VarBit:
+		return schemas.BigInt
+	case schemas.UnsignedBigInt:
+		return "DECIMAL(20,0)"
+	case schemas.Float, schemas.Real:
+		return schemas.Real
+	case schemas.Double:
+		return schemas.Double
+	case schemas.Decimal, schemas.Numeric, schemas.Money:
+		if c.Length > 0 {
+			return fmt.Sprintf("DECIMAL(%d,%d)", c.Length, c.Length2)
+		}
+		return schemas.Decimal
+	case schemas.Char, schemas.NChar:
+		if c.Length > 0 {
+			return fmt.Sprintf("CHAR(%d)", c.Length)
+		}
+		return schemas.Char
+	case schemas.Varchar, schemas.NVarchar, schemas.Enum, schemas.Set, schemas.Inet, schemas.Cidr:
+		if c.Length > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", c.Length)
+		}
+		return "VARCHAR(255)"
+	case schemas.Uuid:
+		return "CHAR(36)"
+	case schemas.TinyText, schemas.Text, schemas.MediumText, schemas.LongText, schemas.Clob,
+		schemas.Json, schemas.Jsonb:
+		return schemas.Clob
+	case schemas.Date:
+		return schemas.Date
+	case schemas.Time:
+		return schemas.Time
+	case schemas.DateTime, schemas.TimeStamp, schemas.TimeStampz:
+		return schemas.TimeStamp
+	case schemas.Binary, schemas.VarBinary:
+		if c.Length > 0 {
+			return fmt.Sprintf("VARBINARY(%d)", c.Length)
+		}
+		return schemas.Blob
+	case schemas.TinyBlob, schemas.Blob, schemas.MediumBlob, schemas.LongBlob, schemas.Bytea:
+		return schemas.Blob
+	default:
+		return t
+	}
+}
+
+func (db *db2) ColumnTypeKind(t string) int {
+	switch strings.ToUpper(schemas.SQLTypeName(t)) {
+	case "DATE", "TIME", "TIMESTAMP":
+		return schemas.TIME_TYPE
+	case "VARCHAR", "CHAR", "CHARACTER", "CLOB", "GRAPHIC", "VARGRAPHIC", "DBCLOB":
+		return schemas.TEXT_TYPE
+	case "SMALLINT", "INTEGER", "BIGINT", "DECIMAL", "DECFLOAT", "REAL", "DOUBLE", "BOOLEAN":
+		return schemas.NUMERIC_TYPE
+	case "VARBINARY", "BINARY", "BLOB":
+		return schemas.BLOB_TYPE
+	default:
+		return schemas.UNKNOW_TYPE
+	}
+}
+
+func (db *db2) IsReserved(name string) bool {
+	_, ok := db2ReservedWords[strings.ToUpper(name)]
+	return ok
+}
+
+func (db *db2) AutoIncrStr() string {
+	return "GENERATED BY DEFAULT AS IDENTITY"
+}
+
+// schemaCond returns the condition of the schema column, the current schema of the session
+// is used if there is no schema in the data source name
+func (db *db2) schemaCond(column string) (string, []interface{}) {
+	if db.uri.Schema != "" {
+		return column + " = ?", []interface{}{db.uri.Schema}
+	}
+	return column + " = CURRENT SCHEMA", nil
+}
+
+// columnString returns the definition of the column, the primary keys are always defined
+// as a table constraint. The NULL keyword is not allowed in the column definitions of Db2.
+func (db *db2) columnString(col *schemas.Column) string {
+	var b strings.Builder
+	_ = db.dialect.Quoter().QuoteTo(&b, col.Name)
+	b.WriteByte(' ')
+	b.WriteString(db.SQLType(col))
+	if !col.Nullable || col.IsAutoIncrement {
+		b.WriteString(" NOT NULL")
+	}
+	if col.IsAutoIncrement {
+		b.WriteByte(' ')
+		b.WriteString(db.AutoIncrStr())
+	} else if !col.DefaultIsEmpty {
+		b.WriteString(" DEFAULT ")
+		if col.Default == "" {
+			b.WriteString("''")
+		} else {
+			b.WriteString(col.Default)
+		}
+	}
+	return b.String()
+}
+
+func (db *db2) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
+	if tableName == "" {
+		tableName = table.Name
+	}
+
+	quoter := db.dialect.Quoter()
+	var b strings.Builder
+	b.WriteString("CREATE TABLE ")
+	if err := quoter.QuoteTo(&b, tableName); err != nil {
+		return "", false, err
+	}
+	b.WriteString(" (")
+
+	for i, colName := range table.ColumnsSeq() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(db.columnString(table.GetColumn(colName)))
+	}
+
+	if len(table.PrimaryKeys) > 0 {
+		b.WriteString(", PRIMARY KEY (")
+		b.WriteString(quoter.Join(table.PrimaryKeys, ","))
+		b.WriteString(")")
+	}
+	b.WriteString(")")
+	return b.String(), false, nil
+}
+
+func (db *db2) DropTableSQL(tableName string) (string, bool) {
+	return fmt.Sprintf("DROP TABLE %s", db.dialect.Quoter().Quote(tableName)), false
+}
+
+func (db *db2) AddColumnSQL(tableName string, col *schemas.Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", db.dialect.Quoter().Quote(tableName), db.columnString(col))
+}
+
+func (db *db2) ModifyColumnSQL(tableName string, col *schemas.Column) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DATA TYPE %s", quoter.Quote(tableName),
+		quoter.Quote(col.Name), db.SQLType(col))
+}
+
+func (db *db2) CreateSequenceSQL(ctx context.Context, queryer core.Queryer, seqName string) (string, error) {
+	return fmt.Sprintf("CREATE SEQUENCE %s START WITH 1 INCREMENT BY 1 NO CYCLE", db.dialect.Quoter().Quote(seqName)), nil
+}
+
+func (db *db2) IsSequenceExist(ctx context.Context, queryer core.Queryer, seqName string) (bool, error) {
+	cond, args := db.schemaCond("SEQSCHEMA")
+	return db.HasRecords(queryer, ctx, "SELECT SEQNAME FROM SYSCAT.SEQUENCES WHERE "+cond+" AND SEQNAME = ?",
+		append(args, seqName)...)
+}
+
+func (db *db2) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
+	cond, args := db.schemaCond("TABSCHEMA")
+	return "SELECT INDNAME FROM SYSCAT.INDEXES WHERE " + cond + " AND TABNAME = ? AND INDNAME = ?",
+		append(args, tableName, idxName)
+}
+
+// DropIndexSQL returns a SQL to drop the index, the index names of Db2 are unique in the schema
+func (db *db2) DropIndexSQL(tableName string, index *schemas.Index) string {
+	name := index.Name
+	if index.IsRegular {
+		name = index.XName(tableName, db.dialect.Features().MaxIdentifierLength)
+	}
+	return fmt.Sprintf("DROP INDEX %s", db.dialect.Quoter().Quote(name))
+}
+
+func (db *db2) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
+	cond, args := db.schemaCond("TABSCHEMA")
+	return db.HasRecords(queryer, ctx, "SELECT TABNAME FROM SYSCAT.TABLES WHERE "+cond+" AND TABNAME = ?",
+		append(args, tableName)...)
+}
+
+func (db *db2) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
+	cond, args := db.schemaCond("TABSCHEMA")
+	return db.HasRecords(queryer, ctx, "SELECT COLNAME FROM SYSCAT.COLUMNS WHERE "+cond+" AND TABNAME = ? AND COLNAME = ?",
+		append(args, tableName, colName)...)
+}
+
+func (db *db2) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	cond, args := db.schemaCond("TABSCHEMA")
+	rows, err := queryer.QueryContext(ctx, "SELECT TABNAME, COALESCE(REMARKS, '') FROM SYSCAT.TABLES WHERE "+
+		cond+" AND TYPE = 'T' ORDER BY TABNAME", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]*schemas.Table, 0)
+	for rows.Next() {
+		table := schemas.NewEmptyTable()
+		if err := rows.Scan(&table.Name, &table.Comment); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// db2ColumnType converts the TYPENAME of SYSCAT.COLUMNS as the SQL type
+func db2ColumnType(typeName string, length, scale int64) schemas.SQLType {
+	switch typeName = strings.ToUpper(strings.TrimSpace(typeName)); typeName {
+	case "VARCHAR", "CHARACTER VARYING":
+		return schemas.SQLType{Name: schemas.Varchar, DefaultLength: length}
+	case "CHARACTER", "CHAR":
+		return schemas.SQLType{Name: schemas.Char, DefaultLength: length}
+	case "DECIMAL":
+		return schemas.SQLType{Name: schemas.Decimal, DefaultLength: length, DefaultLength2: scale}
+	case "VARBINARY":
+		return schemas.SQLType{Name: schemas.VarBinary, DefaultLength: length}
+	case "DECFLOAT":
+		return schemas.SQLType{Name: schemas.Decimal}
+	}
+	return schemas.SQLType{Name: typeName}
+}
+
+func (db *db2) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+	cond, args := db.schemaCond("TABSCHEMA")
+	s := "SELECT COLNAME, TYPENAME, LENGTH, SCALE, NULLS, DEFAULT, IDENTITY, COALESCE(KEYSEQ, 0), COALESCE(REMARKS, '')" +
+		" FROM SYSCAT.COLUMNS WHERE " + cond + " AND TABNAME = ? ORDER BY COLNO"
+	rows, err := queryer.QueryContext(ctx, s, append(args, tableName)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	cols := make(map[string]*schemas.Column)
+	colSeq := make([]string, 0)
+	for rows.Next() {
+		var (
+			name, typeName, nulls, identity, comment string
+			length, scale, keySeq                    int64
+			colDefault                               sql.NullString
+		)
+		if err := rows.Scan(&name, &typeName, &length, &scale, &nulls, &colDefault, &identity, &keySeq, &comment); err != nil {
+			return nil, nil, err
+		}
+
+		col := new(schemas.Column)
+		col.Indexes = make(map[string]int)
+		col.Name = name
+		col.SQLType = db2ColumnType(typeName, length, scale)
+		col.Length = col.SQLType.DefaultLength
+		col.Length2 = col.SQLType.DefaultLength2
+		col.Nullable = nulls == "Y"
+		col.IsAutoIncrement = identity == "Y"
+		col.IsPrimaryKey = keySeq > 0
+		col.Comment = comment
+		if colDefault.Valid && !col.IsAutoIncrement {
+			col.Default = colDefault.String
+		} else {
+			col.DefaultIsEmpty = true
+		}
+
+		cols[col.Name] = col
+		colSeq = append(colSeq, col.Name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return colSeq, cols, nil
+}
+
+func (db *db2) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
+	cond, args := db.schemaCond("TABSCHEMA")
+	s := "SELECT INDNAME, COLNAMES, UNIQUERULE FROM SYSCAT.INDEXES WHERE " + cond + " AND TABNAME = ?"
+	rows, err := queryer.QueryContext(ctx, s, append(args, tableName)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make(map[string]*schemas.Index)
+	for rows.Next() {
+		var indexName, colNames, uniqueRule string
+		if err := rows.Scan(&indexName, &colNames, &uniqueRule); err != nil {
+			return nil, err
+		}
+		// P is the primary key
+		if uniqueRule == "P" {
+			continue
+		}
+
+		var isRegular bool
+		indexName, isRegular = regularIndexName(tableName, indexName)
+
+		index := &schemas.Index{
+			Name:      indexName,
+			Type:      schemas.IndexType,
+			IsRegular: isRegular,
+		}
+		if uniqueRule == "U" {
+			index.Type = schemas.UniqueType
+		}
+		// the columns are like +COL1-COL2, the signs are the orders
+		for _, colName := range strings.FieldsFunc(colNames, func(r rune) bool { return r == '+' || r == '-' }) {
+			index.AddColumn(colName)
+		}
+		indexes[indexName] = index
+	}
+	return indexes, rows.Err()
+}
+
+func (db *db2) Filters() []Filter {
+	return []Filter{}
+}
+
+type db2Driver struct {
+	baseDriver
+}
+
+func (p *db2Driver) Features() *DriverFeatures {
+	return &DriverFeatures{
+		SupportReturnInsertedID: false,
+	}
+}
+
+// Parse parses the data source name of go_ibm_db like
+// HOSTNAME=host;DATABASE=name;PORT=50000;UID=user;PWD=password;CURRENTSCHEMA=schema
+func (p *db2Driver) Parse(driverName, dataSourceName string) (*URI, error) {
+	uri := &URI{DBType: schemas.DB2}
+	for _, kv := range strings.Split(dataSourceName, ";") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		idx := strings.Index(kv, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid db2 data source name: %s", kv)
+		}
+		value := strings.TrimSpace(kv[idx+1:])
+		switch strings.ToUpper(strings.TrimSpace(kv[:idx])) {
+		case "HOSTNAME":
+			uri.Host = value
+		case "PORT":
+			uri.Port = value
+		case "DATABASE":
+			uri.DBName = value
+		case "UID":
+			uri.User = value
+		case "PWD":
+			uri.Passwd = value
+		case "CURRENTSCHEMA":
+			uri.Schema = value
+		}
+	}
+	if uri.DBName == "" {
+		return nil, errors.New("no DATABASE in db2 data source name")
+	}
+	return uri, nil
+}
+
+func (p *db2Driver) GenScanResult(colType string) (interface{}, error) {
+	switch colType {
+	case "SMALLINT", "INTEGER", "BIGINT":
+		var s sql.NullInt64
+		return &s, nil
+	case "REAL", "DOUBLE":
+		var s sql.NullFloat64
+		return &s, nil
+	case "BOOLEAN":
+		var s sql.NullBool
+		return &s, nil
+	case "DATE", "TIME", "TIMESTAMP":
+		var s sql.NullTime
+		return &s, nil
+	case "VARBINARY", "BINARY", "BLOB":
+		var s sql.RawBytes
+		return &s, nil
+	default:
+		// DECIMAL, VARCHAR and CLOB
+		var r sql.NullString
+		return &r, nil
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDB2ConnStr(t *testing.T) {
+	uri, err := QueryDriver("go_ibm_db").Parse("go_ibm_db", "HOSTNAME=db2.example.com;DATABASE=sample;PORT=50000;UID=db2inst1;PWD=secret;CurrentSchema=APP")
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.DB2, uri.DBType)
+	assert.EqualValues(t, "db2.example.com", uri.Host)
+	assert.EqualValues(t, "50000", uri.Port)
+	assert.EqualValues(t, "db2inst1", uri.User)
+	assert.EqualValues(t, "secret", uri.Passwd)
+	assert.EqualValues(t, "sample", uri.DBName)
+	assert.EqualValues(t, "APP", uri.Schema)
+
+	_, err = QueryDriver("go_ibm_db").Parse("go_ibm_db", "HOSTNAME=localhost;PORT=50000")
+	assert.Error(t, err)
+}
+
+func TestDB2CreateTableSQL(t *testing.T) {
+	dialect, err := OpenDialect("go_ibm_db", "HOSTNAME=localhost;DATABASE=sample;UID=db2inst1;PWD=pass")
+	assert.NoError(t, err)
+
+	table := schemas.NewEmptyTable()
+	table.Name = "orders"
+	id := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, true)
+	id.IsPrimaryKey = true
+	id.IsAutoIncrement = true
+	table.AddColumn(id)
+	table.PrimaryKeys = []string{"id"}
+	name := schemas.NewColumn("name", "", schemas.SQLType{Name: schemas.Varchar}, 100, 0, false)
+	name.Default = "''"
+	name.DefaultIsEmpty = false
+	table.AddColumn(name)
+	table.AddColumn(schemas.NewColumn("amount", "", schemas.SQLType{Name: schemas.Decimal}, 12, 2, true))
+	table.AddColumn(schemas.NewColumn("created", "", schemas.SQLType{Name: schemas.DateTime}, 0, 0, true))
+
+	sql, _, err := dialect.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `CREATE TABLE "orders" ("id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY, `+
+		`"name" VARCHAR(100) NOT NULL DEFAULT '', "amount" DECIMAL(12,2), "created" TIMESTAMP, PRIMARY KEY ("id"))`, sql)
+
+	index := &schemas.Index{Name: "name", Type: schemas.IndexType, Cols: []string{"name"}, IsRegular: true}
+	assert.EqualValues(t, `DROP INDEX "IDX_orders_name"`, dialect.DropIndexSQL("orders", index))
+	assert.EqualValues(t, `ALTER TABLE "orders" ADD COLUMN "amount" DECIMAL(12,2)`, dialect.AddColumnSQL("orders", table.GetColumn("amount")))
+	assert.EqualValues(t, `ALTER TABLE "orders" ALTER COLUMN "name" SET DATA TYPE VARCHAR(100)`, dialect.ModifyColumnSQL("orders", table.GetColumn("name")))
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/imkos/xorm/core"
@@ -28,12 +29,15 @@ type URI struct {
 	Raddr   string
 	Timeout time.Duration
 	Schema  string
+	// Warehouse is the virtual warehouse of snowflake which runs the queries
+	Warehouse string
 }
 
-// SetSchema set schema
+// SetSchema set schema, it's only available for postgres, mssql, mysql, snowflake, hana, db2 and dameng.
+// For mysql, the schema is the database the tables belong to.
 func (uri *URI) SetSchema(schema string) {
-	// hack me
-	if uri.DBType == schemas.POSTGRES {
+	switch uri.DBType {
+	case schemas.POSTGRES, schemas.MSSQL, schemas.MYSQL, schemas.SNOWFLAKE, schemas.HANA, schemas.DB2, schemas.DAMENG:
 		uri.Schema = strings.TrimSpace(schema)
 	}
 }
@@ -46,7 +50,12 @@ const (
 
 // DialectFeatures represents a dialect parameters
 type DialectFeatures struct {
-	AutoincrMode int // 0 autoincrement column, 1 sequence
+	AutoincrMode        int  // 0 autoincrement column, 1 sequence
+	SupportQualify      bool // support QUALIFY to filter the results of the window functions
+	MaxIdentifierLength int  // the max length of the identifiers, the longer index names are shortened, 0 means no limit
+	UpdateLimit         bool // support the limit of UPDATE, natively or by a subquery
+	DeleteLimit         bool // support the limit of DELETE, natively or by a subquery
+	MaxParams           int  // the max number of the parameters of a statement, 0 means no limit
 }
 
 // Dialect represents a kind of database
@@ -68,7 +77,7 @@ type Dialect interface {
 
 	GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error)
 	IndexCheckSQL(tableName, idxName string) (string, []interface{})
-	CreateIndexSQL(tableName string, index *schemas.Index) string
+	CreateIndexSQL(tableName string, index *schemas.Index) string // an empty SQL means the database has no indexes
 	DropIndexSQL(tableName string, index *schemas.Index) string
 
 	GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error)
@@ -84,11 +93,73 @@ type Dialect interface {
 	IsColumnExist(queryer core.Queryer, ctx context.Context, tableName string, colName string) (bool, error)
 	AddColumnSQL(tableName string, col *schemas.Column) string
 	ModifyColumnSQL(tableName string, col *schemas.Column) string
+	SetAutoIncrStartSQL(tableName, colName string, start int64) []string
 
 	Filters() []Filter
 	SetParams(params map[string]string)
 }
 
+// TablesMetaLoader represents a dialect which could load the columns and indexes of many
+// tables with one query for each kind, the results are keyed by the given table names.
+// It's optional and used to avoid one metadata round trip per table on Sync.
+type TablesMetaLoader interface {
+	GetColumnsOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string][]*schemas.Column, error)
+	GetIndexesOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string]map[string]*schemas.Index, error)
+}
+
+// PaginationWriter represents a dialect which writes the pagination clause of the SELECT
+// statements itself, limit is nil and offset is 0 if they are not set. It's optional.
+type PaginationWriter interface {
+	PaginationSQL(limit *int, offset int) string
+}
+
+// LastInsertIDQueryer represents a dialect which reads the id generated by the last insert with
+// a query executed in the same transaction, it's used when the driver doesn't return the last
+// inserted id. An empty SQL means the dialect doesn't support it. It's optional.
+type LastInsertIDQueryer interface {
+	LastInsertIDSQL(tableName string) string
+}
+
+// VersionAware represents a dialect which generates the SQL according to the version of the
+// database server, the version is given once by the engine before its first session. It's
+// optional, and SetVersion may be called while the SQL is generated.
+type VersionAware interface {
+	SetVersion(version *schemas.Version)
+}
+
+// TableSQLTyper represents a dialect whose column types depend on the table, i.e. the STRICT
+// tables of sqlite only accept some types. It's optional.
+type TableSQLTyper interface {
+	TableSQLType(table *schemas.Table, col *schemas.Column) string
+}
+
+// WithSchema returns a new dialect of the same database whose uri has the schema instead of the
+// default one, so that the metadata of the tables in other schemas could be queried, params are
+// the params of the dialect. The dialect is returned as is if the schema is not changed, and an
+// error is returned if the database doesn't support schemas.
+func WithSchema(dialect Dialect, schema string, params map[string]string) (Dialect, error) {
+	uri := *dialect.URI()
+	uri.SetSchema(schema)
+	if uri.Schema != strings.TrimSpace(schema) {
+		return nil, fmt.Errorf("%s doesn't support schemas", uri.DBType)
+	}
+	if uri.Schema == dialect.URI().Schema {
+		return dialect, nil
+	}
+
+	newDialect := QueryDialect(uri.DBType)
+	if newDialect == nil {
+		return nil, fmt.Errorf("unsupported dialect type: %v", uri.DBType)
+	}
+	if err := newDialect.Init(&uri); err != nil {
+		return nil, err
+	}
+	if len(params) > 0 {
+		newDialect.SetParams(params)
+	}
+	return newDialect, nil
+}
+
 // Base represents a basic dialect and all real dialects could embed this struct
 type Base struct {
 	dialect Dialect
@@ -119,21 +190,33 @@ func (db *Base) URI() *URI {
 
 // CreateTableSQL implements Dialect
 func (db *Base) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
+	var b strings.Builder
+	if err := writeCreateTable(&b, db.dialect, table, tableName, nil); err != nil {
+		return "", false, err
+	}
+	return b.String(), false, nil
+}
+
+// writeCreateTable writes the create table SQL with the columns and the composite primary key
+// of the table, column could replace a column before it's written, i.e. to change its type
+func writeCreateTable(b *strings.Builder, dialect Dialect, table *schemas.Table, tableName string, column func(col *schemas.Column) *schemas.Column) error {
 	if tableName == "" {
 		tableName = table.Name
 	}
 
-	quoter := db.dialect.Quoter()
-	var b strings.Builder
+	quoter := dialect.Quoter()
 	b.WriteString("CREATE TABLE IF NOT EXISTS ")
-	if err := quoter.QuoteTo(&b, tableName); err != nil {
-		return "", false, err
+	if err := quoter.QuoteTo(b, tableName); err != nil {
+		return err
 	}
 	b.WriteString(" (")
 
 	for i, colName := range table.ColumnsSeq() {
 		col := table.GetColumn(colName)
-		s, _ := ColumnString(db.dialect, col, col.IsPrimaryKey && len(table.PrimaryKeys) == 1, false)
+		if column != nil {
+			col = column(col)
+		}
+		s, _ := ColumnString(dialect, col, col.IsPrimaryKey && len(table.PrimaryKeys) == 1, false)
 		b.WriteString(s)
 
 		if i != len(table.ColumnsSeq())-1 {
@@ -148,8 +231,7 @@ func (db *Base) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 	}
 
 	b.WriteString(")")
-
-	return b.String(), false, nil
+	return nil
 }
 
 func (db *Base) CreateSequenceSQL(ctx context.Context, queryer core.Queryer, seqName string) (string, error) {
@@ -219,10 +301,45 @@ func (db *Base) CreateIndexSQL(tableName string, index *schemas.Index) string {
 	if index.Type == schemas.UniqueType {
 		unique = " UNIQUE"
 	}
-	idxName = index.XName(tableName)
+	idxName = index.XName(tableName, db.dialect.Features().MaxIdentifierLength)
 	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)", unique,
 		quoter.Quote(idxName), quoter.Quote(tableName),
-		quoter.Join(index.Cols, ","))
+		indexKeyParts(quoter, index))
+}
+
+// regularIndexName returns the name of the index without the prefix and true if the index is
+// created by xorm. The names shortened by schemas.ShortenIdentifier which lost the table name are
+// recognized as well, but they are returned as they are since the names can't be restored.
+func regularIndexName(tableName, indexName string) (string, bool) {
+	if (strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName)) &&
+		len(indexName) > 5+len(tableName) {
+		return indexName[5+len(tableName):], true
+	}
+	if (strings.HasPrefix(indexName, "IDX_") || strings.HasPrefix(indexName, "UQE_")) &&
+		schemas.IsShortenedIdentifier(indexName) {
+		return indexName, true
+	}
+	return indexName, false
+}
+
+// indexKeyParts returns the key parts of the index, the column names are quoted, the
+// expressions are kept as they are and the descending key parts are followed by DESC
+func indexKeyParts(quoter schemas.Quoter, index *schemas.Index) string {
+	var b strings.Builder
+	for i, col := range index.Cols {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if schemas.IsExprKeyPart(col) {
+			b.WriteString(col)
+		} else {
+			_ = quoter.QuoteTo(&b, col)
+		}
+		if index.IsDesc(i) {
+			b.WriteString(" DESC")
+		}
+	}
+	return b.String()
 }
 
 // DropIndexSQL returns a SQL to drop index
@@ -230,7 +347,7 @@ func (db *Base) DropIndexSQL(tableName string, index *schemas.Index) string {
 	quote := db.dialect.Quoter().Quote
 	var name string
 	if index.IsRegular {
-		name = index.XName(tableName)
+		name = index.XName(tableName, db.dialect.Features().MaxIdentifierLength)
 	} else {
 		name = index.Name
 	}
@@ -243,23 +360,40 @@ func (db *Base) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", db.quoter.Quote(tableName), s)
 }
 
+// SetAutoIncrStartSQL returns the SQLs to make the next value of the auto increment column be start,
+// nil means the database doesn't support it
+func (db *Base) SetAutoIncrStartSQL(tableName, colName string, start int64) []string {
+	return nil
+}
+
 // SetParams set params
 func (db *Base) SetParams(params map[string]string) {
 }
 
-var dialects = map[string]func() Dialect{}
+var (
+	dialects      = map[string]func() Dialect{}
+	dialectsMutex sync.RWMutex
+)
 
-// RegisterDialect register database dialect
+// RegisterDialect registers the dialect of the database type which is returned by the URI
+// of the driver, the names are case insensitive. A registered dialect, including the
+// built-in ones, is overridden, so an out-of-tree dialect could be registered with
+// RegisterDriver and RegisterDialect in the init function of its package.
 func RegisterDialect(dbName schemas.DBType, dialectFunc func() Dialect) {
 	if dialectFunc == nil {
 		panic("core: Register dialect is nil")
 	}
+	dialectsMutex.Lock()
+	defer dialectsMutex.Unlock()
 	dialects[strings.ToLower(string(dbName))] = dialectFunc // !nashtsai! allow override dialect
 }
 
 // QueryDialect query if registered database dialect
 func QueryDialect(dbName schemas.DBType) Dialect {
-	if d, ok := dialects[strings.ToLower(string(dbName))]; ok {
+	dialectsMutex.RLock()
+	d, ok := dialects[strings.ToLower(string(dbName))]
+	dialectsMutex.RUnlock()
+	if ok {
 		return d()
 	}
 	return nil
@@ -291,6 +425,10 @@ func regDrvsNDialects() bool {
 			RegisterDialect(v.dbType, v.getDialect)
 		}
 	}
+	// the stdlib of pgx v5 registers both pgx and pgx/v5
+	if QueryDriver("pgx/v5") == nil {
+		RegisterDriverAlias("pgx/v5", "pgx")
+	}
 	return true
 }
 
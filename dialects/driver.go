@@ -7,6 +7,7 @@ package dialects
 import (
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/imkos/xorm/core"
@@ -32,27 +33,70 @@ type Driver interface {
 }
 
 var (
-	drivers = map[string]Driver{}
+	drivers       = map[string]Driver{}
+	driverAliases = map[string]string{}
+	driversMutex  sync.RWMutex
 )
 
-// RegisterDriver register a driver
+// RegisterDriver registers a driver with the name which is passed to sql.Open, so that an
+// out-of-tree dialect could be used by NewEngine without forking this package. It panics
+// if the name has been registered, use OverrideDriver to replace a built-in driver.
 func RegisterDriver(driverName string, driver Driver) {
 	if driver == nil {
 		panic("core: Register driver is nil")
 	}
+	driversMutex.Lock()
+	defer driversMutex.Unlock()
 	if _, dup := drivers[driverName]; dup {
 		panic("core: Register called twice for driver " + driverName)
 	}
+	if _, dup := driverAliases[driverName]; dup {
+		panic("core: Register driver " + driverName + " which is an alias")
+	}
+	drivers[driverName] = driver
+}
+
+// OverrideDriver registers a driver with the name, the registered driver with the same
+// name, including the built-in ones, is replaced
+func OverrideDriver(driverName string, driver Driver) {
+	if driver == nil {
+		panic("core: Register driver is nil")
+	}
+	driversMutex.Lock()
+	defer driversMutex.Unlock()
+	delete(driverAliases, driverName)
 	drivers[driverName] = driver
 }
 
-// QueryDriver query a driver with name
+// RegisterDriverAlias makes the alias, i.e. "pgx/v5", use the registered driver of the
+// driver name, i.e. "pgx". It panics if the driver has not been registered or the alias
+// has been registered as a driver.
+func RegisterDriverAlias(alias, driverName string) {
+	driversMutex.Lock()
+	defer driversMutex.Unlock()
+	if _, ok := drivers[driverName]; !ok {
+		panic("core: Register alias of unknown driver " + driverName)
+	}
+	if _, dup := drivers[alias]; dup {
+		panic("core: Register alias " + alias + " which is a driver")
+	}
+	driverAliases[alias] = driverName
+}
+
+// QueryDriver query a driver with name, the aliases are resolved
 func QueryDriver(driverName string) Driver {
+	driversMutex.RLock()
+	defer driversMutex.RUnlock()
+	if name, ok := driverAliases[driverName]; ok {
+		driverName = name
+	}
 	return drivers[driverName]
 }
 
 // RegisteredDriverSize returned all drivers's length
 func RegisteredDriverSize() int {
+	driversMutex.RLock()
+	defer driversMutex.RUnlock()
 	return len(drivers)
 }
 
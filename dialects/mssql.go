@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/imkos/xorm/core"
 	"github.com/imkos/xorm/schemas"
@@ -217,7 +218,8 @@ type mssql struct {
 	Base
 	defaultVarchar string
 	defaultChar    string
-	useLegacy      bool
+	useLegacy      atomic.Bool
+	legacyParam    bool // USE_LEGACY_LIMIT_OFFSET is given
 }
 
 func (db *mssql) Init(uri *URI) error {
@@ -227,7 +229,7 @@ func (db *mssql) Init(uri *URI) error {
 	return db.Base.Init(db, uri)
 }
 
-func (db *mssql) UseLegacyLimitOffset() bool { return db.useLegacy }
+func (db *mssql) UseLegacyLimitOffset() bool { return db.useLegacy.Load() }
 
 func (db *mssql) SetParams(params map[string]string) {
 	defaultVarchar, ok := params["DEFAULT_VARCHAR"]
@@ -258,12 +260,26 @@ func (db *mssql) SetParams(params map[string]string) {
 
 	useLegacy, ok := params["USE_LEGACY_LIMIT_OFFSET"]
 	if ok {
+		db.legacyParam = true
 		if b, _ := strconv.ParseBool(useLegacy); b {
-			db.useLegacy = true
+			db.useLegacy.Store(true)
 		}
 	}
 }
 
+// SetVersion implements VersionAware, the legacy limit offset is used for the servers before
+// sqlserver 2012 (11.x) which don't support OFFSET FETCH, unless USE_LEGACY_LIMIT_OFFSET is given
+func (db *mssql) SetVersion(version *schemas.Version) {
+	if db.legacyParam || version == nil {
+		return
+	}
+	major, err := strconv.Atoi(strings.SplitN(version.Number, ".", 2)[0])
+	if err != nil {
+		return
+	}
+	db.useLegacy.Store(major < 11)
+}
+
 func (db *mssql) Version(ctx context.Context, queryer core.Queryer) (*schemas.Version, error) {
 	rows, err := queryer.QueryContext(ctx,
 		"SELECT SERVERPROPERTY('productversion'), SERVERPROPERTY ('productlevel') AS ProductLevel, SERVERPROPERTY ('edition') AS ProductEdition")
@@ -294,7 +310,11 @@ func (db *mssql) Version(ctx context.Context, queryer core.Queryer) (*schemas.Ve
 
 func (db *mssql) Features() *DialectFeatures {
 	return &DialectFeatures{
-		AutoincrMode: IncrAutoincrMode,
+		AutoincrMode:        IncrAutoincrMode,
+		MaxIdentifierLength: 128,
+		UpdateLimit:         true,
+		DeleteLimit:         true,
+		MaxParams:           2100,
 	}
 }
 
@@ -309,6 +329,8 @@ func (db *mssql) SQLType(c *schemas.Column) string {
 			c.Default = "0"
 		}
 		return res
+	case schemas.BitSet, schemas.VarBit:
+		return schemas.BigInt
 	case schemas.Serial:
 		c.IsAutoIncrement = true
 		c.IsPrimaryKey = true
@@ -343,6 +365,9 @@ func (db *mssql) SQLType(c *schemas.Column) string {
 	case schemas.Uuid:
 		res = schemas.Varchar
 		c.Length = 40
+	case schemas.Inet, schemas.Cidr:
+		res = schemas.Varchar
+		c.Length = 64
 	case schemas.TinyInt:
 		res = schemas.TinyInt
 		c.Length = 0
@@ -427,10 +452,26 @@ func (db *mssql) AutoIncrStr() string {
 	return "IDENTITY"
 }
 
+// objectName returns the table name qualified by the schema and quoted so that
+// it could be used as a string literal in object_id(), i.e. [dbo].[user]
+func (db *mssql) objectName(tableName string) string {
+	tableName = TableNameWithSchema(db, tableName)
+	return strings.ReplaceAll(mssqlQuoter.Quote(tableName), "'", "''")
+}
+
+// schemaName returns the schema of the table name, the current schema will be used if it's empty
+func (db *mssql) schemaName(tableName string) (string, string) {
+	tableName = TableNameWithSchema(db, tableName)
+	if i := strings.LastIndexByte(tableName, '.'); i > 0 {
+		return mssqlQuoter.Trim(tableName[:i]), mssqlQuoter.Trim(tableName[i+1:])
+	}
+	return "", mssqlQuoter.Trim(tableName)
+}
+
 func (db *mssql) DropTableSQL(tableName string) (string, bool) {
 	return fmt.Sprintf("IF EXISTS (SELECT * FROM sysobjects WHERE id = "+
 		"object_id(N'%s') and OBJECTPROPERTY(id, N'IsUserTable') = 1) "+
-		"DROP TABLE \"%s\"", tableName, tableName), true
+		"DROP TABLE %s", db.objectName(tableName), mssqlQuoter.Quote(tableName)), true
 }
 
 func (db *mssql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
@@ -438,20 +479,32 @@ func (db *mssql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", db.quoter.Quote(tableName), s)
 }
 
+func (db *mssql) SetAutoIncrStartSQL(tableName, colName string, start int64) []string {
+	// the reseed value will be used directly if no rows have been inserted since the table was created,
+	// otherwise the next value is the reseed value plus the increment
+	name := db.objectName(tableName)
+	return []string{fmt.Sprintf("IF (SELECT last_value FROM sys.identity_columns WHERE object_id = OBJECT_ID(N'%s')) IS NULL "+
+		"DBCC CHECKIDENT (N'%s', RESEED, %d) ELSE DBCC CHECKIDENT (N'%s', RESEED, %d)", name, name, start, name, start-1)}
+}
+
 func (db *mssql) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
 	args := []interface{}{idxName}
-	sql := "select name from sysindexes where id=object_id('" + tableName + "') and name=?"
+	sql := "select name from sysindexes where id=object_id('" + db.objectName(tableName) + "') and name=?"
 	return sql, args
 }
 
 func (db *mssql) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
-	query := `SELECT "COLUMN_NAME" FROM "INFORMATION_SCHEMA"."COLUMNS" WHERE "TABLE_NAME" = ? AND "COLUMN_NAME" = ?`
-
-	return db.HasRecords(queryer, ctx, query, tableName, colName)
+	schema, tableName := db.schemaName(tableName)
+	if schema == "" {
+		query := `SELECT "COLUMN_NAME" FROM "INFORMATION_SCHEMA"."COLUMNS" WHERE "TABLE_SCHEMA" = SCHEMA_NAME() AND "TABLE_NAME" = ? AND "COLUMN_NAME" = ?`
+		return db.HasRecords(queryer, ctx, query, tableName, colName)
+	}
+	query := `SELECT "COLUMN_NAME" FROM "INFORMATION_SCHEMA"."COLUMNS" WHERE "TABLE_SCHEMA" = ? AND "TABLE_NAME" = ? AND "COLUMN_NAME" = ?`
+	return db.HasRecords(queryer, ctx, query, schema, tableName, colName)
 }
 
 func (db *mssql) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
-	sql := "select * from sysobjects where id = object_id(N'" + tableName + "') and OBJECTPROPERTY(id, N'IsUserTable') = 1"
+	sql := "select * from sysobjects where id = object_id(N'" + db.objectName(tableName) + "') and OBJECTPROPERTY(id, N'IsUserTable') = 1"
 	return db.HasRecords(queryer, ctx, sql)
 }
 
@@ -469,7 +522,7 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		  LEFT JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
 			WHERE i.is_primary_key = 1
 		) as p on p.object_id = a.object_id AND p.column_id = a.column_id
-          where a.object_id=object_id('` + tableName + `')`
+          where a.object_id=object_id('` + db.objectName(tableName) + `')`
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -551,7 +604,15 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 
 func (db *mssql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
-	s := `select name from sysobjects where xtype ='U'`
+	s := `select t.name, COALESCE(CAST(ic.last_value AS bigint) + CAST(ic.increment_value AS bigint), CAST(ic.seed_value AS bigint))
+from sys.tables t inner join sys.schemas s on t.schema_id = s.schema_id
+left join sys.identity_columns ic on ic.object_id = t.object_id`
+	if db.uri.Schema != "" {
+		s += ` where s.name = ?`
+		args = append(args, db.uri.Schema)
+	} else {
+		s += ` where s.name = SCHEMA_NAME()`
+	}
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -563,11 +624,13 @@ func (db *mssql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	for rows.Next() {
 		table := schemas.NewEmptyTable()
 		var name string
-		err = rows.Scan(&name)
+		var autoIncr sql.NullInt64
+		err = rows.Scan(&name, &autoIncr)
 		if err != nil {
 			return nil, err
 		}
 		table.Name = strings.Trim(name, "` ")
+		table.AutoIncrStart = autoIncr.Int64
 		tables = append(tables, table)
 	}
 	if rows.Err() != nil {
@@ -577,7 +640,7 @@ func (db *mssql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 }
 
 func (db *mssql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
-	args := []interface{}{tableName}
+	args := []interface{}{db.objectName(tableName)}
 	s := `SELECT
 IXS.NAME                    AS  [INDEX_NAME],
 C.NAME                      AS  [COLUMN_NAME],
@@ -587,7 +650,7 @@ INNER JOIN sys.index_columns IXCS
 ON IXS.OBJECT_ID=IXCS.OBJECT_ID  AND IXS.INDEX_ID = IXCS.INDEX_ID
 INNER   JOIN sys.columns C  ON IXS.OBJECT_ID=C.OBJECT_ID
 AND IXCS.COLUMN_ID=C.COLUMN_ID
-WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
+WHERE IXS.TYPE_DESC='NONCLUSTERED' and IXS.OBJECT_ID = OBJECT_ID(?)
 `
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -619,10 +682,7 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 
 		colName = strings.Trim(colName, "` ")
 		var isRegular bool
-		if (strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName)) && len(indexName) > (5+len(tableName)) {
-			indexName = indexName[5+len(tableName):]
-			isRegular = true
-		}
+		indexName, isRegular = regularIndexName(tableName, indexName)
 
 		var index *schemas.Index
 		var ok bool
@@ -641,6 +701,14 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 	return indexes, nil
 }
 
+// mssqlTableOptions are the table options of mssql which could be set by TableOptions
+var mssqlTableOptions = map[string]bool{
+	"DATA_COMPRESSION": true,
+	"DURABILITY":       true,
+	"MEMORY_OPTIMIZED": true,
+	"XML_COMPRESSION":  true,
+}
+
 func (db *mssql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
 	if tableName == "" {
 		tableName = table.Name
@@ -648,9 +716,9 @@ func (db *mssql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 
 	quoter := db.dialect.Quoter()
 	var b strings.Builder
-	b.WriteString("IF NOT EXISTS (SELECT [name] FROM sys.tables WHERE [name] = '")
-	quoter.QuoteTo(&b, tableName)
-	b.WriteString("' ) CREATE TABLE ")
+	b.WriteString("IF OBJECT_ID(N'")
+	b.WriteString(db.objectName(tableName))
+	b.WriteString("', N'U') IS NULL CREATE TABLE ")
 	quoter.QuoteTo(&b, tableName)
 	b.WriteString(" (")
 
@@ -672,6 +740,23 @@ func (db *mssql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 
 	b.WriteString(")")
 
+	options := filterTableOptions(table.Options, func(name string) bool {
+		return mssqlTableOptions[name]
+	})
+	for i, opt := range options {
+		if i == 0 {
+			b.WriteString(" WITH (")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(opt.Name)
+		b.WriteString(" = ")
+		b.WriteString(opt.Value)
+	}
+	if len(options) > 0 {
+		b.WriteString(")")
+	}
+
 	return b.String(), true, nil
 }
 
@@ -7,6 +7,10 @@ package dialects
 import (
 	"reflect"
 	"testing"
+
+	"github.com/imkos/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestParseMSSQL(t *testing.T) {
@@ -31,3 +35,36 @@ func TestParseMSSQL(t *testing.T) {
 		}
 	}
 }
+
+func TestMSSQLSchema(t *testing.T) {
+	dialect := QueryDialect("mssql")
+	uri := &URI{DBType: schemas.MSSQL, DBName: "db"}
+	assert.NoError(t, dialect.Init(uri))
+	uri.SetSchema("sales")
+
+	sql, _ := dialect.IndexCheckSQL("orders", "IDX_orders_user")
+	assert.EqualValues(t, "select name from sysindexes where id=object_id('[sales].[orders]') and name=?", sql)
+
+	sql, _ = dialect.DropTableSQL("sales.orders")
+	assert.EqualValues(t, "IF EXISTS (SELECT * FROM sysobjects WHERE id = object_id(N'[sales].[orders]') and "+
+		"OBJECTPROPERTY(id, N'IsUserTable') = 1) DROP TABLE [sales].[orders]", sql)
+
+	sql, _ = dialect.IndexCheckSQL("[dbo].[orders]", "IDX_orders_user")
+	assert.EqualValues(t, "select name from sysindexes where id=object_id('[dbo].[orders]') and name=?", sql)
+}
+
+func TestMSSQLSetVersion(t *testing.T) {
+	dialect := QueryDialect("mssql")
+	assert.NoError(t, dialect.Init(&URI{DBType: schemas.MSSQL, DBName: "db"}))
+	dialect.SetParams(nil)
+
+	legacy := dialect.(interface{ UseLegacyLimitOffset() bool })
+	dialect.(VersionAware).SetVersion(&schemas.Version{Number: "10.50.1600.1"})
+	assert.True(t, legacy.UseLegacyLimitOffset())
+	dialect.(VersionAware).SetVersion(&schemas.Version{Number: "15.0.2000.5"})
+	assert.False(t, legacy.UseLegacyLimitOffset())
+
+	dialect.SetParams(map[string]string{"USE_LEGACY_LIMIT_OFFSET": "true"})
+	dialect.(VersionAware).SetVersion(&schemas.Version{Number: "15.0.2000.5"})
+	assert.True(t, legacy.UseLegacyLimitOffset())
+}
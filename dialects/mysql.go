@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/imkos/xorm/core"
@@ -174,6 +175,9 @@ var (
 type mysql struct {
 	Base
 	rowFormat string
+	indexExpr int32 // 1 if INFORMATION_SCHEMA.STATISTICS has the EXPRESSION column, 2 if not, 0 is unknown
+
+	noBackslashEscapes bool // the sql mode NO_BACKSLASH_ESCAPES is enabled
 }
 
 func (db *mysql) Init(uri *URI) error {
@@ -236,7 +240,11 @@ func (db *mysql) Version(ctx context.Context, queryer core.Queryer) (*schemas.Ve
 
 func (db *mysql) Features() *DialectFeatures {
 	return &DialectFeatures{
-		AutoincrMode: IncrAutoincrMode,
+		AutoincrMode:        IncrAutoincrMode,
+		MaxIdentifierLength: 64,
+		UpdateLimit:         true,
+		DeleteLimit:         true,
+		MaxParams:           65535,
 	}
 }
 
@@ -255,6 +263,10 @@ func (db *mysql) SetParams(params map[string]string) {
 			db.rowFormat = t
 		}
 	}
+
+	if noBackslashEscapes, ok := params["NO_BACKSLASH_ESCAPES"]; ok {
+		db.noBackslashEscapes, _ = strconv.ParseBool(noBackslashEscapes)
+	}
 }
 
 func (db *mysql) SQLType(c *schemas.Column) string {
@@ -276,6 +288,10 @@ func (db *mysql) SQLType(c *schemas.Column) string {
 		res = schemas.BigInt
 	case schemas.Bytea:
 		res = schemas.Blob
+	case schemas.Geography:
+		res = schemas.Geometry
+	case schemas.BitSet, schemas.VarBit:
+		res = schemas.Bit
 	case schemas.TimeStampz:
 		res = schemas.Char
 		c.Length = 64
@@ -302,6 +318,9 @@ func (db *mysql) SQLType(c *schemas.Column) string {
 	case schemas.Uuid:
 		res = schemas.Varchar
 		c.Length = 40
+	case schemas.Inet, schemas.Cidr:
+		res = schemas.Varchar
+		c.Length = 64
 	case schemas.Json:
 		res = schemas.Text
 	case schemas.UnsignedInt:
@@ -371,8 +390,40 @@ func (db *mysql) AutoIncrStr() string {
 	return "AUTO_INCREMENT"
 }
 
+func (db *mysql) getSchema() string {
+	if db.uri.Schema != "" {
+		return db.uri.Schema
+	}
+	return db.uri.DBName
+}
+
+// splitTableName splits a cross database table reference like db.table into
+// the database and table name, the current schema will be used if no database given.
+// The dots inside backtick quoted names like `my.db`.`tbl` don't separate the names.
+func (db *mysql) splitTableName(tableName string) (string, string) {
+	var quoted bool
+	for i := 0; i < len(tableName); i++ {
+		switch {
+		case tableName[i] == '`':
+			quoted = !quoted
+		case tableName[i] == '.' && !quoted && i > 0:
+			return trimMysqlName(tableName[:i]), trimMysqlName(tableName[i+1:])
+		}
+	}
+	return db.getSchema(), trimMysqlName(tableName)
+}
+
+// trimMysqlName removes the backticks around name and unescapes the doubled backticks in it
+func trimMysqlName(name string) string {
+	if len(name) < 2 || name[0] != '`' || name[len(name)-1] != '`' {
+		return name
+	}
+	return strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+}
+
 func (db *mysql) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
-	args := []interface{}{db.uri.DBName, tableName, idxName}
+	schema, tableName := db.splitTableName(tableName)
+	args := []interface{}{schema, tableName, idxName}
 	sql := "SELECT `INDEX_NAME` FROM `INFORMATION_SCHEMA`.`STATISTICS`"
 	sql += " WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? AND `INDEX_NAME`=?"
 	return sql, args
@@ -380,7 +431,14 @@ func (db *mysql) IndexCheckSQL(tableName, idxName string) (string, []interface{}
 
 func (db *mysql) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
 	sql := "SELECT `TABLE_NAME` from `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA`=? and `TABLE_NAME`=?"
-	return db.HasRecords(queryer, ctx, sql, db.uri.DBName, tableName)
+	schema, tableName := db.splitTableName(tableName)
+	return db.HasRecords(queryer, ctx, sql, schema, tableName)
+}
+
+func (db *mysql) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
+	sql := "SELECT `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? AND `COLUMN_NAME` = ?"
+	schema, tableName := db.splitTableName(tableName)
+	return db.HasRecords(queryer, ctx, sql, schema, tableName, colName)
 }
 
 func (db *mysql) AddColumnSQL(tableName string, col *schemas.Column) string {
@@ -391,6 +449,9 @@ func (db *mysql) AddColumnSQL(tableName string, col *schemas.Column) string {
 	quoter.QuoteTo(&b, tableName)
 	b.WriteString(" ADD ")
 	b.WriteString(s)
+	if col.IsInvisible {
+		b.WriteString(" INVISIBLE")
+	}
 	if len(col.Comment) > 0 {
 		b.WriteString(" COMMENT '")
 		b.WriteString(col.Comment)
@@ -405,44 +466,123 @@ func (db *mysql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	if col.IsAutoIncrement {
 		s += " " + db.AutoIncrStr()
 	}
+	if col.IsInvisible {
+		s += " INVISIBLE"
+	}
 	if col.Comment != "" {
 		s += fmt.Sprintf(" COMMENT '%s'", col.Comment)
 	}
 	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", db.quoter.Quote(tableName), s)
 }
 
+// CreateIndexSQL returns a SQL to create index, CREATE SPATIAL INDEX is used for spatial index
+func (db *mysql) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	var s string
+	if index.Type != schemas.SpatialType {
+		s = db.Base.CreateIndexSQL(tableName, index)
+	} else {
+		quoter := db.Quoter()
+		s = fmt.Sprintf("CREATE SPATIAL INDEX %v ON %v (%v)",
+			quoter.Quote(index.XName(tableName, db.dialect.Features().MaxIdentifierLength)), quoter.Quote(tableName),
+			quoter.Join(index.Cols, ","))
+	}
+	if index.IsInvisible {
+		s += " INVISIBLE"
+	}
+	return s
+}
+
+func (db *mysql) SetAutoIncrStartSQL(tableName, colName string, start int64) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = %d", db.quoter.Quote(tableName), start)}
+}
+
 func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
-	args := []interface{}{db.uri.DBName, tableName}
+	tablesCols, err := db.GetColumnsOfTables(queryer, ctx, []string{tableName})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := make(map[string]*schemas.Column)
+	colSeq := make([]string, 0)
+	for _, col := range tablesCols[tableName] {
+		cols[col.Name] = col
+		colSeq = append(colSeq, col.Name)
+	}
+	return colSeq, cols, nil
+}
+
+// groupTableNames groups the table names by schema, the values map the bare table names to the given names
+func (db *mysql) groupTableNames(tableNames []string) map[string]map[string]string {
+	groups := make(map[string]map[string]string)
+	for _, name := range tableNames {
+		schema, tableName := db.splitTableName(name)
+		if groups[schema] == nil {
+			groups[schema] = make(map[string]string)
+		}
+		groups[schema][tableName] = name
+	}
+	return groups
+}
+
+// originalTableName returns the given table name of the table name returned by the database,
+// the database may return it with another case according lower_case_table_names
+func originalTableName(names map[string]string, tableName string) string {
+	if name, ok := names[tableName]; ok {
+		return name
+	}
+	for bare, name := range names {
+		if strings.EqualFold(bare, tableName) {
+			return name
+		}
+	}
+	return tableName
+}
+
+// GetColumnsOfTables returns the columns of all the tables with one query per schema
+func (db *mysql) GetColumnsOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string][]*schemas.Column, error) {
+	tablesCols := make(map[string][]*schemas.Column, len(tableNames))
+	for schema, names := range db.groupTableNames(tableNames) {
+		if err := db.getColumnsOfTables(queryer, ctx, schema, names, tablesCols); err != nil {
+			return nil, err
+		}
+	}
+	return tablesCols, nil
+}
+
+func (db *mysql) getColumnsOfTables(queryer core.Queryer, ctx context.Context, schema string, names map[string]string, tablesCols map[string][]*schemas.Column) error {
+	args := []interface{}{schema}
+	for name := range names {
+		args = append(args, name)
+	}
 	alreadyQuoted := "(INSTR(VERSION(), 'maria') > 0 && " +
 		"(SUBSTRING_INDEX(VERSION(), '.', 1) > 10 || " +
 		"(SUBSTRING_INDEX(VERSION(), '.', 1) = 10 && " +
 		"(SUBSTRING_INDEX(SUBSTRING(VERSION(), 4), '.', 1) > 2 || " +
 		"(SUBSTRING_INDEX(SUBSTRING(VERSION(), 4), '.', 1) = 2 && " +
 		"SUBSTRING_INDEX(SUBSTRING(VERSION(), 6), '-', 1) >= 7)))))"
-	s := "SELECT `COLUMN_NAME`, `IS_NULLABLE`, `COLUMN_DEFAULT`, `COLUMN_TYPE`," +
+	s := "SELECT `TABLE_NAME`, `COLUMN_NAME`, `IS_NULLABLE`, `COLUMN_DEFAULT`, `COLUMN_TYPE`," +
 		" `COLUMN_KEY`, `EXTRA`, `COLUMN_COMMENT`, `CHARACTER_MAXIMUM_LENGTH`, " +
 		alreadyQuoted + " AS NEEDS_QUOTE, `COLLATION_NAME` " +
-		"FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?" +
-		" ORDER BY `COLUMNS`.ORDINAL_POSITION ASC"
+		"FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (" +
+		strings.Repeat(",?", len(names))[1:] + ")" +
+		" ORDER BY `TABLE_NAME`, `COLUMNS`.ORDINAL_POSITION ASC"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	defer rows.Close()
 
-	cols := make(map[string]*schemas.Column)
-	colSeq := make([]string, 0)
 	for rows.Next() {
 		col := new(schemas.Column)
 		col.Indexes = make(map[string]int)
 
-		var columnName, nullableStr, colType, colKey, extra, comment string
+		var tableName, columnName, nullableStr, colType, colKey, extra, comment string
 		var alreadyQuoted, isUnsigned bool
 		var colDefault, maxLength, collation *string
-		err = rows.Scan(&columnName, &nullableStr, &colDefault, &colType, &colKey, &extra, &comment, &maxLength, &alreadyQuoted, &collation)
+		err = rows.Scan(&tableName, &columnName, &nullableStr, &colDefault, &colType, &colKey, &extra, &comment, &maxLength, &alreadyQuoted, &collation)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
 		col.Name = strings.Trim(columnName, "` ")
 		col.Comment = comment
@@ -493,12 +633,12 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 				lens := strings.Split(cts[1][0:idx], ",")
 				len1, err = strconv.ParseInt(strings.TrimSpace(lens[0]), 10, 64)
 				if err != nil {
-					return nil, nil, err
+					return err
 				}
 				if len(lens) == 2 {
 					len2, err = strconv.ParseInt(lens[1], 10, 64)
 					if err != nil {
-						return nil, nil, err
+						return err
 					}
 				}
 			}
@@ -507,7 +647,7 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 			case "MEDIUMTEXT", "LONGTEXT", "TEXT":
 				len1, err = strconv.ParseInt(*maxLength, 10, 64)
 				if err != nil {
-					return nil, nil, err
+					return err
 				}
 			}
 		}
@@ -517,7 +657,7 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		col.Length = len1
 		col.Length2 = len2
 		if _, ok := schemas.SqlTypes[colType]; !ok {
-			return nil, nil, fmt.Errorf("unknown colType %v", colType)
+			return fmt.Errorf("unknown colType %v", colType)
 		}
 		col.SQLType = schemas.SQLType{Name: colType, DefaultLength: len1, DefaultLength2: len2}
 
@@ -528,8 +668,13 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		// col.is
 		// }
 
-		if extra == "auto_increment" {
-			col.IsAutoIncrement = true
+		for _, e := range strings.Fields(extra) {
+			switch strings.ToUpper(e) {
+			case "AUTO_INCREMENT":
+				col.IsAutoIncrement = true
+			case "INVISIBLE":
+				col.IsInvisible = true
+			}
 		}
 
 		if !col.DefaultIsEmpty {
@@ -539,18 +684,15 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 				col.Default = "'" + col.Default + "'"
 			}
 		}
-		cols[col.Name] = col
-		colSeq = append(colSeq, col.Name)
-	}
-	if rows.Err() != nil {
-		return nil, nil, rows.Err()
+		tableName = originalTableName(names, tableName)
+		tablesCols[tableName] = append(tablesCols[tableName], col)
 	}
-	return colSeq, cols, nil
+	return rows.Err()
 }
 
 func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
-	args := []interface{}{db.uri.DBName}
-	s := "SELECT `TABLE_NAME`, `ENGINE`, `AUTO_INCREMENT`, `TABLE_COMMENT`, `TABLE_COLLATION` from " +
+	args := []interface{}{db.getSchema()}
+	s := "SELECT `TABLE_NAME`, `ENGINE`, `AUTO_INCREMENT`, `TABLE_COMMENT`, `TABLE_COLLATION`, `CREATE_OPTIONS` from " +
 		"`INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA`=? AND (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB')"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -563,8 +705,8 @@ func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	for rows.Next() {
 		table := schemas.NewEmptyTable()
 		var name, engine, collation string
-		var autoIncr, comment *string
-		err = rows.Scan(&name, &engine, &autoIncr, &comment, &collation)
+		var autoIncr, comment, createOptions *string
+		err = rows.Scan(&name, &engine, &autoIncr, &comment, &collation, &createOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -573,8 +715,14 @@ func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 		if comment != nil {
 			table.Comment = *comment
 		}
+		if autoIncr != nil {
+			table.AutoIncrStart, _ = strconv.ParseInt(*autoIncr, 10, 64)
+		}
 		table.StoreEngine = engine
 		table.Collation = collation
+		if createOptions != nil {
+			table.Options = parseMysqlCreateOptions(*createOptions)
+		}
 		tables = append(tables, table)
 	}
 	if rows.Err() != nil {
@@ -583,6 +731,27 @@ func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	return tables, nil
 }
 
+// parseMysqlCreateOptions parses the CREATE_OPTIONS of INFORMATION_SCHEMA.TABLES,
+// i.e. "row_format=COMPRESSED KEY_BLOCK_SIZE=8 partitioned"
+func parseMysqlCreateOptions(s string) map[string]string {
+	var options map[string]string
+	for _, field := range strings.Fields(s) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		name := strings.ToUpper(strings.Trim(kv[0], "`"))
+		if !mysqlTableOptions[name] {
+			continue
+		}
+		if options == nil {
+			options = make(map[string]string)
+		}
+		options[name] = kv[1]
+	}
+	return options
+}
+
 func (db *mysql) SetQuotePolicy(quotePolicy QuotePolicy) {
 	switch quotePolicy {
 	case QuotePolicyNone:
@@ -601,56 +770,141 @@ func (db *mysql) SetQuotePolicy(quotePolicy QuotePolicy) {
 }
 
 func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
-	args := []interface{}{db.uri.DBName, tableName}
-	s := "SELECT `INDEX_NAME`, `NON_UNIQUE`, `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? ORDER BY `SEQ_IN_INDEX`"
+	tablesIndexes, err := db.GetIndexesOfTables(queryer, ctx, []string{tableName})
+	if err != nil {
+		return nil, err
+	}
+	if indexes, ok := tablesIndexes[tableName]; ok {
+		return indexes, nil
+	}
+	return make(map[string]*schemas.Index), nil
+}
+
+// GetIndexesOfTables returns the indexes of all the tables with one query per schema
+func (db *mysql) GetIndexesOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string]map[string]*schemas.Index, error) {
+	tablesIndexes := make(map[string]map[string]*schemas.Index, len(tableNames))
+	for schema, names := range db.groupTableNames(tableNames) {
+		if err := db.getIndexesOfTables(queryer, ctx, schema, names, tablesIndexes); err != nil {
+			return nil, err
+		}
+	}
+	return tablesIndexes, nil
+}
+
+// supportIndexExpr returns true if the functional key parts are supported, i.e. MySQL 8.0.13+
+// which has the EXPRESSION column in INFORMATION_SCHEMA.STATISTICS
+func (db *mysql) supportIndexExpr(queryer core.Queryer, ctx context.Context) (bool, error) {
+	switch atomic.LoadInt32(&db.indexExpr) {
+	case 1:
+		return true, nil
+	case 2:
+		return false, nil
+	}
+	has, err := db.HasRecords(queryer, ctx, "SELECT `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = 'information_schema' AND `TABLE_NAME` = 'STATISTICS' AND `COLUMN_NAME` = 'EXPRESSION'")
+	if err != nil {
+		return false, err
+	}
+	if has {
+		atomic.StoreInt32(&db.indexExpr, 1)
+	} else {
+		atomic.StoreInt32(&db.indexExpr, 2)
+	}
+	return has, nil
+}
+
+func (db *mysql) getIndexesOfTables(queryer core.Queryer, ctx context.Context, schema string, names map[string]string, tablesIndexes map[string]map[string]*schemas.Index) error {
+	args := []interface{}{schema}
+	for name := range names {
+		args = append(args, name)
+	}
+	// IS_VISIBLE is also added by MySQL 8
+	expr := "NULL, 'YES'"
+	if ok, err := db.supportIndexExpr(queryer, ctx); err != nil {
+		return err
+	} else if ok {
+		expr = "`EXPRESSION`, `IS_VISIBLE`"
+	}
+	s := "SELECT `TABLE_NAME`, `INDEX_NAME`, `NON_UNIQUE`, `INDEX_TYPE`, `COLUMN_NAME`, `COLLATION`, " + expr +
+		" FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (" +
+		strings.Repeat(",?", len(names))[1:] + ") ORDER BY `TABLE_NAME`, `SEQ_IN_INDEX`"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	indexes := make(map[string]*schemas.Index)
 	for rows.Next() {
 		var indexType int
-		var indexName, colName, nonUnique string
-		err = rows.Scan(&indexName, &nonUnique, &colName)
+		var tableName, indexName, nonUnique, idxType, isVisible string
+		var colName, collation, expression sql.NullString
+		err = rows.Scan(&tableName, &indexName, &nonUnique, &idxType, &colName, &collation, &expression, &isVisible)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if indexName == "PRIMARY" {
 			continue
 		}
 
-		if nonUnique == "YES" || nonUnique == "1" {
+		if idxType == "SPATIAL" {
+			indexType = schemas.SpatialType
+		} else if nonUnique == "YES" || nonUnique == "1" {
 			indexType = schemas.IndexType
 		} else {
 			indexType = schemas.UniqueType
 		}
 
-		colName = strings.Trim(colName, "` ")
+		// the column name of a functional key part is NULL
+		keyPart := strings.Trim(colName.String, "` ")
+		if !colName.Valid && expression.Valid {
+			keyPart = "(" + expression.String + ")"
+		}
 		var isRegular bool
-		if strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName) {
-			indexName = indexName[5+len(tableName):]
-			isRegular = true
+		indexName, isRegular = regularIndexName(tableName, indexName)
+
+		oriTableName := originalTableName(names, tableName)
+		indexes, ok := tablesIndexes[oriTableName]
+		if !ok {
+			indexes = make(map[string]*schemas.Index)
+			tablesIndexes[oriTableName] = indexes
 		}
 
 		var index *schemas.Index
-		var ok bool
 		if index, ok = indexes[indexName]; !ok {
 			index = new(schemas.Index)
 			index.IsRegular = isRegular
 			index.Type = indexType
 			index.Name = indexName
+			index.IsInvisible = isVisible == "NO"
 			indexes[indexName] = index
 		}
-		index.AddColumn(colName)
-	}
-	if rows.Err() != nil {
-		return nil, rows.Err()
+		// the collation is A (ascending), D (descending) or NULL (not sorted)
+		if collation.String == "D" {
+			index.AddDescColumn(keyPart)
+		} else {
+			index.AddColumn(keyPart)
+		}
 	}
-	return indexes, nil
+	return rows.Err()
+}
+
+// mysqlTableOptions are the table options of mysql which could be set by TableOptions
+var mysqlTableOptions = map[string]bool{
+	"AUTO_INCREMENT":     true,
+	"AVG_ROW_LENGTH":     true,
+	"CHECKSUM":           true,
+	"COMPRESSION":        true,
+	"DELAY_KEY_WRITE":    true,
+	"ENCRYPTION":         true,
+	"KEY_BLOCK_SIZE":     true,
+	"MAX_ROWS":           true,
+	"MIN_ROWS":           true,
+	"PACK_KEYS":          true,
+	"ROW_FORMAT":         true,
+	"STATS_AUTO_RECALC":  true,
+	"STATS_PERSISTENT":   true,
+	"STATS_SAMPLE_PAGES": true,
 }
 
 func (db *mysql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
@@ -668,6 +922,9 @@ func (db *mysql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 		col := table.GetColumn(colName)
 		s, _ := ColumnString(db.dialect, col, col.IsPrimaryKey && len(table.PrimaryKeys) == 1, true)
 		b.WriteString(s)
+		if col.IsInvisible {
+			b.WriteString(" INVISIBLE")
+		}
 
 		if len(col.Comment) > 0 {
 			b.WriteString(" COMMENT '")
@@ -702,9 +959,24 @@ func (db *mysql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 		b.WriteString(charset)
 	}
 
-	if db.rowFormat != "" {
+	options := filterTableOptions(table.Options, func(name string) bool {
+		return mysqlTableOptions[name]
+	})
+	rowFormat := db.rowFormat
+	for _, opt := range options {
+		if opt.Name == "ROW_FORMAT" {
+			rowFormat = ""
+		}
+	}
+	if rowFormat != "" {
 		b.WriteString(" ROW_FORMAT=")
-		b.WriteString(db.rowFormat)
+		b.WriteString(rowFormat)
+	}
+	for _, opt := range options {
+		b.WriteString(" ")
+		b.WriteString(opt.Name)
+		b.WriteString("=")
+		b.WriteString(opt.Value)
 	}
 
 	if table.Comment != "" {
@@ -0,0 +1,85 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMysqlCreateIndexSQL(t *testing.T) {
+	dialect, err := OpenDialect("mysql", "root:@tcp(localhost:3306)/test")
+	assert.NoError(t, err)
+
+	index := schemas.NewIndex("created", schemas.IndexType)
+	index.AddColumn("user_id")
+	index.AddDescColumn("created")
+	assert.EqualValues(t, "CREATE INDEX `IDX_orders_created` ON `orders` (`user_id`,`created` DESC)",
+		dialect.CreateIndexSQL("orders", index))
+
+	index = schemas.NewIndex("email", schemas.UniqueType)
+	index.AddColumn("(LOWER(`email`))")
+	assert.EqualValues(t, "CREATE UNIQUE INDEX `UQE_users_email` ON `users` ((LOWER(`email`)))",
+		dialect.CreateIndexSQL("users", index))
+}
+
+func TestMysqlInvisible(t *testing.T) {
+	dialect, err := OpenDialect("mysql", "root:@tcp(localhost:3306)/test")
+	assert.NoError(t, err)
+
+	index := schemas.NewIndex("name", schemas.IndexType)
+	index.AddColumn("name")
+	index.IsInvisible = true
+	assert.EqualValues(t, "CREATE INDEX `IDX_users_name` ON `users` (`name`) INVISIBLE",
+		dialect.CreateIndexSQL("users", index))
+
+	col := schemas.NewColumn("secret", "", schemas.SQLType{Name: schemas.Varchar}, 255, 0, true)
+	col.IsInvisible = true
+	assert.EqualValues(t, "ALTER TABLE `users` ADD `secret` VARCHAR(255) NULL INVISIBLE",
+		dialect.AddColumnSQL("users", col))
+}
+
+func TestMysqlSplitTableName(t *testing.T) {
+	dialect, err := OpenDialect("mysql", "root:@tcp(localhost:3306)/test")
+	assert.NoError(t, err)
+	db := dialect.(*mysql)
+
+	var tests = []struct {
+		name   string
+		schema string
+		table  string
+	}{
+		{"users", "test", "users"},
+		{"`users`", "test", "users"},
+		{"sales.orders", "sales", "orders"},
+		{"`sales`.`orders`", "sales", "orders"},
+		{"`my.db`.`tbl`", "my.db", "tbl"},
+		{"`db`.`my.tbl`", "db", "my.tbl"},
+		{"`my``db`.`t.b`", "my`db", "t.b"},
+	}
+	for _, tt := range tests {
+		schema, table := db.splitTableName(tt.name)
+		assert.EqualValues(t, tt.schema, schema, tt.name)
+		assert.EqualValues(t, tt.table, table, tt.name)
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	pgDialect, err := OpenDialect("postgres", "postgres://postgres:@localhost:5432/test")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `'it''s \'`, QuoteLiteral(pgDialect, `it's \`))
+	assert.EqualValues(t, `''`, QuoteLiteral(pgDialect, ""))
+
+	mysqlDialect, err := OpenDialect("mysql", "root:@tcp(localhost:3306)/test")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `'it''s \\'`, QuoteLiteral(mysqlDialect, `it's \`))
+	assert.EqualValues(t, `'\\'' OR 1=1'`, QuoteLiteral(mysqlDialect, `\' OR 1=1`))
+
+	mysqlDialect.SetParams(map[string]string{"NO_BACKSLASH_ESCAPES": "true"})
+	assert.EqualValues(t, `'it''s \'`, QuoteLiteral(mysqlDialect, `it's \`))
+}
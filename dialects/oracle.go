@@ -553,7 +553,9 @@ func (db *oracle) Version(ctx context.Context, queryer core.Queryer) (*schemas.V
 
 func (db *oracle) Features() *DialectFeatures {
 	return &DialectFeatures{
-		AutoincrMode: SequenceAutoincrMode,
+		AutoincrMode:        SequenceAutoincrMode,
+		MaxIdentifierLength: 30,
+		MaxParams:           65535,
 	}
 }
 
@@ -567,6 +569,8 @@ func (db *oracle) SQLType(c *schemas.Column) string {
 			c.Default = "0"
 		}
 		res = "NUMBER(1,0)"
+	case schemas.BitSet, schemas.VarBit:
+		return "NUMBER(20)"
 	case schemas.Bit, schemas.TinyInt, schemas.SmallInt, schemas.MediumInt, schemas.Int, schemas.Integer, schemas.BigInt, schemas.Serial, schemas.BigSerial:
 		res = "NUMBER"
 	case schemas.Binary, schemas.VarBinary, schemas.Blob, schemas.TinyBlob, schemas.MediumBlob, schemas.LongBlob, schemas.Bytea:
@@ -581,6 +585,9 @@ func (db *oracle) SQLType(c *schemas.Column) string {
 		res = "CLOB"
 	case schemas.Char, schemas.Varchar, schemas.TinyText:
 		res = "VARCHAR2"
+	case schemas.Inet, schemas.Cidr:
+		res = "VARCHAR2"
+		c.Length = 64
 	default:
 		res = t
 	}
@@ -640,8 +647,7 @@ func (db *oracle) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 		/*if col.IsPrimaryKey && len(pkList) == 1 {
 			sql += col.String(b.dialect)
 		} else {*/
-		s, _ := ColumnString(db, col, false, false)
-		sql += s
+		sql += db.columnString(col)
 		// }
 		sql = strings.TrimSpace(sql)
 		sql += ", "
@@ -657,6 +663,25 @@ func (db *oracle) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 	return sql, false, nil
 }
 
+// columnString returns the definition of the column, INVISIBLE follows the column type
+func (db *oracle) columnString(col *schemas.Column) string {
+	s, _ := ColumnString(db, col, false, false)
+	if !col.IsInvisible {
+		return s
+	}
+	prefix := db.Quoter().Quote(col.Name) + " " + db.SQLType(col)
+	return prefix + " INVISIBLE" + s[len(prefix):]
+}
+
+// CreateIndexSQL returns a SQL to create index, an invisible index is ignored by the optimizer
+func (db *oracle) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	s := db.Base.CreateIndexSQL(tableName, index)
+	if index.IsInvisible {
+		s += " INVISIBLE"
+	}
+	return s
+}
+
 func (db *oracle) IsSequenceExist(ctx context.Context, queryer core.Queryer, seqName string) (bool, error) {
 	return db.HasRecords(queryer, ctx, `SELECT sequence_name FROM user_sequences WHERE sequence_name = :1`, seqName)
 }
@@ -688,7 +713,7 @@ func (db *oracle) DropIndexSQL(tableName string, index *schemas.Index) string {
 	quote := db.dialect.Quoter().Quote
 	var name string
 	if index.IsRegular {
-		name = index.XName(tableName)
+		name = index.XName(tableName, db.dialect.Features().MaxIdentifierLength)
 	} else {
 		name = index.Name
 	}
@@ -708,8 +733,9 @@ func (db *oracle) IsColumnExist(queryer core.Queryer, ctx context.Context, table
 
 func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
 	args := []interface{}{tableName}
+	// USER_TAB_COLUMNS excludes the invisible columns, so the user generated columns of USER_TAB_COLS are read
 	s := "SELECT column_name,data_default,data_type,data_length,data_precision,data_scale," +
-		"nullable FROM USER_TAB_COLUMNS WHERE table_name = :1"
+		"nullable,hidden_column FROM USER_TAB_COLS WHERE table_name = :1 AND user_generated = 'YES' ORDER BY column_id"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -723,14 +749,15 @@ func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 		col := new(schemas.Column)
 		col.Indexes = make(map[string]int)
 
-		var colName, colDefault, nullable, dataType, dataPrecision, dataScale *string
+		var colName, colDefault, nullable, dataType, dataPrecision, dataScale, hidden *string
 		var dataLen int64
 
 		err = rows.Scan(&colName, &colDefault, &dataType, &dataLen, &dataPrecision,
-			&dataScale, &nullable)
+			&dataScale, &nullable, &hidden)
 		if err != nil {
 			return nil, nil, err
 		}
+		col.IsInvisible = hidden != nil && *hidden == "YES"
 
 		col.Name = strings.Trim(*colName, `" `)
 		if colDefault != nil {
@@ -834,7 +861,7 @@ func (db *oracle) GetTables(queryer core.Queryer, ctx context.Context) ([]*schem
 
 func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
-	s := "SELECT t.column_name,i.uniqueness,i.index_name FROM user_ind_columns t,user_indexes i " +
+	s := "SELECT t.column_name,i.uniqueness,i.index_name,i.visibility FROM user_ind_columns t,user_indexes i " +
 		"WHERE t.index_name = i.index_name and t.table_name = i.table_name and t.table_name =:1"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -846,9 +873,9 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 	indexes := make(map[string]*schemas.Index)
 	for rows.Next() {
 		var indexType int
-		var indexName, colName, uniqueness string
+		var indexName, colName, uniqueness, visibility string
 
-		err = rows.Scan(&colName, &uniqueness, &indexName)
+		err = rows.Scan(&colName, &uniqueness, &indexName, &visibility)
 		if err != nil {
 			return nil, err
 		}
@@ -856,10 +883,7 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 		indexName = strings.Trim(indexName, `" `)
 
 		var isRegular bool
-		if strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName) {
-			indexName = indexName[5+len(tableName):]
-			isRegular = true
-		}
+		indexName, isRegular = regularIndexName(tableName, indexName)
 
 		if uniqueness == "UNIQUE" {
 			indexType = schemas.UniqueType
@@ -874,6 +898,7 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 			index.Type = indexType
 			index.Name = indexName
 			index.IsRegular = isRegular
+			index.IsInvisible = visibility == "INVISIBLE"
 			indexes[indexName] = index
 		}
 		index.AddColumn(colName)
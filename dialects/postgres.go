@@ -912,6 +912,8 @@ func (db *postgres) SQLType(c *schemas.Column) string {
 	case schemas.Bit:
 		res = schemas.Boolean
 		return res
+	case schemas.BitSet:
+		res = schemas.VarBit
 	case schemas.MediumInt, schemas.Int, schemas.Integer, schemas.UnsignedMediumInt, schemas.UnsignedSmallInt:
 		if c.IsAutoIncrement {
 			return schemas.Serial
@@ -942,6 +944,8 @@ func (db *postgres) SQLType(c *schemas.Column) string {
 		res = schemas.Varchar
 	case schemas.Uuid:
 		return schemas.Uuid
+	case schemas.Inet, schemas.Cidr:
+		return t
 	case schemas.Blob, schemas.TinyBlob, schemas.MediumBlob, schemas.LongBlob:
 		return schemas.Bytea
 	case schemas.Double, schemas.UnsignedFloat:
@@ -970,7 +974,11 @@ func (db *postgres) SQLType(c *schemas.Column) string {
 
 func (db *postgres) Features() *DialectFeatures {
 	return &DialectFeatures{
-		AutoincrMode: IncrAutoincrMode,
+		AutoincrMode:        IncrAutoincrMode,
+		MaxIdentifierLength: 63,
+		UpdateLimit:         true,
+		DeleteLimit:         true,
+		MaxParams:           65535,
 	}
 }
 
@@ -978,7 +986,7 @@ func (db *postgres) ColumnTypeKind(t string) int {
 	switch strings.ToUpper(t) {
 	case "DATETIME", "TIMESTAMP":
 		return schemas.TIME_TYPE
-	case "VARCHAR", "TEXT":
+	case "VARCHAR", "TEXT", "INET", "CIDR":
 		return schemas.TEXT_TYPE
 	case "BIGINT", "BIGSERIAL", "SMALLINT", "INT", "INT8", "INT4", "INTEGER", "SERIAL", "FLOAT", "FLOAT4", "REAL", "DOUBLE PRECISION":
 		return schemas.NUMERIC_TYPE
@@ -1035,6 +1043,17 @@ func (db *postgres) AddColumnSQL(tableName string, col *schemas.Column) string {
 	return addColumnSQL + commentSQL
 }
 
+// CreateIndexSQL returns a SQL to create index, a spatial index is created with GiST
+func (db *postgres) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	if index.Type != schemas.SpatialType {
+		return db.Base.CreateIndexSQL(tableName, index)
+	}
+	quoter := db.Quoter()
+	return fmt.Sprintf("CREATE INDEX %v ON %v USING GIST (%v)",
+		quoter.Quote(index.XName(tableName, db.dialect.Features().MaxIdentifierLength)), quoter.Quote(tableName),
+		quoter.Join(index.Cols, ","))
+}
+
 func (db *postgres) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	quoter := db.dialect.Quoter()
 	modifyColumnSQL := ""
@@ -1058,11 +1077,7 @@ func (db *postgres) DropIndexSQL(tableName string, index *schemas.Index) string
 	tableName = tableParts[len(tableParts)-1]
 
 	if index.IsRegular {
-		if index.Type == schemas.UniqueType && !strings.HasPrefix(idxName, "UQE_") {
-			idxName = fmt.Sprintf("UQE_%v_%v", tableName, index.Name)
-		} else if index.Type == schemas.IndexType && !strings.HasPrefix(idxName, "IDX_") {
-			idxName = fmt.Sprintf("IDX_%v_%v", tableName, index.Name)
-		}
+		idxName = index.XName(tableName, db.Features().MaxIdentifierLength)
 	}
 	if db.getSchema() != "" {
 		idxName = db.getSchema() + "." + idxName
@@ -1093,8 +1108,35 @@ func (db *postgres) IsColumnExist(queryer core.Queryer, ctx context.Context, tab
 }
 
 func (db *postgres) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
-	args := []interface{}{tableName}
-	s := `SELECT column_name, column_default, is_nullable, data_type, character_maximum_length, description,
+	tablesCols, err := db.GetColumnsOfTables(queryer, ctx, []string{tableName})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := make(map[string]*schemas.Column)
+	colSeq := make([]string, 0)
+	for _, col := range tablesCols[tableName] {
+		cols[col.Name] = col
+		colSeq = append(colSeq, col.Name)
+	}
+	return colSeq, cols, nil
+}
+
+// tableNamesArgs returns the placeholders and the arguments of the table names
+func tableNamesArgs(tableNames []string) (string, []interface{}) {
+	placeholders := make([]string, 0, len(tableNames))
+	args := make([]interface{}, 0, len(tableNames)+1)
+	for i, name := range tableNames {
+		placeholders = append(placeholders, "$"+strconv.Itoa(i+1))
+		args = append(args, name)
+	}
+	return strings.Join(placeholders, ","), args
+}
+
+// GetColumnsOfTables returns the columns of all the tables with one query
+func (db *postgres) GetColumnsOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string][]*schemas.Column, error) {
+	placeholders, args := tableNamesArgs(tableNames)
+	s := `SELECT c.relname, column_name, column_default, is_nullable, data_type, character_maximum_length, description,
     CASE WHEN p.contype = 'p' THEN true ELSE false END AS primarykey,
     CASE WHEN p.contype = 'u' THEN true ELSE false END AS uniquekey
 FROM pg_attribute f
@@ -1105,42 +1147,40 @@ FROM pg_attribute f
     LEFT JOIN pg_constraint p ON p.conrelid = c.oid AND f.attnum = ANY (p.conkey)
     LEFT JOIN pg_class AS g ON p.confrelid = g.oid
     LEFT JOIN INFORMATION_SCHEMA.COLUMNS s ON s.column_name=f.attname AND c.relname=s.table_name
-WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.attnum > 0 ORDER BY f.attnum;`
+WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname IN (%s)%s AND f.attnum > 0 ORDER BY c.relname, f.attnum;`
 
 	schema := db.getSchema()
 	if schema != "" {
-		s = fmt.Sprintf(s, " AND s.table_schema = $2")
+		s = fmt.Sprintf(s, placeholders, " AND s.table_schema = $"+strconv.Itoa(len(args)+1))
 		args = append(args, schema)
 	} else {
-		s = fmt.Sprintf(s, "")
+		s = fmt.Sprintf(s, placeholders, "")
 	}
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	cols := make(map[string]*schemas.Column)
-	colSeq := make([]string, 0)
-
+	tablesCols := make(map[string][]*schemas.Column, len(tableNames))
 	for rows.Next() {
 		col := new(schemas.Column)
 		col.Indexes = make(map[string]int)
 
-		var colName, isNullable, dataType string
+		var tableName, colName, isNullable, dataType string
 		var maxLenStr, colDefault, description *string
 		var isPK, isUnique bool
-		err = rows.Scan(&colName, &colDefault, &isNullable, &dataType, &maxLenStr, &description, &isPK, &isUnique)
+		err = rows.Scan(&tableName, &colName, &colDefault, &isNullable, &dataType, &maxLenStr, &description, &isPK, &isUnique)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		var maxLen int64
 		if maxLenStr != nil {
 			maxLen, err = strconv.ParseInt(*maxLenStr, 10, 64)
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 		}
 
@@ -1208,6 +1248,8 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.a
 			col.SQLType = schemas.SQLType{Name: schemas.BigInt, DefaultLength: 0, DefaultLength2: 0}
 		case "array":
 			col.SQLType = schemas.SQLType{Name: schemas.Array, DefaultLength: 0, DefaultLength2: 0}
+		case "bit varying":
+			col.SQLType = schemas.SQLType{Name: schemas.VarBit, DefaultLength: 0, DefaultLength2: 0}
 		default:
 			startIdx := strings.Index(strings.ToLower(dataType), "string(")
 			if startIdx != -1 && strings.HasSuffix(dataType, ")") {
@@ -1219,7 +1261,7 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.a
 			}
 		}
 		if _, ok := schemas.SqlTypes[col.SQLType.Name]; !ok {
-			return nil, nil, fmt.Errorf("unknown colType: %s - %s", dataType, col.SQLType.Name)
+			return nil, fmt.Errorf("unknown colType: %s - %s", dataType, col.SQLType.Name)
 		}
 
 		col.Length = maxLen
@@ -1235,14 +1277,12 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.a
 				col.Default = strings.TrimSuffix(col.Default, "::timestamp without time zone")
 			}
 		}
-		cols[col.Name] = col
-		colSeq = append(colSeq, col.Name)
+		tablesCols[tableName] = append(tablesCols[tableName], col)
 	}
 	if rows.Err() != nil {
-		return nil, nil, rows.Err()
+		return nil, rows.Err()
 	}
-
-	return colSeq, cols, nil
+	return tablesCols, nil
 }
 
 func (db *postgres) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
@@ -1274,9 +1314,56 @@ func (db *postgres) GetTables(queryer core.Queryer, ctx context.Context) ([]*sch
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
+	rows.Close()
+
+	if schema != "" {
+		if err := db.loadAutoIncrStarts(queryer, ctx, schema, tables); err != nil {
+			return nil, err
+		}
+	}
 	return tables, nil
 }
 
+// loadAutoIncrStarts loads the next values of the sequences owned by the tables
+func (db *postgres) loadAutoIncrStarts(queryer core.Queryer, ctx context.Context, schema string, tables []*schemas.Table) error {
+	s := `SELECT t.relname, COALESCE(s.last_value + s.increment_by, s.start_value) FROM pg_class t
+INNER JOIN pg_namespace n ON n.oid = t.relnamespace
+INNER JOIN pg_depend d ON d.refobjid = t.oid AND d.classid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+INNER JOIN pg_class sc ON sc.oid = d.objid AND sc.relkind = 'S'
+INNER JOIN pg_sequences s ON s.schemaname = n.nspname AND s.sequencename = sc.relname
+WHERE n.nspname = $1`
+	rows, err := queryer.QueryContext(ctx, s, schema)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	starts := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var start int64
+		if err := rows.Scan(&name, &start); err != nil {
+			return err
+		}
+		starts[name] = start
+	}
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+
+	for _, table := range tables {
+		if start, ok := starts[table.Name]; ok {
+			table.AutoIncrStart = start
+		}
+	}
+	return nil
+}
+
+func (db *postgres) SetAutoIncrStartSQL(tableName, colName string, start int64) []string {
+	return []string{fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), %d, false)",
+		strings.ReplaceAll(db.quoter.Quote(tableName), "'", "''"), strings.ReplaceAll(colName, "'", "''"), start)}
+}
+
 func getIndexColName(indexdef string) []string {
 	var colNames []string
 
@@ -1289,11 +1376,23 @@ func getIndexColName(indexdef string) []string {
 }
 
 func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
-	args := []interface{}{tableName}
-	s := "SELECT indexname, indexdef FROM pg_indexes WHERE tablename=$1"
+	tablesIndexes, err := db.GetIndexesOfTables(queryer, ctx, []string{tableName})
+	if err != nil {
+		return nil, err
+	}
+	if indexes, ok := tablesIndexes[tableName]; ok {
+		return indexes, nil
+	}
+	return make(map[string]*schemas.Index), nil
+}
+
+// GetIndexesOfTables returns the indexes of all the tables with one query
+func (db *postgres) GetIndexesOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string]map[string]*schemas.Index, error) {
+	placeholders, args := tableNamesArgs(tableNames)
+	s := "SELECT tablename, indexname, indexdef FROM pg_indexes WHERE tablename IN (" + placeholders + ")"
 	if len(db.getSchema()) != 0 {
 		args = append(args, db.getSchema())
-		s += " AND schemaname=$2"
+		s += " AND schemaname=$" + strconv.Itoa(len(args))
 	}
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -1302,12 +1401,12 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 	}
 	defer rows.Close()
 
-	indexes := make(map[string]*schemas.Index)
+	tablesIndexes := make(map[string]map[string]*schemas.Index, len(tableNames))
 	for rows.Next() {
 		var indexType int
-		var indexName, indexdef string
+		var tableName, indexName, indexdef string
 		var colNames []string
-		err = rows.Scan(&indexName, &indexdef)
+		err = rows.Scan(&tableName, &indexName, &indexdef)
 		if err != nil {
 			return nil, err
 		}
@@ -1322,6 +1421,8 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 		}
 		if strings.HasPrefix(indexdef, "CREATE UNIQUE INDEX") {
 			indexType = schemas.UniqueType
+		} else if strings.Contains(indexdef, " USING gist ") {
+			indexType = schemas.SpatialType
 		} else {
 			indexType = schemas.IndexType
 		}
@@ -1333,13 +1434,7 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 		}
 
 		var isRegular bool
-		if strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName) {
-			newIdxName := indexName[5+len(tableName):]
-			isRegular = true
-			if newIdxName != "" {
-				indexName = newIdxName
-			}
-		}
+		indexName, isRegular = regularIndexName(tableName, indexName)
 
 		index := &schemas.Index{Name: indexName, Type: indexType, Cols: make([]string, 0)}
 		for _, colName := range colNames {
@@ -1348,12 +1443,30 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 			index.Cols = append(index.Cols, fields[0])
 		}
 		index.IsRegular = isRegular
+
+		indexes, ok := tablesIndexes[tableName]
+		if !ok {
+			indexes = make(map[string]*schemas.Index)
+			tablesIndexes[tableName] = indexes
+		}
 		indexes[index.Name] = index
 	}
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return indexes, nil
+	return tablesIndexes, nil
+}
+
+// postgresTableOptions are the storage parameters of postgres which could be set by TableOptions,
+// the parameters beginning with autovacuum_ and toast. are also accepted
+var postgresTableOptions = map[string]bool{
+	"FILLFACTOR":                  true,
+	"PARALLEL_WORKERS":            true,
+	"TOAST_TUPLE_TARGET":          true,
+	"USER_CATALOG_TABLE":          true,
+	"VACUUM_INDEX_CLEANUP":        true,
+	"VACUUM_TRUNCATE":             true,
+	"LOG_AUTOVACUUM_MIN_DURATION": true,
 }
 
 func (db *postgres) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
@@ -1367,6 +1480,18 @@ func (db *postgres) CreateTableSQL(ctx context.Context, queryer core.Queryer, ta
 		return "", ok, err
 	}
 
+	// the storage parameters
+	options := filterTableOptions(table.Options, func(name string) bool {
+		return postgresTableOptions[name] || strings.HasPrefix(name, "AUTOVACUUM_") || strings.HasPrefix(name, "TOAST.")
+	})
+	if len(options) > 0 {
+		params := make([]string, 0, len(options))
+		for _, opt := range options {
+			params = append(params, strings.ToLower(opt.Name)+"="+opt.Value)
+		}
+		createTableSQL += " WITH (" + strings.Join(params, ", ") + ")"
+	}
+
 	commentSQL := "; "
 	if table.Comment != "" {
 		// support schema.table -> "schema"."table"
@@ -1524,7 +1649,7 @@ func (p *pqDriver) Parse(driverName, dataSourceName string) (*URI, error) {
 
 func (p *pqDriver) GenScanResult(colType string) (interface{}, error) {
 	switch colType {
-	case "VARCHAR", "TEXT":
+	case "VARCHAR", "TEXT", "INET", "CIDR":
 		var s sql.NullString
 		return &s, nil
 	case "BIGINT", "BIGSERIAL":
@@ -4,6 +4,8 @@
 
 package dialects
 
+import "strings"
+
 // QuotePolicy describes quote handle policy
 type QuotePolicy int
 
@@ -13,3 +15,13 @@ const (
 	QuotePolicyNone
 	QuotePolicyReserved
 )
+
+// QuoteLiteral returns the string as a string literal of the database, the single quotes in it
+// are doubled. The backslashes are escaped for MySQL too, unless its param NO_BACKSLASH_ESCAPES
+// is true, which should be given if the sql mode NO_BACKSLASH_ESCAPES is enabled.
+func QuoteLiteral(dialect Dialect, s string) string {
+	if db, ok := dialect.(*mysql); ok && !db.noBackslashEscapes {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
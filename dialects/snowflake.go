@@ -0,0 +1,668 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/imkos/xorm/core"
+	"github.com/imkos/xorm/schemas"
+)
+
+func init() {
+	RegisterDriver("snowflake", &snowflakeDriver{})
+	RegisterDialect(schemas.SNOWFLAKE, func() Dialect {
+		return &snowflake{}
+	})
+}
+
+var (
+	snowflakeReservedWords = map[string]bool{
+		"ACCOUNT":           true,
+		"ALL":               true,
+		"ALTER":             true,
+		"AND":               true,
+		"ANY":               true,
+		"AS":                true,
+		"BETWEEN":           true,
+		"BY":                true,
+		"CASE":              true,
+		"CAST":              true,
+		"CHECK":             true,
+		"COLUMN":            true,
+		"CONNECT":           true,
+		"CONNECTION":        true,
+		"CONSTRAINT":        true,
+		"CREATE":            true,
+		"CROSS":             true,
+		"CURRENT":           true,
+		"CURRENT_DATE":      true,
+		"CURRENT_TIME":      true,
+		"CURRENT_TIMESTAMP": true,
+		"CURRENT_USER":      true,
+		"DATABASE":          true,
+		"DELETE":            true,
+		"DISTINCT":          true,
+		"DROP":              true,
+		"ELSE":              true,
+		"EXISTS":            true,
+		"FALSE":             true,
+		"FOLLOWING":         true,
+		"FOR":               true,
+		"FROM":              true,
+		"FULL":              true,
+		"GRANT":             true,
+		"GROUP":             true,
+		"GSCLUSTER":         true,
+		"HAVING":            true,
+		"ILIKE":             true,
+		"IN":                true,
+		"INCREMENT":         true,
+		"INNER":             true,
+		"INSERT":            true,
+		"INTERSECT":         true,
+		"INTO":              true,
+		"IS":                true,
+		"ISSUE":             true,
+		"JOIN":              true,
+		"LATERAL":           true,
+		"LEFT":              true,
+		"LIKE":              true,
+		"LOCALTIME":         true,
+		"LOCALTIMESTAMP":    true,
+		"MINUS":             true,
+		"NATURAL":           true,
+		"NOT":               true,
+		"NULL":              true,
+		"OF":                true,
+		"ON":                true,
+		"OR":                true,
+		"ORDER":             true,
+		"ORGANIZATION":      true,
+		"QUALIFY":           true,
+		"REGEXP":            true,
+		"REVOKE":            true,
+		"RIGHT":             true,
+		"RLIKE":             true,
+		"ROW":               true,
+		"ROWS":              true,
+		"SAMPLE":            true,
+		"SCHEMA":            true,
+		"SELECT":            true,
+		"SET":               true,
+		"SOME":              true,
+		"START":             true,
+		"TABLE":             true,
+		"TABLESAMPLE":       true,
+		"THEN":              true,
+		"TO":                true,
+		"TRIGGER":           true,
+		"TRUE":              true,
+		"TRY_CAST":          true,
+		"UNION":             true,
+		"UNIQUE":            true,
+		"UPDATE":            true,
+		"USING":             true,
+		"VALUES":            true,
+		"VIEW":              true,
+		"WHEN":              true,
+		"WHENEVER":          true,
+		"WHERE":             true,
+		"WITH":              true,
+	}
+
+	snowflakeQuoter = schemas.Quoter{
+		Prefix:     '"',
+		Suffix:     '"',
+		IsReserved: schemas.AlwaysReserve,
+	}
+)
+
+type snowflake struct {
+	Base
+}
+
+func (db *snowflake) Init(uri *URI) error {
+	db.quoter = snowflakeQuoter
+	return db.Base.Init(db, uri)
+}
+
+func (db *snowflake) Version(ctx context.Context, queryer core.Queryer) (*schemas.Version, error) {
+	rows, err := queryer.QueryContext(ctx, "SELECT CURRENT_VERSION()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var version string
+	if !rows.Next() {
+		if rows.Err() != nil {
+			return nil, rows.Err()
+		}
+		return nil, errors.New("unknow version")
+	}
+
+	if err := rows.Scan(&version); err != nil {
+		return nil, err
+	}
+	return &schemas.Version{
+		Number:  version,
+		Edition: "snowflake",
+	}, nil
+}
+
+func (db *snowflake) Features() *DialectFeatures {
+	return &DialectFeatures{
+		AutoincrMode:        IncrAutoincrMode,
+		SupportQualify:      true,
+		MaxIdentifierLength: 255,
+	}
+}
+
+func (db *snowflake) SetQuotePolicy(quotePolicy QuotePolicy) {
+	switch quotePolicy {
+	case QuotePolicyNone:
+		q := snowflakeQuoter
+		q.IsReserved = schemas.AlwaysNoReserve
+		db.quoter = q
+	case QuotePolicyReserved:
+		q := snowflakeQuoter
+		q.IsReserved = db.IsReserved
+		db.quoter = q
+	case QuotePolicyAlways:
+		fallthrough
+	default:
+		db.quoter = snowflakeQuoter
+	}
+}
+
+func (db *snowflake) SQLType(c *schemas.Column) string {
+	var res string
+	switch t := c.SQLType.Name; t {
+	case schemas.Bool, schemas.Boolean:
+		return schemas.Boolean
+	case schemas.Bit, schemas.TinyInt, schemas.UnsignedTinyInt, schemas.SmallInt,
+		schemas.UnsignedSmallInt, schemas.MediumInt, schemas.UnsignedMediumInt, schemas.Int,
+		schemas.UnsignedInt, schemas.Integer, schemas.BigInt, schemas.UnsignedBigInt,
+		schemas.BitSet, schemas.VarBit:
+		res = "NUMBER(38,0)"
+	case schemas.Serial, schemas.BigSerial:
+		c.IsAutoIncrement = true
+		c.Nullable = false
+		res = "NUMBER(38,0)"
+	case schemas.Float, schemas.Double, schemas.Real:
+		return "FLOAT"
+	case schemas.Decimal, schemas.Numeric, schemas.Money:
+		if c.Length > 0 {
+			return fmt.Sprintf("NUMBER(%d,%d)", c.Length, c.Length2)
+		}
+		return "NUMBER(38,0)"
+	case schemas.Char, schemas.NChar, schemas.Varchar, schemas.NVarchar:
+		if c.Length > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", c.Length)
+		}
+		return schemas.Varchar
+	case schemas.TinyText, schemas.Text, schemas.MediumText, schemas.LongText, schemas.Clob,
+		schemas.Uuid, schemas.Enum, schemas.Set, schemas.Inet, schemas.Cidr:
+		return schemas.Varchar
+	case schemas.Json, schemas.Jsonb:
+		return "VARIANT"
+	case schemas.Array:
+		return schemas.Array
+	case schemas.Date:
+		return schemas.Date
+	case schemas.Time:
+		return schemas.Time
+	case schemas.DateTime, schemas.TimeStamp:
+		return "TIMESTAMP_NTZ"
+	case schemas.TimeStampz:
+		return "TIMESTAMP_TZ"
+	case schemas.TinyBlob, schemas.Blob, schemas.MediumBlob, schemas.LongBlob, schemas.Bytea,
+		schemas.Binary, schemas.VarBinary:
+		return schemas.Binary
+	case schemas.Geometry, schemas.Geography:
+		return schemas.Geography
+	default:
+		res = t
+	}
+	if c.IsAutoIncrement {
+		res += " AUTOINCREMENT"
+	}
+	return res
+}
+
+func (db *snowflake) ColumnTypeKind(t string) int {
+	switch strings.ToUpper(schemas.SQLTypeName(t)) {
+	case "DATE", "TIME", "DATETIME", "TIMESTAMP", "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		return schemas.TIME_TYPE
+	case "VARCHAR", "CHAR", "CHARACTER", "STRING", "TEXT", "VARIANT", "OBJECT", "ARRAY":
+		return schemas.TEXT_TYPE
+	case "NUMBER", "DECIMAL", "NUMERIC", "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT",
+		"BYTEINT", "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "REAL", "BOOLEAN":
+		return schemas.NUMERIC_TYPE
+	case "BINARY", "VARBINARY":
+		return schemas.BLOB_TYPE
+	default:
+		return schemas.UNKNOW_TYPE
+	}
+}
+
+func (db *snowflake) IsReserved(name string) bool {
+	_, ok := snowflakeReservedWords[strings.ToUpper(name)]
+	return ok
+}
+
+// AutoIncrStr returns empty since AUTOINCREMENT is written as a part of the column type
+func (db *snowflake) AutoIncrStr() string {
+	return ""
+}
+
+// schemaCond returns the condition of TABLE_SCHEMA, the current schema of the session is
+// used if there is no schema in the data source name
+func (db *snowflake) schemaCond() (string, []interface{}) {
+	if db.uri.Schema != "" {
+		return "TABLE_SCHEMA = ?", []interface{}{db.uri.Schema}
+	}
+	return "TABLE_SCHEMA = CURRENT_SCHEMA()", nil
+}
+
+// IndexCheckSQL returns a SQL which has no records since snowflake has no indexes
+func (db *snowflake) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
+	return "SELECT 1 WHERE 1 = 0", nil
+}
+
+// CreateIndexSQL returns empty since snowflake has no indexes
+func (db *snowflake) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	return ""
+}
+
+// DropIndexSQL returns empty since snowflake has no indexes
+func (db *snowflake) DropIndexSQL(tableName string, index *schemas.Index) string {
+	return ""
+}
+
+// GetIndexes returns no indexes since snowflake has no indexes
+func (db *snowflake) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
+	return map[string]*schemas.Index{}, nil
+}
+
+func (db *snowflake) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
+	cond, args := db.schemaCond()
+	return db.HasRecords(queryer, ctx, "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE "+cond+" AND TABLE_NAME = ?",
+		append(args, tableName)...)
+}
+
+func (db *snowflake) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
+	cond, args := db.schemaCond()
+	return db.HasRecords(queryer, ctx, "SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE "+cond+" AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+		append(args, tableName, colName)...)
+}
+
+// columnString returns the definition of the column in the order of snowflake, the primary
+// keys are always defined as a table constraint
+func (db *snowflake) columnString(col *schemas.Column) string {
+	var b strings.Builder
+	_ = db.dialect.Quoter().QuoteTo(&b, col.Name)
+	b.WriteByte(' ')
+	b.WriteString(db.SQLType(col))
+	if col.Comment != "" {
+		b.WriteString(" COMMENT ")
+		b.WriteString(quoteSnowflakeString(col.Comment))
+	}
+	if !col.DefaultIsEmpty && !col.IsAutoIncrement {
+		b.WriteString(" DEFAULT ")
+		if col.Default == "" {
+			b.WriteString("''")
+		} else {
+			b.WriteString(col.Default)
+		}
+	}
+	if col.Nullable {
+		b.WriteString(" NULL")
+	} else {
+		b.WriteString(" NOT NULL")
+	}
+	return b.String()
+}
+
+func quoteSnowflakeString(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", "''") + "'"
+}
+
+func (db *snowflake) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
+	if tableName == "" {
+		tableName = table.Name
+	}
+
+	quoter := db.dialect.Quoter()
+	var b strings.Builder
+	b.WriteString("CREATE TABLE IF NOT EXISTS ")
+	if err := quoter.QuoteTo(&b, tableName); err != nil {
+		return "", false, err
+	}
+	b.WriteString(" (")
+
+	for i, colName := range table.ColumnsSeq() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(db.columnString(table.GetColumn(colName)))
+	}
+
+	if len(table.PrimaryKeys) > 0 {
+		b.WriteString(", PRIMARY KEY (")
+		b.WriteString(quoter.Join(table.PrimaryKeys, ","))
+		b.WriteString(")")
+	}
+	b.WriteString(")")
+
+	if table.Comment != "" {
+		b.WriteString(" COMMENT = ")
+		b.WriteString(quoteSnowflakeString(table.Comment))
+	}
+	return b.String(), true, nil
+}
+
+func (db *snowflake) AddColumnSQL(tableName string, col *schemas.Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", db.dialect.Quoter().Quote(tableName), db.columnString(col))
+}
+
+func (db *snowflake) ModifyColumnSQL(tableName string, col *schemas.Column) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DATA TYPE %s", quoter.Quote(tableName),
+		quoter.Quote(col.Name), strings.TrimSuffix(db.SQLType(col), " AUTOINCREMENT"))
+}
+
+func (db *snowflake) CreateSequenceSQL(ctx context.Context, queryer core.Queryer, seqName string) (string, error) {
+	return fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s START = 1 INCREMENT = 1", db.dialect.Quoter().Quote(seqName)), nil
+}
+
+func (db *snowflake) IsSequenceExist(ctx context.Context, queryer core.Queryer, seqName string) (bool, error) {
+	cond, args := db.schemaCond()
+	cond = strings.Replace(cond, "TABLE_SCHEMA", "SEQUENCE_SCHEMA", 1)
+	return db.HasRecords(queryer, ctx, "SELECT SEQUENCE_NAME FROM INFORMATION_SCHEMA.SEQUENCES WHERE "+cond+" AND SEQUENCE_NAME = ?",
+		append(args, seqName)...)
+}
+
+func (db *snowflake) DropSequenceSQL(seqName string) (string, error) {
+	return fmt.Sprintf("DROP SEQUENCE IF EXISTS %s", db.dialect.Quoter().Quote(seqName)), nil
+}
+
+func (db *snowflake) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	cond, args := db.schemaCond()
+	rows, err := queryer.QueryContext(ctx, "SELECT TABLE_NAME, COALESCE(COMMENT, '') FROM INFORMATION_SCHEMA.TABLES WHERE "+
+		cond+" AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]*schemas.Table, 0)
+	for rows.Next() {
+		table := schemas.NewEmptyTable()
+		if err := rows.Scan(&table.Name, &table.Comment); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// snowflakeColumnType converts the DATA_TYPE of INFORMATION_SCHEMA.COLUMNS as the SQL type
+func snowflakeColumnType(dataType string, length, precision, scale int64) schemas.SQLType {
+	switch strings.ToUpper(dataType) {
+	case "NUMBER", "DECIMAL", "NUMERIC":
+		if scale == 0 {
+			return schemas.SQLType{Name: schemas.BigInt}
+		}
+		return schemas.SQLType{Name: schemas.Decimal, DefaultLength: precision, DefaultLength2: scale}
+	case "FLOAT", "DOUBLE", "REAL":
+		return schemas.SQLType{Name: schemas.Double}
+	case "BOOLEAN":
+		return schemas.SQLType{Name: schemas.Boolean}
+	case "TEXT", "VARCHAR", "STRING", "CHAR", "CHARACTER":
+		return schemas.SQLType{Name: schemas.Varchar, DefaultLength: length}
+	case "BINARY", "VARBINARY":
+		return schemas.SQLType{Name: schemas.Binary}
+	case "DATE":
+		return schemas.SQLType{Name: schemas.Date}
+	case "TIME":
+		return schemas.SQLType{Name: schemas.Time}
+	case "TIMESTAMP_NTZ", "DATETIME", "TIMESTAMP":
+		return schemas.SQLType{Name: schemas.DateTime}
+	case "TIMESTAMP_TZ", "TIMESTAMP_LTZ":
+		return schemas.SQLType{Name: schemas.TimeStampz}
+	case "VARIANT", "OBJECT":
+		return schemas.SQLType{Name: schemas.Json}
+	case "ARRAY":
+		return schemas.SQLType{Name: schemas.Array}
+	case "GEOGRAPHY", "GEOMETRY":
+		return schemas.SQLType{Name: schemas.Geography}
+	}
+	return schemas.SQLType{Name: strings.ToUpper(dataType)}
+}
+
+func (db *snowflake) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+	cond, args := db.schemaCond()
+	s := "SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COALESCE(CHARACTER_MAXIMUM_LENGTH, 0)," +
+		" COALESCE(NUMERIC_PRECISION, 0), COALESCE(NUMERIC_SCALE, 0), COALESCE(IS_IDENTITY, 'NO'), COALESCE(COMMENT, '')" +
+		" FROM INFORMATION_SCHEMA.COLUMNS WHERE " + cond + " AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION"
+	rows, err := queryer.QueryContext(ctx, s, append(args, tableName)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	cols := make(map[string]*schemas.Column)
+	colSeq := make([]string, 0)
+	for rows.Next() {
+		var (
+			name, dataType, isNullable, isIdentity, comment string
+			colDefault                                      sql.NullString
+			length, precision, scale                        int64
+		)
+		if err := rows.Scan(&name, &dataType, &isNullable, &colDefault, &length, &precision, &scale, &isIdentity, &comment); err != nil {
+			return nil, nil, err
+		}
+
+		col := new(schemas.Column)
+		col.Indexes = make(map[string]int)
+		col.Name = name
+		col.SQLType = snowflakeColumnType(dataType, length, precision, scale)
+		col.Length = col.SQLType.DefaultLength
+		col.Length2 = col.SQLType.DefaultLength2
+		col.Nullable = strings.EqualFold(isNullable, "YES")
+		col.IsAutoIncrement = strings.EqualFold(isIdentity, "YES")
+		col.Comment = comment
+		if colDefault.Valid && !col.IsAutoIncrement {
+			col.Default = colDefault.String
+		} else {
+			col.DefaultIsEmpty = true
+		}
+
+		cols[col.Name] = col
+		colSeq = append(colSeq, col.Name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	rows.Close()
+
+	pks, err := db.getPrimaryKeys(queryer, ctx, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pk := range pks {
+		if col, ok := cols[pk]; ok {
+			col.IsPrimaryKey = true
+		}
+	}
+	return colSeq, cols, nil
+}
+
+// getPrimaryKeys reads the primary keys by SHOW PRIMARY KEYS since they are not in the
+// INFORMATION_SCHEMA of snowflake
+func (db *snowflake) getPrimaryKeys(queryer core.Queryer, ctx context.Context, tableName string) ([]string, error) {
+	quoter := db.dialect.Quoter()
+	name := quoter.Quote(tableName)
+	if db.uri.Schema != "" {
+		name = quoter.Quote(db.uri.Schema) + "." + name
+	}
+	rows, err := queryer.QueryContext(ctx, "SHOW PRIMARY KEYS IN TABLE "+name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	idx := -1
+	for i, field := range fields {
+		if strings.EqualFold(field, "column_name") {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, errors.New("no column_name in the result of SHOW PRIMARY KEYS")
+	}
+
+	var pks []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(fields))
+		dest := make([]interface{}, len(fields))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		pks = append(pks, values[idx].String)
+	}
+	return pks, rows.Err()
+}
+
+func (db *snowflake) Filters() []Filter {
+	return []Filter{}
+}
+
+type snowflakeDriver struct {
+	baseDriver
+}
+
+// Features returns the features of the driver, Snowflake has no function returning the value
+// generated by an AUTOINCREMENT column, so the ids of the inserted records are not written back
+func (p *snowflakeDriver) Features() *DriverFeatures {
+	return &DriverFeatures{
+		SupportReturnInsertedID: false,
+	}
+}
+
+// Parse parses the data source name of gosnowflake like
+// user[:password]@account[/database[/schema]][?warehouse=wh&role=role] or
+// user[:password]@host[:port]/database/schema?account=account&warehouse=wh
+func (p *snowflakeDriver) Parse(driverName, dataSourceName string) (*URI, error) {
+	uri := &URI{DBType: schemas.SNOWFLAKE}
+
+	dsn := dataSourceName
+	var params url.Values
+	if idx := strings.Index(dsn, "?"); idx > -1 {
+		var err error
+		params, err = url.ParseQuery(dsn[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid snowflake data source name: %w", err)
+		}
+		dsn = dsn[:idx]
+	}
+
+	if idx := strings.LastIndex(dsn, "@"); idx > -1 {
+		userInfo := dsn[:idx]
+		dsn = dsn[idx+1:]
+		if i := strings.Index(userInfo, ":"); i > -1 {
+			uri.User, uri.Passwd = userInfo[:i], userInfo[i+1:]
+		} else {
+			uri.User = userInfo
+		}
+	}
+
+	parts := strings.Split(dsn, "/")
+	host := parts[0]
+	if len(parts) > 1 {
+		uri.DBName = parts[1]
+	}
+	if len(parts) > 2 {
+		uri.Schema = parts[2]
+	}
+	if idx := strings.LastIndex(host, ":"); idx > -1 {
+		if _, err := strconv.Atoi(host[idx+1:]); err != nil {
+			return nil, fmt.Errorf("invalid port of snowflake data source name: %s", host[idx+1:])
+		}
+		host, uri.Port = host[:idx], host[idx+1:]
+	}
+	if host != "" && !strings.Contains(host, ".") {
+		// the account identifier
+		host += ".snowflakecomputing.com"
+	}
+	uri.Host = host
+
+	for key, values := range params {
+		if len(values) == 0 {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "account":
+			if uri.Host == "" {
+				uri.Host = values[0] + ".snowflakecomputing.com"
+			}
+		case "host":
+			uri.Host = values[0]
+		case "port":
+			uri.Port = values[0]
+		case "database":
+			uri.DBName = values[0]
+		case "schema":
+			uri.Schema = values[0]
+		case "warehouse":
+			uri.Warehouse = values[0]
+		case "user":
+			uri.User = values[0]
+		case "password":
+			uri.Passwd = values[0]
+		}
+	}
+	if uri.Host == "" {
+		return nil, errors.New("no account or host in snowflake data source name")
+	}
+	return uri, nil
+}
+
+func (p *snowflakeDriver) GenScanResult(colType string) (interface{}, error) {
+	switch colType {
+	case "REAL":
+		var s sql.NullFloat64
+		return &s, nil
+	case "BOOLEAN":
+		var s sql.NullBool
+		return &s, nil
+	case "DATE", "TIME", "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		var s sql.NullTime
+		return &s, nil
+	case "BINARY":
+		var s sql.RawBytes
+		return &s, nil
+	default:
+		// FIXED, TEXT, VARIANT, OBJECT and ARRAY
+		var r sql.NullString
+		return &r, nil
+	}
+}
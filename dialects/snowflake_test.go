@@ -0,0 +1,81 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSnowflakeConnStr(t *testing.T) {
+	kases := []struct {
+		dsn       string
+		host      string
+		port      string
+		dbName    string
+		schema    string
+		warehouse string
+		user      string
+	}{
+		{"user:pass@myaccount/mydb/public?warehouse=wh", "myaccount.snowflakecomputing.com", "", "mydb", "public", "wh", "user"},
+		{"user@myaccount.eu-central-1/mydb", "myaccount.eu-central-1", "", "mydb", "", "", "user"},
+		{"user:pass@host.example.com:8443/mydb/analytics?account=acc&warehouse=compute_wh&role=r", "host.example.com", "8443", "mydb", "analytics", "compute_wh", "user"},
+		{"user:pass@/?account=acc&database=db&schema=s", "acc.snowflakecomputing.com", "", "db", "s", "", "user"},
+	}
+	driver := QueryDriver("snowflake")
+	for _, kase := range kases {
+		uri, err := driver.Parse("snowflake", kase.dsn)
+		assert.NoError(t, err, kase.dsn)
+		assert.EqualValues(t, schemas.SNOWFLAKE, uri.DBType)
+		assert.EqualValues(t, kase.host, uri.Host, kase.dsn)
+		assert.EqualValues(t, kase.port, uri.Port, kase.dsn)
+		assert.EqualValues(t, kase.dbName, uri.DBName, kase.dsn)
+		assert.EqualValues(t, kase.schema, uri.Schema, kase.dsn)
+		assert.EqualValues(t, kase.warehouse, uri.Warehouse, kase.dsn)
+		assert.EqualValues(t, kase.user, uri.User, kase.dsn)
+	}
+
+	_, err := driver.Parse("snowflake", "user:pass@/mydb")
+	assert.Error(t, err)
+	_, err = driver.Parse("snowflake", "user:pass@host:port/mydb")
+	assert.Error(t, err)
+}
+
+func TestSnowflakeCreateTableSQL(t *testing.T) {
+	dialect, err := OpenDialect("snowflake", "user:pass@myaccount/mydb/public")
+	assert.NoError(t, err)
+
+	table := schemas.NewEmptyTable()
+	table.Name = "events"
+	table.Comment = "user's events"
+	id := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false)
+	id.IsPrimaryKey = true
+	id.IsAutoIncrement = true
+	table.AddColumn(id)
+	table.PrimaryKeys = []string{"id"}
+	payload := schemas.NewColumn("payload", "", schemas.SQLType{Name: schemas.Json}, 0, 0, true)
+	payload.Comment = "raw payload"
+	table.AddColumn(payload)
+	table.AddColumn(schemas.NewColumn("created", "", schemas.SQLType{Name: schemas.DateTime}, 0, 0, false))
+	table.AddColumn(schemas.NewColumn("amount", "", schemas.SQLType{Name: schemas.Decimal}, 10, 2, true))
+	table.AddIndex(&schemas.Index{Name: "created", Type: schemas.IndexType, Cols: []string{"created"}})
+
+	sql, _, err := dialect.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `CREATE TABLE IF NOT EXISTS "events" ("id" NUMBER(38,0) AUTOINCREMENT NOT NULL, `+
+		`"payload" VARIANT COMMENT 'raw payload' NULL, "created" TIMESTAMP_NTZ NOT NULL, "amount" NUMBER(10,2) NULL, `+
+		`PRIMARY KEY ("id")) COMMENT = 'user''s events'`, sql)
+
+	assert.EqualValues(t, "", dialect.CreateIndexSQL("events", table.Indexes["created"]))
+	assert.EqualValues(t, "", dialect.DropIndexSQL("events", table.Indexes["created"]))
+	assert.EqualValues(t, `ALTER TABLE "events" ALTER COLUMN "amount" SET DATA TYPE NUMBER(10,2)`,
+		dialect.ModifyColumnSQL("events", table.GetColumn("amount")))
+	assert.True(t, dialect.Features().SupportQualify)
+	assert.EqualValues(t, schemas.TIME_TYPE, dialect.ColumnTypeKind("TIMESTAMP_TZ"))
+}
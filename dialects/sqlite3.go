@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -154,6 +155,7 @@ var (
 
 type sqlite3 struct {
 	Base
+	strict bool
 }
 
 func (db *sqlite3) Init(uri *URI) error {
@@ -188,6 +190,9 @@ func (db *sqlite3) Version(ctx context.Context, queryer core.Queryer) (*schemas.
 func (db *sqlite3) Features() *DialectFeatures {
 	return &DialectFeatures{
 		AutoincrMode: IncrAutoincrMode,
+		UpdateLimit:  true,
+		DeleteLimit:  true,
+		MaxParams:    999, // SQLITE_MAX_VARIABLE_NUMBER before 3.32.0
 	}
 }
 
@@ -222,11 +227,11 @@ func (db *sqlite3) SQLType(c *schemas.Column) string {
 	case schemas.TimeStampz:
 		return schemas.Text
 	case schemas.Char, schemas.Varchar, schemas.NVarchar, schemas.TinyText,
-		schemas.Text, schemas.MediumText, schemas.LongText, schemas.Json:
+		schemas.Text, schemas.MediumText, schemas.LongText, schemas.Json, schemas.Inet, schemas.Cidr:
 		return schemas.Text
 	case schemas.Bit, schemas.TinyInt, schemas.UnsignedTinyInt, schemas.SmallInt,
 		schemas.UnsignedSmallInt, schemas.MediumInt, schemas.Int, schemas.UnsignedInt,
-		schemas.BigInt, schemas.UnsignedBigInt, schemas.Integer:
+		schemas.BigInt, schemas.UnsignedBigInt, schemas.Integer, schemas.BitSet, schemas.VarBit:
 		return schemas.Integer
 	case schemas.Float, schemas.Double, schemas.Real:
 		return schemas.Real
@@ -239,6 +244,8 @@ func (db *sqlite3) SQLType(c *schemas.Column) string {
 		c.IsAutoIncrement = true
 		c.Nullable = false
 		return schemas.Integer
+	case schemas.Geography:
+		return schemas.Geometry
 	default:
 		return t
 	}
@@ -264,6 +271,100 @@ func (db *sqlite3) IsReserved(name string) bool {
 	return ok
 }
 
+// SetParams sets the params of sqlite, STRICT=true creates all the tables as STRICT tables,
+// which could also be set by the table option STRICT of a table
+func (db *sqlite3) SetParams(params map[string]string) {
+	db.strict = false
+	if strict, ok := params["STRICT"]; ok {
+		db.strict, _ = strconv.ParseBool(strict)
+	}
+}
+
+// isStrict returns true if the options of the table have STRICT, the options of the existing
+// tables are parsed from their create table SQLs
+func (db *sqlite3) isStrict(table *schemas.Table) bool {
+	for _, opt := range filterTableOptions(table.Options, func(name string) bool { return name == "STRICT" }) {
+		return isTableOptionFlagSet(opt.Value)
+	}
+	return false
+}
+
+// TableSQLType implements TableSQLTyper, the types of the STRICT tables are mapped to INTEGER,
+// REAL, TEXT, BLOB or ANY
+func (db *sqlite3) TableSQLType(table *schemas.Table, col *schemas.Column) string {
+	if !db.isStrict(table) {
+		return db.SQLType(col)
+	}
+	return db.strictSQLType(col)
+}
+
+// strictSQLType returns the type of the column in a STRICT table
+func (db *sqlite3) strictSQLType(col *schemas.Column) string {
+	t := db.SQLType(col)
+	switch strings.ToUpper(t) {
+	case schemas.Int, schemas.Integer, schemas.Real, schemas.Text, schemas.Blob, "ANY":
+		return t
+	case schemas.DateTime:
+		return schemas.Text
+	default:
+		return "ANY"
+	}
+}
+
+// CreateTableSQL implements Dialect, the table options STRICT and WITHOUT ROWID are supported
+func (db *sqlite3) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
+	var column func(col *schemas.Column) *schemas.Column
+	strict := db.strict || db.isStrict(table)
+	if strict {
+		column = func(col *schemas.Column) *schemas.Column {
+			// SQLType may change the column, so the type is got before copying
+			tp := db.strictSQLType(col)
+			strictCol := *col
+			strictCol.SQLType = schemas.SQLType{Name: tp}
+			return &strictCol
+		}
+	}
+
+	var b strings.Builder
+	if err := writeCreateTable(&b, db.dialect, table, tableName, column); err != nil {
+		return "", false, err
+	}
+
+	var flags []string
+	if strict {
+		flags = append(flags, "STRICT")
+	}
+	options := filterTableOptions(table.Options, func(name string) bool {
+		return name == "WITHOUT ROWID"
+	})
+	for _, opt := range options {
+		if isTableOptionFlagSet(opt.Value) {
+			flags = append(flags, opt.Name)
+		}
+	}
+	if len(flags) > 0 {
+		b.WriteString(" ")
+		b.WriteString(strings.Join(flags, ", "))
+	}
+	return b.String(), false, nil
+}
+
+// parseSqliteTableOptions parses the table options STRICT and WITHOUT ROWID following the
+// column definitions of the create table SQL
+func parseSqliteTableOptions(createSQL string) map[string]string {
+	var options map[string]string
+	for _, opt := range strings.Split(createSQL[strings.LastIndex(createSQL, ")")+1:], ",") {
+		name := strings.ToUpper(strings.Join(strings.Fields(opt), " "))
+		if name == "STRICT" || name == "WITHOUT ROWID" {
+			if options == nil {
+				options = make(map[string]string)
+			}
+			options[name] = ""
+		}
+	}
+	return options
+}
+
 func (db *sqlite3) AutoIncrStr() string {
 	return "AUTOINCREMENT"
 }
@@ -400,8 +501,12 @@ func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableNa
 	if name == "" {
 		return nil, nil, errors.New("no table named " + tableName)
 	}
+	return parseSqliteColumns(name)
+}
 
-	name = strings.ReplaceAll(name, "\n", " ")
+// parseSqliteColumns parses the columns from the CREATE TABLE statement of a table
+func parseSqliteColumns(createSQL string) ([]string, map[string]*schemas.Column, error) {
+	name := strings.ReplaceAll(createSQL, "\n", " ")
 
 	nStart := strings.Index(name, "(")
 	nEnd := strings.LastIndex(name, ")")
@@ -439,7 +544,7 @@ func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableNa
 
 func (db *sqlite3) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
-	s := "SELECT name FROM sqlite_master WHERE type='table'"
+	s := "SELECT name, sql FROM sqlite_master WHERE type='table'"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -448,23 +553,71 @@ func (db *sqlite3) GetTables(queryer core.Queryer, ctx context.Context) ([]*sche
 	defer rows.Close()
 
 	tables := make([]*schemas.Table, 0)
+	var hasSequence bool
 	for rows.Next() {
 		table := schemas.NewEmptyTable()
-		err = rows.Scan(&table.Name)
+		var createSQL sql.NullString
+		err = rows.Scan(&table.Name, &createSQL)
 		if err != nil {
 			return nil, err
 		}
 		if table.Name == "sqlite_sequence" {
+			hasSequence = true
 			continue
 		}
+		table.Options = parseSqliteTableOptions(createSQL.String)
 		tables = append(tables, table)
 	}
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
+	rows.Close()
+
+	if hasSequence {
+		if err := db.loadAutoIncrStarts(queryer, ctx, tables); err != nil {
+			return nil, err
+		}
+	}
 	return tables, nil
 }
 
+// loadAutoIncrStarts loads the next values of the AUTOINCREMENT columns from sqlite_sequence
+func (db *sqlite3) loadAutoIncrStarts(queryer core.Queryer, ctx context.Context, tables []*schemas.Table) error {
+	rows, err := queryer.QueryContext(ctx, "SELECT name, seq FROM sqlite_sequence")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	seqs := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var seq int64
+		if err := rows.Scan(&name, &seq); err != nil {
+			return err
+		}
+		seqs[name] = seq
+	}
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+
+	for _, table := range tables {
+		if seq, ok := seqs[table.Name]; ok {
+			table.AutoIncrStart = seq + 1
+		}
+	}
+	return nil
+}
+
+func (db *sqlite3) SetAutoIncrStartSQL(tableName, colName string, start int64) []string {
+	name := strings.ReplaceAll(tableName, "'", "''")
+	return []string{
+		fmt.Sprintf("DELETE FROM sqlite_sequence WHERE name = '%s'", name),
+		fmt.Sprintf("INSERT INTO sqlite_sequence (name, seq) VALUES ('%s', %d)", name, start-1),
+	}
+}
+
 func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
 	s := "SELECT sql FROM sqlite_master WHERE type='index' and tbl_name = ?"
@@ -486,46 +639,121 @@ func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableNa
 		if !tmpSQL.Valid {
 			continue
 		}
-		sql := strings.ReplaceAll(tmpSQL.String, "\n", " ")
-
-		index := new(schemas.Index)
-		nNStart := strings.Index(sql, "INDEX")
-		nNEnd := strings.Index(sql, "ON")
-		if nNStart == -1 || nNEnd == -1 {
-			continue
+		if index := parseSqliteIndex(tableName, tmpSQL.String); index != nil {
+			indexes[index.Name] = index
 		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
 
-		indexName := strings.Trim(strings.TrimSpace(sql[nNStart+6:nNEnd]), "`[]'\"")
-		var isRegular bool
-		if strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName) {
-			index.Name = indexName[5+len(tableName):]
-			isRegular = true
-		} else {
-			index.Name = indexName
+	return indexes, nil
+}
+
+// GetColumnsOfTables returns the columns of the tables with one query of sqlite_master
+func (db *sqlite3) GetColumnsOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string][]*schemas.Column, error) {
+	createSQLs, err := db.loadCreateSQLs(queryer, ctx, "SELECT name, sql FROM sqlite_master WHERE type='table'", tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesCols := make(map[string][]*schemas.Column, len(tableNames))
+	for _, tableName := range tableNames {
+		if len(createSQLs[tableName]) == 0 {
+			return nil, errors.New("no table named " + tableName)
+		}
+		colSeq, cols, err := parseSqliteColumns(createSQLs[tableName][0])
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range colSeq {
+			tablesCols[tableName] = append(tablesCols[tableName], cols[name])
 		}
+	}
+	return tablesCols, nil
+}
 
-		if strings.HasPrefix(sql, "CREATE UNIQUE INDEX") {
-			index.Type = schemas.UniqueType
-		} else {
-			index.Type = schemas.IndexType
+// GetIndexesOfTables returns the indexes of the tables with one query of sqlite_master
+func (db *sqlite3) GetIndexesOfTables(queryer core.Queryer, ctx context.Context, tableNames []string) (map[string]map[string]*schemas.Index, error) {
+	createSQLs, err := db.loadCreateSQLs(queryer, ctx, "SELECT tbl_name, sql FROM sqlite_master WHERE type='index'", tableNames)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesIndexes := make(map[string]map[string]*schemas.Index, len(tableNames))
+	for _, tableName := range tableNames {
+		indexes := make(map[string]*schemas.Index)
+		for _, createSQL := range createSQLs[tableName] {
+			if index := parseSqliteIndex(tableName, createSQL); index != nil {
+				indexes[index.Name] = index
+			}
 		}
+		tablesIndexes[tableName] = indexes
+	}
+	return tablesIndexes, nil
+}
 
-		nStart := strings.Index(sql, "(")
-		nEnd := strings.Index(sql, ")")
-		colIndexes := strings.Split(sql[nStart+1:nEnd], ",")
+// loadCreateSQLs returns the CREATE statements of the tables keyed by the table name, the
+// query returns the table name and the statement of all the tables, so there is no limit
+// of the number of the args
+func (db *sqlite3) loadCreateSQLs(queryer core.Queryer, ctx context.Context, query string, tableNames []string) (map[string][]string, error) {
+	wanted := make(map[string]bool, len(tableNames))
+	for _, tableName := range tableNames {
+		wanted[tableName] = true
+	}
 
-		index.Cols = make([]string, 0)
-		for _, col := range colIndexes {
-			index.Cols = append(index.Cols, strings.Trim(col, "` []"))
+	rows, err := queryer.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	createSQLs := make(map[string][]string, len(tableNames))
+	for rows.Next() {
+		var tableName string
+		var createSQL sql.NullString
+		if err := rows.Scan(&tableName, &createSQL); err != nil {
+			return nil, err
+		}
+		if createSQL.Valid && wanted[tableName] {
+			createSQLs[tableName] = append(createSQLs[tableName], createSQL.String)
 		}
-		index.IsRegular = isRegular
-		indexes[index.Name] = index
 	}
-	if rows.Err() != nil {
-		return nil, rows.Err()
+	return createSQLs, rows.Err()
+}
+
+// parseSqliteIndex parses the index from the CREATE INDEX statement, it returns nil if the
+// statement could not be parsed
+func parseSqliteIndex(tableName, createSQL string) *schemas.Index {
+	sql := strings.ReplaceAll(createSQL, "\n", " ")
+
+	index := new(schemas.Index)
+	nNStart := strings.Index(sql, "INDEX")
+	nNEnd := strings.Index(sql, "ON")
+	if nNStart == -1 || nNEnd == -1 {
+		return nil
 	}
 
-	return indexes, nil
+	indexName := strings.Trim(strings.TrimSpace(sql[nNStart+6:nNEnd]), "`[]'\"")
+	var isRegular bool
+	index.Name, isRegular = regularIndexName(tableName, indexName)
+
+	if strings.HasPrefix(sql, "CREATE UNIQUE INDEX") {
+		index.Type = schemas.UniqueType
+	} else {
+		index.Type = schemas.IndexType
+	}
+
+	nStart := strings.Index(sql, "(")
+	nEnd := strings.Index(sql, ")")
+	colIndexes := strings.Split(sql[nStart+1:nEnd], ",")
+
+	index.Cols = make([]string, 0)
+	for _, col := range colIndexes {
+		index.Cols = append(index.Cols, strings.Trim(col, "` []"))
+	}
+	index.IsRegular = isRegular
+	return index
 }
 
 func (db *sqlite3) Filters() []Filter {
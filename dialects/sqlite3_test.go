@@ -5,8 +5,11 @@
 package dialects
 
 import (
+	"context"
 	"testing"
 
+	"github.com/imkos/xorm/schemas"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +43,36 @@ primary key autoincrement`,
 		assert.EqualValues(t, kase.fields, splitColStr(kase.colStr))
 	}
 }
+
+func TestSqliteStrict(t *testing.T) {
+	dialect, err := OpenDialect("sqlite3", "./test.db")
+	assert.NoError(t, err)
+
+	table := schemas.NewEmptyTable()
+	table.Name = "events"
+	id := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false)
+	id.IsPrimaryKey = true
+	table.AddColumn(id)
+	table.AddColumn(schemas.NewColumn("created", "", schemas.SQLType{Name: schemas.DateTime}, 0, 0, true))
+	table.AddColumn(schemas.NewColumn("amount", "", schemas.SQLType{Name: schemas.Decimal}, 10, 2, true))
+	table.PrimaryKeys = []string{"id"}
+
+	sql, _, err := dialect.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE TABLE IF NOT EXISTS `events` (`id` INTEGER PRIMARY KEY NOT NULL, `created` DATETIME NULL, `amount` NUMERIC NULL)", sql)
+
+	dialect.SetParams(map[string]string{"STRICT": "true"})
+	sql, _, err = dialect.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE TABLE IF NOT EXISTS `events` (`id` INTEGER PRIMARY KEY NOT NULL, `created` TEXT NULL, `amount` ANY NULL) STRICT", sql)
+	// the existing tables are strict only if their options have STRICT
+	assert.EqualValues(t, schemas.DateTime, dialect.(TableSQLTyper).TableSQLType(table, table.GetColumn("created")))
+
+	dialect.SetParams(nil)
+	table.Options = map[string]string{"strict": "", "WITHOUT ROWID": "false"}
+	assert.EqualValues(t, schemas.Text, dialect.(TableSQLTyper).TableSQLType(table, table.GetColumn("created")))
+
+	assert.EqualValues(t, map[string]string{"STRICT": "", "WITHOUT ROWID": ""},
+		parseSqliteTableOptions("CREATE TABLE `events` (`id` INTEGER PRIMARY KEY NOT NULL) strict, WITHOUT  ROWID"))
+	assert.Nil(t, parseSqliteTableOptions("CREATE TABLE `events` (`id` INTEGER PRIMARY KEY NOT NULL)"))
+}
@@ -17,7 +17,7 @@ import (
 // TableNameWithSchema will add schema prefix on table name if possible
 func TableNameWithSchema(dialect Dialect, tableName string) string {
 	// Add schema name as prefix of table name.
-	// Only for postgres database.
+	// Only for postgres, mssql and mysql databases, see URI.SetSchema.
 	if dialect.URI().Schema != "" && !strings.Contains(tableName, ".") {
 		return fmt.Sprintf("%s.%s", dialect.URI().Schema, tableName)
 	}
@@ -46,12 +46,12 @@ func TableNameNoSchema(dialect Dialect, mapper names.Mapper, tableName interface
 			case string:
 				table = f.(string)
 			case names.TableName:
-				table = f.(names.TableName).TableName()
+				table = tableNameOf(dialect, f.(names.TableName))
 			default:
 				v := utils.ReflectValue(f)
 				t := v.Type()
 				if t.Kind() == reflect.Struct {
-					table = names.GetTableName(mapper, v)
+					table = names.GetTableNameFor(mapper, v, dbTypeOf(dialect))
 				} else {
 					table = quote(fmt.Sprintf("%v", f))
 				}
@@ -66,23 +66,41 @@ func TableNameNoSchema(dialect Dialect, mapper names.Mapper, tableName interface
 			return quote(table)
 		}
 	case names.TableName:
-		return tableName.(names.TableName).TableName()
+		return tableNameOf(dialect, tableName.(names.TableName))
 	case string:
 		return tableName.(string)
 	case reflect.Value:
 		v := tableName.(reflect.Value)
-		return names.GetTableName(mapper, v)
+		return names.GetTableNameFor(mapper, v, dbTypeOf(dialect))
 	default:
 		v := utils.ReflectValue(tableName)
 		t := v.Type()
 		if t.Kind() == reflect.Struct {
-			return names.GetTableName(mapper, v)
+			return names.GetTableNameFor(mapper, v, dbTypeOf(dialect))
 		}
 		return quote(fmt.Sprintf("%v", tableName))
 	}
 	return ""
 }
 
+// dbTypeOf returns the database type of the dialect, it's empty for the dialects without uri
+func dbTypeOf(dialect Dialect) schemas.DBType {
+	if uri := dialect.URI(); uri != nil {
+		return uri.DBType
+	}
+	return ""
+}
+
+// tableNameOf returns the table name of the bean on the database of the dialect
+func tableNameOf(dialect Dialect, bean names.TableName) string {
+	if tableNameFor, ok := bean.(names.TableNameFor); ok {
+		if name := tableNameFor.TableNameFor(dbTypeOf(dialect)); name != "" {
+			return name
+		}
+	}
+	return bean.TableName()
+}
+
 // FullTableName returns table name with quote and schema according parameter
 func FullTableName(dialect Dialect, mapper names.Mapper, bean interface{}, includeSchema ...bool) string {
 	tbName := TableNameNoSchema(dialect, mapper, bean)
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/imkos/xorm/names"
+	"github.com/imkos/xorm/schemas"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,3 +29,20 @@ func TestFullTableName(t *testing.T) {
 	assert.EqualValues(t, "mcc", FullTableName(dialect, names.SnakeMapper{}, &MCC{}))
 	assert.EqualValues(t, "mcc", FullTableName(dialect, names.SnakeMapper{}, "mcc"))
 }
+
+func TestTableNameWithSchema(t *testing.T) {
+	dialect := QueryDialect("mysql")
+	assert.NoError(t, dialect.Init(&URI{DBType: schemas.MYSQL, DBName: "app"}))
+	assert.EqualValues(t, "mcc", TableNameWithSchema(dialect, "mcc"))
+
+	dialect.URI().SetSchema("archive")
+	assert.EqualValues(t, "archive.mcc", TableNameWithSchema(dialect, "mcc"))
+	assert.EqualValues(t, "app.mcc", TableNameWithSchema(dialect, "app.mcc"))
+
+	sql, args := dialect.IndexCheckSQL("mcc", "IDX_mcc_code")
+	assert.EqualValues(t, "SELECT `INDEX_NAME` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? AND `INDEX_NAME`=?", sql)
+	assert.EqualValues(t, []interface{}{"archive", "mcc", "IDX_mcc_code"}, args)
+
+	_, args = dialect.IndexCheckSQL("`app`.`mcc`", "IDX_mcc_code")
+	assert.EqualValues(t, []interface{}{"app", "mcc", "IDX_mcc_code"}, args)
+}
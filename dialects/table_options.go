@@ -0,0 +1,39 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"sort"
+	"strings"
+)
+
+type tableOption struct {
+	Name  string // the upper case name
+	Value string
+}
+
+// filterTableOptions returns the table options accepted by the dialect sorted by name, the
+// options of the other dialects are ignored so that a struct could have the options of
+// several databases
+func filterTableOptions(options map[string]string, accept func(name string) bool) []tableOption {
+	var opts []tableOption
+	for name, value := range options {
+		name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+		if accept(name) {
+			opts = append(opts, tableOption{Name: name, Value: value})
+		}
+	}
+	sort.Slice(opts, func(i, j int) bool {
+		return opts[i].Name < opts[j].Name
+	})
+	return opts
+}
+
+// isTableOptionFlagSet returns true if the value of a flag option like STRICT of sqlite is
+// empty or true
+func isTableOptionFlagSet(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return value == "" || value == "true" || value == "1"
+}
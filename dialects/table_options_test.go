@@ -0,0 +1,52 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableOptions(t *testing.T) {
+	table := schemas.NewEmptyTable()
+	table.Name = "logs"
+	col := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false)
+	col.IsPrimaryKey = true
+	table.AddColumn(col)
+	table.PrimaryKeys = []string{"id"}
+	table.Options = map[string]string{
+		"row_format":       "COMPRESSED",
+		"KEY_BLOCK_SIZE":   "8",
+		"fillfactor":       "70",
+		"DATA_COMPRESSION": "PAGE",
+		"STRICT":           "",
+		"without  rowid":   "true",
+	}
+
+	cases := []struct {
+		driverName string
+		dsn        string
+		expected   string
+	}{
+		{"mysql", "root:@tcp(localhost:3306)/test", "CREATE TABLE IF NOT EXISTS `logs` (`id` BIGINT(20) PRIMARY KEY NOT NULL) KEY_BLOCK_SIZE=8 ROW_FORMAT=COMPRESSED"},
+		{"postgres", "postgres://postgres:@localhost/test?sslmode=disable", `CREATE TABLE IF NOT EXISTS "public"."logs" ("id" BIGINT PRIMARY KEY NOT NULL) WITH (fillfactor=70); `},
+		{"mssql", "server=localhost;user id=sa;password=pass;database=test", `IF OBJECT_ID(N'[logs]', N'U') IS NULL CREATE TABLE [logs] ([id] BIGINT PRIMARY KEY NOT NULL) WITH (DATA_COMPRESSION = PAGE)`},
+		{"sqlite3", "./test.db", "CREATE TABLE IF NOT EXISTS `logs` (`id` INTEGER PRIMARY KEY NOT NULL) STRICT, WITHOUT ROWID"},
+	}
+	for _, c := range cases {
+		dialect, err := OpenDialect(c.driverName, c.dsn)
+		assert.NoError(t, err)
+		sql, _, err := dialect.CreateTableSQL(context.Background(), nil, table, "logs")
+		assert.NoError(t, err)
+		assert.EqualValues(t, c.expected, sql)
+	}
+
+	assert.EqualValues(t, map[string]string{"ROW_FORMAT": "COMPRESSED", "KEY_BLOCK_SIZE": "8"},
+		parseMysqlCreateOptions("row_format=COMPRESSED KEY_BLOCK_SIZE=8 partitioned"))
+}
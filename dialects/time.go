@@ -60,7 +60,7 @@ func FormatColumnTime(dialect Dialect, dbLocation *time.Location, col *schemas.C
 			return t.Format(time.RFC3339Nano), nil
 		}
 	case schemas.BigInt, schemas.Int:
-		return t.Unix(), nil
+		return col.Epoch(t), nil
 	default:
 		return t, nil
 	}
@@ -9,23 +9,29 @@ import (
 	"database/sql"
 	"fmt"
 	"io"
+	"net/netip"
 	"os"
 	"reflect"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/imkos/xorm/caches"
 	"github.com/imkos/xorm/contexts"
+	"github.com/imkos/xorm/convert"
 	"github.com/imkos/xorm/core"
 	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/log"
 	"github.com/imkos/xorm/names"
 	"github.com/imkos/xorm/schemas"
 	"github.com/imkos/xorm/tags"
+	"xorm.io/builder"
 )
 
 // Engine is the major struct of xorm, it means a database manager.
@@ -38,15 +44,53 @@ type Engine struct {
 	engineGroup    *EngineGroup
 	logger         log.ContextLogger
 	tagParser      *tags.Parser
-	db             *core.DB
+	db             *atomic.Pointer[core.DB]
 
 	driverName     string
-	dataSourceName string
+	dataSourceName *atomic.Pointer[string]
+
+	// the params and the quote policy of the dialect, they are applied to the dialects of the other schemas
+	dialectParams map[string]string
+	quotePolicy   *dialects.QuotePolicy
 
 	TZLocation *time.Location // The timezone of the application
 	DatabaseTZ *time.Location // The timezone of the database
 
 	logSessionID bool // create session id
+
+	valueNormalization ValueNormalization
+
+	timeoutRules    *timeoutRules
+	identifierAudit IdentifierAuditMode
+	strictMode      StrictMode
+	sqlInterceptors []SQLInterceptor
+	multiInsertTx   bool
+	sqlCommenter    SQLCommenter
+
+	processorSavepoint bool
+	validate           ValidateFunc
+	batchDBMetas       bool
+
+	clock func() time.Time // returns the current time for created, updated and deleted columns
+
+	maxSessionLifetime time.Duration // the max lifetime of the sessions created by NewSessionContext
+
+	poolOptions map[string]func(*sql.DB) // the pool settings which are applied again after failover
+	poolMutex   *sync.Mutex
+	failover    *atomic.Pointer[failoverSupervisor]
+
+	sessionGuard *atomic.Int32 // SessionGuardMode
+	versionOnce  *sync.Once    // detects the version for the dialects.VersionAware dialect
+
+	scopes        *scopes
+	defaultScopes *defaultScopes
+	deletedConds  *deletedConds
+	slowQueries   *slowQueries
+	schemaCache   *schemaCache
+
+	// the engine which the context bound engine is derived from by WithContext, the state
+	// above which is changed at runtime is shared by the pointers
+	parent *Engine
 }
 
 // NewEngine new a db manager according to the parameter. Currently support four
@@ -84,10 +128,23 @@ func newEngine(driverName, dataSourceName string, dialect dialects.Dialect, db *
 		cacherMgr:      cacherMgr,
 		tagParser:      tagParser,
 		driverName:     driverName,
-		dataSourceName: dataSourceName,
-		db:             db,
 		logSessionID:   false,
+		db:             new(atomic.Pointer[core.DB]),
+		dataSourceName: new(atomic.Pointer[string]),
+		timeoutRules:   new(timeoutRules),
+		poolOptions:    make(map[string]func(*sql.DB)),
+		poolMutex:      new(sync.Mutex),
+		failover:       new(atomic.Pointer[failoverSupervisor]),
+		sessionGuard:   new(atomic.Int32),
+		versionOnce:    new(sync.Once),
+		scopes:         new(scopes),
+		defaultScopes:  new(defaultScopes),
+		deletedConds:   new(deletedConds),
+		slowQueries:    new(slowQueries),
+		schemaCache:    new(schemaCache),
 	}
+	engine.db.Store(db)
+	engine.dataSourceName.Store(&dataSourceName)
 
 	if dialect.URI().DBType == schemas.SQLITE {
 		engine.DatabaseTZ = time.UTC
@@ -109,8 +166,12 @@ func newEngine(driverName, dataSourceName string, dialect dialects.Dialect, db *
 // NewEngineWithParams new a db manager with params. The params will be passed to dialects.
 func NewEngineWithParams(driverName string, dataSourceName string, params map[string]string) (*Engine, error) {
 	engine, err := NewEngine(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
 	engine.dialect.SetParams(params)
-	return engine, err
+	engine.dialectParams = params
+	return engine, nil
 }
 
 // NewEngineWithDB new a db manager with db. The params will be passed to db.
@@ -148,6 +209,7 @@ func (engine *Engine) GetCacher(tableName string) caches.Cacher {
 // SetQuotePolicy sets the special quote policy
 func (engine *Engine) SetQuotePolicy(quotePolicy dialects.QuotePolicy) {
 	engine.dialect.SetQuotePolicy(quotePolicy)
+	engine.quotePolicy = &quotePolicy
 }
 
 // BufferSize sets buffer size for iterate
@@ -200,7 +262,7 @@ func (engine *Engine) DriverName() string {
 
 // DataSourceName return the current connection string
 func (engine *Engine) DataSourceName() string {
-	return engine.dataSourceName
+	return *engine.dataSourceName.Load()
 }
 
 // SetMapper set the name mapping rules
@@ -224,6 +286,18 @@ func (engine *Engine) SetTagIdentifier(tagIdentifier string) {
 	engine.tagParser.SetIdentifier(tagIdentifier)
 }
 
+// SetFallbackTagIdentifier sets the tag which names the columns of the fields without the
+// xorm tag, i.e. "json", so that the structs annotated for JSON APIs could be mapped as is
+func (engine *Engine) SetFallbackTagIdentifier(tagIdentifier string) {
+	engine.tagParser.SetFallbackIdentifier(tagIdentifier)
+}
+
+// SetFieldSkipper sets a function to decide the struct fields which are not mapped to any
+// column besides the unexported fields and the fields tagged with "-"
+func (engine *Engine) SetFieldSkipper(skipper func(field reflect.StructField) bool) {
+	engine.tagParser.SetFieldSkipper(skipper)
+}
+
 // Quote Use QuoteStr quote the string sql
 func (engine *Engine) Quote(value string) string {
 	value = strings.TrimSpace(value)
@@ -257,22 +331,22 @@ func (engine *Engine) SQLType(c *schemas.Column) string {
 
 // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
 func (engine *Engine) SetConnMaxLifetime(d time.Duration) {
-	engine.DB().SetConnMaxLifetime(d)
+	engine.setPoolOption("SetConnMaxLifetime", func(db *sql.DB) { db.SetConnMaxLifetime(d) })
 }
 
 // SetConnMaxIdleTime sets the maximum amount of time a connection may be idle.
 func (engine *Engine) SetConnMaxIdleTime(d time.Duration) {
-	engine.DB().SetConnMaxIdleTime(d)
+	engine.setPoolOption("SetConnMaxIdleTime", func(db *sql.DB) { db.SetConnMaxIdleTime(d) })
 }
 
 // SetMaxOpenConns is only available for go 1.2+
 func (engine *Engine) SetMaxOpenConns(conns int) {
-	engine.DB().SetMaxOpenConns(conns)
+	engine.setPoolOption("SetMaxOpenConns", func(db *sql.DB) { db.SetMaxOpenConns(conns) })
 }
 
 // SetMaxIdleConns set the max idle connections on pool, default is 2
 func (engine *Engine) SetMaxIdleConns(conns int) {
-	engine.DB().SetMaxIdleConns(conns)
+	engine.setPoolOption("SetMaxIdleConns", func(db *sql.DB) { db.SetMaxIdleConns(conns) })
 }
 
 // SetDefaultCacher set the default cacher. Xorm's default not enable cacher.
@@ -285,6 +359,26 @@ func (engine *Engine) GetDefaultCacher() caches.Cacher {
 	return engine.cacherMgr.GetDefaultCacher()
 }
 
+// SetCacheInvalidationBroker publishes the cache invalidations of Insert, Update and Delete to
+// the broker and applies the invalidations of the other instances to the local cachers. The
+// invalidations of a transaction are published after it's committed. A nil broker unsubscribes
+// the previous one.
+func (engine *Engine) SetCacheInvalidationBroker(broker caches.InvalidationBroker) error {
+	return engine.cacherMgr.SetInvalidationBroker(broker)
+}
+
+// publishInvalidation publishes the cache invalidation to the other instances, the failure
+// is only logged since the database has been changed
+func (engine *Engine) publishInvalidation(tableName string, all bool, ids ...string) {
+	if err := engine.cacherMgr.PublishInvalidation(caches.InvalidationEvent{
+		Table: tableName,
+		IDs:   ids,
+		All:   all,
+	}); err != nil {
+		engine.logger.Errorf("[cache] publish invalidation of %v failed: %v", tableName, err)
+	}
+}
+
 // NoCache If you has set default cacher, and you want temporilly stop use cache,
 // you can use NoCache()
 func (engine *Engine) NoCache() *Session {
@@ -293,6 +387,13 @@ func (engine *Engine) NoCache() *Session {
 	return session.NoCache()
 }
 
+// NoReflectCache ask this session do not use the cached table information of the structs
+func (engine *Engine) NoReflectCache() *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.NoReflectCache()
+}
+
 // NoCascade If you do not want to auto cascade load object
 func (engine *Engine) NoCascade() *Session {
 	session := engine.NewSession()
@@ -308,12 +409,12 @@ func (engine *Engine) MapCacher(bean interface{}, cacher caches.Cacher) error {
 
 // NewDB provides an interface to operate database directly
 func (engine *Engine) NewDB() (*core.DB, error) {
-	return core.Open(engine.driverName, engine.dataSourceName)
+	return core.Open(engine.driverName, engine.DataSourceName())
 }
 
 // DB return the wrapper of sql.DB
 func (engine *Engine) DB() *core.DB {
-	return engine.db
+	return engine.db.Load()
 }
 
 // Dialect return database dialect
@@ -328,6 +429,11 @@ func (engine *Engine) NewSession() *Session {
 
 // Close the engine
 func (engine *Engine) Close() error {
+	if engine.parent != nil {
+		// the database is owned by the engine which the context bound engine is derived from
+		return nil
+	}
+	engine.DisableFailover()
 	return engine.DB().Close()
 }
 
@@ -359,6 +465,13 @@ func (engine *Engine) NoAutoTime() *Session {
 	return session.NoAutoTime()
 }
 
+// WithTime sets the time of the created, updated and deleted columns for the next operation
+func (engine *Engine) WithTime(t time.Time) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WithTime(t)
+}
+
 // NoAutoCondition disable auto generate Where condition from bean or not
 func (engine *Engine) NoAutoCondition(no ...bool) *Session {
 	session := engine.NewSession()
@@ -366,23 +479,86 @@ func (engine *Engine) NoAutoCondition(no ...bool) *Session {
 	return session.NoAutoCondition(no...)
 }
 
-func (engine *Engine) loadTableInfo(ctx context.Context, table *schemas.Table) error {
-	colSeq, cols, err := engine.dialect.GetColumns(engine.db, ctx, table.Name)
+func (engine *Engine) loadTableInfo(queryer core.Queryer, ctx context.Context, dialect dialects.Dialect, table *schemas.Table) error {
+	colSeq, cols, err := dialect.GetColumns(queryer, ctx, table.Name)
 	if err != nil {
 		return err
 	}
 	for _, name := range colSeq {
 		table.AddColumn(cols[name])
 	}
-	indexes, err := engine.dialect.GetIndexes(engine.db, ctx, table.Name)
+	indexes, err := dialect.GetIndexes(queryer, ctx, table.Name)
 	if err != nil {
 		return err
 	}
+	return setTableIndexes(table, indexes)
+}
+
+// tablesMetaBatchSize is the max number of the tables whose columns or indexes are loaded by one query
+const tablesMetaBatchSize = 500
+
+// loadTablesInfo loads the columns and indexes of the tables, it uses one query for the
+// columns and one for the indexes of every tablesMetaBatchSize tables if the dialect supports
+// it. The tables are not changed if it fails.
+func (engine *Engine) loadTablesInfo(queryer core.Queryer, ctx context.Context, dialect dialects.Dialect, tables []*schemas.Table) error {
+	loader, ok := dialect.(dialects.TablesMetaLoader)
+	if !ok || len(tables) <= 1 {
+		for _, table := range tables {
+			if err := engine.loadTableInfo(queryer, ctx, dialect, table); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tableNames := make([]string, 0, len(tables))
+	for _, table := range tables {
+		tableNames = append(tableNames, table.Name)
+	}
+	tablesCols := make(map[string][]*schemas.Column, len(tables))
+	tablesIndexes := make(map[string]map[string]*schemas.Index, len(tables))
+	for start := 0; start < len(tableNames); start += tablesMetaBatchSize {
+		end := start + tablesMetaBatchSize
+		if end > len(tableNames) {
+			end = len(tableNames)
+		}
+		cols, err := loader.GetColumnsOfTables(queryer, ctx, tableNames[start:end])
+		if err != nil {
+			return err
+		}
+		for name, tableCols := range cols {
+			tablesCols[name] = tableCols
+		}
+		indexes, err := loader.GetIndexesOfTables(queryer, ctx, tableNames[start:end])
+		if err != nil {
+			return err
+		}
+		for name, tableIndexes := range indexes {
+			tablesIndexes[name] = tableIndexes
+		}
+	}
+	for _, table := range tables {
+		for _, col := range tablesCols[table.Name] {
+			table.AddColumn(col)
+		}
+		indexes := tablesIndexes[table.Name]
+		if indexes == nil {
+			indexes = make(map[string]*schemas.Index)
+		}
+		if err := setTableIndexes(table, indexes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTableIndexes sets the indexes of the table and links them to the indexed columns
+func setTableIndexes(table *schemas.Table, indexes map[string]*schemas.Index) error {
 	table.Indexes = indexes
 
 	var seq int
 	for _, index := range indexes {
-		for _, name := range index.Cols {
+		for _, name := range index.Columns() {
 			parts := strings.Split(strings.TrimSpace(name), " ")
 			if len(parts) > 1 {
 				if parts[1] == "DESC" {
@@ -402,15 +578,37 @@ func (engine *Engine) loadTableInfo(ctx context.Context, table *schemas.Table) e
 	return nil
 }
 
-// DBMetas Retrieve all tables, columns, indexes' informations from database.
-func (engine *Engine) DBMetas() ([]*schemas.Table, error) {
-	tables, err := engine.dialect.GetTables(engine.db, engine.defaultContext)
+// DBMetas Retrieve all tables, columns, indexes' informations from database. The tables of
+// the default schema are retrieved unless a schema is given, it's only available for the
+// databases supported by URI.SetSchema and an error is returned for the others.
+func (engine *Engine) DBMetas(schema ...string) ([]*schemas.Table, error) {
+	dialect := engine.dialect
+	if len(schema) > 0 {
+		schemaDialect, err := dialects.WithSchema(dialect, schema[0], engine.dialectParams)
+		if err != nil {
+			return nil, err
+		}
+		if schemaDialect != dialect && engine.quotePolicy != nil {
+			schemaDialect.SetQuotePolicy(*engine.quotePolicy)
+		}
+		dialect = schemaDialect
+	}
+	tables, err := dialect.GetTables(engine.DB(), engine.defaultContext)
 	if err != nil {
 		return nil, err
 	}
 
+	if engine.batchDBMetas {
+		if _, ok := dialect.(dialects.TablesMetaLoader); ok {
+			if err = engine.loadTablesInfo(engine.DB(), engine.defaultContext, dialect, tables); err == nil {
+				return tables, nil
+			}
+			engine.logger.Warnf("load the metas of the tables in batch failed, fall back to load them one by one: %v", err)
+		}
+	}
+
 	for _, table := range tables {
-		if err = engine.loadTableInfo(engine.defaultContext, table); err != nil {
+		if err = engine.loadTableInfo(engine.DB(), engine.defaultContext, dialect, table); err != nil {
 			return nil, err
 		}
 	}
@@ -532,7 +730,7 @@ func (engine *Engine) dumpTables(ctx context.Context, tables []*schemas.Table, w
 		}
 
 		if dstTable.AutoIncrement != "" && dstDialect.Features().AutoincrMode == dialects.SequenceAutoincrMode {
-			sqlstr, err := dstDialect.CreateSequenceSQL(ctx, engine.db, utils.SeqName(dstTableName))
+			sqlstr, err := dstDialect.CreateSequenceSQL(ctx, engine.DB(), utils.SeqName(dstTableName))
 			if err != nil {
 				return err
 			}
@@ -542,7 +740,7 @@ func (engine *Engine) dumpTables(ctx context.Context, tables []*schemas.Table, w
 			}
 		}
 
-		sqlstr, _, err := dstDialect.CreateTableSQL(ctx, engine.db, dstTable, dstTableName)
+		sqlstr, _, err := dstDialect.CreateTableSQL(ctx, engine.DB(), dstTable, dstTableName)
 		if err != nil {
 			return err
 		}
@@ -551,12 +749,24 @@ func (engine *Engine) dumpTables(ctx context.Context, tables []*schemas.Table, w
 			return err
 		}
 
+		if dstTable.AutoIncrement != "" && dstTable.AutoIncrStart > 0 {
+			for _, s := range dstDialect.SetAutoIncrStartSQL(dstTableName, dstTable.AutoIncrement, dstTable.AutoIncrStart) {
+				if _, err = io.WriteString(w, s+";\n"); err != nil {
+					return err
+				}
+			}
+		}
+
 		if len(dstTable.PKColumns()) > 0 && dstDialect.URI().DBType == schemas.MSSQL {
-			fmt.Fprintf(w, "SET IDENTITY_INSERT [%s] ON;\n", dstTable.Name)
+			fmt.Fprintf(w, "SET IDENTITY_INSERT %s ON;\n", quotedDstTableName)
 		}
 
 		for _, index := range dstTable.Indexes {
-			_, err = io.WriteString(w, dstDialect.CreateIndexSQL(dstTable.Name, index)+";\n")
+			sqlStr := dstDialect.CreateIndexSQL(dstTable.Name, index)
+			if sqlStr == "" {
+				continue
+			}
+			_, err = io.WriteString(w, sqlStr+";\n")
 			if err != nil {
 				return err
 			}
@@ -958,6 +1168,126 @@ func (engine *Engine) NotIn(column string, args ...interface{}) *Session {
 	return session.NotIn(column, args...)
 }
 
+// WhereGroup provides a parenthesized condition group
+func (engine *Engine) WhereGroup(fn func(*Session)) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereGroup(fn)
+}
+
+// WhereNull provides a query string like "column IS NULL"
+func (engine *Engine) WhereNull(column string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereNull(column)
+}
+
+// OwnedBy filters the records whose polymorphic reference is the bean
+func (engine *Engine) OwnedBy(bean interface{}, name ...string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.OwnedBy(bean, name...)
+}
+
+// Polymorphic returns the polymorphic reference to the bean which could be assigned to a
+// schemas.Polymorphic field, the bean should have an integer primary key
+func (engine *Engine) Polymorphic(bean interface{}) (schemas.Polymorphic, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.statement.Polymorphic(bean)
+}
+
+// WhereNotNull provides a query string like "column IS NOT NULL"
+func (engine *Engine) WhereNotNull(column string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereNotNull(column)
+}
+
+// WhereNullSafeEq provides a null-safe equality condition
+func (engine *Engine) WhereNullSafeEq(column string, value interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereNullSafeEq(column, value)
+}
+
+// ILike provides a case-insensitive pattern matching like "column ILIKE ?"
+func (engine *Engine) ILike(column, pattern string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.ILike(column, pattern)
+}
+
+// StartsWith provides a query string like "column LIKE 's%'" with the wildcards in s escaped
+func (engine *Engine) StartsWith(column, s string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.StartsWith(column, s)
+}
+
+// EndsWith provides a query string like "column LIKE '%s'" with the wildcards in s escaped
+func (engine *Engine) EndsWith(column, s string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.EndsWith(column, s)
+}
+
+// Contains provides a query string like "column LIKE '%s%'" with the wildcards in s escaped
+func (engine *Engine) Contains(column, s string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Contains(column, s)
+}
+
+// WhereContainsIP provides a query string like "column >>= addr"
+func (engine *Engine) WhereContainsIP(column string, addr netip.Addr) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereContainsIP(column, addr)
+}
+
+// WhereWithin provides a query string like "ST_Within(column, geometry)"
+func (engine *Engine) WhereWithin(column string, g geo.Geometry) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereWithin(column, g)
+}
+
+// WhereDWithin provides a query string like "ST_DWithin(column, geometry, distance)"
+func (engine *Engine) WhereDWithin(column string, g geo.Geometry, distance float64) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereDWithin(column, g, distance)
+}
+
+// WhereBitsAll provides a query string like "(column & mask) = mask"
+func (engine *Engine) WhereBitsAll(column string, mask convert.BitSet) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereBitsAll(column, mask)
+}
+
+// WhereBitsAny provides a query string like "(column & mask) <> 0"
+func (engine *Engine) WhereBitsAny(column string, mask convert.BitSet) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereBitsAny(column, mask)
+}
+
+// WhereExists provides a query string like "EXISTS (SELECT ...)"
+func (engine *Engine) WhereExists(subQuery *builder.Builder) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereExists(subQuery)
+}
+
+// WhereNotExists provides a query string like "NOT EXISTS (SELECT ...)"
+func (engine *Engine) WhereNotExists(subQuery *builder.Builder) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereNotExists(subQuery)
+}
+
 // Incr provides a update string like "column = column + ?"
 func (engine *Engine) Incr(column string, arg ...interface{}) *Session {
 	session := engine.NewSession()
@@ -979,6 +1309,13 @@ func (engine *Engine) SetExpr(column string, expression interface{}) *Session {
 	return session.SetExpr(column, expression)
 }
 
+// JSONSet provides a update string like "column = json_set(column, path, value)"
+func (engine *Engine) JSONSet(column, path string, value interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.JSONSet(column, path, value)
+}
+
 // Table temporarily change the Get, Find, Update's table
 func (engine *Engine) Table(tableNameOrBean interface{}) *Session {
 	session := engine.NewSession()
@@ -993,6 +1330,13 @@ func (engine *Engine) Alias(alias string) *Session {
 	return session.Alias(alias)
 }
 
+// Schema sets the schema of the table for the statement chain instead of the default schema
+func (engine *Engine) Schema(schema string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Schema(schema)
+}
+
 // Limit will generate "LIMIT start, limit"
 func (engine *Engine) Limit(limit int, start ...int) *Session {
 	session := engine.NewSession()
@@ -1018,6 +1362,20 @@ func (engine *Engine) Asc(colNames ...string) *Session {
 	return session.Asc(colNames...)
 }
 
+// FilterBy adds the conditions from user input, see Session.FilterBy
+func (engine *Engine) FilterBy(params map[string][]string, spec FilterSpec) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.FilterBy(params, spec)
+}
+
+// OrderBySafe will generate "ORDER BY" from user input, see Session.OrderBySafe
+func (engine *Engine) OrderBySafe(userInput string, allowed map[string]string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.OrderBySafe(userInput, allowed)
+}
+
 // OrderBy will generate "ORDER BY order"
 func (engine *Engine) OrderBy(order interface{}, args ...interface{}) *Session {
 	session := engine.NewSession()
@@ -1039,23 +1397,62 @@ func (engine *Engine) Join(joinOperator string, tablename interface{}, condition
 	return session.Join(joinOperator, tablename, condition, args...)
 }
 
+// JoinLateral adds a lateral join of the subquery with the alias
+func (engine *Engine) JoinLateral(joinOperator string, subQuery interface{}, alias string, condition interface{}, args ...interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.JoinLateral(joinOperator, subQuery, alias, condition, args...)
+}
+
 // GroupBy generate group by statement
-func (engine *Engine) GroupBy(keys string) *Session {
+func (engine *Engine) GroupBy(keys ...interface{}) *Session {
 	session := engine.NewSession()
 	session.isAutoClose = true
-	return session.GroupBy(keys)
+	return session.GroupBy(keys...)
 }
 
 // Having generate having statement
-func (engine *Engine) Having(conditions string) *Session {
+func (engine *Engine) Having(conditions interface{}, args ...interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Having(conditions, args...)
+}
+
+// Qualify generate qualify statement
+func (engine *Engine) Qualify(conditions string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Qualify(conditions)
+}
+
+// Hint adds the optimizer hints of the query
+func (engine *Engine) Hint(hints ...string) *Session {
 	session := engine.NewSession()
 	session.isAutoClose = true
-	return session.Having(conditions)
+	return session.Hint(hints...)
 }
 
 // DBVersion returns the database version
 func (engine *Engine) DBVersion() (*schemas.Version, error) {
-	return engine.dialect.Version(engine.defaultContext, engine.db)
+	return engine.dialect.Version(engine.defaultContext, engine.DB())
+}
+
+// detectVersion gives the database version to the dialect generating the SQL according to it,
+// i.e. the limit offset of mssql before 2012. The version is detected once by the first session,
+// and the defaults of the dialect are kept if it fails.
+func (engine *Engine) detectVersion() {
+	v, ok := engine.dialect.(dialects.VersionAware)
+	if !ok {
+		return
+	}
+	engine.versionOnce.Do(func() {
+		version, err := engine.DBVersion()
+		if err != nil {
+			engine.logger.Warnf("detect the database version failed: %v", err)
+			return
+		}
+		v.SetVersion(version)
+	})
 }
 
 // TableInfo get table info according to bean's content
@@ -1078,6 +1475,13 @@ func (engine *Engine) IsTableExist(beanOrTableName interface{}) (bool, error) {
 	return session.IsTableExist(beanOrTableName)
 }
 
+// IsColumnExist if a column of the table is exist
+func (engine *Engine) IsColumnExist(beanOrTableName interface{}, colName string) (bool, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.IsColumnExist(beanOrTableName, colName)
+}
+
 // TableName returns table name with schema prefix if has
 func (engine *Engine) TableName(bean interface{}, includeSchema ...bool) string {
 	return dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean, includeSchema...)
@@ -1097,13 +1501,19 @@ func (engine *Engine) CreateUniques(bean interface{}) error {
 	return session.CreateUniques(bean)
 }
 
-// ClearCacheBean if enabled cache, clear the cache bean
+// ClearCacheBean if enabled cache, clear the cache bean, id is the Key() of the bean's schemas.PK,
+// the gob encoded id returned by ToString() is accepted as well
 func (engine *Engine) ClearCacheBean(bean interface{}, id string) error {
 	tableName := dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean)
 	cacher := engine.GetCacher(tableName)
 	if cacher != nil {
+		var pk schemas.PK
+		if err := pk.FromString(id); err == nil && len(pk) > 0 {
+			id = pk.Key()
+		}
 		cacher.ClearIds(tableName)
 		cacher.DelBean(tableName, id)
+		engine.publishInvalidation(tableName, false, id)
 	}
 	return nil
 }
@@ -1116,6 +1526,7 @@ func (engine *Engine) ClearCache(beans ...interface{}) error {
 		if cacher != nil {
 			cacher.ClearIds(tableName)
 			cacher.ClearBeans(tableName)
+			engine.publishInvalidation(tableName, true)
 		}
 	}
 	return nil
@@ -1252,6 +1663,14 @@ func (engine *Engine) Get(beans ...interface{}) (bool, error) {
 	return session.Get(beans...)
 }
 
+// One retrieves one record from table like Get but returns ErrNotFound if there is no record,
+// and ErrMultipleRows if more than one record matches in strict mode
+func (engine *Engine) One(bean interface{}, strict ...bool) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.One(bean, strict...)
+}
+
 // Exist returns true if the record exist otherwise return false
 func (engine *Engine) Exist(bean ...interface{}) (bool, error) {
 	session := engine.NewSession()
@@ -1268,6 +1687,27 @@ func (engine *Engine) Find(beans interface{}, condiBeans ...interface{}) error {
 	return session.Find(beans, condiBeans...)
 }
 
+// GetMulti retrieves the records of the primary keys into a map keyed by the primary key
+func (engine *Engine) GetMulti(ids interface{}, resultsMap interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.GetMulti(ids, resultsMap)
+}
+
+// Pluck retrieves one column of the matched records into a slice
+func (engine *Engine) Pluck(column string, slicePtr interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Pluck(column, slicePtr)
+}
+
+// PluckDistinct retrieves the distinct values of one column into a slice
+func (engine *Engine) PluckDistinct(column string, slicePtr interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.PluckDistinct(column, slicePtr)
+}
+
 // FindAndCount find the results and also return the counts
 func (engine *Engine) FindAndCount(rowsSlicePtr interface{}, condiBean ...interface{}) (int64, error) {
 	session := engine.NewSession()
@@ -1283,6 +1723,28 @@ func (engine *Engine) Iterate(bean interface{}, fun IterFunc) error {
 	return session.Iterate(bean, fun)
 }
 
+// BulkInsert inserts a slice of beans in one transaction with the fastest way the driver supports
+func (engine *Engine) BulkInsert(beans interface{}, opts BulkOptions) (int64, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.BulkInsert(beans, opts)
+}
+
+// Chunk pages through the records in primary key order and calls fn with at most size
+// records each time, bean's non-empty fields are conditions.
+func (engine *Engine) Chunk(bean interface{}, size int, fn ChunkFunc) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Chunk(bean, size, fn)
+}
+
+// ChunkInTx is like Chunk, but every batch is handled in its own transaction
+func (engine *Engine) ChunkInTx(bean interface{}, size int, fn ChunkTxFunc) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.ChunkInTx(bean, size, fn)
+}
+
 // Rows return sql.Rows compatible Rows obj, as a forward Iterator object for iterating record by record, bean's non-empty fields
 // are conditions.
 func (engine *Engine) Rows(bean interface{}) (*Rows, error) {
@@ -1325,6 +1787,48 @@ func (engine *Engine) SumsInt(bean interface{}, colNames ...string) ([]int64, er
 	return session.SumsInt(bean, colNames...)
 }
 
+// Avg avg the records by some column. bean's non-empty fields are conditions.
+func (engine *Engine) Avg(bean interface{}, colName string) (float64, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Avg(bean, colName)
+}
+
+// Min assigns the minimum of some column to res. bean's non-empty fields are conditions.
+func (engine *Engine) Min(bean interface{}, colName string, res interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Min(bean, colName, res)
+}
+
+// Max assigns the maximum of some column to res. bean's non-empty fields are conditions.
+func (engine *Engine) Max(bean interface{}, colName string, res interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Max(bean, colName, res)
+}
+
+// Aggregate scans the grouped aggregates into results
+func (engine *Engine) Aggregate(results interface{}, aggs []Agg) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Aggregate(results, aggs)
+}
+
+// SumDecimal sum the records by some column and return as convert.Decimal to keep the precision.
+func (engine *Engine) SumDecimal(bean interface{}, colName string) (convert.Decimal, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.SumDecimal(bean, colName)
+}
+
+// SumsDecimal like Sums but return slice of convert.Decimal instead of float64.
+func (engine *Engine) SumsDecimal(bean interface{}, colNames ...string) ([]convert.Decimal, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.SumsDecimal(bean, colNames...)
+}
+
 // ImportFile SQL DDL file
 func (engine *Engine) ImportFile(ddlPath string) ([]sql.Result, error) {
 	session := engine.NewSession()
@@ -1339,9 +1843,34 @@ func (engine *Engine) Import(r io.Reader) ([]sql.Result, error) {
 	return session.Import(r)
 }
 
+// ImportStream executes the SQL script read from r statement by statement, see Session.ImportStream
+func (engine *Engine) ImportStream(r io.Reader, opts ImportOptions) ([]sql.Result, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.ImportStream(r, opts)
+}
+
+// SetClock sets the function to get the current time for the created, updated and deleted
+// columns, so that the time could be frozen in tests. nil means time.Now.
+func (engine *Engine) SetClock(clock func() time.Time) {
+	engine.clock = clock
+}
+
+// now returns the current time of the engine's clock
+func (engine *Engine) now() time.Time {
+	if engine.clock != nil {
+		return engine.clock()
+	}
+	return time.Now()
+}
+
 // nowTime return current time
 func (engine *Engine) nowTime(col *schemas.Column) (interface{}, time.Time, error) {
-	t := time.Now()
+	return engine.formatAutoTime(col, engine.now())
+}
+
+// formatAutoTime returns the value of the auto time column and the time in TZLocation
+func (engine *Engine) formatAutoTime(col *schemas.Column, t time.Time) (interface{}, time.Time, error) {
 	result, err := dialects.FormatColumnTime(engine.dialect, engine.DatabaseTZ, col, t)
 	if err != nil {
 		return nil, time.Time{}, err
@@ -1384,9 +1913,31 @@ func (engine *Engine) SetSchema(schema string) {
 	engine.dialect.URI().SetSchema(schema)
 }
 
+// SetMultiInsertTx sets whether an Insert of multiple beans will be wrapped in one transaction
+// automatically when the session is not in a transaction, so that a failure will not leave
+// a part of the beans persisted.
+func (engine *Engine) SetMultiInsertTx(enabled bool) {
+	engine.multiInsertTx = enabled
+}
+
+// SetBatchDBMetas sets whether DBMetas loads the columns and the indexes of all the tables with
+// a few queries instead of two queries per table if the dialect supports it, which is much
+// faster on the schemas with thousands of tables. DBMetas falls back to the queries per table
+// if the batched queries fail.
+func (engine *Engine) SetBatchDBMetas(enabled bool) {
+	engine.batchDBMetas = enabled
+}
+
+// SetProcessorSavepoint sets whether Insert, Update and Delete in a transaction will be wrapped
+// in a savepoint, so that an error or a panic of the processors or the statement only rolls back
+// the changes of the call and the transaction could be continued.
+func (engine *Engine) SetProcessorSavepoint(enabled bool) {
+	engine.processorSavepoint = enabled
+}
+
 // AddHook adds a context Hook
 func (engine *Engine) AddHook(hook contexts.Hook) {
-	engine.db.AddHook(hook)
+	engine.DB().AddHook(hook)
 }
 
 // Unscoped always disable struct tag "deleted"
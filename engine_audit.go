@@ -0,0 +1,108 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IdentifierAuditMode represents how the raw identifiers passed to Table, Cols and OrderBy are audited
+type IdentifierAuditMode int
+
+// enumerates all the identifier audit modes
+const (
+	// IdentifierAuditOff disables the audit, it's the default mode
+	IdentifierAuditOff IdentifierAuditMode = iota
+	// IdentifierAuditLog logs a warning when a suspicious identifier is found
+	IdentifierAuditLog
+	// IdentifierAuditError makes the session fail with ErrSuspiciousIdentifier
+	IdentifierAuditError
+)
+
+// ErrSuspiciousIdentifier represents an error when a raw identifier contains characters
+// which should not be in a table name, a column name or an order by clause
+type ErrSuspiciousIdentifier struct {
+	Kind       string
+	Identifier string
+}
+
+func (err ErrSuspiciousIdentifier) Error() string {
+	return fmt.Sprintf("suspicious %s identifier %q", err.Kind, err.Identifier)
+}
+
+const (
+	identPattern          = "(?:[\\pL\\pN_$]+|`[^`]+`|\"[^\"]+\"|\\[[^\\]]+\\])"
+	qualifiedIdentPattern = identPattern + `(?:\.` + identPattern + `)*(?:\.\*)?`
+	aliasPattern          = `(?:\s+(?i:AS\s+)?` + identPattern + `)?`
+)
+
+var (
+	tableIdentRegexp   = regexp.MustCompile(`^\s*` + qualifiedIdentPattern + aliasPattern + `\s*$`)
+	columnIdentRegexp  = regexp.MustCompile(`^\s*(?:\*|` + qualifiedIdentPattern + aliasPattern + `)\s*$`)
+	orderByIdentRegexp = regexp.MustCompile(`^\s*` + qualifiedIdentPattern + `(?:\s+(?i:ASC|DESC))?(?:\s+(?i:NULLS\s+(?:FIRST|LAST)))?\s*$`)
+)
+
+// auditIdentifier checks the identifier, a comma separated list is accepted for columns and order by
+func auditIdentifier(kind string, ident string) error {
+	switch kind {
+	case "table":
+		if tableIdentRegexp.MatchString(ident) {
+			return nil
+		}
+	case "column", "order by":
+		re := columnIdentRegexp
+		if kind == "order by" {
+			re = orderByIdentRegexp
+		}
+		valid := true
+		for _, part := range strings.Split(ident, ",") {
+			if !re.MatchString(part) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return nil
+		}
+	}
+	return ErrSuspiciousIdentifier{Kind: kind, Identifier: ident}
+}
+
+// SetIdentifierAudit sets how the raw identifiers passed to Table, Cols, Omit, OrderBy, Asc
+// and Desc are audited. It helps to find out the identifiers come from user inputs which
+// may be used to inject SQL, i.e. a sort parameter of a HTTP API.
+func (engine *Engine) SetIdentifierAudit(mode IdentifierAuditMode) {
+	engine.identifierAudit = mode
+}
+
+// SetIdentifierAudit sets the identifier audit mode for all the engines of the group
+func (eg *EngineGroup) SetIdentifierAudit(mode IdentifierAuditMode) {
+	eg.Engine.SetIdentifierAudit(mode)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetIdentifierAudit(mode)
+	}
+}
+
+// auditIdentifiers audits the identifiers according the engine's audit mode, it returns
+// false if the session has been failed because of a suspicious identifier
+func (session *Session) auditIdentifiers(kind string, idents ...string) bool {
+	if session.engine.identifierAudit == IdentifierAuditOff {
+		return true
+	}
+	for _, ident := range idents {
+		err := auditIdentifier(kind, ident)
+		if err == nil {
+			continue
+		}
+		if session.engine.identifierAudit == IdentifierAuditError {
+			session.statement.LastError = err
+			return false
+		}
+		session.engine.logger.Warnf("[identifier_audit] %v", err)
+	}
+	return true
+}
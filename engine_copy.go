@@ -0,0 +1,268 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/internal/utils"
+	"github.com/imkos/xorm/schemas"
+)
+
+// CopyOptions represents the options of CopyDatabase
+type CopyOptions struct {
+	// Tables are the names of the tables to be copied, all the tables will be copied if it's empty
+	Tables []string
+	// BatchSize is the number of records of one INSERT statement, default is 100, it's reduced
+	// if the parameters of the records exceed the limit of the destination database
+	BatchSize int
+	// Workers is the number of the tables copied concurrently, default is 1
+	Workers int
+}
+
+const defaultCopyBatchSize = 100
+
+// CopyDatabase copies the tables and their records from src to dst which may be a different
+// kind of database. The tables which don't exist in dst will be created with dst's dialect,
+// then the records are streamed from src to dst, every table is copied in one transaction.
+// It works like DumpTables and ImportFile but doesn't need a SQL file.
+func CopyDatabase(src, dst *Engine, opts CopyOptions) error {
+	ctx := src.defaultContext
+	tables, err := src.DBMetas()
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Tables) > 0 {
+		selected := make([]*schemas.Table, 0, len(opts.Tables))
+		for _, name := range opts.Tables {
+			var found bool
+			for _, table := range tables {
+				if strings.EqualFold(table.Name, name) {
+					selected = append(selected, table)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%w: %q", ErrTableNotFound, name)
+			}
+		}
+		tables = selected
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		firstErr error
+		tableCh  = make(chan *schemas.Table)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for table := range tableCh {
+				if err := copyTable(ctx, src, dst, table, opts.BatchSize); err != nil {
+					lock.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("copy table %s failed: %w", table.Name, err)
+					}
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+	for _, table := range tables {
+		lock.Lock()
+		failed := firstErr != nil
+		lock.Unlock()
+		if failed {
+			break
+		}
+		tableCh <- table
+	}
+	close(tableCh)
+	wg.Wait()
+	return firstErr
+}
+
+// copyTable creates the table in dst if it doesn't exist and copies the records
+func copyTable(ctx context.Context, src, dst *Engine, table *schemas.Table, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+
+	srcTableName := table.Name
+	if src.dialect.URI().Schema != "" {
+		srcTableName = fmt.Sprintf("%s.%s", src.dialect.URI().Schema, table.Name)
+	}
+	dstTableName := table.Name
+	if dst.dialect.URI().Schema != "" {
+		dstTableName = fmt.Sprintf("%s.%s", dst.dialect.URI().Schema, table.Name)
+	}
+
+	exist, err := dst.dialect.IsTableExist(dst.DB(), ctx, dstTableName)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		if err := createCopiedTable(ctx, dst, table, dstTableName); err != nil {
+			return err
+		}
+	}
+
+	cols := table.ColumnsSeq()
+	if maxParams := dst.dialect.Features().MaxParams; maxParams > 0 && batchSize*len(cols) > maxParams {
+		batchSize = maxParams / len(cols)
+		if batchSize == 0 {
+			batchSize = 1
+		}
+	}
+
+	rows, err := src.DB().QueryContext(ctx, "SELECT "+src.dialect.Quoter().Join(cols, ", ")+" FROM "+src.Quote(srcTableName))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	fields, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	session := dst.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	isMSSQLIdentity := dst.dialect.URI().DBType == schemas.MSSQL && table.AutoIncrement != ""
+	if isMSSQLIdentity {
+		if _, err := session.Exec(fmt.Sprintf("SET IDENTITY_INSERT %s ON", dst.Quote(dstTableName))); err != nil {
+			return err
+		}
+	}
+
+	insertPrefix := "INSERT INTO " + dst.Quote(dstTableName) + " (" + dst.dialect.Quoter().Join(cols, ", ") + ") VALUES "
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ") + ")"
+	flush := func(n int, args []interface{}) error {
+		if n == 0 {
+			return nil
+		}
+		sqlStr := insertPrefix + strings.TrimSuffix(strings.Repeat(placeholders+", ", n), ", ")
+		_, err := session.Exec(append([]interface{}{sqlStr}, args...)...)
+		return err
+	}
+
+	var (
+		n    int
+		args = make([]interface{}, 0, batchSize*len(cols))
+	)
+	for rows.Next() {
+		scanResults, err := src.scanStringInterface(rows, fields, types)
+		if err != nil {
+			return err
+		}
+		for i, scanResult := range scanResults {
+			v, err := copyValue(scanResult.(*sql.NullString), table.Columns()[i], types[i], src.dialect)
+			if err != nil {
+				return err
+			}
+			args = append(args, v)
+		}
+		n++
+		if n == batchSize {
+			if err := flush(n, args); err != nil {
+				return err
+			}
+			n, args = 0, args[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := flush(n, args); err != nil {
+		return err
+	}
+
+	if isMSSQLIdentity {
+		if _, err := session.Exec(fmt.Sprintf("SET IDENTITY_INSERT %s OFF", dst.Quote(dstTableName))); err != nil {
+			return err
+		}
+	}
+	if dst.dialect.URI().DBType == schemas.POSTGRES && table.AutoIncrement != "" {
+		// the sequence has not been used by the copied records, move it after the max id
+		if _, err := session.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+			dstTableName, table.AutoIncrement, dst.Quote(table.AutoIncrement), dst.Quote(dstTableName))); err != nil {
+			return err
+		}
+	}
+	return session.Commit()
+}
+
+// createCopiedTable creates the table and its indexes with dst's dialect
+func createCopiedTable(ctx context.Context, dst *Engine, table *schemas.Table, dstTableName string) error {
+	if table.AutoIncrement != "" && dst.dialect.Features().AutoincrMode == dialects.SequenceAutoincrMode {
+		sqlStr, err := dst.dialect.CreateSequenceSQL(ctx, dst.DB(), utils.SeqName(dstTableName))
+		if err != nil {
+			return err
+		}
+		if _, err := dst.DB().ExecContext(ctx, sqlStr); err != nil {
+			return err
+		}
+	}
+
+	sqlStr, _, err := dst.dialect.CreateTableSQL(ctx, dst.DB(), table, dstTableName)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.DB().ExecContext(ctx, sqlStr); err != nil {
+		return err
+	}
+
+	for _, index := range table.Indexes {
+		sqlStr := dst.dialect.CreateIndexSQL(dstTableName, index)
+		if sqlStr == "" {
+			continue
+		}
+		if _, err := dst.DB().ExecContext(ctx, sqlStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyValue converts the value read from the source database to the destination's
+func copyValue(s *sql.NullString, col *schemas.Column, tp *sql.ColumnType, srcDialect dialects.Dialect) (interface{}, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	stp := schemas.SQLType{Name: tp.DatabaseTypeName()}
+	switch {
+	case col.SQLType.IsBool() || stp.IsBool() ||
+		(srcDialect.URI().DBType == schemas.MSSQL && strings.EqualFold(stp.Name, schemas.Bit)):
+		return strconv.ParseBool(s.String)
+	case col.SQLType.IsBlob() || stp.IsBlob():
+		return []byte(s.String), nil
+	case srcDialect.URI().DBType == schemas.DAMENG && stp.IsTime() && len(s.String) == 25:
+		return strings.ReplaceAll(s.String[:19], "T", " "), nil
+	}
+	return s.String, nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imkos/xorm/caches"
+	"github.com/imkos/xorm/names"
+)
+
+// SlowQuery represents one of the slowest queries executed by an engine
+type SlowQuery struct {
+	SQL      string
+	Duration time.Duration // the max duration of the query
+	At       time.Time     // when the query with the max duration executed
+}
+
+// slowQueries keeps the top N slowest queries, the same SQL is kept once with its max duration.
+// It's disabled if the size is not positive, so the queries don't take the lock by default.
+type slowQueries struct {
+	size    atomic.Int64
+	mutex   sync.Mutex
+	queries []SlowQuery
+}
+
+func (sq *slowQueries) record(sqlStr string, duration time.Duration) {
+	if sq.size.Load() <= 0 {
+		return
+	}
+
+	sq.mutex.Lock()
+	defer sq.mutex.Unlock()
+
+	size := int(sq.size.Load())
+	if size <= 0 {
+		return
+	}
+	if len(sq.queries) >= size && duration <= sq.queries[len(sq.queries)-1].Duration {
+		return
+	}
+
+	now := time.Now()
+	idx := -1
+	for i := range sq.queries {
+		if sq.queries[i].SQL == sqlStr {
+			idx = i
+			break
+		}
+	}
+	if idx >= 0 {
+		if duration <= sq.queries[idx].Duration {
+			return
+		}
+		sq.queries[idx].Duration = duration
+		sq.queries[idx].At = now
+	} else {
+		sq.queries = append(sq.queries, SlowQuery{SQL: sqlStr, Duration: duration, At: now})
+	}
+
+	sort.SliceStable(sq.queries, func(i, j int) bool {
+		return sq.queries[i].Duration > sq.queries[j].Duration
+	})
+	if len(sq.queries) > size {
+		sq.queries = sq.queries[:size]
+	}
+}
+
+func (sq *slowQueries) list() []SlowQuery {
+	sq.mutex.Lock()
+	defer sq.mutex.Unlock()
+	return append([]SlowQuery(nil), sq.queries...)
+}
+
+func (sq *slowQueries) setSize(size int) {
+	sq.mutex.Lock()
+	defer sq.mutex.Unlock()
+	sq.size.Store(int64(size))
+	if size <= 0 {
+		sq.queries = nil
+	} else if len(sq.queries) > size {
+		sq.queries = sq.queries[:size]
+	}
+}
+
+// SetSlowQueriesSize enables recording the slowest queries for DebugReport and sets how many of
+// them are kept. It's disabled by default, since every query is recorded under a lock of the
+// engine, and a non-positive size disables it again.
+func (engine *Engine) SetSlowQueriesSize(size int) {
+	engine.slowQueries.setSize(size)
+}
+
+// SlowQueries returns the slowest queries executed by the engine, the slowest is the first
+func (engine *Engine) SlowQueries() []SlowQuery {
+	return engine.slowQueries.list()
+}
+
+func mapperName(mapper names.Mapper) string {
+	if mapper == nil {
+		return "<nil>"
+	}
+	if cacheMapper, ok := mapper.(*names.CacheMapper); ok {
+		return mapperName(cacheMapper.OriginalMapper()) + " (cached)"
+	}
+	return reflect.TypeOf(mapper).String()
+}
+
+func writeCacherReport(w io.Writer, name string, cacher caches.Cacher) error {
+	if cacher == nil {
+		return nil
+	}
+	if statsCacher, ok := cacher.(caches.StatsCacher); ok {
+		stats := statsCacher.Stats()
+		_, err := fmt.Fprintf(w, "  %s: %T hits=%d misses=%d ids=%d beans=%d\n",
+			name, cacher, stats.Hits, stats.Misses, stats.Ids, stats.Beans)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "  %s: %T\n", name, cacher)
+	return err
+}
+
+// DebugReport writes the connection pool statistics, the cachers' statistics, the slowest
+// queries, the database version and the mapper configuration of the engine to w. It's a
+// support bundle to diagnose the issues on production.
+func (engine *Engine) DebugReport(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "driver: %s\ndialect: %s\n", engine.driverName, engine.dialect.URI().DBType); err != nil {
+		return err
+	}
+	if version, err := engine.DBVersion(); err != nil {
+		if _, err := fmt.Fprintf(w, "version: <error: %v>\n", err); err != nil {
+			return err
+		}
+	} else if _, err := fmt.Fprintf(w, "version: %s\n", strings.TrimSpace(strings.Join([]string{version.Edition, version.Number, version.Level}, " "))); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "table mapper: %s\ncolumn mapper: %s\ntag identifier: %s\ntimezone: %v\ndatabase timezone: %v\n",
+		mapperName(engine.GetTableMapper()), mapperName(engine.GetColumnMapper()), engine.tagParser.GetIdentifier(),
+		engine.TZLocation, engine.DatabaseTZ); err != nil {
+		return err
+	}
+
+	stats := engine.DB().Stats()
+	if _, err := fmt.Fprintf(w, "pool: max_open=%d open=%d in_use=%d idle=%d wait_count=%d wait_duration=%v max_idle_closed=%d max_idle_time_closed=%d max_lifetime_closed=%d\n",
+		stats.MaxOpenConnections, stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount, stats.WaitDuration,
+		stats.MaxIdleClosed, stats.MaxIdleTimeClosed, stats.MaxLifetimeClosed); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "cachers:"); err != nil {
+		return err
+	}
+	if err := writeCacherReport(w, "default", engine.GetDefaultCacher()); err != nil {
+		return err
+	}
+	cachers := engine.cacherMgr.Cachers()
+	tableNames := make([]string, 0, len(cachers))
+	for tableName := range cachers {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+	for _, tableName := range tableNames {
+		if err := writeCacherReport(w, tableName, cachers[tableName]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintln(w, "slow queries:"); err != nil {
+		return err
+	}
+	for i, query := range engine.SlowQueries() {
+		if _, err := fmt.Fprintf(w, "  %d. %v at %s: %s\n", i+1, query.Duration,
+			query.At.Format(time.RFC3339), query.SQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DebugReport writes the debug reports of the master and all the slaves to w
+func (eg *EngineGroup) DebugReport(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "[master]"); err != nil {
+		return err
+	}
+	if err := eg.Engine.DebugReport(w); err != nil {
+		return err
+	}
+	for i, slave := range eg.slaves {
+		if _, err := fmt.Fprintf(w, "[slave %d]\n", i); err != nil {
+			return err
+		}
+		if err := slave.DebugReport(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/imkos/xorm/internal/json"
+	"github.com/imkos/xorm/schemas"
+)
+
+// ExportFormat represents the format of the exported records
+type ExportFormat int
+
+// enumerates all the export formats
+const (
+	// ExportCSV exports the records as CSV with a header line of the column names
+	ExportCSV ExportFormat = iota
+	// ExportJSONL exports the records as JSON Lines, one object per record
+	ExportJSONL
+)
+
+// ExportOptions represents the options of ExportTables
+type ExportOptions struct {
+	Format ExportFormat
+	// NullAs is the text of a NULL value in CSV, default is an empty string
+	NullAs string
+	// NoHeader omits the header line of CSV
+	NoHeader bool
+	// Gzip compresses the output with gzip
+	Gzip bool
+}
+
+// ExportTablesToFile exports the records of the tables to a file, see ExportTables
+func (engine *Engine) ExportTablesToFile(tables []*schemas.Table, fp string, opts ExportOptions) error {
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return engine.ExportTables(tables, f, opts)
+}
+
+// ExportTables streams the records of the tables to w as CSV or JSON Lines. Unlike
+// DumpTables, only the data will be exported. The tables are written one after another,
+// CSV tables are separated by an empty line, so usually one table is exported to one writer.
+func (engine *Engine) ExportTables(tables []*schemas.Table, w io.Writer, opts ExportOptions) error {
+	return engine.exportTables(engine.defaultContext, tables, w, opts)
+}
+
+func (engine *Engine) exportTables(ctx context.Context, tables []*schemas.Table, w io.Writer, opts ExportOptions) error {
+	if opts.Format != ExportCSV && opts.Format != ExportJSONL {
+		return fmt.Errorf("unsupported export format %v", opts.Format)
+	}
+
+	var gw *gzip.Writer
+	if opts.Gzip {
+		gw = gzip.NewWriter(w)
+		defer gw.Close()
+		w = gw
+	}
+	bw := bufio.NewWriter(w)
+
+	for i, table := range tables {
+		if i > 0 && opts.Format == ExportCSV {
+			if _, err := bw.WriteString("\n"); err != nil {
+				return err
+			}
+		}
+		if err := engine.exportTable(ctx, table, bw, opts); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if gw != nil {
+		return gw.Close()
+	}
+	return nil
+}
+
+func (engine *Engine) exportTable(ctx context.Context, table *schemas.Table, w *bufio.Writer, opts ExportOptions) error {
+	tableName := table.Name
+	if engine.dialect.URI().Schema != "" {
+		tableName = fmt.Sprintf("%s.%s", engine.dialect.URI().Schema, table.Name)
+	}
+	cols := table.ColumnsSeq()
+
+	rows, err := engine.DB().QueryContext(ctx, "SELECT "+engine.dialect.Quoter().Join(cols, ", ")+" FROM "+engine.Quote(tableName))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	fields, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var csvWriter *csv.Writer
+	if opts.Format == ExportCSV {
+		csvWriter = csv.NewWriter(w)
+		if !opts.NoHeader {
+			if err := csvWriter.Write(fields); err != nil {
+				return err
+			}
+		}
+	}
+
+	record := make([]string, len(fields))
+	for rows.Next() {
+		scanResults, err := engine.scanStringInterface(rows, fields, types)
+		if err != nil {
+			return err
+		}
+
+		if csvWriter != nil {
+			for i, scanResult := range scanResults {
+				s := scanResult.(*sql.NullString)
+				if s.Valid {
+					record[i] = s.String
+				} else {
+					record[i] = opts.NullAs
+				}
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := w.WriteByte('{'); err != nil {
+			return err
+		}
+		for i, scanResult := range scanResults {
+			if i > 0 {
+				if err := w.WriteByte(','); err != nil {
+					return err
+				}
+			}
+			key, err := json.DefaultJSONHandler.Marshal(fields[i])
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(key, ':')); err != nil {
+				return err
+			}
+
+			var col *schemas.Column
+			if i < len(table.Columns()) {
+				col = table.Columns()[i]
+			}
+			value, err := exportJSONValue(scanResult.(*sql.NullString), col, types[i])
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(value); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return nil
+}
+
+// exportJSONValue renders the value according the column's type, numbers and booleans
+// will not be quoted, binary data will be encoded as base64
+func exportJSONValue(s *sql.NullString, col *schemas.Column, tp *sql.ColumnType) ([]byte, error) {
+	if !s.Valid {
+		return []byte("null"), nil
+	}
+
+	stp := schemas.SQLType{Name: tp.DatabaseTypeName()}
+	var colType schemas.SQLType
+	if col != nil {
+		colType = col.SQLType
+	}
+	switch {
+	case colType.IsBool() || stp.IsBool():
+		if val, err := strconv.ParseBool(s.String); err == nil {
+			return []byte(strconv.FormatBool(val)), nil
+		}
+	case colType.IsNumeric() || stp.IsNumeric():
+		if _, err := strconv.ParseFloat(s.String, 64); err == nil {
+			return []byte(s.String), nil
+		}
+	case colType.IsJson() || stp.IsJson():
+		if stdjson.Valid([]byte(s.String)) {
+			return []byte(s.String), nil
+		}
+	case colType.IsBlob() || stp.IsBlob():
+		return json.DefaultJSONHandler.Marshal([]byte(s.String))
+	}
+	return json.DefaultJSONHandler.Marshal(s.String)
+}
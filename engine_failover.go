@@ -0,0 +1,227 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/imkos/xorm/contexts"
+)
+
+// IsConnectionError returns true if the error means the connection to the database is broken
+// or could not be established, i.e. the dial errors, the refused or reset connections, the
+// unexpected EOF and the bad connections reported by the drivers. The timeouts and the
+// cancellations of the contexts are not connection errors, they happen on the healthy
+// databases too.
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// FailoverOptions represents the options of the failover supervisor of an engine
+type FailoverOptions struct {
+	// NewDataSource returns the data source name to reconnect when the connection failures
+	// persist, i.e. resolves the new address of the primary after a DNS failover. It's required.
+	NewDataSource func(ctx context.Context) (string, error)
+	// Threshold is the number of the consecutive connection failures which triggers a failover,
+	// the default is 3
+	Threshold int
+	// CheckInterval is the interval to ping the database, 0 means the failures are only
+	// detected by the executed SQLs
+	CheckInterval time.Duration
+	// IsConnectionError classifies the errors, the default is IsConnectionError
+	IsConnectionError func(error) bool
+	// OnFailover is called after every failover, err is nil if the database has been swapped
+	OnFailover func(dataSourceName string, err error)
+}
+
+type failoverSupervisor struct {
+	engine   *Engine
+	opts     FailoverOptions
+	failures atomic.Int32
+	running  atomic.Bool // a failover triggered by the failures is running
+	mutex    sync.Mutex  // serializes the failovers
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// failoverHook counts the connection failures of the executed SQLs
+type failoverHook struct {
+	engine *Engine
+}
+
+func (h failoverHook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
+	return c.Ctx, nil
+}
+
+func (h failoverHook) AfterProcess(c *contexts.ContextHook) error {
+	if s := h.engine.failover.Load(); s != nil {
+		s.observe(c.Err)
+	}
+	return nil
+}
+
+// EnableFailover starts a supervisor which detects the persistent connection failures of the
+// engine, then obtains a new data source name from the options and swaps the underlying
+// database atomically. The new sessions use the new database while the running transactions
+// stay on the old one which is closed in the background.
+func (engine *Engine) EnableFailover(opts FailoverOptions) error {
+	if opts.NewDataSource == nil {
+		return errors.New("NewDataSource of the failover options is required")
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 3
+	}
+	if opts.IsConnectionError == nil {
+		opts.IsConnectionError = IsConnectionError
+	}
+
+	s := &failoverSupervisor{
+		engine: engine,
+		opts:   opts,
+		stop:   make(chan struct{}),
+	}
+	old := engine.failover.Swap(s)
+	if old != nil {
+		old.close()
+	} else {
+		// the hooks are shared by the swapped databases, so it's only added once
+		engine.DB().AddHook(failoverHook{engine: engine})
+	}
+	if opts.CheckInterval > 0 {
+		go s.check()
+	}
+	return nil
+}
+
+// DisableFailover stops the failover supervisor
+func (engine *Engine) DisableFailover() {
+	if s := engine.failover.Swap(nil); s != nil {
+		s.close()
+	}
+}
+
+// Failover obtains a new data source name and swaps the underlying database immediately
+func (engine *Engine) Failover(ctx context.Context) error {
+	s := engine.failover.Load()
+	if s == nil {
+		return ErrFailoverNotEnabled
+	}
+	return s.failover(ctx)
+}
+
+func (engine *Engine) setPoolOption(name string, option func(db *sql.DB)) {
+	engine.poolMutex.Lock()
+	defer engine.poolMutex.Unlock()
+	if engine.poolOptions == nil {
+		engine.poolOptions = make(map[string]func(*sql.DB))
+	}
+	engine.poolOptions[name] = option
+	option(engine.DB().DB)
+}
+
+// swapDB opens the database with the data source name and replaces the current one
+func (engine *Engine) swapDB(ctx context.Context, dataSourceName string) error {
+	oldDB := engine.DB()
+	db, err := oldDB.Reopen(engine.driverName, dataSourceName)
+	if err != nil {
+		return err
+	}
+
+	engine.poolMutex.Lock()
+	for _, option := range engine.poolOptions {
+		option(db.DB)
+	}
+	engine.poolMutex.Unlock()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	engine.db.Store(db)
+	engine.dataSourceName.Store(&dataSourceName)
+	go oldDB.Close()
+	return nil
+}
+
+func (s *failoverSupervisor) close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+func (s *failoverSupervisor) observe(err error) {
+	if err == nil {
+		s.failures.Store(0)
+		return
+	}
+	if !s.opts.IsConnectionError(err) {
+		return
+	}
+	if s.failures.Add(1) < int32(s.opts.Threshold) || !s.running.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.running.Store(false)
+		_ = s.failover(context.Background())
+	}()
+}
+
+func (s *failoverSupervisor) check() {
+	ticker := time.NewTicker(s.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.opts.CheckInterval)
+		err := s.engine.DB().PingContext(ctx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			// an unreachable database usually times out rather than refuses
+			err = driver.ErrBadConn
+		}
+		s.observe(err)
+	}
+}
+
+func (s *failoverSupervisor) failover(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dataSourceName, err := s.opts.NewDataSource(ctx)
+	if err == nil {
+		err = s.engine.swapDB(ctx, dataSourceName)
+	}
+	s.failures.Store(0)
+	if s.opts.OnFailover != nil {
+		s.opts.OnFailover(dataSourceName, err)
+	}
+	return err
+}
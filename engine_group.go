@@ -6,6 +6,8 @@ package xorm
 
 import (
 	"context"
+	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/imkos/xorm/caches"
@@ -20,11 +22,22 @@ type EngineGroup struct {
 	*Engine
 	slaves []*Engine
 	policy GroupPolicy
+
+	stickyDuration *atomic.Int64 // time.Duration, it's shared by the groups of WithContext
+	consistency    GroupConsistency
+
+	positionChecker PositionChecker
+	// the last replication position checked on every slave, so that the reads after the same
+	// write don't check the slaves again and again, it's shared by the groups of WithContext
+	slavePositions *slavePositions
 }
 
 // NewEngineGroup creates a new engine group
 func NewEngineGroup(args1 interface{}, args2 interface{}, policies ...GroupPolicy) (*EngineGroup, error) {
-	var eg EngineGroup
+	eg := EngineGroup{
+		stickyDuration: new(atomic.Int64),
+		slavePositions: &slavePositions{},
+	}
 	if len(policies) > 0 {
 		eg.policy = policies[0]
 	} else {
@@ -65,6 +78,10 @@ func NewEngineGroup(args1 interface{}, args2 interface{}, policies ...GroupPolic
 
 // Close the engine
 func (eg *EngineGroup) Close() error {
+	if eg.Engine.parent != nil {
+		// the group is bound to a context by WithContext
+		return nil
+	}
 	err := eg.Engine.Close()
 	if err != nil {
 		return err
@@ -120,6 +137,14 @@ func (eg *EngineGroup) SetColumnMapper(mapper names.Mapper) {
 	}
 }
 
+// SetClock sets the clock for all the engines of the group
+func (eg *EngineGroup) SetClock(clock func() time.Time) {
+	eg.Engine.SetClock(clock)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetClock(clock)
+	}
+}
+
 // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
 func (eg *EngineGroup) SetConnMaxLifetime(d time.Duration) {
 	eg.Engine.SetConnMaxLifetime(d)
@@ -136,6 +161,19 @@ func (eg *EngineGroup) SetDefaultCacher(cacher caches.Cacher) {
 	}
 }
 
+// SetCacheInvalidationBroker sets the cache invalidation broker for all the engines of the group
+func (eg *EngineGroup) SetCacheInvalidationBroker(broker caches.InvalidationBroker) error {
+	if err := eg.Engine.SetCacheInvalidationBroker(broker); err != nil {
+		return err
+	}
+	for i := 0; i < len(eg.slaves); i++ {
+		if err := eg.slaves[i].SetCacheInvalidationBroker(broker); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SetLogger set the new logger
 func (eg *EngineGroup) SetLogger(logger interface{}) {
 	eg.Engine.SetLogger(logger)
@@ -176,6 +214,22 @@ func (eg *EngineGroup) SetTagIdentifier(tagIdentifier string) {
 	}
 }
 
+// SetFallbackTagIdentifier sets the fallback tag identifier for all the engines of the group
+func (eg *EngineGroup) SetFallbackTagIdentifier(tagIdentifier string) {
+	eg.Engine.SetFallbackTagIdentifier(tagIdentifier)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetFallbackTagIdentifier(tagIdentifier)
+	}
+}
+
+// SetFieldSkipper sets the field skipper for all the engines of the group
+func (eg *EngineGroup) SetFieldSkipper(skipper func(field reflect.StructField) bool) {
+	eg.Engine.SetFieldSkipper(skipper)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetFieldSkipper(skipper)
+	}
+}
+
 // SetMaxIdleConns set the max idle connections on pool, default is 2
 func (eg *EngineGroup) SetMaxIdleConns(conns int) {
 	eg.Engine.DB().SetMaxIdleConns(conns)
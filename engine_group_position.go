@@ -0,0 +1,192 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imkos/xorm/core"
+	"github.com/imkos/xorm/schemas"
+)
+
+// PositionChecker reads the replication position of the master after writes and checks
+// whether a slave has replayed it, so that the reads after writes could still be routed
+// to the slaves which have caught up.
+type PositionChecker interface {
+	// Position returns the current replication position of the master
+	Position(ctx context.Context, db *core.DB) (string, error)
+	// Reached returns true if the slave has replayed the position
+	Reached(ctx context.Context, db *core.DB, position string) (bool, error)
+}
+
+// positionCheckTTL is how long the result that a slave has not replayed a position is reused,
+// the slaves which have replayed a position won't fall behind it, so the result is always reused
+const positionCheckTTL = 100 * time.Millisecond
+
+// slavePosition is the result of the last check of the replication position on a slave
+type slavePosition struct {
+	position  string
+	reached   bool
+	checkedAt time.Time
+}
+
+type slavePositions struct {
+	mutex     sync.Mutex
+	positions map[*Engine]slavePosition
+}
+
+// MySQLGTIDChecker checks the replication position by the executed GTID set of MySQL
+type MySQLGTIDChecker struct{}
+
+// Position returns the executed GTID set of the master
+func (MySQLGTIDChecker) Position(ctx context.Context, db *core.DB) (string, error) {
+	var position string
+	err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.gtid_executed").Scan(&position)
+	return position, err
+}
+
+// Reached returns true if the GTID set is a subset of the executed GTID set of the slave
+func (MySQLGTIDChecker) Reached(ctx context.Context, db *core.DB, position string) (bool, error) {
+	var reached bool
+	err := db.QueryRowContext(ctx, "SELECT GTID_SUBSET(?, @@GLOBAL.gtid_executed)", position).Scan(&reached)
+	return reached, err
+}
+
+// PostgresLSNChecker checks the replication position by the WAL LSN of PostgreSQL
+type PostgresLSNChecker struct{}
+
+// Position returns the current WAL LSN of the master
+func (PostgresLSNChecker) Position(ctx context.Context, db *core.DB) (string, error) {
+	var position string
+	err := db.QueryRowContext(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&position)
+	return position, err
+}
+
+// Reached returns true if the slave has replayed the WAL LSN
+func (PostgresLSNChecker) Reached(ctx context.Context, db *core.DB, position string) (bool, error) {
+	var reached bool
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, false)", position).Scan(&reached)
+	return reached, err
+}
+
+// DefaultPositionChecker returns the position checker of the database, it's nil if the
+// database is not supported
+func DefaultPositionChecker(dbType schemas.DBType) PositionChecker {
+	switch dbType {
+	case schemas.MYSQL:
+		return MySQLGTIDChecker{}
+	case schemas.POSTGRES:
+		return PostgresLSNChecker{}
+	}
+	return nil
+}
+
+// SetPositionChecker enables the read-your-writes routing, the replication position of the
+// master is recorded after the writes of a group session or a sticky context, and the reads
+// routed to the slaves will only use the slaves which have replayed the position. Nil disables it.
+func (eg *EngineGroup) SetPositionChecker(checker PositionChecker) {
+	eg.positionChecker = checker
+}
+
+// ReplicationPosition returns the replication position of the master after the last write of
+// the group session, it could be passed to ReadAfter of another session as a token to read
+// the writes, i.e. the next request of the same user.
+func (session *Session) ReplicationPosition() string {
+	return session.position
+}
+
+// ReadAfter makes the reads of the group session only be routed to the slaves which have
+// replayed the replication position returned by ReplicationPosition
+func (session *Session) ReadAfter(position string) *Session {
+	session.guardStatement()
+	session.position = position
+	return session
+}
+
+// recordPosition records the replication position of the master after a write
+func (session *Session) recordPosition() {
+	eg := session.engine.engineGroup
+	if eg.positionChecker == nil {
+		return
+	}
+	position, err := eg.positionChecker.Position(session.ctx, eg.Engine.DB())
+	if err != nil {
+		session.engine.logger.Warnf("read replication position failed: %v", err)
+		return
+	}
+	session.position = position
+	if state, ok := session.ctx.Value(stickyContextKey{}).(*stickyState); ok {
+		state.mutex.Lock()
+		state.position = position
+		state.mutex.Unlock()
+	}
+}
+
+// readPosition returns the replication position which the reads of the session should wait
+func (session *Session) readPosition() string {
+	if session.position != "" {
+		return session.position
+	}
+	if state, ok := session.ctx.Value(stickyContextKey{}).(*stickyState); ok {
+		state.mutex.Lock()
+		defer state.mutex.Unlock()
+		return state.position
+	}
+	return ""
+}
+
+// readSlave returns a slave to execute the reads of the session, the slave chosen by the policy
+// is preferred. It returns nil if none of the slaves has replayed the position of the session.
+func (session *Session) readSlave() *Engine {
+	eg := session.engine.engineGroup
+	slave := eg.Slave()
+	position := session.readPosition()
+	if eg.positionChecker == nil || position == "" {
+		return slave
+	}
+
+	candidates := append([]*Engine{slave}, eg.slaves...)
+	for i, candidate := range candidates {
+		if candidate == eg.Engine || (i > 0 && candidate == slave) {
+			continue
+		}
+		reached, err := eg.reachedPosition(session.ctx, candidate, position)
+		if err != nil {
+			session.engine.logger.Warnf("check replication position failed: %v", err)
+			continue
+		}
+		if reached {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// reachedPosition returns true if the slave has replayed the position, the result of the last
+// check of the slave is reused if it's for the same position
+func (eg *EngineGroup) reachedPosition(ctx context.Context, slave *Engine, position string) (bool, error) {
+	cache := eg.slavePositions
+	cache.mutex.Lock()
+	last, ok := cache.positions[slave]
+	cache.mutex.Unlock()
+	if ok && last.position == position && (last.reached || time.Since(last.checkedAt) < positionCheckTTL) {
+		return last.reached, nil
+	}
+
+	reached, err := eg.positionChecker.Reached(ctx, slave.DB(), position)
+	if err != nil {
+		return false, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.positions == nil {
+		cache.positions = make(map[*Engine]slavePosition)
+	}
+	cache.positions[slave] = slavePosition{position: position, reached: reached, checkedAt: time.Now()}
+	return reached, nil
+}
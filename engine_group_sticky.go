@@ -0,0 +1,152 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type stickyContextKey struct{}
+
+// GroupConsistency represents how the reads of the group sessions are routed after writes
+type GroupConsistency int
+
+const (
+	// EventualConsistency routes the reads to the slaves by the policy even after writes,
+	// unless the reads are executed with a sticky context
+	EventualConsistency GroupConsistency = iota
+	// SessionConsistency routes the reads of a group session to the master for the rest
+	// of the session after it executed a write
+	SessionConsistency
+)
+
+// Route represents the routing of the reads of a group session
+type Route int
+
+const (
+	// RouteAuto routes the reads by the consistency of the engine group and the sticky context
+	RouteAuto Route = iota
+	// RoutePrimary routes the reads to the master
+	RoutePrimary
+	// RouteReplicaPreferred routes the reads to the slaves even if the session or the sticky
+	// context has written. If a position checker is set, only the slaves which have replayed the
+	// writes will be used, the reads will be routed to the master if none of the slaves has.
+	RouteReplicaPreferred
+)
+
+// stickyState records the last write time and the replication position after the last write
+// of a sticky context
+type stickyState struct {
+	mutex     sync.Mutex
+	lastWrite time.Time
+	position  string
+}
+
+// NewStickyContext returns a context which remembers the writes executed with it through
+// an engine group session, so that the reads executed with the context after a write will
+// be routed to the master within the sticky duration of the engine group.
+func NewStickyContext(parent context.Context) context.Context {
+	if _, ok := parent.Value(stickyContextKey{}).(*stickyState); ok {
+		return parent
+	}
+	return context.WithValue(parent, stickyContextKey{}, &stickyState{})
+}
+
+// SetStickyDuration sets how long the reads will be routed to master after a write was
+// executed with a context created by NewStickyContext. Zero disables the stickiness.
+func (eg *EngineGroup) SetStickyDuration(d time.Duration) {
+	eg.stickyDuration.Store(int64(d))
+}
+
+// SetConsistency sets how the reads of the group sessions are routed after writes
+func (eg *EngineGroup) SetConsistency(consistency GroupConsistency) {
+	eg.consistency = consistency
+}
+
+// UsePrimary routes all the reads of the group session to the master for the rest of the
+// session, it overrides the consistency of the engine group and the sticky context
+func (session *Session) UsePrimary() *Session {
+	session.guardStatement()
+	session.readRoute = RoutePrimary
+	return session
+}
+
+// UseReplica routes the reads of the group session to the slaves for the rest of the session,
+// even if the session or the sticky context has written. The reads in a transaction or with
+// ForUpdate are always executed on the master.
+func (session *Session) UseReplica() *Session {
+	session.guardStatement()
+	session.readRoute = RouteReplicaPreferred
+	return session
+}
+
+// RouteTo sets the routing of the next query of the group session, it overrides UsePrimary,
+// UseReplica, the consistency of the engine group and the sticky context
+func (session *Session) RouteTo(route Route) *Session {
+	session.guardStatement()
+	session.queryRoute = route
+	return session
+}
+
+// RouteTo sets the routing of the next query of a group session
+func (eg *EngineGroup) RouteTo(route Route) *Session {
+	session := eg.NewSession()
+	session.isAutoClose = true
+	return session.RouteTo(route)
+}
+
+// markWrite records a write of the group session
+func (session *Session) markWrite() {
+	session.hasWritten = true
+	session.engine.engineGroup.markWrite(session.ctx)
+}
+
+// readFromSlave returns true if the reads of the group session could be routed to a slave
+func (session *Session) readFromSlave() bool {
+	route := session.queryRoute
+	if route == RouteAuto {
+		route = session.readRoute
+	}
+	switch route {
+	case RoutePrimary:
+		return false
+	case RouteReplicaPreferred:
+		return true
+	}
+	eg := session.engine.engineGroup
+	if eg.consistency == SessionConsistency && session.hasWritten {
+		return false
+	}
+	return !eg.isSticky(session.ctx)
+}
+
+// markWrite records a write on the context
+func (eg *EngineGroup) markWrite(ctx context.Context) {
+	if eg.stickyDuration.Load() <= 0 {
+		return
+	}
+	if state, ok := ctx.Value(stickyContextKey{}).(*stickyState); ok {
+		state.mutex.Lock()
+		state.lastWrite = time.Now()
+		state.mutex.Unlock()
+	}
+}
+
+// isSticky returns true if the reads of the context should be routed to master
+func (eg *EngineGroup) isSticky(ctx context.Context) bool {
+	stickyDuration := time.Duration(eg.stickyDuration.Load())
+	if stickyDuration <= 0 {
+		return false
+	}
+	state, ok := ctx.Value(stickyContextKey{}).(*stickyState)
+	if !ok {
+		return false
+	}
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return !state.lastWrite.IsZero() && time.Since(state.lastWrite) < stickyDuration
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import "context"
+
+// SQLInterceptor intercepts a SQL and its args before they are sent to the database. It could
+// rewrite them, i.e. inject comments or shard hints, or return an error to stop the execution,
+// i.e. a read-only guard.
+type SQLInterceptor func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error)
+
+// AddSQLInterceptor adds a SQL interceptor, the interceptors are executed in the order they are
+// added, after the dialect filters and before the SQL is executed. It should be called before
+// the engine is used concurrently.
+func (engine *Engine) AddSQLInterceptor(interceptor SQLInterceptor) {
+	engine.sqlInterceptors = append(engine.sqlInterceptors, interceptor)
+}
+
+// AddSQLInterceptor adds a SQL interceptor for all the engines of the group
+func (eg *EngineGroup) AddSQLInterceptor(interceptor SQLInterceptor) {
+	eg.Engine.AddSQLInterceptor(interceptor)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].AddSQLInterceptor(interceptor)
+	}
+}
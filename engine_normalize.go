@@ -0,0 +1,157 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imkos/xorm/core"
+	"github.com/imkos/xorm/schemas"
+)
+
+// DecimalMode represents how QueryInterface returns the DECIMAL and NUMERIC columns
+type DecimalMode int
+
+// enumerates all the decimal modes
+const (
+	// DecimalAsDriver keeps the type returned by the driver, it's the default mode
+	DecimalAsDriver DecimalMode = iota
+	// DecimalAsString returns the decimals as strings which keep the precision
+	DecimalAsString
+	// DecimalAsFloat returns the decimals as float64
+	DecimalAsFloat
+)
+
+// TimeMode represents how QueryInterface returns the time columns
+type TimeMode int
+
+// enumerates all the time modes
+const (
+	// TimeAsDriver keeps the type returned by the driver, it's the default mode
+	TimeAsDriver TimeMode = iota
+	// TimeAsString returns the times as strings formatted in the engine's TZLocation
+	TimeAsString
+	// TimeAsTime returns the times as time.Time in the engine's TZLocation, NULL is nil
+	TimeAsTime
+)
+
+// BlobMode represents how QueryInterface returns the binary columns
+type BlobMode int
+
+// enumerates all the blob modes
+const (
+	// BlobAsDriver keeps the type returned by the driver, it's the default mode
+	BlobAsDriver BlobMode = iota
+	// BlobAsBytes returns the binaries as []byte
+	BlobAsBytes
+	// BlobAsString returns the binaries as strings
+	BlobAsString
+)
+
+// ValueNormalization represents how QueryInterface normalizes the values of the columns, so
+// the same column has the same Go type whatever the driver is
+type ValueNormalization struct {
+	Decimal DecimalMode
+	Time    TimeMode
+	Blob    BlobMode
+}
+
+// SetValueNormalization sets how QueryInterface and ScanInterfaceMap(s) normalize the values
+// returned by the driver
+func (engine *Engine) SetValueNormalization(normalization ValueNormalization) {
+	engine.valueNormalization = normalization
+}
+
+// SetValueNormalization sets the value normalization for all the engines of the group
+func (eg *EngineGroup) SetValueNormalization(normalization ValueNormalization) {
+	eg.Engine.SetValueNormalization(normalization)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetValueNormalization(normalization)
+	}
+}
+
+// isDecimalType returns true if the numeric database type is an exact decimal
+func isDecimalType(typeName string) bool {
+	switch typeName {
+	case "DECIMAL", "NUMERIC", "NUMBER", "DEC", "MONEY", "SMALLMONEY", "DECFLOAT":
+		return true
+	}
+	return false
+}
+
+// normalizeValue normalizes the value converted from the scan result according to the
+// engine's value normalization
+func (engine *Engine) normalizeValue(scanResult, v interface{}, tp *sql.ColumnType) (interface{}, error) {
+	n := engine.valueNormalization
+	if n == (ValueNormalization{}) {
+		return v, nil
+	}
+
+	typeName := strings.ToUpper(tp.DatabaseTypeName())
+	switch engine.dialect.ColumnTypeKind(typeName) {
+	case schemas.TIME_TYPE:
+		if n.Time == TimeAsDriver {
+			return v, nil
+		}
+		if isNullScanResult(scanResult) {
+			if n.Time == TimeAsTime {
+				return nil, nil
+			}
+			return v, nil
+		}
+		t, err := engine.normalizeTime(scanResult, v, tp)
+		if err != nil {
+			return nil, err
+		}
+		if n.Time == TimeAsTime {
+			return t, nil
+		}
+		return t.Format("2006-01-02 15:04:05"), nil
+	case schemas.BLOB_TYPE:
+		switch n.Blob {
+		case BlobAsBytes:
+			if s, ok := v.(string); ok {
+				return []byte(s), nil
+			}
+		case BlobAsString:
+			if b, ok := v.([]byte); ok {
+				return string(b), nil
+			}
+		}
+	case schemas.NUMERIC_TYPE:
+		if n.Decimal == DecimalAsDriver || !isDecimalType(typeName) || isNullScanResult(scanResult) {
+			return v, nil
+		}
+		// the decimals are decoded as text to keep the precision
+		s, err := core.DecodeValue(v, tp, schemas.TEXT_TYPE, engine.DatabaseTZ)
+		if err != nil || n.Decimal == DecimalAsString {
+			return s, err
+		}
+		return strconv.ParseFloat(s.(string), 64)
+	}
+	return v, nil
+}
+
+// normalizeTime decodes the time in the database's time zone and returns it in the engine's
+// TZLocation. The value of sql.NullTime has been formatted in TZLocation, so its time is used.
+func (engine *Engine) normalizeTime(scanResult, v interface{}, tp *sql.ColumnType) (time.Time, error) {
+	if nt, ok := scanResult.(*sql.NullTime); ok {
+		return nt.Time.In(engine.TZLocation), nil
+	}
+
+	d, err := core.DecodeValue(v, tp, schemas.TIME_TYPE, engine.DatabaseTZ)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, ok := d.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unsupported time value %#v", v)
+	}
+	return t.In(engine.TZLocation), nil
+}
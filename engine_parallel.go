@@ -0,0 +1,120 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"xorm.io/builder"
+)
+
+// RangeSpec represents how ParallelFind splits the records of a table
+type RangeSpec struct {
+	// Column is an integer column to split the records, usually the primary key
+	Column string
+	// Workers is the number of the concurrent range queries, default is GOMAXPROCS
+	Workers int
+}
+
+// ParallelFindFunc only use by ParallelFind, worker is the index of the range which the bean belongs to
+type ParallelFindFunc func(worker int, bean interface{}) error
+
+// ParallelFind splits the range between the minimum and maximum value of spec.Column
+// into spec.Workers sub ranges, and queries them concurrently. Every record found is
+// passed to fn which will be called from multiple goroutines, so it should be safe for
+// concurrent use. bean's non-empty fields are conditions. The first error returned by
+// a query or fn cancels the other queries and is returned.
+func (engine *Engine) ParallelFind(ctx context.Context, bean interface{}, spec RangeSpec, fn ParallelFindFunc) error {
+	if spec.Column == "" {
+		return errors.New("ParallelFind needs a column to split the records")
+	}
+	workers := spec.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	colName := engine.Quote(spec.Column)
+
+	// the range is probed with the same conditions as the range queries
+	var minID, maxID sql.NullInt64
+	probe := engine.NewSession().Context(ctx)
+	defer probe.Close()
+	if err := probe.aggregate(&minID, bean, "MIN(%s)", spec.Column); err != nil {
+		return err
+	}
+	if err := probe.aggregate(&maxID, bean, "MAX(%s)", spec.Column); err != nil {
+		return err
+	}
+	if !minID.Valid || !maxID.Valid {
+		return nil
+	}
+
+	// the offsets from the minimum value are unsigned, so the range between math.MinInt64
+	// and math.MaxInt64 doesn't overflow
+	span := uint64(maxID.Int64 - minID.Int64)
+	step := span/uint64(workers) + 1
+	if step == 0 {
+		step = span
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < workers && uint64(i)*step <= span; i++ {
+		lo := uint64(i) * step
+		hi := lo + step - 1
+		if hi < lo || hi > span || i == workers-1 {
+			hi = span
+		}
+		start, end := minID.Int64+int64(lo), minID.Int64+int64(hi)
+
+		wg.Add(1)
+		go func(worker int, start, end int64) {
+			defer wg.Done()
+			err := engine.findRange(ctx, bean, builder.Gte{colName: start}.And(builder.Lte{colName: end}), func(b interface{}) error {
+				return fn(worker, b)
+			})
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i, start, end)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// findRange queries the records of the range and passes them to fn one by one
+func (engine *Engine) findRange(ctx context.Context, bean interface{}, rangeCond builder.Cond, fn func(bean interface{}) error) error {
+	session := engine.NewSession().Context(ctx)
+	defer session.Close()
+
+	rows, err := session.Where(rangeCond).Rows(bean)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		b := reflect.New(rows.beanType).Interface()
+		if err := rows.Scan(b); err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,21 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"io"
+
+	"github.com/imkos/xorm/reverse"
+)
+
+// GenerateModels reads the tables of the database and writes the Go structs with
+// xorm tags of them to w, see reverse.Options for the options.
+func (engine *Engine) GenerateModels(w io.Writer, opts reverse.Options) error {
+	tables, err := engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	return reverse.Generate(w, engine.dialect.URI().DBType, tables, opts)
+}
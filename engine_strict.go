@@ -0,0 +1,97 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imkos/xorm/schemas"
+)
+
+// StrictMode represents how the mismatches between the result sets and the structs are handled
+type StrictMode int
+
+// enumerates all the strict modes
+const (
+	// StrictOff ignores the mismatches, it's the default mode
+	StrictOff StrictMode = iota
+	// StrictLog logs a warning when the result set doesn't match the struct
+	StrictLog
+	// StrictError makes Find, Get, Iterate and Rows fail with ErrStrictMapping
+	StrictError
+)
+
+// ErrStrictMapping represents the result set of a query doesn't match the struct in strict mode
+type ErrStrictMapping struct {
+	TableName string
+	// UnmappedColumns are the columns of the result set which have no struct field
+	UnmappedColumns []string
+	// MissingColumns are the columns of the struct fields which are not in the result set
+	MissingColumns []string
+}
+
+func (err ErrStrictMapping) Error() string {
+	var reasons []string
+	if len(err.UnmappedColumns) > 0 {
+		reasons = append(reasons, fmt.Sprintf("columns %s have no field", strings.Join(err.UnmappedColumns, ", ")))
+	}
+	if len(err.MissingColumns) > 0 {
+		reasons = append(reasons, fmt.Sprintf("columns %s are not in the result", strings.Join(err.MissingColumns, ", ")))
+	}
+	return fmt.Sprintf("result set mismatches table %s: %s", err.TableName, strings.Join(reasons, "; "))
+}
+
+// SetStrictMode sets how the mismatches between the result sets and the structs of Find, Get,
+// Iterate and Rows are handled. The columns of the result set without a struct field are
+// always reported, the struct fields without a column are only reported if the columns are
+// not chosen by Cols, Omit, Select or a raw SQL, so that the typos and the schema drifts are
+// found early. The invisible columns are never reported since they are not selected by *.
+func (engine *Engine) SetStrictMode(mode StrictMode) {
+	engine.strictMode = mode
+}
+
+// SetStrictMode sets the strict mode for all the engines of the group
+func (eg *EngineGroup) SetStrictMode(mode StrictMode) {
+	eg.Engine.SetStrictMode(mode)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetStrictMode(mode)
+	}
+}
+
+// checkStrict checks the result set matches the struct according the engine's strict mode
+func (session *Session) checkStrict(columnsSchema *ColumnsSchema, table *schemas.Table) error {
+	if session.engine.strictMode == StrictOff || table == nil {
+		return nil
+	}
+
+	var unmapped, missing []string
+	mapped := make(map[*schemas.Column]bool, len(columnsSchema.Fields))
+	for _, field := range columnsSchema.Fields {
+		if field.ColumnSchema == nil {
+			unmapped = append(unmapped, field.FieldName)
+		} else {
+			mapped[field.ColumnSchema] = true
+		}
+	}
+
+	if session.allColumnsQueried {
+		for _, col := range table.Columns() {
+			if col.MapType != schemas.ONLYTODB && !col.IsInvisible && !mapped[col] {
+				missing = append(missing, col.Name)
+			}
+		}
+	}
+
+	if len(unmapped) == 0 && len(missing) == 0 {
+		return nil
+	}
+	err := ErrStrictMapping{TableName: table.Name, UnmappedColumns: unmapped, MissingColumns: missing}
+	if session.engine.strictMode == StrictError {
+		return err
+	}
+	session.engine.logger.Warnf("[strict] %v", err)
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type timeoutRule struct {
+	pattern string
+	timeout time.Duration
+}
+
+type timeoutRules struct {
+	mutex sync.RWMutex
+	rules []timeoutRule
+}
+
+func (rules *timeoutRules) add(pattern string, timeout time.Duration) {
+	rules.mutex.Lock()
+	defer rules.mutex.Unlock()
+	for i, rule := range rules.rules {
+		if rule.pattern == pattern {
+			if timeout <= 0 {
+				rules.rules = append(rules.rules[:i], rules.rules[i+1:]...)
+			} else {
+				rules.rules[i].timeout = timeout
+			}
+			return
+		}
+	}
+	if timeout <= 0 {
+		return
+	}
+	rules.rules = append(rules.rules, timeoutRule{pattern: pattern, timeout: timeout})
+}
+
+// match returns the timeout of the first rule matched the table name
+func (rules *timeoutRules) match(tableName string) (time.Duration, bool) {
+	if tableName == "" {
+		return 0, false
+	}
+	rules.mutex.RLock()
+	defer rules.mutex.RUnlock()
+	if len(rules.rules) == 0 {
+		return 0, false
+	}
+
+	// the table name maybe quoted and prefixed with the schema
+	tableName = strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(tableName)
+	shortName := tableName
+	if idx := strings.LastIndexByte(tableName, '.'); idx > -1 {
+		shortName = tableName[idx+1:]
+	}
+	for _, rule := range rules.rules {
+		if ok, _ := path.Match(rule.pattern, shortName); ok {
+			return rule.timeout, true
+		}
+		if ok, _ := path.Match(rule.pattern, tableName); ok {
+			return rule.timeout, true
+		}
+	}
+	return 0, false
+}
+
+// SetTimeoutFor sets the timeout of the SQLs executed on the tables whose name match the
+// pattern, i.e. engine.SetTimeoutFor("reports_*", 30*time.Second). The pattern syntax is
+// the same as path.Match, and the rules are evaluated in the order they are added.
+// A rule with the same pattern will be replaced, and a non-positive timeout removes it.
+func (engine *Engine) SetTimeoutFor(pattern string, timeout time.Duration) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	engine.timeoutRules.add(pattern, timeout)
+	return nil
+}
+
+// SetTimeoutFor sets the timeout rule for all the engines of the group
+func (eg *EngineGroup) SetTimeoutFor(pattern string, timeout time.Duration) error {
+	if err := eg.Engine.SetTimeoutFor(pattern, timeout); err != nil {
+		return err
+	}
+	for i := 0; i < len(eg.slaves); i++ {
+		if err := eg.slaves[i].SetTimeoutFor(pattern, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statementContext returns the context to execute current statement, if there is a
+// timeout rule matched the statement's table, the context will be with a deadline and
+// the returned cancel function will be non-nil.
+func (session *Session) statementContext() (context.Context, context.CancelFunc) {
+	timeout, ok := session.engine.timeoutRules.match(session.statement.TableName())
+	if !ok {
+		return session.ctx, nil
+	}
+	return context.WithTimeout(session.ctx, timeout)
+}
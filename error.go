@@ -5,7 +5,11 @@
 package xorm
 
 import (
+	"database/sql"
 	"errors"
+
+	"github.com/imkos/xorm/internal/statements"
+	"github.com/imkos/xorm/internal/utils"
 )
 
 var (
@@ -23,4 +27,48 @@ var (
 	ErrCacheFailed = errors.New("Cache failed")
 	// ErrConditionType condition type unsupported
 	ErrConditionType = errors.New("Unsupported condition type")
+	// ErrUnknownSortKey represents a sort key is not allowed by OrderBySafe
+	ErrUnknownSortKey = errors.New("Unknown sort key")
+	// ErrUnknownFilterKey represents a filter field or operator is not allowed by FilterBy
+	ErrUnknownFilterKey = errors.New("Unknown filter key")
+	// ErrUnknownScope represents a scope is not defined by DefineScope
+	ErrUnknownScope = errors.New("Unknown scope")
+	// ErrChunkNeedSinglePK represents Chunk is called on a table without exactly one primary key
+	ErrChunkNeedSinglePK = errors.New("Chunk needs a table with exactly one primary key")
+	// ErrScanCountMismatch represents the number of the scan destinations is not equal to the columns
+	ErrScanCountMismatch = errors.New("Scan destinations mismatch the columns")
+	// ErrMissingParam represents a named parameter of a prepared query is not given
+	ErrMissingParam = errors.New("Missing parameter")
+	// ErrFailoverNotEnabled represents Failover is called before EnableFailover
+	ErrFailoverNotEnabled = errors.New("Failover is not enabled")
+	// ErrNeedRawSQL represents ScanStructs is called without a raw SQL set by SQL()
+	ErrNeedRawSQL = errors.New("A raw SQL set by SQL() is needed")
+	// ErrDuplicateColumn represents several fields of a model are mapped to the same column
+	ErrDuplicateColumn = errors.New("Duplicate column")
+	// ErrSchemaMismatch represents a model doesn't match the table in the database
+	ErrSchemaMismatch = errors.New("Model mismatches the database schema")
+	// ErrEmptySliceArg represents an empty slice is given for the placeholder of IN (?)
+	ErrEmptySliceArg = utils.ErrEmptySliceArg
+	// ErrNotFound represents no record is found by One, it matches sql.ErrNoRows by errors.Is
+	ErrNotFound error = notFoundError{}
+	// ErrMultipleRows represents more than one record is found by One in strict mode
+	ErrMultipleRows = errors.New("Multiple records are found")
 )
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string {
+	return "Record not found"
+}
+
+func (notFoundError) Is(target error) bool {
+	return target == sql.ErrNoRows
+}
+
+// ErrColumnNotFound represents a column given by Cols, Omit, MustCols, Incr, Decr or SetExpr
+// is not a column of the table
+type ErrColumnNotFound = statements.ErrColumnNotFound
+
+// ErrUnsupportedLimit represents the limit or the offset of an operation is not supported by
+// the database, i.e. the offset of Update and Delete
+type ErrUnsupportedLimit = statements.ErrUnsupportedLimit
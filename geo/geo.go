@@ -0,0 +1,245 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package geo contains the spatial types which could be stored into the geometry
+// or geography columns of PostGIS, MySQL and SpatiaLite.
+package geo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/imkos/xorm/schemas"
+)
+
+var (
+	// ErrInvalidGeometry represents the data is not a valid WKB or WKT
+	ErrInvalidGeometry = errors.New("invalid geometry")
+	// ErrUnsupportedGeometry represents the geometry type is not supported
+	ErrUnsupportedGeometry = errors.New("unsupported geometry")
+)
+
+// Geometry represents a spatial value, a struct implementing it could be used as the field
+// of a bean and it will be mapped to a GEOMETRY column.
+type Geometry interface {
+	// MarshalWKB returns the SRID and the WKB of the geometry
+	MarshalWKB() (srid uint32, wkb []byte, err error)
+	// UnmarshalWKB sets the geometry from the SRID and the WKB read from the database
+	UnmarshalWKB(srid uint32, wkb []byte) error
+}
+
+// Encode converts the geometry to the value which could be passed to the driver of dbType.
+// It's a hex EWKB string for PostgreSQL, the internal format of MySQL which is the SRID
+// followed by the WKB, the BLOB geometry of SpatiaLite for SQLite and the WKB for the others.
+func Encode(dbType schemas.DBType, g Geometry) (interface{}, error) {
+	srid, wkb, err := g.MarshalWKB()
+	if err != nil {
+		return nil, err
+	}
+	switch dbType {
+	case schemas.POSTGRES:
+		ewkb, err := toEWKB(srid, wkb)
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(ewkb), nil
+	case schemas.MYSQL:
+		res := make([]byte, 4, 4+len(wkb))
+		binary.LittleEndian.PutUint32(res, srid)
+		return append(res, wkb...), nil
+	case schemas.SQLITE:
+		return toSpatiaLite(srid, wkb)
+	}
+	return wkb, nil
+}
+
+// Decode sets the geometry from the data read from the database, the data could be
+// a WKB, an EWKB, a hex EWKB, the internal format of MySQL or the BLOB geometry of SpatiaLite.
+func Decode(data []byte, g Geometry) error {
+	srid, wkb, err := decode(data)
+	if err != nil {
+		return err
+	}
+	return g.UnmarshalWKB(srid, wkb)
+}
+
+func decode(data []byte) (uint32, []byte, error) {
+	if isHex(data) {
+		b, err := hex.DecodeString(string(data))
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		data = b
+	}
+	// the internal format of MySQL could look like SpatiaLite, so the others are tried if it fails
+	var spatialiteErr error
+	if isSpatiaLite(data) {
+		srid, wkb, err := fromSpatiaLite(data)
+		if err == nil {
+			return srid, wkb, nil
+		}
+		spatialiteErr = err
+	}
+	srid, wkb, err := fromEWKB(data)
+	if err == nil {
+		return srid, wkb, nil
+	}
+	if len(data) > 4 {
+		if _, wkb, err := fromEWKB(data[4:]); err == nil {
+			return binary.LittleEndian.Uint32(data[:4]), wkb, nil
+		}
+	}
+	if spatialiteErr != nil {
+		return 0, nil, spatialiteErr
+	}
+	return 0, nil, err
+}
+
+func isHex(data []byte) bool {
+	if len(data) == 0 || len(data)%2 != 0 {
+		return false
+	}
+	for _, c := range data {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// Coord represents a coordinate
+type Coord struct {
+	X, Y float64
+}
+
+// Point represents a point
+type Point struct {
+	X, Y float64
+	SRID uint32
+}
+
+// MarshalWKB implements Geometry
+func (p Point) MarshalWKB() (uint32, []byte, error) {
+	var w wkbWriter
+	w.header(wkbPoint)
+	w.coord(Coord{p.X, p.Y})
+	return p.SRID, w.buf, nil
+}
+
+// UnmarshalWKB implements Geometry
+func (p *Point) UnmarshalWKB(srid uint32, wkb []byte) error {
+	r, err := newWKBReader(wkb, wkbPoint)
+	if err != nil {
+		return err
+	}
+	c, err := r.coord()
+	if err != nil {
+		return err
+	}
+	*p = Point{X: c.X, Y: c.Y, SRID: srid}
+	return nil
+}
+
+// WKT returns the WKT of the point
+func (p Point) WKT() string {
+	return "POINT(" + formatCoord(Coord{p.X, p.Y}) + ")"
+}
+
+// LineString represents a line string
+type LineString struct {
+	Coords []Coord
+	SRID   uint32
+}
+
+// MarshalWKB implements Geometry
+func (l LineString) MarshalWKB() (uint32, []byte, error) {
+	var w wkbWriter
+	w.header(wkbLineString)
+	w.coords(l.Coords)
+	return l.SRID, w.buf, nil
+}
+
+// UnmarshalWKB implements Geometry
+func (l *LineString) UnmarshalWKB(srid uint32, wkb []byte) error {
+	r, err := newWKBReader(wkb, wkbLineString)
+	if err != nil {
+		return err
+	}
+	coords, err := r.coords()
+	if err != nil {
+		return err
+	}
+	*l = LineString{Coords: coords, SRID: srid}
+	return nil
+}
+
+// WKT returns the WKT of the line string
+func (l LineString) WKT() string {
+	if len(l.Coords) == 0 {
+		return "LINESTRING EMPTY"
+	}
+	return "LINESTRING" + formatCoords(l.Coords)
+}
+
+// Polygon represents a polygon, the first ring is the exterior ring and the others are the holes
+type Polygon struct {
+	Rings [][]Coord
+	SRID  uint32
+}
+
+// MarshalWKB implements Geometry
+func (p Polygon) MarshalWKB() (uint32, []byte, error) {
+	var w wkbWriter
+	w.header(wkbPolygon)
+	w.uint32(uint32(len(p.Rings)))
+	for _, ring := range p.Rings {
+		w.coords(ring)
+	}
+	return p.SRID, w.buf, nil
+}
+
+// UnmarshalWKB implements Geometry
+func (p *Polygon) UnmarshalWKB(srid uint32, wkb []byte) error {
+	r, err := newWKBReader(wkb, wkbPolygon)
+	if err != nil {
+		return err
+	}
+	n, err := r.uint32()
+	if err != nil {
+		return err
+	}
+	rings := make([][]Coord, 0, n)
+	for i := uint32(0); i < n; i++ {
+		ring, err := r.coords()
+		if err != nil {
+			return err
+		}
+		rings = append(rings, ring)
+	}
+	*p = Polygon{Rings: rings, SRID: srid}
+	return nil
+}
+
+// WKT returns the WKT of the polygon
+func (p Polygon) WKT() string {
+	if len(p.Rings) == 0 {
+		return "POLYGON EMPTY"
+	}
+	s := "POLYGON("
+	for i, ring := range p.Rings {
+		if i > 0 {
+			s += ","
+		}
+		s += formatCoords(ring)
+	}
+	return s + ")"
+}
+
+var (
+	_ Geometry = &Point{}
+	_ Geometry = &LineString{}
+	_ Geometry = &Polygon{}
+)
@@ -0,0 +1,133 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	geometries := []Geometry{
+		&Point{X: 1.5, Y: -2, SRID: 4326},
+		&LineString{Coords: []Coord{{0, 0}, {1, 1}, {2, 0}}},
+		&Polygon{Rings: [][]Coord{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}, SRID: 3857},
+	}
+	for _, g := range geometries {
+		for _, dbType := range []schemas.DBType{schemas.POSTGRES, schemas.MYSQL, schemas.SQLITE} {
+			v, err := Encode(dbType, g)
+			assert.NoError(t, err)
+
+			var data []byte
+			switch vv := v.(type) {
+			case string:
+				data = []byte(vv)
+			case []byte:
+				data = vv
+			}
+
+			var res Geometry
+			switch g.(type) {
+			case *Point:
+				res = new(Point)
+			case *LineString:
+				res = new(LineString)
+			case *Polygon:
+				res = new(Polygon)
+			}
+			assert.NoError(t, Decode(data, res), dbType)
+
+			srid, _, _ := g.MarshalWKB()
+			resSrid, _, _ := res.MarshalWKB()
+			assert.EqualValues(t, srid, resSrid, dbType)
+			assert.EqualValues(t, g.(interface{ WKT() string }).WKT(), res.(interface{ WKT() string }).WKT(), dbType)
+		}
+	}
+}
+
+func TestDecodePostGIS(t *testing.T) {
+	// SELECT 'SRID=4326;POINT(1 2)'::geometry
+	var p Point
+	assert.NoError(t, Decode([]byte("0101000020E6100000000000000000F03F0000000000000040"), &p))
+	assert.EqualValues(t, Point{X: 1, Y: 2, SRID: 4326}, p)
+
+	v, err := Encode(schemas.POSTGRES, &p)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "0101000020e6100000000000000000f03f0000000000000040", v)
+
+	// a big endian WKB
+	data, err := hex.DecodeString("00000000013ff00000000000004000000000000000")
+	assert.NoError(t, err)
+	assert.NoError(t, Decode(data, &p))
+	assert.EqualValues(t, Point{X: 1, Y: 2}, p)
+
+	var l LineString
+	assert.ErrorIs(t, Decode(data, &l), ErrUnsupportedGeometry)
+	assert.ErrorIs(t, Decode([]byte{1, 2, 3}, &p), ErrInvalidGeometry)
+}
+
+func TestDecodeSpatiaLite(t *testing.T) {
+	// SELECT MakePoint(1, 2, 4326)
+	data, err := hex.DecodeString("0001E6100000000000000000F03F0000000000000040000000000000F03F00000000000000407C01000000000000000000F03F0000000000000040FE")
+	assert.NoError(t, err)
+	var p Point
+	assert.NoError(t, Decode(data, &p))
+	assert.EqualValues(t, Point{X: 1, Y: 2, SRID: 4326}, p)
+
+	v, err := Encode(schemas.SQLITE, &p)
+	assert.NoError(t, err)
+	assert.EqualValues(t, data, v)
+
+	// SELECT GeomFromText('MULTIPOINT(1 2, 3 4)', 4326), the entities are marked by 0x69
+	data, err = hex.DecodeString("0001E6100000000000000000F03F00000000000000400000000000000840000000000000104" +
+		"07C04000000020000006901000000000000000000F03F0000000000000040690100000000000000000008400000000000001040FE")
+	assert.NoError(t, err)
+	srid, wkb, err := decode(data)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4326, srid)
+	assert.EqualValues(t, "0104000000020000000101000000000000000000F03F0000000000000040010100000000000000000008400000000000001040",
+		strings.ToUpper(hex.EncodeToString(wkb)))
+
+	res, err := toSpatiaLite(srid, wkb)
+	assert.NoError(t, err)
+	assert.EqualValues(t, data, res)
+
+	// the entity marker is missing
+	data[47] = 1
+	assert.ErrorIs(t, Decode(data, &p), ErrInvalidGeometry)
+}
+
+func TestParseWKT(t *testing.T) {
+	kases := []struct {
+		wkt      string
+		expected Geometry
+		output   string
+	}{
+		{"POINT(1 2)", &Point{X: 1, Y: 2}, "POINT(1 2)"},
+		{"SRID=4326; point ( 1.5 -2 )", &Point{X: 1.5, Y: -2, SRID: 4326}, "POINT(1.5 -2)"},
+		{"LINESTRING(0 0, 1 1)", &LineString{Coords: []Coord{{0, 0}, {1, 1}}}, "LINESTRING(0 0,1 1)"},
+		{"LINESTRING EMPTY", &LineString{}, "LINESTRING EMPTY"},
+		{
+			"POLYGON((0 0,4 0,4 4,0 0), (1 1,2 1,2 2,1 1))",
+			&Polygon{Rings: [][]Coord{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}},
+			"POLYGON((0 0,4 0,4 4,0 0),(1 1,2 1,2 2,1 1))",
+		},
+	}
+	for _, kase := range kases {
+		g, err := ParseWKT(kase.wkt)
+		assert.NoError(t, err, kase.wkt)
+		assert.EqualValues(t, kase.expected, g)
+		assert.EqualValues(t, kase.output, g.(interface{ WKT() string }).WKT())
+	}
+
+	_, err := ParseWKT("MULTIPOINT((1 2))")
+	assert.ErrorIs(t, err, ErrUnsupportedGeometry)
+	_, err = ParseWKT("POINT(1)")
+	assert.ErrorIs(t, err, ErrInvalidGeometry)
+}
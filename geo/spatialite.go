@@ -0,0 +1,136 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// the markers of the BLOB geometry of SpatiaLite, which is
+// START, byte order, SRID, MBR, MBR_END, geometry type, body and END,
+// and the byte order of every entity of a collection is replaced by ENTITY
+const (
+	spatialiteStart  byte = 0x00
+	spatialiteMBREnd byte = 0x7C
+	spatialiteEntity byte = 0x69
+	spatialiteEnd    byte = 0xFE
+
+	spatialiteHeaderLen = 39
+)
+
+// isSpatiaLite returns true if the data looks like a BLOB geometry of SpatiaLite
+func isSpatiaLite(data []byte) bool {
+	return len(data) >= spatialiteHeaderLen+5 && data[0] == spatialiteStart &&
+		data[spatialiteHeaderLen-1] == spatialiteMBREnd && data[len(data)-1] == spatialiteEnd
+}
+
+// toSpatiaLite converts the WKB to the BLOB geometry of SpatiaLite
+func toSpatiaLite(srid uint32, wkb []byte) ([]byte, error) {
+	if len(wkb) < 5 {
+		return nil, fmt.Errorf("%w: WKB is too short", ErrInvalidGeometry)
+	}
+	order, err := byteOrder(wkb[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		minX, minY, maxX, maxY float64
+		hasCoords              bool
+	)
+	if _, err := walkWKB(wkb, func(c Coord) {
+		if !hasCoords {
+			minX, minY, maxX, maxY = c.X, c.Y, c.X, c.Y
+			hasCoords = true
+			return
+		}
+		minX, minY = math.Min(minX, c.X), math.Min(minY, c.Y)
+		maxX, maxY = math.Max(maxX, c.X), math.Max(maxY, c.Y)
+	}); err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, spatialiteHeaderLen, spatialiteHeaderLen+len(wkb))
+	res[0] = spatialiteStart
+	res[1] = wkb[0]
+	order.PutUint32(res[2:], srid)
+	for i, v := range []float64{minX, minY, maxX, maxY} {
+		order.PutUint64(res[6+i*8:], math.Float64bits(v))
+	}
+	res[spatialiteHeaderLen-1] = spatialiteMBREnd
+
+	body := append([]byte(nil), wkb...)
+	if _, err := replaceEntityMarkers(body, wkb[0], spatialiteEntity); err != nil {
+		return nil, err
+	}
+	res = append(res, body[1:]...)
+	return append(res, spatialiteEnd), nil
+}
+
+// fromSpatiaLite validates the BLOB geometry of SpatiaLite and returns the SRID and the WKB
+func fromSpatiaLite(data []byte) (uint32, []byte, error) {
+	if !isSpatiaLite(data) {
+		return 0, nil, fmt.Errorf("%w: not a SpatiaLite geometry", ErrInvalidGeometry)
+	}
+	order, err := byteOrder(data[1])
+	if err != nil {
+		return 0, nil, err
+	}
+	srid := order.Uint32(data[2:6])
+
+	wkb := make([]byte, 1, len(data)-spatialiteHeaderLen)
+	wkb[0] = data[1]
+	wkb = append(wkb, data[spatialiteHeaderLen:len(data)-1]...)
+	n, err := replaceEntityMarkers(wkb, spatialiteEntity, data[1])
+	if err != nil {
+		return 0, nil, err
+	}
+	if n != len(wkb) {
+		return 0, nil, fmt.Errorf("%w: %d extra bytes after SpatiaLite geometry", ErrInvalidGeometry, len(wkb)-n)
+	}
+	return srid, wkb, nil
+}
+
+// replaceEntityMarkers replaces the first byte of the entities of the collections in the
+// geometry at the beginning of wkb, which is from, with to, and returns the length of
+// the geometry. The first byte of the geometry itself should be its byte order.
+func replaceEntityMarkers(wkb []byte, from, to byte) (int, error) {
+	if len(wkb) < 5 {
+		return 0, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+	}
+	order, err := byteOrder(wkb[0])
+	if err != nil {
+		return 0, err
+	}
+	switch order.Uint32(wkb[1:5]) {
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+	default:
+		return wkbLen(wkb)
+	}
+
+	r := &wkbReader{data: wkb[5:], order: order}
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint32(0); i < n; i++ {
+		if len(r.data) == 0 {
+			return 0, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+		}
+		if r.data[0] != from {
+			return 0, fmt.Errorf("%w: unexpected entity marker %d", ErrInvalidGeometry, r.data[0])
+		}
+		// the entity is read in the byte order of the geometry
+		r.data[0] = wkb[0]
+		l, err := replaceEntityMarkers(r.data, from, to)
+		if err != nil {
+			return 0, err
+		}
+		r.data[0] = to
+		r.data = r.data[l:]
+	}
+	return len(wkb) - len(r.data), nil
+}
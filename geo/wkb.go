@@ -0,0 +1,252 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// enumerates the WKB geometry types
+const (
+	wkbPoint uint32 = iota + 1
+	wkbLineString
+	wkbPolygon
+	wkbMultiPoint
+	wkbMultiLineString
+	wkbMultiPolygon
+	wkbGeometryCollection
+)
+
+// the flags of the geometry type in EWKB
+const (
+	ewkbZ    uint32 = 0x80000000
+	ewkbM    uint32 = 0x40000000
+	ewkbSRID uint32 = 0x20000000
+)
+
+// wkbWriter writes WKB in little endian
+type wkbWriter struct {
+	buf []byte
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+func (w *wkbWriter) header(tp uint32) {
+	w.buf = append(w.buf, 1)
+	w.uint32(tp)
+}
+
+func (w *wkbWriter) coord(c Coord) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(c.X))
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(c.Y))
+}
+
+func (w *wkbWriter) coords(coords []Coord) {
+	w.uint32(uint32(len(coords)))
+	for _, c := range coords {
+		w.coord(c)
+	}
+}
+
+// wkbReader reads the body of a WKB
+type wkbReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func byteOrder(b byte) (binary.ByteOrder, error) {
+	switch b {
+	case 0:
+		return binary.BigEndian, nil
+	case 1:
+		return binary.LittleEndian, nil
+	}
+	return nil, fmt.Errorf("%w: unknown byte order %d", ErrInvalidGeometry, b)
+}
+
+// newWKBReader reads the header of the WKB and checks the geometry type
+func newWKBReader(wkb []byte, tp uint32) (*wkbReader, error) {
+	if len(wkb) < 5 {
+		return nil, fmt.Errorf("%w: WKB is too short", ErrInvalidGeometry)
+	}
+	order, err := byteOrder(wkb[0])
+	if err != nil {
+		return nil, err
+	}
+	if actual := order.Uint32(wkb[1:5]); actual != tp {
+		return nil, fmt.Errorf("%w: expected geometry type %d but got %d", ErrUnsupportedGeometry, tp, actual)
+	}
+	return &wkbReader{data: wkb[5:], order: order}, nil
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.data) < 4 {
+		return 0, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+	}
+	v := r.order.Uint32(r.data)
+	r.data = r.data[4:]
+	return v, nil
+}
+
+func (r *wkbReader) coord() (Coord, error) {
+	if len(r.data) < 16 {
+		return Coord{}, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+	}
+	c := Coord{
+		X: math.Float64frombits(r.order.Uint64(r.data)),
+		Y: math.Float64frombits(r.order.Uint64(r.data[8:])),
+	}
+	r.data = r.data[16:]
+	return c, nil
+}
+
+func (r *wkbReader) coords() ([]Coord, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(n)*16 > uint64(len(r.data)) {
+		return nil, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+	}
+	coords := make([]Coord, 0, n)
+	for i := uint32(0); i < n; i++ {
+		c, err := r.coord()
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, c)
+	}
+	return coords, nil
+}
+
+func (r *wkbReader) walkCoords(fn func(Coord)) error {
+	coords, err := r.coords()
+	if err != nil {
+		return err
+	}
+	if fn != nil {
+		for _, c := range coords {
+			fn(c)
+		}
+	}
+	return nil
+}
+
+// toEWKB adds the SRID to the header of the WKB
+func toEWKB(srid uint32, wkb []byte) ([]byte, error) {
+	if len(wkb) < 5 {
+		return nil, fmt.Errorf("%w: WKB is too short", ErrInvalidGeometry)
+	}
+	if srid == 0 {
+		return wkb, nil
+	}
+	order, err := byteOrder(wkb[0])
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, 9, len(wkb)+4)
+	res[0] = wkb[0]
+	order.PutUint32(res[1:], order.Uint32(wkb[1:5])|ewkbSRID)
+	order.PutUint32(res[5:], srid)
+	return append(res, wkb[5:]...), nil
+}
+
+// fromEWKB validates the WKB or EWKB and returns the SRID and the WKB without SRID
+func fromEWKB(data []byte) (uint32, []byte, error) {
+	if len(data) < 5 {
+		return 0, nil, fmt.Errorf("%w: WKB is too short", ErrInvalidGeometry)
+	}
+	order, err := byteOrder(data[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	tp := order.Uint32(data[1:5])
+	wkb := data
+	var srid uint32
+	if tp&ewkbSRID != 0 {
+		if len(data) < 9 {
+			return 0, nil, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+		}
+		srid = order.Uint32(data[5:9])
+		wkb = make([]byte, 5, len(data)-4)
+		wkb[0] = data[0]
+		order.PutUint32(wkb[1:], tp&^ewkbSRID)
+		wkb = append(wkb, data[9:]...)
+	}
+	n, err := wkbLen(wkb)
+	if err != nil {
+		return 0, nil, err
+	}
+	if n != len(wkb) {
+		return 0, nil, fmt.Errorf("%w: %d extra bytes after WKB", ErrInvalidGeometry, len(wkb)-n)
+	}
+	return srid, wkb, nil
+}
+
+// wkbLen returns the length of the 2D geometry at the beginning of data
+func wkbLen(data []byte) (int, error) {
+	return walkWKB(data, nil)
+}
+
+// walkWKB returns the length of the 2D geometry at the beginning of data, fn is called with
+// every coordinate of the geometry if it's not nil
+func walkWKB(data []byte, fn func(Coord)) (int, error) {
+	if len(data) < 5 {
+		return 0, fmt.Errorf("%w: unexpected end of WKB", ErrInvalidGeometry)
+	}
+	order, err := byteOrder(data[0])
+	if err != nil {
+		return 0, err
+	}
+	tp := order.Uint32(data[1:5])
+	if tp&(ewkbZ|ewkbM) != 0 || tp > 1000 {
+		return 0, fmt.Errorf("%w: geometry with Z or M is not supported", ErrUnsupportedGeometry)
+	}
+
+	r := &wkbReader{data: data[5:], order: order}
+	switch tp {
+	case wkbPoint:
+		c, err := r.coord()
+		if err != nil {
+			return 0, err
+		}
+		if fn != nil {
+			fn(c)
+		}
+	case wkbLineString:
+		if err := r.walkCoords(fn); err != nil {
+			return 0, err
+		}
+	case wkbPolygon:
+		n, err := r.uint32()
+		if err != nil {
+			return 0, err
+		}
+		for i := uint32(0); i < n; i++ {
+			if err := r.walkCoords(fn); err != nil {
+				return 0, err
+			}
+		}
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := r.uint32()
+		if err != nil {
+			return 0, err
+		}
+		for i := uint32(0); i < n; i++ {
+			l, err := walkWKB(r.data, fn)
+			if err != nil {
+				return 0, err
+			}
+			r.data = r.data[l:]
+		}
+	default:
+		return 0, fmt.Errorf("%w: unknown geometry type %d", ErrInvalidGeometry, tp)
+	}
+	return len(data) - len(r.data), nil
+}
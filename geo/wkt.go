@@ -0,0 +1,137 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func formatCoord(c Coord) string {
+	return strconv.FormatFloat(c.X, 'f', -1, 64) + " " + strconv.FormatFloat(c.Y, 'f', -1, 64)
+}
+
+func formatCoords(coords []Coord) string {
+	parts := make([]string, 0, len(coords))
+	for _, c := range coords {
+		parts = append(parts, formatCoord(c))
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+// ParseWKT parses a WKT or an EWKT with the SRID prefix like SRID=4326;POINT(1 2),
+// it returns *Point, *LineString or *Polygon.
+func ParseWKT(s string) (Geometry, error) {
+	s = strings.TrimSpace(s)
+	var srid uint32
+	if strings.HasPrefix(strings.ToUpper(s), "SRID=") {
+		idx := strings.Index(s, ";")
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGeometry, s)
+		}
+		v, err := strconv.ParseUint(s[5:idx], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		srid = uint32(v)
+		s = strings.TrimSpace(s[idx+1:])
+	}
+
+	var name, body string
+	if idx := strings.Index(s, "("); idx > 0 {
+		if !strings.HasSuffix(s, ")") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGeometry, s)
+		}
+		name = strings.ToUpper(strings.TrimSpace(s[:idx]))
+		body = s[idx+1 : len(s)-1]
+	} else {
+		fields := strings.Fields(strings.ToUpper(s))
+		if len(fields) != 2 || fields[1] != "EMPTY" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGeometry, s)
+		}
+		name = fields[0]
+	}
+
+	switch name {
+	case "POINT":
+		if body == "" {
+			return nil, fmt.Errorf("%w: empty point is not supported", ErrUnsupportedGeometry)
+		}
+		c, err := parseCoord(body)
+		if err != nil {
+			return nil, err
+		}
+		return &Point{X: c.X, Y: c.Y, SRID: srid}, nil
+	case "LINESTRING":
+		coords, err := parseCoords(body)
+		if err != nil {
+			return nil, err
+		}
+		return &LineString{Coords: coords, SRID: srid}, nil
+	case "POLYGON":
+		rings, err := parseRings(body)
+		if err != nil {
+			return nil, err
+		}
+		return &Polygon{Rings: rings, SRID: srid}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedGeometry, name)
+}
+
+func parseCoord(s string) (Coord, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Coord{}, fmt.Errorf("%w: invalid coordinate %q", ErrInvalidGeometry, s)
+	}
+	x, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Coord{}, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+	}
+	y, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return Coord{}, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+	}
+	return Coord{X: x, Y: y}, nil
+}
+
+func parseCoords(s string) ([]Coord, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	coords := make([]Coord, 0, len(parts))
+	for _, part := range parts {
+		c, err := parseCoord(part)
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, c)
+	}
+	return coords, nil
+}
+
+func parseRings(s string) ([][]Coord, error) {
+	var rings [][]Coord
+	for s = strings.TrimSpace(s); s != ""; {
+		if s[0] != '(' {
+			return nil, fmt.Errorf("%w: invalid ring %q", ErrInvalidGeometry, s)
+		}
+		end := strings.Index(s, ")")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: invalid ring %q", ErrInvalidGeometry, s)
+		}
+		ring, err := parseCoords(s[1:end])
+		if err != nil {
+			return nil, err
+		}
+		rings = append(rings, ring)
+		s = strings.TrimSpace(s[end+1:])
+		if strings.HasPrefix(s, ",") {
+			s = strings.TrimSpace(s[1:])
+		}
+	}
+	return rings, nil
+}
@@ -7,24 +7,37 @@ package xorm
 import (
 	"context"
 	"database/sql"
+	"io"
+	"net/netip"
 	"reflect"
 	"time"
 
 	"github.com/imkos/xorm/caches"
 	"github.com/imkos/xorm/contexts"
+	"github.com/imkos/xorm/convert"
 	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/log"
 	"github.com/imkos/xorm/names"
+	"github.com/imkos/xorm/reverse"
 	"github.com/imkos/xorm/schemas"
+	"xorm.io/builder"
 )
 
 // Interface defines the interface which Engine, EngineGroup and Session will implementate.
 type Interface interface {
 	AllCols() *Session
+	Aggregate(results interface{}, aggs []Agg) error
 	Alias(alias string) *Session
+	Apply(scopes ...func(*Session) *Session) *Session
 	Asc(colNames ...string) *Session
+	Avg(bean interface{}, colName string) (float64, error)
 	BufferSize(size int) *Session
+	BulkInsert(beans interface{}, opts BulkOptions) (int64, error)
+	Chunk(bean interface{}, size int, fn ChunkFunc) error
+	ChunkInTx(bean interface{}, size int, fn ChunkTxFunc) error
 	Cols(columns ...string) *Session
+	Contains(column, s string) *Session
 	Count(...interface{}) (int64, error)
 	CreateIndexes(bean interface{}) error
 	CreateUniques(bean interface{}) error
@@ -34,62 +47,100 @@ type Interface interface {
 	Truncate(...interface{}) (int64, error)
 	Distinct(columns ...string) *Session
 	DropIndexes(bean interface{}) error
+	EndsWith(column, s string) *Session
 	Exec(sqlOrArgs ...interface{}) (sql.Result, error)
 	Exist(bean ...interface{}) (bool, error)
 	Find(interface{}, ...interface{}) error
 	FindAndCount(interface{}, ...interface{}) (int64, error)
 	Get(...interface{}) (bool, error)
-	GroupBy(keys string) *Session
+	GetMulti(ids interface{}, resultsMap interface{}) error
+	GroupBy(keys ...interface{}) *Session
+	Hint(hints ...string) *Session
 	ID(interface{}) *Session
+	ILike(column, pattern string) *Session
 	In(string, ...interface{}) *Session
 	Incr(column string, arg ...interface{}) *Session
 	Insert(...interface{}) (int64, error)
 	InsertOne(interface{}) (int64, error)
 	IsTableEmpty(bean interface{}) (bool, error)
+	IsColumnExist(beanOrTableName interface{}, colName string) (bool, error)
 	IsTableExist(beanOrTableName interface{}) (bool, error)
 	Iterate(interface{}, IterFunc) error
 	Limit(int, ...int) *Session
+	Max(bean interface{}, colName string, res interface{}) error
+	Min(bean interface{}, colName string, res interface{}) error
 	MustCols(columns ...string) *Session
 	NoAutoCondition(...bool) *Session
 	NotIn(string, ...interface{}) *Session
+	OwnedBy(bean interface{}, name ...string) *Session
 	Nullable(...string) *Session
+	JSONSet(column, path string, value interface{}) *Session
 	Join(joinOperator string, tablename interface{}, condition interface{}, args ...interface{}) *Session
+	JoinLateral(joinOperator string, subQuery interface{}, alias string, condition interface{}, args ...interface{}) *Session
 	Omit(columns ...string) *Session
+	One(bean interface{}, strict ...bool) error
 	OrderBy(order interface{}, args ...interface{}) *Session
+	OrderBySafe(userInput string, allowed map[string]string) *Session
+	FilterBy(params map[string][]string, spec FilterSpec) *Session
 	Ping() error
+	Pluck(column string, slicePtr interface{}) error
+	PluckDistinct(column string, slicePtr interface{}) error
 	Query(sqlOrArgs ...interface{}) (resultsSlice []map[string][]byte, err error)
 	QueryInterface(sqlOrArgs ...interface{}) ([]map[string]interface{}, error)
 	QueryString(sqlOrArgs ...interface{}) ([]map[string]string, error)
 	Rows(bean interface{}) (*Rows, error)
+	Schema(schema string) *Session
 	SetExpr(string, interface{}) *Session
+	Scope(names ...string) *Session
 	Select(string) *Session
+	StartsWith(column, s string) *Session
 	SQL(interface{}, ...interface{}) *Session
 	Sum(bean interface{}, colName string) (float64, error)
+	SumDecimal(bean interface{}, colName string) (convert.Decimal, error)
 	SumInt(bean interface{}, colName string) (int64, error)
 	Sums(bean interface{}, colNames ...string) ([]float64, error)
+	SumsDecimal(bean interface{}, colNames ...string) ([]convert.Decimal, error)
 	SumsInt(bean interface{}, colNames ...string) ([]int64, error)
 	Table(tableNameOrBean interface{}) *Session
 	Unscoped() *Session
 	Update(bean interface{}, condiBeans ...interface{}) (int64, error)
 	UseBool(...string) *Session
 	Where(interface{}, ...interface{}) *Session
+	WhereBitsAll(column string, mask convert.BitSet) *Session
+	WhereBitsAny(column string, mask convert.BitSet) *Session
+	WhereContainsIP(column string, addr netip.Addr) *Session
+	WhereDWithin(column string, g geo.Geometry, distance float64) *Session
+	WhereExists(subQuery *builder.Builder) *Session
+	WhereGroup(fn func(*Session)) *Session
+	WhereNotExists(subQuery *builder.Builder) *Session
+	WhereNotNull(column string) *Session
+	WhereNull(column string) *Session
+	WhereNullSafeEq(column string, value interface{}) *Session
+	WhereWithin(column string, g geo.Geometry) *Session
 }
 
 // EngineInterface defines the interface which Engine, EngineGroup will implementate.
 type EngineInterface interface {
 	Interface
 
+	AddDefaultScope(bean interface{}, cond builder.Cond)
+	SetDeletedCond(bean interface{}, cond func(col *schemas.Column) builder.Cond)
+	ApplySchemaSnapshot(snapshot *schemas.Snapshot, opts SyncOptions) (*SyncResult, error)
 	Before(func(interface{})) *Session
 	Charset(charset string) *Session
 	ClearCache(...interface{}) error
 	Context(context.Context) *Session
 	CreateTables(...interface{}) error
-	DBMetas() ([]*schemas.Table, error)
+	DBMetas(schema ...string) ([]*schemas.Table, error)
 	DBVersion() (*schemas.Version, error)
+	DebugReport(w io.Writer) error
+	DefineScope(name string, scope func(*Session) *Session)
 	Dialect() dialects.Dialect
 	DriverName() string
 	DropTables(...interface{}) error
 	DumpAllToFile(fp string, tp ...schemas.DBType) error
+	ExportTables(tables []*schemas.Table, w io.Writer, opts ExportOptions) error
+	GenerateModels(w io.Writer, opts reverse.Options) error
 	GetCacher(string) caches.Cacher
 	GetColumnMapper() names.Mapper
 	GetDefaultCacher() caches.Cacher
@@ -97,27 +148,54 @@ type EngineInterface interface {
 	GetTZDatabase() *time.Location
 	GetTZLocation() *time.Location
 	ImportFile(fp string) ([]sql.Result, error)
+	ImportStream(r io.Reader, opts ImportOptions) ([]sql.Result, error)
+	InvalidateSchemaCache(beanOrTableNames ...interface{})
 	MapCacher(interface{}, caches.Cacher) error
 	NewSession() *Session
+	NewSessionContext(ctx context.Context) *Session
 	NoAutoTime() *Session
+	WithTime(t time.Time) *Session
+	NoReflectCache() *Session
+	Polymorphic(bean interface{}) (schemas.Polymorphic, error)
+	ParallelFind(ctx context.Context, bean interface{}, spec RangeSpec, fn ParallelFindFunc) error
 	Prepare() *Session
+	PrepareQuery(fn func(*Session) *Session) *Query
 	Quote(string) string
+	RegisterModels(beans ...interface{}) error
+	RegisterModelsWithOptions(opts RegisterOptions, beans ...interface{}) error
+	SchemaSnapshot(beans ...interface{}) (*schemas.Snapshot, error)
 	SetCacher(string, caches.Cacher)
+	SetCacheInvalidationBroker(broker caches.InvalidationBroker) error
+	SetClock(clock func() time.Time)
 	SetConnMaxLifetime(time.Duration)
 	SetColumnMapper(names.Mapper)
 	SetTagIdentifier(string)
+	SetFallbackTagIdentifier(tagIdentifier string)
+	SetFieldSkipper(skipper func(field reflect.StructField) bool)
+	SetValidator(validate ValidateFunc)
 	SetDefaultCacher(caches.Cacher)
 	SetLogger(logger interface{})
 	SetLogLevel(log.LogLevel)
 	SetMapper(names.Mapper)
 	SetMaxOpenConns(int)
+	SetMaxSessionLifetime(d time.Duration)
 	SetMaxIdleConns(int)
+	SetBatchDBMetas(enabled bool)
+	SetMultiInsertTx(enabled bool)
+	SetProcessorSavepoint(enabled bool)
 	SetQuotePolicy(dialects.QuotePolicy)
 	SetSchema(string)
+	SetSchemaCacheTTL(ttl time.Duration)
+	SetSQLCommenter(commenter SQLCommenter)
 	SetTableMapper(names.Mapper)
+	SetIdentifierAudit(mode IdentifierAuditMode)
+	SetStrictMode(mode StrictMode)
+	SetTimeoutFor(pattern string, timeout time.Duration) error
 	SetTZDatabase(tz *time.Location)
 	SetTZLocation(tz *time.Location)
+	SetValueNormalization(normalization ValueNormalization)
 	AddHook(hook contexts.Hook)
+	AddSQLInterceptor(interceptor SQLInterceptor)
 	ShowSQL(show ...bool)
 	Sync(...interface{}) error
 	Sync2(...interface{}) error
@@ -127,6 +205,7 @@ type EngineInterface interface {
 	TableName(interface{}, ...bool) string
 	UnMapType(reflect.Type)
 	EnableSessionID(bool)
+	WithContext(ctx context.Context) EngineInterface
 }
 
 var (
@@ -5,7 +5,13 @@
 package statements
 
 import (
+	"fmt"
+	"net/netip"
+	"strings"
+
 	"xorm.io/builder"
+	"github.com/imkos/xorm/convert"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/schemas"
 )
 
@@ -35,8 +41,8 @@ func (statement *Statement) Where(query interface{}, args ...interface{}) *State
 func (statement *Statement) And(query interface{}, args ...interface{}) *Statement {
 	switch qr := query.(type) {
 	case string:
-		cond := builder.Expr(qr, args...)
-		statement.cond = statement.cond.And(cond)
+		qr, args := statement.normalizeSQL(qr, args)
+		statement.cond = statement.cond.And(builder.Expr(qr, args...))
 	case map[string]interface{}:
 		cond := make(builder.Eq)
 		for k, v := range qr {
@@ -61,8 +67,8 @@ func (statement *Statement) And(query interface{}, args ...interface{}) *Stateme
 func (statement *Statement) Or(query interface{}, args ...interface{}) *Statement {
 	switch qr := query.(type) {
 	case string:
-		cond := builder.Expr(qr, args...)
-		statement.cond = statement.cond.Or(cond)
+		qr, args := statement.normalizeSQL(qr, args)
+		statement.cond = statement.cond.Or(builder.Expr(qr, args...))
 	case map[string]interface{}:
 		cond := make(builder.Eq)
 		for k, v := range qr {
@@ -82,6 +88,29 @@ func (statement *Statement) Or(query interface{}, args ...interface{}) *Statemen
 	return statement
 }
 
+// group calls fn with an empty condition and returns the conditions fn added
+func (statement *Statement) group(fn func()) builder.Cond {
+	cond := statement.cond
+	statement.cond = builder.NewCond()
+	defer func() {
+		statement.cond = cond
+	}()
+	fn()
+	return statement.cond
+}
+
+// AndGroup adds the conditions which fn adds as one parenthesized group with AND
+func (statement *Statement) AndGroup(fn func()) *Statement {
+	statement.cond = statement.cond.And(statement.group(fn))
+	return statement
+}
+
+// OrGroup adds the conditions which fn adds as one parenthesized group with OR
+func (statement *Statement) OrGroup(fn func()) *Statement {
+	statement.cond = statement.cond.Or(statement.group(fn))
+	return statement
+}
+
 // In generate "Where column IN (?) " statement
 func (statement *Statement) In(column string, args ...interface{}) *Statement {
 	in := builder.In(statement.quote(column), args...)
@@ -96,6 +125,254 @@ func (statement *Statement) NotIn(column string, args ...interface{}) *Statement
 	return statement
 }
 
+// WhereNull generate "Where column IS NULL" statement
+func (statement *Statement) WhereNull(column string) *Statement {
+	statement.cond = statement.cond.And(builder.IsNull{statement.quote(column)})
+	return statement
+}
+
+// WhereNotNull generate "Where column IS NOT NULL" statement
+func (statement *Statement) WhereNotNull(column string) *Statement {
+	statement.cond = statement.cond.And(builder.NotNull{statement.quote(column)})
+	return statement
+}
+
+// NullSafeEqCond returns the condition which compares the column and the value as equal
+// even if both of them are NULL, i.e. IS NOT DISTINCT FROM, with the syntax of the dialect
+func (statement *Statement) NullSafeEqCond(column string, value interface{}) builder.Cond {
+	column = statement.quote(column)
+	if value == nil {
+		return builder.IsNull{column}
+	}
+	switch statement.dialect.URI().DBType {
+	case schemas.MYSQL:
+		return builder.Expr(column+" <=> ?", value)
+	case schemas.POSTGRES:
+		return builder.Expr(column+" IS NOT DISTINCT FROM ?", value)
+	case schemas.SQLITE:
+		return builder.Expr(column+" IS ?", value)
+	}
+	return builder.Expr("("+column+" = ? OR ("+column+" IS NULL AND ? IS NULL))", value, value)
+}
+
+// WhereNullSafeEq generate "Where column IS NOT DISTINCT FROM ?" statement
+func (statement *Statement) WhereNullSafeEq(column string, value interface{}) *Statement {
+	statement.cond = statement.cond.And(statement.NullSafeEqCond(column, value))
+	return statement
+}
+
+// likeEscaper escapes the wildcards of LIKE with the escape character !, the [ is a
+// wildcard of SQL Server, and it's harmless to escape it on the other databases
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_", "[", "![")
+
+// ILike generate "Where column ILIKE ?" statement, it's "LOWER(column) LIKE LOWER(?)" on
+// the databases which don't support ILIKE
+func (statement *Statement) ILike(column, pattern string) *Statement {
+	var cond builder.Cond
+	if statement.dialect.URI().DBType == schemas.POSTGRES {
+		cond = builder.Expr(statement.quote(column)+" ILIKE ?", pattern)
+	} else {
+		cond = builder.Expr("LOWER("+statement.quote(column)+") LIKE LOWER(?)", pattern)
+	}
+	statement.cond = statement.cond.And(cond)
+	return statement
+}
+
+// likeEscaped generate "Where column LIKE ? ESCAPE '!'" statement, the wildcards in s are escaped
+func (statement *Statement) likeEscaped(column, prefix, s, suffix string) *Statement {
+	like := builder.Expr(statement.quote(column)+" LIKE ? ESCAPE '!'", prefix+likeEscaper.Replace(s)+suffix)
+	statement.cond = statement.cond.And(like)
+	return statement
+}
+
+// StartsWith generate "Where column LIKE 's%'" statement
+func (statement *Statement) StartsWith(column, s string) *Statement {
+	return statement.likeEscaped(column, "", s, "%")
+}
+
+// EndsWith generate "Where column LIKE '%s'" statement
+func (statement *Statement) EndsWith(column, s string) *Statement {
+	return statement.likeEscaped(column, "%", s, "")
+}
+
+// Contains generate "Where column LIKE '%s%'" statement
+func (statement *Statement) Contains(column, s string) *Statement {
+	return statement.likeEscaped(column, "%", s, "%")
+}
+
+// WhereContainsIP generate "Where column >>= addr" statement on PostgreSQL which means the inet
+// or cidr column contains or equals to the address. On the other databases, the column is compared
+// with the address and all the prefixes containing it, so the prefixes should be stored masked.
+func (statement *Statement) WhereContainsIP(column string, addr netip.Addr) *Statement {
+	if !addr.IsValid() {
+		statement.LastError = fmt.Errorf("WhereContainsIP on column %s needs a valid address", column)
+		return statement
+	}
+	if statement.dialect.URI().DBType == schemas.POSTGRES {
+		statement.cond = statement.cond.And(builder.Expr(statement.quote(column)+" >>= ?", addr.String()))
+		return statement
+	}
+
+	addr = addr.WithZone("")
+	values := make([]interface{}, 0, addr.BitLen()+2)
+	values = append(values, addr.String())
+	for bits := addr.BitLen(); bits >= 0; bits-- {
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			statement.LastError = err
+			return statement
+		}
+		values = append(values, prefix.String())
+	}
+	statement.cond = statement.cond.And(builder.In(statement.quote(column), values...))
+	return statement
+}
+
+// geometryExpr returns the expression and the arguments to construct the geometry in SQL
+func (statement *Statement) geometryExpr(g geo.Geometry) (string, []interface{}, bool) {
+	srid, wkb, err := g.MarshalWKB()
+	if err != nil {
+		statement.LastError = err
+		return "", nil, false
+	}
+	return "ST_GeomFromWKB(?, ?)", []interface{}{wkb, srid}, true
+}
+
+// WhereWithin generate "Where ST_Within(column, geometry)" statement
+func (statement *Statement) WhereWithin(column string, g geo.Geometry) *Statement {
+	expr, args, ok := statement.geometryExpr(g)
+	if !ok {
+		return statement
+	}
+	statement.cond = statement.cond.And(builder.Expr("ST_Within("+statement.quote(column)+", "+expr+")", args...))
+	return statement
+}
+
+// WhereDWithin generate "Where ST_DWithin(column, geometry, distance)" statement on PostgreSQL,
+// and "Where ST_Distance(column, geometry) <= distance" on the other databases
+func (statement *Statement) WhereDWithin(column string, g geo.Geometry, distance float64) *Statement {
+	expr, args, ok := statement.geometryExpr(g)
+	if !ok {
+		return statement
+	}
+	args = append(args, distance)
+	var cond builder.Cond
+	if statement.dialect.URI().DBType == schemas.POSTGRES {
+		cond = builder.Expr("ST_DWithin("+statement.quote(column)+", "+expr+", ?)", args...)
+	} else {
+		cond = builder.Expr("ST_Distance("+statement.quote(column)+", "+expr+") <= ?", args...)
+	}
+	statement.cond = statement.cond.And(cond)
+	return statement
+}
+
+// whereBits generate the condition of the bitwise AND of the column and the mask, the result is
+// compared with the mask if all is true, otherwise it's compared with zero
+func (statement *Statement) whereBits(column string, mask convert.BitSet, all bool) *Statement {
+	if mask.Len() == 0 {
+		statement.LastError = fmt.Errorf("bitwise condition on column %s needs a non-empty mask", column)
+		return statement
+	}
+
+	dbType := statement.dialect.URI().DBType
+	if dbType == schemas.POSTGRES {
+		expr := "(" + statement.quote(column) + " & CAST(? AS VARBIT))"
+		m := mask.String()
+		if all {
+			statement.cond = statement.cond.And(builder.Expr(expr+" = CAST(? AS VARBIT)", m, m))
+		} else {
+			statement.cond = statement.cond.And(builder.Expr(expr+" <> CAST(? AS VARBIT)", m, strings.Repeat("0", mask.Len())))
+		}
+		return statement
+	}
+
+	if mask.Len() > 64 {
+		statement.LastError = fmt.Errorf("bitwise condition on column %s could not have more than 64 bits on %s", column, dbType)
+		return statement
+	}
+	var v interface{} = int64(mask.Uint64())
+	if dbType == schemas.MYSQL {
+		v = mask.Uint64()
+	}
+	expr := "(" + statement.quote(column) + " & ?)"
+	if dbType == schemas.ORACLE || dbType == schemas.DAMENG {
+		expr = "BITAND(" + statement.quote(column) + ", ?)"
+	}
+	if all {
+		statement.cond = statement.cond.And(builder.Expr(expr+" = ?", v, v))
+	} else {
+		statement.cond = statement.cond.And(builder.Expr(expr+" <> 0", v))
+	}
+	return statement
+}
+
+// WhereBitsAll generate "Where (column & mask) = mask" statement, all the bits of the mask
+// should be set in the column. On PostgreSQL the mask should have the same length as the column.
+func (statement *Statement) WhereBitsAll(column string, mask convert.BitSet) *Statement {
+	return statement.whereBits(column, mask, true)
+}
+
+// WhereBitsAny generate "Where (column & mask) <> 0" statement, any of the bits of the mask
+// should be set in the column. On PostgreSQL the mask should have the same length as the column.
+func (statement *Statement) WhereBitsAny(column string, mask convert.BitSet) *Statement {
+	return statement.whereBits(column, mask, false)
+}
+
+// existsCond represents "EXISTS (subquery)" or "NOT EXISTS (subquery)" condition
+type existsCond struct {
+	not      bool
+	subQuery *builder.Builder
+}
+
+var _ builder.Cond = existsCond{}
+
+func (cond existsCond) WriteTo(w builder.Writer) error {
+	op := "EXISTS ("
+	if cond.not {
+		op = "NOT EXISTS ("
+	}
+	if _, err := fmt.Fprint(w, op); err != nil {
+		return err
+	}
+	if err := cond.subQuery.WriteTo(w); err != nil {
+		return err
+	}
+	_, err := fmt.Fprint(w, ")")
+	return err
+}
+
+func (cond existsCond) And(conds ...builder.Cond) builder.Cond {
+	return builder.And(cond, builder.And(conds...))
+}
+
+func (cond existsCond) Or(conds ...builder.Cond) builder.Cond {
+	return builder.Or(cond, builder.Or(conds...))
+}
+
+func (cond existsCond) IsValid() bool {
+	return cond.subQuery != nil
+}
+
+// WhereExists generate "Where EXISTS (subquery)" statement
+func (statement *Statement) WhereExists(subQuery *builder.Builder) *Statement {
+	if subQuery == nil {
+		statement.LastError = ErrConditionType
+		return statement
+	}
+	statement.cond = statement.cond.And(existsCond{subQuery: subQuery})
+	return statement
+}
+
+// WhereNotExists generate "Where NOT EXISTS (subquery)" statement
+func (statement *Statement) WhereNotExists(subQuery *builder.Builder) *Statement {
+	if subQuery == nil {
+		statement.LastError = ErrConditionType
+		return statement
+	}
+	statement.cond = statement.cond.And(existsCond{not: true, subQuery: subQuery})
+	return statement
+}
+
 // SetNoAutoCondition if you do not want convert bean's field as query condition, then use this function
 func (statement *Statement) SetNoAutoCondition(no ...bool) *Statement {
 	statement.NoAutoCondition = true
@@ -105,7 +382,75 @@ func (statement *Statement) SetNoAutoCondition(no ...bool) *Statement {
 	return statement
 }
 
+// SetDefaultScopes sets the function returns the default conditions of a table
+func (statement *Statement) SetDefaultScopes(defaultScopes func(tableName string) builder.Cond) {
+	statement.defaultScopes = defaultScopes
+}
+
+// SetDeletedConds sets the function returns the custom soft deleted condition of a table
+func (statement *Statement) SetDeletedConds(deletedConds func(tableName string) func(col *schemas.Column) builder.Cond) {
+	statement.deletedConds = deletedConds
+}
+
+// DiscriminatorCond returns the condition of the discriminator column of the statement's table,
+// it's empty if the table has no discriminator
+func (statement *Statement) DiscriminatorCond() builder.Cond {
+	if statement.RefTable == nil {
+		return builder.NewCond()
+	}
+	col := statement.RefTable.DiscriminatorColumn()
+	if col == nil {
+		return builder.NewCond()
+	}
+	arg, err := col.DiscriminatorArg()
+	if err != nil {
+		return builder.NewCond()
+	}
+	return builder.Eq{statement.colName(col, statement.TableName()): arg}
+}
+
+// DefaultScopeCond returns the default conditions of the statement's table, they are skipped
+// if the statement is unscoped like the "deleted" conditions, but the discriminator condition
+// is always kept
+func (statement *Statement) DefaultScopeCond() builder.Cond {
+	if statement.defaultScopeApplied {
+		return builder.NewCond()
+	}
+	discriminatorCond := statement.DiscriminatorCond()
+	if statement.defaultScopes == nil || statement.unscoped {
+		return discriminatorCond
+	}
+	tableName := statement.TableName()
+	if tableName == "" {
+		return discriminatorCond
+	}
+	if cond := statement.defaultScopes(tableName); cond != nil {
+		return discriminatorCond.And(cond)
+	}
+	return discriminatorCond
+}
+
+// ApplyDefaultScope adds the default conditions of the statement's table once
+func (statement *Statement) ApplyDefaultScope() {
+	if cond := statement.DefaultScopeCond(); cond.IsValid() {
+		statement.cond = statement.cond.And(cond)
+	}
+	statement.defaultScopeApplied = true
+}
+
 // Conds returns condtions
 func (statement *Statement) Conds() builder.Cond {
 	return statement.cond
 }
+
+// SetConds replaces the conditions of the statement
+func (statement *Statement) SetConds(cond builder.Cond) {
+	statement.cond = cond
+}
+
+// PKAfterCond returns the condition which filters the records whose primary key is
+// greater than last, the table should have exactly one primary key
+func (statement *Statement) PKAfterCond(last interface{}) builder.Cond {
+	col := statement.RefTable.PKColumns()[0]
+	return builder.Gt{statement.colName(col, statement.TableName()): last}
+}
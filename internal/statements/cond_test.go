@@ -0,0 +1,134 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/imkos/xorm/convert"
+	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/geo"
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestNullSafeEqCond(t *testing.T) {
+	kases := []struct {
+		driverName string
+		connStr    string
+		expected   string
+		args       int
+	}{
+		{"sqlite3", "./test.db", "`name` IS ?", 1},
+		{"mysql", "root:@tcp(localhost:3306)/test", "`name` <=> ?", 1},
+		{"postgres", "postgres://postgres:@localhost:5432/test?sslmode=disable", `"name" IS NOT DISTINCT FROM ?`, 1},
+		{"mssql", "server=localhost;user id=sa;password=;database=test", "([name] = ? OR ([name] IS NULL AND ? IS NULL))", 2},
+	}
+	for _, kase := range kases {
+		dialect, err := dialects.OpenDialect(kase.driverName, kase.connStr)
+		assert.NoError(t, err)
+		statement := NewStatement(dialect, tagParser, time.Local)
+
+		sql, args, err := builder.ToSQL(statement.NullSafeEqCond("name", "a"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.expected, sql, kase.driverName)
+		assert.Len(t, args, kase.args)
+
+		sql, args, err = builder.ToSQL(statement.NullSafeEqCond("name", nil))
+		assert.NoError(t, err)
+		assert.EqualValues(t, dialect.Quoter().Quote("name")+" IS NULL", sql)
+		assert.Len(t, args, 0)
+	}
+}
+
+func TestSpatialConds(t *testing.T) {
+	kases := []struct {
+		driverName string
+		connStr    string
+		expected   string
+	}{
+		{"mysql", "root:@tcp(localhost:3306)/test", "ST_Distance(`loc`, ST_GeomFromWKB(?, ?)) <= ?"},
+		{"postgres", "postgres://postgres:@localhost:5432/test?sslmode=disable", `ST_DWithin("loc", ST_GeomFromWKB(?, ?), ?)`},
+	}
+	for _, kase := range kases {
+		dialect, err := dialects.OpenDialect(kase.driverName, kase.connStr)
+		assert.NoError(t, err)
+
+		statement := NewStatement(dialect, tagParser, time.Local)
+		statement.WhereDWithin("loc", &geo.Point{X: 1, Y: 2, SRID: 4326}, 10)
+		sql, args, err := builder.ToSQL(statement.Conds())
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.expected, sql, kase.driverName)
+		assert.Len(t, args, 3)
+		assert.EqualValues(t, 4326, args[1])
+
+		statement = NewStatement(dialect, tagParser, time.Local)
+		statement.WhereWithin("loc", &geo.Polygon{Rings: [][]geo.Coord{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}})
+		sql, _, err = builder.ToSQL(statement.Conds())
+		assert.NoError(t, err)
+		assert.EqualValues(t, "ST_Within("+dialect.Quoter().Quote("loc")+", ST_GeomFromWKB(?, ?))", sql)
+	}
+}
+
+func TestWhereContainsIP(t *testing.T) {
+	dialect, err := dialects.OpenDialect("postgres", "postgres://postgres:@localhost:5432/test?sslmode=disable")
+	assert.NoError(t, err)
+	statement := NewStatement(dialect, tagParser, time.Local)
+	statement.WhereContainsIP("network", netip.MustParseAddr("10.1.2.3"))
+	sql, args, err := builder.ToSQL(statement.Conds())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `"network" >>= ?`, sql)
+	assert.EqualValues(t, []interface{}{"10.1.2.3"}, args)
+
+	dialect, err = dialects.OpenDialect("mysql", "root:@tcp(localhost:3306)/test")
+	assert.NoError(t, err)
+	statement = NewStatement(dialect, tagParser, time.Local)
+	statement.WhereContainsIP("network", netip.MustParseAddr("10.1.2.3"))
+	_, args, err = builder.ToSQL(statement.Conds())
+	assert.NoError(t, err)
+	assert.Len(t, args, 34)
+	assert.Contains(t, args, "10.1.2.3")
+	assert.Contains(t, args, "10.1.2.0/24")
+	assert.Contains(t, args, "0.0.0.0/0")
+
+	statement = NewStatement(dialect, tagParser, time.Local)
+	statement.WhereContainsIP("network", netip.Addr{})
+	assert.Error(t, statement.LastError)
+}
+
+func TestWhereBits(t *testing.T) {
+	mask := convert.BitSetFromUint64(5, 8)
+
+	dialect, err := dialects.OpenDialect("postgres", "postgres://postgres:@localhost:5432/test?sslmode=disable")
+	assert.NoError(t, err)
+	statement := NewStatement(dialect, tagParser, time.Local)
+	statement.WhereBitsAll("flags", mask)
+	sql, args, err := builder.ToSQL(statement.Conds())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `("flags" & CAST(? AS VARBIT)) = CAST(? AS VARBIT)`, sql)
+	assert.EqualValues(t, []interface{}{"00000101", "00000101"}, args)
+
+	statement = NewStatement(dialect, tagParser, time.Local)
+	statement.WhereBitsAny("flags", mask)
+	sql, args, err = builder.ToSQL(statement.Conds())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `("flags" & CAST(? AS VARBIT)) <> CAST(? AS VARBIT)`, sql)
+	assert.EqualValues(t, []interface{}{"00000101", "00000000"}, args)
+
+	dialect, err = dialects.OpenDialect("mysql", "root:@tcp(localhost:3306)/test")
+	assert.NoError(t, err)
+	statement = NewStatement(dialect, tagParser, time.Local)
+	statement.WhereBitsAll("flags", mask)
+	sql, args, err = builder.ToSQL(statement.Conds())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "(`flags` & ?) = ?", sql)
+	assert.EqualValues(t, []interface{}{uint64(5), uint64(5)}, args)
+
+	statement = NewStatement(dialect, tagParser, time.Local)
+	statement.WhereBitsAny("flags", convert.BitSet{})
+	assert.Error(t, statement.LastError)
+}
@@ -21,7 +21,7 @@ func (statement *Statement) writeDeleteOrder(w *builder.BytesWriter) error {
 
 	if statement.LimitN != nil && *statement.LimitN > 0 {
 		if statement.Start > 0 {
-			return fmt.Errorf("Delete with Limit start is unsupported")
+			return statement.unsupportedLimit("delete", true)
 		}
 		limitNValue := *statement.LimitN
 		if _, err := fmt.Fprintf(w, " LIMIT %d", limitNValue); err != nil {
@@ -35,52 +35,24 @@ func (statement *Statement) writeDeleteOrder(w *builder.BytesWriter) error {
 // ErrNotImplemented not implemented
 var ErrNotImplemented = errors.New("Not implemented")
 
-func (statement *Statement) writeOrderCond(orderCondWriter *builder.BytesWriter, tableName string) error {
-	orderSQLWriter := builder.NewWriter()
-	if err := statement.writeDeleteOrder(orderSQLWriter); err != nil {
-		return err
-	}
-
-	if orderSQLWriter.Len() == 0 {
-		return nil
+func (statement *Statement) writeOrderCond(orderCondWriter *builder.BytesWriter) error {
+	hasLimit := statement.LimitN != nil && *statement.LimitN > 0
+	if hasLimit && !statement.dialect.Features().DeleteLimit {
+		return statement.unsupportedLimit("delete", statement.Start > 0)
 	}
 
 	switch statement.dialect.URI().DBType {
-	case schemas.POSTGRES:
-		if statement.cond.IsValid() {
-			if _, err := fmt.Fprint(orderCondWriter, " AND "); err != nil {
-				return err
-			}
-		} else {
-			if _, err := fmt.Fprint(orderCondWriter, " WHERE "); err != nil {
-				return err
-			}
-		}
-		if _, err := fmt.Fprintf(orderCondWriter, "ctid IN (SELECT ctid FROM %s%s)", tableName, orderSQLWriter.String()); err != nil {
-			return err
-		}
-		orderCondWriter.Append(orderSQLWriter.Args()...)
-		return nil
-	case schemas.SQLITE:
-		if statement.cond.IsValid() {
-			if _, err := fmt.Fprint(orderCondWriter, " AND "); err != nil {
-				return err
-			}
-		} else {
-			if _, err := fmt.Fprint(orderCondWriter, " WHERE "); err != nil {
-				return err
-			}
+	case schemas.POSTGRES, schemas.SQLITE, schemas.MSSQL:
+		// the order makes no difference without a limit
+		if !hasLimit {
+			return nil
 		}
-		if _, err := fmt.Fprintf(orderCondWriter, "rowid IN (SELECT rowid FROM %s%s)", tableName, orderSQLWriter.String()); err != nil {
-			return err
+		if statement.Start > 0 {
+			return statement.unsupportedLimit("delete", true)
 		}
-		orderCondWriter.Append(orderSQLWriter.Args()...)
-		return nil
-		// TODO: how to handle delete limit on mssql?
-	case schemas.MSSQL:
-		return ErrNotImplemented
+		return statement.writeLimitSubquery(orderCondWriter, "delete", statement.cond)
 	default:
-		return utils.WriteBuilder(orderCondWriter, orderSQLWriter)
+		return statement.writeDeleteOrder(orderCondWriter)
 	}
 }
 
@@ -96,7 +68,7 @@ func (statement *Statement) WriteDelete(realSQLWriter, deleteSQLWriter *builder.
 	}
 
 	orderCondWriter := builder.NewWriter()
-	if err := statement.writeOrderCond(orderCondWriter, tableName); err != nil {
+	if err := statement.writeOrderCond(orderCondWriter); err != nil {
 		return err
 	}
 
@@ -111,11 +83,15 @@ func (statement *Statement) WriteDelete(realSQLWriter, deleteSQLWriter *builder.
 		return err
 	}
 
-	val, _, err := nowTime(deletedColumn)
-	if err != nil {
-		return err
+	if deletedColumn.IsDeletedFlag {
+		realSQLWriter.Append(deletedColumn.DeletedFlagValue(true))
+	} else {
+		val, _, err := nowTime(deletedColumn)
+		if err != nil {
+			return err
+		}
+		realSQLWriter.Append(val)
 	}
-	realSQLWriter.Append(val)
 
 	if err := statement.writeWhere(realSQLWriter); err != nil {
 		return err
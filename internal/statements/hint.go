@@ -0,0 +1,59 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imkos/xorm/schemas"
+	"xorm.io/builder"
+)
+
+// Hint adds the optimizer hints of the select statement, i.e. "MAX_EXECUTION_TIME(1000)" of mysql
+// or "RECOMPILE" of mssql
+func (statement *Statement) Hint(hints ...string) *Statement {
+	for _, hint := range hints {
+		if hint = strings.TrimSpace(hint); hint != "" {
+			statement.hints = append(statement.hints, hint)
+		}
+	}
+	return statement
+}
+
+// writeHints writes the optimizer hints comment "/*+ ... */" after SELECT
+func (statement *Statement) writeHints(w *builder.BytesWriter) error {
+	if len(statement.hints) == 0 {
+		return nil
+	}
+
+	switch statement.dialect.URI().DBType {
+	case schemas.MYSQL, schemas.ORACLE, schemas.POSTGRES, schemas.DAMENG:
+		_, err := fmt.Fprint(w, " /*+ ", strings.Join(statement.hints, " "), " */")
+		return err
+	case schemas.MSSQL, schemas.HANA:
+		// written at the end of the statement by writeQueryHints
+		return nil
+	default:
+		return fmt.Errorf("optimizer hints are not supported by %s", statement.dialect.URI().DBType)
+	}
+}
+
+// writeQueryHints writes the query hints which follow the statement, "OPTION (...)" of mssql and
+// "WITH HINT (...)" of hana
+func (statement *Statement) writeQueryHints(w *builder.BytesWriter) error {
+	if len(statement.hints) == 0 {
+		return nil
+	}
+
+	var err error
+	switch statement.dialect.URI().DBType {
+	case schemas.MSSQL:
+		_, err = fmt.Fprint(w, " OPTION (", strings.Join(statement.hints, ", "), ")")
+	case schemas.HANA:
+		_, err = fmt.Fprint(w, " WITH HINT (", strings.Join(statement.hints, ", "), ")")
+	}
+	return err
+}
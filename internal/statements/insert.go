@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/imkos/xorm/dialects"
 	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/schemas"
 	"xorm.io/builder"
@@ -44,7 +45,7 @@ func (statement *Statement) GenInsertSQL(colNames []string, args []interface{})
 	}
 
 	hasInsertColumns := len(colNames) > 0
-	needSeq := len(table.AutoIncrement) > 0 && (statement.dialect.URI().DBType == schemas.ORACLE || statement.dialect.URI().DBType == schemas.DAMENG)
+	needSeq := len(table.AutoIncrement) > 0 && statement.dialect.Features().AutoincrMode == dialects.SequenceAutoincrMode
 	if needSeq {
 		for _, col := range colNames {
 			if strings.EqualFold(col, table.AutoIncrement) {
@@ -0,0 +1,117 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"strings"
+
+	"xorm.io/builder"
+)
+
+// ErrColumnCountMismatch represents an error when the columns of INSERT and SELECT are not matched
+type ErrColumnCountMismatch struct {
+	InsertColumns int
+	SelectColumns int
+}
+
+func (err ErrColumnCountMismatch) Error() string {
+	return fmt.Sprintf("insert %d columns but select %d columns", err.InsertColumns, err.SelectColumns)
+}
+
+// countColumns returns the number of the top level columns in a column string, -1 means unknown
+func countColumns(columnStr string) int {
+	columnStr = strings.TrimSpace(columnStr)
+	if columnStr == "" || columnStr == "*" || strings.HasSuffix(columnStr, ".*") {
+		return -1
+	}
+
+	cnt, depth := 1, 0
+	var inQuote byte
+	for i := 0; i < len(columnStr); i++ {
+		c := columnStr[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			inQuote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			if strings.HasSuffix(strings.TrimSpace(columnStr[:i]), "*") {
+				return -1
+			}
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// GenSelectSQL generates the SQL of the statement which could be used as a sub query and
+// returns the number of the selected columns, -1 means the number is unknown
+func (statement *Statement) GenSelectSQL() (string, []interface{}, int, error) {
+	if statement.RawSQL != "" {
+		return statement.GenRawSQL(), statement.RawParams, -1, nil
+	}
+
+	if len(statement.TableName()) <= 0 {
+		return "", nil, 0, ErrTableNotFound
+	}
+
+	if statement.RefTable != nil {
+		if col := statement.RefTable.DeletedColumn(); col != nil && !statement.GetUnscoped() {
+			statement.cond = statement.cond.And(statement.CondDeleted(col))
+		}
+	}
+
+	columnStr := statement.genSelectColumnStr()
+	buf := builder.NewWriter()
+	if err := statement.writeSelect(buf, columnStr, false); err != nil {
+		return "", nil, 0, err
+	}
+	return buf.String(), buf.Args(), countColumns(columnStr), nil
+}
+
+// GenInsertSelectSQL generates "INSERT INTO table (columns) SELECT ..." SQL, selectColumns is the
+// number of the selected columns which will be validated if it's not negative
+func (statement *Statement) GenInsertSelectSQL(colNames []string, selectSQL string, selectArgs []interface{}, selectColumns int) (string, []interface{}, error) {
+	tableName := statement.TableName()
+	if len(tableName) <= 0 {
+		return "", nil, ErrTableNotFound
+	}
+	if len(colNames) > 0 && selectColumns >= 0 && len(colNames) != selectColumns {
+		return "", nil, ErrColumnCountMismatch{InsertColumns: len(colNames), SelectColumns: selectColumns}
+	}
+
+	var buf strings.Builder
+	if _, err := buf.WriteString("INSERT INTO "); err != nil {
+		return "", nil, err
+	}
+	if err := statement.dialect.Quoter().QuoteTo(&buf, tableName); err != nil {
+		return "", nil, err
+	}
+	if len(colNames) > 0 {
+		if _, err := buf.WriteString(" ("); err != nil {
+			return "", nil, err
+		}
+		if err := statement.dialect.Quoter().JoinWrite(&buf, colNames, ","); err != nil {
+			return "", nil, err
+		}
+		if _, err := buf.WriteString(")"); err != nil {
+			return "", nil, err
+		}
+	}
+	if _, err := buf.WriteString(" "); err != nil {
+		return "", nil, err
+	}
+	if _, err := buf.WriteString(selectSQL); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), selectArgs, nil
+}
@@ -5,6 +5,7 @@
 package statements
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -16,6 +17,9 @@ import (
 
 // Join The joinOP should be one of INNER, LEFT OUTER, CROSS etc - this will be prepended to JOIN
 func (statement *Statement) Join(joinOP string, joinTable interface{}, condition interface{}, args ...interface{}) *Statement {
+	if s, ok := condition.(string); ok {
+		condition, args = statement.normalizeSQL(s, args)
+	}
 	statement.joins = append(statement.joins, join{
 		op:        joinOP,
 		table:     joinTable,
@@ -25,6 +29,25 @@ func (statement *Statement) Join(joinOP string, joinTable interface{}, condition
 	return statement
 }
 
+// JoinLateral adds a lateral join of the subquery, which could reference the columns of the
+// preceding tables, i.e. the top N rows per group. The joinOP should be one of INNER, LEFT
+// and CROSS, the condition could be nil. It's written as JOIN LATERAL for postgres, mysql 8.0.14+
+// and oracle 12c+, or CROSS APPLY and OUTER APPLY for mssql.
+func (statement *Statement) JoinLateral(joinOP string, subQuery interface{}, alias string, condition interface{}, args ...interface{}) *Statement {
+	if s, ok := condition.(string); ok {
+		condition, args = statement.normalizeSQL(s, args)
+	}
+	statement.joins = append(statement.joins, join{
+		op:        joinOP,
+		table:     subQuery,
+		condition: condition,
+		args:      args,
+		lateral:   true,
+		alias:     alias,
+	})
+	return statement
+}
+
 func (statement *Statement) writeJoins(w *builder.BytesWriter) error {
 	for _, join := range statement.joins {
 		if err := statement.writeJoin(w, join); err != nil {
@@ -85,6 +108,10 @@ func (statement *Statement) writeJoinTable(buf *builder.BytesWriter, join join)
 }
 
 func (statement *Statement) writeJoin(buf *builder.BytesWriter, join join) error {
+	if join.lateral {
+		return statement.writeLateralJoin(buf, join)
+	}
+
 	// write join operator
 	if _, err := fmt.Fprint(buf, " ", join.op, " JOIN"); err != nil {
 		return err
@@ -100,6 +127,10 @@ func (statement *Statement) writeJoin(buf *builder.BytesWriter, join join) error
 		return err
 	}
 
+	return statement.writeJoinCondition(buf, join)
+}
+
+func (statement *Statement) writeJoinCondition(buf *builder.BytesWriter, join join) error {
 	switch condTp := join.condition.(type) {
 	case string:
 		if _, err := fmt.Fprint(buf, statement.ReplaceQuote(condTp)); err != nil {
@@ -117,6 +148,89 @@ func (statement *Statement) writeJoin(buf *builder.BytesWriter, join join) error
 	return nil
 }
 
+func (statement *Statement) writeLateralJoin(buf *builder.BytesWriter, join join) error {
+	if join.alias == "" {
+		return errors.New("lateral join needs an alias")
+	}
+	op := strings.ToUpper(strings.Join(strings.Fields(join.op), " "))
+	if op == "CROSS" && join.condition != nil {
+		return errors.New("cross lateral join should not have a condition")
+	}
+
+	switch statement.dialect.URI().DBType {
+	case schemas.POSTGRES, schemas.MYSQL, schemas.ORACLE:
+		if _, err := fmt.Fprint(buf, " ", op, " JOIN LATERAL ("); err != nil {
+			return err
+		}
+		if err := statement.writeSubQuery(buf, join.table); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(buf, ") ", statement.quote(join.alias)); err != nil {
+			return err
+		}
+		if op == "CROSS" {
+			return nil
+		}
+		if join.condition == nil {
+			_, err := fmt.Fprint(buf, " ON 1=1")
+			return err
+		}
+		if _, err := fmt.Fprint(buf, " ON "); err != nil {
+			return err
+		}
+		return statement.writeJoinCondition(buf, join)
+	case schemas.MSSQL:
+		var apply string
+		switch op {
+		case "INNER", "CROSS":
+			apply = " CROSS APPLY ("
+		case "LEFT", "LEFT OUTER":
+			apply = " OUTER APPLY ("
+		default:
+			return fmt.Errorf("%s lateral join is not supported by %s", op, schemas.MSSQL)
+		}
+		if _, err := fmt.Fprint(buf, apply); err != nil {
+			return err
+		}
+		// APPLY has no ON clause, so the condition filters the subquery
+		if join.condition != nil {
+			if _, err := fmt.Fprint(buf, "SELECT * FROM ("); err != nil {
+				return err
+			}
+		}
+		if err := statement.writeSubQuery(buf, join.table); err != nil {
+			return err
+		}
+		if join.condition != nil {
+			if _, err := fmt.Fprint(buf, ") ", statement.quote(join.alias), " WHERE "); err != nil {
+				return err
+			}
+			if err := statement.writeJoinCondition(buf, join); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprint(buf, ") ", statement.quote(join.alias))
+		return err
+	default:
+		return fmt.Errorf("lateral join is not supported by %s", statement.dialect.URI().DBType)
+	}
+}
+
+// writeSubQuery writes the subquery of a lateral join without the parentheses
+func (statement *Statement) writeSubQuery(buf *builder.BytesWriter, subQuery interface{}) error {
+	switch tp := subQuery.(type) {
+	case *builder.Builder:
+		return tp.WriteTo(statement.QuoteReplacer(buf))
+	case builder.Builder:
+		return tp.WriteTo(statement.QuoteReplacer(buf))
+	case string:
+		_, err := fmt.Fprint(buf, statement.ReplaceQuote(tp))
+		return err
+	default:
+		return fmt.Errorf("unsupported subquery type: %T", subQuery)
+	}
+}
+
 func (statement *Statement) convertJoinCondition(join join) (builder.Cond, error) {
 	switch condTp := join.condition.(type) {
 	case string:
@@ -0,0 +1,172 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/imkos/xorm/internal/json"
+	"github.com/imkos/xorm/schemas"
+	"xorm.io/builder"
+)
+
+// ErrUnsupportedJSONSet represents an error when the database cannot update a JSON path in place
+type ErrUnsupportedJSONSet struct {
+	DBType schemas.DBType
+}
+
+func (err ErrUnsupportedJSONSet) Error() string {
+	return fmt.Sprintf("JSONSet is not supported by %v", err.DBType)
+}
+
+// jsonSet represents one path mutation of a JSON column
+type jsonSet struct {
+	path  []string
+	value interface{}
+}
+
+// jsonSets represents all the path mutations of one JSON column, they
+// will be applied in order
+type jsonSets []jsonSet
+
+var jsonPathKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// isJSONArrayIndex returns true if the path element addresses an array item
+func isJSONArrayIndex(elem string) bool {
+	_, err := strconv.Atoi(elem)
+	return err == nil
+}
+
+// postgresJSONPath converts the path elements to a text array literal like {a,0,b}
+func postgresJSONPath(path []string) string {
+	elems := make([]string, 0, len(path))
+	for _, elem := range path {
+		if strings.ContainsAny(elem, `,{}"\ `) {
+			elem = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(elem) + `"`
+		}
+		elems = append(elems, elem)
+	}
+	return "{" + strings.Join(elems, ",") + "}"
+}
+
+// standardJSONPath converts the path elements to a SQL/JSON path like $.a[0].b
+func standardJSONPath(path []string) string {
+	var buf strings.Builder
+	buf.WriteString("$")
+	for _, elem := range path {
+		switch {
+		case isJSONArrayIndex(elem):
+			buf.WriteString("[" + elem + "]")
+		case jsonPathKeyRegexp.MatchString(elem):
+			buf.WriteString("." + elem)
+		default:
+			buf.WriteString(`."` + strings.ReplaceAll(elem, `"`, `\"`) + `"`)
+		}
+	}
+	return buf.String()
+}
+
+// JSONSet generates "Update ... Set column = json_set(column, path, value)" statement,
+// path is a dot separated list of object keys and array indexes, i.e. "tags.0.name"
+func (statement *Statement) JSONSet(column, path string, value interface{}) *Statement {
+	elems := splitJSONPath(path)
+	if len(elems) == 0 {
+		statement.LastError = fmt.Errorf("JSONSet on column %s needs a non-empty path", column)
+		return statement
+	}
+	switch dbType := statement.dialect.URI().DBType; dbType {
+	case schemas.POSTGRES, schemas.MYSQL, schemas.SQLITE, schemas.MSSQL:
+	default:
+		statement.LastError = ErrUnsupportedJSONSet{dbType}
+		return statement
+	}
+
+	set := jsonSet{path: elems, value: value}
+	for i, expr := range statement.ExprColumns {
+		if sets, ok := expr.Arg.(jsonSets); ok && strings.EqualFold(expr.ColName, column) {
+			statement.ExprColumns[i].Arg = append(sets, set)
+			return statement
+		}
+	}
+	statement.ExprColumns.Add(column, jsonSets{set})
+	return statement
+}
+
+// writeJSONSets writes the nested json_set calls of the column
+func (statement *Statement) writeJSONSets(w *builder.BytesWriter, colName string, sets jsonSets) error {
+	dbType := statement.dialect.URI().DBType
+	var fn, base string
+	switch dbType {
+	case schemas.POSTGRES:
+		fn, base = "jsonb_set", "CAST("+statement.quote(colName)+" AS jsonb)"
+	case schemas.MYSQL:
+		fn, base = "JSON_SET", statement.quote(colName)
+	case schemas.SQLITE:
+		fn, base = "json_set", statement.quote(colName)
+	case schemas.MSSQL:
+		fn, base = "JSON_MODIFY", statement.quote(colName)
+	default:
+		return ErrUnsupportedJSONSet{dbType}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(strings.Repeat(fn+"(", len(sets)))
+	buf.WriteString(base)
+	args := make([]interface{}, 0, len(sets)*2)
+	for _, set := range sets {
+		bs, err := json.DefaultJSONHandler.Marshal(set.value)
+		if err != nil {
+			return err
+		}
+		switch dbType {
+		case schemas.POSTGRES:
+			buf.WriteString(", CAST(? AS text[]), CAST(? AS jsonb))")
+			args = append(args, postgresJSONPath(set.path), string(bs))
+		case schemas.MYSQL:
+			buf.WriteString(", ?, CAST(? AS JSON))")
+			args = append(args, standardJSONPath(set.path), string(bs))
+		case schemas.SQLITE:
+			buf.WriteString(", ?, json(?))")
+			args = append(args, standardJSONPath(set.path), string(bs))
+		case schemas.MSSQL:
+			if len(bs) > 0 && (bs[0] == '{' || bs[0] == '[') {
+				buf.WriteString(", ?, JSON_QUERY(?))")
+				args = append(args, standardJSONPath(set.path), string(bs))
+			} else {
+				buf.WriteString(", ?, ?)")
+				args = append(args, standardJSONPath(set.path), set.value)
+			}
+		}
+	}
+
+	content := buf.String()
+	if dbType == schemas.POSTGRES && statement.RefTable != nil {
+		// jsonb_set always returns jsonb, convert it back to the column's type
+		if col := statement.RefTable.GetColumn(colName); col != nil && col.SQLType.Name != schemas.Jsonb {
+			tp := "text"
+			if col.SQLType.Name == schemas.Json {
+				tp = "json"
+			}
+			content = "CAST(" + content + " AS " + tp + ")"
+		}
+	}
+
+	if _, err := fmt.Fprint(w, statement.quote(colName), " = ", content); err != nil {
+		return err
+	}
+	w.Append(args...)
+	return nil
+}
@@ -28,6 +28,7 @@ func (statement *Statement) writeMssqlLegacySelect(buf *builder.BytesWriter, col
 		statement.writeHaving,
 		statement.writeOrderBys,
 		statement.writeForUpdate,
+		statement.writeQueryHints,
 	)
 }
 
@@ -5,28 +5,132 @@
 package statements
 
 import (
-	"errors"
 	"fmt"
+	"math"
 
+	"github.com/imkos/xorm/dialects"
 	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/schemas"
 	"xorm.io/builder"
 )
 
+// ErrUnsupportedLimit represents the limit or the offset of an operation is not supported by
+// the database, i.e. the offset of update and delete, or the limit of delete on oracle
+type ErrUnsupportedLimit struct {
+	Operation string // select, update or delete
+	DBType    schemas.DBType
+	Offset    bool // it's the offset which is not supported
+}
+
+func (err ErrUnsupportedLimit) Error() string {
+	if err.Offset {
+		return fmt.Sprintf("%s with the offset of limit is not supported by %s", err.Operation, err.DBType)
+	}
+	return fmt.Sprintf("%s with limit is not supported by %s", err.Operation, err.DBType)
+}
+
+func (statement *Statement) unsupportedLimit(operation string, offset bool) error {
+	return ErrUnsupportedLimit{
+		Operation: operation,
+		DBType:    statement.dialect.URI().DBType,
+		Offset:    offset,
+	}
+}
+
+// writeLimitSubquery writes the condition which limits the rows of UPDATE and DELETE by a
+// subquery of the primary key for the databases without UPDATE/DELETE ... ORDER BY ... LIMIT,
+// i.e. "id IN (SELECT id FROM t WHERE ... ORDER BY ... LIMIT n)". The ctid of postgres and the
+// rowid of sqlite are used instead if the table has no single primary key.
+func (statement *Statement) writeLimitSubquery(w *builder.BytesWriter, operation string, cond builder.Cond) error {
+	dbType := statement.dialect.URI().DBType
+	var column string
+	if table := statement.RefTable; table != nil && len(table.PrimaryKeys) == 1 {
+		column = statement.quote(table.PrimaryKeys[0])
+	} else {
+		switch dbType {
+		case schemas.POSTGRES:
+			column = "ctid"
+		case schemas.SQLITE:
+			column = "rowid"
+		default:
+			return fmt.Errorf("%s with limit needs a table with exactly one primary key on %s", operation, dbType)
+		}
+	}
+
+	if err := statement.writeWhereOrAnd(w, cond.IsValid()); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, column, " IN (SELECT "); err != nil {
+		return err
+	}
+	if dbType == schemas.MSSQL {
+		if _, err := fmt.Fprintf(w, "TOP (%d) ", *statement.LimitN); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprint(w, column, " FROM ", statement.quote(statement.TableName())); err != nil {
+		return err
+	}
+	if err := statement.writeWhereCond(w, cond); err != nil {
+		return err
+	}
+	if err := statement.writeOrderBys(w); err != nil {
+		return err
+	}
+	if dbType != schemas.MSSQL {
+		if _, err := fmt.Fprintf(w, " LIMIT %d", *statement.LimitN); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, ")")
+	return err
+}
+
 func (statement *Statement) writePagination(bw *builder.BytesWriter) error {
+	if p, ok := statement.dialect.(dialects.PaginationWriter); ok {
+		_, err := fmt.Fprint(bw, p.PaginationSQL(statement.LimitN, statement.Start))
+		return err
+	}
 	dbType := statement.dialect.URI().DBType
 	if dbType == schemas.MSSQL || dbType == schemas.ORACLE {
 		return statement.writeOffsetFetch(bw)
 	}
+	if dbType == schemas.DB2 {
+		return statement.writeFetchFirst(bw)
+	}
 	return statement.writeLimitOffset(bw)
 }
 
+// writeFetchFirst writes "OFFSET m ROWS FETCH FIRST n ROWS ONLY" (db2 only)
+func (statement *Statement) writeFetchFirst(w builder.Writer) error {
+	if statement.Start > 0 {
+		if _, err := fmt.Fprintf(w, " OFFSET %v ROWS", statement.Start); err != nil {
+			return err
+		}
+	}
+	if statement.LimitN != nil {
+		_, err := fmt.Fprintf(w, " FETCH FIRST %v ROWS ONLY", *statement.LimitN)
+		return err
+	}
+	return nil
+}
+
 func (statement *Statement) writeLimitOffset(w builder.Writer) error {
 	if statement.Start > 0 {
 		if statement.LimitN != nil {
 			_, err := fmt.Fprintf(w, " LIMIT %v OFFSET %v", *statement.LimitN, statement.Start)
 			return err
 		}
+		switch statement.dialect.URI().DBType {
+		case schemas.SNOWFLAKE:
+			// snowflake needs a LIMIT before OFFSET, NULL means no limit
+			_, err := fmt.Fprintf(w, " LIMIT NULL OFFSET %v", statement.Start)
+			return err
+		case schemas.HANA:
+			// hana needs a LIMIT before OFFSET
+			_, err := fmt.Fprintf(w, " LIMIT %d OFFSET %v", math.MaxInt32, statement.Start)
+			return err
+		}
 		_, err := fmt.Fprintf(w, " OFFSET %v", statement.Start)
 		return err
 	}
@@ -71,7 +175,8 @@ func (statement *Statement) writeMssqlPaginationCond(w *builder.BytesWriter) err
 	}
 
 	if statement.RefTable == nil {
-		return errors.New("unsupported query limit without reference table")
+		// the legacy offset needs a column of the table to exclude the skipped rows
+		return statement.unsupportedLimit("select", true)
 	}
 
 	var column string
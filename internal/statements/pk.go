@@ -5,6 +5,7 @@
 package statements
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 
@@ -35,7 +36,9 @@ func IsIDConditionWithNoTableErr(err error) bool {
 	return ok
 }
 
-// ID generate "where id = ? " statement or for composite key "where key1 = ? and key2 = ?"
+// ID generate "where id = ? " statement or for composite key "where key1 = ? and key2 = ?".
+// The id could be a value, a typed ID whose kind is string or integer or which implements
+// driver.Valuer, a schemas.PK or a struct bean whose primary keys are used
 func (statement *Statement) ID(id interface{}) *Statement {
 	switch t := id.(type) {
 	case *schemas.PK:
@@ -48,6 +51,15 @@ func (statement *Statement) ID(id interface{}) *Statement {
 		idValue := reflect.ValueOf(id)
 		idType := idValue.Type()
 
+		if schemas.GetIDConverter(idType) != nil {
+			statement.idParam = schemas.PK{id}
+			break
+		}
+		if _, ok := id.(driver.Valuer); ok {
+			statement.idParam = schemas.PK{id}
+			break
+		}
+
 		switch idType.Kind() {
 		case reflect.String:
 			statement.idParam = schemas.PK{idValue.Convert(stringType).Interface()}
@@ -62,17 +74,33 @@ func (statement *Statement) ID(id interface{}) *Statement {
 		case reflect.Ptr:
 			if idType.ConvertibleTo(ptrPkType) {
 				statement.idParam = idValue.Convert(ptrPkType).Elem().Interface().(schemas.PK)
+			} else if idType.Elem().Kind() == reflect.Struct && !idValue.IsNil() {
+				statement.idParam, statement.LastError = statement.beanID(idValue)
 			}
+		case reflect.Struct:
+			statement.idParam, statement.LastError = statement.beanID(idValue)
 		}
 	}
 
-	if statement.idParam == nil {
+	if statement.idParam == nil && statement.LastError == nil {
 		statement.LastError = fmt.Errorf("ID param %#v is not supported", id)
 	}
 
 	return statement
 }
 
+// beanID returns the primary keys of the bean
+func (statement *Statement) beanID(beanValue reflect.Value) (schemas.PK, error) {
+	table, err := statement.ParseTable(reflect.Indirect(beanValue))
+	if err != nil {
+		return nil, err
+	}
+	if len(table.PrimaryKeys) == 0 {
+		return nil, fmt.Errorf("the bean of the table %s has no primary key", table.Name)
+	}
+	return table.IDOfV(beanValue)
+}
+
 // ProcessIDParam handles the process of id condition
 func (statement *Statement) ProcessIDParam() error {
 	if statement.idParam == nil {
@@ -92,7 +120,11 @@ func (statement *Statement) ProcessIDParam() error {
 
 	for i, col := range statement.RefTable.PKColumns() {
 		var colName = statement.colName(col, statement.TableName())
-		statement.cond = statement.cond.And(builder.Eq{colName: statement.idParam[i]})
+		v, err := schemas.IDValue(statement.idParam[i])
+		if err != nil {
+			return err
+		}
+		statement.cond = statement.cond.And(builder.Eq{colName: v})
 	}
 	return nil
 }
@@ -0,0 +1,119 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/imkos/xorm/schemas"
+	"xorm.io/builder"
+)
+
+// ownedByCond filters the records whose polymorphic reference is the owner, the name of the
+// reference is looked up from the statement's table when the SQL is written if it's not given
+type ownedByCond struct {
+	statement *Statement
+	name      string
+	ownerType string
+	ownerID   int64
+}
+
+var _ builder.Cond = ownedByCond{}
+
+func (cond ownedByCond) WriteTo(w builder.Writer) error {
+	name := cond.name
+	if name == "" {
+		var err error
+		if name, err = cond.statement.polymorphicName(); err != nil {
+			return err
+		}
+	}
+
+	typeColumn, idColumn := schemas.PolymorphicColumns(name)
+	tableName := cond.statement.TableName()
+	return builder.Eq{
+		cond.statement.colName(&schemas.Column{Name: typeColumn}, tableName): cond.ownerType,
+		cond.statement.colName(&schemas.Column{Name: idColumn}, tableName):   cond.ownerID,
+	}.WriteTo(w)
+}
+
+func (cond ownedByCond) And(conds ...builder.Cond) builder.Cond {
+	return builder.And(cond, builder.And(conds...))
+}
+
+func (cond ownedByCond) Or(conds ...builder.Cond) builder.Cond {
+	return builder.Or(cond, builder.Or(conds...))
+}
+
+func (cond ownedByCond) IsValid() bool {
+	return true
+}
+
+// polymorphicName returns the name of the only polymorphic reference of the statement's table
+func (statement *Statement) polymorphicName() (string, error) {
+	if statement.RefTable == nil {
+		return "", fmt.Errorf("OwnedBy needs the name of the polymorphic reference without a table")
+	}
+	names := statement.RefTable.Polymorphics()
+	if len(names) != 1 {
+		return "", fmt.Errorf("OwnedBy needs the name of the polymorphic reference since table %s has %d references",
+			statement.RefTable.Name, len(names))
+	}
+	return names[0], nil
+}
+
+// Polymorphic returns the polymorphic reference to the bean, the type is the table name of
+// the bean and the id is its primary key which should be an integer
+func (statement *Statement) Polymorphic(bean interface{}) (schemas.Polymorphic, error) {
+	v := rValue(bean)
+	table, err := statement.ParseTable(v)
+	if err != nil {
+		return schemas.Polymorphic{}, err
+	}
+	pkCols := table.PKColumns()
+	if len(pkCols) != 1 {
+		return schemas.Polymorphic{}, fmt.Errorf("polymorphic reference needs table %s with exactly one primary key", table.Name)
+	}
+	pkValue, err := pkCols[0].ValueOfV(&v)
+	if err != nil {
+		return schemas.Polymorphic{}, err
+	}
+
+	ref := schemas.Polymorphic{Type: table.Name}
+	switch pk := reflect.Indirect(*pkValue); pk.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ref.ID = pk.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ref.ID = int64(pk.Uint())
+	default:
+		return schemas.Polymorphic{}, fmt.Errorf("polymorphic reference needs an integer primary key but table %s has %v",
+			table.Name, pk.Type())
+	}
+	return ref, nil
+}
+
+// OwnedBy filters the records whose polymorphic reference is the bean, the name of the
+// reference could be omitted if the table has only one polymorphic reference
+func (statement *Statement) OwnedBy(bean interface{}, name string) *Statement {
+	ref, err := statement.Polymorphic(bean)
+	if err != nil {
+		statement.LastError = err
+		return statement
+	}
+	if name == "" && statement.RefTable != nil {
+		if name, err = statement.polymorphicName(); err != nil {
+			statement.LastError = err
+			return statement
+		}
+	}
+	statement.cond = statement.cond.And(ownedByCond{
+		statement: statement,
+		name:      name,
+		ownerType: ref.Type,
+		ownerID:   ref.ID,
+	})
+	return statement
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"xorm.io/builder"
+	"github.com/imkos/xorm/dialects"
 	"github.com/imkos/xorm/schemas"
 )
 
@@ -41,6 +42,12 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 
 // GenSumSQL generates sum SQL
 func (statement *Statement) GenSumSQL(bean interface{}, columns ...string) (string, []interface{}, error) {
+	return statement.GenAggregateSQL(bean, "COALESCE(sum(%s),0)", columns...)
+}
+
+// GenAggregateSQL generates SQL to select the aggregate expressions of the columns,
+// exprFormat is the format of every expression, i.e. "max(%s)"
+func (statement *Statement) GenAggregateSQL(bean interface{}, exprFormat string, columns ...string) (string, []interface{}, error) {
 	if statement.RawSQL != "" {
 		return statement.GenRawSQL(), statement.RawParams, nil
 	}
@@ -49,14 +56,14 @@ func (statement *Statement) GenSumSQL(bean interface{}, columns ...string) (stri
 		return "", nil, err
 	}
 
-	sumStrs := make([]string, 0, len(columns))
+	exprStrs := make([]string, 0, len(columns))
 	for _, colName := range columns {
 		if !strings.Contains(colName, " ") && !strings.Contains(colName, "(") {
 			colName = statement.quote(colName)
 		} else {
 			colName = statement.ReplaceQuote(colName)
 		}
-		sumStrs = append(sumStrs, fmt.Sprintf("COALESCE(sum(%s),0)", colName))
+		exprStrs = append(exprStrs, fmt.Sprintf(exprFormat, colName))
 	}
 
 	if err := statement.MergeConds(bean); err != nil {
@@ -64,7 +71,7 @@ func (statement *Statement) GenSumSQL(bean interface{}, columns ...string) (stri
 	}
 
 	buf := builder.NewWriter()
-	if err := statement.writeSelect(buf, strings.Join(sumStrs, ", "), true); err != nil {
+	if err := statement.writeSelect(buf, strings.Join(exprStrs, ", "), true); err != nil {
 		return "", nil, err
 	}
 	return buf.String(), buf.Args(), nil
@@ -82,6 +89,9 @@ func (statement *Statement) GenGetSQL(bean interface{}) (string, []interface{},
 			}
 		}
 	}
+	if err := statement.CheckColumns(); err != nil {
+		return "", nil, err
+	}
 
 	columnStr := statement.ColumnStr()
 	if len(statement.SelectStr) > 0 {
@@ -209,8 +219,15 @@ func (statement *Statement) writeDistinct(w *builder.BytesWriter) error {
 func (statement *Statement) writeSelectColumns(columnStr string) func(w *builder.BytesWriter) error {
 	return statement.groupWriteFns(
 		statement.writeStrings("SELECT"),
+		statement.writeHints,
 		statement.writeDistinct,
 		statement.writeStrings(" ", columnStr),
+		func(w *builder.BytesWriter) error {
+			if statement.isGroupByColumnStr(columnStr) {
+				w.Append(statement.groupByArgs...)
+			}
+			return nil
+		},
 	)
 }
 
@@ -242,6 +259,8 @@ func (statement *Statement) writeForUpdate(w *builder.BytesWriter) error {
 }
 
 func (statement *Statement) writeSelect(buf *builder.BytesWriter, columnStr string, isCounting bool) error {
+	statement.ApplyDefaultScope()
+
 	dbType := statement.dialect.URI().DBType
 	if statement.isUsingLegacyLimitOffset() {
 		if dbType == "mssql" {
@@ -258,6 +277,7 @@ func (statement *Statement) writeSelect(buf *builder.BytesWriter, columnStr stri
 		statement.writeWhere,
 		statement.writeGroupBy,
 		statement.writeHaving,
+		statement.writeQualify,
 		func(bw *builder.BytesWriter) (err error) {
 			if dbType == "mssql" && len(statement.orderBy) == 0 {
 				// ORDER BY is mandatory to use OFFSET and FETCH clause (only in sqlserver)
@@ -283,6 +303,7 @@ func (statement *Statement) writeSelect(buf *builder.BytesWriter, columnStr stri
 		},
 		statement.writePagination,
 		statement.writeForUpdate,
+		statement.writeQueryHints,
 	)
 }
 
@@ -358,7 +379,14 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 		if err := statement.writeWhere(buf); err != nil {
 			return "", nil, err
 		}
-		if _, err := fmt.Fprintf(buf, " LIMIT 1"); err != nil {
+		limit := " LIMIT 1"
+		if p, ok := statement.dialect.(dialects.PaginationWriter); ok {
+			one := 1
+			limit = p.PaginationSQL(&one, 0)
+		} else if statement.dialect.URI().DBType == schemas.DB2 {
+			limit = " FETCH FIRST 1 ROWS ONLY"
+		}
+		if _, err := fmt.Fprint(buf, limit); err != nil {
 			return "", nil, err
 		}
 	}
@@ -402,6 +430,9 @@ func (statement *Statement) GenFindSQL(autoCond builder.Cond) (string, []interfa
 	if len(statement.TableName()) <= 0 {
 		return "", nil, ErrTableNotFound
 	}
+	if err := statement.CheckColumns(); err != nil {
+		return "", nil, err
+	}
 
 	statement.cond = statement.cond.And(autoCond)
 
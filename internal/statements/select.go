@@ -50,6 +50,11 @@ func (statement *Statement) AllCols() *Statement {
 	return statement
 }
 
+// GetAllCols returns true if all the columns will be updated
+func (statement *Statement) GetAllCols() bool {
+	return statement.useAllCols
+}
+
 // MustCols update use only: must update columns
 func (statement *Statement) MustCols(columns ...string) *Statement {
 	newColumns := col2NewCols(columns...)
@@ -98,6 +103,11 @@ func (statement *Statement) genColumnStr() string {
 			continue
 		}
 
+		// an invisible column is only selected if it's specified
+		if col.IsInvisible && len(statement.ColumnMap) == 0 {
+			continue
+		}
+
 		if buf.Len() != 0 {
 			buf.WriteString(", ")
 		}
@@ -135,3 +145,56 @@ func (statement *Statement) Distinct(columns ...string) *Statement {
 	statement.Cols(columns...)
 	return statement
 }
+
+// ErrColumnNotFound represents a column given by Cols, Omit, MustCols, Incr, Decr or SetExpr
+// is not a column of the table
+type ErrColumnNotFound struct {
+	Column    string
+	TableName string
+	// Columns are the valid column names of the table
+	Columns []string
+}
+
+func (err ErrColumnNotFound) Error() string {
+	return fmt.Sprintf("column %s is not found in table %s, the valid columns are: %s",
+		err.Column, err.TableName, strings.Join(err.Columns, ", "))
+}
+
+// CheckColumns checks the columns given by Cols, Omit, MustCols, Incr, Decr and SetExpr are
+// the columns of the table. The expressions, the qualified columns, the statements with joins
+// and the statements whose table is not the one of the bean are not checked since the columns
+// may belong to the other tables.
+func (statement *Statement) CheckColumns() error {
+	if statement.RefTable == nil || len(statement.joins) > 0 {
+		return nil
+	}
+	tableName := statement.TableName()
+	if tableName != statement.RefTable.Name && !strings.HasSuffix(tableName, "."+statement.RefTable.Name) {
+		return nil
+	}
+
+	columns := make([]string, 0, len(statement.ColumnMap)+len(statement.OmitColumnMap)+len(statement.MustColumnMap))
+	columns = append(columns, statement.ColumnMap...)
+	columns = append(columns, statement.OmitColumnMap...)
+	for col := range statement.MustColumnMap {
+		columns = append(columns, col)
+	}
+	columns = append(columns, statement.IncrColumns.ColNames()...)
+	columns = append(columns, statement.DecrColumns.ColNames()...)
+	columns = append(columns, statement.ExprColumns.ColNames()...)
+
+	for _, col := range columns {
+		col = strings.Trim(col, "`\"[]")
+		if col == "" || strings.ContainsAny(col, ".*() ") {
+			continue
+		}
+		if statement.RefTable.GetColumn(col) == nil {
+			return ErrColumnNotFound{
+				Column:    col,
+				TableName: statement.RefTable.Name,
+				Columns:   statement.RefTable.ColumnsSeq(),
+			}
+		}
+	}
+	return nil
+}
@@ -39,6 +39,8 @@ type join struct {
 	table     interface{}
 	condition interface{}
 	args      []interface{}
+	lateral   bool
+	alias     string // the alias of the lateral subquery
 }
 
 type indexHint struct {
@@ -59,7 +61,10 @@ type Statement struct {
 	orderBy         []orderBy
 	joins           []join
 	GroupByStr      string
+	groupByArgs     []interface{}
 	HavingStr       string
+	havingArgs      []interface{}
+	QualifyStr      string
 	SelectStr       string
 	useAllCols      bool
 	AltTableName    string
@@ -71,11 +76,14 @@ type Statement struct {
 	StoreEngine     string
 	Charset         string
 	UseCache        bool
+	NoReflectCache  bool
 	UseAutoTime     bool
+	AutoTime        time.Time // the time of the auto time columns set by WithTime
 	NoAutoCondition bool
 	IsDistinct      bool
 	IsForUpdate     bool
 	TableAlias      string
+	schema          string
 	allUseBool      bool
 	CheckVersion    bool
 	unscoped        bool
@@ -91,6 +99,11 @@ type Statement struct {
 	Context         contexts.ContextCache
 	LastError       error
 	indexHints      []indexHint
+	hints           []string
+
+	defaultScopes       func(tableName string) builder.Cond
+	deletedConds        func(tableName string) func(col *schemas.Column) builder.Cond
+	defaultScopeApplied bool
 }
 
 // NewStatement creates a new statement
@@ -137,7 +150,10 @@ func (statement *Statement) Reset() {
 	statement.UseCascade = true
 	statement.joins = nil
 	statement.GroupByStr = ""
+	statement.groupByArgs = nil
 	statement.HavingStr = ""
+	statement.havingArgs = nil
+	statement.QualifyStr = ""
 	statement.ColumnMap = columnMap{}
 	statement.OmitColumnMap = columnMap{}
 	statement.AltTableName = ""
@@ -146,11 +162,14 @@ func (statement *Statement) Reset() {
 	statement.RawSQL = ""
 	statement.RawParams = make([]interface{}, 0)
 	statement.UseCache = true
+	statement.NoReflectCache = false
 	statement.UseAutoTime = true
+	statement.AutoTime = time.Time{}
 	statement.NoAutoCondition = false
 	statement.IsDistinct = false
 	statement.IsForUpdate = false
 	statement.TableAlias = ""
+	statement.schema = ""
 	statement.SelectStr = ""
 	statement.allUseBool = false
 	statement.useAllCols = false
@@ -165,6 +184,61 @@ func (statement *Statement) Reset() {
 	statement.BufferSize = 0
 	statement.Context = nil
 	statement.LastError = nil
+	statement.hints = nil
+	statement.defaultScopeApplied = false
+}
+
+// Clone returns a copy of the statement, the slices and the maps are duplicated so that
+// building on the copy will not change the original statement
+func (statement *Statement) Clone() *Statement {
+	newStatement := *statement
+	if statement.LimitN != nil {
+		limitN := *statement.LimitN
+		newStatement.LimitN = &limitN
+	}
+	newStatement.idParam = append(schemas.PK(nil), statement.idParam...)
+	newStatement.orderBy = append([]orderBy(nil), statement.orderBy...)
+	newStatement.joins = append([]join(nil), statement.joins...)
+	newStatement.groupByArgs = append([]interface{}(nil), statement.groupByArgs...)
+	newStatement.havingArgs = append([]interface{}(nil), statement.havingArgs...)
+	newStatement.RawParams = append([]interface{}{}, statement.RawParams...)
+	newStatement.ColumnMap = append(columnMap{}, statement.ColumnMap...)
+	newStatement.OmitColumnMap = append(columnMap{}, statement.OmitColumnMap...)
+	newStatement.MustColumnMap = make(map[string]bool, len(statement.MustColumnMap))
+	for k, v := range statement.MustColumnMap {
+		newStatement.MustColumnMap[k] = v
+	}
+	newStatement.NullableMap = make(map[string]bool, len(statement.NullableMap))
+	for k, v := range statement.NullableMap {
+		newStatement.NullableMap[k] = v
+	}
+	newStatement.IncrColumns = append(exprParams{}, statement.IncrColumns...)
+	newStatement.DecrColumns = append(exprParams{}, statement.DecrColumns...)
+	newStatement.ExprColumns = append(exprParams{}, statement.ExprColumns...)
+	newStatement.indexHints = append([]indexHint(nil), statement.indexHints...)
+	newStatement.hints = append([]string(nil), statement.hints...)
+	return &newStatement
+}
+
+// normalizeSQL rewrites the numbered or named placeholders of the SQL fragment to ?, so that
+// the fragment written for any database could be mixed with the generated SQL, and all the
+// placeholders are converted for the database by its filters on execution. The slice args of
+// IN (?) are expanded to the placeholders of their elements.
+func (statement *Statement) normalizeSQL(query string, args []interface{}) (string, []interface{}) {
+	newQuery, newArgs, err := normalizeSQL(query, args)
+	if err != nil {
+		statement.LastError = err
+		return query, args
+	}
+	return newQuery, newArgs
+}
+
+func normalizeSQL(query string, args []interface{}) (string, []interface{}, error) {
+	query, args, err := utils.NormalizePlaceholders(query, args)
+	if err != nil {
+		return "", nil, err
+	}
+	return utils.ExpandSliceArgs(query, args)
 }
 
 // SQL adds raw sql statement
@@ -177,8 +251,7 @@ func (statement *Statement) SQL(query interface{}, args ...interface{}) *Stateme
 			statement.LastError = err
 		}
 	case string:
-		statement.RawSQL = t
-		statement.RawParams = args
+		statement.RawSQL, statement.RawParams = statement.normalizeSQL(t, args)
 	default:
 		statement.LastError = ErrUnSupportedSQLType
 	}
@@ -186,6 +259,15 @@ func (statement *Statement) SQL(query interface{}, args ...interface{}) *Stateme
 	return statement
 }
 
+// ParseTable parses the struct's table information, the tag parser's cache will be
+// bypassed if NoReflectCache is set
+func (statement *Statement) ParseTable(v reflect.Value) (*schemas.Table, error) {
+	if statement.NoReflectCache {
+		return statement.tagParser.Parse(v)
+	}
+	return statement.tagParser.ParseWithCache(v)
+}
+
 func (statement *Statement) quote(s string) string {
 	return statement.dialect.Quoter().Quote(s)
 }
@@ -193,7 +275,7 @@ func (statement *Statement) quote(s string) string {
 // SetRefValue set ref value
 func (statement *Statement) SetRefValue(v reflect.Value) error {
 	var err error
-	statement.RefTable, err = statement.tagParser.ParseWithCache(reflect.Indirect(v))
+	statement.RefTable, err = statement.ParseTable(reflect.Indirect(v))
 	if err != nil {
 		return err
 	}
@@ -208,7 +290,7 @@ func rValue(bean interface{}) reflect.Value {
 // SetRefBean set ref bean
 func (statement *Statement) SetRefBean(bean interface{}) error {
 	var err error
-	statement.RefTable, err = statement.tagParser.ParseWithCache(rValue(bean))
+	statement.RefTable, err = statement.ParseTable(rValue(bean))
 	if err != nil {
 		return err
 	}
@@ -285,7 +367,7 @@ func (statement *Statement) SetTable(tableNameOrBean interface{}) error {
 	t := v.Type()
 	if t.Kind() == reflect.Struct {
 		var err error
-		statement.RefTable, err = statement.tagParser.ParseWithCache(v)
+		statement.RefTable, err = statement.ParseTable(v)
 		if err != nil {
 			return err
 		}
@@ -295,9 +377,35 @@ func (statement *Statement) SetTable(tableNameOrBean interface{}) error {
 	return nil
 }
 
-// GroupBy generate "Group By keys" statement
-func (statement *Statement) GroupBy(keys string) *Statement {
-	statement.GroupByStr = statement.ReplaceQuote(keys)
+// GroupBy generate "Group By keys" statement, a key could be a column, a comma separated
+// columns string or a builder.Cond expression, i.e. builder.Expr("DATE(created)"). The args of
+// the expressions are kept as the args of their placeholders. Postgres doesn't take two
+// placeholders as the same expression, so the selected expressions with args should be grouped
+// by their positions there, i.e. GroupBy("1").
+func (statement *Statement) GroupBy(keys ...interface{}) *Statement {
+	exprs := make([]string, 0, len(keys))
+	var args []interface{}
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			if k = strings.TrimSpace(k); k != "" {
+				exprs = append(exprs, statement.ReplaceQuote(k))
+			}
+		case builder.Cond:
+			w := builder.NewWriter()
+			if err := k.WriteTo(statement.QuoteReplacer(w)); err != nil {
+				statement.LastError = err
+				return statement
+			}
+			exprs = append(exprs, w.String())
+			args = append(args, w.Args()...)
+		default:
+			statement.LastError = fmt.Errorf("unsupported group by key type: %T", key)
+			return statement
+		}
+	}
+	statement.GroupByStr = strings.Join(exprs, ", ")
+	statement.groupByArgs = args
 	return statement
 }
 
@@ -305,13 +413,38 @@ func (statement *Statement) writeGroupBy(w *builder.BytesWriter) error {
 	if statement.GroupByStr == "" {
 		return nil
 	}
-	_, err := fmt.Fprint(w, " GROUP BY ", statement.GroupByStr)
-	return err
+	if _, err := fmt.Fprint(w, " GROUP BY ", statement.GroupByStr); err != nil {
+		return err
+	}
+	w.Append(statement.groupByArgs...)
+	return nil
+}
+
+// isGroupByColumnStr returns true if the columns are the group by expressions selected when
+// no columns are given, their args should be appended too
+func (statement *Statement) isGroupByColumnStr(columnStr string) bool {
+	return len(statement.groupByArgs) > 0 &&
+		(columnStr == statement.GroupByStr || columnStr == statement.quoteColumnStr(statement.GroupByStr))
 }
 
-// Having generate "Having conditions" statement
-func (statement *Statement) Having(conditions string) *Statement {
-	statement.HavingStr = conditions
+// Having generate "Having conditions" statement, the conditions could be a string with the
+// args of its placeholders or a builder.Cond
+func (statement *Statement) Having(conditions interface{}, args ...interface{}) *Statement {
+	switch cond := conditions.(type) {
+	case string:
+		cond, args = statement.normalizeSQL(cond, args)
+		statement.HavingStr, statement.havingArgs = statement.ReplaceQuote(cond), args
+	case builder.Cond:
+		w := builder.NewWriter()
+		if err := cond.WriteTo(statement.QuoteReplacer(w)); err != nil {
+			statement.LastError = err
+			return statement
+		}
+		statement.HavingStr = w.String()
+		statement.havingArgs = w.Args()
+	default:
+		statement.LastError = fmt.Errorf("unsupported having conditions type: %T", conditions)
+	}
 	return statement
 }
 
@@ -319,7 +452,27 @@ func (statement *Statement) writeHaving(w *builder.BytesWriter) error {
 	if statement.HavingStr == "" {
 		return nil
 	}
-	_, err := fmt.Fprint(w, " HAVING ", statement.ReplaceQuote(statement.HavingStr))
+	if _, err := fmt.Fprint(w, " HAVING ", statement.HavingStr); err != nil {
+		return err
+	}
+	w.Append(statement.havingArgs...)
+	return nil
+}
+
+// Qualify generate "QUALIFY conditions" statement which filters the results of the window functions
+func (statement *Statement) Qualify(conditions string) *Statement {
+	statement.QualifyStr = conditions
+	return statement
+}
+
+func (statement *Statement) writeQualify(w *builder.BytesWriter) error {
+	if statement.QualifyStr == "" {
+		return nil
+	}
+	if !statement.dialect.Features().SupportQualify {
+		return fmt.Errorf("QUALIFY is not supported by %s", statement.dialect.URI().DBType)
+	}
+	_, err := fmt.Fprint(w, " QUALIFY ", statement.ReplaceQuote(statement.QualifyStr))
 	return err
 }
 
@@ -339,9 +492,10 @@ func (statement *Statement) GenIndexSQL() []string {
 	var sqls []string
 	tbName := statement.TableName()
 	for _, index := range statement.RefTable.Indexes {
-		if index.Type == schemas.IndexType {
-			sql := statement.dialect.CreateIndexSQL(tbName, index)
-			sqls = append(sqls, sql)
+		if index.Type == schemas.IndexType || index.Type == schemas.SpatialType {
+			if sql := statement.dialect.CreateIndexSQL(tbName, index); sql != "" {
+				sqls = append(sqls, sql)
+			}
 		}
 	}
 	return sqls
@@ -353,8 +507,9 @@ func (statement *Statement) GenUniqueSQL() []string {
 	tbName := statement.TableName()
 	for _, index := range statement.RefTable.Indexes {
 		if index.Type == schemas.UniqueType {
-			sql := statement.dialect.CreateIndexSQL(tbName, index)
-			sqls = append(sqls, sql)
+			if sql := statement.dialect.CreateIndexSQL(tbName, index); sql != "" {
+				sqls = append(sqls, sql)
+			}
 		}
 	}
 	return sqls
@@ -369,12 +524,24 @@ func (statement *Statement) GenDelIndexSQL() []string {
 		tbName = tbName[idx+1:]
 	}
 	for _, index := range statement.RefTable.Indexes {
-		sqls = append(sqls, statement.dialect.DropIndexSQL(tbName, index))
+		if sql := statement.dialect.DropIndexSQL(tbName, index); sql != "" {
+			sqls = append(sqls, sql)
+		}
 	}
 	return sqls
 }
 
 func (statement *Statement) asDBCond(fieldValue reflect.Value, fieldType reflect.Type, col *schemas.Column, allUseBool, requiredField bool) (interface{}, bool, error) {
+	if v, ok, err := statement.bitSetValue(col, fieldValue); ok {
+		if err != nil {
+			return nil, false, err
+		}
+		if v == nil && !requiredField {
+			return nil, false, nil
+		}
+		return v, true, nil
+	}
+
 	switch fieldType.Kind() {
 	case reflect.Ptr:
 		if fieldValue.IsNil() {
@@ -401,6 +568,9 @@ func (statement *Statement) asDBCond(fieldValue reflect.Value, fieldType reflect
 		if !requiredField && fieldValue.Int() == 0 {
 			return nil, false, nil
 		}
+		if col.IsDuration {
+			return durationValue(col, time.Duration(fieldValue.Int())), true, nil
+		}
 		return fieldValue.Interface(), true, nil
 	case reflect.Float32, reflect.Float64:
 		if !requiredField && fieldValue.Float() == 0.0 {
@@ -413,6 +583,16 @@ func (statement *Statement) asDBCond(fieldValue reflect.Value, fieldType reflect
 		}
 		return fieldValue.Interface(), true, nil
 	case reflect.Struct:
+		if reflect.PtrTo(fieldType).Implements(geometryType) {
+			// geometries could not be compared by =, please use WhereWithin or Where instead
+			return nil, false, nil
+		}
+		if v, ok := netipValue(fieldValue); ok {
+			if v == nil && !requiredField {
+				return nil, false, nil
+			}
+			return v, true, nil
+		}
 		if fieldType.ConvertibleTo(schemas.TimeType) {
 			t := fieldValue.Convert(schemas.TimeType).Interface().(time.Time)
 			if !requiredField && (t.IsZero() || !fieldValue.IsValid()) {
@@ -430,6 +610,12 @@ func (statement *Statement) asDBCond(fieldValue reflect.Value, fieldType reflect
 				return nil, false, nil
 			}
 			return t.String(), true, nil
+		} else if fieldType.ConvertibleTo(schemas.DecimalType) {
+			t := fieldValue.Convert(schemas.DecimalType).Interface().(convert.Decimal)
+			if !requiredField && t.IsZero() {
+				return nil, false, nil
+			}
+			return t.String(), true, nil
 		} else if _, ok := reflect.New(fieldType).Interface().(convert.Conversion); ok {
 			return nil, false, nil
 		} else if valNul, ok := fieldValue.Interface().(driver.Valuer); ok {
@@ -456,7 +642,7 @@ func (statement *Statement) asDBCond(fieldValue reflect.Value, fieldType reflect
 					return bytes, true, nil
 				}
 			} else {
-				table, err := statement.tagParser.ParseWithCache(fieldValue)
+				table, err := statement.ParseTable(fieldValue)
 				if err != nil {
 					return fieldValue.Interface(), true, nil
 				}
@@ -645,8 +831,12 @@ func (statement *Statement) convertSQLOrArgs(sqlOrArgs ...interface{}) (string,
 	switch sqlOrArgs[0].(type) {
 	case string:
 		if len(sqlOrArgs) > 1 {
-			newArgs := make([]interface{}, 0, len(sqlOrArgs)-1)
-			for _, arg := range sqlOrArgs[1:] {
+			query, args, err := normalizeSQL(sqlOrArgs[0].(string), sqlOrArgs[1:])
+			if err != nil {
+				return "", nil, err
+			}
+			newArgs := make([]interface{}, 0, len(args))
+			for _, arg := range args {
 				if v, ok := arg.(time.Time); ok {
 					newArgs = append(newArgs, v.In(statement.defaultTimeZone).Format("2006-01-02 15:04:05"))
 				} else if v, ok := arg.(*time.Time); ok && v != nil {
@@ -672,7 +862,7 @@ func (statement *Statement) convertSQLOrArgs(sqlOrArgs ...interface{}) (string,
 					newArgs = append(newArgs, arg)
 				}
 			}
-			return sqlOrArgs[0].(string), newArgs, nil
+			return query, newArgs, nil
 		}
 		return sqlOrArgs[0].(string), sqlOrArgs[1:], nil
 	case *builder.Builder:
@@ -700,6 +890,14 @@ func (statement *Statement) joinColumns(cols []*schemas.Column, includeTableName
 
 // CondDeleted returns the conditions whether a record is soft deleted.
 func (statement *Statement) CondDeleted(col *schemas.Column) builder.Cond {
+	if statement.deletedConds != nil {
+		if tableName := statement.TableName(); tableName != "" {
+			if deletedCond := statement.deletedConds(tableName); deletedCond != nil {
+				return deletedCond(col)
+			}
+		}
+	}
+
 	colName := statement.quote(col.Name)
 	if len(statement.joins) > 0 {
 		var prefix string
@@ -711,7 +909,9 @@ func (statement *Statement) CondDeleted(col *schemas.Column) builder.Cond {
 		colName = statement.quote(prefix) + "." + statement.quote(col.Name)
 	}
 	cond := builder.NewCond()
-	if col.SQLType.IsNumeric() {
+	if col.IsDeletedFlag {
+		cond = builder.Eq{colName: col.DeletedFlagValue(false)}
+	} else if col.SQLType.IsNumeric() {
 		cond = builder.Eq{colName: 0}
 	} else if col.SQLType.Name == schemas.TimeStamp || col.SQLType.Name == schemas.TimeStampz {
 		tmZone := statement.defaultTimeZone
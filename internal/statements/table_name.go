@@ -9,16 +9,38 @@ import (
 	"strings"
 
 	"xorm.io/builder"
+	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/schemas"
 )
 
 // TableName return current tableName
 func (statement *Statement) TableName() string {
 	if statement.AltTableName != "" {
-		return statement.AltTableName
+		return statement.withSchema(statement.AltTableName)
 	}
 
-	return statement.tableName
+	return statement.withSchema(statement.tableName)
+}
+
+// Schema sets the schema of the table for the statement instead of the default schema
+func (statement *Statement) Schema(schema string) *Statement {
+	statement.schema = strings.TrimSpace(schema)
+	return statement
+}
+
+// withSchema replaces the default schema of the table name with the schema of the statement,
+// the table names qualified by other schemas are kept
+func (statement *Statement) withSchema(tableName string) string {
+	if statement.schema == "" || tableName == "" || utils.IsSubQuery(tableName) {
+		return tableName
+	}
+	if defaultSchema := statement.dialect.URI().Schema; defaultSchema != "" {
+		tableName = strings.TrimPrefix(tableName, defaultSchema+".")
+	}
+	if strings.Contains(tableName, ".") {
+		return tableName
+	}
+	return statement.schema + "." + tableName
 }
 
 // Alias set the table alias
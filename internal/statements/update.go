@@ -13,6 +13,7 @@ import (
 
 	"github.com/imkos/xorm/convert"
 	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/internal/json"
 	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/schemas"
@@ -124,6 +125,20 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 
 		var val interface{}
 
+		if g, ok := asGeometry(fieldValue); ok {
+			if !includeNil && !requiredField && utils.IsZero(fieldValue.Interface()) {
+				continue
+			}
+			if g != nil {
+				v, err := geo.Encode(statement.dialect.URI().DBType, g)
+				if err != nil {
+					return nil, nil, err
+				}
+				val = v
+			}
+			goto APPEND
+		}
+
 		if fieldValue.CanAddr() {
 			if structConvert, ok := fieldValue.Addr().Interface().(convert.Conversion); ok {
 				if !includeNil && !requiredField && utils.IsZero(fieldValue.Interface()) {
@@ -174,6 +189,17 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 			}
 		}
 
+		if v, ok, err := statement.bitSetValue(col, fieldValue); ok {
+			if err != nil {
+				return nil, nil, err
+			}
+			if v == nil && !requiredField {
+				continue
+			}
+			val = v
+			goto APPEND
+		}
+
 		switch fieldType.Kind() {
 		case reflect.Bool:
 			if allUseBool || requiredField {
@@ -197,7 +223,11 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 			if !requiredField && fieldValue.Int() == 0 {
 				continue
 			}
-			val = fieldValue.Interface()
+			if col.IsDuration {
+				val = durationValue(col, time.Duration(fieldValue.Int()))
+			} else {
+				val = fieldValue.Interface()
+			}
 		case reflect.Float32, reflect.Float64:
 			if !requiredField && fieldValue.Float() == 0.0 {
 				continue
@@ -209,7 +239,12 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 			}
 			val = fieldValue.Interface()
 		case reflect.Struct:
-			if fieldType.ConvertibleTo(schemas.TimeType) {
+			if v, ok := netipValue(fieldValue); ok {
+				if v == nil && !requiredField {
+					continue
+				}
+				val = v
+			} else if fieldType.ConvertibleTo(schemas.TimeType) {
 				t := fieldValue.Convert(schemas.TimeType).Interface().(time.Time)
 				if !requiredField && (t.IsZero() || !fieldValue.IsValid()) {
 					continue
@@ -225,7 +260,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 				}
 			} else {
 				if !col.IsJSON {
-					table, err := statement.tagParser.ParseWithCache(fieldValue)
+					table, err := statement.ParseTable(fieldValue)
 					if err != nil {
 						val = fieldValue.Interface()
 					} else {
@@ -314,12 +349,8 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 }
 
 func (statement *Statement) writeUpdateTop(updateWriter *builder.BytesWriter) error {
-	if statement.dialect.URI().DBType != schemas.MSSQL || statement.LimitN == nil {
-		return nil
-	}
-
-	table := statement.RefTable
-	if statement.HasOrderBy() && table != nil && len(table.PrimaryKeys) == 1 {
+	// TOP can't be ordered, the ordered rows are limited by a subquery
+	if statement.dialect.URI().DBType != schemas.MSSQL || statement.LimitN == nil || statement.HasOrderBy() {
 		return nil
 	}
 
@@ -377,6 +408,9 @@ func (statement *Statement) writeUpdateFrom(updateWriter *builder.BytesWriter) (
 				return nil, err
 			}
 		}
+		if join.lateral {
+			return nil, errors.New("lateral join is not supported by update")
+		}
 		if err := statement.writeJoinTable(updateWriter, join); err != nil {
 			return nil, err
 		}
@@ -404,64 +438,27 @@ func (statement *Statement) writeUpdateLimit(updateWriter *builder.BytesWriter,
 	if statement.LimitN == nil {
 		return nil
 	}
+	if statement.Start > 0 {
+		return statement.unsupportedLimit("update", true)
+	}
+	if !statement.dialect.Features().UpdateLimit {
+		return statement.unsupportedLimit("update", false)
+	}
 
-	table := statement.RefTable
-	tableName := statement.TableName()
-
-	limitValue := *statement.LimitN
 	switch statement.dialect.URI().DBType {
 	case schemas.MYSQL:
-		_, err := fmt.Fprintf(updateWriter, " LIMIT %d", limitValue)
-		return err
-	case schemas.SQLITE:
-		if err := statement.writeWhereOrAnd(updateWriter, cond.IsValid()); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprint(updateWriter, "rowid IN (SELECT rowid FROM ", statement.quote(tableName)); err != nil {
-			return err
-		}
-		if err := statement.writeWhereCond(updateWriter, cond); err != nil {
-			return err
-		}
-		if err := statement.writeOrderBys(updateWriter); err != nil {
-			return err
-		}
-		_, err := fmt.Fprintf(updateWriter, " LIMIT %d)", limitValue)
-		return err
-	case schemas.POSTGRES:
-		if err := statement.writeWhereOrAnd(updateWriter, cond.IsValid()); err != nil {
-			return err
-		}
-		if _, err := fmt.Fprint(updateWriter, "CTID IN (SELECT CTID FROM ", statement.quote(tableName)); err != nil {
-			return err
-		}
-		if err := statement.writeWhereCond(updateWriter, cond); err != nil {
-			return err
-		}
-		if err := statement.writeOrderBys(updateWriter); err != nil {
-			return err
-		}
-		_, err := fmt.Fprintf(updateWriter, " LIMIT %d)", limitValue)
+		_, err := fmt.Fprintf(updateWriter, " LIMIT %d", *statement.LimitN)
 		return err
 	case schemas.MSSQL:
-		if statement.HasOrderBy() && table != nil && len(table.PrimaryKeys) == 1 {
-			if _, err := fmt.Fprintf(updateWriter, " WHERE %s IN (SELECT TOP (%d) %s FROM %v",
-				table.PrimaryKeys[0], limitValue, table.PrimaryKeys[0],
-				statement.quote(tableName)); err != nil {
-				return err
-			}
-			if err := statement.writeWhereCond(updateWriter, cond); err != nil {
-				return err
-			}
-			if err := statement.writeOrderBys(updateWriter); err != nil {
-				return err
-			}
-			_, err := fmt.Fprint(updateWriter, ")")
-			return err
+		if !statement.HasOrderBy() {
+			// the rows are limited by UPDATE TOP
+			return nil
 		}
-		return nil
-	default: // TODO: Oracle support needed
-		return fmt.Errorf("not implemented")
+		return statement.writeLimitSubquery(updateWriter, "update", cond)
+	case schemas.POSTGRES, schemas.SQLITE:
+		return statement.writeLimitSubquery(updateWriter, "update", cond)
+	default:
+		return statement.unsupportedLimit("update", false)
 	}
 }
 
@@ -572,6 +569,10 @@ func (statement *Statement) writeExprSets(w *builder.BytesWriter, hasPreviousSet
 			if _, err := fmt.Fprint(w, ")"); err != nil {
 				return err
 			}
+		case jsonSets:
+			if err := statement.writeJSONSets(w, expr.ColName, tp); err != nil {
+				return err
+			}
 		default:
 			if _, err := fmt.Fprint(w, statement.quote(expr.ColName), " = ?"); err != nil {
 				return err
@@ -712,13 +713,9 @@ func (statement *Statement) writeUpdateMSSQL(updateWriter *builder.BytesWriter,
 		return err
 	}
 
-	table := statement.RefTable
-	if statement.HasOrderBy() && table != nil && len(table.PrimaryKeys) == 1 {
-	} else {
-		// write where
-		if err := statement.writeWhereCond(updateWriter, cond.And(joinConds)); err != nil {
-			return err
-		}
+	// write where
+	if err := statement.writeWhereCond(updateWriter, cond.And(joinConds)); err != nil {
+		return err
 	}
 
 	return statement.writeUpdateLimit(updateWriter, cond.And(joinConds))
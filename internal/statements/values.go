@@ -9,11 +9,13 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"math/big"
+	"net/netip"
 	"reflect"
 	"time"
 
 	"github.com/imkos/xorm/convert"
 	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/internal/json"
 	"github.com/imkos/xorm/schemas"
 )
@@ -21,10 +23,125 @@ import (
 var (
 	nullFloatType = reflect.TypeOf(sql.NullFloat64{})
 	bigFloatType  = reflect.TypeOf(big.Float{})
+	geometryType  = reflect.TypeOf((*geo.Geometry)(nil)).Elem()
 )
 
+// asGeometry returns the geometry of the field value if its type implements geo.Geometry,
+// the geometry is nil if the field is a nil pointer.
+func asGeometry(fieldValue reflect.Value) (geo.Geometry, bool) {
+	fieldType := fieldValue.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		if !fieldType.Implements(geometryType) {
+			return nil, false
+		}
+		if fieldValue.IsNil() {
+			return nil, true
+		}
+		return fieldValue.Interface().(geo.Geometry), true
+	}
+	if !reflect.PtrTo(fieldType).Implements(geometryType) {
+		return nil, false
+	}
+	if fieldValue.CanAddr() {
+		return fieldValue.Addr().Interface().(geo.Geometry), true
+	}
+	v := reflect.New(fieldType)
+	v.Elem().Set(fieldValue)
+	return v.Interface().(geo.Geometry), true
+}
+
+// netipValue returns the string of netip.Addr or netip.Prefix, it's nil if the value is invalid
+func netipValue(fieldValue reflect.Value) (interface{}, bool) {
+	switch v := fieldValue.Interface().(type) {
+	case netip.Addr:
+		if !v.IsValid() {
+			return nil, true
+		}
+		return v.String(), true
+	case netip.Prefix:
+		if !v.IsValid() {
+			return nil, true
+		}
+		return v.String(), true
+	}
+	return nil, false
+}
+
+// durationValue converts the duration to the value of the column with duration tag, it's an
+// interval string for INTERVAL, a [-]HH:MM:SS.ffffff string for TIME and an integer of the unit
+// for the others
+func durationValue(col *schemas.Column, d time.Duration) interface{} {
+	switch col.SQLType.Name {
+	case schemas.Interval:
+		return fmt.Sprintf("%d microseconds", d.Microseconds())
+	case schemas.Time:
+		var sign string
+		if d < 0 {
+			sign = "-"
+			d = -d
+		}
+		return fmt.Sprintf("%s%02d:%02d:%02d.%06d", sign, d/time.Hour, d%time.Hour/time.Minute,
+			d%time.Minute/time.Second, d%time.Second/time.Microsecond)
+	}
+	unit := col.DurationUnit
+	if unit <= 0 {
+		unit = time.Nanosecond
+	}
+	return int64(d / unit)
+}
+
+// bitSetValue converts the convert.BitSet or []byte field of a BITSET or VARBIT column to a bit
+// string on PostgreSQL and an integer on the other databases, it's nil if the bit set is empty.
+// The number of the bits should be the length of the column.
+func (statement *Statement) bitSetValue(col *schemas.Column, fieldValue reflect.Value) (interface{}, bool, error) {
+	if col.SQLType.Name != schemas.BitSet && col.SQLType.Name != schemas.VarBit {
+		return nil, false, nil
+	}
+
+	var b convert.BitSet
+	switch v := fieldValue.Interface().(type) {
+	case convert.BitSet:
+		if v.Len() == 0 {
+			return nil, true, nil
+		}
+		b = v
+	case []byte:
+		if v == nil {
+			return nil, true, nil
+		}
+		var err error
+		if b, err = convert.BitSetFromBytes(v, int(col.Length)); err != nil {
+			return nil, true, fmt.Errorf("column %s: %w", col.Name, err)
+		}
+	default:
+		return nil, false, nil
+	}
+
+	if int64(b.Len()) != col.Length {
+		return nil, true, fmt.Errorf("column %s needs %d bits but got %d bits", col.Name, col.Length, b.Len())
+	}
+	dbType := statement.dialect.URI().DBType
+	if dbType == schemas.POSTGRES {
+		return b.String(), true, nil
+	}
+	if b.Len() > 64 {
+		return nil, true, fmt.Errorf("column %s could not store more than 64 bits on %s", col.Name, dbType)
+	}
+	if dbType == schemas.MYSQL {
+		return b.Uint64(), true, nil
+	}
+	return int64(b.Uint64()), true, nil
+}
+
 // Value2Interface convert a field value of a struct to interface for putting into database
 func (statement *Statement) Value2Interface(col *schemas.Column, fieldValue reflect.Value) (interface{}, error) {
+	if g, ok := asGeometry(fieldValue); ok {
+		if g == nil {
+			return nil, nil
+		}
+		return geo.Encode(statement.dialect.URI().DBType, g)
+	}
+
 	if fieldValue.CanAddr() {
 		if fieldConvert, ok := fieldValue.Addr().Interface().(convert.Conversion); ok {
 			data, err := fieldConvert.ToDB()
@@ -79,12 +196,22 @@ func (statement *Statement) Value2Interface(col *schemas.Column, fieldValue refl
 		}
 	}
 
+	if col.IsDuration && k == reflect.Int64 {
+		return durationValue(col, time.Duration(fieldValue.Int())), nil
+	}
+	if v, ok, err := statement.bitSetValue(col, fieldValue); ok {
+		return v, err
+	}
+
 	switch k {
 	case reflect.Bool:
 		return fieldValue.Bool(), nil
 	case reflect.String:
 		return fieldValue.String(), nil
 	case reflect.Struct:
+		if v, ok := netipValue(fieldValue); ok {
+			return v, nil
+		}
 		if fieldType.ConvertibleTo(schemas.TimeType) {
 			t := fieldValue.Convert(schemas.TimeType).Interface().(time.Time)
 			tf, err := dialects.FormatColumnTime(statement.dialect, statement.defaultTimeZone, col, t)
@@ -119,7 +246,7 @@ func (statement *Statement) Value2Interface(col *schemas.Column, fieldValue refl
 				return v.Value()
 			}
 
-			fieldTable, err := statement.tagParser.ParseWithCache(fieldValue)
+			fieldTable, err := statement.ParseTable(fieldValue)
 			if err != nil {
 				return nil, err
 			}
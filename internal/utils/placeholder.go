@@ -0,0 +1,266 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type placeholder struct {
+	start, end int
+	num        int    // the number of $N or :N, it's 0 for ? and the named placeholders
+	name       string // the name of @name or :name
+}
+
+func (p placeholder) isQuestionMark() bool {
+	return p.num == 0 && p.name == ""
+}
+
+func isIdentByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// dollarQuoteTag returns the tag of the dollar quoted string of postgres starting at query[i],
+// i.e. $$ or $body$, or an empty string if it's not a dollar quote
+func dollarQuoteTag(query string, i int) string {
+	for j := i + 1; j < len(query); j++ {
+		c := query[j]
+		if c == '$' {
+			return query[i : j+1]
+		}
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || (j > i+1 && isDigit(c))) {
+			return ""
+		}
+	}
+	return ""
+}
+
+// scanPlaceholders returns the number of the ? placeholders and all the placeholders, the
+// numbered ones and ?, or the named ones and ? if named is true. The strings, the quoted
+// identifiers and the comments are skipped.
+func scanPlaceholders(query string, named bool) (int, []placeholder) {
+	var (
+		questionMarks int
+		placeholders  []placeholder
+	)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			if end := strings.IndexByte(query[i+1:], c); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(query)
+			}
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+		case c == '?':
+			questionMarks++
+			placeholders = append(placeholders, placeholder{start: i, end: i + 1})
+		case c == '$' || c == ':' || c == '@':
+			if i > 0 && (isIdentByte(query[i-1]) || query[i-1] == ':' || query[i-1] == ']') {
+				continue
+			}
+			if c == '$' {
+				if tag := dollarQuoteTag(query, i); tag != "" {
+					if end := strings.Index(query[i+len(tag):], tag); end >= 0 {
+						i += len(tag) + end + len(tag) - 1
+					} else {
+						i = len(query)
+					}
+					continue
+				}
+			}
+			j := i + 1
+			for j < len(query) && (isIdentByte(query[j]) && query[j] != '$') {
+				j++
+			}
+			word := query[i+1 : j]
+			if word == "" {
+				continue
+			}
+			if named {
+				if c != '$' && !isDigit(word[0]) {
+					placeholders = append(placeholders, placeholder{start: i, end: j, name: word})
+				}
+			} else if c != '@' {
+				if num, err := strconv.Atoi(word); err == nil && num > 0 {
+					placeholders = append(placeholders, placeholder{start: i, end: j, num: num})
+				}
+			}
+			i = j - 1
+		}
+	}
+	return questionMarks, placeholders
+}
+
+// NormalizePlaceholders rewrites the numbered placeholders $N and :N, or the named placeholders
+// @name and :name if all the args are sql.NamedArg, to ? and reorders the args accordingly, so
+// that the SQL written for any database could be mixed with the SQL whose placeholders are ?
+// and the placeholders are converted for the database on execution. The args of the
+// placeholders used more than once are repeated. The SQL and the args are returned as they
+// are if the SQL has ? placeholders or no other placeholders, or if the numbered placeholders
+// don't start at 1 or exceed the args, i.e. they continue the numbering of the previous
+// fragments of the statement like Where("a = $1", a).And("b = $2", b).
+func NormalizePlaceholders(query string, args []interface{}) (string, []interface{}, error) {
+	if len(args) == 0 || !strings.ContainsAny(query, "$:@") {
+		return query, args, nil
+	}
+
+	named := true
+	for _, arg := range args {
+		if _, ok := arg.(sql.NamedArg); !ok {
+			named = false
+			break
+		}
+	}
+
+	questionMarks, placeholders := scanPlaceholders(query, named)
+	if questionMarks > 0 || len(placeholders) == 0 {
+		return query, args, nil
+	}
+	if !named {
+		minNum, maxNum := placeholders[0].num, placeholders[0].num
+		for _, p := range placeholders[1:] {
+			minNum = min(minNum, p.num)
+			maxNum = max(maxNum, p.num)
+		}
+		if minNum != 1 || maxNum > len(args) {
+			return query, args, nil
+		}
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(query))
+	newArgs := make([]interface{}, 0, len(placeholders))
+	var last int
+	for _, p := range placeholders {
+		if named {
+			var found bool
+			for _, arg := range args {
+				if namedArg := arg.(sql.NamedArg); namedArg.Name == p.name {
+					newArgs = append(newArgs, namedArg.Value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return "", nil, fmt.Errorf("no arg for the named placeholder %s", query[p.start:p.end])
+			}
+		} else {
+			newArgs = append(newArgs, args[p.num-1])
+		}
+		buf.WriteString(query[last:p.start])
+		buf.WriteByte('?')
+		last = p.end
+	}
+	buf.WriteString(query[last:])
+	return buf.String(), newArgs, nil
+}
+
+// ErrEmptySliceArg represents an error that an empty slice is given for IN (?)
+var ErrEmptySliceArg = errors.New("empty slice is given for IN (?)")
+
+// isSliceArg returns true if the arg is a slice or an array which should be expanded, the
+// []byte and the types implementing driver.Valuer are the values of single args
+func isSliceArg(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+	if _, ok := arg.(driver.Valuer); ok {
+		return false
+	}
+	t := reflect.TypeOf(arg)
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+// isInPlaceholder returns true if the placeholder is the only item of IN ( ), and so it
+// could be expanded to a list of placeholders
+func isInPlaceholder(query string, p placeholder) bool {
+	before := strings.TrimRight(query[:p.start], " \t\r\n")
+	if !strings.HasSuffix(before, "(") {
+		return false
+	}
+	before = strings.TrimRight(before[:len(before)-1], " \t\r\n")
+	if len(before) < 2 || !strings.EqualFold(before[len(before)-2:], "IN") ||
+		(len(before) > 2 && isIdentByte(before[len(before)-3])) {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimLeft(query[p.end:], " \t\r\n"), ")")
+}
+
+// ExpandSliceArgs expands the slice args of the ? placeholders of IN (?) to the placeholders
+// of their elements, i.e. IN (?) with []int64{1, 2, 3} is rewritten to IN (?,?,?) with the
+// args 1, 2 and 3. The slices of the other placeholders are kept as they are, so that they
+// could be passed as the arrays of postgres, i.e. = ANY(?).
+func ExpandSliceArgs(query string, args []interface{}) (string, []interface{}, error) {
+	var hasSlice bool
+	for _, arg := range args {
+		if isSliceArg(arg) {
+			hasSlice = true
+			break
+		}
+	}
+	if !hasSlice {
+		return query, args, nil
+	}
+
+	questionMarks, placeholders := scanPlaceholders(query, false)
+	if questionMarks != len(args) {
+		return query, args, nil
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(query))
+	newArgs := make([]interface{}, 0, len(args))
+	var last, i int
+	for _, p := range placeholders {
+		if !p.isQuestionMark() {
+			continue
+		}
+		arg := args[i]
+		i++
+		if !isSliceArg(arg) || !isInPlaceholder(query, p) {
+			newArgs = append(newArgs, arg)
+			continue
+		}
+
+		v := reflect.ValueOf(arg)
+		if v.Len() == 0 {
+			return "", nil, ErrEmptySliceArg
+		}
+		buf.WriteString(query[last:p.start])
+		for j := 0; j < v.Len(); j++ {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('?')
+			newArgs = append(newArgs, v.Index(j).Interface())
+		}
+		last = p.end
+	}
+	buf.WriteString(query[last:])
+	return buf.String(), newArgs, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePlaceholders(t *testing.T) {
+	kases := []struct {
+		query        string
+		args         []interface{}
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{"a = ? AND b = ?", []interface{}{1, 2}, "a = ? AND b = ?", []interface{}{1, 2}},
+		{"a = $1 AND b = $2", []interface{}{1, 2}, "a = ? AND b = ?", []interface{}{1, 2}},
+		{"a = $2 AND b = $1 AND c = $2", []interface{}{1, 2}, "a = ? AND b = ? AND c = ?", []interface{}{2, 1, 2}},
+		{"a = :1 OR b = :1", []interface{}{1}, "a = ? OR b = ?", []interface{}{1, 1}},
+		{"a::text = $1 AND b = '$2' AND \"c$1\" = $1 -- $2", []interface{}{1}, "a::text = ? AND b = '$2' AND \"c$1\" = ? -- $2", []interface{}{1, 1}},
+		{"a = $body$ $1 $body$ AND b = $1", []interface{}{1}, "a = $body$ $1 $body$ AND b = ?", []interface{}{1}},
+		{"a = $1 AND b = ?", []interface{}{1, 2}, "a = $1 AND b = ?", []interface{}{1, 2}},
+		{"a = @name AND b = :id AND c = @name", []interface{}{sql.Named("id", 1), sql.Named("name", "x")}, "a = ? AND b = ? AND c = ?", []interface{}{"x", 1, "x"}},
+		{"a = @name", []interface{}{1}, "a = @name", []interface{}{1}},
+		{"b = $2", []interface{}{2}, "b = $2", []interface{}{2}},
+		{"b = $2 OR c = $3", []interface{}{2, 3}, "b = $2 OR c = $3", []interface{}{2, 3}},
+		{"a = $1 AND b = $2", []interface{}{1}, "a = $1 AND b = $2", []interface{}{1}},
+	}
+
+	for _, kase := range kases {
+		t.Run(kase.query, func(t *testing.T) {
+			query, args, err := NormalizePlaceholders(kase.query, kase.args)
+			assert.NoError(t, err)
+			assert.EqualValues(t, kase.expectedSQL, query)
+			assert.EqualValues(t, kase.expectedArgs, args)
+		})
+	}
+
+	_, _, err := NormalizePlaceholders("a = :id", []interface{}{sql.Named("name", 1)})
+	assert.Error(t, err)
+}
+
+func TestExpandSliceArgs(t *testing.T) {
+	kases := []struct {
+		query        string
+		args         []interface{}
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{"a IN (?) AND b = ?", []interface{}{[]int64{1, 2, 3}, 4}, "a IN (?,?,?) AND b = ?", []interface{}{int64(1), int64(2), int64(3), 4}},
+		{"a NOT IN ( ? ) OR b in(?)", []interface{}{[2]string{"x", "y"}, []int{1}}, "a NOT IN ( ?,? ) OR b in(?)", []interface{}{"x", "y", 1}},
+		{"a = ANY(?)", []interface{}{[]int64{1, 2}}, "a = ANY(?)", []interface{}{[]int64{1, 2}}},
+		{"a IN (?)", []interface{}{[]byte("ab")}, "a IN (?)", []interface{}{[]byte("ab")}},
+		{"a IN (?) AND b = '?'", []interface{}{[]int{1, 2}}, "a IN (?,?) AND b = '?'", []interface{}{1, 2}},
+		{"a IN (?, ?)", []interface{}{[]int{1, 2}, 3}, "a IN (?, ?)", []interface{}{[]int{1, 2}, 3}},
+	}
+
+	for _, kase := range kases {
+		t.Run(kase.query, func(t *testing.T) {
+			query, args, err := ExpandSliceArgs(kase.query, kase.args)
+			assert.NoError(t, err)
+			assert.EqualValues(t, kase.expectedSQL, query)
+			assert.EqualValues(t, kase.expectedArgs, args)
+		})
+	}
+
+	_, _, err := ExpandSliceArgs("a IN (?)", []interface{}{[]int{}})
+	assert.ErrorIs(t, err, ErrEmptySliceArg)
+}
@@ -0,0 +1,378 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// SQLSplitter splits a stream of SQL script into statements. It's aware of string
+// literals, quoted identifiers, comments, BEGIN ... END blocks of CREATE statements
+// and custom delimiters, so the delimiters in them will not split the statement.
+type SQLSplitter struct {
+	// Delimiter ends a statement, default is ;
+	Delimiter string
+	// BackslashEscape enables the backslash escapes in string literals like MySQL
+	BackslashEscape bool
+	// DollarQuote enables the dollar quoted strings like Postgres
+	DollarQuote bool
+	// HashComment enables the comments begin with # like MySQL
+	HashComment bool
+	// DelimiterCommand enables the DELIMITER command of MySQL client to change the delimiter
+	DelimiterCommand bool
+	// BatchSeparator is a line which ends a statement like GO of SQL Server
+	BatchSeparator string
+
+	r        *bufio.Reader
+	line     int
+	stmtLine int
+}
+
+// NewSQLSplitter creates a SQLSplitter reads from r
+func NewSQLSplitter(r io.Reader) *SQLSplitter {
+	return &SQLSplitter{
+		Delimiter: ";",
+		r:         bufio.NewReader(r),
+		line:      1,
+	}
+}
+
+// Line returns the line number where the last statement returned by Next begins
+func (s *SQLSplitter) Line() int {
+	return s.stmtLine
+}
+
+func isSQLWordByte(b byte) bool {
+	return b == '_' || b >= 0x80 ||
+		(b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func isSQLSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v'
+}
+
+// stmtState represents the state of the statement being split
+type stmtState struct {
+	buf          strings.Builder
+	word         strings.Builder
+	firstWord    string
+	depth        int
+	pendingBegin bool
+	pendingEnd   bool
+}
+
+// endWord evaluates the word just read, the BEGIN ... END and CASE ... END blocks
+// of CREATE statements are counted to find the end of the triggers and procedures
+func (st *stmtState) endWord() {
+	if st.word.Len() == 0 {
+		return
+	}
+	w := strings.ToUpper(st.word.String())
+	st.word.Reset()
+	if st.firstWord == "" {
+		st.firstWord = w
+	}
+	if st.firstWord != "CREATE" {
+		return
+	}
+
+	if st.pendingBegin {
+		st.pendingBegin = false
+		switch w {
+		case "TRAN", "TRANSACTION", "WORK":
+			return
+		}
+		st.depth++
+	}
+	if st.pendingEnd {
+		st.pendingEnd = false
+		switch w {
+		case "IF", "LOOP", "WHILE", "REPEAT":
+			return
+		case "CASE":
+			st.depth--
+			return
+		}
+		st.depth--
+	}
+
+	switch w {
+	case "BEGIN":
+		st.pendingBegin = true
+	case "END":
+		st.pendingEnd = true
+	case "CASE":
+		st.depth++
+	}
+}
+
+// endPending resolves the pending BEGIN or END which is followed by a punctuation
+func (st *stmtState) endPending() {
+	st.pendingBegin = false
+	if st.pendingEnd {
+		st.pendingEnd = false
+		st.depth--
+	}
+}
+
+// peekLine returns the rest of the current line without consuming it
+func (s *SQLSplitter) peekLine() string {
+	for n := 64; ; n *= 2 {
+		if n > s.r.Size() {
+			n = s.r.Size()
+		}
+		p, err := s.r.Peek(n)
+		if i := strings.IndexByte(string(p), '\n'); i >= 0 {
+			return string(p[:i])
+		}
+		if err != nil || n == s.r.Size() {
+			return string(p)
+		}
+	}
+}
+
+// discardLine consumes the rest of the current line
+func (s *SQLSplitter) discardLine() error {
+	_, err := s.r.ReadString('\n')
+	s.line++
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// readQuoted reads until the quote char, the content will be written to buf
+func (s *SQLSplitter) readQuoted(buf *strings.Builder, quote byte, backslash bool) error {
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(c)
+		if c == '\n' {
+			s.line++
+		}
+		if backslash && c == '\\' {
+			next, err := s.r.ReadByte()
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(next)
+			if next == '\n' {
+				s.line++
+			}
+			continue
+		}
+		if c == quote {
+			return nil
+		}
+	}
+}
+
+// readUntil reads until the end string, the content will be written to buf if it's not nil
+func (s *SQLSplitter) readUntil(buf *strings.Builder, end string) error {
+	var tail []byte
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if buf != nil {
+			buf.WriteByte(c)
+		}
+		if c == '\n' {
+			s.line++
+		}
+		tail = append(tail, c)
+		if len(tail) > len(end) {
+			tail = tail[1:]
+		}
+		if string(tail) == end {
+			return nil
+		}
+	}
+}
+
+// dollarTag returns the tag like $tag$ or $$ at the beginning of the reader
+func (s *SQLSplitter) dollarTag() string {
+	for n := 1; n < 64; n++ {
+		p, err := s.r.Peek(n)
+		if len(p) < n {
+			return ""
+		}
+		c := p[n-1]
+		if c == '$' {
+			return "$" + string(p)
+		}
+		if !isSQLWordByte(c) || (n == 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+		if err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+// Next returns the next statement without the delimiter and the comments,
+// io.EOF will be returned when there are no more statements.
+func (s *SQLSplitter) Next() (string, error) {
+	var (
+		st        stmtState
+		lineStart = true
+		prev      byte
+	)
+	content := func() bool {
+		return st.buf.Len() > 0
+	}
+	write := func(b byte) {
+		if !content() {
+			s.stmtLine = s.line
+		}
+		st.buf.WriteByte(b)
+	}
+	result := func() string {
+		return strings.TrimSpace(st.buf.String())
+	}
+
+	for {
+		if lineStart && (s.DelimiterCommand || s.BatchSeparator != "") {
+			line := strings.TrimSpace(s.peekLine())
+			if s.DelimiterCommand && !content() && len(line) > 10 && strings.EqualFold(line[:10], "DELIMITER ") {
+				s.Delimiter = strings.TrimSpace(line[10:])
+				if err := s.discardLine(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if s.BatchSeparator != "" && strings.EqualFold(line, s.BatchSeparator) {
+				if err := s.discardLine(); err != nil {
+					return "", err
+				}
+				st.endWord()
+				if content() {
+					return result(), nil
+				}
+				continue
+			}
+		}
+
+		b, err := s.r.ReadByte()
+		if err == io.EOF {
+			st.endWord()
+			if content() {
+				return result(), nil
+			}
+			return "", io.EOF
+		} else if err != nil {
+			return "", err
+		}
+
+		if isSQLWordByte(b) {
+			st.word.WriteByte(b)
+			write(b)
+			lineStart = false
+			prev = b
+			continue
+		}
+		st.endWord()
+
+		if isSQLSpaceByte(b) {
+			if content() {
+				st.buf.WriteByte(b)
+			}
+			if b == '\n' {
+				s.line++
+				lineStart = true
+			}
+			prev = b
+			continue
+		}
+		lineStart = false
+		st.endPending()
+
+		if b == s.Delimiter[0] && (st.depth <= 0 || s.Delimiter != ";") {
+			p, _ := s.r.Peek(len(s.Delimiter) - 1)
+			if string(b)+string(p) == s.Delimiter {
+				if _, err := s.r.Discard(len(p)); err != nil {
+					return "", err
+				}
+				if content() {
+					return result(), nil
+				}
+				st = stmtState{}
+				prev = b
+				continue
+			}
+		}
+
+		switch {
+		case b == '-' && s.peekByte() == '-', b == '#' && s.HashComment:
+			if _, err := s.r.ReadString('\n'); err != nil && err != io.EOF {
+				return "", err
+			}
+			s.line++
+			lineStart = true
+			if content() {
+				st.buf.WriteByte('\n')
+			}
+		case b == '/' && s.peekByte() == '*':
+			p, _ := s.r.Peek(2)
+			// keep the optimizer hints and the MySQL specific comments
+			if len(p) == 2 && (p[1] == '+' || p[1] == '!') {
+				write(b)
+				if err := s.readUntil(&st.buf, "*/"); err != nil && err != io.EOF {
+					return "", err
+				}
+			} else {
+				if err := s.readUntil(nil, "*/"); err != nil && err != io.EOF {
+					return "", err
+				}
+				if content() {
+					st.buf.WriteByte(' ')
+				}
+			}
+		case b == '\'' || b == '"':
+			write(b)
+			if err := s.readQuoted(&st.buf, b, s.BackslashEscape); err != nil && err != io.EOF {
+				return "", err
+			}
+		case b == '`':
+			write(b)
+			if err := s.readQuoted(&st.buf, b, false); err != nil && err != io.EOF {
+				return "", err
+			}
+		case b == '[':
+			write(b)
+			if err := s.readQuoted(&st.buf, ']', false); err != nil && err != io.EOF {
+				return "", err
+			}
+		case b == '$' && s.DollarQuote && !isSQLWordByte(prev):
+			write(b)
+			if tag := s.dollarTag(); tag != "" {
+				if _, err := s.r.Discard(len(tag) - 1); err != nil {
+					return "", err
+				}
+				st.buf.WriteString(tag[1:])
+				if err := s.readUntil(&st.buf, tag); err != nil && err != io.EOF {
+					return "", err
+				}
+			}
+		default:
+			write(b)
+		}
+		prev = b
+	}
+}
+
+func (s *SQLSplitter) peekByte() byte {
+	p, err := s.r.Peek(1)
+	if err != nil {
+		return 0
+	}
+	return p[0]
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func splitSQL(t *testing.T, s *SQLSplitter) ([]string, []int) {
+	var stmts []string
+	var lines []int
+	for {
+		stmt, err := s.Next()
+		if err == io.EOF {
+			return stmts, lines
+		}
+		assert.NoError(t, err)
+		stmts = append(stmts, stmt)
+		lines = append(lines, s.Line())
+	}
+}
+
+func TestSQLSplitter(t *testing.T) {
+	script := `-- comment; with a semicolon
+CREATE TABLE a (id INT, name TEXT); /* block; comment */
+INSERT INTO a VALUES (1, 'a;b''c');
+INSERT INTO "a;" VALUES (2, "x;y");
+
+CREATE TRIGGER t AFTER INSERT ON a
+BEGIN
+	UPDATE a SET name = CASE WHEN id = 1 THEN 'x' ELSE 'y' END;
+	DELETE FROM a WHERE id = 0;
+END;
+BEGIN TRANSACTION;
+SELECT /*+ INDEX(a) */ [col;umn] FROM a`
+
+	stmts, lines := splitSQL(t, NewSQLSplitter(strings.NewReader(script)))
+	assert.EqualValues(t, []string{
+		"CREATE TABLE a (id INT, name TEXT)",
+		"INSERT INTO a VALUES (1, 'a;b''c')",
+		`INSERT INTO "a;" VALUES (2, "x;y")`,
+		`CREATE TRIGGER t AFTER INSERT ON a
+BEGIN
+	UPDATE a SET name = CASE WHEN id = 1 THEN 'x' ELSE 'y' END;
+	DELETE FROM a WHERE id = 0;
+END`,
+		"BEGIN TRANSACTION",
+		"SELECT /*+ INDEX(a) */ [col;umn] FROM a",
+	}, stmts)
+	assert.EqualValues(t, []int{2, 3, 4, 6, 11, 12}, lines)
+}
+
+func TestSQLSplitterMySQL(t *testing.T) {
+	script := `INSERT INTO a VALUES ('it\'s;'); # comment;
+DELIMITER //
+CREATE PROCEDURE p()
+BEGIN
+	IF 1 THEN SELECT 1; END IF;
+END//
+DELIMITER ;
+SELECT 1;`
+
+	s := NewSQLSplitter(strings.NewReader(script))
+	s.BackslashEscape = true
+	s.HashComment = true
+	s.DelimiterCommand = true
+	stmts, _ := splitSQL(t, s)
+	assert.EqualValues(t, []string{
+		`INSERT INTO a VALUES ('it\'s;')`,
+		`CREATE PROCEDURE p()
+BEGIN
+	IF 1 THEN SELECT 1; END IF;
+END`,
+		"SELECT 1",
+	}, stmts)
+
+	// the procedure could be split by BEGIN ... END without DELIMITER
+	s = NewSQLSplitter(strings.NewReader(`CREATE PROCEDURE p()
+BEGIN
+	WHILE 1 DO SELECT 1; END WHILE;
+	CASE WHEN 1 THEN SELECT 1; END CASE;
+END;
+SELECT 2;`))
+	stmts, _ = splitSQL(t, s)
+	assert.Len(t, stmts, 2)
+	assert.EqualValues(t, "SELECT 2", stmts[1])
+}
+
+func TestSQLSplitterPostgres(t *testing.T) {
+	script := `CREATE FUNCTION f() RETURNS trigger AS $body$
+BEGIN
+	RAISE NOTICE 'a;b';
+	RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+SELECT $1, $$a;b$$;`
+
+	s := NewSQLSplitter(strings.NewReader(script))
+	s.DollarQuote = true
+	stmts, _ := splitSQL(t, s)
+	assert.EqualValues(t, []string{
+		`CREATE FUNCTION f() RETURNS trigger AS $body$
+BEGIN
+	RAISE NOTICE 'a;b';
+	RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql`,
+		"SELECT $1, $$a;b$$",
+	}, stmts)
+}
+
+func TestSQLSplitterMSSQL(t *testing.T) {
+	script := `CREATE PROCEDURE p AS
+BEGIN TRY
+	SELECT 1;
+END TRY
+BEGIN CATCH
+	SELECT 2;
+END CATCH
+GO
+SELECT 3
+go
+`
+
+	s := NewSQLSplitter(strings.NewReader(script))
+	s.BatchSeparator = "GO"
+	stmts, _ := splitSQL(t, s)
+	assert.EqualValues(t, []string{
+		`CREATE PROCEDURE p AS
+BEGIN TRY
+	SELECT 1;
+END TRY
+BEGIN CATCH
+	SELECT 2;
+END CATCH`,
+		"SELECT 3",
+	}, stmts)
+}
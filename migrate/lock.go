@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"hash/crc32"
+	"time"
+
+	"github.com/imkos/xorm/schemas"
+)
+
+// lockName returns the name of the advisory lock
+func (m *Migrate) lockName() string {
+	if m.options.LockName != "" {
+		return m.options.LockName
+	}
+	return m.options.TableName
+}
+
+// withLock runs fn with the advisory lock acquired if UseLock is true. The lock is held
+// on a dedicated connection, so the connection pool should allow more than one connection.
+func (m *Migrate) withLock(fn func() error) error {
+	if !m.options.UseLock {
+		return fn()
+	}
+
+	ctx := context.Background()
+	conn, err := m.db.DB().Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	name := m.lockName()
+	switch m.db.Dialect().URI().DBType {
+	case schemas.POSTGRES:
+		key := int64(crc32.ChecksumIEEE([]byte(name)))
+		if err := lockPostgres(ctx, conn, key, m.options.LockTimeout); err != nil {
+			return err
+		}
+		defer func() { _, _ = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key) }()
+	case schemas.MYSQL:
+		timeout := -1
+		if m.options.LockTimeout > 0 {
+			timeout = int(m.options.LockTimeout / time.Second)
+		}
+		var res sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, timeout).Scan(&res); err != nil {
+			return err
+		}
+		if !res.Valid || res.Int64 != 1 {
+			return ErrLockTimeout
+		}
+		defer func() { _, _ = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name) }()
+	case schemas.MSSQL:
+		timeout := int64(-1)
+		if m.options.LockTimeout > 0 {
+			timeout = m.options.LockTimeout.Milliseconds()
+		}
+		var res int
+		if err := conn.QueryRowContext(ctx, "DECLARE @res INT; EXEC @res = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = @p2; SELECT @res",
+			name, timeout).Scan(&res); err != nil {
+			return err
+		}
+		if res < 0 {
+			return ErrLockTimeout
+		}
+		defer func() {
+			_, _ = conn.ExecContext(ctx, "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", name)
+		}()
+	}
+	return fn()
+}
+
+// lockPostgres acquires the advisory lock of PostgreSQL, it polls the lock if there is a timeout
+func lockPostgres(ctx context.Context, conn *sql.Conn, key int64, timeout time.Duration) error {
+	if timeout <= 0 {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key)
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
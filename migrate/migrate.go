@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/schemas"
@@ -16,15 +18,50 @@ type MigrateFunc func(*xorm.Engine) error
 // RollbackFunc is the func signature for rollbacking.
 type RollbackFunc func(*xorm.Engine) error
 
+// MigrateTxFunc is the func signature for migrating in a transaction.
+type MigrateTxFunc func(*xorm.Session) error
+
+// RollbackTxFunc is the func signature for rollbacking in a transaction.
+type RollbackTxFunc func(*xorm.Session) error
+
 // InitSchemaFunc is the func signature for initializing the schemas.
 type InitSchemaFunc func(*xorm.Engine) error
 
+// TransactionMode decides how the migrations are wrapped in transactions
+type TransactionMode int
+
+// enumerates all the transaction modes
+const (
+	// NoTransaction runs the migrations without transaction
+	NoTransaction TransactionMode = iota
+	// TransactionPerMigration wraps every migration and its record in a transaction
+	TransactionPerMigration
+	// TransactionAll wraps all the migrations of one run in a transaction
+	TransactionAll
+)
+
 // Options define options for all migrations.
 type Options struct {
 	// TableName is the migration table.
 	TableName string
 	// IDColumnName is the name of column where the migration id will be stored.
 	IDColumnName string
+	// ChecksumColumnName is the name of column where the migration checksum will be stored.
+	ChecksumColumnName string
+	// AppliedAtColumnName is the name of column where the time of the migration executed will be stored.
+	AppliedAtColumnName string
+	// TransactionMode decides how the migrations are wrapped in transactions. It's ignored
+	// on the databases which don't support transactional DDL like MySQL. Only MigrateTx
+	// and RollbackTx could be executed in the transactions, ErrTxFuncRequired is returned
+	// before running anything if a pending migration has only Migrate or Rollback.
+	TransactionMode TransactionMode
+	// UseLock acquires an advisory lock while migrating so that the concurrent instances
+	// of the application will not race. It works on PostgreSQL, MySQL and SQL Server.
+	UseLock bool
+	// LockName is the name of the advisory lock, default is the migration table name.
+	LockName string
+	// LockTimeout is the max time to wait for the lock, 0 means waiting forever.
+	LockTimeout time.Duration
 }
 
 // Migration represents a database migration (a modification to be made on the database).
@@ -35,6 +72,14 @@ type Migration struct {
 	Migrate MigrateFunc
 	// Rollback will be executed on rollback. Can be nil.
 	Rollback RollbackFunc
+	// MigrateTx will be executed instead of Migrate if it's not nil, it's in a transaction
+	// if TransactionMode is not NoTransaction.
+	MigrateTx MigrateTxFunc
+	// RollbackTx will be executed instead of Rollback if it's not nil.
+	RollbackTx RollbackTxFunc
+	// Checksum is recorded when the migration is executed, Migrate returns ErrChecksumMismatch
+	// if it has been changed since then. It could be empty to skip the check.
+	Checksum string
 }
 
 // Migrate represents a collection of all migrations of a database schemas.
@@ -48,8 +93,10 @@ type Migrate struct {
 var (
 	// DefaultOptions can be used if you don't want to think about options.
 	DefaultOptions = &Options{
-		TableName:    "migrations",
-		IDColumnName: "id",
+		TableName:           "migrations",
+		IDColumnName:        "id",
+		ChecksumColumnName:  "checksum",
+		AppliedAtColumnName: "applied_at",
 	}
 
 	// ErrRollbackImpossible is returned when trying to rollback a migration
@@ -65,13 +112,34 @@ var (
 	// ErrNoRunnedMigration is returned when any runned migration was found while
 	// running RollbackLast
 	ErrNoRunnedMigration = errors.New("Could not find last runned migration")
+
+	// ErrChecksumMismatch is returned when the checksum of a runned migration has been changed
+	ErrChecksumMismatch = errors.New("Checksum of the runned migration has been changed")
+
+	// ErrLockTimeout is returned when the migration lock could not be acquired in time
+	ErrLockTimeout = errors.New("Acquire migration lock timeout")
+
+	// ErrMigrationNotFound is returned when the migration of the ID is not defined
+	ErrMigrationNotFound = errors.New("Could not find the migration")
+
+	// ErrTxFuncRequired is returned when a migration without MigrateTx or RollbackTx is run in
+	// a transaction mode, since Migrate and Rollback take the engine which is not in the
+	// transaction, they are neither atomic nor safe on sqlite locked by the transaction
+	ErrTxFuncRequired = errors.New("MigrateTx or RollbackTx is required in the transaction mode")
 )
 
 // New returns a new Gormigrate.
 func New(db *xorm.Engine, options *Options, migrations []*Migration) *Migrate {
+	opts := *options
+	if opts.ChecksumColumnName == "" {
+		opts.ChecksumColumnName = DefaultOptions.ChecksumColumnName
+	}
+	if opts.AppliedAtColumnName == "" {
+		opts.AppliedAtColumnName = DefaultOptions.AppliedAtColumnName
+	}
 	return &Migrate{
 		db:         db,
-		options:    options,
+		options:    &opts,
 		migrations: migrations,
 	}
 }
@@ -86,24 +154,79 @@ func (m *Migrate) InitSchema(initSchema InitSchemaFunc) {
 
 // Migrate executes all migrations that did not run yet.
 func (m *Migrate) Migrate() error {
-	if err := m.createMigrationTableIfNotExists(); err != nil {
-		return err
-	}
+	return m.withLock(func() error {
+		if err := m.createMigrationTableIfNotExists(); err != nil {
+			return err
+		}
 
-	isFirstRun, err := m.isFirstRun()
-	if err != nil {
-		return err
-	}
-	if m.initSchema != nil && isFirstRun {
-		return m.runInitSchema()
+		isFirstRun, err := m.isFirstRun()
+		if err != nil {
+			return err
+		}
+		if m.initSchema != nil && isFirstRun {
+			return m.runInitSchema()
+		}
+
+		if err := m.checkChecksums(); err != nil {
+			return err
+		}
+		return m.runMigrations(m.migrations)
+	})
+}
+
+// MigrateTo executes the migrations until the migration of id in order, the
+// migrations after it will not be executed. InitSchema is not used by MigrateTo.
+func (m *Migrate) MigrateTo(id string) error {
+	idx := m.migrationIndex(id)
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, id)
 	}
 
-	for _, migration := range m.migrations {
-		if err := m.runMigration(migration); err != nil {
+	return m.withLock(func() error {
+		if err := m.createMigrationTableIfNotExists(); err != nil {
 			return err
 		}
+		if err := m.checkChecksums(); err != nil {
+			return err
+		}
+		return m.runMigrations(m.migrations[:idx+1])
+	})
+}
+
+// RollbackTo undo the runned migrations after the migration of id in reverse
+// order, the migration of id itself will not be rollbacked.
+func (m *Migrate) RollbackTo(id string) error {
+	idx := m.migrationIndex(id)
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, id)
 	}
-	return nil
+
+	return m.withLock(func() error {
+		for i := len(m.migrations) - 1; i > idx; i-- {
+			migration := m.migrations[i]
+			run, err := m.migrationDidRun(m.db, migration)
+			if err != nil {
+				return err
+			}
+			if !run {
+				continue
+			}
+			if err := m.rollbackMigration(migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrationIndex returns the index of the migration of id, or -1 if it's not found
+func (m *Migrate) migrationIndex(id string) int {
+	for i, migration := range m.migrations {
+		if migration.ID == id {
+			return i
+		}
+	}
+	return -1
 }
 
 // RollbackLast undo the last migration
@@ -112,18 +235,20 @@ func (m *Migrate) RollbackLast() error {
 		return ErrNoMigrationDefined
 	}
 
-	lastRunnedMigration, err := m.getLastRunnedMigration()
-	if err != nil {
-		return err
-	}
+	return m.withLock(func() error {
+		lastRunnedMigration, err := m.getLastRunnedMigration()
+		if err != nil {
+			return err
+		}
 
-	return m.RollbackMigration(lastRunnedMigration)
+		return m.rollbackMigration(lastRunnedMigration)
+	})
 }
 
 func (m *Migrate) getLastRunnedMigration() (*Migration, error) {
 	for i := len(m.migrations) - 1; i >= 0; i-- {
 		migration := m.migrations[i]
-		run, err := m.migrationDidRun(migration)
+		run, err := m.migrationDidRun(m.db, migration)
 		if err != nil {
 			return nil, err
 		} else if run {
@@ -135,20 +260,103 @@ func (m *Migrate) getLastRunnedMigration() (*Migration, error) {
 
 // RollbackMigration undo a migration.
 func (m *Migrate) RollbackMigration(mig *Migration) error {
-	if mig.Rollback == nil {
+	return m.withLock(func() error {
+		return m.rollbackMigration(mig)
+	})
+}
+
+func (m *Migrate) rollbackMigration(mig *Migration) error {
+	if mig.Rollback == nil && mig.RollbackTx == nil {
 		return ErrRollbackImpossible
 	}
+	useTx := m.useTransaction()
+	if useTx && mig.RollbackTx == nil {
+		return fmt.Errorf("%w: %s", ErrTxFuncRequired, mig.ID)
+	}
 
-	if err := mig.Rollback(m.db); err != nil {
-		return err
+	return m.inSession(useTx, func(session *xorm.Session) error {
+		if mig.RollbackTx != nil {
+			if err := mig.RollbackTx(session); err != nil {
+				return err
+			}
+		} else if err := mig.Rollback(m.db); err != nil {
+			return err
+		}
+
+		tableName := m.db.TableName(m.options.TableName, true)
+
+		sql := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", tableName, m.options.IDColumnName)
+		if _, err := session.Exec(sql, mig.ID); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// useTransaction returns true if the migrations should be wrapped in transactions
+func (m *Migrate) useTransaction() bool {
+	if m.options.TransactionMode == NoTransaction {
+		return false
+	}
+	switch m.db.Dialect().URI().DBType {
+	case schemas.POSTGRES, schemas.SQLITE, schemas.MSSQL:
+		return true
 	}
+	return false
+}
 
-	tableName := m.db.TableName(m.options.TableName, true)
+// inSession runs fn with a new session which is in a transaction if useTx is true
+func (m *Migrate) inSession(useTx bool, fn func(*xorm.Session) error) error {
+	session := m.db.NewSession()
+	defer session.Close()
 
-	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", tableName, m.options.IDColumnName)
-	if _, err := m.db.Exec(sql, mig.ID); err != nil {
+	if !useTx {
+		return fn(session)
+	}
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if err := fn(session); err != nil {
+		_ = session.Rollback()
 		return err
 	}
+	return session.Commit()
+}
+
+func (m *Migrate) runMigrations(migrations []*Migration) error {
+	useTx := m.useTransaction()
+	if useTx {
+		for _, migration := range migrations {
+			if migration.MigrateTx != nil {
+				continue
+			}
+			run, err := m.migrationDidRun(m.db, migration)
+			if err != nil {
+				return err
+			}
+			if !run {
+				return fmt.Errorf("%w: %s", ErrTxFuncRequired, migration.ID)
+			}
+		}
+	}
+	if useTx && m.options.TransactionMode == TransactionAll {
+		return m.inSession(true, func(session *xorm.Session) error {
+			for _, migration := range migrations {
+				if err := m.runMigration(session, migration); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, migration := range migrations {
+		if err := m.inSession(useTx, func(session *xorm.Session) error {
+			return m.runMigration(session, migration)
+		}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -158,7 +366,7 @@ func (m *Migrate) runInitSchema() error {
 	}
 
 	for _, migration := range m.migrations {
-		if err := m.insertMigration(migration.ID); err != nil {
+		if err := m.insertMigration(m.db, migration); err != nil {
 			return err
 		}
 	}
@@ -166,44 +374,64 @@ func (m *Migrate) runInitSchema() error {
 	return nil
 }
 
-func (m *Migrate) runMigration(migration *Migration) error {
+func (m *Migrate) runMigration(session *xorm.Session, migration *Migration) error {
 	if len(migration.ID) == 0 {
 		return ErrMissingID
 	}
 
-	run, err := m.migrationDidRun(migration)
+	run, err := m.migrationDidRun(session, migration)
 	if err != nil {
 		return err
 	}
 
 	if !run {
-		if err := migration.Migrate(m.db); err != nil {
+		if migration.MigrateTx != nil {
+			if err := migration.MigrateTx(session); err != nil {
+				return err
+			}
+		} else if err := migration.Migrate(m.db); err != nil {
 			return err
 		}
 
-		if err := m.insertMigration(migration.ID); err != nil {
+		if err := m.insertMigration(session, migration); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (m *Migrate) createMigrationTableIfNotExists() error {
-	exists, err := m.db.IsTableExist(m.options.TableName)
-	if err != nil {
-		return err
-	}
-	if exists {
-		return nil
-	}
-
+// migrationTable returns the schema of the migration table
+func (m *Migrate) migrationTable() *schemas.Table {
 	idCol := schemas.NewColumn(m.options.IDColumnName, "", schemas.SQLType{
 		Name: "VARCHAR",
 	}, 255, 0, false)
 	idCol.IsPrimaryKey = true
 
+	checksumCol := schemas.NewColumn(m.options.ChecksumColumnName, "", schemas.SQLType{
+		Name: "VARCHAR",
+	}, 64, 0, true)
+
+	appliedAtCol := schemas.NewColumn(m.options.AppliedAtColumnName, "", schemas.SQLType{
+		Name: schemas.DateTime,
+	}, 0, 0, true)
+
 	table := schemas.NewTable(m.options.TableName, reflect.TypeOf(new(schemas.Table)))
 	table.AddColumn(idCol)
+	table.AddColumn(checksumCol)
+	table.AddColumn(appliedAtCol)
+	return table
+}
+
+func (m *Migrate) createMigrationTableIfNotExists() error {
+	table := m.migrationTable()
+
+	exists, err := m.db.IsTableExist(m.options.TableName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return m.addMissingColumns(table)
+	}
 
 	sql, _, err := m.db.Dialect().CreateTableSQL(context.Background(), m.db.DB(), table, m.options.TableName)
 	if err != nil {
@@ -216,9 +444,53 @@ func (m *Migrate) createMigrationTableIfNotExists() error {
 	return nil
 }
 
-func (m *Migrate) migrationDidRun(mig *Migration) (bool, error) {
+// addMissingColumns adds the columns to the migration table created by the old versions
+func (m *Migrate) addMissingColumns(table *schemas.Table) error {
+	colNames, _, err := m.db.Dialect().GetColumns(m.db.DB(), context.Background(), m.options.TableName)
+	if err != nil {
+		return err
+	}
+	for _, col := range table.Columns() {
+		var found bool
+		for _, name := range colNames {
+			if strings.EqualFold(name, col.Name) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if _, err := m.db.Exec(m.db.Dialect().AddColumnSQL(m.options.TableName, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkChecksums returns ErrChecksumMismatch if a runned migration has been changed
+func (m *Migrate) checkChecksums() error {
+	tableName := m.db.TableName(m.options.TableName, true)
+	records, err := m.db.SQL(fmt.Sprintf("SELECT %s, %s FROM %s", m.options.IDColumnName, m.options.ChecksumColumnName, tableName)).QueryString()
+	if err != nil {
+		return err
+	}
+	checksums := make(map[string]string, len(records))
+	for _, record := range records {
+		checksums[record[m.options.IDColumnName]] = record[m.options.ChecksumColumnName]
+	}
+	for _, migration := range m.migrations {
+		checksum := checksums[migration.ID]
+		if migration.Checksum != "" && checksum != "" && checksum != migration.Checksum {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, migration.ID)
+		}
+	}
+	return nil
+}
+
+func (m *Migrate) migrationDidRun(db xorm.Interface, mig *Migration) (bool, error) {
 	tableName := m.db.TableName(m.options.TableName, true)
-	count, err := m.db.SQL(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ?", tableName, m.options.IDColumnName), mig.ID).Count()
+	count, err := db.SQL(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ?", tableName, m.options.IDColumnName), mig.ID).Count()
 	return count > 0, err
 }
 
@@ -229,9 +501,10 @@ func (m *Migrate) isFirstRun() (bool, error) {
 	return count == 0, err
 }
 
-func (m *Migrate) insertMigration(id string) error {
+func (m *Migrate) insertMigration(db xorm.Interface, mig *Migration) error {
 	tableName := m.db.TableName(m.options.TableName, true)
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?)", tableName, m.options.IDColumnName)
-	_, err := m.db.Exec(sql, id)
+	sql := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?)", tableName,
+		m.options.IDColumnName, m.options.ChecksumColumnName, m.options.AppliedAtColumnName)
+	_, err := db.Exec(sql, mig.ID, mig.Checksum, time.Now())
 	return err
 }
@@ -1,10 +1,14 @@
 package migrate
 
 import (
+	"embed"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -142,3 +146,218 @@ func tableCount(db *xorm.Engine, tableName string) (count int) {
 	_, _ = db.SQL(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Get(&count)
 	return
 }
+
+func TestMigrationTransaction(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	options := *DefaultOptions
+	options.TransactionMode = TransactionAll
+	options.UseLock = true
+
+	failed := errors.New("failed")
+	m := New(db, &options, []*Migration{
+		{
+			ID: "201608301400",
+			MigrateTx: func(tx *xorm.Session) error {
+				return tx.Sync(&Person{})
+			},
+		},
+		{
+			ID: "201608301430",
+			MigrateTx: func(tx *xorm.Session) error {
+				if err := tx.Sync(&Pet{}); err != nil {
+					return err
+				}
+				return failed
+			},
+		},
+	})
+
+	// all the migrations are rolled back
+	assert.ErrorIs(t, m.Migrate(), failed)
+	exists, _ := db.IsTableExist(&Person{})
+	assert.False(t, exists)
+	exists, _ = db.IsTableExist(&Pet{})
+	assert.False(t, exists)
+	assert.Equal(t, 0, tableCount(db, "migrations"))
+
+	// only the failed migration is rolled back
+	m.options.TransactionMode = TransactionPerMigration
+	assert.ErrorIs(t, m.Migrate(), failed)
+	exists, _ = db.IsTableExist(&Person{})
+	assert.True(t, exists)
+	exists, _ = db.IsTableExist(&Pet{})
+	assert.False(t, exists)
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+
+	// the migrations taking the engine are refused before running anything
+	var migrated bool
+	m = New(db, &options, []*Migration{
+		{
+			ID: "201608301400",
+			MigrateTx: func(tx *xorm.Session) error {
+				return tx.Sync(&Person{})
+			},
+		},
+		{
+			ID: "201608301500",
+			Migrate: func(tx *xorm.Engine) error {
+				migrated = true
+				return nil
+			},
+		},
+	})
+	assert.ErrorIs(t, m.Migrate(), ErrTxFuncRequired)
+	assert.False(t, migrated)
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+}
+
+func TestMigrationChecksum(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// the migration table created by the old versions has no checksum column
+	_, err = db.Exec("CREATE TABLE migrations (id VARCHAR(255) PRIMARY KEY NOT NULL)")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO migrations (id) VALUES ('201608301400')")
+	assert.NoError(t, err)
+
+	checksumMigrations := []*Migration{
+		{
+			ID:       "201608301400",
+			Checksum: "a",
+			Migrate: func(tx *xorm.Engine) error {
+				return tx.Sync(&Person{})
+			},
+		},
+		{
+			ID:       "201608301430",
+			Checksum: "b",
+			Migrate: func(tx *xorm.Engine) error {
+				return tx.Sync(&Pet{})
+			},
+		},
+	}
+
+	m := New(db, DefaultOptions, checksumMigrations)
+	assert.NoError(t, m.Migrate())
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+
+	var checksum string
+	has, err := db.SQL("SELECT checksum FROM migrations WHERE id = ?", "201608301430").Get(&checksum)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "b", checksum)
+
+	checksumMigrations[1].Checksum = "c"
+	assert.ErrorIs(t, m.Migrate(), ErrChecksumMismatch)
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Changed)
+	// the migration recorded by the old version has no applied time
+	assert.Nil(t, statuses[0].AppliedAt)
+	assert.True(t, statuses[1].Changed)
+}
+
+func TestMigrateToAndRollbackTo(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	m := New(db, DefaultOptions, migrations)
+	assert.ErrorIs(t, m.MigrateTo("not_exist"), ErrMigrationNotFound)
+	assert.ErrorIs(t, m.RollbackTo("not_exist"), ErrMigrationNotFound)
+
+	assert.NoError(t, m.MigrateTo("201608301400"))
+	exists, _ := db.IsTableExist(&Person{})
+	assert.True(t, exists)
+	exists, _ = db.IsTableExist(&Pet{})
+	assert.False(t, exists)
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.EqualValues(t, "201608301400", statuses[0].ID)
+	assert.True(t, statuses[0].Applied)
+	if assert.NotNil(t, statuses[0].AppliedAt) {
+		assert.WithinDuration(t, time.Now(), *statuses[0].AppliedAt, time.Minute)
+	}
+	assert.EqualValues(t, "201608301430", statuses[1].ID)
+	assert.False(t, statuses[1].Applied)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	assert.NoError(t, m.MigrateTo("201608301430"))
+	exists, _ = db.IsTableExist(&Pet{})
+	assert.True(t, exists)
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+
+	assert.NoError(t, m.RollbackTo("201608301400"))
+	exists, _ = db.IsTableExist(&Person{})
+	assert.True(t, exists)
+	exists, _ = db.IsTableExist(&Pet{})
+	assert.False(t, exists)
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+
+	// the migrations recorded but not defined are reported as missing
+	m = New(db, DefaultOptions, migrations[1:])
+	statuses, err = m.Status()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.EqualValues(t, "201608301430", statuses[0].ID)
+	assert.False(t, statuses[0].Applied)
+	assert.EqualValues(t, "201608301400", statuses[1].ID)
+	assert.True(t, statuses[1].Applied)
+	assert.True(t, statuses[1].Missing)
+}
+
+//go:embed testdata/*.sql
+var sqlMigrations embed.FS
+
+func TestSQLMigrations(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	migrations, err := LoadSQLMigrations(sqlMigrations, "testdata")
+	assert.NoError(t, err)
+	if !assert.Len(t, migrations, 2) {
+		return
+	}
+	assert.EqualValues(t, "201608301400_create_person", migrations[0].ID)
+	assert.EqualValues(t, "201608301430_create_pet", migrations[1].ID)
+	assert.Len(t, migrations[0].Checksum, 64)
+
+	options := *DefaultOptions
+	options.TransactionMode = TransactionPerMigration
+	m := New(db, &options, migrations)
+	assert.NoError(t, m.Migrate())
+	exists, _ := db.IsTableExist("person")
+	assert.True(t, exists)
+	exists, _ = db.IsTableExist("pet")
+	assert.True(t, exists)
+	assert.Equal(t, 1, tableCount(db, "person"))
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+
+	assert.NoError(t, m.RollbackLast())
+	exists, _ = db.IsTableExist("pet")
+	assert.False(t, exists)
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+
+	_, err = LoadSQLMigrations(fstest.MapFS{
+		"sql/1_a.down.sql": &fstest.MapFile{Data: []byte("SELECT 1;")},
+	}, "sql")
+	assert.Error(t, err)
+}
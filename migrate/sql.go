@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/imkos/xorm"
+)
+
+const (
+	sqlUpSuffix   = ".up.sql"
+	sqlDownSuffix = ".down.sql"
+)
+
+// LoadSQLMigrations loads the migrations from the SQL files in dir of fsys which could be
+// an embed.FS. The migrations are declared by the file pairs like 201608301400_add_user.up.sql
+// and 201608301400_add_user.down.sql, the file name without the suffix is the migration ID,
+// and the migrations are sorted by ID. The down file is optional. The statements in the files
+// are split with the syntax of the database and executed by MigrateTx and RollbackTx, the
+// checksum of the up file is recorded to detect the changes.
+func LoadSQLMigrations(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ups := make(map[string][]byte)
+	downs := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		var (
+			id      string
+			scripts map[string][]byte
+		)
+		switch {
+		case strings.HasSuffix(name, sqlUpSuffix):
+			id, scripts = strings.TrimSuffix(name, sqlUpSuffix), ups
+		case strings.HasSuffix(name, sqlDownSuffix):
+			id, scripts = strings.TrimSuffix(name, sqlDownSuffix), downs
+		default:
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		scripts[id] = content
+	}
+
+	migrations := make([]*Migration, 0, len(ups))
+	for id, up := range ups {
+		migrations = append(migrations, newSQLMigration(id, up, downs[id]))
+	}
+	for id := range downs {
+		if _, ok := ups[id]; !ok {
+			return nil, fmt.Errorf("migration %s has no up file", id)
+		}
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ID < migrations[j].ID
+	})
+	return migrations, nil
+}
+
+func newSQLMigration(id string, up, down []byte) *Migration {
+	checksum := sha256.Sum256(up)
+	migration := &Migration{
+		ID:       id,
+		Checksum: hex.EncodeToString(checksum[:]),
+		MigrateTx: func(tx *xorm.Session) error {
+			_, err := tx.ImportStream(bytes.NewReader(up), xorm.ImportOptions{})
+			return err
+		},
+	}
+	if down != nil {
+		migration.RollbackTx = func(tx *xorm.Session) error {
+			_, err := tx.ImportStream(bytes.NewReader(down), xorm.ImportOptions{})
+			return err
+		}
+	}
+	return migration
+}
@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/imkos/xorm/convert"
+)
+
+// MigrationStatus represents the status of a migration
+type MigrationStatus struct {
+	// ID is the migration identifier
+	ID string
+	// Applied is true if the migration has been executed
+	Applied bool
+	// AppliedAt is the time of the migration executed, it's nil if the migration has not
+	// been executed or it was recorded by the old versions without the time.
+	AppliedAt *time.Time
+	// Changed is true if the checksum of the executed migration has been changed
+	Changed bool
+	// Missing is true if the migration is recorded in the database but not defined
+	Missing bool
+}
+
+// Status returns the status of the defined migrations in order, followed by the
+// migrations recorded in the database but not defined any more.
+func (m *Migrate) Status() ([]MigrationStatus, error) {
+	if err := m.createMigrationTableIfNotExists(); err != nil {
+		return nil, err
+	}
+
+	tableName := m.db.TableName(m.options.TableName, true)
+	records, err := m.db.SQL(fmt.Sprintf("SELECT %s, %s, %s FROM %s ORDER BY %s", m.options.IDColumnName,
+		m.options.ChecksumColumnName, m.options.AppliedAtColumnName, tableName, m.options.IDColumnName)).QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make(map[string]map[string]string, len(records))
+	for _, record := range records {
+		recorded[record[m.options.IDColumnName]] = record
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	defined := make(map[string]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		defined[migration.ID] = true
+		status := MigrationStatus{ID: migration.ID}
+		if record, ok := recorded[migration.ID]; ok {
+			checksum := record[m.options.ChecksumColumnName]
+			status.Applied = true
+			status.Changed = migration.Checksum != "" && checksum != "" && checksum != migration.Checksum
+			if status.AppliedAt, err = m.parseAppliedAt(record); err != nil {
+				return nil, err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range records {
+		id := record[m.options.IDColumnName]
+		if defined[id] {
+			continue
+		}
+		status := MigrationStatus{ID: id, Applied: true, Missing: true}
+		if status.AppliedAt, err = m.parseAppliedAt(record); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrate) parseAppliedAt(record map[string]string) (*time.Time, error) {
+	appliedAt := record[m.options.AppliedAtColumnName]
+	if appliedAt == "" {
+		return nil, nil
+	}
+	return convert.String2Time(appliedAt, m.db.DatabaseTZ, m.db.TZLocation)
+}
@@ -0,0 +1,3 @@
+-- the persons; the pets
+CREATE TABLE person (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);
+INSERT INTO person (name) VALUES ('a;b');
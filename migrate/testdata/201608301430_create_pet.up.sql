@@ -0,0 +1,2 @@
+CREATE TABLE pet (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, person_id INTEGER);
+CREATE INDEX idx_pet_person_id ON pet (person_id);
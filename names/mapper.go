@@ -32,6 +32,11 @@ func NewCacheMapper(mapper Mapper) *CacheMapper {
 	}
 }
 
+// OriginalMapper returns the mapper which is cached
+func (m *CacheMapper) OriginalMapper() Mapper {
+	return m.oriMapper
+}
+
 // Obj2Table implements Mapper
 func (m *CacheMapper) Obj2Table(o string) string {
 	m.obj2tableMutex.RLock()
@@ -7,6 +7,8 @@ package names
 import (
 	"reflect"
 	"sync"
+
+	"github.com/imkos/xorm/schemas"
 )
 
 // TableName table name interface to define customerize table name
@@ -14,12 +16,20 @@ type TableName interface {
 	TableName() string
 }
 
+// TableNameFor could be implemented to use different table names on the databases, i.e. a
+// schema qualified name on postgres but a plain one on sqlite. An empty name means the
+// TableName or the mapped name should be used.
+type TableNameFor interface {
+	TableNameFor(dbType schemas.DBType) string
+}
+
 type TableComment interface {
 	TableComment() string
 }
 
 var (
 	tpTableName    = reflect.TypeOf((*TableName)(nil)).Elem()
+	tpTableNameFor = reflect.TypeOf((*TableNameFor)(nil)).Elem()
 	tpTableComment = reflect.TypeOf((*TableComment)(nil)).Elem()
 	tvCache        sync.Map
 	tcCache        sync.Map
@@ -62,6 +72,38 @@ func GetTableName(mapper Mapper, v reflect.Value) string {
 	return mapper.Obj2Table(v.Type().Name())
 }
 
+// GetTableNameFor returns table name on the database, the TableNameFor of the bean takes
+// precedence over the TableName
+func GetTableNameFor(mapper Mapper, v reflect.Value, dbType schemas.DBType) string {
+	if tableNameFor := getTableNameFor(v); tableNameFor != nil {
+		if name := tableNameFor.TableNameFor(dbType); name != "" {
+			return name
+		}
+	}
+	return GetTableName(mapper, v)
+}
+
+func getTableNameFor(v reflect.Value) TableNameFor {
+	if v.Type().Implements(tpTableNameFor) {
+		return v.Interface().(TableNameFor)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpTableNameFor) {
+			return v.Interface().(TableNameFor)
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpTableNameFor) {
+			return v1.Interface().(TableNameFor)
+		}
+	} else if v2 := reflect.New(v.Type()); v2.Type().Implements(tpTableNameFor) {
+		return v2.Interface().(TableNameFor)
+	}
+	return nil
+}
+
 // GetTableComment returns table comment
 func GetTableComment(v reflect.Value) string {
 	if v.Type().Implements(tpTableComment) {
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/imkos/xorm/schemas"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -138,3 +139,27 @@ func TestMyTable(t *testing.T) {
 		assert.EqualValues(t, fmt.Sprintf("mytable_%d", i), GetTableName(SameMapper{}, reflect.ValueOf(&table)))
 	}
 }
+
+type MyTableNameFor struct{}
+
+func (MyTableNameFor) TableName() string {
+	return "my_table"
+}
+
+func (*MyTableNameFor) TableNameFor(dbType schemas.DBType) string {
+	if dbType == schemas.POSTGRES {
+		return "analytics.my_table"
+	}
+	return ""
+}
+
+func TestGetTableNameFor(t *testing.T) {
+	assert.EqualValues(t, "analytics.my_table",
+		GetTableNameFor(SnakeMapper{}, reflect.ValueOf(new(MyTableNameFor)), schemas.POSTGRES))
+	assert.EqualValues(t, "analytics.my_table",
+		GetTableNameFor(SnakeMapper{}, reflect.ValueOf(MyTableNameFor{}), schemas.POSTGRES))
+	assert.EqualValues(t, "my_table",
+		GetTableNameFor(SnakeMapper{}, reflect.ValueOf(new(MyTableNameFor)), schemas.SQLITE))
+	assert.EqualValues(t, "userinfo",
+		GetTableNameFor(SnakeMapper{}, reflect.ValueOf(new(Userinfo)), schemas.POSTGRES))
+}
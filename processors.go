@@ -4,6 +4,8 @@
 
 package xorm
 
+import "github.com/imkos/xorm/schemas"
+
 // BeforeInsertProcessor executed before an object is initially persisted to the database
 type BeforeInsertProcessor interface {
 	BeforeInsert()
@@ -19,6 +21,24 @@ type BeforeDeleteProcessor interface {
 	BeforeDelete()
 }
 
+// BeforeInsertSessionProcessor executed before an object is initially persisted to the database
+// with session parameter, the insert will be aborted if an error is returned
+type BeforeInsertSessionProcessor interface {
+	BeforeInsert(*Session) error
+}
+
+// BeforeUpdateSessionProcessor executed before an object is updated with session parameter,
+// the update will be aborted if an error is returned
+type BeforeUpdateSessionProcessor interface {
+	BeforeUpdate(*Session) error
+}
+
+// BeforeDeleteSessionProcessor executed before an object is deleted with session parameter,
+// the delete will be aborted if an error is returned
+type BeforeDeleteSessionProcessor interface {
+	BeforeDelete(*Session) error
+}
+
 // BeforeSetProcessor executed before data set to the struct fields
 type BeforeSetProcessor interface {
 	BeforeSet(string, Cell)
@@ -54,6 +74,19 @@ type AfterLoadSessionProcessor interface {
 	AfterLoad(*Session)
 }
 
+// ScanContext represents the loading context of a bean which has been scanned from database
+type ScanContext struct {
+	Session *Session
+	Table   *schemas.Table
+	Columns []string
+}
+
+// AfterScanProcessor executed after a bean has been fully populated by Find, Get or Rows.
+// Different from AfterLoadProcessor, it receives the loading context and could return an error
+type AfterScanProcessor interface {
+	AfterScan(*ScanContext) error
+}
+
 type executedProcessorFunc func(*Session, interface{}) error
 
 type executedProcessor struct {
@@ -107,7 +140,7 @@ func executeAfterSet(bean interface{}, fields []string, scanResults []interface{
 	}
 }
 
-func buildAfterProcessors(session *Session, bean interface{}) {
+func buildAfterProcessors(session *Session, bean interface{}, table *schemas.Table, fields []string) {
 	// handle afterClosures
 	for _, closure := range session.afterClosures {
 		session.afterProcessors = append(session.afterProcessors, executedProcessor{
@@ -141,4 +174,18 @@ func buildAfterProcessors(session *Session, bean interface{}) {
 			bean:    bean,
 		})
 	}
+
+	if a, has := bean.(AfterScanProcessor); has {
+		session.afterProcessors = append(session.afterProcessors, executedProcessor{
+			fun: func(sess *Session, bean interface{}) error {
+				return a.AfterScan(&ScanContext{
+					Session: sess,
+					Table:   table,
+					Columns: fields,
+				})
+			},
+			session: session,
+			bean:    bean,
+		})
+	}
 }
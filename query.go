@@ -0,0 +1,102 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"fmt"
+
+	"xorm.io/builder"
+)
+
+// Param represents a named parameter slot of a prepared query, it's used as an argument
+// of the conditions and replaced by the value of the name when the query is executed
+type Param string
+
+// Query represents a prepared query whose SQL is rendered once by PrepareQuery, it's
+// immutable and could be executed concurrently with different parameters
+type Query struct {
+	engine      *Engine
+	sql         string
+	args        []interface{}
+	prepareStmt bool
+	err         error
+}
+
+// PrepareQuery builds the query by fn and renders the SQL once, the parameters which will
+// be given on executing are specified by Param. The table should be specified in fn.
+//
+//	q := engine.PrepareQuery(func(session *Session) *Session {
+//		return session.Table(new(User)).Where("age > ?", xorm.Param("age")).Desc("id")
+//	})
+//	err := q.Find(ctx, map[string]interface{}{"age": 18}, &users)
+func (engine *Engine) PrepareQuery(fn func(*Session) *Session) *Query {
+	session := engine.NewSession()
+	defer session.Close()
+
+	query := &Query{engine: engine}
+	s := fn(session)
+	if s != session {
+		defer s.Close()
+	}
+	statement := s.statement
+	if statement.LastError != nil {
+		query.err = statement.LastError
+		return query
+	}
+
+	var autoCond builder.Cond
+	if table := statement.RefTable; table != nil {
+		if col := table.DeletedColumn(); col != nil && !statement.GetUnscoped() {
+			autoCond = statement.CondDeleted(col)
+		}
+	}
+	query.sql, query.args, query.err = statement.GenFindSQL(autoCond)
+	query.prepareStmt = s.prepareStmt
+	return query
+}
+
+// SQL returns the rendered SQL and the arguments of the query, the named parameters are
+// the Param values in the arguments
+func (q *Query) SQL() (string, []interface{}) {
+	return q.sql, q.args
+}
+
+// bind returns the arguments with the named parameters replaced by the values of params
+func (q *Query) bind(params map[string]interface{}) ([]interface{}, error) {
+	args := make([]interface{}, len(q.args))
+	for i, arg := range q.args {
+		param, ok := arg.(Param)
+		if !ok {
+			args[i] = arg
+			continue
+		}
+		v, ok := params[string(param)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingParam, param)
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// Find executes the query with the named parameters and retrieves the records to the
+// slice or the map like Session.Find
+func (q *Query) Find(ctx context.Context, params map[string]interface{}, rowsSlicePtr interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	args, err := q.bind(params)
+	if err != nil {
+		return err
+	}
+
+	session := q.engine.NewSession()
+	defer session.Close()
+	if q.prepareStmt {
+		session.Prepare()
+	}
+	return session.Context(ctx).SQL(q.sql, args...).Find(rowsSlicePtr)
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/imkos/xorm/internal/utils"
+	"github.com/imkos/xorm/schemas"
+)
+
+// RegisterOptions represents the options of RegisterModelsWithOptions
+type RegisterOptions struct {
+	// VerifySchema checks the tables and the columns of the models exist in the database
+	VerifySchema bool
+}
+
+// ModelError represents a mapping error of a model found by RegisterModels
+type ModelError struct {
+	Model string
+	// Field is the name of the struct field, it's empty if the error is of the whole model
+	Field string
+	Err   error
+}
+
+func (err *ModelError) Error() string {
+	if err.Field == "" {
+		return fmt.Sprintf("model %s: %v", err.Model, err.Err)
+	}
+	return fmt.Sprintf("model %s field %s: %v", err.Model, err.Field, err.Err)
+}
+
+// Unwrap returns the cause of the error
+func (err *ModelError) Unwrap() error {
+	return err.Err
+}
+
+// ModelErrors represents all the errors found by RegisterModels
+type ModelErrors []*ModelError
+
+func (errs ModelErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more errors)", errs[0], len(errs)-1)
+}
+
+// Unwrap returns all the errors, so that errors.Is and errors.As could check any of them
+func (errs ModelErrors) Unwrap() []error {
+	all := make([]error, len(errs))
+	for i, err := range errs {
+		all[i] = err
+	}
+	return all
+}
+
+// RegisterModels parses and caches the table metadata of the models at startup, so that the
+// mapping errors like duplicate columns or unsupported field types are reported at once
+// instead of at the first query of the models. All the errors are returned as ModelErrors.
+func (engine *Engine) RegisterModels(beans ...interface{}) error {
+	return engine.RegisterModelsWithOptions(RegisterOptions{}, beans...)
+}
+
+// RegisterModelsWithOptions registers the models like RegisterModels and verifies the tables
+// of the models in the database if VerifySchema is set
+func (engine *Engine) RegisterModelsWithOptions(opts RegisterOptions, beans ...interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	var errs ModelErrors
+	tables := make([]*schemas.Table, len(beans))
+	for i, bean := range beans {
+		v := utils.ReflectValue(bean)
+		model := v.Type().String()
+		table, err := session.statement.ParseTable(v)
+		if err != nil {
+			errs = append(errs, &ModelError{Model: model, Err: err})
+			continue
+		}
+		tables[i] = table
+		errs = append(errs, validateModel(model, table)...)
+	}
+
+	if opts.VerifySchema && len(errs) == 0 {
+		for i, bean := range beans {
+			modelErrs, err := session.verifyModel(utils.ReflectValue(bean).Type().String(), engine.TableName(bean), tables[i])
+			if err != nil {
+				return err
+			}
+			errs = append(errs, modelErrs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateModel checks the columns of the parsed model
+func validateModel(model string, table *schemas.Table) ModelErrors {
+	var errs ModelErrors
+	fields := make(map[string]string, len(table.Columns()))
+	for _, col := range table.Columns() {
+		colName := strings.ToLower(col.Name)
+		if field, ok := fields[colName]; ok {
+			errs = append(errs, &ModelError{
+				Model: model,
+				Field: col.FieldName,
+				Err:   fmt.Errorf("%w: %s is also mapped by %s", ErrDuplicateColumn, col.Name, field),
+			})
+		} else {
+			fields[colName] = col.FieldName
+		}
+
+		if col.FieldType != nil && !isSupportedFieldType(col.FieldType) {
+			errs = append(errs, &ModelError{
+				Model: model,
+				Field: col.FieldName,
+				Err:   fmt.Errorf("%w: %v", ErrUnSupportedType, col.FieldType),
+			})
+		}
+	}
+	return errs
+}
+
+// isSupportedFieldType returns false if the values of the type could not be stored in a column
+func isSupportedFieldType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// verifyModel checks the table and the columns of the model exist in the database
+func (session *Session) verifyModel(model, tableName string, table *schemas.Table) (ModelErrors, error) {
+	exist, err := session.isTableExist(tableName)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return ModelErrors{&ModelError{
+			Model: model,
+			Err:   fmt.Errorf("%w: table %s does not exist", ErrSchemaMismatch, tableName),
+		}}, nil
+	}
+
+	_, cols, err := session.engine.dialect.GetColumns(session.getQueryer(), session.ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	dbCols := make(map[string]bool, len(cols))
+	for name := range cols {
+		dbCols[strings.ToLower(name)] = true
+	}
+
+	var errs ModelErrors
+	for _, col := range table.Columns() {
+		if !dbCols[strings.ToLower(col.Name)] {
+			errs = append(errs, &ModelError{
+				Model: model,
+				Field: col.FieldName,
+				Err:   fmt.Errorf("%w: column %s does not exist in table %s", ErrSchemaMismatch, col.Name, tableName),
+			})
+		}
+	}
+	return errs, nil
+}
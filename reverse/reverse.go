@@ -0,0 +1,360 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reverse generates the Go structs with xorm tags from the tables of a database.
+package reverse
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/imkos/xorm/names"
+	"github.com/imkos/xorm/schemas"
+)
+
+// DefaultTemplate is the default template to generate the models
+const DefaultTemplate = `// Code generated by xorm reverse. DO NOT EDIT.
+
+package {{.Package}}
+{{if .Imports}}
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{end}}
+{{- range .Models}}
+{{if .Comment}}// {{.Name}} {{.Comment}}
+{{end -}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`{{.Tag}}`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+}
+
+// TableName returns the name of the table of {{.Name}}
+func ({{.Name}}) TableName() string {
+	return {{printf "%q" .Table.Name}}
+}
+{{end}}`
+
+// Options represents the options to generate the models
+type Options struct {
+	// Package is the package name of the generated file, default is models
+	Package string
+	// Tables are the names of the tables to be generated, all the tables will be generated if it's empty
+	Tables []string
+	// TablePrefix will be trimmed from the table names before they are mapped to the struct names
+	TablePrefix string
+	// Mapper maps the table and column names to the struct and field names, default is LintGonicMapper
+	Mapper names.Mapper
+	// Template is a text/template to generate the file, default is DefaultTemplate
+	Template string
+	// JSONTag adds json tags with the column names to the fields
+	JSONTag bool
+	// NullablePointer generates pointer fields for the nullable columns
+	NullablePointer bool
+	// TypeMapper returns the Go type of the column, the default mapping will be used if it returns empty
+	TypeMapper func(col *schemas.Column) string
+}
+
+// Field represents a field of a generated struct
+type Field struct {
+	Name    string
+	Type    string
+	Tag     string
+	Comment string
+	Column  *schemas.Column
+}
+
+// Model represents a generated struct
+type Model struct {
+	Name    string
+	Comment string
+	Table   *schemas.Table
+	Fields  []*Field
+}
+
+// Data is the data passed to the template
+type Data struct {
+	Package string
+	Imports []string
+	Models  []*Model
+}
+
+// Generate writes the Go structs of the tables to w, the tables are usually returned by
+// engine.DBMetas() and dbType is the database type they come from.
+func Generate(w io.Writer, dbType schemas.DBType, tables []*schemas.Table, opts Options) error {
+	if opts.Package == "" {
+		opts.Package = "models"
+	}
+	if opts.Mapper == nil {
+		opts.Mapper = names.LintGonicMapper
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	tmpl, err := template.New("reverse").Parse(opts.Template)
+	if err != nil {
+		return err
+	}
+
+	tables, err = filterTables(tables, opts.Tables)
+	if err != nil {
+		return err
+	}
+
+	data := Data{Package: opts.Package}
+	imports := make(map[string]bool)
+	for _, table := range tables {
+		model := newModel(dbType, table, &opts)
+		for _, field := range model.Fields {
+			if strings.Contains(field.Type, "time.") {
+				imports["time"] = true
+			}
+		}
+		data.Models = append(data.Models, model)
+	}
+	for imp := range imports {
+		data.Imports = append(data.Imports, imp)
+	}
+	sort.Strings(data.Imports)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated source failed: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// filterTables returns the tables of the names in order
+func filterTables(tables []*schemas.Table, tableNames []string) ([]*schemas.Table, error) {
+	if len(tableNames) == 0 {
+		sorted := make([]*schemas.Table, len(tables))
+		copy(sorted, tables)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Name < sorted[j].Name
+		})
+		return sorted, nil
+	}
+
+	res := make([]*schemas.Table, 0, len(tableNames))
+	for _, name := range tableNames {
+		var found bool
+		for _, table := range tables {
+			if strings.EqualFold(table.Name, name) {
+				res = append(res, table)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("table %s is not found", name)
+		}
+	}
+	return res, nil
+}
+
+func newModel(dbType schemas.DBType, table *schemas.Table, opts *Options) *Model {
+	model := &Model{
+		Name:    identifier(opts.Mapper.Table2Obj(strings.TrimPrefix(table.Name, opts.TablePrefix))),
+		Comment: oneLine(table.Comment),
+		Table:   table,
+	}
+	for _, col := range table.Columns() {
+		tp := ""
+		if opts.TypeMapper != nil {
+			tp = opts.TypeMapper(col)
+		}
+		if tp == "" {
+			tp = GoType(dbType, col)
+			if opts.NullablePointer && col.Nullable && !col.IsPrimaryKey && !strings.HasPrefix(tp, "[]") {
+				tp = "*" + tp
+			}
+		}
+
+		tag := "xorm:" + strconv.Quote(xormTag(table, col))
+		if opts.JSONTag {
+			tag += " json:" + strconv.Quote(col.Name)
+		}
+		model.Fields = append(model.Fields, &Field{
+			Name:    identifier(opts.Mapper.Table2Obj(col.Name)),
+			Type:    tp,
+			Tag:     strings.ReplaceAll(tag, "`", ""),
+			Comment: oneLine(col.Comment),
+			Column:  col,
+		})
+	}
+	return model
+}
+
+// GoType returns the default Go type of the column read from the database of dbType
+func GoType(dbType schemas.DBType, col *schemas.Column) string {
+	name := strings.ToUpper(col.SQLType.Name)
+	unsigned := strings.HasPrefix(name, "UNSIGNED ")
+	name = strings.TrimPrefix(name, "UNSIGNED ")
+
+	switch {
+	case dbType == schemas.MYSQL && name == schemas.TinyInt && col.Length == 1,
+		dbType == schemas.MSSQL && name == schemas.Bit:
+		return "bool"
+	case dbType == schemas.SQLITE && name == schemas.Integer:
+		return "int64"
+	case name == schemas.BigInt || name == schemas.BigSerial || name == "INT8":
+		if unsigned {
+			return "uint64"
+		}
+		return "int64"
+	case name == schemas.Bit || name == schemas.TinyInt || name == schemas.SmallInt || name == schemas.MediumInt ||
+		name == schemas.Int || name == schemas.Integer || name == schemas.Serial:
+		if unsigned {
+			return "uint"
+		}
+		return "int"
+	case name == schemas.Float || name == schemas.Real:
+		return "float32"
+	case name == schemas.Double:
+		return "float64"
+	case name == schemas.Bool || name == schemas.Boolean:
+		return "bool"
+	}
+
+	switch schemas.SQLType2Type(schemas.SQLType{Name: name}) {
+	case schemas.TimeType:
+		return "time.Time"
+	case schemas.BytesType:
+		return "[]byte"
+	}
+	return "string"
+}
+
+// xormTag returns the xorm tag of the column which could be parsed back to the same column
+func xormTag(table *schemas.Table, col *schemas.Column) string {
+	tags := []string{"'" + col.Name + "'"}
+	if col.IsPrimaryKey {
+		tags = append(tags, "pk")
+	}
+	if col.IsAutoIncrement {
+		tags = append(tags, "autoincr")
+	}
+	if tp := sqlTypeTag(col); tp != "" {
+		tags = append(tags, tp)
+	}
+	if col.Nullable {
+		tags = append(tags, "null")
+	} else if !col.IsPrimaryKey {
+		tags = append(tags, "notnull")
+	}
+	if !col.DefaultIsEmpty && col.Default != "" && !col.IsAutoIncrement && isTagDefault(col.Default) {
+		tags = append(tags, "default "+col.Default)
+	}
+
+	indexNames := make([]string, 0, len(col.Indexes))
+	for name := range col.Indexes {
+		indexNames = append(indexNames, name)
+	}
+	sort.Strings(indexNames)
+	for _, name := range indexNames {
+		index, ok := table.Indexes[name]
+		if !ok {
+			continue
+		}
+		tp := "index"
+		if index.Type == schemas.UniqueType {
+			tp = "unique"
+		} else if index.Type == schemas.SpatialType {
+			tp = "spatial"
+		}
+		if len(index.Cols) == 1 && index.Name == col.Name {
+			tags = append(tags, tp)
+		} else {
+			tags = append(tags, tp+"("+index.Name+")")
+		}
+	}
+
+	if col.Collation != "" {
+		tags = append(tags, "collate "+col.Collation)
+	}
+	if col.Comment != "" {
+		tags = append(tags, "comment('"+strings.ReplaceAll(oneLine(col.Comment), "'", "")+"')")
+	}
+	return strings.Join(tags, " ")
+}
+
+// isTagDefault returns true if the default value could be parsed by the tag parser,
+// the expressions like CURRENT_TIMESTAMP() or nextval('seq') are not supported.
+func isTagDefault(def string) bool {
+	if strings.HasPrefix(def, "'") && strings.HasSuffix(def, "'") && len(def) > 1 {
+		return !strings.ContainsAny(def[1:len(def)-1], "'()")
+	}
+	return !strings.ContainsAny(def, "'() ")
+}
+
+// sqlTypeTag returns the SQL type of the column in the tag, it's empty if the type is unknown to xorm
+func sqlTypeTag(col *schemas.Column) string {
+	name := strings.ToUpper(col.SQLType.Name)
+	var prefix string
+	if strings.HasPrefix(name, "UNSIGNED ") {
+		prefix = "unsigned "
+		name = strings.TrimPrefix(name, "UNSIGNED ")
+	}
+	if _, ok := schemas.SqlTypes[name]; !ok {
+		return ""
+	}
+
+	switch {
+	case len(col.EnumOptions) > 0 || len(col.SetOptions) > 0:
+		options := col.EnumOptions
+		if len(options) == 0 {
+			options = col.SetOptions
+		}
+		keys := make([]string, 0, len(options))
+		for k := range options {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return options[keys[i]] < options[keys[j]]
+		})
+		return prefix + name + "('" + strings.Join(keys, "','") + "')"
+	case col.Length2 > 0:
+		return fmt.Sprintf("%s%s(%d,%d)", prefix, name, col.Length, col.Length2)
+	case col.Length > 0:
+		return fmt.Sprintf("%s%s(%d)", prefix, name, col.Length)
+	}
+	return prefix + name
+}
+
+// identifier makes the name a valid exported Go identifier
+func identifier(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	runes := []rune(b.String())
+	if len(runes) == 0 || !unicode.IsLetter(runes[0]) {
+		return "X" + string(runes)
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
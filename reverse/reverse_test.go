@@ -0,0 +1,135 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/imkos/xorm/schemas"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReverseTable() *schemas.Table {
+	table := schemas.NewTable("t_user_info", nil)
+	table.Comment = "stores the users"
+
+	id := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 20, 0, false)
+	id.IsPrimaryKey = true
+	id.IsAutoIncrement = true
+	table.AddColumn(id)
+
+	name := schemas.NewColumn("user_name", "", schemas.SQLType{Name: schemas.Varchar}, 50, 0, false)
+	name.Default = "''"
+	name.DefaultIsEmpty = false
+	name.Comment = "the user's name"
+	table.AddColumn(name)
+
+	status := schemas.NewColumn("status", "", schemas.SQLType{Name: schemas.TinyInt}, 1, 0, true)
+	table.AddColumn(status)
+
+	created := schemas.NewColumn("created_at", "", schemas.SQLType{Name: schemas.DateTime}, 0, 0, true)
+	created.Default = "CURRENT_TIMESTAMP()"
+	created.DefaultIsEmpty = false
+	table.AddColumn(created)
+
+	unique := schemas.NewIndex("user_name", schemas.UniqueType)
+	unique.AddColumn("user_name")
+	table.AddIndex(unique)
+	name.Indexes["user_name"] = schemas.UniqueType
+
+	index := schemas.NewIndex("s_c", schemas.IndexType)
+	index.AddColumn("status", "created_at")
+	table.AddIndex(index)
+	status.Indexes["s_c"] = schemas.IndexType
+	created.Indexes["s_c"] = schemas.IndexType
+	return table
+}
+
+func TestGenerate(t *testing.T) {
+	var buf bytes.Buffer
+	err := Generate(&buf, schemas.MYSQL, []*schemas.Table{newReverseTable()}, Options{
+		TablePrefix: "t_",
+		JSONTag:     true,
+	})
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, `// Code generated by xorm reverse. DO NOT EDIT.
+
+package models
+
+import (
+	"time"
+)
+
+// UserInfo stores the users
+type UserInfo struct {
+	ID        int64     `+"`"+`xorm:"'id' pk autoincr BIGINT(20)" json:"id"`+"`"+`
+	UserName  string    `+"`"+`xorm:"'user_name' VARCHAR(50) notnull default '' unique comment('the users name')" json:"user_name"`+"`"+` // the user's name
+	Status    bool      `+"`"+`xorm:"'status' TINYINT(1) null index(s_c)" json:"status"`+"`"+`
+	CreatedAt time.Time `+"`"+`xorm:"'created_at' DATETIME null index(s_c)" json:"created_at"`+"`"+`
+}
+
+// TableName returns the name of the table of UserInfo
+func (UserInfo) TableName() string {
+	return "t_user_info"
+}
+`, buf.String())
+}
+
+func TestGenerateOptions(t *testing.T) {
+	var buf bytes.Buffer
+	err := Generate(&buf, schemas.POSTGRES, []*schemas.Table{newReverseTable()}, Options{
+		Package:         "db",
+		NullablePointer: true,
+		TypeMapper: func(col *schemas.Column) string {
+			if col.Name == "id" {
+				return "uint64"
+			}
+			return ""
+		},
+		Template: `package {{.Package}}
+{{range .Models}}{{range .Fields}}// {{.Name}} {{.Type}}
+{{end}}{{end}}`,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, `package db
+
+// ID uint64
+// UserName string
+// Status *int
+// CreatedAt *time.Time
+`, buf.String())
+
+	err = Generate(&buf, schemas.POSTGRES, []*schemas.Table{newReverseTable()}, Options{
+		Tables: []string{"not_exist"},
+	})
+	assert.Error(t, err)
+}
+
+func TestGoType(t *testing.T) {
+	kases := []struct {
+		dbType   schemas.DBType
+		sqlType  string
+		length   int64
+		expected string
+	}{
+		{schemas.MYSQL, schemas.TinyInt, 1, "bool"},
+		{schemas.MYSQL, schemas.TinyInt, 4, "int"},
+		{schemas.MYSQL, schemas.UnsignedBigInt, 20, "uint64"},
+		{schemas.MSSQL, schemas.Bit, 0, "bool"},
+		{schemas.SQLITE, schemas.Integer, 0, "int64"},
+		{schemas.POSTGRES, schemas.Integer, 0, "int"},
+		{schemas.POSTGRES, schemas.Real, 0, "float32"},
+		{schemas.POSTGRES, schemas.Bytea, 0, "[]byte"},
+		{schemas.POSTGRES, schemas.TimeStampz, 0, "time.Time"},
+		{schemas.POSTGRES, schemas.Numeric, 0, "string"},
+		{schemas.POSTGRES, "INTERVAL", 0, "string"},
+	}
+	for _, kase := range kases {
+		col := schemas.NewColumn("a", "", schemas.SQLType{Name: kase.sqlType}, kase.length, 0, true)
+		assert.EqualValues(t, kase.expected, GoType(kase.dbType, col), kase.sqlType)
+	}
+}
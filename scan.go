@@ -6,6 +6,7 @@ package xorm
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -17,116 +18,110 @@ import (
 	"github.com/imkos/xorm/schemas"
 )
 
-// genScanResultsByBeanNullabale generates scan result
-func genScanResultsByBeanNullable(bean interface{}) (interface{}, bool, error) {
+// genScanResult generates the scan result of a scalar destination, it's a nullable container
+// for the basic types, or the container of the column type generated by the driver for the
+// others like pointers, then the result will be converted to the destination.
+func (engine *Engine) genScanResult(bean interface{}, tp *sql.ColumnType) (interface{}, bool, error) {
 	switch t := bean.(type) {
-	case *interface{}:
-		return t, false, nil
-	case *sql.NullInt64, *sql.NullBool, *sql.NullFloat64, *sql.NullString, *sql.RawBytes, *[]byte:
+	case *interface{}, *sql.RawBytes, *[]byte:
 		return t, false, nil
-	case *time.Time:
-		return &sql.NullString{}, true, nil
-	case *sql.NullTime:
-		return &sql.NullString{}, true, nil
 	case *string:
 		return &sql.NullString{}, true, nil
-	case *int, *int8, *int16, *int32:
-		return &sql.NullInt32{}, true, nil
-	case *int64:
+	case *int, *int8, *int16, *int32, *int64:
 		return &sql.NullInt64{}, true, nil
-	case *uint, *uint8, *uint16, *uint32:
-		return &convert.NullUint32{}, true, nil
-	case *uint64:
+	case *uint, *uint8, *uint16, *uint32, *uint64:
 		return &convert.NullUint64{}, true, nil
 	case *float32, *float64:
 		return &sql.NullFloat64{}, true, nil
 	case *bool:
 		return &sql.NullBool{}, true, nil
-	case sql.NullInt64, sql.NullBool, sql.NullFloat64, sql.NullString,
-		time.Time,
-		string,
-		int, int8, int16, int32, int64,
-		uint, uint8, uint16, uint32, uint64,
-		float32, float64,
-		bool:
-		return nil, false, fmt.Errorf("unsupported scan type: %t", t)
-	case convert.Conversion:
-		return &sql.RawBytes{}, true, nil
-	}
-
-	tp := reflect.TypeOf(bean).Elem()
-	switch tp.Kind() {
+	}
+
+	v := reflect.ValueOf(bean)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, false, fmt.Errorf("unsupported scan type: %T", bean)
+	}
+	switch v.Elem().Kind() {
 	case reflect.String:
 		return &sql.NullString{}, true, nil
-	case reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return &sql.NullInt64{}, true, nil
-	case reflect.Int32, reflect.Int, reflect.Int16, reflect.Int8:
-		return &sql.NullInt32{}, true, nil
-	case reflect.Uint64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return &convert.NullUint64{}, true, nil
-	case reflect.Uint32, reflect.Uint, reflect.Uint16, reflect.Uint8:
-		return &convert.NullUint32{}, true, nil
-	default:
-		return nil, false, fmt.Errorf("genScanResultsByBeanNullable: unsupported type: %#v", bean)
+	case reflect.Float32, reflect.Float64:
+		return &sql.NullFloat64{}, true, nil
+	case reflect.Bool:
+		return &sql.NullBool{}, true, nil
 	}
+
+	scanResult, err := engine.driver.GenScanResult(tp.DatabaseTypeName())
+	if err != nil {
+		return nil, false, err
+	}
+	return scanResult, true, nil
 }
 
-func genScanResultsByBean(bean interface{}) (interface{}, bool, error) {
-	switch t := bean.(type) {
+// isNullScanResult returns true if the scan result is NULL
+func isNullScanResult(scanResult interface{}) bool {
+	switch t := scanResult.(type) {
+	case *sql.RawBytes:
+		return *t == nil
 	case *interface{}:
-		return t, false, nil
-	case *sql.NullInt64, *sql.NullBool, *sql.NullFloat64, *sql.NullString,
-		*sql.RawBytes,
-		*string,
-		*int, *int8, *int16, *int32, *int64,
-		*uint, *uint8, *uint16, *uint32, *uint64,
-		*float32, *float64,
-		*bool:
-		return t, false, nil
-	case *time.Time, *sql.NullTime:
-		return &sql.NullString{}, true, nil
-	case sql.NullInt64, sql.NullBool, sql.NullFloat64, sql.NullString,
-		time.Time,
-		string,
-		int, int8, int16, int32, int64,
-		uint, uint8, uint16, uint32, uint64,
-		bool:
-		return nil, false, fmt.Errorf("unsupported scan type: %t", t)
-	case convert.Conversion:
-		return &sql.RawBytes{}, true, nil
-	}
-
-	tp := reflect.TypeOf(bean).Elem()
-	switch tp.Kind() {
-	case reflect.String:
-		return new(string), true, nil
-	case reflect.Int64:
-		return new(int64), true, nil
-	case reflect.Int32:
-		return new(int32), true, nil
-	case reflect.Int:
-		return new(int32), true, nil
-	case reflect.Int16:
-		return new(int32), true, nil
-	case reflect.Int8:
-		return new(int32), true, nil
-	case reflect.Uint64:
-		return new(uint64), true, nil
-	case reflect.Uint32:
-		return new(uint32), true, nil
-	case reflect.Uint:
-		return new(uint), true, nil
-	case reflect.Uint16:
-		return new(uint16), true, nil
-	case reflect.Uint8:
-		return new(uint8), true, nil
-	case reflect.Float32:
-		return new(float32), true, nil
-	case reflect.Float64:
-		return new(float64), true, nil
-	default:
-		return nil, false, fmt.Errorf("genScanResultsByBean: unsupported type: %#v", bean)
+		return *t == nil
+	case driver.Valuer:
+		v, err := t.Value()
+		return err == nil && v == nil
+	}
+	return false
+}
+
+// assignScanResult assigns the scan result to the destination. When the result is NULL, the
+// destination will be set to nil if it's a pointer, otherwise it keeps unchanged.
+func (engine *Engine) assignScanResult(dest, scanResult interface{}) error {
+	if _, ok := dest.(convert.Conversion); ok {
+		return convert.Assign(dest, scanResult, engine.DatabaseTZ, engine.TZLocation)
 	}
+
+	dv := reflect.ValueOf(dest).Elem()
+	if isNullScanResult(scanResult) {
+		if dv.Kind() == reflect.Ptr {
+			dv.Set(reflect.Zero(dv.Type()))
+		}
+		return nil
+	}
+	if dv.Kind() == reflect.Ptr {
+		if dv.IsNil() {
+			dv.Set(reflect.New(dv.Type().Elem()))
+		}
+		return engine.assignScanResult(dv.Interface(), scanResult)
+	}
+
+	switch d := dest.(type) {
+	case *time.Time:
+		t, err := convert.AsTime(scanResult, engine.DatabaseTZ, engine.TZLocation)
+		if err != nil {
+			return err
+		}
+		*d = *t
+		return nil
+	case *big.Float, *sql.NullTime:
+		return convert.Assign(dest, scanResult, engine.DatabaseTZ, engine.TZLocation)
+	}
+
+	src := scanResult
+	if valuer, ok := scanResult.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		src = v
+	} else if raw, ok := scanResult.(*sql.RawBytes); ok {
+		src = []byte(*raw)
+	}
+	if t, ok := src.(time.Time); ok && dv.Kind() != reflect.Struct {
+		src = t.In(engine.TZLocation).Format("2006-01-02 15:04:05")
+	}
+	return convert.Assign(dest, src, engine.DatabaseTZ, engine.TZLocation)
 }
 
 func (engine *Engine) scanStringInterface(rows *core.Rows, fields []string, types []*sql.ColumnType) ([]interface{}, error) {
@@ -144,10 +139,14 @@ func (engine *Engine) scanStringInterface(rows *core.Rows, fields []string, type
 
 // scan is a wrap of driver.Scan but will automatically change the input values according requirements
 func (engine *Engine) scan(rows *core.Rows, fields []string, types []*sql.ColumnType, vv ...interface{}) error {
+	if len(vv) != len(types) {
+		return fmt.Errorf("%w: %d columns but %d destinations", ErrScanCountMismatch, len(types), len(vv))
+	}
+
 	scanResults := make([]interface{}, 0, len(types))
 	replaces := make([]bool, 0, len(types))
 	var err error
-	for _, v := range vv {
+	for i, v := range vv {
 		var replaced bool
 		var scanResult interface{}
 		switch t := v.(type) {
@@ -160,12 +159,7 @@ func (engine *Engine) scan(rows *core.Rows, fields []string, types []*sql.Column
 			scanResult = &sql.RawBytes{}
 			replaced = true
 		default:
-			nullable, ok := types[0].Nullable()
-			if !ok || nullable {
-				scanResult, replaced, err = genScanResultsByBeanNullable(v)
-			} else {
-				scanResult, replaced, err = genScanResultsByBean(v)
-			}
+			scanResult, replaced, err = engine.genScanResult(v, types[i])
 			if err != nil {
 				return err
 			}
@@ -184,7 +178,7 @@ func (engine *Engine) scan(rows *core.Rows, fields []string, types []*sql.Column
 
 	for i, replaced := range replaces {
 		if replaced {
-			if err = convert.Assign(vv[i], scanResults[i], engine.DatabaseTZ, engine.TZLocation); err != nil {
+			if err = engine.assignScanResult(vv[i], scanResults[i]); err != nil {
 				return err
 			}
 		}
@@ -230,6 +224,10 @@ func (engine *Engine) row2mapInterface(rows *core.Rows, types []*sql.ColumnType,
 		if err != nil {
 			return nil, err
 		}
+		res, err = engine.normalizeValue(scanResultContainers[ii], res, types[ii])
+		if err != nil {
+			return nil, err
+		}
 		resultsMap[key] = res
 	}
 	return resultsMap, nil
@@ -0,0 +1,161 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type schemaCacheEntry struct {
+	exist     bool
+	expiredAt time.Time
+}
+
+// schemaCache caches the results of IsTableExist and IsColumnExist for a while
+type schemaCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	tables  map[string]schemaCacheEntry
+	columns map[string]map[string]schemaCacheEntry
+}
+
+func (c *schemaCache) setTTL(ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ttl = ttl
+	c.tables = nil
+	c.columns = nil
+}
+
+func (c *schemaCache) table(tableName string) (exist, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.tables[tableName]
+	if !ok || !time.Now().Before(entry.expiredAt) {
+		return false, false
+	}
+	return entry.exist, true
+}
+
+func (c *schemaCache) setTable(tableName string, exist bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	if c.tables == nil {
+		c.tables = make(map[string]schemaCacheEntry)
+	}
+	c.tables[tableName] = schemaCacheEntry{exist: exist, expiredAt: time.Now().Add(c.ttl)}
+}
+
+func (c *schemaCache) column(tableName, colName string) (exist, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.columns[tableName][strings.ToLower(colName)]
+	if !ok || !time.Now().Before(entry.expiredAt) {
+		return false, false
+	}
+	return entry.exist, true
+}
+
+func (c *schemaCache) setColumn(tableName, colName string, exist bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	if c.columns == nil {
+		c.columns = make(map[string]map[string]schemaCacheEntry)
+	}
+	if c.columns[tableName] == nil {
+		c.columns[tableName] = make(map[string]schemaCacheEntry)
+	}
+	c.columns[tableName][strings.ToLower(colName)] = schemaCacheEntry{exist: exist, expiredAt: time.Now().Add(c.ttl)}
+}
+
+// invalidate removes the entries of the tables, all the entries are removed if no table is given
+func (c *schemaCache) invalidate(tableNames ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(tableNames) == 0 {
+		c.tables = nil
+		c.columns = nil
+		return
+	}
+	for _, tableName := range tableNames {
+		delete(c.tables, tableName)
+		delete(c.columns, tableName)
+	}
+}
+
+// schemaCacheKey returns the key of the table in the schema cache, the default schema is trimmed
+// so that the table names with and without it share the entries
+func (engine *Engine) schemaCacheKey(tableName string) string {
+	if defaultSchema := engine.dialect.URI().Schema; defaultSchema != "" {
+		return strings.TrimPrefix(tableName, defaultSchema+".")
+	}
+	return tableName
+}
+
+// invalidateSchemaCache removes the cached metadata of the tables changed by the session, all
+// the cached metadata is removed if no table is given. The tables changed in a transaction are
+// invalidated again after committing, since the others may cache the metadata before the
+// changes are visible to them.
+func (session *Session) invalidateSchemaCache(tableNames ...string) {
+	cache := session.engine.schemaCache
+	keys := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		keys = append(keys, session.engine.schemaCacheKey(tableName))
+	}
+	cache.invalidate(keys...)
+	if !session.isAutoCommit {
+		session.afterCommit(func() {
+			cache.invalidate(keys...)
+		})
+	}
+}
+
+// SetSchemaCacheTTL caches the results of IsTableExist and IsColumnExist for the ttl, so that
+// the hot paths like creating a table if it doesn't exist don't query the database schema on
+// every call. The entries of a table are invalidated when the table is created, dropped or
+// altered by the engine, the changes made outside the engine should be followed by
+// InvalidateSchemaCache. The cache is not used in transactions. A zero ttl disables the cache.
+func (engine *Engine) SetSchemaCacheTTL(ttl time.Duration) {
+	engine.schemaCache.setTTL(ttl)
+}
+
+// InvalidateSchemaCache removes the cached schema metadata of the tables, the beanOrTableNames
+// could be structs or table names. All the cached metadata is removed if nothing is given.
+func (engine *Engine) InvalidateSchemaCache(beanOrTableNames ...interface{}) {
+	if len(beanOrTableNames) == 0 {
+		engine.schemaCache.invalidate()
+		return
+	}
+	tableNames := make([]string, 0, len(beanOrTableNames))
+	for _, beanOrTableName := range beanOrTableNames {
+		tableNames = append(tableNames, engine.schemaCacheKey(engine.TableName(beanOrTableName)))
+	}
+	engine.schemaCache.invalidate(tableNames...)
+}
+
+// SetSchemaCacheTTL sets the ttl of the schema metadata cache for all the engines of the group
+func (eg *EngineGroup) SetSchemaCacheTTL(ttl time.Duration) {
+	eg.Engine.SetSchemaCacheTTL(ttl)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetSchemaCacheTTL(ttl)
+	}
+}
+
+// InvalidateSchemaCache removes the cached schema metadata of the tables for all the engines
+// of the group
+func (eg *EngineGroup) InvalidateSchemaCache(beanOrTableNames ...interface{}) {
+	eg.Engine.InvalidateSchemaCache(beanOrTableNames...)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].InvalidateSchemaCache(beanOrTableNames...)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"strings"
+
+	"github.com/imkos/xorm/schemas"
+)
+
+// SchemaSnapshot returns the snapshot of the tables of the beans, or the snapshot of all
+// the tables in the database if there is no bean. The snapshot could be serialized as
+// JSON or YAML and be applied to a database by ApplySchemaSnapshot later.
+func (engine *Engine) SchemaSnapshot(beans ...interface{}) (*schemas.Snapshot, error) {
+	if len(beans) == 0 {
+		tables, err := engine.DBMetas()
+		if err != nil {
+			return nil, err
+		}
+		return schemas.NewSnapshot(tables), nil
+	}
+
+	tables := make([]*schemas.Table, 0, len(beans))
+	for _, bean := range beans {
+		table, err := engine.TableInfo(bean)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return schemas.NewSnapshot(tables), nil
+}
+
+// ApplySchemaSnapshot syncs the tables of the snapshot to the database like SyncWithOptions
+func (engine *Engine) ApplySchemaSnapshot(snapshot *schemas.Snapshot, opts SyncOptions) (*SyncResult, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.ApplySchemaSnapshot(snapshot, opts)
+}
+
+// ApplySchemaSnapshot syncs the tables of the snapshot to the database like SyncWithOptions
+func (session *Session) ApplySchemaSnapshot(snapshot *schemas.Snapshot, opts SyncOptions) (*SyncResult, error) {
+	engine := session.engine
+
+	if session.isAutoClose {
+		session.isAutoClose = false
+		defer session.Close()
+	}
+
+	tables, err := engine.dialect.GetTables(session.getQueryer(), session.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session.autoResetStatement = false
+	defer func() {
+		session.autoResetStatement = true
+		session.resetStatement()
+	}()
+
+	findTable := func(name string) *schemas.Table {
+		tbNameWithSchema := engine.tbNameWithSchema(name)
+		for _, tb := range tables {
+			if strings.EqualFold(engine.tbNameWithSchema(tb.Name), tbNameWithSchema) {
+				return tb
+			}
+		}
+		return nil
+	}
+
+	oriTables := make([]*schemas.Table, 0, len(snapshot.Tables))
+	for _, table := range snapshot.Tables {
+		if oriTable := findTable(table.Name); oriTable != nil && !containsTable(oriTables, oriTable) {
+			oriTables = append(oriTables, oriTable)
+		}
+	}
+	if err = engine.loadTablesInfo(session.getQueryer(), session.ctx, engine.dialect, oriTables); err != nil {
+		return nil, err
+	}
+
+	for _, table := range snapshot.Tables {
+		session.statement.StoreEngine = table.StoreEngine
+		session.statement.Charset = table.Charset
+		if err := session.syncTable(opts, table, table.Name, findTable(table.Name), nil); err != nil {
+			return nil, err
+		}
+	}
+	return &SyncResult{}, nil
+}
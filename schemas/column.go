@@ -22,8 +22,9 @@ const (
 type Column struct {
 	Name            string
 	TableName       string
-	FieldName       string // Available only when parsed from a struct
-	FieldIndex      []int  // Available only when parsed from a struct
+	FieldName       string       // Available only when parsed from a struct
+	FieldIndex      []int        // Available only when parsed from a struct
+	FieldType       reflect.Type // Available only when parsed from a struct
 	SQLType         SQLType
 	IsJSON          bool
 	Length          int64
@@ -37,6 +38,7 @@ type Column struct {
 	IsCreated       bool
 	IsUpdated       bool
 	IsDeleted       bool
+	IsDeletedFlag   bool // the deleted column is a bool or integer flag instead of a time
 	IsCascade       bool
 	IsVersion       bool
 	DefaultIsEmpty  bool // false means column has no default set, but not default value is empty
@@ -44,8 +46,14 @@ type Column struct {
 	SetOptions      map[string]int
 	DisableTimeZone bool
 	TimeZone        *time.Location // column specified time zone
+	IsDuration      bool           // the field is a time.Duration stored by the duration tag
+	DurationUnit    time.Duration  // the unit of the integer column storing a duration
+	EpochUnit       time.Duration  // the unit of the integer created, updated or deleted column, zero means seconds
 	Comment         string
 	Collation       string
+	IsInvisible     bool   // the column is not selected unless it's specified by Cols
+	Polymorphic     string // the name of the polymorphic reference which the column belongs to
+	Discriminator   string // the value of the single table inheritance discriminator column
 }
 
 // NewColumn creates a new column
@@ -100,6 +108,9 @@ func (col *Column) ValueOfV(dataStruct *reflect.Value) (*reflect.Value, error) {
 
 // ConvertID converts id content to suitable type according column type
 func (col *Column) ConvertID(sid string) (interface{}, error) {
+	if converter := GetIDConverter(col.FieldType); converter != nil {
+		return converter.FromID(sid)
+	}
 	if col.SQLType.IsNumeric() {
 		n, err := strconv.ParseInt(sid, 10, 64)
 		if err != nil {
@@ -111,3 +122,23 @@ func (col *Column) ConvertID(sid string) (interface{}, error) {
 	}
 	return nil, errors.New("not supported")
 }
+
+// Epoch returns the time as the integer of the column's EpochUnit since the Unix epoch
+func (col *Column) Epoch(t time.Time) int64 {
+	if col.EpochUnit <= 0 || col.EpochUnit == time.Second {
+		return t.Unix()
+	}
+	return t.UnixNano() / int64(col.EpochUnit)
+}
+
+// DeletedFlagValue returns the value of the deleted flag column, it's a bool for a boolean
+// column and 1 or 0 for the others
+func (col *Column) DeletedFlagValue(deleted bool) interface{} {
+	if col.SQLType.IsBool() {
+		return deleted
+	}
+	if deleted {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// DiscriminatorColumn returns the discriminator column of the single table inheritance,
+// or nil if the table has no discriminator
+func (table *Table) DiscriminatorColumn() *Column {
+	if table.Discriminator == "" {
+		return nil
+	}
+	return table.GetColumn(table.Discriminator)
+}
+
+// DiscriminatorArg returns the discriminator value of the column converted to the column type
+func (col *Column) DiscriminatorArg() (interface{}, error) {
+	if !col.SQLType.IsNumeric() {
+		return col.Discriminator, nil
+	}
+	return strconv.ParseInt(col.Discriminator, 10, 64)
+}
+
+// SetDiscriminator sets the field value to the discriminator value of the column
+func (col *Column) SetDiscriminator(fieldValue reflect.Value) error {
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(col.Discriminator)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(col.Discriminator, 10, 64)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(col.Discriminator, 10, 64)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported discriminator field type %v", fieldValue.Type())
+	}
+	return nil
+}
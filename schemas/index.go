@@ -6,6 +6,7 @@ package schemas
 
 import (
 	"fmt"
+	"hash/fnv"
 	"strings"
 )
 
@@ -13,6 +14,7 @@ import (
 const (
 	IndexType = iota + 1
 	UniqueType
+	SpatialType
 )
 
 // Index represents a database index
@@ -20,36 +22,132 @@ type Index struct {
 	IsRegular bool
 	Name      string
 	Type      int
-	Cols      []string
+	// Cols are the key parts, a key part is a column name or an expression in parentheses,
+	// i.e. "(LOWER(`email`))", for a functional key part
+	Cols []string
+	// Desc marks the descending key parts of Cols, nil means all the key parts are ascending
+	Desc []bool
+	// IsInvisible is true if the index is ignored by the optimizer, i.e. INVISIBLE of MySQL 8 and Oracle
+	IsInvisible bool
 }
 
 // NewIndex new an index object
 func NewIndex(name string, indexType int) *Index {
-	return &Index{true, name, indexType, make([]string, 0)}
+	return &Index{IsRegular: true, Name: name, Type: indexType, Cols: make([]string, 0)}
 }
 
-// XName returns the special index name for the table
-func (index *Index) XName(tableName string) string {
+// IsExprKeyPart returns true if the key part of an index is an expression
+func IsExprKeyPart(keyPart string) bool {
+	return strings.HasPrefix(strings.TrimSpace(keyPart), "(")
+}
+
+// normalizeKeyPart returns the key part which could be compared, the quotes, the spaces and
+// the cases of the expressions are ignored since the databases return them in their own forms
+func normalizeKeyPart(keyPart string) string {
+	if !IsExprKeyPart(keyPart) {
+		return keyPart
+	}
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		switch r {
+		case '`', '"', ' ', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, keyPart))
+}
+
+// XName returns the special index name for the table, the name is shortened by
+// ShortenIdentifier if it's longer than the max identifier length of the database
+func (index *Index) XName(tableName string, maxLen ...int) string {
+	name := index.Name
 	if !strings.HasPrefix(index.Name, "UQE_") &&
 		!strings.HasPrefix(index.Name, "IDX_") {
 		tableParts := strings.Split(strings.ReplaceAll(tableName, `"`, ""), ".")
 		tableName = tableParts[len(tableParts)-1]
 		if index.Type == UniqueType {
-			return fmt.Sprintf("UQE_%v_%v", tableName, index.Name)
+			name = fmt.Sprintf("UQE_%v_%v", tableName, index.Name)
+		} else {
+			name = fmt.Sprintf("IDX_%v_%v", tableName, index.Name)
+		}
+	}
+	if len(maxLen) > 0 {
+		return ShortenIdentifier(name, maxLen[0])
+	}
+	return name
+}
+
+// shortenedHashLen is the length of the hash suffix of the shortened identifiers, i.e. _1a2b3c4d
+const shortenedHashLen = 9
+
+// ShortenIdentifier shortens the identifier to maxLen by replacing its tail with the hash
+// of the whole identifier, so the result is deterministic and the identifiers sharing a long
+// prefix are still different. It's returned as is if maxLen is not positive or not exceeded.
+func ShortenIdentifier(name string, maxLen int) string {
+	if maxLen <= 0 || len(name) <= maxLen {
+		return name
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+	if maxLen <= shortenedHashLen {
+		return suffix[shortenedHashLen-maxLen:]
+	}
+	return name[:maxLen-shortenedHashLen] + suffix
+}
+
+// IsShortenedIdentifier returns true if the identifier looks like one shortened by ShortenIdentifier
+func IsShortenedIdentifier(name string) bool {
+	if len(name) <= shortenedHashLen || name[len(name)-shortenedHashLen] != '_' {
+		return false
+	}
+	for _, c := range name[len(name)-shortenedHashLen+1:] {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
 		}
-		return fmt.Sprintf("IDX_%v_%v", tableName, index.Name)
 	}
-	return index.Name
+	return true
 }
 
 // AddColumn add columns which will be composite index
 func (index *Index) AddColumn(cols ...string) {
 	index.Cols = append(index.Cols, cols...)
+	if index.Desc != nil {
+		index.Desc = append(index.Desc, make([]bool, len(cols))...)
+	}
+}
+
+// AddDescColumn add columns which will be the descending key parts of composite index
+func (index *Index) AddDescColumn(cols ...string) {
+	if index.Desc == nil {
+		index.Desc = make([]bool, len(index.Cols))
+	}
+	index.Cols = append(index.Cols, cols...)
+	for range cols {
+		index.Desc = append(index.Desc, true)
+	}
+}
+
+// IsDesc returns true if the i-th key part is descending
+func (index *Index) IsDesc(i int) bool {
+	return i < len(index.Desc) && index.Desc[i]
+}
+
+// Columns returns the column names of the key parts, the expressions are excluded
+func (index *Index) Columns() []string {
+	cols := make([]string, 0, len(index.Cols))
+	for _, col := range index.Cols {
+		if !IsExprKeyPart(col) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
 }
 
-// Equal return true if the two Index is equal
+// Equal return true if the two Index is equal, the visibilities are not compared so that
+// the visibility of an index changed on the database is kept
 func (index *Index) Equal(dst *Index) bool {
-	if index.Type != dst.Type {
+	if index.Type != dst.Type && (index.Type == UniqueType || dst.Type == UniqueType) {
+		// a spatial index is created as a regular index on the databases which don't support it
 		return false
 	}
 	if len(index.Cols) != len(dst.Cols) {
@@ -59,7 +157,7 @@ func (index *Index) Equal(dst *Index) bool {
 	for i := 0; i < len(index.Cols); i++ {
 		var found bool
 		for j := 0; j < len(dst.Cols); j++ {
-			if index.Cols[i] == dst.Cols[j] {
+			if normalizeKeyPart(index.Cols[i]) == normalizeKeyPart(dst.Cols[j]) && index.IsDesc(i) == dst.IsDesc(j) {
 				found = true
 				break
 			}
@@ -0,0 +1,57 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexEqual(t *testing.T) {
+	index := NewIndex("created", IndexType)
+	index.AddColumn("user_id")
+	index.AddDescColumn("created")
+	assert.EqualValues(t, []bool{false, true}, index.Desc)
+	assert.EqualValues(t, []string{"user_id", "created"}, index.Columns())
+
+	dbIndex := NewIndex("created", IndexType)
+	dbIndex.AddColumn("user_id", "created")
+	assert.False(t, index.Equal(dbIndex))
+
+	dbIndex = NewIndex("created", IndexType)
+	dbIndex.AddColumn("user_id")
+	dbIndex.AddDescColumn("created")
+	assert.True(t, index.Equal(dbIndex))
+
+	exprIndex := NewIndex("email", UniqueType)
+	exprIndex.AddColumn("(LOWER(email))")
+	assert.True(t, IsExprKeyPart(exprIndex.Cols[0]))
+	assert.Empty(t, exprIndex.Columns())
+
+	dbIndex = NewIndex("email", UniqueType)
+	dbIndex.AddColumn("(lower(`email`))")
+	assert.True(t, exprIndex.Equal(dbIndex))
+}
+
+func TestIndexXName(t *testing.T) {
+	index := NewIndex("created", IndexType)
+	assert.EqualValues(t, "IDX_user_created", index.XName("user"))
+	assert.EqualValues(t, "IDX_user_created", index.XName("public.user", 30))
+
+	index = NewIndex("organization_id_created_at_deleted_at", UniqueType)
+	name := index.XName("organization_membership")
+	assert.EqualValues(t, "UQE_organization_membership_organization_id_created_at_deleted_at", name)
+	short := index.XName("organization_membership", 30)
+	assert.Len(t, short, 30)
+	assert.EqualValues(t, ShortenIdentifier(name, 30), short)
+	assert.EqualValues(t, name[:21], short[:21])
+	assert.True(t, IsShortenedIdentifier(short))
+	assert.False(t, IsShortenedIdentifier(name))
+
+	other := NewIndex("organization_id_created_at_updated_at", UniqueType)
+	assert.NotEqual(t, short, other.XName("organization_membership", 30))
+	assert.EqualValues(t, name, index.XName("organization_membership", 0))
+}
@@ -7,6 +7,13 @@ package schemas
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/imkos/xorm/internal/utils"
 )
@@ -20,6 +27,54 @@ func NewPK(pks ...interface{}) *PK {
 	return &p
 }
 
+// IDConverter converts the primary key values of a custom type, i.e. a typed string ID, an uuid
+// or a wrapper of int
+type IDConverter struct {
+	// FromID converts the primary key content, i.e. read by the cache, to a value of the type
+	FromID func(sid string) (interface{}, error)
+	// ToID converts a value of the type to the argument of the SQL, the value is passed to
+	// the driver as it is if ToID is nil
+	ToID func(v interface{}) (interface{}, error)
+}
+
+var (
+	idConverters      = make(map[reflect.Type]*IDConverter)
+	idConvertersMutex sync.RWMutex
+)
+
+// RegisterIDConverter registers the converter of the primary key type, so the primary keys of
+// the type could be used by ID(), the map keys and the cache
+func RegisterIDConverter(t reflect.Type, converter IDConverter) {
+	if converter.FromID == nil {
+		panic("FromID of the ID converter is required")
+	}
+	// the primary keys are gob encoded by the cache
+	gob.Register(reflect.New(t).Elem().Interface())
+
+	idConvertersMutex.Lock()
+	defer idConvertersMutex.Unlock()
+	idConverters[t] = &converter
+}
+
+// GetIDConverter returns the registered converter of the primary key type or nil
+func GetIDConverter(t reflect.Type) *IDConverter {
+	if t == nil {
+		return nil
+	}
+	idConvertersMutex.RLock()
+	defer idConvertersMutex.RUnlock()
+	return idConverters[t]
+}
+
+// IDValue converts the primary key value to the argument of the SQL via the registered converter
+func IDValue(v interface{}) (interface{}, error) {
+	converter := GetIDConverter(reflect.TypeOf(v))
+	if converter == nil || converter.ToID == nil {
+		return v, nil
+	}
+	return converter.ToID(v)
+}
+
 // IsZero return true if primay keys are zero
 func (p *PK) IsZero() bool {
 	for _, k := range *p {
@@ -38,6 +93,51 @@ func (p *PK) ToString() (string, error) {
 	return buf.String(), err
 }
 
+// Key returns the string which identifies the primary key values, i.e. the key of the cached bean.
+// Every value is written with its kind and length, so the keys of the different values never
+// collide, and the integers share the kind whatever their types are, so int(1) and int64(1)
+// have the same key.
+func (p *PK) Key() string {
+	var buf strings.Builder
+	for _, v := range *p {
+		kind, s := pkKeyPart(v)
+		buf.WriteByte(kind)
+		buf.WriteString(strconv.Itoa(len(s)))
+		buf.WriteByte(':')
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
+
+func pkKeyPart(v interface{}) (byte, string) {
+	switch t := v.(type) {
+	case nil:
+		return 'n', ""
+	case []byte:
+		return 's', string(t)
+	case time.Time:
+		return 't', t.UTC().Format(time.RFC3339Nano)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return 'b', strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 'i', strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() <= math.MaxInt64 {
+			return 'i', strconv.FormatUint(rv.Uint(), 10)
+		}
+		return 'u', strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return 'f', strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	case reflect.String:
+		return 's', rv.String()
+	}
+	return 'v', fmt.Sprintf("%T:%v", v, v)
+}
+
 // FromString reads content to load primary keys
 func (p *PK) FromString(content string) error {
 	dec := gob.NewDecoder(bytes.NewBufferString(content))
@@ -34,3 +34,53 @@ func TestPK(t *testing.T) {
 		}
 	}
 }
+
+func TestPKKey(t *testing.T) {
+	a := NewPK(1, "2")
+	b := NewPK(int64(1), []byte("2"))
+	if a.Key() != b.Key() {
+		t.Fatal("key", a.Key(), "should be equal", b.Key())
+	}
+
+	collisions := [][2]*PK{
+		{NewPK("1", 2), NewPK(1, "2")},
+		{NewPK("a1:b"), NewPK("a", "b")},
+		{NewPK(nil), NewPK("")},
+		{NewPK(uint64(1 << 63)), NewPK(int64(-1 << 63))},
+	}
+	for _, pks := range collisions {
+		if pks[0].Key() == pks[1].Key() {
+			t.Fatal("key of", *pks[0], "should not be equal to", *pks[1])
+		}
+	}
+}
+
+type testOrderID string
+
+func TestIDConverter(t *testing.T) {
+	RegisterIDConverter(reflect.TypeOf(testOrderID("")), IDConverter{
+		FromID: func(sid string) (interface{}, error) {
+			return testOrderID("O" + sid), nil
+		},
+		ToID: func(v interface{}) (interface{}, error) {
+			return string(v.(testOrderID))[1:], nil
+		},
+	})
+
+	col := &Column{FieldType: reflect.TypeOf(testOrderID(""))}
+	id, err := col.ConvertID("12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != testOrderID("O12") {
+		t.Fatal("id", id, "should be equal", testOrderID("O12"))
+	}
+
+	v, err := IDValue(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "12" {
+		t.Fatal("value", v, "should be equal", "12")
+	}
+}
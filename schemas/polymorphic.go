@@ -0,0 +1,37 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import "reflect"
+
+// Polymorphic represents a reference to a record of any table, i.e. the comments or the
+// attachments shared by several tables. A field of the type tagged with polymorphic(name)
+// is mapped to the columns <name>_type and <name>_id.
+type Polymorphic struct {
+	Type string // the table name of the referenced record
+	ID   int64  // the primary key of the referenced record
+}
+
+// PolymorphicType is the reflect type of Polymorphic
+var PolymorphicType = reflect.TypeOf(Polymorphic{})
+
+// PolymorphicColumns returns the names of the type and the id columns of a polymorphic reference
+func PolymorphicColumns(name string) (typeColumn, idColumn string) {
+	return name + "_type", name + "_id"
+}
+
+// Polymorphics returns the names of the polymorphic references of the table
+func (table *Table) Polymorphics() []string {
+	var names []string
+	for _, col := range table.Columns() {
+		if col.Polymorphic == "" {
+			continue
+		}
+		if len(names) == 0 || names[len(names)-1] != col.Polymorphic {
+			names = append(names, col.Polymorphic)
+		}
+	}
+	return names
+}
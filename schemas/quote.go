@@ -93,75 +93,119 @@ func (q Quoter) JoinWrite(b *strings.Builder, a []string, sep string) error {
 	return nil
 }
 
-func findWord(v string, start int) int {
-	for j := start; j < len(v); j++ {
-		switch v[j] {
-		case '.', ' ':
+// findWord returns the end of the bare word starting at v[start], it ends before a separator
+// or the beginning of a quoted identifier
+func (q Quoter) findWord(v string, start int) int {
+	for j := start + 1; j < len(v); j++ {
+		if v[j] == '.' || v[j] == ' ' {
+			return j
+		}
+		if _, ok := q.closingMark(v[j]); ok {
 			return j
 		}
 	}
 	return len(v)
 }
 
-func findStart(value string, start int) int {
-	if value[start] == '.' {
-		return start + 1
-	}
-	if value[start] != ' ' {
-		return start
+// closingMark returns the closing quote mark if c opens a quoted identifier, the common quote
+// marks are recognized besides the ones of the quoter so that the names written for other
+// databases could be requoted
+func (q Quoter) closingMark(c byte) (byte, bool) {
+	switch {
+	case c == CommanQuoteMark, c == '"':
+		return c, true
+	case c == '[':
+		return ']', true
+	case c != 0 && c == q.Prefix:
+		return q.Suffix, true
 	}
+	return 0, false
+}
 
-	k := -1
-	for j := start; j < len(value); j++ {
-		if value[j] != ' ' {
-			k = j
-			break
+// readQuotedIdent reads the quoted identifier starting at value[start] and unescapes the doubled
+// closing marks in it, it returns the identifier and the index after the closing mark, ok is
+// false if the identifier is not closed
+func readQuotedIdent(value string, start int, closing byte) (ident string, end int, ok bool) {
+	var b strings.Builder
+	var escaped bool
+	for i := start + 1; i < len(value); i++ {
+		if value[i] != closing {
+			if escaped {
+				b.WriteByte(value[i])
+			}
+			continue
 		}
+		if i+1 < len(value) && value[i+1] == closing {
+			if !escaped { // copy the identifier only if it has escaped marks
+				escaped = true
+				b.WriteString(value[start+1 : i])
+			}
+			b.WriteByte(closing)
+			i++
+			continue
+		}
+		if !escaped {
+			return value[start+1 : i], i + 1, true
+		}
+		return b.String(), i + 1, true
 	}
-	if k == -1 {
-		return len(value)
-	}
+	return "", start, false
+}
 
-	if k+1 < len(value) &&
-		(value[k] == 'A' || value[k] == 'a') &&
-		(value[k+1] == 'S' || value[k+1] == 's') {
-		k += 2
+// isPlainIdent returns true if the identifier could be used without quotes
+func isPlainIdent(ident string) bool {
+	if ident == "" {
+		return false
 	}
-
-	for j := k; j < len(value); j++ {
-		if value[j] != ' ' {
-			return j
+	for i := 0; i < len(ident); i++ {
+		c := ident[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80) {
+			return false
 		}
 	}
-	return len(value)
+	return true
 }
 
-func (q Quoter) quoteWordTo(buf *strings.Builder, word string) error {
-	realWord := word
-	if (word[0] == CommanQuoteMark && word[len(word)-1] == CommanQuoteMark) ||
-		(word[0] == q.Prefix && word[len(word)-1] == q.Suffix) {
-		realWord = word[1 : len(word)-1]
-	}
-
-	if q.IsEmpty() {
-		_, err := buf.WriteString(realWord)
+// writeIdentTo writes the identifier with the quote marks of the quoter and escapes the closing
+// mark in it by doubling it
+func (q Quoter) writeIdentTo(buf *strings.Builder, ident string) error {
+	if err := buf.WriteByte(q.Prefix); err != nil {
 		return err
 	}
-
-	isReserved := q.IsReserved(realWord)
-	if isReserved && realWord != "*" {
-		if err := buf.WriteByte(q.Prefix); err != nil {
+	for i := 0; i < len(ident); i++ {
+		if ident[i] == q.Suffix {
+			if err := buf.WriteByte(q.Suffix); err != nil {
+				return err
+			}
+		}
+		if err := buf.WriteByte(ident[i]); err != nil {
 			return err
 		}
 	}
-	if _, err := buf.WriteString(realWord); err != nil {
-		return err
-	}
-	if isReserved && realWord != "*" {
-		return buf.WriteByte(q.Suffix)
+	return buf.WriteByte(q.Suffix)
+}
+
+// hasQuoteMark returns true if the word contains a quote mark which could be read as the
+// beginning of a quoted identifier
+func (q Quoter) hasQuoteMark(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if _, ok := q.closingMark(word[i]); ok || word[i] == q.Suffix {
+			return true
+		}
 	}
+	return false
+}
 
-	return nil
+// quoteWordTo quotes the identifier if it's reserved, the identifiers which were quoted or
+// contain quote marks are quoted as well if they could not be used without quotes, i.e. they
+// contain dots or spaces
+func (q Quoter) quoteWordTo(buf *strings.Builder, ident string, wasQuoted bool) error {
+	mustQuote := !isPlainIdent(ident) && (wasQuoted || q.hasQuoteMark(ident))
+	if q.IsEmpty() || (ident == "*" && !wasQuoted) || (!q.IsReserved(ident) && !mustQuote) {
+		_, err := buf.WriteString(ident)
+		return err
+	}
+	return q.writeIdentTo(buf, ident)
 }
 
 // QuoteTo quotes the table or column names. i.e. if the quotes are [ and ]
@@ -169,36 +213,74 @@ func (q Quoter) quoteWordTo(buf *strings.Builder, word string) error {
 //	name -> [name]
 //	`name` -> [name]
 //	[name] -> [name]
+//	"name" -> [name]
 //	schema.name -> [schema].[name]
 //	`schema`.`name` -> [schema].[name]
 //	`schema`.name -> [schema].[name]
 //	schema.`name` -> [schema].[name]
 //	[schema].name -> [schema].[name]
 //	schema.[name] -> [schema].[name]
-//	name AS a  ->  [name] AS a
-//	schema.name AS a  ->  [schema].[name] AS a
+//	"schema"."name" -> [schema].[name]
+//	"my.schema".name -> [my.schema].[name]
+//	name AS a  ->  [name] AS [a]
+//	schema.name AS a  ->  [schema].[name] AS [a]
+//	db..name -> [db]..[name]
+//	na]me -> [na]]me]
+//
+// The quoted identifiers are unescaped and escaped again with the quote marks of the quoter,
+// so quoting a quoted name returns it unchanged.
 func (q Quoter) QuoteTo(buf *strings.Builder, value string) error {
-	var i int
-	for i < len(value) {
-		start := findStart(value, i)
-		if start > i {
-			if _, err := buf.WriteString(value[i:start]); err != nil {
+	var hasWord, afterSpace bool
+	for i := 0; i < len(value); {
+		c := value[i]
+		if c == ' ' || c == '.' {
+			if err := buf.WriteByte(c); err != nil {
 				return err
 			}
+			afterSpace = c == ' '
+			i++
+			continue
 		}
-		if start == len(value) {
-			return nil
+
+		if closing, ok := q.closingMark(c); ok {
+			if ident, end, ok := readQuotedIdent(value, i, closing); ok {
+				if err := q.quoteWordTo(buf, ident, true); err != nil {
+					return err
+				}
+				hasWord, afterSpace = true, false
+				i = end
+				continue
+			}
 		}
 
-		nextEnd := findWord(value, start)
-		if err := q.quoteWordTo(buf, value[start:nextEnd]); err != nil {
+		end := q.findWord(value, i)
+		word := value[i:end]
+		if hasWord && afterSpace && strings.EqualFold(word, "AS") {
+			if _, err := buf.WriteString(word); err != nil {
+				return err
+			}
+		} else if err := q.quoteWordTo(buf, word, false); err != nil {
 			return err
 		}
-		i = nextEnd
+		hasWord, afterSpace = true, false
+		i = end
 	}
 	return nil
 }
 
+// QuoteIdent quotes the name as one identifier even if it's not reserved, the dots in it are
+// not the separators of the schema and the closing quote marks in it are escaped. It's
+// returned as is if the quoter has no quote marks.
+func (q Quoter) QuoteIdent(name string) string {
+	if q.IsEmpty() {
+		return name
+	}
+	var buf strings.Builder
+	buf.Grow(len(name) + 2)
+	_ = q.writeIdentTo(&buf, name)
+	return buf.String()
+}
+
 // Strings quotes a slice of string
 func (q Quoter) Strings(s []string) []string {
 	res := make([]string, 0, len(s))
@@ -21,7 +21,7 @@ func TestAlwaysQuoteTo(t *testing.T) {
 			{"[mytable]", "mytable"},
 			{"[mytable]", "`mytable`"},
 			{"[mytable]", `[mytable]`},
-			{`["mytable"]`, `"mytable"`},
+			{"[mytable]", `"mytable"`},
 			{`[mytable].*`, `[mytable].*`},
 			{"[myschema].[mytable]", "myschema.mytable"},
 			{"[myschema].[mytable]", "`myschema`.mytable"},
@@ -30,9 +30,21 @@ func TestAlwaysQuoteTo(t *testing.T) {
 			{"[myschema].[mytable]", `[myschema].mytable`},
 			{"[myschema].[mytable]", `myschema.[mytable]`},
 			{"[myschema].[mytable]", `[myschema].[mytable]`},
-			{`["myschema].[mytable"]`, `"myschema.mytable"`},
+			{"[myschema.mytable]", `"myschema.mytable"`},
+			{"[myschema].[mytable]", `"myschema"."mytable"`},
+			{"[myschema].[mytable]", "`myschema`.\"mytable\""},
+			{"[my.schema].[my table]", "`my.schema`.[my table]"},
+			{"[my]]table]", "`my]table`"},
+			{"[my]]table]", "[my]]table]"},
+			{"[my`table]", "`my``table`"},
+			{"[mytable] as [t]", `"mytable" as "t"`},
+			{"[mytable] as [t]", "mytable as t"},
+			{"[name] [ascending]", "name ascending"},
+			{"[AS]", "AS"},
 			{"[message_user] AS [sender]", "`message_user` AS `sender`"},
 			{"[myschema].[mytable] AS [table]", "myschema.mytable AS table"},
+			{"[mydb]..[mytable]", "mydb..mytable"},
+			{"[mydb]..[mytable] AS [table]", "[mydb]..[mytable] AS table"},
 			{" [mytable]", " mytable"},
 			{"  [mytable]", "  mytable"},
 			{"[mytable] ", "mytable "},
@@ -65,7 +77,7 @@ func TestReversedQuoteTo(t *testing.T) {
 			{"[mytable]", "`mytable`"},
 			{"[mytable]", `[mytable]`},
 			{"[mytable].*", `[mytable].*`},
-			{`"mytable"`, `"mytable"`},
+			{"[mytable]", `"mytable"`},
 			{"myschema.[mytable]", "myschema.mytable"},
 			{"myschema.[mytable]", "`myschema`.mytable"},
 			{"myschema.[mytable]", "myschema.`mytable`"},
@@ -73,7 +85,7 @@ func TestReversedQuoteTo(t *testing.T) {
 			{"myschema.[mytable]", `[myschema].mytable`},
 			{"myschema.[mytable]", `myschema.[mytable]`},
 			{"myschema.[mytable]", `[myschema].[mytable]`},
-			{`"myschema.mytable"`, `"myschema.mytable"`},
+			{"[myschema.mytable]", `"myschema.mytable"`},
 			{"message_user AS sender", "`message_user` AS `sender`"},
 			{"myschema.[mytable] AS table", "myschema.mytable AS table"},
 		}
@@ -99,7 +111,7 @@ func TestNoQuoteTo(t *testing.T) {
 			{"mytable", "`mytable`"},
 			{"mytable", `[mytable]`},
 			{"mytable.*", `[mytable].*`},
-			{`"mytable"`, `"mytable"`},
+			{"mytable", `"mytable"`},
 			{"myschema.mytable", "myschema.mytable"},
 			{"myschema.mytable", "`myschema`.mytable"},
 			{"myschema.mytable", "myschema.`mytable`"},
@@ -107,7 +119,8 @@ func TestNoQuoteTo(t *testing.T) {
 			{"myschema.mytable", `[myschema].mytable`},
 			{"myschema.mytable", `myschema.[mytable]`},
 			{"myschema.mytable", `[myschema].[mytable]`},
-			{`"myschema.mytable"`, `"myschema.mytable"`},
+			{"[myschema.mytable]", `"myschema.mytable"`},
+			{"myschema.mytable", `"myschema"."mytable"`},
 			{"message_user AS sender", "`message_user` AS `sender`"},
 			{"myschema.mytable AS table", "myschema.mytable AS table"},
 		}
@@ -193,3 +206,34 @@ func TestReplace(t *testing.T) {
 		})
 	}
 }
+
+func TestQuoteIdent(t *testing.T) {
+	quoter := Quoter{'"', '"', AlwaysNoReserve}
+	assert.EqualValues(t, `"my.table"`, quoter.QuoteIdent("my.table"))
+	assert.EqualValues(t, `"my""table"`, quoter.QuoteIdent(`my"table`))
+	assert.EqualValues(t, "[my]]table]", Quoter{'[', ']', AlwaysNoReserve}.QuoteIdent("my]table"))
+	assert.EqualValues(t, "my.table", Quoter{0, 0, AlwaysReserve}.QuoteIdent("my.table"))
+}
+
+func FuzzQuoteTo(f *testing.F) {
+	for _, seed := range []string{
+		"mytable", "`myschema`.mytable", `"my.schema"."my table"`, "[my]]table]",
+		"myschema.mytable AS table", "mydb..mytable", "[mytable].*", ` "a" as b `,
+	} {
+		f.Add(seed)
+	}
+	quoters := []Quoter{
+		{'[', ']', AlwaysReserve},
+		{'"', '"', AlwaysReserve},
+		{'`', '`', AlwaysReserve},
+		{'[', ']', AlwaysNoReserve},
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		for _, quoter := range quoters {
+			quoted := quoter.Quote(value)
+			if requoted := quoter.Quote(quoted); requoted != quoted {
+				t.Errorf("%c%c: quoting %q is not idempotent: %q then %q", quoter.Prefix, quoter.Suffix, value, quoted, requoted)
+			}
+		}
+	})
+}
@@ -0,0 +1,328 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SnapshotVersion is the version of the snapshot format
+const SnapshotVersion = 1
+
+// Snapshot represents the schemas of some tables which could be serialized as JSON
+// or YAML with a stable format, so that it could be stored in VCS and be diffed.
+type Snapshot struct {
+	Version int      `json:"version" yaml:"version"`
+	Tables  []*Table `json:"tables" yaml:"tables"`
+}
+
+// NewSnapshot creates a snapshot of the tables, the tables will be sorted by name
+func NewSnapshot(tables []*Table) *Snapshot {
+	sorted := make([]*Table, len(tables))
+	copy(sorted, tables)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return &Snapshot{
+		Version: SnapshotVersion,
+		Tables:  sorted,
+	}
+}
+
+// Table returns the table of the name, or nil if it's not in the snapshot
+func (snapshot *Snapshot) Table(name string) *Table {
+	for _, table := range snapshot.Tables {
+		if strings.EqualFold(table.Name, name) {
+			return table
+		}
+	}
+	return nil
+}
+
+type tableSnapshot struct {
+	Name          string            `json:"name" yaml:"name"`
+	Comment       string            `json:"comment,omitempty" yaml:"comment,omitempty"`
+	StoreEngine   string            `json:"store_engine,omitempty" yaml:"store_engine,omitempty"`
+	Charset       string            `json:"charset,omitempty" yaml:"charset,omitempty"`
+	Collation     string            `json:"collation,omitempty" yaml:"collation,omitempty"`
+	AutoIncrStart int64             `json:"auto_incr_start,omitempty" yaml:"auto_incr_start,omitempty"`
+	Options       map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	Columns       []*Column         `json:"columns" yaml:"columns"`
+	Indexes       []*Index          `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+}
+
+type columnSnapshot struct {
+	Name          string   `json:"name" yaml:"name"`
+	Type          string   `json:"type" yaml:"type"`
+	Length        int64    `json:"length,omitempty" yaml:"length,omitempty"`
+	Length2       int64    `json:"length2,omitempty" yaml:"length2,omitempty"`
+	Nullable      bool     `json:"nullable" yaml:"nullable"`
+	Default       *string  `json:"default,omitempty" yaml:"default,omitempty"`
+	PrimaryKey    bool     `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	AutoIncrement bool     `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+	Created       bool     `json:"created,omitempty" yaml:"created,omitempty"`
+	Updated       bool     `json:"updated,omitempty" yaml:"updated,omitempty"`
+	Deleted       bool     `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	DeletedFlag   bool     `json:"deleted_flag,omitempty" yaml:"deleted_flag,omitempty"`
+	Version       bool     `json:"version,omitempty" yaml:"version,omitempty"`
+	JSON          bool     `json:"json,omitempty" yaml:"json,omitempty"`
+	EnumOptions   []string `json:"enum_options,omitempty" yaml:"enum_options,omitempty"`
+	SetOptions    []string `json:"set_options,omitempty" yaml:"set_options,omitempty"`
+	Comment       string   `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Collation     string   `json:"collation,omitempty" yaml:"collation,omitempty"`
+	Invisible     bool     `json:"invisible,omitempty" yaml:"invisible,omitempty"`
+}
+
+type indexSnapshot struct {
+	Name      string   `json:"name" yaml:"name"`
+	Unique    bool     `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Spatial   bool     `json:"spatial,omitempty" yaml:"spatial,omitempty"`
+	Regular   bool     `json:"regular" yaml:"regular"`
+	Cols      []string `json:"cols" yaml:"cols"`
+	Desc      []bool   `json:"desc,omitempty" yaml:"desc,omitempty"`
+	Invisible bool     `json:"invisible,omitempty" yaml:"invisible,omitempty"`
+}
+
+// sortedOptions returns the options of enum or set in order
+func sortedOptions(options map[string]int) []string {
+	if len(options) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(options))
+	for k := range options {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return options[res[i]] < options[res[j]]
+	})
+	return res
+}
+
+func optionsMap(options []string) map[string]int {
+	res := make(map[string]int, len(options))
+	for i, k := range options {
+		res[k] = i
+	}
+	return res
+}
+
+func (table *Table) snapshot() *tableSnapshot {
+	indexes := make([]*Index, 0, len(table.Indexes))
+	for _, index := range table.Indexes {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].Name < indexes[j].Name
+	})
+	return &tableSnapshot{
+		Name:          table.Name,
+		Comment:       table.Comment,
+		StoreEngine:   table.StoreEngine,
+		Charset:       table.Charset,
+		Collation:     table.Collation,
+		AutoIncrStart: table.AutoIncrStart,
+		Options:       table.Options,
+		Columns:       table.Columns(),
+		Indexes:       indexes,
+	}
+}
+
+func (table *Table) fromSnapshot(s *tableSnapshot) error {
+	*table = *NewEmptyTable()
+	table.Name = s.Name
+	table.Comment = s.Comment
+	table.StoreEngine = s.StoreEngine
+	table.Charset = s.Charset
+	table.Collation = s.Collation
+	table.AutoIncrStart = s.AutoIncrStart
+	table.Options = s.Options
+	for _, col := range s.Columns {
+		col.TableName = s.Name
+		table.AddColumn(col)
+	}
+	for _, index := range s.Indexes {
+		for _, colName := range index.Columns() {
+			col := table.GetColumn(colName)
+			if col == nil {
+				return fmt.Errorf("unknown column %s of index %s on table %s", colName, index.Name, s.Name)
+			}
+			col.Indexes[index.Name] = index.Type
+		}
+		table.AddIndex(index)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (table *Table) MarshalJSON() ([]byte, error) {
+	return json.Marshal(table.snapshot())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (table *Table) UnmarshalJSON(data []byte) error {
+	var s tableSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return table.fromSnapshot(&s)
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (table *Table) MarshalYAML() (interface{}, error) {
+	return table.snapshot(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (table *Table) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s tableSnapshot
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return table.fromSnapshot(&s)
+}
+
+func (col *Column) snapshot() *columnSnapshot {
+	s := &columnSnapshot{
+		Name:          col.Name,
+		Type:          col.SQLType.Name,
+		Length:        col.Length,
+		Length2:       col.Length2,
+		Nullable:      col.Nullable,
+		PrimaryKey:    col.IsPrimaryKey,
+		AutoIncrement: col.IsAutoIncrement,
+		Created:       col.IsCreated,
+		Updated:       col.IsUpdated,
+		Deleted:       col.IsDeleted,
+		DeletedFlag:   col.IsDeletedFlag,
+		Version:       col.IsVersion,
+		JSON:          col.IsJSON,
+		EnumOptions:   sortedOptions(col.EnumOptions),
+		SetOptions:    sortedOptions(col.SetOptions),
+		Comment:       col.Comment,
+		Collation:     col.Collation,
+		Invisible:     col.IsInvisible,
+	}
+	if !col.DefaultIsEmpty {
+		def := col.Default
+		s.Default = &def
+	}
+	return s
+}
+
+func (col *Column) fromSnapshot(s *columnSnapshot) {
+	*col = *NewColumn(s.Name, "", SQLType{Name: s.Type}, s.Length, s.Length2, s.Nullable)
+	if s.Default != nil {
+		col.Default = *s.Default
+		col.DefaultIsEmpty = false
+	}
+	col.IsPrimaryKey = s.PrimaryKey
+	col.IsAutoIncrement = s.AutoIncrement
+	col.IsCreated = s.Created
+	col.IsUpdated = s.Updated
+	col.IsDeleted = s.Deleted
+	col.IsDeletedFlag = s.DeletedFlag
+	col.IsVersion = s.Version
+	col.IsJSON = s.JSON || col.IsJSON
+	if len(s.EnumOptions) > 0 {
+		col.EnumOptions = optionsMap(s.EnumOptions)
+	}
+	if len(s.SetOptions) > 0 {
+		col.SetOptions = optionsMap(s.SetOptions)
+	}
+	col.Comment = s.Comment
+	col.Collation = s.Collation
+	col.IsInvisible = s.Invisible
+}
+
+// MarshalJSON implements json.Marshaler
+func (col *Column) MarshalJSON() ([]byte, error) {
+	return json.Marshal(col.snapshot())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (col *Column) UnmarshalJSON(data []byte) error {
+	var s columnSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	col.fromSnapshot(&s)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (col *Column) MarshalYAML() (interface{}, error) {
+	return col.snapshot(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (col *Column) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s columnSnapshot
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	col.fromSnapshot(&s)
+	return nil
+}
+
+func (index *Index) snapshot() *indexSnapshot {
+	return &indexSnapshot{
+		Name:      index.Name,
+		Unique:    index.Type == UniqueType,
+		Spatial:   index.Type == SpatialType,
+		Regular:   index.IsRegular,
+		Cols:      index.Cols,
+		Desc:      index.Desc,
+		Invisible: index.IsInvisible,
+	}
+}
+
+func (index *Index) fromSnapshot(s *indexSnapshot) {
+	tp := IndexType
+	if s.Unique {
+		tp = UniqueType
+	} else if s.Spatial {
+		tp = SpatialType
+	}
+	*index = *NewIndex(s.Name, tp)
+	index.IsRegular = s.Regular
+	index.IsInvisible = s.Invisible
+	index.AddColumn(s.Cols...)
+	if len(s.Desc) == len(s.Cols) {
+		index.Desc = s.Desc
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (index *Index) MarshalJSON() ([]byte, error) {
+	return json.Marshal(index.snapshot())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (index *Index) UnmarshalJSON(data []byte) error {
+	var s indexSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	index.fromSnapshot(&s)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (index *Index) MarshalYAML() (interface{}, error) {
+	return index.snapshot(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (index *Index) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s indexSnapshot
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	index.fromSnapshot(&s)
+	return nil
+}
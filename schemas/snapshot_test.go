@@ -0,0 +1,96 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func newSnapshotTable() *Table {
+	table := NewTable("user", nil)
+	table.Comment = "users"
+
+	id := NewColumn("id", "Id", SQLType{Name: BigInt}, 0, 0, false)
+	id.IsPrimaryKey = true
+	id.IsAutoIncrement = true
+	table.AddColumn(id)
+
+	name := NewColumn("name", "Name", SQLType{Name: Varchar}, 255, 0, true)
+	name.Default = "''"
+	name.DefaultIsEmpty = false
+	table.AddColumn(name)
+
+	status := NewColumn("status", "Status", SQLType{Name: Enum}, 0, 0, false)
+	status.EnumOptions = map[string]int{"active": 0, "disabled": 1}
+	table.AddColumn(status)
+
+	created := NewColumn("created", "Created", SQLType{Name: DateTime}, 0, 0, true)
+	created.IsCreated = true
+	table.AddColumn(created)
+
+	for _, index := range []*Index{NewIndex("name", UniqueType), NewIndex("created", IndexType)} {
+		index.AddColumn(index.Name)
+		table.AddIndex(index)
+		table.GetColumn(index.Name).Indexes[index.Name] = index.Type
+	}
+	return table
+}
+
+func assertSnapshotTable(t *testing.T, expected, table *Table) {
+	assert.EqualValues(t, expected.Name, table.Name)
+	assert.EqualValues(t, expected.Comment, table.Comment)
+	assert.EqualValues(t, expected.ColumnsSeq(), table.ColumnsSeq())
+	assert.EqualValues(t, expected.PrimaryKeys, table.PrimaryKeys)
+	assert.EqualValues(t, expected.AutoIncrement, table.AutoIncrement)
+	assert.EqualValues(t, expected.Created, table.Created)
+	assert.EqualValues(t, expected.Indexes, table.Indexes)
+	for i, col := range table.Columns() {
+		expectedCol := expected.Columns()[i]
+		assert.EqualValues(t, expectedCol.SQLType, col.SQLType)
+		assert.EqualValues(t, expectedCol.Length, col.Length)
+		assert.EqualValues(t, expectedCol.Nullable, col.Nullable)
+		assert.EqualValues(t, expectedCol.Default, col.Default)
+		assert.EqualValues(t, expectedCol.DefaultIsEmpty, col.DefaultIsEmpty)
+		assert.EqualValues(t, expectedCol.EnumOptions, col.EnumOptions)
+		assert.EqualValues(t, expectedCol.Indexes, col.Indexes)
+	}
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	snapshot := NewSnapshot([]*Table{newSnapshotTable(), NewTable("a", nil)})
+	assert.EqualValues(t, "a", snapshot.Tables[0].Name)
+
+	bs, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	// the output should be stable
+	bs2, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	assert.EqualValues(t, string(bs), string(bs2))
+
+	var res Snapshot
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	assert.EqualValues(t, SnapshotVersion, res.Version)
+	assert.Len(t, res.Tables, 2)
+	assertSnapshotTable(t, newSnapshotTable(), res.Table("user"))
+	assert.Nil(t, res.Table("not_exist"))
+}
+
+func TestSnapshotYAML(t *testing.T) {
+	snapshot := NewSnapshot([]*Table{newSnapshotTable()})
+
+	bs, err := yaml.Marshal(snapshot)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bs), "enum_options:\n            - active\n            - disabled\n")
+
+	var res Snapshot
+	assert.NoError(t, yaml.Unmarshal(bs, &res))
+	assert.Len(t, res.Tables, 1)
+	assertSnapshotTable(t, newSnapshotTable(), res.Tables[0])
+}
@@ -20,14 +20,17 @@ type Table struct {
 	Indexes       map[string]*Index
 	PrimaryKeys   []string
 	AutoIncrement string
+	AutoIncrStart int64 // the next value of the auto increment column, 0 means unknown or database default
 	Created       map[string]bool
 	Updated       string
 	Deleted       string
 	Version       string
+	Discriminator string // the discriminator column of the single table inheritance
 	StoreEngine   string
 	Charset       string
 	Comment       string
 	Collation     string
+	Options       map[string]string // the dialect specific table options, i.e. ROW_FORMAT of mysql
 }
 
 // NewEmptyTable creates an empty table
@@ -146,6 +149,9 @@ func (table *Table) AddColumn(col *Column) {
 	if col.IsVersion {
 		table.Version = col.Name
 	}
+	if col.Discriminator != "" {
+		table.Discriminator = col.Name
+	}
 }
 
 // AddIndex adds an index or an unique to table
@@ -161,6 +167,10 @@ func (table *Table) IDOfV(rv reflect.Value) (PK, error) {
 		var err error
 
 		pkField := v.FieldByIndex(col.FieldIndex)
+		if GetIDConverter(pkField.Type()) != nil {
+			pk[i] = pkField.Interface()
+			continue
+		}
 
 		switch pkField.Kind() {
 		case reflect.String:
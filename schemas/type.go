@@ -7,9 +7,12 @@ package schemas
 import (
 	"database/sql"
 	"math/big"
+	"net/netip"
 	"reflect"
 	"strings"
 	"time"
+
+	"github.com/imkos/xorm/convert"
 )
 
 // DBType represents a database type
@@ -17,12 +20,15 @@ type DBType string
 
 // enumerates all database types
 const (
-	POSTGRES DBType = "postgres"
-	SQLITE   DBType = "sqlite3"
-	MYSQL    DBType = "mysql"
-	MSSQL    DBType = "mssql"
-	ORACLE   DBType = "oracle"
-	DAMENG   DBType = "dameng"
+	POSTGRES  DBType = "postgres"
+	SQLITE    DBType = "sqlite3"
+	MYSQL     DBType = "mysql"
+	MSSQL     DBType = "mssql"
+	ORACLE    DBType = "oracle"
+	DAMENG    DBType = "dameng"
+	SNOWFLAKE DBType = "snowflake"
+	HANA      DBType = "hana"
+	DB2       DBType = "db2"
 )
 
 // SQLType represents SQL types
@@ -41,6 +47,7 @@ const (
 	NUMERIC_TYPE
 	ARRAY_TYPE
 	BOOL_TYPE
+	SPATIAL_TYPE
 )
 
 // IsType reutrns ture if the column type is the same as the parameter
@@ -81,6 +88,11 @@ func (s *SQLType) IsArray() bool {
 	return s.IsType(ARRAY_TYPE)
 }
 
+// IsSpatial returns true if column is a geometry or geography type
+func (s *SQLType) IsSpatial() bool {
+	return s.IsType(SPATIAL_TYPE)
+}
+
 // IsJson returns true if column is an array type
 func (s *SQLType) IsJson() bool {
 	return s.Name == Json || s.Name == Jsonb
@@ -95,6 +107,8 @@ func (s *SQLType) IsXML() bool {
 var (
 	Bit               = "BIT"
 	UnsignedBit       = "UNSIGNED BIT"
+	BitSet            = "BITSET"
+	VarBit            = "VARBIT"
 	TinyInt           = "TINYINT"
 	UnsignedTinyInt   = "UNSIGNED TINYINT"
 	SmallInt          = "SMALLINT"
@@ -133,6 +147,7 @@ var (
 	TimeStamp     = "TIMESTAMP"
 	TimeStampz    = "TIMESTAMPZ"
 	Year          = "YEAR"
+	Interval      = "INTERVAL"
 
 	Decimal    = "DECIMAL"
 	Numeric    = "NUMERIC"
@@ -164,9 +179,17 @@ var (
 	XML   = "XML"
 	Array = "ARRAY"
 
+	Inet = "INET"
+	Cidr = "CIDR"
+
+	Geometry  = "GEOMETRY"
+	Geography = "GEOGRAPHY"
+
 	SqlTypes = map[string]int{
 		Bit:               NUMERIC_TYPE,
 		UnsignedBit:       NUMERIC_TYPE,
+		BitSet:            NUMERIC_TYPE,
+		VarBit:            NUMERIC_TYPE,
 		TinyInt:           NUMERIC_TYPE,
 		UnsignedTinyInt:   NUMERIC_TYPE,
 		SmallInt:          NUMERIC_TYPE,
@@ -187,6 +210,9 @@ var (
 
 		XML: TEXT_TYPE,
 
+		Inet: TEXT_TYPE,
+		Cidr: TEXT_TYPE,
+
 		Char:       TEXT_TYPE,
 		NChar:      TEXT_TYPE,
 		Varchar:    TEXT_TYPE,
@@ -208,6 +234,7 @@ var (
 		TimeStampz:    TIME_TYPE,
 		SmallDateTime: TIME_TYPE,
 		Year:          TIME_TYPE,
+		Interval:      TIME_TYPE,
 
 		Decimal:       NUMERIC_TYPE,
 		Numeric:       NUMERIC_TYPE,
@@ -237,6 +264,9 @@ var (
 		"INT8": NUMERIC_TYPE,
 
 		Array: ARRAY_TYPE,
+
+		Geometry:  SPATIAL_TYPE,
+		Geography: SPATIAL_TYPE,
 	}
 )
 
@@ -267,11 +297,15 @@ var (
 
 	TimeType        = reflect.TypeOf((*time.Time)(nil)).Elem()
 	BigFloatType    = reflect.TypeOf((*big.Float)(nil)).Elem()
+	DecimalType     = reflect.TypeOf((*convert.Decimal)(nil)).Elem()
+	BitSetType      = reflect.TypeOf((*convert.BitSet)(nil)).Elem()
 	NullFloat64Type = reflect.TypeOf((*sql.NullFloat64)(nil)).Elem()
 	NullStringType  = reflect.TypeOf((*sql.NullString)(nil)).Elem()
 	NullInt32Type   = reflect.TypeOf((*sql.NullInt32)(nil)).Elem()
 	NullInt64Type   = reflect.TypeOf((*sql.NullInt64)(nil)).Elem()
 	NullBoolType    = reflect.TypeOf((*sql.NullBool)(nil)).Elem()
+	NetipAddrType   = reflect.TypeOf((*netip.Addr)(nil)).Elem()
+	NetipPrefixType = reflect.TypeOf((*netip.Prefix)(nil)).Elem()
 )
 
 // Type2SQLType generate SQLType acorrding Go's type
@@ -302,8 +336,16 @@ func Type2SQLType(t reflect.Type) (st SQLType) {
 	case reflect.String:
 		st = SQLType{Varchar, 255, 0}
 	case reflect.Struct:
-		if t.ConvertibleTo(TimeType) {
+		if t == NetipAddrType {
+			st = SQLType{Inet, 0, 0}
+		} else if t == NetipPrefixType {
+			st = SQLType{Cidr, 0, 0}
+		} else if t == BitSetType {
+			st = SQLType{BitSet, 64, 0}
+		} else if t.ConvertibleTo(TimeType) {
 			st = SQLType{DateTime, 0, 0}
+		} else if t.ConvertibleTo(DecimalType) {
+			st = SQLType{Decimal, 38, 10}
 		} else if t.ConvertibleTo(NullFloat64Type) {
 			st = SQLType{Double, 0, 0}
 		} else if t.ConvertibleTo(NullStringType) {
@@ -342,10 +384,12 @@ func SQLType2Type(st SQLType) reflect.Type {
 		return Float32Type
 	case Double:
 		return Float64Type
-	case Char, NChar, Varchar, NVarchar, TinyText, Text, NText, MediumText, LongText, Enum, Set, Uuid, Clob, SysName:
+	case Char, NChar, Varchar, NVarchar, TinyText, Text, NText, MediumText, LongText, Enum, Set, Uuid, Clob, SysName, Inet, Cidr:
 		return StringType
-	case TinyBlob, Blob, LongBlob, Bytea, Binary, MediumBlob, VarBinary, UniqueIdentifier:
+	case TinyBlob, Blob, LongBlob, Bytea, Binary, MediumBlob, VarBinary, UniqueIdentifier, Geometry, Geography:
 		return BytesType
+	case BitSet, VarBit:
+		return BitSetType
 	case Bool:
 		return BoolType
 	case DateTime, Date, Time, TimeStamp, TimeStampz, SmallDateTime, Year:
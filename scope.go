@@ -0,0 +1,189 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/schemas"
+	"xorm.io/builder"
+)
+
+// scopes represents the named scopes defined on an engine
+type scopes struct {
+	mutex  sync.RWMutex
+	scopes map[string]func(*Session) *Session
+}
+
+func (s *scopes) define(name string, scope func(*Session) *Session) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.scopes == nil {
+		s.scopes = make(map[string]func(*Session) *Session)
+	}
+	s.scopes[name] = scope
+}
+
+func (s *scopes) get(name string) (func(*Session) *Session, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	scope, ok := s.scopes[name]
+	return scope, ok
+}
+
+// defaultScopes represents the default conditions of the tables
+type defaultScopes struct {
+	mutex sync.RWMutex
+	conds map[string]builder.Cond
+}
+
+func (s *defaultScopes) add(tableName string, cond builder.Cond) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conds == nil {
+		s.conds = make(map[string]builder.Cond)
+	}
+	if c, ok := s.conds[tableName]; ok {
+		cond = c.And(cond)
+	}
+	s.conds[tableName] = cond
+}
+
+func (s *defaultScopes) cond(tableName string) builder.Cond {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.conds[tableName]
+}
+
+// deletedConds represents the custom conditions of the soft deleted records of the tables
+type deletedConds struct {
+	mutex sync.RWMutex
+	conds map[string]func(col *schemas.Column) builder.Cond
+}
+
+func (s *deletedConds) set(tableName string, cond func(col *schemas.Column) builder.Cond) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conds == nil {
+		s.conds = make(map[string]func(col *schemas.Column) builder.Cond)
+	}
+	if cond == nil {
+		delete(s.conds, tableName)
+		return
+	}
+	s.conds[tableName] = cond
+}
+
+func (s *deletedConds) cond(tableName string) func(col *schemas.Column) builder.Cond {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.conds[tableName]
+}
+
+// AddDefaultScope registers a default condition of a table, the bean could be a struct or
+// a table name. The conditions will be applied to Find, Get, Count, Exist, Iterate, Update
+// and Delete of the table automatically unless Unscoped() is used, just like the "deleted" tag.
+//
+//	engine.AddDefaultScope(new(Post), builder.Eq{"published": true})
+func (engine *Engine) AddDefaultScope(bean interface{}, cond builder.Cond) {
+	tableName := dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean, true)
+	engine.defaultScopes.add(tableName, cond)
+}
+
+// SetDeletedCond replaces the condition of the records which are not soft deleted of a table
+// with the "deleted" column, the bean could be a struct or a table name and the column is the
+// "deleted" column. Delete still soft deletes the records by setting the "deleted" column, the
+// condition only changes how they are filtered out. A nil cond restores the default condition.
+// Use AddDefaultScope for the tables without a "deleted" column.
+//
+//	engine.SetDeletedCond(new(Order), func(col *schemas.Column) builder.Cond {
+//		return builder.IsNull{col.Name}.And(builder.Eq{"purged": 0})
+//	})
+func (engine *Engine) SetDeletedCond(bean interface{}, cond func(col *schemas.Column) builder.Cond) {
+	tableName := dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean, true)
+	engine.deletedConds.set(tableName, cond)
+}
+
+// SetDeletedCond sets the soft deleted condition of a table for all the engines of the group
+func (eg *EngineGroup) SetDeletedCond(bean interface{}, cond func(col *schemas.Column) builder.Cond) {
+	eg.Engine.SetDeletedCond(bean, cond)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetDeletedCond(bean, cond)
+	}
+}
+
+// AddDefaultScope registers a default condition of a table for all the engines of the group
+func (eg *EngineGroup) AddDefaultScope(bean interface{}, cond builder.Cond) {
+	eg.Engine.AddDefaultScope(bean, cond)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].AddDefaultScope(bean, cond)
+	}
+}
+
+// DefineScope registers a named scope which could be applied to sessions by Scope,
+// it's useful to share common filters, i.e. tenant or published state, between repositories.
+// Defining a scope with an existing name will replace it.
+//
+//	engine.DefineScope("active", func(s *xorm.Session) *xorm.Session {
+//		return s.Where("status = ?", "active")
+//	})
+//	engine.Scope("active").Find(&users)
+func (engine *Engine) DefineScope(name string, scope func(*Session) *Session) {
+	engine.scopes.define(name, scope)
+}
+
+// DefineScope registers a named scope for all the engines of the group
+func (eg *EngineGroup) DefineScope(name string, scope func(*Session) *Session) {
+	eg.Engine.DefineScope(name, scope)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].DefineScope(name, scope)
+	}
+}
+
+// Apply applies the scopes to the session in order
+func (engine *Engine) Apply(scopes ...func(*Session) *Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Apply(scopes...)
+}
+
+// Scope applies the named scopes which defined by DefineScope to the session in order
+func (engine *Engine) Scope(names ...string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Scope(names...)
+}
+
+// Apply applies the scopes to the session in order, a scope could add conditions,
+// joins, orders and etc.
+func (session *Session) Apply(scopes ...func(*Session) *Session) *Session {
+	session.guardStatement()
+	for _, scope := range scopes {
+		if scope == nil {
+			continue
+		}
+		if s := scope(session); s != nil {
+			session = s
+		}
+	}
+	return session
+}
+
+// Scope applies the named scopes which defined by Engine.DefineScope to the session in order,
+// the session will fail with ErrUnknownScope if a scope is not defined
+func (session *Session) Scope(names ...string) *Session {
+	session.guardStatement()
+	for _, name := range names {
+		scope, ok := session.engine.scopes.get(name)
+		if !ok {
+			session.statement.LastError = fmt.Errorf("%w: %q", ErrUnknownScope, name)
+			return session
+		}
+		session = session.Apply(scope)
+	}
+	return session
+}
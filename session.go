@@ -16,10 +16,14 @@ import (
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/imkos/xorm/contexts"
 	"github.com/imkos/xorm/convert"
 	"github.com/imkos/xorm/core"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/internal/json"
 	"github.com/imkos/xorm/internal/statements"
 	"github.com/imkos/xorm/log"
@@ -58,12 +62,16 @@ const (
 type Session struct {
 	engine                 *Engine
 	tx                     *core.Tx
+	conn                   *sharedConn
 	statement              *statements.Statement
 	isAutoCommit           bool
 	isCommitedOrRollbacked bool
 	isAutoClose            bool
 	isClosed               bool
 	prepareStmt            bool
+	// the transaction is shared with the session which this session is cloned from,
+	// it will not be rolled back when this session is closed
+	isTxShared bool
 	// Automatically reset the statement after operations that execute a SQL
 	// query such as Count(), Find(), Get(), ...
 	autoResetStatement bool
@@ -83,9 +91,44 @@ type Session struct {
 
 	lastSQL     string
 	lastSQLArgs []interface{}
+	stats       SessionStats
+
+	// the last query selects all the columns of the struct, it's recorded before the statement
+	// is reset so that the strict mode could report the struct fields without a column
+	allColumnsQueried bool
 
 	ctx         context.Context
 	sessionType sessionType
+
+	// the cancel function of the context with the max session lifetime,
+	// it will be released when the session closed
+	lifetimeCancel context.CancelFunc
+
+	// the context of NewSessionContext, the session is closed by the next call after it's done
+	guardCtx context.Context
+
+	// the functions called after the transaction is committed, i.e. the invalidations of the
+	// caches shared with the other sessions which could not see the changes before committing
+	afterCommitFuncs []func()
+
+	// the sequence to generate the names of the processor savepoints, and whether the before
+	// processors are running, so that their panics could be told from the others
+	savepointSeq int
+	inProcessors bool
+
+	// the routing of the reads, whether the session has written and the replication position
+	// after the last write for a group session
+	readRoute  Route
+	queryRoute Route
+	hasWritten bool
+	position   string
+
+	// the goroutine which is executing on the session and the depth of its nested calls,
+	// they are only recorded if the session guard is enabled
+	guardOwner atomic.Int64
+	guardDepth int32
+	// the concurrent use found by the statement methods which is returned by the next executing method
+	guardErr atomic.Pointer[ErrConcurrentSessionUse]
 }
 
 func newSessionID() string {
@@ -100,6 +143,8 @@ func newSessionID() string {
 }
 
 func newSession(engine *Engine) *Session {
+	engine.detectVersion()
+
 	var ctx context.Context
 	if engine.logSessionID {
 		ctx = context.WithValue(engine.defaultContext, log.SessionIDKey, newSessionID())
@@ -137,6 +182,8 @@ func newSession(engine *Engine) *Session {
 
 		sessionType: engineSession,
 	}
+	session.statement.SetDefaultScopes(engine.defaultScopes.cond)
+	session.statement.SetDeletedConds(engine.deletedConds.cond)
 	if engine.logSessionID {
 		session.ctx = context.WithValue(session.ctx, log.SessionKey, session)
 	}
@@ -157,15 +204,27 @@ func (session *Session) Close() error {
 		}
 	}
 
+	if session.lifetimeCancel != nil {
+		session.lifetimeCancel()
+		session.lifetimeCancel = nil
+	}
+
 	if !session.isClosed {
 		// When Close be called, if session is a transaction and do not call
 		// Commit or Rollback, then call Rollback.
-		if session.tx != nil && !session.isCommitedOrRollbacked {
-			if err := session.Rollback(); err != nil {
+		if session.tx != nil && !session.isCommitedOrRollbacked && !session.isTxShared {
+			// the transaction maybe rolled back already when its context is done
+			if err := session.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
 				return err
 			}
 		}
 		session.tx = nil
+		if session.conn != nil && session.conn.refs.Add(-1) == 0 {
+			if err := session.conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) {
+				return err
+			}
+		}
+		session.conn = nil
 		session.stmtCache = nil
 		session.txStmtCache = nil
 		session.isClosed = true
@@ -174,7 +233,7 @@ func (session *Session) Close() error {
 }
 
 func (session *Session) db() *core.DB {
-	return session.engine.db
+	return session.engine.DB()
 }
 
 // Engine returns session Engine
@@ -187,15 +246,76 @@ func (session *Session) Tx() *core.Tx {
 	return session.tx
 }
 
+// Conn returns the dedicated connection of UseSingleConn, it's nil if the session executes
+// on the pool
+func (session *Session) Conn() *core.Conn {
+	if session.conn == nil {
+		return nil
+	}
+	return session.conn.Conn
+}
+
+// sharedConn is the dedicated connection of UseSingleConn shared by the session and its clones,
+// it's returned to the pool when all of them are closed
+type sharedConn struct {
+	*core.Conn
+	refs atomic.Int32
+}
+
+// dbConn is the part of core.DB and core.Conn which executes the SQLs out of transactions
+type dbConn interface {
+	core.QueryExecuter
+	PrepareContext(ctx context.Context, query string) (*core.Stmt, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*core.Tx, error)
+}
+
+// dbConn returns the dedicated connection if UseSingleConn is called or the pool
+func (session *Session) dbConn() dbConn {
+	if session.conn != nil {
+		return session.conn
+	}
+	return session.db()
+}
+
 func (session *Session) getQueryer() core.Queryer {
 	if session.tx != nil {
 		return session.tx
 	}
-	return session.db()
+	return session.dbConn()
+}
+
+// UseSingleConn acquires a dedicated connection from the pool and executes all the SQLs of
+// the session on it until the session is closed, so that the temporary tables, the session
+// variables and the advisory locks are visible to the following SQLs. The sessions cloned from
+// the session share the connection, which is returned to the pool when the session and all its
+// clones are closed. It should be called before Begin.
+func (session *Session) UseSingleConn() error {
+	if session.conn != nil {
+		return nil
+	}
+	if !session.isAutoCommit {
+		return errors.New("UseSingleConn should be called before Begin")
+	}
+	conn, err := session.DB().SingleConn(session.ctx)
+	if err != nil {
+		return err
+	}
+	// the statements prepared on the pool may run on the other connections
+	for crc, stmt := range session.stmtCache {
+		if err := stmt.Close(); err != nil {
+			conn.Close()
+			return err
+		}
+		delete(session.stmtCache, crc)
+	}
+	session.conn = &sharedConn{Conn: conn}
+	session.conn.refs.Store(1)
+	return nil
 }
 
 // ContextCache enable context cache or not
 func (session *Session) ContextCache(context contexts.ContextCache) *Session {
+	session.guardStatement()
 	session.statement.SetContextCache(context)
 	return session
 }
@@ -209,17 +329,48 @@ func (session *Session) resetStatement() {
 	if session.autoResetStatement {
 		session.statement.Reset()
 		session.prepareStmt = false
+		session.queryRoute = RouteAuto
+	}
+}
+
+// Clone returns a new session which shares the engine, the transaction and the context with
+// this session but has a copy of the statement built so far, so that a base query could be
+// branched into different queries, i.e. a Count and a Find with Limit. The transaction is
+// still managed by this session, the new session should not commit or roll back it and it
+// will not be rolled back when the new session is closed. The connection of UseSingleConn is
+// shared too, it's returned to the pool after both sessions are closed.
+func (session *Session) Clone() *Session {
+	newSession := newSession(session.engine)
+	newSession.ctx = session.ctx
+	newSession.tx = session.tx
+	newSession.isAutoCommit = session.isAutoCommit
+	newSession.isCommitedOrRollbacked = session.isCommitedOrRollbacked
+	newSession.isTxShared = session.tx != nil
+	if session.conn != nil {
+		session.conn.refs.Add(1)
+		newSession.conn = session.conn
 	}
+	newSession.isAutoClose = session.isAutoClose
+	newSession.prepareStmt = session.prepareStmt
+	newSession.autoResetStatement = session.autoResetStatement
+	newSession.sessionType = session.sessionType
+	newSession.readRoute = session.readRoute
+	newSession.hasWritten = session.hasWritten
+	newSession.position = session.position
+	newSession.statement = session.statement.Clone()
+	return newSession
 }
 
 // Prepare set a flag to session that should be prepare statement before execute query
 func (session *Session) Prepare() *Session {
+	session.guardStatement()
 	session.prepareStmt = true
 	return session
 }
 
 // Before Apply before Processor, affected bean is passed to closure arg
 func (session *Session) Before(closures func(interface{})) *Session {
+	session.guardStatement()
 	if closures != nil {
 		session.beforeClosures = append(session.beforeClosures, closures)
 	}
@@ -228,6 +379,7 @@ func (session *Session) Before(closures func(interface{})) *Session {
 
 // After Apply after Processor, affected bean is passed to closure arg
 func (session *Session) After(closures func(interface{})) *Session {
+	session.guardStatement()
 	if closures != nil {
 		session.afterClosures = append(session.afterClosures, closures)
 	}
@@ -236,6 +388,17 @@ func (session *Session) After(closures func(interface{})) *Session {
 
 // Table can input a string or pointer to struct for special a table to operate.
 func (session *Session) Table(tableNameOrBean interface{}) *Session {
+	session.guardStatement()
+	switch t := tableNameOrBean.(type) {
+	case string:
+		if !session.auditIdentifiers("table", t) {
+			return session
+		}
+	case []string:
+		if !session.auditIdentifiers("table", t...) {
+			return session
+		}
+	}
 	if err := session.statement.SetTable(tableNameOrBean); err != nil {
 		session.statement.LastError = err
 	}
@@ -244,30 +407,46 @@ func (session *Session) Table(tableNameOrBean interface{}) *Session {
 
 // Alias set the table alias
 func (session *Session) Alias(alias string) *Session {
+	session.guardStatement()
 	session.statement.Alias(alias)
 	return session
 }
 
+// Schema sets the schema of the table for the statement chain instead of the default schema
+// of the engine, the table names qualified by a schema are not changed
+func (session *Session) Schema(schema string) *Session {
+	session.guardStatement()
+	if !session.auditIdentifiers("schema", schema) {
+		return session
+	}
+	session.statement.Schema(schema)
+	return session
+}
+
 // NoCascade indicate that no cascade load child object
 func (session *Session) NoCascade() *Session {
+	session.guardStatement()
 	session.statement.UseCascade = false
 	return session
 }
 
 // ForUpdate Set Read/Write locking for UPDATE
 func (session *Session) ForUpdate() *Session {
+	session.guardStatement()
 	session.statement.IsForUpdate = true
 	return session
 }
 
 // NoAutoCondition disable generate SQL condition from beans
 func (session *Session) NoAutoCondition(no ...bool) *Session {
+	session.guardStatement()
 	session.statement.SetNoAutoCondition(no...)
 	return session
 }
 
 // Limit provide limit and offset query condition
 func (session *Session) Limit(limit int, start ...int) *Session {
+	session.guardStatement()
 	session.statement.Limit(limit, start...)
 	return session
 }
@@ -275,36 +454,118 @@ func (session *Session) Limit(limit int, start ...int) *Session {
 // OrderBy provide order by query condition, the input parameter is the content
 // after order by on a sql statement.
 func (session *Session) OrderBy(order interface{}, args ...interface{}) *Session {
+	session.guardStatement()
+	// an order by clause with args is an expression but not identifiers
+	if s, ok := order.(string); ok && len(args) == 0 && !session.auditIdentifiers("order by", s) {
+		return session
+	}
 	session.statement.OrderBy(order, args...)
 	return session
 }
 
+// OrderBySafe provide order by query condition from user input, i.e. a sort parameter of a
+// HTTP API. The input is a comma separated list of sort keys, a key could be prefixed by "-"
+// or "+", or followed by "desc" or "asc" to specify the direction, i.e. "-created,name asc".
+// Every key must be in allowed which maps the key to a column name with an optional default
+// direction, i.e. map[string]string{"created": "created_at DESC", "name": "name"},
+// otherwise ErrUnknownSortKey will be returned. If allowed is nil, the keys are the columns of
+// the table specified by Table.
+func (session *Session) OrderBySafe(userInput string, allowed map[string]string) *Session {
+	session.guardStatement()
+	if allowed == nil && strings.TrimSpace(userInput) != "" {
+		table := session.statement.RefTable
+		if table == nil {
+			session.statement.LastError = fmt.Errorf("%w: the allowed keys are required if the table is not specified by Table", ErrTableNotFound)
+			return session
+		}
+		allowed = make(map[string]string, len(table.Columns()))
+		for _, col := range table.Columns() {
+			allowed[col.Name] = col.Name
+		}
+	}
+	unknown := func(key string) *Session {
+		session.statement.LastError = fmt.Errorf("%w: %q", ErrUnknownSortKey, key)
+		return session
+	}
+	for _, part := range strings.Split(userInput, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var direction string
+		switch part[0] {
+		case '-':
+			direction, part = "DESC", part[1:]
+		case '+':
+			direction, part = "ASC", part[1:]
+		}
+		fields := strings.Fields(part)
+		if len(fields) == 2 && direction == "" {
+			direction = strings.ToUpper(fields[1])
+			if direction != "ASC" && direction != "DESC" {
+				return unknown(part)
+			}
+			fields = fields[:1]
+		}
+		if len(fields) != 1 {
+			return unknown(part)
+		}
+
+		column, ok := allowed[fields[0]]
+		colFields := strings.Fields(column)
+		if !ok || len(colFields) == 0 {
+			return unknown(fields[0])
+		}
+		if direction == "" && len(colFields) > 1 {
+			direction = strings.ToUpper(colFields[1])
+		}
+		if direction == "DESC" {
+			session.statement.Desc(colFields[0])
+		} else {
+			session.statement.Asc(colFields[0])
+		}
+	}
+	return session
+}
+
 // Desc provide desc order by query condition, the input parameters are columns.
 func (session *Session) Desc(colNames ...string) *Session {
+	session.guardStatement()
+	if !session.auditIdentifiers("column", colNames...) {
+		return session
+	}
 	session.statement.Desc(colNames...)
 	return session
 }
 
 // Asc provide asc order by query condition, the input parameters are columns.
 func (session *Session) Asc(colNames ...string) *Session {
+	session.guardStatement()
+	if !session.auditIdentifiers("column", colNames...) {
+		return session
+	}
 	session.statement.Asc(colNames...)
 	return session
 }
 
 // StoreEngine is only avialble mysql dialect currently
 func (session *Session) StoreEngine(storeEngine string) *Session {
+	session.guardStatement()
 	session.statement.StoreEngine = storeEngine
 	return session
 }
 
 // Charset is only avialble mysql dialect currently
 func (session *Session) Charset(charset string) *Session {
+	session.guardStatement()
 	session.statement.Charset = charset
 	return session
 }
 
 // Cascade indicates if loading sub Struct
 func (session *Session) Cascade(trueOrFalse ...bool) *Session {
+	session.guardStatement()
 	if len(trueOrFalse) >= 1 {
 		session.statement.UseCascade = trueOrFalse[0]
 	}
@@ -313,6 +574,7 @@ func (session *Session) Cascade(trueOrFalse ...bool) *Session {
 
 // MustLogSQL means record SQL or not and don't follow engine's setting
 func (session *Session) MustLogSQL(logs ...bool) *Session {
+	session.guardStatement()
 	showSQL := true
 	if len(logs) > 0 {
 		showSQL = logs[0]
@@ -324,25 +586,64 @@ func (session *Session) MustLogSQL(logs ...bool) *Session {
 // NoCache ask this session do not retrieve data from cache system and
 // get data from database directly.
 func (session *Session) NoCache() *Session {
+	session.guardStatement()
 	session.statement.UseCache = false
 	return session
 }
 
+// NoReflectCache ask this session do not use the cached table information of the structs but
+// parse them every time, it's useful for the tables whose shape can change between calls,
+// i.e. temporary tables or per-tenant tables created by DDL at runtime.
+func (session *Session) NoReflectCache() *Session {
+	session.guardStatement()
+	session.statement.NoReflectCache = true
+	return session
+}
+
 // Join join_operator should be one of INNER, LEFT OUTER, CROSS etc - this will be prepended to JOIN
 func (session *Session) Join(joinOperator string, tablename interface{}, condition interface{}, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.Join(joinOperator, tablename, condition, args...)
 	return session
 }
 
-// GroupBy Generate Group By statement
-func (session *Session) GroupBy(keys string) *Session {
-	session.statement.GroupBy(keys)
+// JoinLateral adds a lateral join of the subquery with the alias, the subquery could reference
+// the columns of the preceding tables. join_operator should be one of INNER, LEFT and CROSS,
+// the condition could be nil. It's supported by postgres, mysql 8.0.14+, oracle 12c+ and mssql.
+func (session *Session) JoinLateral(joinOperator string, subQuery interface{}, alias string, condition interface{}, args ...interface{}) *Session {
+	session.guardStatement()
+	session.statement.JoinLateral(joinOperator, subQuery, alias, condition, args...)
 	return session
 }
 
-// Having Generate Having statement
-func (session *Session) Having(conditions string) *Session {
-	session.statement.Having(conditions)
+// GroupBy Generate Group By statement, the keys could be columns or builder.Cond expressions
+func (session *Session) GroupBy(keys ...interface{}) *Session {
+	session.guardStatement()
+	session.statement.GroupBy(keys...)
+	return session
+}
+
+// Having Generate Having statement, the conditions could be a string with args or a builder.Cond
+func (session *Session) Having(conditions interface{}, args ...interface{}) *Session {
+	session.guardStatement()
+	session.statement.Having(conditions, args...)
+	return session
+}
+
+// Qualify Generate Qualify statement which filters the results of the window functions,
+// it's only supported by the databases like snowflake
+func (session *Session) Qualify(conditions string) *Session {
+	session.guardStatement()
+	session.statement.Qualify(conditions)
+	return session
+}
+
+// Hint adds the optimizer hints of the query, they are written as "/*+ ... */" after SELECT for
+// mysql, oracle, postgres (pg_hint_plan) and dameng, as "OPTION (...)" for mssql and as
+// "WITH HINT (...)" for hana, i.e. Hint("MAX_EXECUTION_TIME(1000)") or Hint("RECOMPILE")
+func (session *Session) Hint(hints ...string) *Session {
+	session.guardStatement()
+	session.statement.Hint(hints...)
 	return session
 }
 
@@ -364,7 +665,7 @@ func (session *Session) canCache() bool {
 	return true
 }
 
-func (session *Session) doPrepare(db *core.DB, sqlStr string) (stmt *core.Stmt, err error) {
+func (session *Session) doPrepare(db dbConn, sqlStr string) (stmt *core.Stmt, err error) {
 	crc := crc32.ChecksumIEEE([]byte(sqlStr))
 	// TODO try hash(sqlStr+len(sqlStr))
 	var has bool
@@ -533,7 +834,47 @@ func asKind(vv reflect.Value, tp reflect.Type) (interface{}, error) {
 	return nil, fmt.Errorf("unsupported primary key type: %v, %v", tp, vv)
 }
 
-var uint8ZeroValue = reflect.ValueOf(uint8(0))
+var (
+	uint8ZeroValue = reflect.ValueOf(uint8(0))
+	geometryType   = reflect.TypeOf((*geo.Geometry)(nil)).Elem()
+)
+
+// asBitSet converts the scan result of a bit set column which is a bit string on PostgreSQL,
+// the big endian bytes of BIT(n) on MySQL and an integer on the other databases
+func (session *Session) asBitSet(col *schemas.Column, scanResult interface{}) (convert.BitSet, error) {
+	n := int(col.Length)
+	switch session.engine.dialect.URI().DBType {
+	case schemas.POSTGRES:
+		data, ok := convert.AsBytes(scanResult)
+		if !ok {
+			return convert.BitSet{}, fmt.Errorf("cannot convert %#v as bit string", scanResult)
+		}
+		b, err := convert.ParseBitSet(string(data))
+		if err != nil {
+			return convert.BitSet{}, err
+		}
+		if b.Len() != n {
+			return convert.BitSet{}, fmt.Errorf("column %s needs %d bits but got %d bits", col.Name, n, b.Len())
+		}
+		return b, nil
+	case schemas.MYSQL:
+		data, ok := convert.AsBytes(scanResult)
+		if !ok || len(data) > 8 {
+			return convert.BitSet{}, fmt.Errorf("cannot convert %#v as bit set", scanResult)
+		}
+		var v uint64
+		for _, c := range data {
+			v = v<<8 | uint64(c)
+		}
+		return convert.BitSetFromUint64(v, n), nil
+	}
+
+	v, err := convert.AsInt64(scanResult)
+	if err != nil {
+		return convert.BitSet{}, err
+	}
+	return convert.BitSetFromUint64(uint64(v), n), nil
+}
 
 func (session *Session) convertBeanField(col *schemas.Column, fieldValue *reflect.Value,
 	scanResult interface{}, table *schemas.Table,
@@ -546,6 +887,24 @@ func (session *Session) convertBeanField(col *schemas.Column, fieldValue *reflec
 		return nil
 	}
 
+	if tp := fieldValue.Type(); (tp.Kind() == reflect.Ptr && tp.Implements(geometryType)) ||
+		(tp.Kind() != reflect.Interface && reflect.PtrTo(tp).Implements(geometryType)) {
+		data, ok := convert.AsBytes(scanResult)
+		if !ok {
+			return fmt.Errorf("cannot convert %#v as bytes", scanResult)
+		}
+		if data == nil {
+			return nil
+		}
+		if tp.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(tp.Elem()))
+			}
+			return geo.Decode(data, fieldValue.Interface().(geo.Geometry))
+		}
+		return geo.Decode(data, fieldValue.Addr().Interface().(geo.Geometry))
+	}
+
 	if fieldValue.CanAddr() {
 		if structConvert, ok := fieldValue.Addr().Interface().(convert.Conversion); ok {
 			data, ok := convert.AsBytes(scanResult)
@@ -582,6 +941,32 @@ func (session *Session) convertBeanField(col *schemas.Column, fieldValue *reflec
 		return setJSON(fieldValue, fieldType, scanResult)
 	}
 
+	if col.IsDuration && fieldType.Kind() == reflect.Int64 {
+		d, err := convert.AsDuration(scanResult, col.DurationUnit)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(int64(d))
+		return nil
+	}
+
+	if (col.SQLType.Name == schemas.BitSet || col.SQLType.Name == schemas.VarBit) &&
+		(fieldType == schemas.BitSetType || fieldType == schemas.BytesType) {
+		if isNullScanResult(scanResult) {
+			return nil
+		}
+		b, err := session.asBitSet(col, scanResult)
+		if err != nil {
+			return err
+		}
+		if fieldType == schemas.BytesType {
+			fieldValue.SetBytes(b.Bytes())
+		} else {
+			fieldValue.Set(reflect.ValueOf(b))
+		}
+		return nil
+	}
+
 	switch fieldType.Kind() {
 	case reflect.Ptr:
 		var e reflect.Value
@@ -639,6 +1024,23 @@ func (session *Session) convertBeanField(col *schemas.Column, fieldValue *reflec
 			return nil
 		}
 	case reflect.Struct:
+		switch fieldType {
+		case schemas.NetipAddrType:
+			addr, err := convert.AsAddr(scanResult)
+			if err != nil {
+				return err
+			}
+			fieldValue.Set(reflect.ValueOf(addr))
+			return nil
+		case schemas.NetipPrefixType:
+			prefix, err := convert.AsPrefix(scanResult)
+			if err != nil {
+				return err
+			}
+			fieldValue.Set(reflect.ValueOf(prefix))
+			return nil
+		}
+
 		if fieldType.ConvertibleTo(schemas.BigFloatType) {
 			v, err := convert.AsBigFloat(scanResult)
 			if err != nil {
@@ -668,7 +1070,7 @@ func (session *Session) convertBeanField(col *schemas.Column, fieldValue *reflec
 			}
 			session.engine.logger.Errorf("sql.Sanner error: %v", err)
 		} else if session.statement.UseCascade {
-			table, err := session.engine.tagParser.ParseWithCache(*fieldValue)
+			table, err := session.statement.ParseTable(*fieldValue)
 			if err != nil {
 				return err
 			}
@@ -687,7 +1089,7 @@ func (session *Session) convertBeanField(col *schemas.Column, fieldValue *reflec
 				// however, also need to consider adding a 'lazy' attribute to xorm tag which allow hasOne
 				// property to be fetched lazily
 				structInter := reflect.New(fieldValue.Type())
-				has, err := session.ID(pk).NoCascade().get(structInter.Interface())
+				has, err := session.ID(pk).NoCascade().get(false, structInter.Interface())
 				if err != nil {
 					return err
 				}
@@ -709,7 +1111,7 @@ func (session *Session) slice2Bean(scanResults []interface{}, columnsSchema *Col
 		executeAfterSet(bean, fields, scanResults)
 	}()
 
-	buildAfterProcessors(session, bean)
+	buildAfterProcessors(session, bean, table, fields)
 
 	var pk schemas.PK
 	for i, field := range columnsSchema.Fields {
@@ -745,8 +1147,21 @@ func (session *Session) LastSQL() (string, []interface{}) {
 	return session.lastSQL, session.lastSQLArgs
 }
 
+// SessionStats represents the cumulative statistics of the statements executed by a session
+type SessionStats struct {
+	Queries      int64         // the number of the statements sent to the database
+	RowsAffected int64         // the total rows affected by the executed statements
+	Duration     time.Duration // the total time spent on executing the statements
+}
+
+// Stats returns the cumulative statistics of the statements executed by the session
+func (session *Session) Stats() SessionStats {
+	return session.stats
+}
+
 // Unscoped always disable struct tag "deleted"
 func (session *Session) Unscoped() *Session {
+	session.guardStatement()
 	session.statement.SetUnscoped()
 	return session
 }
@@ -777,6 +1192,10 @@ func (session *Session) PingContext(ctx context.Context) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	session.engine.logger.Infof("PING DATABASE %v", session.engine.DriverName())
 	return session.DB().PingContext(ctx)
@@ -784,6 +1203,7 @@ func (session *Session) PingContext(ctx context.Context) error {
 
 // disable version check
 func (session *Session) NoVersionCheck() *Session {
+	session.guardStatement()
 	session.statement.CheckVersion = false
 	return session
 }
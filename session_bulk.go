@@ -0,0 +1,307 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/imkos/xorm/internal/utils"
+	"github.com/imkos/xorm/schemas"
+)
+
+// BulkOptions represents the options of BulkInsert
+type BulkOptions struct {
+	// BatchSize is the number of records of one multiple values INSERT when
+	// falling back to INSERT statements, default is 1000
+	BatchSize int
+	// RegisterReaderHandler registers a reader for MySQL LOAD DATA LOCAL INFILE,
+	// it should be mysql.RegisterReaderHandler of github.com/go-sql-driver/mysql.
+	// LOAD DATA will not be used if it's nil.
+	RegisterReaderHandler func(name string, handler func() io.Reader)
+	// DeregisterReaderHandler should be mysql.DeregisterReaderHandler
+	DeregisterReaderHandler func(name string)
+	// NoCopy always uses INSERT statements even if the driver supports a bulk loader
+	NoCopy bool
+}
+
+const defaultBulkBatchSize = 1000
+
+var bulkReaderSeq uint64
+
+// BulkInsert inserts a slice of beans in one transaction with the fastest way the driver
+// supports. It uses COPY FROM STDIN with the lib/pq driver of Postgres, LOAD DATA LOCAL
+// INFILE with the MySQL driver when opts.RegisterReaderHandler is given, and falls back
+// to multiple values INSERT statements of opts.BatchSize records otherwise.
+// Note that the auto increment ids will not be set back to the beans and the after
+// insert processors will not be called when COPY or LOAD DATA is used.
+func (session *Session) BulkInsert(beans interface{}, opts BulkOptions) (int64, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
+
+	session.autoResetStatement = false
+	defer func() {
+		session.autoResetStatement = true
+		session.resetStatement()
+	}()
+
+	if session.statement.LastError != nil {
+		return 0, session.statement.LastError
+	}
+
+	sliceValue := reflect.Indirect(reflect.ValueOf(beans))
+	if sliceValue.Kind() != reflect.Slice {
+		return 0, errors.New("needs a slice or a pointer to a slice")
+	}
+	if sliceValue.Len() == 0 {
+		return 0, ErrNoElementsOnSlice
+	}
+
+	// wrap the beans in one transaction if the session is not in a transaction
+	began := session.isAutoCommit
+	if began {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+	}
+
+	var (
+		affected int64
+		err      error
+	)
+	switch {
+	case !opts.NoCopy && session.engine.driverName == "postgres":
+		affected, err = session.bulkCopy(sliceValue)
+	case !opts.NoCopy && session.engine.driverName == "mysql" && opts.RegisterReaderHandler != nil:
+		affected, err = session.bulkLoadData(sliceValue, opts)
+	default:
+		affected, err = session.bulkInsertBatches(sliceValue, opts.BatchSize)
+	}
+	if err != nil {
+		if began {
+			_ = session.Rollback()
+		}
+		return 0, err
+	}
+
+	if began {
+		if err := session.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return affected, nil
+}
+
+// bulkInsertBatches inserts the beans by multiple values INSERT statements
+func (session *Session) bulkInsertBatches(sliceValue reflect.Value, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultBulkBatchSize
+	}
+	var affected int64
+	for start := 0; start < sliceValue.Len(); start += batchSize {
+		end := start + batchSize
+		if end > sliceValue.Len() {
+			end = sliceValue.Len()
+		}
+		cnt, err := session.insertMultipleStruct(sliceValue.Slice(start, end).Interface())
+		if err != nil {
+			return affected, err
+		}
+		affected += cnt
+	}
+	return affected, nil
+}
+
+// bulkRows converts the beans to the rows of column values
+func (session *Session) bulkRows(sliceValue reflect.Value) (string, []string, [][]interface{}, error) {
+	if err := session.statement.SetRefBean(sliceValue.Index(0).Interface()); err != nil {
+		return "", nil, nil, err
+	}
+	tableName := session.statement.TableName()
+	if len(tableName) == 0 {
+		return "", nil, nil, ErrTableNotFound
+	}
+	table := session.statement.RefTable
+
+	var cols []*schemas.Column
+	first := reflect.Indirect(sliceValue.Index(0))
+	for _, col := range table.Columns() {
+		if col.MapType == schemas.ONLYFROMDB || col.IsDeleted {
+			continue
+		}
+		if session.statement.OmitColumnMap.Contain(col.Name) {
+			continue
+		}
+		if len(session.statement.ColumnMap) > 0 && !session.statement.ColumnMap.Contain(col.Name) {
+			continue
+		}
+		if col.IsAutoIncrement {
+			fieldValue, err := col.ValueOfV(&first)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			if utils.IsZero(fieldValue.Interface()) {
+				continue
+			}
+		}
+		cols = append(cols, col)
+	}
+
+	colNames := make([]string, 0, len(cols))
+	for _, col := range cols {
+		colNames = append(colNames, col.Name)
+	}
+
+	rows := make([][]interface{}, 0, sliceValue.Len())
+	for i := 0; i < sliceValue.Len(); i++ {
+		elemValue := sliceValue.Index(i).Interface()
+		if processor, ok := elemValue.(BeforeInsertProcessor); ok {
+			processor.BeforeInsert()
+		}
+		if err := session.validateElem(sliceValue.Index(i)); err != nil {
+			return "", nil, nil, err
+		}
+
+		vv := reflect.Indirect(sliceValue.Index(i))
+		row := make([]interface{}, 0, len(cols))
+		for _, col := range cols {
+			if (col.IsCreated || col.IsUpdated) && session.statement.UseAutoTime {
+				val, _, err := session.nowTime(col)
+				if err != nil {
+					return "", nil, nil, err
+				}
+				row = append(row, val)
+				continue
+			}
+			if col.IsVersion && session.statement.CheckVersion {
+				row = append(row, 1)
+				continue
+			}
+			fieldValue, err := col.ValueOfV(&vv)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			arg, err := session.statement.Value2Interface(col, *fieldValue)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			row = append(row, arg)
+		}
+		rows = append(rows, row)
+	}
+	return tableName, colNames, rows, nil
+}
+
+// bulkCopy inserts the beans via Postgres COPY FROM STDIN which is supported by lib/pq
+func (session *Session) bulkCopy(sliceValue reflect.Value) (int64, error) {
+	tableName, colNames, rows, err := session.bulkRows(sliceValue)
+	if err != nil {
+		return 0, err
+	}
+
+	quoter := session.engine.dialect.Quoter()
+	copySQL := fmt.Sprintf("COPY %s (%s) FROM STDIN", quoter.Quote(tableName), quoter.Join(colNames, ", "))
+	session.saveLastSQL(copySQL)
+	stmt, err := session.tx.Tx.PrepareContext(session.ctx, copySQL)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(session.ctx, row...); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := stmt.ExecContext(session.ctx); err != nil {
+		return 0, err
+	}
+
+	_ = session.cacheInsert(tableName)
+	return int64(len(rows)), nil
+}
+
+// bulkLoadData inserts the beans via MySQL LOAD DATA LOCAL INFILE
+func (session *Session) bulkLoadData(sliceValue reflect.Value, opts BulkOptions) (int64, error) {
+	tableName, colNames, rows, err := session.bulkRows(sliceValue)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	for _, row := range rows {
+		for i, v := range row {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			writeLoadDataValue(&buf, v)
+		}
+		buf.WriteByte('\n')
+	}
+
+	name := fmt.Sprintf("xorm_bulk_%d", atomic.AddUint64(&bulkReaderSeq, 1))
+	opts.RegisterReaderHandler(name, func() io.Reader {
+		return bytes.NewReader(buf.Bytes())
+	})
+	if opts.DeregisterReaderHandler != nil {
+		defer opts.DeregisterReaderHandler(name)
+	}
+
+	quoter := session.engine.dialect.Quoter()
+	loadSQL := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 "+
+		"FIELDS TERMINATED BY '\\t' ESCAPED BY '\\\\' LINES TERMINATED BY '\\n' (%s)",
+		name, quoter.Quote(tableName), quoter.Join(colNames, ", "))
+	res, err := session.exec(loadSQL)
+	if err != nil {
+		return 0, err
+	}
+
+	_ = session.cacheInsert(tableName)
+	return res.RowsAffected()
+}
+
+var loadDataReplacer = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`, "\x00", `\0`)
+
+// writeLoadDataValue writes the value as a field of LOAD DATA's default text format
+func writeLoadDataValue(buf *bytes.Buffer, v interface{}) {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString(`\N`)
+	case bool:
+		if t {
+			buf.WriteByte('1')
+		} else {
+			buf.WriteByte('0')
+		}
+	case []byte:
+		if t == nil {
+			buf.WriteString(`\N`)
+			return
+		}
+		buf.WriteString(loadDataReplacer.Replace(string(t)))
+	case string:
+		buf.WriteString(loadDataReplacer.Replace(t))
+	case time.Time:
+		buf.WriteString(t.Format("2006-01-02 15:04:05.999999"))
+	case int64:
+		buf.WriteString(strconv.FormatInt(t, 10))
+	case float64:
+		buf.WriteString(strconv.FormatFloat(t, 'g', -1, 64))
+	default:
+		buf.WriteString(loadDataReplacer.Replace(fmt.Sprint(t)))
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/imkos/xorm/internal/utils"
+)
+
+// ChunkFunc only use by Chunk, batch is a pointer to a slice of beans
+type ChunkFunc func(batch interface{}) error
+
+// ChunkTxFunc only use by ChunkInTx, tx is a session in which a transaction has been began
+// and batch is a pointer to a slice of beans
+type ChunkTxFunc func(tx *Session, batch interface{}) error
+
+// Chunk pages through the records in primary key order and calls fn with at most size
+// records each time, bean's non-empty fields are conditions. Unlike Iterate with
+// BufferSize, the pages are located by the last primary key but not an offset, so it's
+// safe to modify the records in fn. The table should have exactly one primary key,
+// OrderBy and Limit of the session will be ignored.
+func (session *Session) Chunk(bean interface{}, size int, fn ChunkFunc) error {
+	return session.chunk(bean, size, fn)
+}
+
+// ChunkInTx is like Chunk, but every batch is handled in its own transaction on a new
+// session. The transaction will be committed if fn returns nil, or rolled back otherwise,
+// and the batches committed before will not be affected.
+func (session *Session) ChunkInTx(bean interface{}, size int, fn ChunkTxFunc) error {
+	return session.chunk(bean, size, func(batch interface{}) error {
+		tx := session.engine.NewSession().Context(session.ctx)
+		defer tx.Close()
+
+		if err := tx.Begin(); err != nil {
+			return err
+		}
+		if err := fn(tx, batch); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+func (session *Session) chunk(bean interface{}, size int, fn ChunkFunc) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+
+	session.autoResetStatement = false
+	defer func() {
+		session.autoResetStatement = true
+		session.resetStatement()
+	}()
+
+	if session.statement.LastError != nil {
+		return session.statement.LastError
+	}
+	if size <= 0 {
+		return errors.New("chunk size should be greater than 0")
+	}
+
+	if err := session.statement.SetRefBean(bean); err != nil {
+		return err
+	}
+	table := session.statement.RefTable
+	if len(table.PrimaryKeys) != 1 {
+		return ErrChunkNeedSinglePK
+	}
+	pkCol := table.PKColumns()[0]
+
+	// default scopes will be applied only once, so apply them before saving the conditions
+	session.statement.ApplyDefaultScope()
+	conds := session.statement.Conds()
+
+	sliceType := reflect.SliceOf(utils.ReflectValue(bean).Type())
+	var last interface{}
+	for {
+		if err := session.ctx.Err(); err != nil {
+			return err
+		}
+
+		session.statement.SetConds(conds)
+		if last != nil {
+			session.statement.SetConds(conds.And(session.statement.PKAfterCond(last)))
+		}
+		session.statement.ResetOrderBy()
+		session.statement.Start = 0
+
+		slice := reflect.New(sliceType)
+		if err := session.NoCache().Asc(pkCol.Name).Limit(size).find(slice.Interface(), bean); err != nil {
+			return err
+		}
+
+		n := slice.Elem().Len()
+		if n == 0 {
+			return nil
+		}
+
+		pkValue, err := pkCol.ValueOf(slice.Elem().Index(n - 1).Addr().Interface())
+		if err != nil {
+			return err
+		}
+		last = pkValue.Interface()
+
+		if err := fn(slice.Interface()); err != nil {
+			return err
+		}
+
+		if n < size {
+			return nil
+		}
+	}
+}
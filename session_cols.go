@@ -37,9 +37,34 @@ func setColumnTime(bean interface{}, col *schemas.Column, t time.Time) {
 		case reflect.Struct:
 			v.Set(reflect.ValueOf(t).Convert(v.Type()))
 		case reflect.Int, reflect.Int64, reflect.Int32:
-			v.SetInt(t.Unix())
+			v.SetInt(col.Epoch(t))
 		case reflect.Uint, reflect.Uint64, reflect.Uint32:
-			v.SetUint(uint64(t.Unix()))
+			v.SetUint(uint64(col.Epoch(t)))
+		}
+	}
+}
+
+// setColumnFlag sets the deleted flag field of the bean to true or 1
+func setColumnFlag(bean interface{}, col *schemas.Column) {
+	v, err := col.ValueOf(bean)
+	if err != nil {
+		return
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() && v.CanSet() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		elem := v.Elem()
+		v = &elem
+	}
+	if v.CanSet() {
+		switch v.Type().Kind() {
+		case reflect.Bool:
+			v.SetBool(true)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v.SetUint(1)
 		}
 	}
 }
@@ -65,42 +90,61 @@ func getFlagForColumn(m map[string]bool, col *schemas.Column) (val bool, has boo
 
 // Incr provides a query string like "count = count + 1"
 func (session *Session) Incr(column string, arg ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.Incr(column, arg...)
 	return session
 }
 
 // Decr provides a query string like "count = count - 1"
 func (session *Session) Decr(column string, arg ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.Decr(column, arg...)
 	return session
 }
 
 // SetExpr provides a query string like "column = {expression}"
 func (session *Session) SetExpr(column string, expression interface{}) *Session {
+	session.guardStatement()
 	session.statement.SetExpr(column, expression)
 	return session
 }
 
+// JSONSet provides a query string like "column = json_set(column, path, value)",
+// so that only the value at the path of the JSON column will be changed.
+// path is a dot separated list of keys and array indexes, i.e. "tags.0"
+func (session *Session) JSONSet(column, path string, value interface{}) *Session {
+	session.guardStatement()
+	session.statement.JSONSet(column, path, value)
+	return session
+}
+
 // Select provides some columns to special
 func (session *Session) Select(str string) *Session {
+	session.guardStatement()
 	session.statement.Select(str)
 	return session
 }
 
 // Cols provides some columns to special
 func (session *Session) Cols(columns ...string) *Session {
+	session.guardStatement()
+	if !session.auditIdentifiers("column", columns...) {
+		return session
+	}
 	session.statement.Cols(columns...)
 	return session
 }
 
 // AllCols ask all columns
 func (session *Session) AllCols() *Session {
+	session.guardStatement()
 	session.statement.AllCols()
 	return session
 }
 
 // MustCols specify some columns must use even if they are empty
 func (session *Session) MustCols(columns ...string) *Session {
+	session.guardStatement()
 	session.statement.MustCols(columns...)
 	return session
 }
@@ -111,6 +155,7 @@ func (session *Session) MustCols(columns ...string) *Session {
 // If no parameters, it will use all the bool field of struct, or
 // it will use parameters's columns
 func (session *Session) UseBool(columns ...string) *Session {
+	session.guardStatement()
 	session.statement.UseBool(columns...)
 	return session
 }
@@ -119,18 +164,24 @@ func (session *Session) UseBool(columns ...string) *Session {
 // distinct will not be cached because cache system need id,
 // but distinct will not provide id
 func (session *Session) Distinct(columns ...string) *Session {
+	session.guardStatement()
 	session.statement.Distinct(columns...)
 	return session
 }
 
 // Omit Only not use the parameters as select or update columns
 func (session *Session) Omit(columns ...string) *Session {
+	session.guardStatement()
+	if !session.auditIdentifiers("column", columns...) {
+		return session
+	}
 	session.statement.Omit(columns...)
 	return session
 }
 
 // Nullable Set null when column is zero-value and nullable for update
 func (session *Session) Nullable(columns ...string) *Session {
+	session.guardStatement()
 	session.statement.Nullable(columns...)
 	return session
 }
@@ -138,6 +189,24 @@ func (session *Session) Nullable(columns ...string) *Session {
 // NoAutoTime means do not automatically give created field and updated field
 // the current time on the current session temporarily
 func (session *Session) NoAutoTime() *Session {
+	session.guardStatement()
 	session.statement.UseAutoTime = false
 	return session
 }
+
+// WithTime sets the time of the created, updated and deleted columns for the next operation
+// instead of the engine's clock, i.e. to backfill historical data
+func (session *Session) WithTime(t time.Time) *Session {
+	session.guardStatement()
+	session.statement.AutoTime = t
+	return session
+}
+
+// nowTime returns the value of the auto time column of the time set by WithTime or the
+// current time of the engine's clock
+func (session *Session) nowTime(col *schemas.Column) (interface{}, time.Time, error) {
+	if !session.statement.AutoTime.IsZero() {
+		return session.engine.formatAutoTime(col, session.statement.AutoTime)
+	}
+	return session.engine.nowTime(col)
+}
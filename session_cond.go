@@ -4,51 +4,219 @@
 
 package xorm
 
-import "xorm.io/builder"
+import (
+	"net/netip"
+
+	"github.com/imkos/xorm/convert"
+	"github.com/imkos/xorm/geo"
+	"xorm.io/builder"
+)
 
 // SQL provides raw sql input parameter. When you have a complex SQL statement
 // and cannot use Where, Id, In and etc. Methods to describe, you can use SQL.
 func (session *Session) SQL(query interface{}, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.SQL(query, args...)
 	return session
 }
 
 // Where provides custom query condition.
 func (session *Session) Where(query interface{}, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.Where(query, args...)
 	return session
 }
 
 // And provides custom query condition.
 func (session *Session) And(query interface{}, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.And(query, args...)
 	return session
 }
 
 // Or provides custom query condition.
 func (session *Session) Or(query interface{}, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.Or(query, args...)
 	return session
 }
 
-// ID provides converting id as a query condition
+// WhereGroup provides a parenthesized condition group, the conditions added to the session
+// in fn, i.e. Where, And, Or, In, will be combined with the other conditions by AND as a whole.
+//
+//	sess.Where("a = ?", 1).WhereGroup(func(s *Session) {
+//		s.Where("b = ?", 2).Or("c = ?", 3)
+//	})
+//
+// generates "a = ? AND (b = ? OR c = ?)"
+func (session *Session) WhereGroup(fn func(*Session)) *Session {
+	session.guardStatement()
+	return session.AndGroup(fn)
+}
+
+// AndGroup provides a parenthesized condition group which will be combined by AND
+func (session *Session) AndGroup(fn func(*Session)) *Session {
+	session.guardStatement()
+	session.statement.AndGroup(func() {
+		fn(session)
+	})
+	return session
+}
+
+// OrGroup provides a parenthesized condition group which will be combined by OR
+func (session *Session) OrGroup(fn func(*Session)) *Session {
+	session.guardStatement()
+	session.statement.OrGroup(func() {
+		fn(session)
+	})
+	return session
+}
+
+// ID provides converting id as a query condition, id could be a value, a typed ID, a schemas.PK
+// or a bean whose primary keys are used, i.e. ID(&User{Id: 5})
 func (session *Session) ID(id interface{}) *Session {
+	session.guardStatement()
 	session.statement.ID(id)
 	return session
 }
 
 // In provides a query string like "id in (1, 2, 3)"
 func (session *Session) In(column string, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.In(column, args...)
 	return session
 }
 
 // NotIn provides a query string like "id in (1, 2, 3)"
 func (session *Session) NotIn(column string, args ...interface{}) *Session {
+	session.guardStatement()
 	session.statement.NotIn(column, args...)
 	return session
 }
 
+// WhereNull provides a query string like "column IS NULL"
+func (session *Session) WhereNull(column string) *Session {
+	session.guardStatement()
+	session.statement.WhereNull(column)
+	return session
+}
+
+// OwnedBy filters the records whose polymorphic reference, a schemas.Polymorphic field tagged
+// with polymorphic(name), is the bean. The name could be omitted if the table has only one
+// polymorphic reference.
+//
+//	engine.OwnedBy(&post).Find(&comments)
+func (session *Session) OwnedBy(bean interface{}, name ...string) *Session {
+	session.guardStatement()
+	var refName string
+	if len(name) > 0 {
+		refName = name[0]
+	}
+	session.statement.OwnedBy(bean, refName)
+	return session
+}
+
+// WhereNotNull provides a query string like "column IS NOT NULL"
+func (session *Session) WhereNotNull(column string) *Session {
+	session.guardStatement()
+	session.statement.WhereNotNull(column)
+	return session
+}
+
+// WhereNullSafeEq provides a null-safe equality condition which matches when both the
+// column and the value are NULL, it generates "column IS NOT DISTINCT FROM ?" on Postgres,
+// "column <=> ?" on MySQL, "column IS ?" on SQLite, and an equivalent expression elsewhere.
+func (session *Session) WhereNullSafeEq(column string, value interface{}) *Session {
+	session.guardStatement()
+	session.statement.WhereNullSafeEq(column, value)
+	return session
+}
+
+// ILike provides a case-insensitive pattern matching like "column ILIKE ?", it's
+// "LOWER(column) LIKE LOWER(?)" on the databases which don't support ILIKE.
+// The wildcards in pattern are not escaped.
+func (session *Session) ILike(column, pattern string) *Session {
+	session.guardStatement()
+	session.statement.ILike(column, pattern)
+	return session
+}
+
+// StartsWith provides a query string like "column LIKE 's%'", the wildcards % and _ in s
+// are escaped, so it's safe to pass the user input
+func (session *Session) StartsWith(column, s string) *Session {
+	session.guardStatement()
+	session.statement.StartsWith(column, s)
+	return session
+}
+
+// EndsWith provides a query string like "column LIKE '%s'", the wildcards in s are escaped
+func (session *Session) EndsWith(column, s string) *Session {
+	session.guardStatement()
+	session.statement.EndsWith(column, s)
+	return session
+}
+
+// Contains provides a query string like "column LIKE '%s%'", the wildcards in s are escaped
+func (session *Session) Contains(column, s string) *Session {
+	session.guardStatement()
+	session.statement.Contains(column, s)
+	return session
+}
+
+// WhereContainsIP provides a query string like "column >>= addr" on PostgreSQL, and compares
+// the column with the address and the prefixes containing it on the other databases
+func (session *Session) WhereContainsIP(column string, addr netip.Addr) *Session {
+	session.guardStatement()
+	session.statement.WhereContainsIP(column, addr)
+	return session
+}
+
+// WhereWithin provides a query string like "ST_Within(column, geometry)"
+func (session *Session) WhereWithin(column string, g geo.Geometry) *Session {
+	session.guardStatement()
+	session.statement.WhereWithin(column, g)
+	return session
+}
+
+// WhereDWithin provides a query string like "ST_DWithin(column, geometry, distance)", it's
+// "ST_Distance(column, geometry) <= distance" on the databases except PostgreSQL
+func (session *Session) WhereDWithin(column string, g geo.Geometry, distance float64) *Session {
+	session.guardStatement()
+	session.statement.WhereDWithin(column, g, distance)
+	return session
+}
+
+// WhereBitsAll provides a query string like "(column & mask) = mask", it's
+// "BITAND(column, mask) = mask" on Oracle and Dameng
+func (session *Session) WhereBitsAll(column string, mask convert.BitSet) *Session {
+	session.guardStatement()
+	session.statement.WhereBitsAll(column, mask)
+	return session
+}
+
+// WhereBitsAny provides a query string like "(column & mask) <> 0", it's
+// "BITAND(column, mask) <> 0" on Oracle and Dameng
+func (session *Session) WhereBitsAny(column string, mask convert.BitSet) *Session {
+	session.guardStatement()
+	session.statement.WhereBitsAny(column, mask)
+	return session
+}
+
+// WhereExists provides a query string like "EXISTS (SELECT ...)", the sub query
+// could refer the columns of the outer table to be a correlated sub query
+func (session *Session) WhereExists(subQuery *builder.Builder) *Session {
+	session.guardStatement()
+	session.statement.WhereExists(subQuery)
+	return session
+}
+
+// WhereNotExists provides a query string like "NOT EXISTS (SELECT ...)"
+func (session *Session) WhereNotExists(subQuery *builder.Builder) *Session {
+	session.guardStatement()
+	session.statement.WhereNotExists(subQuery)
+	return session
+}
+
 // Conds returns session query conditions except auto bean conditions
 func (session *Session) Conds() builder.Cond {
 	return session.statement.Conds()
@@ -0,0 +1,87 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"time"
+)
+
+// SetMaxSessionLifetime sets the max lifetime of the sessions created by NewSessionContext,
+// the sessions expire after the lifetime. A non-positive value means the sessions live until
+// their contexts are done or they are closed.
+func (engine *Engine) SetMaxSessionLifetime(d time.Duration) {
+	engine.maxSessionLifetime = d
+}
+
+// SetMaxSessionLifetime sets the max lifetime of the sessions for all the engines of the group
+func (eg *EngineGroup) SetMaxSessionLifetime(d time.Duration) {
+	eg.Engine.SetMaxSessionLifetime(d)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetMaxSessionLifetime(d)
+	}
+}
+
+// NewSessionContext creates a session bound to the context, the session expires when the context
+// is done or the max session lifetime is reached, so that a request scoped session could not
+// outlive its request. The open transaction is rolled back by database/sql as soon as the context
+// is done, and the next call of the expired session closes it and returns the error of the context.
+func (engine *Engine) NewSessionContext(ctx context.Context) *Session {
+	return engine.NewSession().guard(ctx)
+}
+
+// NewSessionContext creates a group session bound to the context
+func (eg *EngineGroup) NewSessionContext(ctx context.Context) *Session {
+	return eg.NewSession().guard(ctx)
+}
+
+// WithContext returns an engine bound to the context, the sessions created by it, including the
+// ones of the convenience methods like Insert and Find, inherit the context, so the request scoped
+// deadlines apply without calling Context() every time. The returned engine shares the database,
+// the caches and the hooks with engine, the other settings are copied. Closing it does nothing.
+func (engine *Engine) WithContext(ctx context.Context) EngineInterface {
+	return engine.withContext(ctx)
+}
+
+func (engine *Engine) withContext(ctx context.Context) *Engine {
+	bound := *engine
+	bound.defaultContext = ctx
+	bound.parent = engine
+	if engine.parent != nil {
+		bound.parent = engine.parent
+	}
+	return &bound
+}
+
+// WithContext returns an engine group whose master is bound to the context
+func (eg *EngineGroup) WithContext(ctx context.Context) EngineInterface {
+	bound := *eg
+	bound.Engine = eg.Engine.withContext(ctx)
+	return &bound
+}
+
+// guard binds the session to the context so that the session expires when the context is done
+func (session *Session) guard(ctx context.Context) *Session {
+	if lifetime := session.engine.maxSessionLifetime; lifetime > 0 {
+		ctx, session.lifetimeCancel = context.WithTimeout(ctx, lifetime)
+	}
+	session.Context(ctx)
+	session.guardCtx = ctx
+	return session
+}
+
+// checkExpired closes the session in the goroutine using it if its context of NewSessionContext
+// is done and returns the error of the context
+func (session *Session) checkExpired() error {
+	if session.guardCtx == nil || session.guardCtx.Err() == nil {
+		return nil
+	}
+	if !session.isClosed {
+		if err := session.Close(); err != nil {
+			session.engine.logger.Errorf("close expired session failed: %v", err)
+		}
+	}
+	return session.guardCtx.Err()
+}
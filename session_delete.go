@@ -6,7 +6,6 @@ package xorm
 
 import (
 	"errors"
-	"strconv"
 
 	"github.com/imkos/xorm/caches"
 	"github.com/imkos/xorm/schemas"
@@ -16,6 +15,21 @@ import (
 // ErrNeedDeletedCond delete needs less one condition error
 var ErrNeedDeletedCond = errors.New("Delete action needs at least one condition")
 
+// ErrUnsupportedTruncateOption represents the database doesn't support the truncate option
+var ErrUnsupportedTruncateOption = errors.New("Unsupported truncate option")
+
+// TruncateOptions represents the options of Truncate, when it's passed to Truncate,
+// all the records of the table will be removed and the bean is only used to find
+// the table.
+type TruncateOptions struct {
+	// RestartIdentity resets the auto increment column, so that the next id will be 1, the
+	// records are deleted and the sequence is reset in one transaction if it takes several SQLs
+	RestartIdentity bool
+	// Cascade truncates the tables which have foreign key references to the table,
+	// it's only supported by postgres
+	Cascade bool
+}
+
 func (session *Session) cacheDelete(table *schemas.Table, tableName, sqlStr string, args ...interface{}) error {
 	if table == nil ||
 		session.tx != nil {
@@ -48,57 +62,185 @@ func (session *Session) cacheDelete(table *schemas.Table, tableName, sqlStr stri
 		ids = make([]schemas.PK, 0)
 		if len(resultsSlice) > 0 {
 			for _, data := range resultsSlice {
-				var id int64
 				var pk schemas.PK = make([]interface{}, 0)
 				for _, col := range pkColumns {
-					if v, ok := data[col.Name]; !ok {
+					v, ok := data[col.Name]
+					if !ok {
 						return errors.New("no id")
-					} else if col.SQLType.IsText() {
-						pk = append(pk, v)
-					} else if col.SQLType.IsNumeric() {
-						id, err = strconv.ParseInt(v, 10, 64)
-						if err != nil {
-							return err
-						}
-						pk = append(pk, id)
-					} else {
-						return errors.New("not supported primary key type")
 					}
+					id, err := col.ConvertID(v)
+					if err != nil {
+						return err
+					}
+					pk = append(pk, id)
 				}
 				ids = append(ids, pk)
 			}
 		}
 	}
 
+	sids := make([]string, 0, len(ids))
 	for _, id := range ids {
 		session.engine.logger.Debugf("[cache] delete cache obj: %v, %v", tableName, id)
-		sid, err := id.ToString()
-		if err != nil {
-			return err
-		}
+		sid := id.Key()
 		cacher.DelBean(tableName, sid)
+		sids = append(sids, sid)
 	}
 	session.engine.logger.Debugf("[cache] clear cache table: %v", tableName)
 	cacher.ClearIds(tableName)
+	session.publishInvalidation(tableName, false, sids...)
 	return nil
 }
 
 // Delete records, bean's non-empty fields are conditions
 // At least one condition must be set.
 func (session *Session) Delete(beans ...interface{}) (int64, error) {
-	return session.delete(beans, true)
+	return session.withProcessorSavepoint(func() (int64, error) {
+		return session.delete(beans, true)
+	})
 }
 
 // Truncate records, bean's non-empty fields are conditions
 // In contrast to Delete this method allows deletes without conditions.
+// If a TruncateOptions is given, i.e. Truncate(bean, TruncateOptions{RestartIdentity: true}),
+// the table will be truncated according to the options.
 func (session *Session) Truncate(beans ...interface{}) (int64, error) {
-	return session.delete(beans, false)
+	for i, bean := range beans {
+		var opts TruncateOptions
+		switch t := bean.(type) {
+		case TruncateOptions:
+			opts = t
+		case *TruncateOptions:
+			opts = *t
+		default:
+			continue
+		}
+		beans = append(beans[:i:i], beans[i+1:]...)
+		return session.truncate(beans, opts)
+	}
+	return session.withProcessorSavepoint(func() (int64, error) {
+		return session.delete(beans, false)
+	})
+}
+
+func (session *Session) truncate(beans []interface{}, opts TruncateOptions) (int64, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
+
+	if session.statement.LastError != nil {
+		return 0, session.statement.LastError
+	}
+	if len(beans) > 0 {
+		if err := session.statement.SetRefBean(beans[0]); err != nil {
+			return 0, err
+		}
+	}
+	if session.statement.Conds().IsValid() {
+		return 0, errors.New("Truncate with options cannot have conditions")
+	}
+
+	dialect := session.engine.dialect
+	tableName := session.statement.TableName()
+	quotedTableName := session.engine.Quote(tableName)
+	var sqls []string
+	switch dialect.URI().DBType {
+	case schemas.POSTGRES:
+		sql := "TRUNCATE TABLE " + quotedTableName
+		if opts.RestartIdentity {
+			sql += " RESTART IDENTITY"
+		}
+		if opts.Cascade {
+			sql += " CASCADE"
+		}
+		sqls = append(sqls, sql)
+	case schemas.MYSQL:
+		if opts.Cascade {
+			return 0, ErrUnsupportedTruncateOption
+		}
+		// TRUNCATE TABLE always resets the auto increment value on mysql
+		if opts.RestartIdentity {
+			sqls = append(sqls, "TRUNCATE TABLE "+quotedTableName)
+		} else {
+			sqls = append(sqls, "DELETE FROM "+quotedTableName)
+		}
+	default:
+		if opts.Cascade {
+			return 0, ErrUnsupportedTruncateOption
+		}
+		sqls = append(sqls, "DELETE FROM "+quotedTableName)
+		table := session.statement.RefTable
+		if opts.RestartIdentity && (table == nil || table.AutoIncrement != "") {
+			var colName string
+			if table != nil {
+				colName = table.AutoIncrement
+			}
+			restartSQLs := dialect.SetAutoIncrStartSQL(tableName, colName, 1)
+			if len(restartSQLs) == 0 {
+				return 0, ErrUnsupportedTruncateOption
+			}
+			if dialect.URI().DBType == schemas.SQLITE {
+				// sqlite_sequence is created with the first AUTOINCREMENT table, there is
+				// no sequence to reset without it
+				exist, err := dialect.IsTableExist(session.getQueryer(), session.ctx, "sqlite_sequence")
+				if err != nil {
+					return 0, err
+				}
+				if !exist {
+					restartSQLs = nil
+				}
+			}
+			sqls = append(sqls, restartSQLs...)
+		}
+	}
+
+	// the records are deleted and the sequence is reset together
+	var needCommit bool
+	if len(sqls) > 1 && session.isAutoCommit {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+		needCommit = true
+	}
+
+	var affected int64
+	for i, sql := range sqls {
+		res, err := session.exec(sql)
+		if err != nil {
+			if needCommit {
+				_ = session.Rollback()
+			}
+			return 0, err
+		}
+		if i == 0 {
+			if affected, err = res.RowsAffected(); err != nil {
+				if needCommit {
+					_ = session.Rollback()
+				}
+				return 0, err
+			}
+		}
+	}
+	if needCommit {
+		if err := session.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return affected, nil
 }
 
 func (session *Session) delete(beans []interface{}, mustHaveConditions bool) (int64, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	if session.statement.LastError != nil {
 		return 0, session.statement.LastError
@@ -114,10 +256,18 @@ func (session *Session) delete(beans []interface{}, mustHaveConditions bool) (in
 			return 0, err
 		}
 
-		executeBeforeClosures(session, bean)
+		if err := session.runProcessors(func() error {
+			executeBeforeClosures(session, bean)
 
-		if processor, ok := interface{}(bean).(BeforeDeleteProcessor); ok {
-			processor.BeforeDelete()
+			if processor, ok := interface{}(bean).(BeforeDeleteProcessor); ok {
+				processor.BeforeDelete()
+			}
+			if processor, ok := interface{}(bean).(BeforeDeleteSessionProcessor); ok {
+				return processor.BeforeDelete(session)
+			}
+			return nil
+		}); err != nil {
+			return 0, err
 		}
 
 		if err = session.statement.MergeConds(bean); err != nil {
@@ -129,20 +279,26 @@ func (session *Session) delete(beans []interface{}, mustHaveConditions bool) (in
 	if mustHaveConditions && !session.statement.Conds().IsValid() && (pLimitN == nil || *pLimitN == 0) {
 		return 0, ErrNeedDeletedCond
 	}
+	session.statement.ApplyDefaultScope()
 
 	tableNameNoQuote := session.statement.TableName()
 	table := session.statement.RefTable
 
 	realSQLWriter := builder.NewWriter()
 	deleteSQLWriter := builder.NewWriter()
-	if err := session.statement.WriteDelete(realSQLWriter, deleteSQLWriter, session.engine.nowTime); err != nil {
+	if err := session.statement.WriteDelete(realSQLWriter, deleteSQLWriter, session.nowTime); err != nil {
 		return 0, err
 	}
 
 	if session.statement.GetUnscoped() || table == nil || table.DeletedColumn() == nil { // tag "deleted" is disabled
+	} else if deletedColumn := table.DeletedColumn(); deletedColumn.IsDeletedFlag {
+		colName := deletedColumn.Name
+		session.afterClosures = append(session.afterClosures, func(bean interface{}) {
+			col := table.GetColumn(colName)
+			setColumnFlag(bean, col)
+		})
 	} else {
-		deletedColumn := table.DeletedColumn()
-		_, t, err := session.engine.nowTime(deletedColumn)
+		_, t, err := session.nowTime(deletedColumn)
 		if err != nil {
 			return 0, err
 		}
@@ -9,6 +9,10 @@ func (session *Session) Exist(bean ...interface{}) (bool, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return false, err
+	}
+	defer session.release()
 
 	if session.statement.LastError != nil {
 		return false, session.statement.LastError
@@ -0,0 +1,149 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"xorm.io/builder"
+)
+
+// enumerates all the operators of FilterBy, a param "field[op]" filters the field with the
+// operator and a param "field" means FilterEq
+const (
+	FilterEq   = "eq"
+	FilterNe   = "ne"
+	FilterGt   = "gt"
+	FilterGte  = "gte"
+	FilterLt   = "lt"
+	FilterLte  = "lte"
+	FilterLike = "like" // the value is matched as a substring, the wildcards in it are escaped
+	FilterIn   = "in"   // the value is a comma separated list
+	FilterNull = "null" // the value is true for IS NULL or false for IS NOT NULL
+)
+
+// FilterField represents a field which is allowed to be filtered by FilterBy
+type FilterField struct {
+	// Column is the column name of the field, the default is the field name
+	Column string
+	// Ops are the allowed operators, the default is FilterEq only
+	Ops []string
+}
+
+// FilterSpec represents the fields which are allowed to be filtered by FilterBy
+type FilterSpec map[string]FilterField
+
+// FilterBy adds the conditions of the params from the user input, i.e. the query parameters of
+// a HTTP API. A param "field" or "field[op]" filters the field which is in the spec with the
+// operator, the values are always bound as args. The other params are ignored, so the params
+// could also contain the pagination and the sort parameters. The spec is required so that only
+// the fields chosen explicitly could be filtered. An operator which is not allowed makes the
+// session fail with ErrUnknownFilterKey.
+func (session *Session) FilterBy(params map[string][]string, spec FilterSpec) *Session {
+	session.guardStatement()
+	if spec == nil {
+		session.statement.LastError = errors.New("FilterBy needs a filter spec of the allowed fields")
+		return session
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, op := key, FilterEq
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			field, op = key[:i], strings.ToLower(key[i+1:len(key)-1])
+		}
+		f, ok := spec[field]
+		if !ok {
+			continue
+		}
+		if !f.allows(op) {
+			session.statement.LastError = fmt.Errorf("%w: %q", ErrUnknownFilterKey, key)
+			return session
+		}
+
+		colName := f.Column
+		if colName == "" {
+			colName = field
+		}
+		if op == FilterLike {
+			if values := params[key]; len(values) > 0 {
+				session.statement.Contains(colName, values[len(values)-1])
+			}
+			continue
+		}
+		cond, err := filterCond(session.engine.Quote(colName), op, params[key])
+		if err != nil {
+			session.statement.LastError = err
+			return session
+		}
+		if cond != nil {
+			session.statement.And(cond)
+		}
+	}
+	return session
+}
+
+func (f FilterField) allows(op string) bool {
+	if len(f.Ops) == 0 {
+		return op == FilterEq
+	}
+	for _, o := range f.Ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func filterCond(colName, op string, values []string) (builder.Cond, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	value := values[len(values)-1]
+
+	switch op {
+	case FilterEq:
+		if len(values) > 1 {
+			return builder.In(colName, values), nil
+		}
+		return builder.Eq{colName: value}, nil
+	case FilterNe:
+		return builder.Neq{colName: value}, nil
+	case FilterGt:
+		return builder.Gt{colName: value}, nil
+	case FilterGte:
+		return builder.Gte{colName: value}, nil
+	case FilterLt:
+		return builder.Lt{colName: value}, nil
+	case FilterLte:
+		return builder.Lte{colName: value}, nil
+	case FilterIn:
+		var items []string
+		for _, v := range values {
+			items = append(items, strings.Split(v, ",")...)
+		}
+		return builder.In(colName, items), nil
+	case FilterNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid null filter value %q of %s", value, colName)
+		}
+		if isNull {
+			return builder.IsNull{colName}, nil
+		}
+		return builder.NotNull{colName}, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter operator %q", op)
+	}
+}
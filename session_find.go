@@ -7,6 +7,7 @@ package xorm
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -30,6 +31,10 @@ func (session *Session) Find(rowsSlicePtr interface{}, condiBean ...interface{})
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 	return session.find(rowsSlicePtr, condiBean...)
 }
 
@@ -38,6 +43,10 @@ func (session *Session) FindAndCount(rowsSlicePtr interface{}, condiBean ...inte
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	session.autoResetStatement = false
 	err := session.find(rowsSlicePtr, condiBean...)
@@ -180,9 +189,34 @@ type ColumnsSchema struct {
 func (columnsSchema *ColumnsSchema) ParseTableSchema(table *schemas.Table) {
 	for _, field := range columnsSchema.Fields {
 		field.ColumnSchema = table.GetColumnIdx(field.FieldName, field.TempIndex)
+		if field.ColumnSchema == nil {
+			field.ColumnSchema = prefixedColumn(table, field.FieldName)
+		}
 	}
 }
 
+// prefixedColumn returns the column of the extended struct for the field labeled as
+// "prefix.column", the prefix is the name of the extended struct field in any case
+// or in snake case
+func prefixedColumn(table *schemas.Table, fieldName string) *schemas.Column {
+	i := strings.LastIndexByte(fieldName, '.')
+	if i <= 0 {
+		return nil
+	}
+	prefix := strings.ReplaceAll(strings.ToLower(fieldName[:i]), "_", "")
+	name := fieldName[i+1:]
+	for _, col := range table.Columns() {
+		j := strings.LastIndexByte(col.FieldName, '.')
+		if j <= 0 || !strings.EqualFold(col.Name, name) {
+			continue
+		}
+		if strings.ReplaceAll(strings.ToLower(col.FieldName[:j]), "_", "") == prefix {
+			return col
+		}
+	}
+	return nil
+}
+
 func ParseColumnsSchema(fieldNames []string, types []*sql.ColumnType, table *schemas.Table) *ColumnsSchema {
 	var columnsSchema ColumnsSchema
 
@@ -266,27 +300,22 @@ func (session *Session) noCacheFind(table *schemas.Table, containerValue reflect
 	} else {
 		keyType := containerValue.Type().Key()
 		if len(table.PrimaryKeys) == 0 {
-			return errors.New("don't support multiple primary key's map has non-slice key type")
+			return errors.New("needs a primary key to find into a map")
 		}
-		if len(table.PrimaryKeys) > 1 && keyType.Kind() != reflect.Slice {
-			return errors.New("don't support multiple primary key's map has non-slice key type")
+		if err := session.checkPKMapKey(keyType, table); err != nil {
+			return err
 		}
 
 		containerValueSetFunc = func(newValue *reflect.Value, pk schemas.PK) error {
-			keyValue := reflect.New(keyType)
-			cols := table.PKColumns()
-			if len(cols) == 1 {
-				if err := convert.AssignValue(keyValue, pk[0]); err != nil {
-					return err
-				}
-			} else {
-				keyValue.Set(reflect.ValueOf(&pk))
+			keyValue, err := session.pkMapKey(keyType, table, pk)
+			if err != nil {
+				return err
 			}
 
 			if isPointer {
-				containerValue.SetMapIndex(keyValue.Elem(), newValue.Elem().Addr())
+				containerValue.SetMapIndex(keyValue, newValue.Elem().Addr())
 			} else {
-				containerValue.SetMapIndex(keyValue.Elem(), newValue.Elem())
+				containerValue.SetMapIndex(keyValue, newValue.Elem())
 			}
 			return nil
 		}
@@ -294,12 +323,15 @@ func (session *Session) noCacheFind(table *schemas.Table, containerValue reflect
 
 	if elemType.Kind() == reflect.Struct {
 		newValue := newElemFunc(fields)
-		tb, err := session.engine.tagParser.ParseWithCache(newValue)
+		tb, err := session.statement.ParseTable(newValue)
 		if err != nil {
 			return err
 		}
 
 		columnsSchema := ParseColumnsSchema(fields, types, tb)
+		if err := session.checkStrict(columnsSchema, tb); err != nil {
+			return err
+		}
 
 		err = session.rows2Beans(rows, columnsSchema, fields, types, tb, newElemFunc, containerValueSetFunc)
 		rows.Close()
@@ -406,10 +438,7 @@ func (session *Session) cacheFind(t reflect.Type, sqlStr string, rowsSlicePtr in
 	temps := make([]interface{}, len(ids))
 
 	for idx, id := range ids {
-		sid, err := id.ToString()
-		if err != nil {
-			return err
-		}
+		sid := id.Key()
 		bean := cacher.GetBean(tableName, sid)
 
 		// fix issue #894
@@ -436,10 +465,7 @@ func (session *Session) cacheFind(t reflect.Type, sqlStr string, rowsSlicePtr in
 				return err
 			}
 
-			xid, err := pk.ToString()
-			if err != nil {
-				return err
-			}
+			xid := pk.Key()
 
 			if sid != xid {
 				session.engine.logger.Errorf("[cache] error cache: %v, %v, %v", xid, sid, bean)
@@ -462,7 +488,11 @@ func (session *Session) cacheFind(t reflect.Type, sqlStr string, rowsSlicePtr in
 		if len(table.PrimaryKeys) == 1 {
 			ff := make([]interface{}, 0, len(ides))
 			for _, ie := range ides {
-				ff = append(ff, ie[0])
+				v, err := schemas.IDValue(ie[0])
+				if err != nil {
+					return err
+				}
+				ff = append(ff, v)
 			}
 
 			session.In("`"+table.PrimaryKeys[0]+"`", ff...)
@@ -470,7 +500,11 @@ func (session *Session) cacheFind(t reflect.Type, sqlStr string, rowsSlicePtr in
 			for _, ie := range ides {
 				cond := builder.NewCond()
 				for i, name := range table.PrimaryKeys {
-					cond = cond.And(builder.Eq{"`" + name + "`": ie[i]})
+					v, err := schemas.IDValue(ie[i])
+					if err != nil {
+						return err
+					}
+					cond = cond.And(builder.Eq{"`" + name + "`": v})
 				}
 				session.Or(cond)
 			}
@@ -492,10 +526,7 @@ func (session *Session) cacheFind(t reflect.Type, sqlStr string, rowsSlicePtr in
 			if err != nil {
 				return err
 			}
-			sid, err := id.ToString()
-			if err != nil {
-				return err
-			}
+			sid := id.Key()
 
 			bean := rv.Interface()
 			temps[ididxes[sid]] = bean
@@ -518,29 +549,112 @@ func (session *Session) cacheFind(t reflect.Type, sqlStr string, rowsSlicePtr in
 				sliceValue.Set(reflect.Append(sliceValue, reflect.Indirect(reflect.ValueOf(bean))))
 			}
 		} else if sliceValue.Kind() == reflect.Map {
-			key := ids[j]
-			keyType := sliceValue.Type().Key()
-			keyValue := reflect.New(keyType)
-			var ikey interface{}
-			if len(key) == 1 {
-				if err := convert.AssignValue(keyValue, key[0]); err != nil {
-					return err
-				}
-				ikey = keyValue.Elem().Interface()
-			} else {
-				if keyType.Kind() != reflect.Slice {
-					return errors.New("table have multiple primary keys, key is not schemas.PK or slice")
-				}
-				ikey = key
+			keyValue, err := session.pkMapKey(sliceValue.Type().Key(), table, ids[j])
+			if err != nil {
+				return err
 			}
 
 			if t.Kind() == reflect.Ptr {
-				sliceValue.SetMapIndex(reflect.ValueOf(ikey), reflect.ValueOf(bean))
+				sliceValue.SetMapIndex(keyValue, reflect.ValueOf(bean))
 			} else {
-				sliceValue.SetMapIndex(reflect.ValueOf(ikey), reflect.Indirect(reflect.ValueOf(bean)))
+				sliceValue.SetMapIndex(keyValue, reflect.Indirect(reflect.ValueOf(bean)))
 			}
 		}
 	}
 
 	return nil
 }
+
+// checkPKMapKey checks the key type of the map which is keyed by the primary keys, the composite
+// primary keys need a struct whose fields are the primary key columns or an array of the values
+func (session *Session) checkPKMapKey(keyType reflect.Type, table *schemas.Table) error {
+	if len(table.PrimaryKeys) <= 1 {
+		return nil
+	}
+	switch keyType.Kind() {
+	case reflect.Array:
+		if keyType.Len() != len(table.PrimaryKeys) {
+			return fmt.Errorf("the map key %v should have %d elements as the primary keys", keyType, len(table.PrimaryKeys))
+		}
+		return nil
+	case reflect.Struct:
+		_, err := session.pkKeyFields(keyType, table)
+		return err
+	}
+	return fmt.Errorf("the map key %v should be a struct or an array for the composite primary keys", keyType)
+}
+
+// pkMapKey converts the primary key values to the key of the map
+func (session *Session) pkMapKey(keyType reflect.Type, table *schemas.Table, pk schemas.PK) (reflect.Value, error) {
+	keyValue := reflect.New(keyType)
+	if len(pk) == 1 {
+		if err := assignPKValue(keyValue.Elem(), pk[0]); err != nil {
+			return reflect.Value{}, err
+		}
+		return keyValue.Elem(), nil
+	}
+
+	var fields []int
+	if keyType.Kind() == reflect.Struct {
+		var err error
+		if fields, err = session.pkKeyFields(keyType, table); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	for i, v := range pk {
+		var dv reflect.Value
+		switch keyType.Kind() {
+		case reflect.Array:
+			dv = keyValue.Elem().Index(i)
+		case reflect.Struct:
+			dv = keyValue.Elem().Field(fields[i])
+		default:
+			return reflect.Value{}, fmt.Errorf("the map key %v should be a struct or an array for the composite primary keys", keyType)
+		}
+		if err := assignPKValue(dv, v); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return keyValue.Elem(), nil
+}
+
+// assignPKValue assigns the primary key value to the element of the map key, the values of the
+// custom primary key types are assigned as they are
+func assignPKValue(dv reflect.Value, v interface{}) error {
+	if v != nil && reflect.TypeOf(v).AssignableTo(dv.Type()) {
+		dv.Set(reflect.ValueOf(v))
+		return nil
+	}
+	return convert.AssignValue(dv.Addr(), v)
+}
+
+// pkKeyFields returns the indexes of the struct fields for the primary keys, the fields are
+// matched by the names of the primary key columns, or by the order if none of the names match
+func (session *Session) pkKeyFields(keyType reflect.Type, table *schemas.Table) ([]int, error) {
+	fields := make([]int, len(table.PrimaryKeys))
+	exported := make([]int, 0, keyType.NumField())
+	var matched int
+	for i := 0; i < keyType.NumField(); i++ {
+		field := keyType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		exported = append(exported, i)
+		colName := session.engine.GetColumnMapper().Obj2Table(field.Name)
+		for j, pkName := range table.PrimaryKeys {
+			if strings.EqualFold(pkName, colName) || strings.EqualFold(pkName, field.Name) {
+				fields[j] = i
+				matched++
+				break
+			}
+		}
+	}
+
+	if matched == len(table.PrimaryKeys) {
+		return fields, nil
+	}
+	if matched == 0 && len(exported) == len(table.PrimaryKeys) {
+		return exported, nil
+	}
+	return nil, fmt.Errorf("the fields of the map key %v don't match the primary keys %v", keyType, table.PrimaryKeys)
+}
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
-	"strconv"
 	"time"
 
 	"github.com/imkos/xorm/caches"
@@ -29,7 +28,32 @@ func (session *Session) Get(beans ...interface{}) (bool, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
-	return session.get(beans...)
+	if err := session.acquire(); err != nil {
+		return false, err
+	}
+	defer session.release()
+	return session.get(false, beans...)
+}
+
+// One retrieves one record like Get but returns ErrNotFound if there is no record. If strict
+// is true, ErrMultipleRows is returned if more than one record matches the conditions, and
+// the bean is filled with the first record.
+func (session *Session) One(bean interface{}, strict ...bool) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+	has, err := session.get(len(strict) > 0 && strict[0], bean)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func isPtrOfTime(v interface{}) bool {
@@ -45,7 +69,9 @@ func isPtrOfTime(v interface{}) bool {
 	return el.Type().ConvertibleTo(schemas.TimeType)
 }
 
-func (session *Session) get(beans ...interface{}) (bool, error) {
+// get retrieves one record into the beans, if strict is true, it checks there are no more
+// records and the caches are not used
+func (session *Session) get(strict bool, beans ...interface{}) (bool, error) {
 	defer session.resetStatement()
 
 	if session.statement.LastError != nil {
@@ -79,7 +105,11 @@ func (session *Session) get(beans ...interface{}) (bool, error) {
 		if len(session.statement.TableName()) == 0 {
 			return false, ErrTableNotFound
 		}
-		session.statement.Limit(1)
+		if strict {
+			session.statement.Limit(2)
+		} else {
+			session.statement.Limit(1)
+		}
 		sqlStr, args, err = session.statement.GenGetSQL(beans[0])
 		if err != nil {
 			return false, err
@@ -91,7 +121,7 @@ func (session *Session) get(beans ...interface{}) (bool, error) {
 
 	table := session.statement.RefTable
 
-	if session.statement.ColumnMap.IsEmpty() && session.canCache() && isStruct {
+	if !strict && session.statement.ColumnMap.IsEmpty() && session.canCache() && isStruct {
 		if cacher := session.engine.GetCacher(session.statement.TableName()); cacher != nil &&
 			!session.statement.GetUnscoped() {
 			has, err := session.cacheGet(beans[0], sqlStr, args...)
@@ -102,6 +132,9 @@ func (session *Session) get(beans ...interface{}) (bool, error) {
 	}
 
 	context := session.statement.Context
+	if strict {
+		context = nil
+	}
 	if context != nil && isStruct {
 		res := context.Get(fmt.Sprintf("%v-%v", sqlStr, args))
 		if res != nil {
@@ -115,7 +148,7 @@ func (session *Session) get(beans ...interface{}) (bool, error) {
 		}
 	}
 
-	has, err := session.nocacheGet(beanValue.Elem().Kind(), table, beans, sqlStr, args...)
+	has, err := session.nocacheGet(beanValue.Elem().Kind(), table, beans, strict, sqlStr, args...)
 	if err != nil || !has {
 		return has, err
 	}
@@ -141,7 +174,7 @@ func isScannableStruct(bean interface{}, typeLen int) bool {
 	return true
 }
 
-func (session *Session) nocacheGet(beanKind reflect.Kind, table *schemas.Table, beans []interface{}, sqlStr string, args ...interface{}) (bool, error) {
+func (session *Session) nocacheGet(beanKind reflect.Kind, table *schemas.Table, beans []interface{}, strict bool, sqlStr string, args ...interface{}) (bool, error) {
 	rows, err := session.queryRows(sqlStr, args...)
 	if err != nil {
 		return false, err
@@ -167,6 +200,9 @@ func (session *Session) nocacheGet(beanKind reflect.Kind, table *schemas.Table,
 	if err := session.scan(rows, table, beanKind, beans, columnsSchema, types, fields); err != nil {
 		return true, err
 	}
+	if strict && rows.Next() {
+		return true, ErrMultipleRows
+	}
 	rows.Close()
 
 	return true, session.executeProcessors()
@@ -180,6 +216,9 @@ func (session *Session) scan(rows *core.Rows, table *schemas.Table, firstBeanKin
 			if !isScannableStruct(bean, len(types)) {
 				break
 			}
+			if err := session.checkStrict(columnsSchema, table); err != nil {
+				return err
+			}
 			scanResults, err := session.row2Slice(rows, fields, types, bean)
 			if err != nil {
 				return err
@@ -195,10 +234,6 @@ func (session *Session) scan(rows *core.Rows, table *schemas.Table, firstBeanKin
 		}
 	}
 
-	if len(beans) != len(types) {
-		return fmt.Errorf("expected columns %d, but only %d variables", len(types), len(beans))
-	}
-
 	return session.engine.scan(rows, fields, types, beans...)
 }
 
@@ -313,16 +348,9 @@ func (session *Session) cacheGet(bean interface{}, sqlStr string, args ...interf
 
 		var pk schemas.PK = make([]interface{}, len(table.PrimaryKeys))
 		for i, col := range table.PKColumns() {
-			if col.SQLType.IsText() {
-				pk[i] = res[i]
-			} else if col.SQLType.IsNumeric() {
-				n, err := strconv.ParseInt(res[i], 10, 64)
-				if err != nil {
-					return false, err
-				}
-				pk[i] = n
-			} else {
-				return false, errors.New("unsupported")
+			pk[i], err = col.ConvertID(res[i])
+			if err != nil {
+				return false, err
 			}
 		}
 
@@ -340,14 +368,11 @@ func (session *Session) cacheGet(bean interface{}, sqlStr string, args ...interf
 		structValue := reflect.Indirect(reflect.ValueOf(bean))
 		id := ids[0]
 		session.engine.logger.Debugf("[cache] get bean: %s, %v", tableName, id)
-		sid, err := id.ToString()
-		if err != nil {
-			return false, err
-		}
+		sid := id.Key()
 		cacheBean := cacher.GetBean(tableName, sid)
 		if cacheBean == nil {
 			cacheBean = bean
-			has, err = session.nocacheGet(reflect.Struct, table, []interface{}{cacheBean}, sqlStr, args...)
+			has, err = session.nocacheGet(reflect.Struct, table, []interface{}{cacheBean}, false, sqlStr, args...)
 			if err != nil || !has {
 				return has, err
 			}
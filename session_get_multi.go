@@ -0,0 +1,243 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/imkos/xorm/caches"
+	"github.com/imkos/xorm/schemas"
+	"xorm.io/builder"
+)
+
+// GetMulti retrieves the records of the primary keys into resultsMap, which should be a
+// pointer to a map keyed by the primary key, i.e. *map[int64]*User. The ids without a record
+// are absent from the map. If the table has a cacher, the cached beans are used and only the
+// missed ids are retrieved from the database with one IN query.
+// For the tables with composite primary keys, ids should be a slice of schemas.PK and the map
+// should be keyed by string which is the PK's ToString(), by a struct whose fields are the
+// primary key columns or by an array of the primary key values.
+func (session *Session) GetMulti(ids interface{}, resultsMap interface{}) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+	defer session.resetStatement()
+
+	if session.statement.LastError != nil {
+		return session.statement.LastError
+	}
+
+	mapValue := reflect.ValueOf(resultsMap)
+	if mapValue.Kind() != reflect.Ptr || mapValue.Elem().Kind() != reflect.Map {
+		return errors.New("needs a pointer to a map")
+	}
+	mapValue = mapValue.Elem()
+	elemType := mapValue.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return errors.New("needs a map of structs")
+	}
+
+	idsValue := reflect.ValueOf(ids)
+	if idsValue.Kind() != reflect.Slice {
+		return errors.New("needs a slice of ids")
+	}
+
+	if session.statement.RefTable == nil {
+		if err := session.statement.SetRefValue(reflect.New(structType)); err != nil {
+			return err
+		}
+	}
+	table := session.statement.RefTable
+	if len(table.PrimaryKeys) == 0 {
+		return fmt.Errorf("table %s has no primary key", table.Name)
+	}
+	if mapValue.Type().Key().Kind() != reflect.String {
+		if err := session.checkPKMapKey(mapValue.Type().Key(), table); err != nil {
+			return err
+		}
+	}
+
+	pks, err := normalizeIDs(table, idsValue)
+	if err != nil {
+		return err
+	}
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapValue.Type()))
+	}
+	if len(pks) == 0 {
+		return nil
+	}
+
+	setResult := func(pk schemas.PK, bean reflect.Value) error {
+		if elemType.Kind() != reflect.Ptr {
+			bean = bean.Elem()
+		}
+		if len(pk) > 1 && mapValue.Type().Key().Kind() == reflect.String {
+			sid, err := pk.ToString()
+			if err != nil {
+				return err
+			}
+			mapValue.SetMapIndex(reflect.ValueOf(sid).Convert(mapValue.Type().Key()), bean)
+			return nil
+		}
+		key, err := session.pkMapKey(mapValue.Type().Key(), table, pk)
+		if err != nil {
+			return err
+		}
+		mapValue.SetMapIndex(key, bean)
+		return nil
+	}
+
+	// the cached beans could only be used when there is no other condition
+	tableName := session.statement.TableName()
+	var cacher caches.Cacher
+	if session.statement.ColumnMap.IsEmpty() && !session.statement.Conds().IsValid() && session.canCache() {
+		cacher = session.engine.cacherMgr.GetCacher(tableName)
+	}
+
+	misses := make([]schemas.PK, 0, len(pks))
+	for _, pk := range pks {
+		if cacher != nil {
+			sid := pk.Key()
+			if bean := cacher.GetBean(tableName, sid); bean != nil && reflect.TypeOf(bean) == reflect.PtrTo(structType) {
+				session.engine.logger.Debugf("[cache] cache hit bean: %v, %v, %v", tableName, pk, bean)
+				// the cached bean is shared, so a copy is returned
+				if err := setResult(pk, copyBean(reflect.ValueOf(bean))); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		misses = append(misses, pk)
+	}
+	if len(misses) == 0 {
+		return nil
+	}
+
+	// the missed ids are queried in chunks whose args fit the max parameters of the database,
+	// every chunk is queried by a copy of the statement built so far
+	chunkSize := len(misses)
+	if maxParams := session.engine.dialect.Features().MaxParams; maxParams > 0 {
+		_, condArgs, err := builder.ToSQL(session.statement.Conds())
+		if err != nil {
+			return err
+		}
+		chunkSize = max((maxParams-len(condArgs))/len(table.PrimaryKeys), 1)
+	}
+	base := session.statement
+	defer func() {
+		session.statement = base
+	}()
+	for start := 0; start < len(misses); start += chunkSize {
+		session.statement = base.Clone()
+		if err := session.getMultiChunk(table, misses[start:min(start+chunkSize, len(misses))], structType, func(pk schemas.PK, bean reflect.Value) error {
+			if cacher != nil {
+				cacher.PutBean(tableName, pk.Key(), copyBean(bean).Interface())
+			}
+			return setResult(pk, bean)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMultiChunk queries the records of the primary keys and passes them to fn one by one
+func (session *Session) getMultiChunk(table *schemas.Table, pks []schemas.PK, structType reflect.Type, fn func(pk schemas.PK, bean reflect.Value) error) error {
+	if len(table.PrimaryKeys) == 1 {
+		args := make([]interface{}, 0, len(pks))
+		for _, pk := range pks {
+			v, err := schemas.IDValue(pk[0])
+			if err != nil {
+				return err
+			}
+			args = append(args, v)
+		}
+		session.statement.In(table.PrimaryKeys[0], args...)
+	} else {
+		cond := builder.NewCond()
+		for _, pk := range pks {
+			pkCond := builder.NewCond()
+			for i, name := range table.PrimaryKeys {
+				v, err := schemas.IDValue(pk[i])
+				if err != nil {
+					return err
+				}
+				pkCond = pkCond.And(builder.Eq{session.engine.Quote(name): v})
+			}
+			cond = cond.Or(pkCond)
+		}
+		session.statement.And(cond)
+	}
+
+	beans := reflect.New(reflect.SliceOf(reflect.PtrTo(structType)))
+	if err := session.NoCache().find(beans.Interface()); err != nil {
+		return err
+	}
+
+	beansValue := beans.Elem()
+	for i := 0; i < beansValue.Len(); i++ {
+		bean := beansValue.Index(i)
+		pk, err := table.IDOfV(bean)
+		if err != nil {
+			return err
+		}
+		if err := fn(pk, bean); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyBean returns a pointer to a shallow copy of the struct which bean points to
+func copyBean(bean reflect.Value) reflect.Value {
+	copied := reflect.New(bean.Type().Elem())
+	copied.Elem().Set(bean.Elem())
+	return copied
+}
+
+// normalizeIDs converts the ids to primary keys, the values will be converted to the
+// primary key columns' types so that they could be used as the cache keys
+func normalizeIDs(table *schemas.Table, idsValue reflect.Value) ([]schemas.PK, error) {
+	pkCols := table.PKColumns()
+	pks := make([]schemas.PK, 0, idsValue.Len())
+	for i := 0; i < idsValue.Len(); i++ {
+		var pk schemas.PK
+		switch id := idsValue.Index(i).Interface().(type) {
+		case schemas.PK:
+			pk = id
+		case *schemas.PK:
+			pk = *id
+		default:
+			pk = schemas.PK{id}
+		}
+		if len(pk) != len(pkCols) {
+			return nil, fmt.Errorf("id %v does not match the primary keys %v", pk, table.PrimaryKeys)
+		}
+
+		normalized := make(schemas.PK, len(pk))
+		for j, v := range pk {
+			normalized[j] = v
+			if schemas.GetIDConverter(reflect.TypeOf(v)) != nil {
+				continue
+			}
+			if cv, err := pkCols[j].ConvertID(fmt.Sprint(v)); err == nil {
+				normalized[j] = cv
+			}
+		}
+		pks = append(pks, normalized)
+	}
+	return pks, nil
+}
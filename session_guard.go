@@ -0,0 +1,108 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+
+	"github.com/imkos/xorm/internal/utils"
+)
+
+// SessionGuardMode represents how the concurrent use of a session is reported
+type SessionGuardMode int32
+
+// enumerates the session guard modes
+const (
+	// SessionGuardOff disables the detection, it's the default
+	SessionGuardOff SessionGuardMode = iota
+	// SessionGuardError returns ErrConcurrentSessionUse from the method which uses the session concurrently
+	SessionGuardError
+	// SessionGuardPanic panics with ErrConcurrentSessionUse
+	SessionGuardPanic
+)
+
+// ErrConcurrentSessionUse represents a session is used by a goroutine while another goroutine
+// is still executing on it
+type ErrConcurrentSessionUse struct {
+	Owner     int64 // the goroutine which is executing on the session
+	Goroutine int64 // the goroutine which tries to use the session
+}
+
+func (err ErrConcurrentSessionUse) Error() string {
+	return fmt.Sprintf("session is used by goroutine %d while goroutine %d is executing on it, sessions are not goroutine safe",
+		err.Goroutine, err.Owner)
+}
+
+// EnableSessionGuard enables the detection of the concurrent use of the sessions. Every executing
+// method of a session records the goroutine which borrows the session, and the other goroutines
+// using the session before it's returned are reported according to the mode, including the
+// statement methods, i.e. Where or Cols, which change the statement being executed. A statement
+// could be built by a goroutine and executed by another one after it's handed off. As the
+// statement methods are chainable, the concurrent use found by them is returned by the next
+// executing method in SessionGuardError mode. It looks up the goroutine ids, so it's for the debugging and the tests
+// rather than the production.
+func (engine *Engine) EnableSessionGuard(mode SessionGuardMode) {
+	engine.sessionGuard.Store(int32(mode))
+}
+
+// acquire borrows the session for the current goroutine, the nested calls of the same goroutine
+// are allowed
+func (session *Session) acquire() error {
+	if err := session.checkExpired(); err != nil {
+		return err
+	}
+
+	mode := SessionGuardMode(session.engine.sessionGuard.Load())
+	if mode == SessionGuardOff {
+		return nil
+	}
+
+	if err := session.guardErr.Swap(nil); err != nil {
+		// the statement built concurrently is dropped
+		session.resetStatement()
+		return *err
+	}
+
+	gid := utils.GoroutineID()
+	if session.guardOwner.CompareAndSwap(0, gid) || session.guardOwner.Load() == gid {
+		session.guardDepth++
+		return nil
+	}
+	return session.reportConcurrentUse(mode, session.guardOwner.Load(), gid)
+}
+
+// guardStatement reports the statement methods called by a goroutine while another goroutine
+// is executing on the session
+func (session *Session) guardStatement() {
+	mode := SessionGuardMode(session.engine.sessionGuard.Load())
+	if mode == SessionGuardOff {
+		return
+	}
+
+	gid := utils.GoroutineID()
+	if owner := session.guardOwner.Load(); owner != 0 && owner != gid {
+		err := session.reportConcurrentUse(mode, owner, gid)
+		session.guardErr.CompareAndSwap(nil, &err)
+	}
+}
+
+func (session *Session) reportConcurrentUse(mode SessionGuardMode, owner, gid int64) ErrConcurrentSessionUse {
+	err := ErrConcurrentSessionUse{Owner: owner, Goroutine: gid}
+	if mode == SessionGuardPanic {
+		panic(err)
+	}
+	return err
+}
+
+// release returns the session borrowed by acquire
+func (session *Session) release() {
+	if session.guardOwner.Load() == 0 || session.guardOwner.Load() != utils.GoroutineID() {
+		return
+	}
+	session.guardDepth--
+	if session.guardDepth == 0 {
+		session.guardOwner.Store(0)
+	}
+}
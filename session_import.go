@@ -0,0 +1,186 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/imkos/xorm/internal/utils"
+	"github.com/imkos/xorm/schemas"
+)
+
+// ImportOptions represents the options of ImportStream
+type ImportOptions struct {
+	// BatchSize commits a transaction every BatchSize statements, 0 means ImportStream
+	// will not begin any transaction. It's ignored if the session is in a transaction.
+	BatchSize int
+	// ContinueOnError executes the remaining statements when one fails, all the errors
+	// will be returned as ImportErrors. It should not be used with transactions on
+	// the databases like Postgres which abort the transaction on error.
+	ContinueOnError bool
+	// Progress is called after every statement has been executed
+	Progress func(ImportProgress)
+}
+
+// ImportProgress represents the progress of ImportStream
+type ImportProgress struct {
+	// Statements is the number of the executed statements
+	Statements int
+	// Failed is the number of the failed statements
+	Failed int
+	// Bytes is the number of the bytes read from the reader
+	Bytes int64
+}
+
+// ImportError represents an error of a statement executed by ImportStream
+type ImportError struct {
+	// Index is the index of the statement in the script, it begins from 0
+	Index int
+	// Line is the line number where the statement begins
+	Line int
+	SQL  string
+	Err  error
+}
+
+func (err *ImportError) Error() string {
+	return fmt.Sprintf("import statement %d at line %d failed: %v", err.Index, err.Line, err.Err)
+}
+
+// Unwrap returns the error of the database
+func (err *ImportError) Unwrap() error {
+	return err.Err
+}
+
+// ImportErrors represents all the errors of ImportStream with ContinueOnError
+type ImportErrors []*ImportError
+
+func (errs ImportErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more errors)", errs[0], len(errs)-1)
+}
+
+// countingReader counts the bytes read from the reader
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+var noBackslashEscapesRegexp = regexp.MustCompile(`(?is)^SET\s+(SESSION\s+|@@)?sql_mode\s*=.*NO_BACKSLASH_ESCAPES`)
+
+// newSQLSplitter creates a splitter which understands the syntax of the database
+func newSQLSplitter(r io.Reader, dbType schemas.DBType) *utils.SQLSplitter {
+	splitter := utils.NewSQLSplitter(r)
+	switch dbType {
+	case schemas.MYSQL:
+		splitter.BackslashEscape = true
+		splitter.HashComment = true
+		splitter.DelimiterCommand = true
+	case schemas.POSTGRES:
+		splitter.DollarQuote = true
+	case schemas.MSSQL:
+		splitter.BatchSeparator = "GO"
+	}
+	return splitter
+}
+
+// ImportStream executes the SQL script read from r statement by statement. The script
+// is split with the syntax of the database, so the delimiters in string literals,
+// comments, dollar quoted strings, BEGIN ... END blocks are handled, and the DELIMITER
+// command of MySQL and the GO separator of SQL Server are supported.
+func (session *Session) ImportStream(r io.Reader, opts ImportOptions) ([]sql.Result, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return nil, err
+	}
+	defer session.release()
+	// the imported statements may change any table
+	defer session.invalidateSchemaCache()
+
+	var (
+		reader   = &countingReader{Reader: r}
+		splitter = newSQLSplitter(reader, session.engine.dialect.URI().DBType)
+		results  []sql.Result
+		errs     ImportErrors
+		progress ImportProgress
+		inBatch  bool
+	)
+
+	useBatch := opts.BatchSize > 0 && session.isAutoCommit
+	rollback := func() {
+		if inBatch {
+			_ = session.Rollback()
+		}
+	}
+
+	for i := 0; ; i++ {
+		query, err := splitter.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			rollback()
+			return nil, err
+		}
+
+		if useBatch && !inBatch {
+			if err := session.Begin(); err != nil {
+				return nil, err
+			}
+			inBatch = true
+		}
+
+		result, err := session.Exec(query)
+		progress.Statements++
+		if err != nil {
+			importErr := &ImportError{Index: i, Line: splitter.Line(), SQL: query, Err: err}
+			if !opts.ContinueOnError {
+				rollback()
+				return nil, importErr
+			}
+			errs = append(errs, importErr)
+			progress.Failed++
+		} else {
+			results = append(results, result)
+		}
+
+		if splitter.BackslashEscape && noBackslashEscapesRegexp.MatchString(query) {
+			splitter.BackslashEscape = false
+		}
+
+		if inBatch && progress.Statements%opts.BatchSize == 0 {
+			inBatch = false
+			if err := session.Commit(); err != nil {
+				return nil, err
+			}
+		}
+
+		if opts.Progress != nil {
+			progress.Bytes = reader.n
+			opts.Progress(progress)
+		}
+	}
+
+	if inBatch {
+		if err := session.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
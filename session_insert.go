@@ -24,12 +24,13 @@ var ErrNoElementsOnSlice = errors.New("no element on slice when insert")
 
 // Insert insert one or more beans
 func (session *Session) Insert(beans ...interface{}) (int64, error) {
-	var affected int64
-	var err error
-
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	session.autoResetStatement = false
 	defer func() {
@@ -37,6 +38,27 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 		session.resetStatement()
 	}()
 
+	// wrap the beans in one transaction so that they are all inserted or none
+	if len(beans) > 1 && session.isAutoCommit && session.engine.multiInsertTx {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+		affected, err := session.insertBeans(beans)
+		if err != nil {
+			_ = session.Rollback()
+			session.afterInsertBeans = make(map[interface{}]*[]func(interface{}))
+			return 0, err
+		}
+		return affected, session.Commit()
+	}
+
+	return session.withProcessorSavepoint(func() (int64, error) {
+		return session.insertBeans(beans)
+	})
+}
+
+func (session *Session) insertBeans(beans []interface{}) (int64, error) {
+	var affected int64
 	for _, bean := range beans {
 		var cnt int64
 		var err error
@@ -63,7 +85,7 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 		affected += cnt
 	}
 
-	return affected, err
+	return affected, nil
 }
 
 func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, error) {
@@ -84,6 +106,9 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 	if len(tableName) == 0 {
 		return 0, ErrTableNotFound
 	}
+	if err := session.statement.CheckColumns(); err != nil {
+		return 0, err
+	}
 
 	var (
 		table          = session.statement.RefTable
@@ -106,13 +131,27 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 		var colPlaces []string
 
 		// handle BeforeInsertProcessor
-		// !nashtsai! does user expect it's same slice to passed closure when using Before()/After() when insert multi??
-		for _, closure := range session.beforeClosures {
-			closure(elemValue)
-		}
+		if err := session.runProcessors(func() error {
+			// !nashtsai! does user expect it's same slice to passed closure when using Before()/After() when insert multi??
+			for _, closure := range session.beforeClosures {
+				closure(elemValue)
+			}
 
-		if processor, ok := interface{}(elemValue).(BeforeInsertProcessor); ok {
-			processor.BeforeInsert()
+			if processor, ok := interface{}(elemValue).(BeforeInsertProcessor); ok {
+				processor.BeforeInsert()
+			}
+			if processor, ok := interface{}(elemValue).(BeforeInsertSessionProcessor); ok {
+				return processor.BeforeInsert(session)
+			}
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+		if err := session.setDiscriminator(table, &vv); err != nil {
+			return 0, err
+		}
+		if err := session.validateElem(v); err != nil {
+			return 0, err
 		}
 		// --
 
@@ -151,7 +190,7 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 				}
 			}
 			if (col.IsCreated || col.IsUpdated) && session.statement.UseAutoTime {
-				val, t, err := session.engine.nowTime(col)
+				val, t, err := session.nowTime(col)
 				if err != nil {
 					return 0, err
 				}
@@ -238,6 +277,10 @@ func (session *Session) InsertMulti(rowsSlicePtr interface{}) (int64, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	sliceValue := reflect.Indirect(reflect.ValueOf(rowsSlicePtr))
 	if sliceValue.Kind() != reflect.Slice {
@@ -247,6 +290,20 @@ func (session *Session) InsertMulti(rowsSlicePtr interface{}) (int64, error) {
 	return session.insertMultipleStruct(rowsSlicePtr)
 }
 
+// setDiscriminator sets the discriminator field of the struct so that the record could be
+// found by the queries of the struct
+func (session *Session) setDiscriminator(table *schemas.Table, dataStruct *reflect.Value) error {
+	col := table.DiscriminatorColumn()
+	if col == nil {
+		return nil
+	}
+	fieldValue, err := col.ValueOfV(dataStruct)
+	if err != nil {
+		return err
+	}
+	return col.SetDiscriminator(*fieldValue)
+}
+
 func (session *Session) insertStruct(bean interface{}) (int64, error) {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return 0, err
@@ -254,20 +311,38 @@ func (session *Session) insertStruct(bean interface{}) (int64, error) {
 	if len(session.statement.TableName()) == 0 {
 		return 0, ErrTableNotFound
 	}
+	if err := session.statement.CheckColumns(); err != nil {
+		return 0, err
+	}
 
 	// handle BeforeInsertProcessor
-	for _, closure := range session.beforeClosures {
-		closure(bean)
-	}
-	cleanupProcessorsClosures(&session.beforeClosures) // cleanup after used
+	if err := session.runProcessors(func() error {
+		for _, closure := range session.beforeClosures {
+			closure(bean)
+		}
+		cleanupProcessorsClosures(&session.beforeClosures) // cleanup after used
 
-	if processor, ok := interface{}(bean).(BeforeInsertProcessor); ok {
-		processor.BeforeInsert()
+		if processor, ok := interface{}(bean).(BeforeInsertProcessor); ok {
+			processor.BeforeInsert()
+		}
+		if processor, ok := interface{}(bean).(BeforeInsertSessionProcessor); ok {
+			return processor.BeforeInsert(session)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
 	}
-
 	tableName := session.statement.TableName()
 	table := session.statement.RefTable
 
+	beanValue := reflect.Indirect(reflect.ValueOf(bean))
+	if err := session.setDiscriminator(table, &beanValue); err != nil {
+		return 0, err
+	}
+	if err := session.validate(bean); err != nil {
+		return 0, err
+	}
+
 	colNames, args, err := session.genInsertColumns(bean)
 	if err != nil {
 		return 0, err
@@ -306,13 +381,17 @@ func (session *Session) insertStruct(bean interface{}) (int64, error) {
 		cleanupProcessorsClosures(&session.afterClosures) // cleanup after used
 	}
 
-	// if there is auto increment column and driver don't support return it
-	if len(table.AutoIncrement) > 0 && !session.engine.driver.Features().SupportReturnInsertedID {
+	// if there is auto increment column and driver don't support return it, snowflake has no
+	// way to read the generated id so the insert is executed and the id is not written back
+	if len(table.AutoIncrement) > 0 && !session.engine.driver.Features().SupportReturnInsertedID &&
+		session.engine.dialect.URI().DBType != schemas.SNOWFLAKE {
 		var sql string
 		var newArgs []interface{}
 		var needCommit bool
 		var id int64
-		if session.engine.dialect.URI().DBType == schemas.ORACLE || session.engine.dialect.URI().DBType == schemas.DAMENG {
+		idQueryer, _ := session.engine.dialect.(dialects.LastInsertIDQueryer)
+		if dbType := session.engine.dialect.URI().DBType; dbType == schemas.ORACLE || dbType == schemas.DAMENG || dbType == schemas.HANA || dbType == schemas.DB2 ||
+			(idQueryer != nil && idQueryer.LastInsertIDSQL(tableName) != "") {
 			if session.isAutoCommit { // if it's not in transaction
 				if err := session.Begin(); err != nil {
 					return 0, err
@@ -329,6 +408,16 @@ func (session *Session) insertStruct(bean interface{}) (int64, error) {
 				if err != nil {
 					return 0, err
 				}
+			} else if idQueryer != nil && idQueryer.LastInsertIDSQL(tableName) != "" {
+				sql = idQueryer.LastInsertIDSQL(tableName)
+			} else if dbType == schemas.HANA {
+				// the identity value is kept per connection, so it's read in the same transaction
+				sql = "SELECT CURRENT_IDENTITY_VALUE() FROM DUMMY"
+			} else if dbType == schemas.DB2 {
+				sql = "SELECT IDENTITY_VAL_LOCAL() FROM SYSIBM.SYSDUMMY1"
+			} else if session.engine.dialect.Features().AutoincrMode == dialects.IncrAutoincrMode {
+				// dameng with the identity columns
+				sql = "SELECT SCOPE_IDENTITY()"
 			} else {
 				sql = fmt.Sprintf("select %s.currval from dual", utils.SeqName(tableName))
 			}
@@ -429,6 +518,10 @@ func (session *Session) InsertOne(bean interface{}) (int64, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	return session.insertStruct(bean)
 }
@@ -443,6 +536,7 @@ func (session *Session) cacheInsert(table string) error {
 	}
 	session.engine.logger.Debugf("[cache] clear SQL: %v", table)
 	cacher.ClearIds(table)
+	session.publishInvalidation(table, false)
 	return nil
 }
 
@@ -470,6 +564,11 @@ func (session *Session) genInsertColumns(bean interface{}) ([]string, []interfac
 			continue
 		}
 
+		if col.IsDeletedFlag {
+			args = append(args, col.DeletedFlagValue(false))
+			colNames = append(colNames, col.Name)
+			continue
+		}
 		if col.IsDeleted {
 			zeroTime := time.Date(1, 1, 1, 0, 0, 0, 0, session.engine.DatabaseTZ)
 			arg, err := dialects.FormatColumnTime(session.engine.dialect, session.engine.DatabaseTZ, col, zeroTime)
@@ -501,7 +600,7 @@ func (session *Session) genInsertColumns(bean interface{}) ([]string, []interfac
 
 		if (col.IsCreated || col.IsUpdated) && session.statement.UseAutoTime /*&& isZero(fieldValue.Interface())*/ {
 			// if time is non-empty, then set to auto time
-			val, t, err := session.engine.nowTime(col)
+			val, t, err := session.nowTime(col)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -697,3 +796,81 @@ func (session *Session) insertMultipleMap(columns []string, argss [][]interface{
 	}
 	return affected, nil
 }
+
+// InsertSelect inserts the records selected by src into the table of the session and
+// returns the affected rows. src could be another *Session which has been prepared with
+// Table, Cols, Where and etc. or a *builder.Builder. The inserted columns are the columns
+// specified by Cols or all the non-autoincrement columns of the table, and they should
+// be matched with the selected columns. i.e.
+//
+//	engine.Table(new(ArchivedUser)).Cols("id", "name").
+//		InsertSelect(engine.Table(new(User)).Cols("id", "name").Where("status = ?", 0))
+func (session *Session) InsertSelect(src interface{}) (int64, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
+	if session.statement.LastError != nil {
+		return 0, session.statement.LastError
+	}
+
+	tableName := session.statement.TableName()
+	if len(tableName) == 0 {
+		return 0, ErrTableNotFound
+	}
+
+	var (
+		selectSQL     string
+		selectArgs    []interface{}
+		selectColumns = -1
+		err           error
+	)
+	switch t := src.(type) {
+	case *Session:
+		if t.isAutoClose {
+			defer t.Close()
+		}
+		defer t.resetStatement()
+		if t.statement.LastError != nil {
+			return 0, t.statement.LastError
+		}
+		selectSQL, selectArgs, selectColumns, err = t.statement.GenSelectSQL()
+	case *builder.Builder:
+		selectSQL, selectArgs, err = t.ToSQL()
+		selectSQL = session.statement.ReplaceQuote(selectSQL)
+	default:
+		return 0, ErrParamsType
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var colNames []string
+	if !session.statement.ColumnMap.IsEmpty() {
+		colNames = session.statement.ColumnMap
+	} else if table := session.statement.RefTable; table != nil {
+		for _, col := range table.Columns() {
+			if !col.IsAutoIncrement && !session.statement.OmitColumnMap.Contain(col.Name) {
+				colNames = append(colNames, col.Name)
+			}
+		}
+	}
+
+	sqlStr, args, err := session.statement.GenInsertSelectSQL(colNames, selectSQL, selectArgs, selectColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := session.cacheInsert(tableName); err != nil {
+		return 0, err
+	}
+
+	res, err := session.exec(sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -26,6 +26,10 @@ func (session *Session) Iterate(bean interface{}, fun IterFunc) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	session.autoResetStatement = false
 	defer func() {
@@ -65,6 +69,7 @@ func (session *Session) Iterate(bean interface{}, fun IterFunc) error {
 
 // BufferSize sets the buffersize for iterate
 func (session *Session) BufferSize(size int) *Session {
+	session.guardStatement()
 	session.statement.BufferSize = size
 	return session
 }
@@ -0,0 +1,108 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// JSONOptions represents the options of WriteJSON
+type JSONOptions struct {
+	// NDJSON writes a JSON object per line instead of a JSON array
+	NDJSON bool
+}
+
+// WriteJSON runs the query and streams the records to the writer as a JSON array of objects,
+// or newline delimited JSON objects with NDJSON. The keys of an object keep the order of the
+// columns and the values are the same as QueryInterface's, only one record is buffered.
+func (session *Session) WriteJSON(w io.Writer, opts JSONOptions) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+
+	sqlStr, args, err := session.statement.GenQuerySQL()
+	if err != nil {
+		return err
+	}
+
+	rows, err := session.queryRows(sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	fields, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	keys := make([][]byte, len(fields))
+	for i, field := range fields {
+		if keys[i], err = json.Marshal(field); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if !opts.NDJSON {
+		buf.WriteByte('[')
+	}
+	var count int
+	for rows.Next() {
+		record, err := session.engine.row2mapInterface(rows, types, fields)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 && !opts.NDJSON {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for i, field := range fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+			if err := enc.Encode(record[field]); err != nil {
+				return err
+			}
+			// Encode always appends a newline
+			buf.Truncate(buf.Len() - 1)
+		}
+		buf.WriteByte('}')
+		if opts.NDJSON {
+			buf.WriteByte('\n')
+		}
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		buf.Reset()
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !opts.NDJSON {
+		buf.WriteString("]\n")
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
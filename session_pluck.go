@@ -0,0 +1,96 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"reflect"
+
+	"xorm.io/builder"
+)
+
+// Pluck retrieves one column of the matched records into a slice, i.e.
+//
+//	var names []string
+//	err := engine.Table(new(User)).Where("age > ?", 18).Pluck("name", &names)
+//
+// the conditions, joins, orders and limits of the session are honoured.
+func (session *Session) Pluck(column string, slicePtr interface{}) error {
+	return session.pluck(column, false, slicePtr)
+}
+
+// PluckDistinct retrieves the distinct values of one column into a slice
+func (session *Session) PluckDistinct(column string, slicePtr interface{}) error {
+	return session.pluck(column, true, slicePtr)
+}
+
+func (session *Session) pluck(column string, distinct bool, slicePtr interface{}) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+	defer session.resetStatement()
+
+	if session.statement.LastError != nil {
+		return session.statement.LastError
+	}
+
+	sliceValue := reflect.ValueOf(slicePtr)
+	if sliceValue.Kind() != reflect.Ptr || sliceValue.Elem().Kind() != reflect.Slice {
+		return ErrPtrSliceType
+	}
+	sliceValue = sliceValue.Elem()
+	elemType := sliceValue.Type().Elem()
+
+	if !session.auditIdentifiers("column", column) {
+		return session.statement.LastError
+	}
+	// only the plucked column will be selected
+	session.statement.SelectStr = ""
+	session.statement.ColumnMap = []string{}
+	if distinct {
+		session.statement.Distinct(column)
+	} else {
+		session.statement.Cols(column)
+	}
+
+	var autoCond builder.Cond
+	if table := session.statement.RefTable; table != nil {
+		if col := table.DeletedColumn(); col != nil && !session.statement.GetUnscoped() { // tag "deleted" is enabled
+			autoCond = session.statement.CondDeleted(col)
+		}
+	}
+
+	sqlStr, args, err := session.statement.GenFindSQL(autoCond)
+	if err != nil {
+		return err
+	}
+
+	rows, err := session.queryRows(sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	fields, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		newValue := reflect.New(elemType)
+		if err := session.engine.scan(rows, fields, types, newValue.Interface()); err != nil {
+			return err
+		}
+		sliceValue.Set(reflect.Append(sliceValue, newValue.Elem()))
+	}
+	return rows.Err()
+}
@@ -5,38 +5,93 @@
 package xorm
 
 import (
+	"context"
 	"database/sql"
 	"strings"
+	"time"
 
 	"github.com/imkos/xorm/core"
 )
 
-func (session *Session) queryPreprocess(sqlStr *string, paramStr ...interface{}) {
+func (session *Session) queryPreprocess(sqlStr *string, args *[]interface{}) error {
 	for _, filter := range session.engine.dialect.Filters() {
 		*sqlStr = filter.Do(session.ctx, *sqlStr)
 	}
 
+	for _, interceptor := range session.engine.sqlInterceptors {
+		var err error
+		*sqlStr, *args, err = interceptor(session.ctx, *sqlStr, *args)
+		if err != nil {
+			return err
+		}
+	}
+
+	if session.engine.sqlCommenter != nil {
+		*sqlStr = appendSQLComment(*sqlStr, session.engine.sqlCommenter(session.ctx))
+	}
+
 	session.lastSQL = *sqlStr
-	session.lastSQLArgs = paramStr
+	session.lastSQLArgs = *args
+	return nil
 }
 
 func (session *Session) queryRows(sqlStr string, args ...interface{}) (*core.Rows, error) {
+	session.allColumnsQueried = session.statement.ColumnMap.IsEmpty() &&
+		session.statement.OmitColumnMap.IsEmpty() && session.statement.SelectStr == "" &&
+		session.statement.RawSQL == ""
 	defer session.resetStatement()
 	if session.statement.LastError != nil {
 		return nil, session.statement.LastError
 	}
 
-	session.queryPreprocess(&sqlStr, args...)
+	if err := session.queryPreprocess(&sqlStr, &args); err != nil {
+		return nil, err
+	}
 
-	session.lastSQL = sqlStr
-	session.lastSQLArgs = args
+	start := time.Now()
+	defer func() {
+		session.engine.slowQueries.record(sqlStr, time.Since(start))
+	}()
+	recordStats := func() {
+		session.stats.Queries++
+		session.stats.Duration += time.Since(start)
+	}
 
+	ctx, cancel := session.statementContext()
+	rows, err := session.queryRowsContext(ctx, sqlStr, args...)
+	if err != nil {
+		recordStats()
+		if cancel != nil {
+			cancel()
+		}
+		return nil, err
+	}
+	// the rows maybe read after return, so the duration including the fetching is recorded and
+	// the context is canceled when the rows are closed
+	rows.OnClose(recordStats)
+	if cancel != nil {
+		rows.OnClose(cancel)
+	}
+	return rows, nil
+}
+
+func (session *Session) queryRowsContext(ctx context.Context, sqlStr string, args ...interface{}) (*core.Rows, error) {
 	if session.isAutoCommit {
-		var db *core.DB
-		if session.sessionType == groupSession && strings.EqualFold(strings.TrimSpace(sqlStr)[:6], "select") && !session.statement.IsForUpdate {
-			db = session.engine.engineGroup.Slave().DB()
+		var db dbConn
+		if session.sessionType == groupSession {
+			isSelect := strings.EqualFold(strings.TrimSpace(sqlStr)[:6], "select")
+			db = session.dbConn()
+			if isSelect && !session.statement.IsForUpdate && session.conn == nil && session.readFromSlave() {
+				if slave := session.readSlave(); slave != nil {
+					db = slave.DB()
+				}
+			}
+			if !isSelect {
+				session.markWrite()
+				defer session.recordPosition()
+			}
 		} else {
-			db = session.DB()
+			db = session.dbConn()
 		}
 
 		if session.prepareStmt {
@@ -46,10 +101,10 @@ func (session *Session) queryRows(sqlStr string, args ...interface{}) (*core.Row
 				return nil, err
 			}
 
-			return stmt.QueryContext(session.ctx, args...)
+			return stmt.QueryContext(ctx, args...)
 		}
 
-		return db.QueryContext(session.ctx, sqlStr, args...)
+		return db.QueryContext(ctx, sqlStr, args...)
 	}
 
 	if session.prepareStmt {
@@ -58,10 +113,10 @@ func (session *Session) queryRows(sqlStr string, args ...interface{}) (*core.Row
 			return nil, err
 		}
 
-		return stmt.QueryContext(session.ctx, args...)
+		return stmt.QueryContext(ctx, args...)
 	}
 
-	return session.tx.QueryContext(session.ctx, sqlStr, args...)
+	return session.tx.QueryContext(ctx, sqlStr, args...)
 }
 
 func (session *Session) queryRow(sqlStr string, args ...interface{}) *core.Row {
@@ -73,6 +128,10 @@ func (session *Session) Query(sqlOrArgs ...interface{}) ([]map[string][]byte, er
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return nil, err
+	}
+	defer session.release()
 
 	sqlStr, args, err := session.statement.GenQuerySQL(sqlOrArgs...)
 	if err != nil {
@@ -93,6 +152,10 @@ func (session *Session) QueryString(sqlOrArgs ...interface{}) ([]map[string]stri
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return nil, err
+	}
+	defer session.release()
 
 	sqlStr, args, err := session.statement.GenQuerySQL(sqlOrArgs...)
 	if err != nil {
@@ -113,6 +176,10 @@ func (session *Session) QuerySliceString(sqlOrArgs ...interface{}) ([][]string,
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return nil, err
+	}
+	defer session.release()
 
 	sqlStr, args, err := session.statement.GenQuerySQL(sqlOrArgs...)
 	if err != nil {
@@ -133,6 +200,10 @@ func (session *Session) QueryInterface(sqlOrArgs ...interface{}) ([]map[string]i
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return nil, err
+	}
+	defer session.release()
 
 	sqlStr, args, err := session.statement.GenQuerySQL(sqlOrArgs...)
 	if err != nil {
@@ -148,13 +219,41 @@ func (session *Session) QueryInterface(sqlOrArgs ...interface{}) ([]map[string]i
 	return session.engine.ScanInterfaceMaps(rows)
 }
 
-func (session *Session) exec(sqlStr string, args ...interface{}) (sql.Result, error) {
+func (session *Session) exec(sqlStr string, args ...interface{}) (res sql.Result, err error) {
 	defer session.resetStatement()
 
-	session.queryPreprocess(&sqlStr, args...)
+	if err := session.queryPreprocess(&sqlStr, &args); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start)
+		session.stats.Queries++
+		session.stats.Duration += duration
+		session.engine.slowQueries.record(sqlStr, duration)
+		if err == nil && res != nil {
+			if affected, err := res.RowsAffected(); err == nil {
+				session.stats.RowsAffected += affected
+			}
+		}
+	}()
 
-	session.lastSQL = sqlStr
-	session.lastSQLArgs = args
+	ctx, cancel := session.statementContext()
+	if cancel != nil {
+		defer cancel()
+	}
+
+	if session.sessionType == groupSession {
+		session.markWrite()
+		if session.isAutoCommit {
+			defer func() {
+				if err == nil {
+					session.recordPosition()
+				}
+			}()
+		}
+	}
 
 	if !session.isAutoCommit {
 		if session.prepareStmt {
@@ -162,20 +261,20 @@ func (session *Session) exec(sqlStr string, args ...interface{}) (sql.Result, er
 			if err != nil {
 				return nil, err
 			}
-			return stmt.ExecContext(session.ctx, args...)
+			return stmt.ExecContext(ctx, args...)
 		}
-		return session.tx.ExecContext(session.ctx, sqlStr, args...)
+		return session.tx.ExecContext(ctx, sqlStr, args...)
 	}
 
 	if session.prepareStmt {
-		stmt, err := session.doPrepare(session.DB(), sqlStr)
+		stmt, err := session.doPrepare(session.dbConn(), sqlStr)
 		if err != nil {
 			return nil, err
 		}
-		return stmt.ExecContext(session.ctx, args...)
+		return stmt.ExecContext(ctx, args...)
 	}
 
-	return session.DB().ExecContext(session.ctx, sqlStr, args...)
+	return session.dbConn().ExecContext(ctx, sqlStr, args...)
 }
 
 // Exec raw sql
@@ -183,6 +282,10 @@ func (session *Session) Exec(sqlOrArgs ...interface{}) (sql.Result, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return nil, err
+	}
+	defer session.release()
 
 	if len(sqlOrArgs) == 0 {
 		return nil, ErrUnSupportedType
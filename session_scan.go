@@ -0,0 +1,36 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+// ScanStructs executes the raw SQL set by SQL() and scans the records into dest which should be a
+// pointer to a slice of structs or struct pointers. The columns are mapped to the fields like Find,
+// and a column labeled with a prefix, i.e. `SELECT u.id AS "user.id"`, is mapped to the field of
+// the extended struct named as the prefix, so the records of the joined tables could be scanned
+// into the structs extending all the tables.
+func (session *Session) ScanStructs(dest interface{}) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+
+	if session.statement.RawSQL == "" {
+		session.resetStatement()
+		return ErrNeedRawSQL
+	}
+	return session.NoCache().find(dest)
+}
+
+// QueryStructs executes the raw SQL set by SQL() on the session and returns the records as the
+// values of T, the columns are mapped as ScanStructs
+func QueryStructs[T any](session *Session) ([]T, error) {
+	var results []T
+	if err := session.ScanStructs(&results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
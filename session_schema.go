@@ -5,16 +5,15 @@
 package xorm
 
 import (
-	"bufio"
 	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
 	"github.com/imkos/xorm/dialects"
 	"github.com/imkos/xorm/internal/utils"
+	"github.com/imkos/xorm/schemas"
 )
 
 // Ping test if database is ok
@@ -22,6 +21,10 @@ func (session *Session) Ping() error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	session.engine.logger.Infof("PING DATABASE %v", session.engine.DriverName())
 	return session.DB().PingContext(session.ctx)
@@ -32,6 +35,10 @@ func (session *Session) CreateTable(bean interface{}) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	return session.createTable(bean)
 }
@@ -40,13 +47,19 @@ func (session *Session) createTable(bean interface{}) error {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return err
 	}
+	return session.createRefTable()
+}
 
+// createRefTable creates the statement's RefTable
+func (session *Session) createRefTable() error {
 	session.statement.RefTable.StoreEngine = session.statement.StoreEngine
 	session.statement.RefTable.Charset = session.statement.Charset
 	tableName := session.statement.TableName()
 	refTable := session.statement.RefTable
+	defer session.invalidateSchemaCache(tableName)
+
 	if refTable.AutoIncrement != "" && session.engine.dialect.Features().AutoincrMode == dialects.SequenceAutoincrMode {
-		sqlStr, err := session.engine.dialect.CreateSequenceSQL(context.Background(), session.engine.db, utils.SeqName(tableName))
+		sqlStr, err := session.engine.dialect.CreateSequenceSQL(context.Background(), session.engine.DB(), utils.SeqName(tableName))
 		if err != nil {
 			return err
 		}
@@ -55,7 +68,7 @@ func (session *Session) createTable(bean interface{}) error {
 		}
 	}
 
-	sqlStr, _, err := session.engine.dialect.CreateTableSQL(context.Background(), session.engine.db, refTable, tableName)
+	sqlStr, _, err := session.engine.dialect.CreateTableSQL(context.Background(), session.engine.DB(), refTable, tableName)
 	if err != nil {
 		return err
 	}
@@ -63,6 +76,19 @@ func (session *Session) createTable(bean interface{}) error {
 		return err
 	}
 
+	if refTable.AutoIncrement != "" && refTable.AutoIncrStart > 0 {
+		return session.setAutoIncrStart(tableName, refTable.AutoIncrement, refTable.AutoIncrStart)
+	}
+	return nil
+}
+
+// setAutoIncrStart changes the next value of the auto increment column of the table
+func (session *Session) setAutoIncrStart(tableName, colName string, start int64) error {
+	for _, sqlStr := range session.engine.dialect.SetAutoIncrStartSQL(tableName, colName, start) {
+		if _, err := session.exec(sqlStr); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -71,6 +97,10 @@ func (session *Session) CreateIndexes(bean interface{}) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	return session.createIndexes(bean)
 }
@@ -79,7 +109,11 @@ func (session *Session) createIndexes(bean interface{}) error {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return err
 	}
+	return session.createRefIndexes()
+}
 
+// createRefIndexes creates the indexes of the statement's RefTable
+func (session *Session) createRefIndexes() error {
 	sqls := session.statement.GenIndexSQL()
 	for _, sqlStr := range sqls {
 		_, err := session.exec(sqlStr)
@@ -95,6 +129,10 @@ func (session *Session) CreateUniques(bean interface{}) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 	return session.createUniques(bean)
 }
 
@@ -102,7 +140,11 @@ func (session *Session) createUniques(bean interface{}) error {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return err
 	}
+	return session.createRefUniques()
+}
 
+// createRefUniques creates the uniques of the statement's RefTable
+func (session *Session) createRefUniques() error {
 	sqls := session.statement.GenUniqueSQL()
 	for _, sqlStr := range sqls {
 		_, err := session.exec(sqlStr)
@@ -118,6 +160,10 @@ func (session *Session) DropIndexes(bean interface{}) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	return session.dropIndexes(bean)
 }
@@ -142,12 +188,18 @@ func (session *Session) DropTable(beanOrTableName interface{}) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	return session.dropTable(beanOrTableName)
 }
 
 func (session *Session) dropTable(beanOrTableName interface{}) error {
 	tableName := session.engine.TableName(beanOrTableName)
+	defer session.invalidateSchemaCache(tableName)
+
 	sqlStr, checkIfExist := session.engine.dialect.DropTableSQL(session.engine.TableName(tableName, true))
 	if !checkIfExist {
 		exist, err := session.engine.dialect.IsTableExist(session.getQueryer(), session.ctx, tableName)
@@ -190,6 +242,10 @@ func (session *Session) IsTableExist(beanOrTableName interface{}) (bool, error)
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return false, err
+	}
+	defer session.release()
 
 	tableName := session.engine.TableName(beanOrTableName)
 
@@ -197,7 +253,51 @@ func (session *Session) IsTableExist(beanOrTableName interface{}) (bool, error)
 }
 
 func (session *Session) isTableExist(tableName string) (bool, error) {
-	return session.engine.dialect.IsTableExist(session.getQueryer(), session.ctx, tableName)
+	// the schema changes in a transaction are not visible to the others before committing
+	useCache, key := session.isAutoCommit, session.engine.schemaCacheKey(tableName)
+	if useCache {
+		if exist, ok := session.engine.schemaCache.table(key); ok {
+			return exist, nil
+		}
+	}
+	exist, err := session.engine.dialect.IsTableExist(session.getQueryer(), session.ctx, tableName)
+	if err != nil {
+		return false, err
+	}
+	if useCache {
+		session.engine.schemaCache.setTable(key, exist)
+	}
+	return exist, nil
+}
+
+// IsColumnExist if a column of the table is exist
+func (session *Session) IsColumnExist(beanOrTableName interface{}, colName string) (bool, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return false, err
+	}
+	defer session.release()
+
+	return session.isColumnExist(session.engine.TableName(beanOrTableName), colName)
+}
+
+func (session *Session) isColumnExist(tableName, colName string) (bool, error) {
+	useCache, key := session.isAutoCommit, session.engine.schemaCacheKey(tableName)
+	if useCache {
+		if exist, ok := session.engine.schemaCache.column(key, colName); ok {
+			return exist, nil
+		}
+	}
+	exist, err := session.engine.dialect.IsColumnExist(session.getQueryer(), session.ctx, tableName, colName)
+	if err != nil {
+		return false, err
+	}
+	if useCache {
+		session.engine.schemaCache.setColumn(key, colName, exist)
+	}
+	return exist, nil
 }
 
 // IsTableEmpty if table have any records
@@ -205,6 +305,10 @@ func (session *Session) IsTableEmpty(bean interface{}) (bool, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return false, err
+	}
+	defer session.release()
 	return session.isTableEmpty(session.engine.TableName(bean))
 }
 
@@ -222,9 +326,11 @@ func (session *Session) isTableEmpty(tableName string) (bool, error) {
 	return total == 0, nil
 }
 
-func (session *Session) addColumn(colName string) error {
-	col := session.statement.RefTable.GetColumn(colName)
-	sql := session.engine.dialect.AddColumnSQL(session.statement.TableName(), col)
+func (session *Session) addColumn(col *schemas.Column) error {
+	tableName := session.statement.TableName()
+	defer session.invalidateSchemaCache(tableName)
+
+	sql := session.engine.dialect.AddColumnSQL(tableName, col)
 	_, err := session.exec(sql)
 	return err
 }
@@ -232,6 +338,9 @@ func (session *Session) addColumn(colName string) error {
 func (session *Session) addIndex(tableName, idxName string) error {
 	index := session.statement.RefTable.Indexes[idxName]
 	sqlStr := session.engine.dialect.CreateIndexSQL(tableName, index)
+	if sqlStr == "" {
+		return nil
+	}
 	_, err := session.exec(sqlStr)
 	return err
 }
@@ -239,6 +348,9 @@ func (session *Session) addIndex(tableName, idxName string) error {
 func (session *Session) addUnique(tableName, uqeName string) error {
 	index := session.statement.RefTable.Indexes[uqeName]
 	sqlStr := session.engine.dialect.CreateIndexSQL(tableName, index)
+	if sqlStr == "" {
+		return nil
+	}
 	_, err := session.exec(sqlStr)
 	return err
 }
@@ -255,64 +367,11 @@ func (session *Session) ImportFile(ddlPath string) ([]sql.Result, error) {
 
 // Import SQL DDL from io.Reader
 func (session *Session) Import(r io.Reader) ([]sql.Result, error) {
-	var (
-		results       []sql.Result
-		lastError     error
-		inSingleQuote bool
-		startComment  bool
-	)
-
-	scanner := bufio.NewScanner(r)
-	semiColSpliter := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-		oriInSingleQuote := inSingleQuote
-		for i, b := range data {
-			if startComment {
-				if b == '\n' {
-					startComment = false
-				}
-			} else {
-				if !inSingleQuote && i > 0 && data[i-1] == '-' && data[i] == '-' {
-					startComment = true
-					continue
-				}
-
-				if b == '\'' {
-					inSingleQuote = !inSingleQuote
-				}
-				if !inSingleQuote && b == ';' {
-					return i + 1, data[0:i], nil
-				}
-			}
-		}
-		// If we're at EOF, we have a final, non-terminated line. Return it.
-		if atEOF {
-			return len(data), data, nil
-		}
-		inSingleQuote = oriInSingleQuote
-		// Request more data.
-		return 0, nil, nil
-	}
-
-	scanner.Split(semiColSpliter)
-
-	for scanner.Scan() {
-		query := strings.Trim(scanner.Text(), " \t\n\r")
-		if len(query) > 0 {
-			result, err := session.Exec(query)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, result)
-		}
-	}
-
-	return results, lastError
+	return session.ImportStream(r, ImportOptions{})
 }
 
 func (session *Session) IndexHint(op, forType, indexerOrColName string) *Session {
+	session.guardStatement()
 	session.statement.IndexHint(op, forType, indexerOrColName)
 	return session
 }
@@ -7,15 +7,43 @@ package xorm
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/imkos/xorm/convert"
+)
+
+// AggFunc represents an aggregate function
+type AggFunc string
+
+// enumerates the common aggregate functions
+const (
+	AggCount AggFunc = "count"
+	AggSum   AggFunc = "sum"
+	AggMin   AggFunc = "min"
+	AggMax   AggFunc = "max"
+	AggAvg   AggFunc = "avg"
 )
 
+// Agg represents an aggregate expression like "sum(amount) AS total", the result
+// will be scanned into the field mapped to As. An empty Column means all the rows.
+type Agg struct {
+	Func   AggFunc
+	Column string
+	As     string
+}
+
 // Count counts the records. bean's non-empty fields
 // are conditions.
 func (session *Session) Count(bean ...interface{}) (int64, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	sqlStr, args, err := session.statement.GenCountSQL(bean...)
 	if err != nil {
@@ -33,16 +61,25 @@ func (session *Session) Count(bean ...interface{}) (int64, error) {
 
 // sum call sum some column. bean's non-empty fields are conditions.
 func (session *Session) sum(res interface{}, bean interface{}, columnNames ...string) error {
+	return session.aggregate(res, bean, "COALESCE(sum(%s),0)", columnNames...)
+}
+
+// aggregate call the aggregate expression on some columns. bean's non-empty fields are conditions.
+func (session *Session) aggregate(res interface{}, bean interface{}, exprFormat string, columnNames ...string) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
 
 	v := reflect.ValueOf(res)
 	if v.Kind() != reflect.Ptr {
 		return errors.New("need a pointer to a variable")
 	}
 
-	sqlStr, args, err := session.statement.GenSumSQL(bean, columnNames...)
+	sqlStr, args, err := session.statement.GenAggregateSQL(bean, exprFormat, columnNames...)
 	if err != nil {
 		return err
 	}
@@ -79,3 +116,110 @@ func (session *Session) SumsInt(bean interface{}, columnNames ...string) ([]int6
 	res := make([]int64, len(columnNames))
 	return res, session.sum(&res, bean, columnNames...)
 }
+
+// SumDecimal call sum some column and return as convert.Decimal to keep the precision
+func (session *Session) SumDecimal(bean interface{}, columnName string) (res convert.Decimal, err error) {
+	return res, session.sum(&res, bean, columnName)
+}
+
+// SumsDecimal sum specify columns and return as []convert.Decimal instead of []float64
+func (session *Session) SumsDecimal(bean interface{}, columnNames ...string) ([]convert.Decimal, error) {
+	res := make([]convert.Decimal, len(columnNames))
+	return res, session.sum(&res, bean, columnNames...)
+}
+
+// Avg call avg some column. bean's non-empty fields are conditions.
+func (session *Session) Avg(bean interface{}, columnName string) (res float64, err error) {
+	return res, session.aggregate(&res, bean, "COALESCE(avg(%s),0)", columnName)
+}
+
+// Min call min some column and assign the result to res. bean's non-empty fields are conditions.
+// res keeps unchanged if there is no record matched.
+func (session *Session) Min(bean interface{}, columnName string, res interface{}) error {
+	return session.aggregateNullable(res, bean, "min(%s)", columnName)
+}
+
+// Max call max some column and assign the result to res. bean's non-empty fields are conditions.
+// res keeps unchanged if there is no record matched.
+func (session *Session) Max(bean interface{}, columnName string, res interface{}) error {
+	return session.aggregateNullable(res, bean, "max(%s)", columnName)
+}
+
+// aggregateNullable call an aggregate expression which maybe return NULL on the column
+func (session *Session) aggregateNullable(res interface{}, bean interface{}, exprFormat string, columnName string) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+
+	if reflect.ValueOf(res).Kind() != reflect.Ptr {
+		return errors.New("need a pointer to a variable")
+	}
+
+	sqlStr, args, err := session.statement.GenAggregateSQL(bean, exprFormat, columnName)
+	if err != nil {
+		return err
+	}
+
+	rows, err := session.queryRows(sqlStr, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return rows.Err()
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	fields, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	return session.engine.scan(rows, fields, types, res)
+}
+
+// Aggregate selects the GROUP BY columns and the aggregate expressions and scans
+// every group as an element of results, which should be a pointer to a slice of
+// structs with the fields of group keys and aggregate results.
+func (session *Session) Aggregate(results interface{}, aggs []Agg) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	if err := session.acquire(); err != nil {
+		return err
+	}
+	defer session.release()
+
+	if len(aggs) == 0 {
+		return errors.New("at least one aggregate is needed")
+	}
+
+	quoter := session.engine.dialect.Quoter()
+	exprs := make([]string, 0, len(aggs)+1)
+	if session.statement.GroupByStr != "" {
+		exprs = append(exprs, session.statement.GroupByStr)
+	}
+	for _, agg := range aggs {
+		if agg.Func == "" || agg.As == "" {
+			return fmt.Errorf("aggregate on column %s should have both function and alias", agg.Column)
+		}
+		colName := "*"
+		if agg.Column != "" {
+			if !strings.Contains(agg.Column, " ") && !strings.Contains(agg.Column, "(") {
+				colName = quoter.Quote(agg.Column)
+			} else {
+				colName = session.statement.ReplaceQuote(agg.Column)
+			}
+		}
+		exprs = append(exprs, fmt.Sprintf("%s(%s) AS %s", agg.Func, colName, quoter.Quote(agg.As)))
+	}
+	session.statement.SelectStr = strings.Join(exprs, ", ")
+
+	return session.find(results)
+}
@@ -4,10 +4,16 @@
 
 package xorm
 
+import (
+	"fmt"
+
+	"github.com/imkos/xorm/schemas"
+)
+
 // Begin a transaction
 func (session *Session) Begin() error {
 	if session.isAutoCommit {
-		tx, err := session.DB().BeginTx(session.ctx, nil)
+		tx, err := session.dbConn().BeginTx(session.ctx, nil)
 		if err != nil {
 			return err
 		}
@@ -26,6 +32,7 @@ func (session *Session) Rollback() error {
 		session.saveLastSQL("ROLL BACK")
 		session.isCommitedOrRollbacked = true
 		session.isAutoCommit = true
+		session.afterCommitFuncs = nil
 
 		return session.tx.Rollback()
 	}
@@ -39,9 +46,17 @@ func (session *Session) Commit() error {
 		session.isCommitedOrRollbacked = true
 		session.isAutoCommit = true
 
+		afterCommitFuncs := session.afterCommitFuncs
+		session.afterCommitFuncs = nil
 		if err := session.tx.Commit(); err != nil {
 			return err
 		}
+		if session.sessionType == groupSession && session.hasWritten {
+			session.recordPosition()
+		}
+		for _, fn := range afterCommitFuncs {
+			fn()
+		}
 
 		// handle processors after tx committed
 		closureCallFunc := func(closuresPtr *[]func(interface{}), bean interface{}) {
@@ -85,7 +100,114 @@ func (session *Session) Commit() error {
 	return nil
 }
 
+// afterCommit calls fn after the transaction is committed, or at once if the session is not in
+// a transaction. The functions are dropped if the transaction is rolled back.
+func (session *Session) afterCommit(fn func()) {
+	if session.isAutoCommit {
+		fn()
+		return
+	}
+	session.afterCommitFuncs = append(session.afterCommitFuncs, fn)
+}
+
+// publishInvalidation publishes the cache invalidation after the transaction is committed, so
+// that the other instances don't cache the records before the changes are visible to them
+func (session *Session) publishInvalidation(tableName string, all bool, ids ...string) {
+	session.afterCommit(func() {
+		session.engine.publishInvalidation(tableName, all, ids...)
+	})
+}
+
 // IsInTx if current session is in a transaction
 func (session *Session) IsInTx() bool {
 	return !session.isAutoCommit
 }
+
+func (session *Session) savepointSQLs(name string) (savepoint, rollback, release string) {
+	switch session.engine.dialect.URI().DBType {
+	case schemas.MSSQL:
+		return "SAVE TRANSACTION " + name, "ROLLBACK TRANSACTION " + name, ""
+	case schemas.ORACLE, schemas.DAMENG:
+		return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, ""
+	default:
+		return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, "RELEASE SAVEPOINT " + name
+	}
+}
+
+func (session *Session) execSavepointSQL(sql string) error {
+	session.saveLastSQL(sql)
+	_, err := session.tx.ExecContext(session.ctx, sql)
+	return err
+}
+
+func beanKeys(beans map[interface{}]*[]func(interface{})) map[interface{}]bool {
+	keys := make(map[interface{}]bool, len(beans))
+	for bean := range beans {
+		keys[bean] = true
+	}
+	return keys
+}
+
+func removeNewBeans(beans map[interface{}]*[]func(interface{}), keys map[interface{}]bool) {
+	for bean := range beans {
+		if !keys[bean] {
+			delete(beans, bean)
+		}
+	}
+}
+
+// runProcessors runs the before processors of a bean, the flag is left set if they panic, so
+// that withProcessorSavepoint could tell their panics from the others
+func (session *Session) runProcessors(fn func() error) error {
+	session.inProcessors = true
+	err := fn()
+	session.inProcessors = false
+	return err
+}
+
+// withProcessorSavepoint executes fn in a savepoint if the session is in a transaction and
+// the processor savepoint is enabled. If fn returns an error or a processor panics, the changes
+// of fn will be rolled back to the savepoint and the after processors of fn will not be called.
+// The other panics are propagated.
+func (session *Session) withProcessorSavepoint(fn func() (int64, error)) (affected int64, err error) {
+	if session.isAutoCommit || !session.engine.processorSavepoint {
+		return fn()
+	}
+
+	session.savepointSeq++
+	savepoint, rollback, release := session.savepointSQLs(fmt.Sprintf("xorm_sp_%d", session.savepointSeq))
+	if err := session.execSavepointSQL(savepoint); err != nil {
+		return 0, err
+	}
+
+	insertBeans := beanKeys(session.afterInsertBeans)
+	updateBeans := beanKeys(session.afterUpdateBeans)
+	deleteBeans := beanKeys(session.afterDeleteBeans)
+	session.inProcessors = false
+	defer func() {
+		if r := recover(); r != nil {
+			if !session.inProcessors {
+				panic(r)
+			}
+			session.inProcessors = false
+			err = fmt.Errorf("panic in processors: %v", r)
+		}
+		if err != nil {
+			affected = 0
+			removeNewBeans(session.afterInsertBeans, insertBeans)
+			removeNewBeans(session.afterUpdateBeans, updateBeans)
+			removeNewBeans(session.afterDeleteBeans, deleteBeans)
+			if rbErr := session.execSavepointSQL(rollback); rbErr != nil {
+				session.engine.logger.Errorf("rollback to savepoint failed: %v", rbErr)
+				return
+			}
+		}
+		if release != "" {
+			if relErr := session.execSavepointSQL(release); relErr != nil && err == nil {
+				err = relErr
+			}
+		}
+	}()
+
+	return fn()
+}
@@ -61,9 +61,19 @@ func (session *Session) genAutoCond(condiBean interface{}) (builder.Cond, error)
 //	 You should call UseBool if you have bool to use.
 //	2.float32 & float64 may be not inexact as conditions
 func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int64, error) {
+	return session.withProcessorSavepoint(func() (int64, error) {
+		return session.update(bean, condiBean...)
+	})
+}
+
+func (session *Session) update(bean interface{}, condiBean ...interface{}) (int64, error) {
 	if session.isAutoClose {
 		defer session.Close()
 	}
+	if err := session.acquire(); err != nil {
+		return 0, err
+	}
+	defer session.release()
 
 	defer session.resetStatement()
 
@@ -75,12 +85,27 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	t := v.Type()
 
 	// handle before update processors
-	for _, closure := range session.beforeClosures {
-		closure(bean)
+	if err := session.runProcessors(func() error {
+		for _, closure := range session.beforeClosures {
+			closure(bean)
+		}
+		cleanupProcessorsClosures(&session.beforeClosures) // cleanup after used
+		if processor, ok := interface{}(bean).(BeforeUpdateProcessor); ok {
+			processor.BeforeUpdate()
+		}
+		if processor, ok := interface{}(bean).(BeforeUpdateSessionProcessor); ok {
+			return processor.BeforeUpdate(session)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
 	}
-	cleanupProcessorsClosures(&session.beforeClosures) // cleanup after used
-	if processor, ok := interface{}(bean).(BeforeUpdateProcessor); ok {
-		processor.BeforeUpdate()
+	// a partial update leaves the zero fields of the bean out, so only the beans updated with
+	// all the columns are validated
+	if session.statement.GetAllCols() {
+		if err := session.validate(bean); err != nil {
+			return 0, err
+		}
 	}
 	// --
 
@@ -119,6 +144,9 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	} else {
 		return 0, ErrParamsType
 	}
+	if err := session.statement.CheckColumns(); err != nil {
+		return 0, err
+	}
 
 	table := session.statement.RefTable
 
@@ -127,7 +155,7 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 			!session.statement.OmitColumnMap.Contain(table.Updated) {
 			colNames = append(colNames, session.engine.Quote(table.Updated)+" = ?")
 			col := table.UpdatedColumn()
-			val, t, err := session.engine.nowTime(col)
+			val, t, err := session.nowTime(col)
 			if err != nil {
 				return 0, err
 			}
@@ -170,7 +198,7 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	}
 
 	var (
-		cond     = session.statement.Conds().And(autoCond)
+		cond     = session.statement.Conds().And(autoCond).And(session.statement.DefaultScopeCond())
 		doIncVer = isStruct && (table != nil && table.Version != "" && session.statement.CheckVersion)
 		verValue *reflect.Value
 	)
@@ -206,6 +234,7 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 		session.engine.logger.Debugf("[cache] clear table: %v", tableName)
 		cacher.ClearIds(tableName)
 		cacher.ClearBeans(tableName)
+		session.publishInvalidation(tableName, true)
 	}
 
 	// handle after update processors
@@ -292,7 +321,7 @@ func (session *Session) genUpdateColumns(bean interface{}) ([]string, []interfac
 
 		if col.IsUpdated && session.statement.UseAutoTime /*&& isZero(fieldValue.Interface())*/ {
 			// if time is non-empty, then set to auto time
-			val, t, err := session.engine.nowTime(col)
+			val, t, err := session.nowTime(col)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -0,0 +1,65 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SQLCommenter returns the tags of the context which will be appended to the SQLs as a comment
+// in the sqlcommenter format, i.e. /*route='%2Fusers',traceparent='00-...-01'*/
+type SQLCommenter func(ctx context.Context) map[string]string
+
+// SetSQLCommenter sets the commenter to append trace metadata to every SQL, so that the slow
+// logs of the database could be linked to the traces of the application. Since the comments
+// are usually different for every request, it's not recommended to use it with Prepare.
+func (engine *Engine) SetSQLCommenter(commenter SQLCommenter) {
+	engine.sqlCommenter = commenter
+}
+
+// SetSQLCommenter sets the commenter for all the engines of the group
+func (eg *EngineGroup) SetSQLCommenter(commenter SQLCommenter) {
+	eg.Engine.SetSQLCommenter(commenter)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetSQLCommenter(commenter)
+	}
+}
+
+func sqlCommentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// appendSQLComment appends the tags to the sql as a comment according the sqlcommenter
+// specification, a sql which has already had a comment will not be changed.
+func appendSQLComment(sqlStr string, tags map[string]string) string {
+	if len(tags) == 0 || strings.Contains(sqlStr, "/*") {
+		return sqlStr
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	sqlStr = strings.TrimRight(sqlStr, "; \t\n")
+	buf.WriteString(sqlStr)
+	buf.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(sqlCommentEscape(k))
+		buf.WriteString("='")
+		buf.WriteString(sqlCommentEscape(tags[k]))
+		buf.WriteByte('\'')
+	}
+	buf.WriteString("*/")
+	return buf.String()
+}
@@ -5,8 +5,11 @@
 package xorm
 
 import (
+	"context"
 	"strings"
+	"sync"
 
+	"github.com/imkos/xorm/dialects"
 	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/schemas"
 )
@@ -19,6 +22,10 @@ type SyncOptions struct {
 	IgnoreIndices bool
 	// IgnoreDropIndices will not delete indices
 	IgnoreDropIndices bool
+	// Parallelism is the max number of the tables synced concurrently, each table is synced
+	// on its own session. 0 or 1 syncs the tables serially in order, and the tables are always
+	// synced serially in a transaction or with the table name specified by Table.
+	Parallelism int
 }
 
 type SyncResult struct{}
@@ -62,6 +69,22 @@ func (session *Session) Sync(beans ...interface{}) error {
 	return err
 }
 
+// tableColumn returns the column with its type in the existing table, which is different
+// from the type of the struct for the STRICT tables of sqlite
+func (engine *Engine) tableColumn(oriTable *schemas.Table, col *schemas.Column) *schemas.Column {
+	typer, ok := engine.dialect.(dialects.TableSQLTyper)
+	if !ok {
+		return col
+	}
+	tp := typer.TableSQLType(oriTable, col)
+	if tp == engine.dialect.SQLType(col) {
+		return col
+	}
+	tableCol := *col
+	tableCol.SQLType = schemas.SQLType{Name: tp}
+	return &tableCol
+}
+
 func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{}) (*SyncResult, error) {
 	engine := session.engine
 
@@ -83,110 +106,211 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 
 	var syncResult SyncResult
 
+	// load the columns and indexes of all the existing tables at once, so that
+	// there is no metadata round trip per table
+	oriTables := make([]*schemas.Table, 0, len(beans))
 	for _, bean := range beans {
-		v := utils.ReflectValue(bean)
-		table, err := engine.tagParser.ParseWithCache(v)
-		if err != nil {
-			return nil, err
+		oriTable := session.findSyncTable(tables, bean)
+		if oriTable != nil && !containsTable(oriTables, oriTable) {
+			oriTables = append(oriTables, oriTable)
 		}
-		var tbName string
-		if len(session.statement.AltTableName) > 0 {
-			tbName = session.statement.AltTableName
-		} else {
-			tbName = engine.TableName(bean)
+	}
+	if err = engine.loadTablesInfo(session.getQueryer(), session.ctx, engine.dialect, oriTables); err != nil {
+		return nil, err
+	}
+
+	if opts.Parallelism > 1 && len(beans) > 1 && session.isAutoCommit && len(session.statement.AltTableName) == 0 {
+		if err := session.syncParallel(opts, tables, beans); err != nil {
+			return nil, err
 		}
-		tbNameWithSchema := engine.tbNameWithSchema(tbName)
+		return &syncResult, nil
+	}
 
-		var oriTable *schemas.Table
-		for _, tb := range tables {
-			if strings.EqualFold(engine.tbNameWithSchema(tb.Name), engine.tbNameWithSchema(tbName)) {
-				oriTable = tb
-				break
-			}
+	if err := session.syncBeans(opts, tables, beans); err != nil {
+		return nil, err
+	}
+	return &syncResult, nil
+}
+
+// syncBeans syncs the tables of the beans one by one, tables are the existing tables in the
+// database whose metadata has been loaded. When several beans share a table, the table is
+// loaded again for the later beans since it has been changed by the previous ones.
+func (session *Session) syncBeans(opts SyncOptions, tables []*schemas.Table, beans []interface{}) error {
+	synced := make(map[string]bool, len(beans))
+	for _, bean := range beans {
+		table, err := session.statement.ParseTable(utils.ReflectValue(bean))
+		if err != nil {
+			return err
 		}
+		tbName := session.syncTableName(bean)
+		key := strings.ToLower(session.engine.tbNameWithSchema(tbName))
 
-		// this is a new table
-		if oriTable == nil {
-			err = session.StoreEngine(session.statement.StoreEngine).createTable(bean)
+		oriTable := session.findSyncTable(tables, bean)
+		if synced[key] {
+			currentTables, err := session.engine.dialect.GetTables(session.getQueryer(), session.ctx)
 			if err != nil {
-				return nil, err
+				return err
 			}
-
-			if !opts.IgnoreConstrains {
-				err = session.createUniques(bean)
-				if err != nil {
-					return nil, err
+			if oriTable = session.findSyncTable(currentTables, bean); oriTable != nil {
+				if err := session.engine.loadTableInfo(session.getQueryer(), session.ctx, session.engine.dialect, oriTable); err != nil {
+					return err
 				}
 			}
+		}
+
+		if err := session.syncTable(opts, table, tbName, oriTable, bean); err != nil {
+			return err
+		}
+		synced[key] = true
+	}
+	return nil
+}
 
-			if !opts.IgnoreIndices {
-				err = session.createIndexes(bean)
+// syncParallel syncs the tables of the beans on at most opts.Parallelism sessions concurrently,
+// the beans sharing a table are synced by the same session one by one, and the tables which
+// have not been started are skipped after the first error
+func (session *Session) syncParallel(opts SyncOptions, tables []*schemas.Table, beans []interface{}) error {
+	ctx, cancel := context.WithCancel(session.ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	var groups [][]interface{}
+	groupIndexes := make(map[string]int, len(beans))
+	for _, bean := range beans {
+		key := strings.ToLower(session.engine.tbNameWithSchema(session.syncTableName(bean)))
+		if i, ok := groupIndexes[key]; ok {
+			groups[i] = append(groups[i], bean)
+			continue
+		}
+		groupIndexes[key] = len(groups)
+		groups = append(groups, []interface{}{bean})
+	}
+
+	jobs := make(chan []interface{})
+	workers := opts.Parallelism
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				worker := session.engine.NewSession().Context(ctx)
+				worker.autoResetStatement = false
+				err := worker.syncBeans(opts, tables, group)
+				worker.Close()
 				if err != nil {
-					return nil, err
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
 				}
 			}
+		}()
+	}
+	for _, group := range groups {
+		jobs <- group
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
 
-			continue
+// syncTable syncs the table to the database, oriTable is the existing table in the database or nil,
+// bean is nil if the table is not parsed from a struct
+func (session *Session) syncTable(opts SyncOptions, table *schemas.Table, tbName string, oriTable *schemas.Table, bean interface{}) error {
+	engine := session.engine
+	tbNameWithSchema := engine.tbNameWithSchema(tbName)
+
+	// this is a new table
+	if oriTable == nil {
+		if bean != nil {
+			if err := session.statement.SetRefBean(bean); err != nil {
+				return err
+			}
+		} else {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
 		}
 
-		// this will modify an old table
-		if err = engine.loadTableInfo(session.ctx, oriTable); err != nil {
-			return nil, err
+		if err := session.createRefTable(); err != nil {
+			return err
 		}
 
-		// check columns
-		for _, col := range table.Columns() {
-			var oriCol *schemas.Column
-			for _, col2 := range oriTable.Columns() {
-				if strings.EqualFold(col.Name, col2.Name) {
-					oriCol = col2
-					break
-				}
+		if !opts.IgnoreConstrains {
+			if err := session.createRefUniques(); err != nil {
+				return err
 			}
+		}
 
-			// column is not exist on table
-			if oriCol == nil {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				if err = session.addColumn(col.Name); err != nil {
-					return nil, err
-				}
-				continue
+		if !opts.IgnoreIndices {
+			if err := session.createRefIndexes(); err != nil {
+				return err
 			}
+		}
+		return nil
+	}
 
-			err = nil
-			expectedType := engine.dialect.SQLType(col)
-			curType := engine.dialect.SQLType(oriCol)
-			if expectedType != curType {
-				if expectedType == schemas.Text &&
-					strings.HasPrefix(curType, schemas.Varchar) {
-					// currently only support mysql & postgres
-					if engine.dialect.URI().DBType == schemas.MYSQL ||
-						engine.dialect.URI().DBType == schemas.POSTGRES {
-						engine.logger.Infof("Table %s column %s change type from %s to %s\n",
-							tbNameWithSchema, col.Name, curType, expectedType)
-						_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
-					} else {
-						engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s\n",
-							tbNameWithSchema, col.Name, curType, expectedType)
-					}
-				} else if strings.HasPrefix(curType, schemas.Varchar) && strings.HasPrefix(expectedType, schemas.Varchar) {
-					if engine.dialect.URI().DBType == schemas.MYSQL {
-						if oriCol.Length < col.Length {
-							engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
-								tbNameWithSchema, col.Name, oriCol.Length, col.Length)
-							_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
-						}
-					}
+	// this will modify an old table
+	var err error
+	// check columns
+	for _, col := range table.Columns() {
+		var oriCol *schemas.Column
+		for _, col2 := range oriTable.Columns() {
+			if strings.EqualFold(col.Name, col2.Name) {
+				oriCol = col2
+				break
+			}
+		}
+		col = engine.tableColumn(oriTable, col)
+
+		// column is not exist on table
+		if oriCol == nil {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
+			if err = session.addColumn(col); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if oriCol.IsInvisible && !col.IsInvisible {
+			// keep the invisibility of the column which is made invisible on the database
+			invisibleCol := *col
+			invisibleCol.IsInvisible = true
+			col = &invisibleCol
+		} else if col.IsInvisible && !oriCol.IsInvisible && engine.dialect.URI().DBType == schemas.MYSQL {
+			engine.logger.Infof("Table %s column %s change to invisible", tbNameWithSchema, col.Name)
+			if _, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col)); err != nil {
+				return err
+			}
+		}
+
+		err = nil
+		expectedType := engine.dialect.SQLType(col)
+		curType := engine.dialect.SQLType(oriCol)
+		if expectedType != curType {
+			if expectedType == schemas.Text &&
+				strings.HasPrefix(curType, schemas.Varchar) {
+				// currently only support mysql & postgres
+				if engine.dialect.URI().DBType == schemas.MYSQL ||
+					engine.dialect.URI().DBType == schemas.POSTGRES {
+					engine.logger.Infof("Table %s column %s change type from %s to %s\n",
+						tbNameWithSchema, col.Name, curType, expectedType)
+					_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
 				} else {
-					if !(strings.HasPrefix(curType, expectedType) && curType[len(expectedType)] == '(') {
-						if !strings.EqualFold(schemas.SQLTypeName(curType), engine.dialect.Alias(schemas.SQLTypeName(expectedType))) {
-							engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s",
-								tbNameWithSchema, col.Name, curType, expectedType)
-						}
-					}
+					engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s\n",
+						tbNameWithSchema, col.Name, curType, expectedType)
 				}
-			} else if expectedType == schemas.Varchar {
+			} else if strings.HasPrefix(curType, schemas.Varchar) && strings.HasPrefix(expectedType, schemas.Varchar) {
 				if engine.dialect.URI().DBType == schemas.MYSQL {
 					if oriCol.Length < col.Length {
 						engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
@@ -194,99 +318,151 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 						_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
 					}
 				}
-			} else if col.Comment != oriCol.Comment {
-				if engine.dialect.URI().DBType == schemas.POSTGRES ||
-					engine.dialect.URI().DBType == schemas.MYSQL {
-					_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
+			} else {
+				if !(strings.HasPrefix(curType, expectedType) && curType[len(expectedType)] == '(') {
+					if !strings.EqualFold(schemas.SQLTypeName(curType), engine.dialect.Alias(schemas.SQLTypeName(expectedType))) {
+						engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s",
+							tbNameWithSchema, col.Name, curType, expectedType)
+					}
 				}
 			}
-
-			if col.Default != oriCol.Default {
-				switch {
-				case col.IsAutoIncrement: // For autoincrement column, don't check default
-				case (col.SQLType.Name == schemas.Bool || col.SQLType.Name == schemas.Boolean) &&
-					((strings.EqualFold(col.Default, "true") && oriCol.Default == "1") ||
-						(strings.EqualFold(col.Default, "false") && oriCol.Default == "0")):
-				default:
-					engine.logger.Warnf("Table %s Column %s db default is %s, struct default is %s",
-						tbName, col.Name, oriCol.Default, col.Default)
+		} else if expectedType == schemas.Varchar {
+			if engine.dialect.URI().DBType == schemas.MYSQL {
+				if oriCol.Length < col.Length {
+					engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
+						tbNameWithSchema, col.Name, oriCol.Length, col.Length)
+					_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
 				}
 			}
-			if col.Nullable != oriCol.Nullable {
-				engine.logger.Warnf("Table %s Column %s db nullable is %v, struct nullable is %v",
-					tbName, col.Name, oriCol.Nullable, col.Nullable)
+		} else if col.Comment != oriCol.Comment {
+			if engine.dialect.URI().DBType == schemas.POSTGRES ||
+				engine.dialect.URI().DBType == schemas.MYSQL {
+				_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
 			}
+		}
 
-			if err != nil {
-				return nil, err
+		if col.Default != oriCol.Default {
+			switch {
+			case col.IsAutoIncrement: // For autoincrement column, don't check default
+			case (col.SQLType.Name == schemas.Bool || col.SQLType.Name == schemas.Boolean) &&
+				((strings.EqualFold(col.Default, "true") && oriCol.Default == "1") ||
+					(strings.EqualFold(col.Default, "false") && oriCol.Default == "0")):
+			default:
+				engine.logger.Warnf("Table %s Column %s db default is %s, struct default is %s",
+					tbName, col.Name, oriCol.Default, col.Default)
 			}
 		}
+		if col.Nullable != oriCol.Nullable {
+			engine.logger.Warnf("Table %s Column %s db nullable is %v, struct nullable is %v",
+				tbName, col.Name, oriCol.Nullable, col.Nullable)
+		}
 
-		// indices found in orig table
-		foundIndexNames := make(map[string]bool)
-		// indices to be added
-		addedNames := make(map[string]*schemas.Index)
-
-		// drop indices that exist in orig and new table schema but are not equal
-		for name, index := range table.Indexes {
-			var oriIndex *schemas.Index
-			for name2, index2 := range oriTable.Indexes {
-				if index.Equal(index2) {
-					oriIndex = index2
-					foundIndexNames[name2] = true
-					break
-				}
-			}
+		if err != nil {
+			return err
+		}
+	}
 
-			if oriIndex == nil {
-				addedNames[name] = index
-			}
+	// the auto increment start value only moves forward, so the ids which have been used will not be reused
+	if table.AutoIncrement != "" && table.AutoIncrStart > oriTable.AutoIncrStart {
+		if err = session.setAutoIncrStart(tbNameWithSchema, table.AutoIncrement, table.AutoIncrStart); err != nil {
+			return err
 		}
+	}
 
-		// drop all indices that do not exist in new schema or have changed
-		for name2, index2 := range oriTable.Indexes {
-			if _, ok := foundIndexNames[name2]; !ok {
-				// ignore based on there type
-				if (index2.Type == schemas.IndexType && (opts.IgnoreIndices || opts.IgnoreDropIndices)) ||
-					(index2.Type == schemas.UniqueType && opts.IgnoreConstrains) {
-					// make sure we do not add a index with same name later
-					delete(addedNames, name2)
-					continue
-				}
+	// indices found in orig table
+	foundIndexNames := make(map[string]bool)
+	// indices to be added
+	addedNames := make(map[string]*schemas.Index)
 
-				sql := engine.dialect.DropIndexSQL(tbNameWithSchema, index2)
-				_, err = session.exec(sql)
-				if err != nil {
-					return nil, err
-				}
+	// drop indices that exist in orig and new table schema but are not equal
+	for name, index := range table.Indexes {
+		var oriIndex *schemas.Index
+		for name2, index2 := range oriTable.Indexes {
+			if index.Equal(index2) {
+				oriIndex = index2
+				foundIndexNames[name2] = true
+				break
 			}
 		}
 
-		// Add new indices because either they did not exist before or were dropped to update them
-		for name, index := range addedNames {
-			if index.Type == schemas.UniqueType && !opts.IgnoreConstrains {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				err = session.addUnique(tbNameWithSchema, name)
-			} else if index.Type == schemas.IndexType && !opts.IgnoreIndices {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				err = session.addIndex(tbNameWithSchema, name)
+		if oriIndex == nil {
+			addedNames[name] = index
+		}
+	}
+
+	// drop all indices that do not exist in new schema or have changed
+	for name2, index2 := range oriTable.Indexes {
+		if _, ok := foundIndexNames[name2]; !ok {
+			// ignore based on there type
+			if (index2.Type != schemas.UniqueType && (opts.IgnoreIndices || opts.IgnoreDropIndices)) ||
+				(index2.Type == schemas.UniqueType && opts.IgnoreConstrains) {
+				// make sure we do not add a index with same name later
+				delete(addedNames, name2)
+				continue
 			}
+
+			sql := engine.dialect.DropIndexSQL(tbNameWithSchema, index2)
+			if sql == "" {
+				continue
+			}
+			_, err = session.exec(sql)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
+	}
 
-		if opts.WarnIfDatabaseColumnMissed {
-			// check all the columns which removed from struct fields but left on database tables.
-			for _, colName := range oriTable.ColumnsSeq() {
-				if table.GetColumn(colName) == nil {
-					engine.logger.Warnf("Table %s has column %s but struct has not related field", engine.TableName(oriTable.Name, true), colName)
-				}
+	// Add new indices because either they did not exist before or were dropped to update them
+	for name, index := range addedNames {
+		if index.Type == schemas.UniqueType && !opts.IgnoreConstrains {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
+			err = session.addUnique(tbNameWithSchema, name)
+		} else if index.Type != schemas.UniqueType && !opts.IgnoreIndices {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
+			err = session.addIndex(tbNameWithSchema, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.WarnIfDatabaseColumnMissed {
+		// check all the columns which removed from struct fields but left on database tables.
+		for _, colName := range oriTable.ColumnsSeq() {
+			if table.GetColumn(colName) == nil {
+				engine.logger.Warnf("Table %s has column %s but struct has not related field", engine.TableName(oriTable.Name, true), colName)
 			}
 		}
 	}
+	return nil
+}
 
-	return &syncResult, nil
+// syncTableName returns the table name the bean will be synced to
+func (session *Session) syncTableName(bean interface{}) string {
+	if len(session.statement.AltTableName) > 0 {
+		return session.statement.AltTableName
+	}
+	return session.engine.TableName(bean)
+}
+
+// findSyncTable returns the existing table of the bean, or nil if the table is not exist
+func (session *Session) findSyncTable(tables []*schemas.Table, bean interface{}) *schemas.Table {
+	tbNameWithSchema := session.engine.tbNameWithSchema(session.syncTableName(bean))
+	for _, tb := range tables {
+		if strings.EqualFold(session.engine.tbNameWithSchema(tb.Name), tbNameWithSchema) {
+			return tb
+		}
+	}
+	return nil
+}
+
+func containsTable(tables []*schemas.Table, table *schemas.Table) bool {
+	for _, tb := range tables {
+		if tb == table {
+			return true
+		}
+	}
+	return false
 }
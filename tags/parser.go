@@ -17,6 +17,7 @@ import (
 	"github.com/imkos/xorm/caches"
 	"github.com/imkos/xorm/convert"
 	"github.com/imkos/xorm/dialects"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/names"
 	"github.com/imkos/xorm/schemas"
 )
@@ -37,15 +38,27 @@ type TableCollations interface {
 
 var tpTableCollations = reflect.TypeOf((*TableCollations)(nil)).Elem()
 
+// TableOptions is an interface that describes structs that provide the dialect specific table
+// options, i.e. map[string]string{"ROW_FORMAT": "COMPRESSED", "fillfactor": "70", "STRICT": ""}.
+// Every dialect only takes the options it knows, so the options of several databases could be
+// provided together.
+type TableOptions interface {
+	TableOptions() map[string]string
+}
+
+var tpTableOptions = reflect.TypeOf((*TableOptions)(nil)).Elem()
+
 // Parser represents a parser for xorm tag
 type Parser struct {
-	identifier   string
-	dialect      dialects.Dialect
-	columnMapper names.Mapper
-	tableMapper  names.Mapper
-	handlers     map[string]Handler
-	cacherMgr    *caches.Manager
-	tableCache   sync.Map // map[reflect.Type]*schemas.Table
+	identifier         string
+	fallbackIdentifier string // the tag which names the columns of the fields without the xorm tag
+	fieldSkipper       func(field reflect.StructField) bool
+	dialect            dialects.Dialect
+	columnMapper       names.Mapper
+	tableMapper        names.Mapper
+	handlers           map[string]Handler
+	cacherMgr          *caches.Manager
+	tableCache         sync.Map // map[reflect.Type]*schemas.Table
 }
 
 // NewParser creates a tag parser
@@ -82,12 +95,32 @@ func (parser *Parser) SetColumnMapper(mapper names.Mapper) {
 	parser.columnMapper = mapper
 }
 
+// GetIdentifier returns tag identifier
+func (parser *Parser) GetIdentifier() string {
+	return parser.identifier
+}
+
 // SetIdentifier sets tag identifier
 func (parser *Parser) SetIdentifier(identifier string) {
 	parser.ClearCaches()
 	parser.identifier = identifier
 }
 
+// SetFallbackIdentifier sets the identifier of the tag which names the column of a field
+// without the xorm tag, i.e. "json". The name before the first comma is used unless it's
+// empty or "-", the options like omitempty are ignored.
+func (parser *Parser) SetFallbackIdentifier(identifier string) {
+	parser.ClearCaches()
+	parser.fallbackIdentifier = identifier
+}
+
+// SetFieldSkipper sets a function to decide the fields which are not mapped to any column,
+// it's called for every exported field. A nil skipper maps all the exported fields.
+func (parser *Parser) SetFieldSkipper(skipper func(field reflect.StructField) bool) {
+	parser.ClearCaches()
+	parser.fieldSkipper = skipper
+}
+
 // ParseWithCache parse a struct with cache
 func (parser *Parser) ParseWithCache(v reflect.Value) (*schemas.Table, error) {
 	t := v.Type()
@@ -139,10 +172,15 @@ func addIndex(indexName string, table *schemas.Table, col *schemas.Column, index
 // ErrIgnoreField represents an error to ignore field
 var ErrIgnoreField = errors.New("field will be ignored")
 
+var geometryType = reflect.TypeOf((*geo.Geometry)(nil)).Elem()
+
 func (parser *Parser) getSQLTypeByType(t reflect.Type) (schemas.SQLType, error) {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	if reflect.PtrTo(t).Implements(geometryType) {
+		return schemas.SQLType{Name: schemas.Geometry}, nil
+	}
 	if t.Kind() == reflect.Struct {
 		v, ok := parser.tableCache.Load(t)
 		if ok {
@@ -178,6 +216,7 @@ func (parser *Parser) parseFieldWithNoTag(fieldIndex int, field reflect.StructFi
 		field.Name, sqlType, sqlType.DefaultLength,
 		sqlType.DefaultLength2, true)
 	col.FieldIndex = []int{fieldIndex}
+	col.FieldType = field.Type
 
 	if field.Type.Kind() == reflect.Int64 && (strings.ToUpper(col.FieldName) == "ID" || strings.HasSuffix(strings.ToUpper(col.FieldName), ".ID")) {
 		col.IsAutoIncrement = true
@@ -191,6 +230,7 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 	col := &schemas.Column{
 		FieldName:       field.Name,
 		FieldIndex:      []int{fieldIndex},
+		FieldType:       field.Type,
 		Nullable:        true,
 		IsPrimaryKey:    false,
 		IsAutoIncrement: false,
@@ -267,6 +307,9 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 	if col.Length2 == 0 {
 		col.Length2 = col.SQLType.DefaultLength2
 	}
+	if col.SQLType.Name == schemas.BitSet && col.Length == 0 {
+		col.Length = 64
+	}
 	if col.Name == "" {
 		col.Name = parser.columnMapper.Obj2Table(field.Name)
 	}
@@ -275,11 +318,18 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 		ctx.indexNames[col.Name] = schemas.UniqueType
 	} else if ctx.isIndex {
 		ctx.indexNames[col.Name] = schemas.IndexType
+	} else if ctx.isSpatial {
+		ctx.indexNames[col.Name] = schemas.SpatialType
 	}
 
 	for indexName, indexType := range ctx.indexNames {
 		addIndex(indexName, table, col, indexType)
 	}
+	for _, indexName := range ctx.invisibleIndexNames {
+		if index, ok := table.Indexes[indexName]; ok {
+			index.IsInvisible = true
+		}
+	}
 
 	return col, nil
 }
@@ -288,6 +338,9 @@ func (parser *Parser) parseField(table *schemas.Table, fieldIndex int, field ref
 	if isNotTitle(field.Name) {
 		return nil, ErrIgnoreField
 	}
+	if parser.fieldSkipper != nil && parser.fieldSkipper(field) {
+		return nil, ErrIgnoreField
+	}
 
 	var (
 		tag       = field.Tag
@@ -297,7 +350,14 @@ func (parser *Parser) parseField(table *schemas.Table, fieldIndex int, field ref
 		return nil, ErrIgnoreField
 	}
 	if ormTagStr == "" {
-		return parser.parseFieldWithNoTag(fieldIndex, field, fieldValue)
+		col, err := parser.parseFieldWithNoTag(fieldIndex, field, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		if name := parser.fallbackName(field); name != "" {
+			col.Name = name
+		}
+		return col, nil
 	}
 	tags, err := splitTag(ormTagStr)
 	if err != nil {
@@ -306,6 +366,19 @@ func (parser *Parser) parseField(table *schemas.Table, fieldIndex int, field ref
 	return parser.parseFieldWithTags(table, fieldIndex, field, fieldValue, tags)
 }
 
+// fallbackName returns the column name in the fallback tag of the field
+func (parser *Parser) fallbackName(field reflect.StructField) string {
+	if parser.fallbackIdentifier == "" {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get(parser.fallbackIdentifier), ",")
+	name = strings.TrimSpace(name)
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
 func isNotTitle(n string) bool {
 	for _, c := range n {
 		return unicode.IsLower(c)
@@ -326,7 +399,11 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 
 	table := schemas.NewEmptyTable()
 	table.Type = t
-	table.Name = names.GetTableName(parser.tableMapper, v)
+	var dbType schemas.DBType
+	if uri := parser.dialect.URI(); uri != nil {
+		dbType = uri.DBType
+	}
+	table.Name = names.GetTableNameFor(parser.tableMapper, v, dbType)
 	table.Comment = names.GetTableComment(v)
 
 	for i := 0; i < t.NumField(); i++ {
@@ -344,7 +421,7 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 	for _, index := range indices {
 		// Override old information
 		if oldIndex, ok := table.Indexes[index.Name]; ok {
-			for _, colName := range oldIndex.Cols {
+			for _, colName := range oldIndex.Columns() {
 				col := table.GetColumn(colName)
 				if col == nil {
 					return nil, ErrUnsupportedType
@@ -353,7 +430,7 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 			}
 		}
 		table.AddIndex(index)
-		for _, colName := range index.Cols {
+		for _, colName := range index.Columns() {
 			col := table.GetColumn(colName)
 			if col == nil {
 				return nil, ErrUnsupportedType
@@ -378,6 +455,8 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 		}
 	}
 
+	table.Options = tableOptions(v)
+
 	return table, nil
 }
 
@@ -400,6 +479,25 @@ func tableIndices(v reflect.Value) []*schemas.Index {
 	return nil
 }
 
+func tableOptions(v reflect.Value) map[string]string {
+	if v.Type().Implements(tpTableOptions) {
+		return v.Interface().(TableOptions).TableOptions()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpTableOptions) {
+			return v.Interface().(TableOptions).TableOptions()
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpTableOptions) {
+			return v1.Interface().(TableOptions).TableOptions()
+		}
+	}
+	return nil
+}
+
 func tableCollations(v reflect.Value) []*schemas.Collation {
 	if v.Type().Implements(tpTableCollations) {
 		return v.Interface().(TableCollations).TableCollations()
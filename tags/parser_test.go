@@ -307,6 +307,109 @@ func TestParseWithExtends(t *testing.T) {
 	assert.True(t, table.Columns()[3].IsDeleted)
 }
 
+func TestParseWithExtendsPrefix(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	type AuditFields struct {
+		CreatedBy string `db:"index"`
+	}
+
+	type AddressFields struct {
+		City   string      `db:"index(city_street)"`
+		Street string      `db:"index(city_street)"`
+		Audit  AuditFields `db:"extends(audit_)"`
+	}
+
+	type StructWithAddresses struct {
+		Id       int64
+		Billing  AddressFields  `db:"extends(billing_)"`
+		Shipping *AddressFields `db:"extends('shipping_')"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithAddresses)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{
+		"id",
+		"billing_city", "billing_street", "billing_audit_created_by",
+		"shipping_city", "shipping_street", "shipping_audit_created_by",
+	}, table.ColumnsSeq())
+	assert.EqualValues(t, "Billing.Audit.CreatedBy", table.GetColumn("billing_audit_created_by").FieldName)
+	assert.EqualValues(t, []int{1, 2, 0}, table.GetColumn("billing_audit_created_by").FieldIndex)
+	assert.True(t, table.GetColumn("shipping_city").Nullable)
+
+	assert.Len(t, table.Indexes, 4)
+	assert.EqualValues(t, []string{"billing_city", "billing_street"}, table.Indexes["billing_city_street"].Cols)
+	assert.EqualValues(t, []string{"shipping_city", "shipping_street"}, table.Indexes["shipping_city_street"].Cols)
+	assert.EqualValues(t, []string{"billing_audit_created_by"}, table.Indexes["billing_audit_created_by"].Cols)
+	assert.EqualValues(t, []string{"shipping_audit_created_by"}, table.Indexes["shipping_audit_created_by"].Cols)
+	assert.EqualValues(t, schemas.IndexType, table.GetColumn("billing_city").Indexes["billing_city_street"])
+
+	type StructWithAddress struct {
+		Id      int64
+		Billing AddressFields `db:"extends(billing_)"`
+	}
+
+	table, err = parser.Parse(reflect.ValueOf(new(StructWithAddress)))
+	assert.NoError(t, err)
+	assert.Len(t, table.Indexes, 2)
+	assert.EqualValues(t, []string{"billing_city", "billing_street"}, table.Indexes["billing_city_street"].Cols)
+	assert.EqualValues(t, []string{"billing_audit_created_by"}, table.Indexes["billing_audit_created_by"].Cols)
+}
+
+func TestParseWithFallbackTag(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+	parser.SetFallbackIdentifier("json")
+
+	type StructWithJSON struct {
+		Id        int64
+		UserName  string `json:"login,omitempty"`
+		Password  string `json:"-"`
+		Nick      string `json:",omitempty"`
+		CreatedAt int64  `json:"created" db:"'create_time'"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithJSON)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"id", "login", "password", "nick", "create_time"}, table.ColumnsSeq())
+	assert.True(t, table.GetColumn("id").IsPrimaryKey)
+}
+
+func TestParseWithFieldSkipper(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+	parser.SetFieldSkipper(func(field reflect.StructField) bool {
+		return strings.HasPrefix(field.Name, "X")
+	})
+
+	type StructWithSkipped struct {
+		Id      int64
+		Name    string
+		XCache  string
+		XLoaded bool `db:"bool"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithSkipped)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"id", "name"}, table.ColumnsSeq())
+}
+
 func TestParseWithCache(t *testing.T) {
 	parser := NewParser(
 		"db",
@@ -617,3 +720,36 @@ func TestParseWithSQLType(t *testing.T) {
 	assert.EqualValues(t, "DATETIME", table.Columns()[3].SQLType.Name)
 	assert.EqualValues(t, "UUID", table.Columns()[4].SQLType.Name)
 }
+
+func TestParseWithEpochUnit(t *testing.T) {
+	parser := NewParser(
+		"xorm",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithEpochUnit struct {
+		Created int64 `xorm:"created(ms)"`
+		Updated int64 `xorm:"updated(NS)"`
+		Deleted int64 `xorm:"deleted"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithEpochUnit)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, time.Millisecond, table.GetColumn("created").EpochUnit)
+	assert.EqualValues(t, time.Nanosecond, table.GetColumn("updated").EpochUnit)
+	assert.EqualValues(t, 0, table.GetColumn("deleted").EpochUnit)
+
+	now := time.Date(2023, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	assert.EqualValues(t, now.UnixNano()/1e6, table.GetColumn("created").Epoch(now))
+	assert.EqualValues(t, now.UnixNano(), table.GetColumn("updated").Epoch(now))
+	assert.EqualValues(t, now.Unix(), table.GetColumn("deleted").Epoch(now))
+
+	type StructWithUnknownEpochUnit struct {
+		Created int64 `xorm:"created(h)"`
+	}
+	_, err = parser.Parse(reflect.ValueOf(new(StructWithUnknownEpochUnit)))
+	assert.Error(t, err)
+}
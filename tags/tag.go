@@ -5,6 +5,7 @@
 package tags
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -81,19 +82,21 @@ func splitTag(tagStr string) ([]tag, error) {
 // Context represents a context for xorm tag parse.
 type Context struct {
 	tag
-	tagUname        string
-	preTag, nextTag string
-	table           *schemas.Table
-	col             *schemas.Column
-	fieldValue      reflect.Value
-	isIndex         bool
-	isUnique        bool
-	indexNames      map[string]int
-	parser          *Parser
-	hasCacheTag     bool
-	hasNoCacheTag   bool
-	ignoreNext      bool
-	isUnsigned      bool
+	tagUname            string
+	preTag, nextTag     string
+	table               *schemas.Table
+	col                 *schemas.Column
+	fieldValue          reflect.Value
+	isIndex             bool
+	isUnique            bool
+	isSpatial           bool
+	indexNames          map[string]int
+	parser              *Parser
+	hasCacheTag         bool
+	hasNoCacheTag       bool
+	ignoreNext          bool
+	isUnsigned          bool
+	invisibleIndexNames []string
 }
 
 // Handler describes tag handler for XORM
@@ -101,29 +104,35 @@ type Handler func(ctx *Context) error
 
 // defaultTagHandlers enumerates all the default tag handler
 var defaultTagHandlers = map[string]Handler{
-	"-":        IgnoreHandler,
-	"<-":       OnlyFromDBTagHandler,
-	"->":       OnlyToDBTagHandler,
-	"PK":       PKTagHandler,
-	"NULL":     NULLTagHandler,
-	"NOT":      NotTagHandler,
-	"AUTOINCR": AutoIncrTagHandler,
-	"DEFAULT":  DefaultTagHandler,
-	"CREATED":  CreatedTagHandler,
-	"UPDATED":  UpdatedTagHandler,
-	"DELETED":  DeletedTagHandler,
-	"VERSION":  VersionTagHandler,
-	"UTC":      UTCTagHandler,
-	"LOCAL":    LocalTagHandler,
-	"NOTNULL":  NotNullTagHandler,
-	"INDEX":    IndexTagHandler,
-	"UNIQUE":   UniqueTagHandler,
-	"CACHE":    CacheTagHandler,
-	"NOCACHE":  NoCacheTagHandler,
-	"COMMENT":  CommentTagHandler,
-	"EXTENDS":  ExtendsTagHandler,
-	"UNSIGNED": UnsignedTagHandler,
-	"COLLATE":  CollateTagHandler,
+	"-":             IgnoreHandler,
+	"<-":            OnlyFromDBTagHandler,
+	"->":            OnlyToDBTagHandler,
+	"PK":            PKTagHandler,
+	"NULL":          NULLTagHandler,
+	"NOT":           NotTagHandler,
+	"AUTOINCR":      AutoIncrTagHandler,
+	"DEFAULT":       DefaultTagHandler,
+	"CREATED":       CreatedTagHandler,
+	"UPDATED":       UpdatedTagHandler,
+	"DELETED":       DeletedTagHandler,
+	"DELETED_FLAG":  DeletedFlagTagHandler,
+	"VERSION":       VersionTagHandler,
+	"UTC":           UTCTagHandler,
+	"DURATION":      DurationTagHandler,
+	"LOCAL":         LocalTagHandler,
+	"NOTNULL":       NotNullTagHandler,
+	"INDEX":         IndexTagHandler,
+	"UNIQUE":        UniqueTagHandler,
+	"SPATIAL":       SpatialTagHandler,
+	"CACHE":         CacheTagHandler,
+	"NOCACHE":       NoCacheTagHandler,
+	"COMMENT":       CommentTagHandler,
+	"EXTENDS":       ExtendsTagHandler,
+	"POLYMORPHIC":   PolymorphicTagHandler,
+	"DISCRIMINATOR": DiscriminatorTagHandler,
+	"UNSIGNED":      UnsignedTagHandler,
+	"COLLATE":       CollateTagHandler,
+	"INVISIBLE":     InvisibleTagHandler,
 }
 
 func init() {
@@ -177,17 +186,13 @@ func NotNullTagHandler(ctx *Context) error {
 func AutoIncrTagHandler(ctx *Context) error {
 	ctx.col.IsAutoIncrement = true
 	ctx.col.Nullable = false
-	/*
-		if len(ctx.params) > 0 {
-			autoStartInt, err := strconv.Atoi(ctx.params[0])
-			if err != nil {
-				return err
-			}
-			ctx.col.AutoIncrStart = autoStartInt
-		} else {
-			ctx.col.AutoIncrStart = 1
+	if len(ctx.params) > 0 {
+		autoStart, err := strconv.ParseInt(ctx.params[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid autoincr start value %q: %v", ctx.params[0], err)
 		}
-	*/
+		ctx.table.AutoIncrStart = autoStart
+	}
 	return nil
 }
 
@@ -206,7 +211,7 @@ func DefaultTagHandler(ctx *Context) error {
 // CreatedTagHandler describes created tag handler
 func CreatedTagHandler(ctx *Context) error {
 	ctx.col.IsCreated = true
-	return nil
+	return epochUnitHandler(ctx)
 }
 
 // VersionTagHandler describes version tag handler
@@ -222,6 +227,50 @@ func UTCTagHandler(ctx *Context) error {
 	return nil
 }
 
+// durationUnits are the units of the duration tag
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// DurationTagHandler describes duration tag handler, the time.Duration field is stored as an
+// INTERVAL on PostgreSQL, a TIME on MySQL and a BIGINT of nanoseconds on the other databases.
+// With a unit like duration(ms), it's stored as a BIGINT of the unit on all the databases.
+func DurationTagHandler(ctx *Context) error {
+	tp := ctx.fieldValue.Type()
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	if tp.Kind() != reflect.Int64 {
+		return fmt.Errorf("duration tag needs a time.Duration field but got %v", ctx.fieldValue.Type())
+	}
+
+	ctx.col.IsDuration = true
+	if len(ctx.params) > 0 {
+		unit, ok := durationUnits[strings.ToLower(ctx.params[0])]
+		if !ok {
+			return fmt.Errorf("unknown unit %s of duration tag", ctx.params[0])
+		}
+		ctx.col.SQLType = schemas.SQLType{Name: schemas.BigInt}
+		ctx.col.DurationUnit = unit
+		return nil
+	}
+
+	switch ctx.parser.dialect.URI().DBType {
+	case schemas.POSTGRES:
+		ctx.col.SQLType = schemas.SQLType{Name: schemas.Interval}
+	case schemas.MYSQL:
+		ctx.col.SQLType = schemas.SQLType{Name: schemas.Time}
+		ctx.col.Length = 6
+	default:
+		ctx.col.SQLType = schemas.SQLType{Name: schemas.BigInt}
+		ctx.col.DurationUnit = time.Nanosecond
+	}
+	return nil
+}
+
 // LocalTagHandler describes local tag handler
 func LocalTagHandler(ctx *Context) error {
 	if len(ctx.params) == 0 {
@@ -239,13 +288,48 @@ func LocalTagHandler(ctx *Context) error {
 // UpdatedTagHandler describes updated tag handler
 func UpdatedTagHandler(ctx *Context) error {
 	ctx.col.IsUpdated = true
-	return nil
+	return epochUnitHandler(ctx)
 }
 
 // DeletedTagHandler describes deleted tag handler
 func DeletedTagHandler(ctx *Context) error {
+	if len(ctx.params) > 0 && strings.EqualFold(ctx.params[0], "bool") {
+		return DeletedFlagTagHandler(ctx)
+	}
 	ctx.col.IsDeleted = true
 	ctx.col.Nullable = true
+	return epochUnitHandler(ctx)
+}
+
+// DeletedFlagTagHandler describes deleted_flag tag handler, the bool or integer field is set
+// to true or 1 when the record is soft deleted, deleted(bool) is the same
+func DeletedFlagTagHandler(ctx *Context) error {
+	tp := ctx.fieldValue.Type()
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	switch tp.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return fmt.Errorf("deleted flag tag needs a bool or integer field but got %v", ctx.fieldValue.Type())
+	}
+	ctx.col.IsDeleted = true
+	ctx.col.IsDeletedFlag = true
+	return nil
+}
+
+// epochUnitHandler sets the unit of an integer created, updated or deleted column, i.e.
+// created(ms) stores the Unix milliseconds
+func epochUnitHandler(ctx *Context) error {
+	if len(ctx.params) == 0 {
+		return nil
+	}
+	unit, ok := durationUnits[strings.ToLower(ctx.params[0])]
+	if !ok {
+		return fmt.Errorf("unknown epoch unit %s of %s tag", ctx.params[0], strings.ToLower(ctx.tagUname))
+	}
+	ctx.col.EpochUnit = unit
 	return nil
 }
 
@@ -269,6 +353,27 @@ func UniqueTagHandler(ctx *Context) error {
 	return nil
 }
 
+// SpatialTagHandler describes spatial index tag handler
+func SpatialTagHandler(ctx *Context) error {
+	if len(ctx.params) > 0 {
+		ctx.indexNames[ctx.params[0]] = schemas.SpatialType
+	} else {
+		ctx.isSpatial = true
+	}
+	return nil
+}
+
+// InvisibleTagHandler represents the column is invisible, or the indexes of the names in
+// the params are invisible
+func InvisibleTagHandler(ctx *Context) error {
+	if len(ctx.params) > 0 {
+		ctx.invisibleIndexNames = append(ctx.invisibleIndexNames, ctx.params...)
+	} else {
+		ctx.col.IsInvisible = true
+	}
+	return nil
+}
+
 // UnsignedTagHandler represents the column is unsigned
 func UnsignedTagHandler(ctx *Context) error {
 	ctx.isUnsigned = true
@@ -339,7 +444,11 @@ func SQLTypeTagHandler(ctx *Context) error {
 	return nil
 }
 
-// ExtendsTagHandler describes extends tag handler
+// ExtendsTagHandler describes extends tag handler, extends(prefix_) maps the columns of the
+// embedded struct with the prefix, and the names of its indexes are prefixed too so that the
+// same struct could be embedded several times, i.e. the billing and the shipping addresses.
+// Sync matches the existing indexes by their columns rather than their names, so the indexes
+// created before the prefix was added keep their unprefixed names.
 func ExtendsTagHandler(ctx *Context) error {
 	fieldValue := ctx.fieldValue
 	isPtr := false
@@ -382,8 +491,17 @@ func ExtendsTagHandler(ctx *Context) error {
 			}
 
 			ctx.table.AddColumn(col)
-			for indexName, indexType := range col.Indexes {
-				addIndex(indexName, ctx.table, col, indexType)
+			indexes := col.Indexes
+			col.Indexes = make(map[string]int, len(indexes))
+			for indexName, indexType := range indexes {
+				newIndexName := indexName
+				if len(ctx.params) > 0 {
+					newIndexName = tagPrefix + indexName
+				}
+				addIndex(newIndexName, ctx.table, col, indexType)
+				if parentIndex, ok := parentTable.Indexes[indexName]; ok && parentIndex.IsInvisible {
+					ctx.table.Indexes[newIndexName].IsInvisible = true
+				}
 			}
 		}
 	default:
@@ -392,6 +510,65 @@ func ExtendsTagHandler(ctx *Context) error {
 	return ErrIgnoreField
 }
 
+// PolymorphicTagHandler maps a schemas.Polymorphic field to the columns <name>_type and
+// <name>_id with an index on both, the name is the param or the column name of the field
+func PolymorphicTagHandler(ctx *Context) error {
+	if ctx.fieldValue.Type() != schemas.PolymorphicType {
+		return fmt.Errorf("polymorphic tag needs a field of schemas.Polymorphic but got %v", ctx.fieldValue.Type())
+	}
+	name := ctx.parser.columnMapper.Obj2Table(ctx.col.FieldName)
+	if len(ctx.params) > 0 {
+		name = strings.Trim(ctx.params[0], "'")
+	}
+
+	typeColumn, idColumn := schemas.PolymorphicColumns(name)
+	for i, colName := range []string{typeColumn, idColumn} {
+		field := schemas.PolymorphicType.Field(i)
+		sqlType := schemas.Type2SQLType(field.Type)
+		col := schemas.NewColumn(colName, ctx.col.FieldName+"."+field.Name, sqlType,
+			sqlType.DefaultLength, sqlType.DefaultLength2, true)
+		col.FieldIndex = append(append([]int{}, ctx.col.FieldIndex...), i)
+		col.FieldType = field.Type
+		col.Polymorphic = name
+		ctx.table.AddColumn(col)
+		addIndex(name, ctx.table, col, schemas.IndexType)
+	}
+	return ErrIgnoreField
+}
+
+// DiscriminatorTagHandler marks the column as the discriminator of the single table inheritance,
+// discriminator(value) or discriminator(column, value). The structs sharing a table are told
+// apart by the value, which is set on insert and added to the conditions of the queries.
+func DiscriminatorTagHandler(ctx *Context) error {
+	var value string
+	switch len(ctx.params) {
+	case 1:
+		value = ctx.params[0]
+	case 2:
+		ctx.col.Name = strings.Trim(strings.TrimSpace(ctx.params[0]), "'")
+		value = strings.TrimSpace(ctx.params[1])
+	default:
+		return errors.New("discriminator tag needs a value")
+	}
+	value = strings.Trim(value, "'")
+	if value == "" {
+		return errors.New("discriminator tag needs a value")
+	}
+
+	switch ctx.fieldValue.Kind() {
+	case reflect.String:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("invalid discriminator value %q of integer field: %v", value, err)
+		}
+	default:
+		return fmt.Errorf("discriminator tag needs a string or integer field but got %v", ctx.fieldValue.Type())
+	}
+	ctx.col.Discriminator = value
+	return nil
+}
+
 // CacheTagHandler describes cache tag handler
 func CacheTagHandler(ctx *Context) error {
 	if !ctx.hasCacheTag {
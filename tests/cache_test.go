@@ -5,10 +5,14 @@
 package tests
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/caches"
+	"github.com/imkos/xorm/schemas"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -181,3 +185,326 @@ func TestCacheGet(t *testing.T) {
 
 	testEngine.SetDefaultCacher(oldCacher)
 }
+
+func TestCacheClearBean(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	engine, ok := testEngine.(*xorm.Engine)
+	if !ok {
+		t.Skip("ClearCacheBean is only tested on the engine")
+	}
+
+	type ClearCacheBox struct {
+		Id       int64
+		Username string
+	}
+
+	assertSync(t, new(ClearCacheBox))
+
+	cacher := caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)
+	testEngine.MapCacher(new(ClearCacheBox), cacher)
+	defer testEngine.MapCacher(new(ClearCacheBox), nil)
+
+	box := ClearCacheBox{Username: "user1"}
+	_, err := testEngine.Insert(&box)
+	assert.NoError(t, err)
+
+	pk := schemas.PK{box.Id}
+	oldKey, err := pk.ToString()
+	assert.NoError(t, err)
+
+	for _, id := range []string{pk.Key(), oldKey} {
+		has, err := testEngine.ID(box.Id).Get(new(ClearCacheBox))
+		assert.NoError(t, err)
+		assert.True(t, has)
+		assert.EqualValues(t, 1, cacher.Stats().Beans)
+
+		assert.NoError(t, engine.ClearCacheBean(new(ClearCacheBox), id))
+		assert.EqualValues(t, 0, cacher.Stats().Beans)
+	}
+}
+
+func TestGetMulti(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type MultiGetUser struct {
+		Id   int64
+		Name string
+	}
+
+	type MultiGetMember struct {
+		GroupId int64 `xorm:"pk"`
+		UserId  int64 `xorm:"pk"`
+		Role    string
+	}
+
+	assertSync(t, new(MultiGetUser), new(MultiGetMember))
+
+	users := []MultiGetUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	for i := range users {
+		_, err := testEngine.Insert(&users[i])
+		assert.NoError(t, err)
+	}
+
+	var results map[int64]*MultiGetUser
+	assert.NoError(t, testEngine.GetMulti([]int{int(users[0].Id), int(users[2].Id), 10000}, &results))
+	assert.EqualValues(t, 2, len(results))
+	assert.EqualValues(t, "a", results[users[0].Id].Name)
+	assert.EqualValues(t, "c", results[users[2].Id].Name)
+
+	// the existing conditions are honoured
+	values := make(map[int64]MultiGetUser)
+	assert.NoError(t, testEngine.Where("`name` <> ?", "a").GetMulti([]int64{users[0].Id, users[1].Id}, &values))
+	assert.EqualValues(t, 1, len(values))
+	assert.EqualValues(t, "b", values[users[1].Id].Name)
+
+	cacher := caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)
+	testEngine.MapCacher(new(MultiGetUser), cacher)
+	defer testEngine.MapCacher(new(MultiGetUser), nil)
+
+	results = nil
+	assert.NoError(t, testEngine.GetMulti([]int64{users[0].Id, users[1].Id}, &results))
+	assert.EqualValues(t, 2, len(results))
+	stats := cacher.Stats()
+	assert.EqualValues(t, 0, stats.Hits)
+	assert.EqualValues(t, 2, stats.Beans)
+
+	// only the missed id will be queried
+	results = nil
+	assert.NoError(t, testEngine.GetMulti([]int64{users[0].Id, users[1].Id, users[2].Id}, &results))
+	assert.EqualValues(t, 3, len(results))
+	assert.EqualValues(t, "b", results[users[1].Id].Name)
+	stats = cacher.Stats()
+	assert.EqualValues(t, 2, stats.Hits)
+	assert.EqualValues(t, 3, stats.Beans)
+
+	// the cached beans are copied, so changing the results doesn't change the cache
+	results[users[0].Id].Name = "changed"
+	results = nil
+	assert.NoError(t, testEngine.GetMulti([]int64{users[0].Id}, &results))
+	assert.EqualValues(t, "a", results[users[0].Id].Name)
+
+	// the ids more than the max parameters of the database are queried in chunks
+	manyIds := make([]int64, 0, 70000)
+	for i := int64(0); i < 70000; i++ {
+		manyIds = append(manyIds, users[2].Id+1+i)
+	}
+	manyIds = append(manyIds, users[2].Id)
+	testEngine.MapCacher(new(MultiGetUser), nil)
+	results = nil
+	assert.NoError(t, testEngine.Where("`name` <> ?", "a").GetMulti(manyIds, &results))
+	assert.EqualValues(t, 1, len(results))
+	assert.EqualValues(t, "c", results[users[2].Id].Name)
+	testEngine.MapCacher(new(MultiGetUser), cacher)
+
+	_, err := testEngine.Insert([]MultiGetMember{
+		{GroupId: 1, UserId: 1, Role: "owner"},
+		{GroupId: 1, UserId: 2, Role: "member"},
+	})
+	assert.NoError(t, err)
+
+	pk := schemas.PK{int64(1), int64(2)}
+	key, err := pk.ToString()
+	assert.NoError(t, err)
+	members := make(map[string]MultiGetMember)
+	assert.NoError(t, testEngine.GetMulti([]schemas.PK{pk, {2, 2}}, &members))
+	assert.EqualValues(t, 1, len(members))
+	assert.EqualValues(t, "member", members[key].Role)
+}
+
+func TestCacheFindCompositeKeyMap(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type CacheMapMember struct {
+		GroupId int64 `xorm:"pk"`
+		UserId  int64 `xorm:"pk"`
+		Role    string
+	}
+
+	type MemberKey struct {
+		UserId  int64
+		GroupId int64
+	}
+
+	oldCacher := testEngine.GetDefaultCacher()
+	cacher := caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)
+	testEngine.SetDefaultCacher(cacher)
+	defer testEngine.SetDefaultCacher(oldCacher)
+
+	assertSync(t, new(CacheMapMember))
+
+	_, err := testEngine.Insert([]CacheMapMember{
+		{GroupId: 1, UserId: 2, Role: "owner"},
+		{GroupId: 2, UserId: 1, Role: "member"},
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		members := make(map[MemberKey]CacheMapMember)
+		assert.NoError(t, testEngine.Find(&members))
+		assert.EqualValues(t, 2, len(members))
+		assert.EqualValues(t, "owner", members[MemberKey{UserId: 2, GroupId: 1}].Role)
+		assert.EqualValues(t, "member", members[MemberKey{UserId: 1, GroupId: 2}].Role)
+
+		arrayMembers := make(map[[2]int64]*CacheMapMember)
+		assert.NoError(t, testEngine.Find(&arrayMembers))
+		assert.EqualValues(t, 2, len(arrayMembers))
+		assert.EqualValues(t, "owner", arrayMembers[[2]int64{1, 2}].Role)
+	}
+
+	// the arguments of the cached sql don't collide
+	var members []CacheMapMember
+	assert.NoError(t, testEngine.Where("role = ?", "owner member").Find(&members))
+	assert.EqualValues(t, 0, len(members))
+	assert.NoError(t, testEngine.Where("role IN (?, ?)", "owner", "member").Find(&members))
+	assert.EqualValues(t, 2, len(members))
+
+	results := make(map[MemberKey]*CacheMapMember)
+	assert.NoError(t, testEngine.GetMulti([]schemas.PK{{1, 2}, {2, 1}}, &results))
+	assert.EqualValues(t, 2, len(results))
+	assert.EqualValues(t, "member", results[MemberKey{UserId: 1, GroupId: 2}].Role)
+
+	invalid := make(map[int64]CacheMapMember)
+	assert.Error(t, testEngine.Find(&invalid))
+}
+
+type CacheTicketID struct {
+	Prefix string
+	Num    int64
+}
+
+func (id CacheTicketID) String() string {
+	return fmt.Sprintf("%s-%d", id.Prefix, id.Num)
+}
+
+func (id *CacheTicketID) FromDB(data []byte) error {
+	_, err := fmt.Sscanf(string(data), "%1s-%d", &id.Prefix, &id.Num)
+	return err
+}
+
+func (id CacheTicketID) ToDB() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func TestCacheCustomIDType(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	schemas.RegisterIDConverter(reflect.TypeOf(CacheTicketID{}), schemas.IDConverter{
+		FromID: func(sid string) (interface{}, error) {
+			var id CacheTicketID
+			err := id.FromDB([]byte(sid))
+			return id, err
+		},
+		ToID: func(v interface{}) (interface{}, error) {
+			return v.(CacheTicketID).String(), nil
+		},
+	})
+
+	type CacheTicket struct {
+		Id    CacheTicketID `xorm:"varchar(20) pk"`
+		Title string
+	}
+
+	oldCacher := testEngine.GetDefaultCacher()
+	cacher := caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)
+	testEngine.SetDefaultCacher(cacher)
+	defer testEngine.SetDefaultCacher(oldCacher)
+
+	assertSync(t, new(CacheTicket))
+
+	_, err := testEngine.Insert([]CacheTicket{
+		{Id: CacheTicketID{"T", 1}, Title: "first"},
+		{Id: CacheTicketID{"T", 2}, Title: "second"},
+	})
+	assert.NoError(t, err)
+
+	var ticket CacheTicket
+	has, err := testEngine.ID(CacheTicketID{"T", 2}).Get(&ticket)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "second", ticket.Title)
+
+	for i := 0; i < 2; i++ {
+		tickets := make(map[CacheTicketID]CacheTicket)
+		assert.NoError(t, testEngine.Find(&tickets))
+		assert.EqualValues(t, 2, len(tickets))
+		assert.EqualValues(t, "first", tickets[CacheTicketID{"T", 1}].Title)
+	}
+
+	results := make(map[CacheTicketID]*CacheTicket)
+	assert.NoError(t, testEngine.GetMulti([]CacheTicketID{{"T", 1}, {"T", 3}}, &results))
+	assert.EqualValues(t, 1, len(results))
+	assert.EqualValues(t, "first", results[CacheTicketID{"T", 1}].Title)
+}
+
+type recordingBroker struct {
+	events []caches.InvalidationEvent
+}
+
+func (b *recordingBroker) Publish(event caches.InvalidationEvent) error {
+	b.events = append(b.events, event)
+	return nil
+}
+
+func (b *recordingBroker) Subscribe(handler func(caches.InvalidationEvent)) (func(), error) {
+	return func() {}, nil
+}
+
+func TestCacheInvalidationBroker(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type CacheInvalidation struct {
+		Id   int64
+		Name string
+	}
+
+	oldCacher := testEngine.GetDefaultCacher()
+	testEngine.SetDefaultCacher(caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000))
+	defer testEngine.SetDefaultCacher(oldCacher)
+	assertSync(t, new(CacheInvalidation))
+
+	broker := new(recordingBroker)
+	assert.NoError(t, testEngine.SetCacheInvalidationBroker(broker))
+	defer testEngine.SetCacheInvalidationBroker(nil)
+
+	tableName := testEngine.TableName(new(CacheInvalidation))
+	bean := CacheInvalidation{Name: "a"}
+	_, err := testEngine.Insert(&bean)
+	assert.NoError(t, err)
+	if assert.Len(t, broker.events, 1) {
+		assert.EqualValues(t, tableName, broker.events[0].Table)
+		assert.NotEmpty(t, broker.events[0].Origin)
+		assert.False(t, broker.events[0].All)
+		assert.Empty(t, broker.events[0].IDs)
+	}
+
+	_, err = testEngine.ID(bean.Id).Update(&CacheInvalidation{Name: "b"})
+	assert.NoError(t, err)
+	if assert.Len(t, broker.events, 2) {
+		assert.True(t, broker.events[1].All)
+	}
+
+	_, err = testEngine.ID(bean.Id).Delete(new(CacheInvalidation))
+	assert.NoError(t, err)
+	if assert.Len(t, broker.events, 3) {
+		assert.False(t, broker.events[2].All)
+		pk := schemas.PK{bean.Id}
+		assert.EqualValues(t, []string{pk.Key()}, broker.events[2].IDs)
+	}
+
+	// the invalidations of a transaction are published after committing
+	session := testEngine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+	_, err = session.Insert(&CacheInvalidation{Name: "c"})
+	assert.NoError(t, err)
+	assert.Len(t, broker.events, 3)
+	assert.NoError(t, session.Commit())
+	assert.Len(t, broker.events, 4)
+
+	assert.NoError(t, session.Begin())
+	_, err = session.Insert(&CacheInvalidation{Name: "d"})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Rollback())
+	assert.Len(t, broker.events, 4)
+}
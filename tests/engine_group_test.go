@@ -5,9 +5,14 @@
 package tests
 
 import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/imkos/xorm"
+	"github.com/imkos/xorm/core"
 	"github.com/imkos/xorm/log"
 	"github.com/imkos/xorm/schemas"
 
@@ -33,3 +38,220 @@ func TestEngineGroup(t *testing.T) {
 	eg.SetLogLevel(log.LOG_INFO)
 	eg.ShowSQL(true)
 }
+
+func TestEngineGroupSticky(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	master, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "master.db"))
+	assert.NoError(t, err)
+	slave, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "slave.db"))
+	assert.NoError(t, err)
+
+	eg, err := xorm.NewEngineGroup(master, []*xorm.Engine{slave})
+	assert.NoError(t, err)
+	defer eg.Close()
+
+	type EngineGroupSticky struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, master.Sync(new(EngineGroupSticky)))
+	assert.NoError(t, slave.Sync(new(EngineGroupSticky)))
+
+	eg.SetStickyDuration(time.Minute)
+
+	ctx := xorm.NewStickyContext(context.Background())
+	var beans []EngineGroupSticky
+	assert.NoError(t, eg.Context(ctx).Find(&beans))
+	assert.EqualValues(t, 0, len(beans))
+
+	_, err = eg.Context(ctx).Insert(&EngineGroupSticky{Name: "sticky"})
+	assert.NoError(t, err)
+
+	// the reads of the sticky context go to master after the write
+	assert.NoError(t, eg.Context(ctx).Find(&beans))
+	assert.EqualValues(t, 1, len(beans))
+
+	// the other contexts still read from the slave
+	beans = beans[:0]
+	assert.NoError(t, eg.Context(context.Background()).Find(&beans))
+	assert.EqualValues(t, 0, len(beans))
+
+	// the sticky duration could be changed while the reads are executed
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		eg.SetStickyDuration(0)
+	}()
+	_, err = eg.Context(ctx).Count(new(EngineGroupSticky))
+	assert.NoError(t, err)
+	<-done
+
+	beans = beans[:0]
+	assert.NoError(t, eg.Context(ctx).Find(&beans))
+	assert.EqualValues(t, 0, len(beans))
+}
+
+func TestEngineGroupSessionConsistency(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	master, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "master.db"))
+	assert.NoError(t, err)
+	slave, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "slave.db"))
+	assert.NoError(t, err)
+
+	eg, err := xorm.NewEngineGroup(master, []*xorm.Engine{slave})
+	assert.NoError(t, err)
+	defer eg.Close()
+
+	type EngineGroupConsistency struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, master.Sync(new(EngineGroupConsistency)))
+	assert.NoError(t, slave.Sync(new(EngineGroupConsistency)))
+
+	// the reads go to the slave after a write by default
+	session := eg.NewSession()
+	defer session.Close()
+	_, err = session.Insert(&EngineGroupConsistency{Name: "a"})
+	assert.NoError(t, err)
+	cnt, err := session.Count(new(EngineGroupConsistency))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	// the reads go to the master for the rest of the session after a write
+	eg.SetConsistency(xorm.SessionConsistency)
+	session2 := eg.NewSession()
+	defer session2.Close()
+	cnt, err = session2.Count(new(EngineGroupConsistency))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+	_, err = session2.Insert(&EngineGroupConsistency{Name: "b"})
+	assert.NoError(t, err)
+	cnt, err = session2.Count(new(EngineGroupConsistency))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	// the explicit routing overrides the consistency
+	cnt, err = session2.UseReplica().Count(new(EngineGroupConsistency))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	eg.SetConsistency(xorm.EventualConsistency)
+	session3 := eg.NewSession().UsePrimary()
+	defer session3.Close()
+	cnt, err = session3.Count(new(EngineGroupConsistency))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+}
+
+// countPositionChecker uses the count of the rows as the replication position
+type countPositionChecker struct {
+	checks atomic.Int32
+}
+
+func (*countPositionChecker) Position(ctx context.Context, db *core.DB) (string, error) {
+	var cnt string
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM engine_group_position").Scan(&cnt)
+	return cnt, err
+}
+
+func (checker *countPositionChecker) Reached(ctx context.Context, db *core.DB, position string) (bool, error) {
+	checker.checks.Add(1)
+	var reached bool
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) >= CAST(? AS INTEGER) FROM engine_group_position", position).Scan(&reached)
+	return reached, err
+}
+
+func TestEngineGroupRouteTo(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	master, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "master.db"))
+	assert.NoError(t, err)
+	slave, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "slave.db"))
+	assert.NoError(t, err)
+
+	eg, err := xorm.NewEngineGroup(master, []*xorm.Engine{slave})
+	assert.NoError(t, err)
+	defer eg.Close()
+
+	type EngineGroupPosition struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, master.Sync(new(EngineGroupPosition)))
+	assert.NoError(t, slave.Sync(new(EngineGroupPosition)))
+
+	// the hint only affects the next query
+	_, err = master.Insert(&EngineGroupPosition{Name: "master"})
+	assert.NoError(t, err)
+	session := eg.NewSession()
+	defer session.Close()
+	cnt, err := session.RouteTo(xorm.RoutePrimary).Count(new(EngineGroupPosition))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	cnt, err = session.Count(new(EngineGroupPosition))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	cnt, err = eg.RouteTo(xorm.RoutePrimary).Count(new(EngineGroupPosition))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// the reads go to the master until the slave replays the writes
+	checker := &countPositionChecker{}
+	eg.SetPositionChecker(checker)
+	defer eg.SetPositionChecker(nil)
+	_, err = session.Insert(&EngineGroupPosition{Name: "master"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "2", session.ReplicationPosition())
+
+	var bean EngineGroupPosition
+	has, err := session.RouteTo(xorm.RouteReplicaPreferred).Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "master", bean.Name)
+	assert.EqualValues(t, 1, checker.checks.Load())
+
+	// the slave which has not replayed the position is not checked again for a while
+	_, err = slave.Insert([]EngineGroupPosition{{Name: "slave"}, {Name: "slave"}})
+	assert.NoError(t, err)
+	bean = EngineGroupPosition{}
+	has, err = session.Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "master", bean.Name)
+	assert.EqualValues(t, 1, checker.checks.Load())
+
+	time.Sleep(150 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		bean = EngineGroupPosition{}
+		has, err = session.Get(&bean)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		assert.EqualValues(t, "slave", bean.Name)
+	}
+	assert.EqualValues(t, 2, checker.checks.Load())
+
+	// the position could be passed to another session
+	_, err = master.Insert(&EngineGroupPosition{Name: "master"})
+	assert.NoError(t, err)
+	session2 := eg.NewSession()
+	defer session2.Close()
+	cnt, err = session2.ReadAfter("3").Count(new(EngineGroupPosition))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+}
@@ -5,13 +5,26 @@
 package tests
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/imkos/xorm"
+	"github.com/imkos/xorm/caches"
+	"github.com/imkos/xorm/dialects"
 	"github.com/imkos/xorm/schemas"
 
 	_ "gitee.com/travelliu/dm"
@@ -44,6 +57,194 @@ func TestPingContext(t *testing.T) {
 	assert.Contains(t, err.Error(), "context deadline exceeded")
 }
 
+func TestSetTimeoutFor(t *testing.T) {
+	type TimeoutReport struct {
+		Id   int64
+		Name string
+	}
+
+	type TimeoutOrder struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TimeoutReport), new(TimeoutOrder))
+
+	assert.Error(t, testEngine.SetTimeoutFor("[", time.Second))
+	assert.NoError(t, testEngine.SetTimeoutFor("timeout_report*", time.Nanosecond))
+	defer testEngine.SetTimeoutFor("timeout_report*", 0)
+
+	_, err := testEngine.Insert(&TimeoutReport{Name: "report"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+
+	var reports []TimeoutReport
+	err = testEngine.Find(&reports)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+
+	_, err = testEngine.Insert(&TimeoutOrder{Name: "order"})
+	assert.NoError(t, err)
+	var orders []TimeoutOrder
+	assert.NoError(t, testEngine.Find(&orders))
+	assert.EqualValues(t, 1, len(orders))
+
+	assert.NoError(t, testEngine.SetTimeoutFor("timeout_report*", time.Minute))
+	_, err = testEngine.Insert(&TimeoutReport{Name: "report"})
+	assert.NoError(t, err)
+	assert.NoError(t, testEngine.Find(&reports))
+	assert.EqualValues(t, 1, len(reports))
+}
+
+func TestSetIdentifierAudit(t *testing.T) {
+	type AuditUser struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(AuditUser))
+
+	_, err := testEngine.Insert(&AuditUser{Name: "audit"})
+	assert.NoError(t, err)
+
+	testEngine.SetIdentifierAudit(xorm.IdentifierAuditError)
+	defer testEngine.SetIdentifierAudit(xorm.IdentifierAuditOff)
+
+	var users []AuditUser
+	assert.NoError(t, testEngine.Cols("id, `name`").OrderBy("id DESC, name asc").Find(&users))
+	assert.EqualValues(t, 1, len(users))
+
+	users = nil
+	assert.NoError(t, testEngine.Table("audit_user AS u").Desc("u.id").Find(&users))
+	assert.EqualValues(t, 1, len(users))
+
+	// the order by clause with args is an expression
+	users = nil
+	assert.NoError(t, testEngine.OrderBy("CASE WHEN id = ? THEN 0 ELSE 1 END", 1).Find(&users))
+	assert.EqualValues(t, 1, len(users))
+
+	for _, session := range []*xorm.Session{
+		testEngine.OrderBy("id; DELETE FROM audit_user"),
+		testEngine.OrderBy("(SELECT 1)"),
+		testEngine.Cols("name", "id --"),
+		testEngine.Desc("id' OR '1'='1"),
+		testEngine.Table("audit_user; DROP TABLE audit_user"),
+	} {
+		users = nil
+		err = session.Find(&users)
+		assert.Error(t, err)
+		assert.IsType(t, xorm.ErrSuspiciousIdentifier{}, err)
+	}
+
+	testEngine.SetIdentifierAudit(xorm.IdentifierAuditLog)
+	users = nil
+	assert.NoError(t, testEngine.OrderBy("(SELECT 1)").Find(&users))
+	assert.EqualValues(t, 1, len(users))
+}
+
+func TestAddSQLInterceptor(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "interceptor.db"))
+	assert.NoError(t, err)
+	defer engine.Close()
+
+	type InterceptorUser struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, engine.Sync(new(InterceptorUser)))
+
+	var sqls []string
+	engine.AddSQLInterceptor(func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error) {
+		return sql + " /*route='users'*/", args, nil
+	})
+	var readOnly bool
+	errReadOnly := errors.New("read only")
+	engine.AddSQLInterceptor(func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error) {
+		sqls = append(sqls, sql)
+		if readOnly && !strings.HasPrefix(sql, "SELECT") {
+			return "", nil, errReadOnly
+		}
+		return sql, args, nil
+	})
+
+	_, err = engine.Insert(&InterceptorUser{Name: "a"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(sqls))
+	assert.True(t, strings.HasSuffix(sqls[0], " /*route='users'*/"))
+
+	readOnly = true
+	_, err = engine.Insert(&InterceptorUser{Name: "b"})
+	assert.ErrorIs(t, err, errReadOnly)
+
+	var users []InterceptorUser
+	assert.NoError(t, engine.Find(&users))
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, 3, len(sqls))
+}
+
+type sqlCommenterRouteKey struct{}
+
+func TestSetSQLCommenter(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "commenter.db"))
+	assert.NoError(t, err)
+	defer engine.Close()
+
+	type CommenterUser struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, engine.Sync(new(CommenterUser)))
+
+	engine.SetSQLCommenter(func(ctx context.Context) map[string]string {
+		route, _ := ctx.Value(sqlCommenterRouteKey{}).(string)
+		if route == "" {
+			return nil
+		}
+		return map[string]string{
+			"route":       route,
+			"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01",
+		}
+	})
+
+	ctx := context.WithValue(context.Background(), sqlCommenterRouteKey{}, "/users/{id}")
+	session := engine.Context(ctx)
+	defer session.Close()
+	_, err = session.Insert(&CommenterUser{Name: "a"})
+	assert.NoError(t, err)
+	sql, _ := session.LastSQL()
+	assert.True(t, strings.HasSuffix(sql, " /*route='%2Fusers%2F%7Bid%7D',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/"), sql)
+
+	var users []CommenterUser
+	assert.NoError(t, session.Find(&users))
+	assert.EqualValues(t, 1, len(users))
+
+	// a sql which has a comment will not be changed
+	_, err = session.Exec("UPDATE commenter_user SET name = ? /* keep */", "b")
+	assert.NoError(t, err)
+	sql, _ = session.LastSQL()
+	assert.EqualValues(t, "UPDATE commenter_user SET name = ? /* keep */", sql)
+
+	session2 := engine.NewSession()
+	defer session2.Close()
+	assert.NoError(t, session2.Find(&users))
+	sql, _ = session2.LastSQL()
+	assert.False(t, strings.Contains(sql, "/*"))
+}
+
 func TestAutoTransaction(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 
@@ -199,6 +400,56 @@ func TestDumpTables(t *testing.T) {
 	})
 }
 
+func TestExportTables(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type TestExportStruct struct {
+		Id    int64
+		Name  string
+		IsMan bool
+		Data  []byte `xorm:"BLOB"`
+		Note  *string
+	}
+
+	assertSync(t, new(TestExportStruct))
+
+	note := "a, \"quoted\"\nnote"
+	_, err := testEngine.Insert([]TestExportStruct{
+		{Name: "1", IsMan: true, Data: []byte("Help"), Note: &note},
+		{Name: "2\n"},
+	})
+	assert.NoError(t, err)
+
+	table, err := testEngine.TableInfo(new(TestExportStruct))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, testEngine.ExportTables([]*schemas.Table{table}, &buf, xorm.ExportOptions{
+		Format: xorm.ExportCSV,
+		NullAs: "NULL",
+	}))
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.EqualValues(t, [][]string{
+		{"id", "name", "is_man", "data", "note"},
+		{"1", "1", "1", "Help", note},
+		{"2", "2\n", "0", "NULL", "NULL"},
+	}, records)
+
+	buf.Reset()
+	assert.NoError(t, testEngine.ExportTables([]*schemas.Table{table}, &buf, xorm.ExportOptions{
+		Format: xorm.ExportJSONL,
+		Gzip:   true,
+	}))
+	gr, err := gzip.NewReader(&buf)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"id":1,"name":"1","is_man":true,"data":"SGVscA==","note":"a, \"quoted\"\nnote"}
+{"id":2,"name":"2\n","is_man":false,"data":null,"note":null}
+`, string(content))
+}
+
 func TestDumpTables2(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 
@@ -246,6 +497,151 @@ func TestImport(t *testing.T) {
 	assert.NoError(t, sess.Commit())
 }
 
+func TestCopyDatabase(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type CopyDatabaseStruct struct {
+		Id    int64
+		Name  string `xorm:"index"`
+		IsMan bool
+		Data  []byte `xorm:"BLOB"`
+		Note  *string
+	}
+	type CopyDatabaseOther struct {
+		Id int64
+	}
+
+	assertSync(t, new(CopyDatabaseStruct), new(CopyDatabaseOther))
+
+	note := "note"
+	for i := 0; i < 25; i++ {
+		_, err := testEngine.Insert(&CopyDatabaseStruct{
+			Name:  fmt.Sprintf("name%d", i),
+			IsMan: i%2 == 0,
+			Data:  []byte{0, byte(i)},
+			Note:  &note,
+		})
+		assert.NoError(t, err)
+	}
+	_, err := testEngine.Insert(&CopyDatabaseStruct{Name: "null"})
+	assert.NoError(t, err)
+
+	src, ok := testEngine.(*xorm.Engine)
+	if !ok {
+		t.Skip()
+		return
+	}
+	dst, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "copy.db"))
+	assert.NoError(t, err)
+	defer dst.Close()
+
+	err = xorm.CopyDatabase(src, dst, xorm.CopyOptions{Tables: []string{"not_exist"}})
+	assert.ErrorIs(t, err, xorm.ErrTableNotFound)
+
+	assert.NoError(t, xorm.CopyDatabase(src, dst, xorm.CopyOptions{
+		Tables:    []string{"copy_database_struct", "copy_database_other"},
+		BatchSize: 10,
+		Workers:   2,
+	}))
+
+	var beans []CopyDatabaseStruct
+	assert.NoError(t, dst.Asc("id").Find(&beans))
+	assert.Len(t, beans, 26)
+	assert.EqualValues(t, "name3", beans[3].Name)
+	assert.False(t, beans[3].IsMan)
+	assert.True(t, beans[4].IsMan)
+	assert.EqualValues(t, []byte{0, 3}, beans[3].Data)
+	assert.EqualValues(t, "note", *beans[3].Note)
+	assert.Nil(t, beans[25].Note)
+
+	tables, err := dst.DBMetas()
+	assert.NoError(t, err)
+	assert.Len(t, tables, 2)
+	for _, table := range tables {
+		if table.Name == "copy_database_struct" {
+			assert.Len(t, table.Indexes, 1)
+		}
+	}
+
+	// the auto increment id should continue after the copied records
+	bean := CopyDatabaseStruct{Name: "new"}
+	_, err = dst.Insert(&bean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 27, bean.Id)
+
+	// the batch size is reduced to the parameter limit of the destination database
+	dst2, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "copy2.db"))
+	assert.NoError(t, err)
+	defer dst2.Close()
+	assert.NoError(t, xorm.CopyDatabase(src, dst2, xorm.CopyOptions{
+		Tables:    []string{"copy_database_struct"},
+		BatchSize: 1000,
+	}))
+	cnt, err := dst2.Count(new(CopyDatabaseStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 26, cnt)
+}
+
+func TestImportStream(t *testing.T) {
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+	assert.NoError(t, PrepareEngine())
+
+	script := `-- create tables; with a trigger
+CREATE TABLE import_stream (id INTEGER PRIMARY KEY, name TEXT);
+CREATE TABLE import_stream_log (name TEXT);
+CREATE TRIGGER import_stream_trigger AFTER INSERT ON import_stream
+BEGIN
+	INSERT INTO import_stream_log VALUES (NEW.name || ';');
+END;
+INSERT INTO import_stream VALUES (1, 'a;b'); /* comment; */
+INSERT INTO import_stream VALUES (2, 'it''s;');
+`
+	var progresses []xorm.ImportProgress
+	results, err := testEngine.ImportStream(strings.NewReader(script), xorm.ImportOptions{
+		BatchSize: 2,
+		Progress: func(progress xorm.ImportProgress) {
+			progresses = append(progresses, progress)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 5)
+	assert.Len(t, progresses, 5)
+	assert.EqualValues(t, 5, progresses[4].Statements)
+	assert.EqualValues(t, len(script), progresses[4].Bytes)
+
+	var names []string
+	assert.NoError(t, testEngine.Table("import_stream_log").Cols("name").Find(&names))
+	assert.EqualValues(t, []string{"a;b;", "it's;;"}, names)
+
+	// the error reports the failed statement
+	_, err = testEngine.ImportStream(strings.NewReader("INSERT INTO import_stream VALUES (3, 'c');\nINSERT INTO not_exist VALUES (1);\nINSERT INTO import_stream VALUES (4, 'd');"), xorm.ImportOptions{})
+	var importErr *xorm.ImportError
+	assert.True(t, errors.As(err, &importErr))
+	assert.EqualValues(t, 1, importErr.Index)
+	assert.EqualValues(t, 2, importErr.Line)
+	assert.EqualValues(t, "INSERT INTO not_exist VALUES (1)", importErr.SQL)
+
+	cnt, err := testEngine.Table("import_stream").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	// continue on error
+	results, err = testEngine.ImportStream(strings.NewReader("INSERT INTO not_exist VALUES (1);\nINSERT INTO import_stream VALUES (4, 'd');"), xorm.ImportOptions{
+		ContinueOnError: true,
+	})
+	assert.Len(t, results, 1)
+	var importErrs xorm.ImportErrors
+	assert.True(t, errors.As(err, &importErrs))
+	assert.Len(t, importErrs, 1)
+
+	cnt, err = testEngine.Table("import_stream").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, cnt)
+}
+
 func TestDBVersion(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 
@@ -361,3 +757,179 @@ func TestGetColumnsLength(t *testing.T) {
 		}
 	}
 }
+
+func TestDebugReport(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "report.db"))
+	assert.NoError(t, err)
+	defer engine.Close()
+
+	type ReportUser struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, engine.Sync(new(ReportUser)))
+	engine.MapCacher(new(ReportUser), caches.NewLRUCacher(caches.NewMemoryStore(), 1000))
+
+	// the slow queries are not recorded by default
+	_, err = engine.Insert(&ReportUser{Name: "a"})
+	assert.NoError(t, err)
+	assert.Empty(t, engine.SlowQueries())
+
+	engine.SetSlowQueriesSize(2)
+	_, err = engine.Insert(&ReportUser{Name: "b"})
+	assert.NoError(t, err)
+	var users []ReportUser
+	assert.NoError(t, engine.Find(&users))
+	assert.NoError(t, engine.Find(&users))
+	slowQueries := engine.SlowQueries()
+	assert.EqualValues(t, 2, len(slowQueries))
+	assert.True(t, slowQueries[0].Duration >= slowQueries[1].Duration)
+
+	var buf bytes.Buffer
+	assert.NoError(t, engine.DebugReport(&buf))
+	report := buf.String()
+	assert.Contains(t, report, "dialect: sqlite3")
+	assert.Contains(t, report, "table mapper: *names.SnakeMapper (cached)")
+	assert.Contains(t, report, "pool: max_open=")
+	assert.Contains(t, report, "report_user: *caches.LRUCacher hits=")
+	assert.Contains(t, report, "slow queries:\n  1. ")
+}
+
+func TestEngineFailover(t *testing.T) {
+	assert.True(t, xorm.IsConnectionError(fmt.Errorf("query: %w", driver.ErrBadConn)))
+	assert.True(t, xorm.IsConnectionError(&net.OpError{Op: "dial", Err: errors.New("refused")}))
+	assert.False(t, xorm.IsConnectionError(errors.New("syntax error")))
+	assert.False(t, xorm.IsConnectionError(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.False(t, xorm.IsConnectionError(context.Canceled))
+	assert.False(t, xorm.IsConnectionError(&net.OpError{Op: "read", Err: context.DeadlineExceeded}))
+	assert.True(t, xorm.IsConnectionError(&net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	primary := filepath.Join(t.TempDir(), "primary.db")
+	standby := filepath.Join(t.TempDir(), "standby.db")
+
+	type FailoverUser struct {
+		Id   int64
+		Name string
+	}
+	standbyEngine, err := xorm.NewEngine("sqlite3", standby)
+	assert.NoError(t, err)
+	assert.NoError(t, standbyEngine.Sync(new(FailoverUser)))
+	_, err = standbyEngine.Insert(&FailoverUser{Name: "standby"})
+	assert.NoError(t, err)
+	assert.NoError(t, standbyEngine.Close())
+
+	engine, err := xorm.NewEngine("sqlite3", primary)
+	assert.NoError(t, err)
+	defer engine.Close()
+	engine.SetMaxOpenConns(2)
+	assert.NoError(t, engine.Sync(new(FailoverUser)))
+	assert.ErrorIs(t, engine.Failover(context.Background()), xorm.ErrFailoverNotEnabled)
+
+	failovers := make(chan string, 1)
+	assert.NoError(t, engine.EnableFailover(xorm.FailoverOptions{
+		NewDataSource: func(ctx context.Context) (string, error) {
+			return standby, nil
+		},
+		Threshold: 2,
+		// sqlite has no connection errors, so treat the missing tables as the failures
+		IsConnectionError: func(err error) bool {
+			return strings.Contains(err.Error(), "no such table")
+		},
+		OnFailover: func(dataSourceName string, err error) {
+			assert.NoError(t, err)
+			failovers <- dataSourceName
+		},
+	}))
+
+	oldDB := engine.DB()
+	_, err = engine.Exec("SELECT * FROM failover_missing")
+	assert.Error(t, err)
+	assert.EqualValues(t, primary, engine.DataSourceName())
+	_, err = engine.Exec("SELECT * FROM failover_missing")
+	assert.Error(t, err)
+
+	select {
+	case dataSourceName := <-failovers:
+		assert.EqualValues(t, standby, dataSourceName)
+	case <-time.After(5 * time.Second):
+		t.Fatal("failover is not triggered")
+	}
+	assert.EqualValues(t, standby, engine.DataSourceName())
+	assert.False(t, oldDB == engine.DB())
+	assert.EqualValues(t, 2, engine.DB().Stats().MaxOpenConnections)
+
+	var users []FailoverUser
+	assert.NoError(t, engine.Find(&users))
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "standby", users[0].Name)
+
+	// a manual failover
+	assert.NoError(t, engine.Failover(context.Background()))
+	assert.EqualValues(t, standby, <-failovers)
+	cnt, err := engine.Count(new(FailoverUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
+
+func TestGenericDialectInsert(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	// registers the sqlite driver as two generic databases, the one reads the inserted ids by
+	// LastInsertId of the driver and the other by a query
+	db, err := sql.Open("sqlite3", "")
+	assert.NoError(t, err)
+	sql.Register("generic_sqlite3", db.Driver())
+	sql.Register("generic_sqlite3_query", db.Driver())
+	assert.NoError(t, db.Close())
+	assert.NoError(t, dialects.RegisterGenericDialect(&dialects.GenericDialectConfig{
+		Name:       "genericlite",
+		DriverName: "generic_sqlite3",
+		ParseAs:    "sqlite3",
+	}))
+	assert.NoError(t, dialects.RegisterGenericDialect(&dialects.GenericDialectConfig{
+		Name:            "genericlite_query",
+		DriverName:      "generic_sqlite3_query",
+		ParseAs:         "sqlite3",
+		LastInsertIDSQL: "SELECT seq FROM sqlite_sequence WHERE name = '{table}'",
+	}))
+
+	type GenericUser struct {
+		Id   int64
+		Name string
+	}
+
+	for _, driverName := range []string{"generic_sqlite3", "generic_sqlite3_query"} {
+		t.Run(driverName, func(t *testing.T) {
+			engine, err := xorm.NewEngine(driverName, filepath.Join(t.TempDir(), "generic.db"))
+			assert.NoError(t, err)
+			defer engine.Close()
+
+			_, err = engine.Exec("CREATE TABLE generic_user (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+			assert.NoError(t, err)
+
+			for i, name := range []string{"a", "b"} {
+				user := GenericUser{Name: name}
+				cnt, err := engine.Insert(&user)
+				assert.NoError(t, err)
+				assert.EqualValues(t, 1, cnt)
+				assert.EqualValues(t, i+1, user.Id)
+			}
+		})
+	}
+}
@@ -5,8 +5,11 @@
 package tests
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/imkos/xorm"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -102,3 +105,53 @@ func BenchmarkFindStruct(b *testing.B) {
 		b.StartTimer()
 	}
 }
+
+type BenchmarkBulkRow struct {
+	Id   int64
+	Name string
+}
+
+func benchmarkBulkRows() []BenchmarkBulkRow {
+	rows := make([]BenchmarkBulkRow, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		rows = append(rows, BenchmarkBulkRow{Name: fmt.Sprintf("name%d", i)})
+	}
+	return rows
+}
+
+func BenchmarkInsertOneByOne(b *testing.B) {
+	b.StopTimer()
+
+	assert.NoError(b, PrepareEngine())
+	testEngine.ShowSQL(false)
+	assert.NoError(b, testEngine.Sync(new(BenchmarkBulkRow)))
+
+	rows := benchmarkBulkRows()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		sess := testEngine.NewSession()
+		assert.NoError(b, sess.Begin())
+		for j := range rows {
+			rows[j].Id = 0
+			_, err := sess.Insert(&rows[j])
+			assert.NoError(b, err)
+		}
+		assert.NoError(b, sess.Commit())
+		sess.Close()
+	}
+}
+
+func BenchmarkBulkInsert(b *testing.B) {
+	b.StopTimer()
+
+	assert.NoError(b, PrepareEngine())
+	testEngine.ShowSQL(false)
+	assert.NoError(b, testEngine.Sync(new(BenchmarkBulkRow)))
+
+	rows := benchmarkBulkRows()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := testEngine.BulkInsert(rows, xorm.BulkOptions{BatchSize: 100})
+		assert.NoError(b, err)
+	}
+}
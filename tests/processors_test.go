@@ -884,6 +884,60 @@ func TestAfterLoadProcessor(t *testing.T) {
 	}
 }
 
+type AfterScanStruct struct {
+	Id        int64
+	FirstName string
+	LastName  string
+	FullName  string   `xorm:"-"`
+	Columns   []string `xorm:"-"`
+}
+
+var errAfterScan = errors.New("after scan error")
+
+func (s *AfterScanStruct) AfterScan(ctx *xorm.ScanContext) error {
+	if s.FirstName == "" {
+		return errAfterScan
+	}
+	s.FullName = s.FirstName + " " + s.LastName
+	s.Columns = ctx.Columns
+	return nil
+}
+
+func TestAfterScanProcessor(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	assertSync(t, new(AfterScanStruct))
+
+	_, err := testEngine.Insert(&AfterScanStruct{FirstName: "xorm", LastName: "go"})
+	assert.NoError(t, err)
+
+	var s AfterScanStruct
+	has, err := testEngine.Get(&s)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "xorm go", s.FullName)
+	assert.EqualValues(t, []string{"id", "first_name", "last_name"}, s.Columns)
+
+	var ss []AfterScanStruct
+	err = testEngine.Cols("first_name", "last_name").Find(&ss)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(ss))
+	assert.EqualValues(t, "xorm go", ss[0].FullName)
+	assert.EqualValues(t, []string{"first_name", "last_name"}, ss[0].Columns)
+
+	rows, err := testEngine.Rows(new(AfterScanStruct))
+	assert.NoError(t, err)
+	for rows.Next() {
+		var s2 AfterScanStruct
+		assert.NoError(t, rows.Scan(&s2))
+		assert.EqualValues(t, "xorm go", s2.FullName)
+	}
+	assert.NoError(t, rows.Close())
+
+	err = testEngine.Cols("id").Find(&ss)
+	assert.ErrorIs(t, err, errAfterScan)
+}
+
 type AfterInsertStruct struct {
 	Id int64
 }
@@ -902,3 +956,103 @@ func TestAfterInsert(t *testing.T) {
 	_, err := testEngine.Insert(&AfterInsertStruct{})
 	assert.NoError(t, err)
 }
+
+type SavepointProcessorStruct struct {
+	Id       int64
+	Name     string `xorm:"unique"`
+	inserted bool
+}
+
+var errSavepointProcessor = errors.New("bad name")
+
+func (s *SavepointProcessorStruct) BeforeInsert(session *xorm.Session) error {
+	switch s.Name {
+	case "error":
+		return errSavepointProcessor
+	case "panic":
+		panic("bad name")
+	}
+	return nil
+}
+
+func (s *SavepointProcessorStruct) AfterInsert() {
+	s.inserted = true
+}
+
+func TestProcessorSavepoint(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SavepointProcessorStruct))
+
+	testEngine.SetProcessorSavepoint(true)
+	defer testEngine.SetProcessorSavepoint(false)
+
+	session := testEngine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+
+	first := SavepointProcessorStruct{Name: "first"}
+	_, err := session.Insert(&first)
+	assert.NoError(t, err)
+
+	// the beans inserted before the bad one are rolled back to the savepoint too
+	rolledBack := SavepointProcessorStruct{Name: "rolled back"}
+	_, err = session.Insert(&rolledBack, &SavepointProcessorStruct{Name: "error"})
+	assert.ErrorIs(t, err, errSavepointProcessor)
+
+	_, err = session.Insert(&SavepointProcessorStruct{Name: "panic"})
+	assert.Error(t, err)
+
+	// a failed statement will not abort the transaction
+	_, err = session.Insert(&SavepointProcessorStruct{Name: "first"})
+	assert.Error(t, err)
+
+	second := SavepointProcessorStruct{Name: "second"}
+	_, err = session.Insert(&second)
+	assert.NoError(t, err)
+
+	_, err = session.ID(second.Id).Update(&SavepointProcessorStruct{Name: "first"})
+	assert.Error(t, err)
+
+	assert.NoError(t, session.Commit())
+	assert.True(t, first.inserted)
+	assert.True(t, second.inserted)
+	assert.False(t, rolledBack.inserted)
+
+	var names []string
+	assert.NoError(t, testEngine.Table(new(SavepointProcessorStruct)).Asc("id").Cols("name").Find(&names))
+	assert.EqualValues(t, []string{"first", "second"}, names)
+}
+
+// panicConversion panics when it's converted to the database, it's not a processor
+type panicConversion string
+
+func (c *panicConversion) FromDB(data []byte) error {
+	*c = panicConversion(data)
+	return nil
+}
+
+func (c panicConversion) ToDB() ([]byte, error) {
+	panic("bad conversion")
+}
+
+func TestProcessorSavepointOtherPanic(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type SavepointConversionStruct struct {
+		Id    int64
+		Value panicConversion `xorm:"text"`
+	}
+	assertSync(t, new(SavepointConversionStruct))
+
+	testEngine.SetProcessorSavepoint(true)
+	defer testEngine.SetProcessorSavepoint(false)
+
+	session := testEngine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+
+	// only the panics of the processors are returned as errors
+	assert.PanicsWithValue(t, "bad conversion", func() {
+		_, _ = session.Insert(&SavepointConversionStruct{Value: "a"})
+	})
+}
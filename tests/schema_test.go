@@ -5,6 +5,8 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	"github.com/imkos/xorm"
+	"github.com/imkos/xorm/reverse"
 	"github.com/imkos/xorm/schemas"
 	"github.com/stretchr/testify/assert"
 )
@@ -726,6 +729,92 @@ func TestSyncWithOptions(t *testing.T) {
 	assert.ElementsMatch(t, getKeysFromMap(tableInfoFromStruct.Indexes), getKeysFromMap(getIndicesOfBeanFromDB(t, &SyncWithOpts1{})))
 }
 
+func TestSyncParallelism(t *testing.T) {
+	type SyncParallel1 struct {
+		Id   int64
+		Name string `xorm:"index"`
+	}
+	type SyncParallel2 struct {
+		Id    int64
+		Title string `xorm:"unique"`
+	}
+	type SyncParallel3 struct {
+		Id      int64
+		Content string
+	}
+	type SyncParallel4 struct {
+		Id    int64
+		Count int `xorm:"index"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+
+	beans := []interface{}{new(SyncParallel1), new(SyncParallel2), new(SyncParallel3), new(SyncParallel4)}
+	// the first sync creates the tables and the second one checks the existing tables
+	for i := 0; i < 2; i++ {
+		result, err := testEngine.SyncWithOptions(xorm.SyncOptions{Parallelism: 3}, beans...)
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+	}
+
+	for _, bean := range beans {
+		exist, err := testEngine.IsTableExist(bean)
+		assert.NoError(t, err)
+		assert.True(t, exist)
+	}
+	assert.Len(t, getIndicesOfBeanFromDB(t, new(SyncParallel1)), 1)
+	assert.Len(t, getIndicesOfBeanFromDB(t, new(SyncParallel2)), 1)
+}
+
+func TestBatchDBMetas(t *testing.T) {
+	type BatchMetas1 struct {
+		Id   int64
+		Name string `xorm:"varchar(50) index"`
+	}
+	type BatchMetas2 struct {
+		Id     int64
+		Title  string `xorm:"unique(title_kind)"`
+		Kind   int    `xorm:"unique(title_kind)"`
+		Remark string `xorm:"text"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(BatchMetas1), new(BatchMetas2))
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		// sqlite has no schemas
+		_, err = testEngine.DBMetas("analytics")
+		assert.Error(t, err)
+	}
+
+	testEngine.SetBatchDBMetas(true)
+	defer testEngine.SetBatchDBMetas(false)
+	batchTables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+
+	if !assert.Len(t, batchTables, len(tables)) {
+		return
+	}
+	for i, table := range tables {
+		batchTable := batchTables[i]
+		assert.EqualValues(t, table.Name, batchTable.Name)
+		assert.EqualValues(t, table.ColumnsSeq(), batchTable.ColumnsSeq())
+		assert.EqualValues(t, table.PrimaryKeys, batchTable.PrimaryKeys)
+		for _, col := range table.Columns() {
+			batchCol := batchTable.GetColumn(col.Name)
+			if assert.NotNil(t, batchCol) {
+				assert.EqualValues(t, col.SQLType, batchCol.SQLType)
+				assert.EqualValues(t, col.Nullable, batchCol.Nullable)
+				assert.EqualValues(t, col.Indexes, batchCol.Indexes)
+			}
+		}
+		assert.EqualValues(t, table.Indexes, batchTable.Indexes)
+	}
+}
+
 func getIndicesOfBeanFromDB(t *testing.T, bean interface{}) map[string]*schemas.Index {
 	dbm, err := testEngine.DBMetas()
 	assert.NoError(t, err)
@@ -788,3 +877,455 @@ func TestSync2_3(t *testing.T) {
 
 	}
 }
+
+type SyncAutoIncrStart struct {
+	Id   int64 `xorm:"pk autoincr(1000)"`
+	Name string
+}
+
+func (SyncAutoIncrStart) TableName() string {
+	return "sync_auto_incr_start"
+}
+
+type SyncAutoIncrStart2 struct {
+	Id   int64 `xorm:"pk autoincr(5000)"`
+	Name string
+}
+
+func (SyncAutoIncrStart2) TableName() string {
+	return "sync_auto_incr_start"
+}
+
+func TestSyncAutoIncrStart(t *testing.T) {
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.MYSQL, schemas.SQLITE, schemas.POSTGRES, schemas.MSSQL:
+	default:
+		t.Skip("auto increment start value is not supported")
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SyncAutoIncrStart))
+
+	first := SyncAutoIncrStart{Name: "first"}
+	_, err := testEngine.Insert(&first)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1000, first.Id)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	var found bool
+	for _, table := range tables {
+		if table.Name == "sync_auto_incr_start" {
+			found = true
+			assert.EqualValues(t, 1001, table.AutoIncrStart)
+		}
+	}
+	assert.True(t, found)
+
+	assert.NoError(t, testEngine.Sync(new(SyncAutoIncrStart2)))
+	second := SyncAutoIncrStart2{Name: "second"}
+	_, err = testEngine.Insert(&second)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5000, second.Id)
+
+	// a smaller start value will not make the ids go back
+	assert.NoError(t, testEngine.Sync(new(SyncAutoIncrStart)))
+	third := SyncAutoIncrStart{Name: "third"}
+	_, err = testEngine.Insert(&third)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5001, third.Id)
+}
+
+type SyncBatchA struct {
+	Id   int64
+	Name string `xorm:"index"`
+}
+
+type SyncBatchB struct {
+	Id    int64
+	Title string `xorm:"unique"`
+}
+
+func (SyncBatchB) TableName() string {
+	return "sync_batch_b"
+}
+
+type SyncBatchB2 struct {
+	Id     int64
+	Title  string `xorm:"unique"`
+	Status int    `xorm:"index"`
+}
+
+func (SyncBatchB2) TableName() string {
+	return "sync_batch_b"
+}
+
+func TestSyncManyExistingTables(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SyncBatchA), new(SyncBatchB))
+
+	// the existing tables are loaded at once, a table referenced twice is loaded again for the
+	// second bean, which drops the index of status added by the first one
+	assert.NoError(t, testEngine.Sync(new(SyncBatchA), new(SyncBatchB2), new(SyncBatchB)))
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	var found int
+	for _, table := range tables {
+		switch table.Name {
+		case "sync_batch_a":
+			found++
+			assert.EqualValues(t, 2, len(table.ColumnsSeq()))
+			assert.EqualValues(t, 1, len(table.Indexes))
+		case "sync_batch_b":
+			found++
+			assert.EqualValues(t, 3, len(table.ColumnsSeq()))
+			assert.NotNil(t, table.GetColumn("status"))
+			assert.EqualValues(t, 1, len(table.Indexes))
+		}
+	}
+	assert.EqualValues(t, 2, found)
+}
+
+type SchemaSnapshotUser struct {
+	Id      int64
+	Name    string    `xorm:"varchar(50) unique"`
+	Age     int       `xorm:"index"`
+	Created time.Time `xorm:"created"`
+}
+
+func TestSchemaSnapshot(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SchemaSnapshotUser))
+
+	snapshot, err := testEngine.SchemaSnapshot(new(SchemaSnapshotUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.SnapshotVersion, snapshot.Version)
+	assert.Len(t, snapshot.Tables, 1)
+
+	bs, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	var res schemas.Snapshot
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	table := res.Table("schema_snapshot_user")
+	if !assert.NotNil(t, table) {
+		return
+	}
+	assert.EqualValues(t, []string{"id", "name", "age", "created"}, table.ColumnsSeq())
+	assert.EqualValues(t, []string{"id"}, table.PrimaryKeys)
+	assert.Len(t, table.Indexes, 2)
+
+	assert.NoError(t, testEngine.DropTables(new(SchemaSnapshotUser)))
+	_, err = testEngine.ApplySchemaSnapshot(&res, xorm.SyncOptions{})
+	assert.NoError(t, err)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	var found *schemas.Table
+	for _, tb := range tables {
+		if tb.Name == "schema_snapshot_user" {
+			found = tb
+		}
+	}
+	if !assert.NotNil(t, found) {
+		return
+	}
+	assert.EqualValues(t, 4, len(found.ColumnsSeq()))
+	assert.EqualValues(t, 2, len(found.Indexes))
+
+	cnt, err := testEngine.Insert(&SchemaSnapshotUser{Name: "a", Age: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// a new column in the snapshot will be added to the existing table
+	col := schemas.NewColumn("nickname", "", schemas.SQLType{Name: schemas.Varchar}, 20, 0, true)
+	table.AddColumn(col)
+	_, err = testEngine.ApplySchemaSnapshot(&res, xorm.SyncOptions{})
+	assert.NoError(t, err)
+
+	snapshot, err = testEngine.SchemaSnapshot()
+	assert.NoError(t, err)
+	found = snapshot.Table("schema_snapshot_user")
+	if assert.NotNil(t, found) {
+		assert.NotNil(t, found.GetColumn("nickname"))
+	}
+}
+
+type GenerateModelsUser struct {
+	Id       int64
+	UserName string `xorm:"varchar(50) notnull unique"`
+	Age      int    `xorm:"index"`
+	Created  time.Time
+}
+
+func TestGenerateModels(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(GenerateModelsUser))
+
+	var buf bytes.Buffer
+	assert.NoError(t, testEngine.GenerateModels(&buf, reverse.Options{
+		Tables: []string{"generate_models_user"},
+	}))
+	src := buf.String()
+	assert.Contains(t, src, "package models")
+	assert.Contains(t, src, "type GenerateModelsUser struct {")
+	assert.Contains(t, src, "'id' pk autoincr")
+	assert.Regexp(t, "UserName +string +`xorm:\"'user_name' [A-Z]+(\\(50\\))? notnull unique\"`", src)
+	assert.Regexp(t, "'age' [A-Z]+ null index\"", src)
+	assert.Contains(t, src, "time.Time")
+	assert.Contains(t, src, `return "generate_models_user"`)
+
+	assert.Error(t, testEngine.GenerateModels(&buf, reverse.Options{
+		Tables: []string{"not_exist"},
+	}))
+}
+
+type TestTableOptionsStruct struct {
+	Code string `xorm:"varchar(20) pk"`
+	Name string
+}
+
+func (TestTableOptionsStruct) TableOptions() map[string]string {
+	return map[string]string{
+		"WITHOUT ROWID": "",
+		"fillfactor":    "90",
+		"ROW_FORMAT":    "DYNAMIC",
+	}
+}
+
+func TestTableOptions(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TestTableOptionsStruct))
+	assert.NoError(t, testEngine.Sync(new(TestTableOptionsStruct)))
+
+	_, err := testEngine.Insert(&TestTableOptionsStruct{Code: "a", Name: "lunny"})
+	assert.NoError(t, err)
+
+	table, err := testEngine.TableInfo(new(TestTableOptionsStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "90", table.Options["fillfactor"])
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		var sql string
+		has, err := testEngine.SQL("SELECT sql FROM sqlite_master WHERE type='table' AND name = ?",
+			testEngine.TableName("test_table_options_struct", true)).Get(&sql)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		assert.True(t, strings.HasSuffix(sql, "WITHOUT ROWID"))
+	}
+}
+
+type TestStrictTable struct {
+	Id      int64
+	Name    string
+	Amount  float64 `xorm:"decimal(10,2)"`
+	Active  bool
+	Created time.Time
+}
+
+func (TestStrictTable) TableOptions() map[string]string {
+	return map[string]string{"STRICT": ""}
+}
+
+type TestStrictTable2 struct {
+	Id      int64
+	Name    string
+	Amount  float64 `xorm:"decimal(10,2)"`
+	Active  bool
+	Created time.Time
+	Updated time.Time
+}
+
+func (TestStrictTable2) TableName() string {
+	return "test_strict_table"
+}
+
+func (TestStrictTable2) TableOptions() map[string]string {
+	return map[string]string{"STRICT": ""}
+}
+
+func TestSqliteStrictTable(t *testing.T) {
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TestStrictTable))
+
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, testEngine.GetTZLocation())
+	_, err := testEngine.Insert(&TestStrictTable{Name: "lunny", Amount: 1.5, Active: true, Created: created})
+	assert.NoError(t, err)
+
+	var record TestStrictTable
+	has, err := testEngine.Get(&record)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 1.5, record.Amount)
+	assert.True(t, record.Active)
+	assert.True(t, created.Equal(record.Created))
+
+	assert.NoError(t, testEngine.Sync(new(TestStrictTable2)))
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == "test_strict_table" {
+			assert.Contains(t, table.Options, "STRICT")
+			assert.EqualValues(t, schemas.Text, table.GetColumn("updated").SQLType.Name)
+		}
+	}
+}
+
+func TestSchemaCache(t *testing.T) {
+	type SchemaCache struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SchemaCache)))
+
+	testEngine.SetSchemaCacheTTL(time.Minute)
+	defer testEngine.SetSchemaCacheTTL(0)
+
+	exist, err := testEngine.IsTableExist(new(SchemaCache))
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	// creating the table by the engine invalidates the cache
+	assert.NoError(t, testEngine.CreateTables(new(SchemaCache)))
+	exist, err = testEngine.IsTableExist(new(SchemaCache))
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	exist, err = testEngine.IsColumnExist(new(SchemaCache), "name")
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	exist, err = testEngine.IsColumnExist(new(SchemaCache), "title")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	// the changes outside the engine are not visible until the cache is invalidated
+	tableName := testEngine.Quote(testEngine.TableName(new(SchemaCache), true))
+	_, err = testEngine.Exec("DROP TABLE " + tableName)
+	assert.NoError(t, err)
+	exist, err = testEngine.IsTableExist(new(SchemaCache))
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	testEngine.InvalidateSchemaCache(new(SchemaCache))
+	exist, err = testEngine.IsTableExist(new(SchemaCache))
+	assert.NoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestRegisterModels(t *testing.T) {
+	type RegisterModel struct {
+		Id   int64
+		Name string
+	}
+	type RegisterDuplicate struct {
+		Id    int64
+		Name  string
+		Title string `xorm:"'name'"`
+	}
+	type RegisterUnsupported struct {
+		Id     int64
+		Notify chan int
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.RegisterModels(new(RegisterModel)))
+
+	err := testEngine.RegisterModels(new(RegisterModel), new(RegisterDuplicate), new(RegisterUnsupported))
+	var errs xorm.ModelErrors
+	if assert.ErrorAs(t, err, &errs) && assert.Len(t, errs, 2) {
+		assert.ErrorIs(t, errs[0], xorm.ErrDuplicateColumn)
+		assert.EqualValues(t, "Title", errs[0].Field)
+		assert.ErrorIs(t, errs[1], xorm.ErrUnSupportedType)
+		assert.EqualValues(t, "Notify", errs[1].Field)
+	}
+
+	assert.NoError(t, testEngine.DropTables(new(RegisterModel)))
+	err = testEngine.RegisterModelsWithOptions(xorm.RegisterOptions{VerifySchema: true}, new(RegisterModel))
+	assert.ErrorIs(t, err, xorm.ErrSchemaMismatch)
+
+	assertSync(t, new(RegisterModel))
+	assert.NoError(t, testEngine.RegisterModelsWithOptions(xorm.RegisterOptions{VerifySchema: true}, new(RegisterModel)))
+
+	err = testEngine.RegisterModelsWithOptions(xorm.RegisterOptions{VerifySchema: true}, new(RegisterModelV2))
+	if assert.ErrorAs(t, err, &errs) && assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs[0], xorm.ErrSchemaMismatch)
+		assert.EqualValues(t, "Title", errs[0].Field)
+	}
+}
+
+type RegisterModelV2 struct {
+	Id    int64
+	Name  string
+	Title string
+}
+
+func (RegisterModelV2) TableName() string {
+	return "register_model"
+}
+
+type SyncBatchB3 struct {
+	Id     int64
+	Title  string `xorm:"unique"`
+	Status int    `xorm:"index"`
+	Memo   string
+}
+
+func (SyncBatchB3) TableName() string {
+	return "sync_batch_b"
+}
+
+type SyncBatchB4 struct {
+	Id     int64
+	Title  string `xorm:"unique"`
+	Status int    `xorm:"index"`
+	Memo   string
+	Score  int
+}
+
+func (SyncBatchB4) TableName() string {
+	return "sync_batch_b"
+}
+
+func TestSyncSharedTable(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SyncBatchB))
+
+	// the table is changed by the first bean, so it's loaded again for the second one
+	assert.NoError(t, testEngine.Sync(new(SyncBatchB3), new(SyncBatchB4)))
+	assertSyncBatchBColumns(t, 5)
+
+	_, err := testEngine.SyncWithOptions(xorm.SyncOptions{Parallelism: 2}, new(SyncBatchA), new(SyncBatchB3), new(SyncBatchB4))
+	assert.NoError(t, err)
+
+	assertSyncBatchBColumns(t, 5)
+
+	// the tables created in a transaction are loaded by the transaction
+	assert.NoError(t, testEngine.DropTables(new(SyncBatchB)))
+	session := testEngine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+	assert.NoError(t, session.Sync(new(SyncBatchB)))
+	assert.NoError(t, session.Sync(new(SyncBatchA), new(SyncBatchB4)))
+	assert.NoError(t, session.Commit())
+
+	assertSyncBatchBColumns(t, 5)
+}
+
+func assertSyncBatchBColumns(t *testing.T, n int) {
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == "sync_batch_b" {
+			assert.Len(t, table.ColumnsSeq(), n)
+			return
+		}
+	}
+	t.Error("sync_batch_b is not found")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"xorm.io/builder"
+	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/schemas"
 )
 
@@ -134,3 +135,46 @@ func TestMustCol(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, affected)
 }
+
+func TestColsNotFound(t *testing.T) {
+	type ColsNotFound struct {
+		Id   int64
+		Name string
+		Cnt  int
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ColsNotFound))
+
+	_, err := testEngine.Insert(&ColsNotFound{Name: "a"})
+	assert.NoError(t, err)
+
+	var colErr xorm.ErrColumnNotFound
+	var beans []ColsNotFound
+	err = testEngine.Cols("id", "nmae").Find(&beans)
+	if assert.ErrorAs(t, err, &colErr) {
+		assert.EqualValues(t, "nmae", colErr.Column)
+		assert.EqualValues(t, []string{"id", "name", "cnt"}, colErr.Columns)
+	}
+
+	_, err = testEngine.Omit("title").Get(new(ColsNotFound))
+	assert.ErrorAs(t, err, &colErr)
+
+	_, err = testEngine.ID(1).MustCols("title").Update(new(ColsNotFound))
+	assert.ErrorAs(t, err, &colErr)
+
+	_, err = testEngine.ID(1).Incr("count").Update(new(ColsNotFound))
+	assert.ErrorAs(t, err, &colErr)
+
+	_, err = testEngine.Cols("title").Insert(&ColsNotFound{Name: "b"})
+	assert.ErrorAs(t, err, &colErr)
+
+	// the quoted and the qualified columns are allowed
+	has, err := testEngine.Cols("`name`", "cols_not_found.cnt").Get(new(ColsNotFound))
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	cnt, err := testEngine.ID(1).Incr("`cnt`").Update(new(ColsNotFound))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
@@ -8,7 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/imkos/xorm"
+	"github.com/imkos/xorm/schemas"
 	"github.com/stretchr/testify/assert"
 	"xorm.io/builder"
 )
@@ -226,3 +229,431 @@ func TestFindAndCount(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, total)
 }
+
+func TestWhereExists(t *testing.T) {
+	type ExistsUser struct {
+		Id   int64
+		Name string
+	}
+
+	type ExistsOrder struct {
+		Id     int64
+		UserId int64
+		Amount int
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ExistsUser), new(ExistsOrder))
+
+	users := []ExistsUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	for i := range users {
+		_, err := testEngine.Insert(&users[i])
+		assert.NoError(t, err)
+	}
+	_, err := testEngine.Insert([]ExistsOrder{
+		{UserId: users[0].Id, Amount: 10},
+		{UserId: users[1].Id, Amount: 100},
+	})
+	assert.NoError(t, err)
+
+	var res []ExistsUser
+	err = testEngine.WhereExists(builder.Select("1").From("`exists_order`").
+		Where(builder.Expr("`exists_order`.`user_id` = `exists_user`.`id`").And(builder.Gt{"`exists_order`.`amount`": 50}))).
+		Find(&res)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(res))
+	assert.EqualValues(t, "b", res[0].Name)
+
+	res = res[:0]
+	err = testEngine.Where("`name` <> ?", "a").
+		WhereNotExists(builder.Select("1").From("`exists_order`").
+			Where(builder.Expr("`exists_order`.`user_id` = `exists_user`.`id`"))).
+		Find(&res)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(res))
+	assert.EqualValues(t, "c", res[0].Name)
+
+	cnt, err := testEngine.WhereNotExists(builder.Select("1").From("`exists_order`").
+		Where(builder.Expr("`exists_order`.`user_id` = `exists_user`.`id`"))).
+		Count(new(ExistsUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
+
+func TestWhereGroup(t *testing.T) {
+	type WhereGroupItem struct {
+		Id int64
+		A  int
+		B  int
+		C  int
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(WhereGroupItem))
+
+	_, err := testEngine.Insert([]WhereGroupItem{
+		{A: 1, B: 2},
+		{A: 1, C: 3},
+		{A: 2, C: 3},
+		{A: 1},
+	})
+	assert.NoError(t, err)
+
+	// without a group, the OR applies to the whole condition
+	cnt, err := testEngine.Where("`a` = ?", 1).And("`b` = ?", 2).Or("`c` = ?", 3).Count(new(WhereGroupItem))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	cnt, err = testEngine.Where("`a` = ?", 1).WhereGroup(func(s *xorm.Session) {
+		s.Where("`b` = ?", 2).Or("`c` = ?", 3)
+	}).Count(new(WhereGroupItem))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Where("`a` = ?", 2).OrGroup(func(s *xorm.Session) {
+		s.Where("`a` = ?", 1).And("`b` = ?", 2)
+	}).Count(new(WhereGroupItem))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	// groups could be nested
+	var items []WhereGroupItem
+	err = testEngine.WhereGroup(func(s *xorm.Session) {
+		s.Where("`a` = ?", 1).AndGroup(func(s *xorm.Session) {
+			s.Where("`b` = ?", 2).Or("`c` = ?", 3)
+		})
+	}).OrGroup(func(s *xorm.Session) {
+		s.Where("`a` = ?", 2).And("`b` = ?", 2)
+	}).Asc("id").Find(&items)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(items))
+	assert.EqualValues(t, 2, items[0].B)
+	assert.EqualValues(t, 3, items[1].C)
+
+	// an empty group adds nothing
+	cnt, err = testEngine.WhereGroup(func(s *xorm.Session) {}).Count(new(WhereGroupItem))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, cnt)
+}
+
+func TestScopes(t *testing.T) {
+	type ScopeArticle struct {
+		Id       int64
+		TenantId int64
+		Status   string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ScopeArticle))
+
+	_, err := testEngine.Insert([]ScopeArticle{
+		{TenantId: 1, Status: "published"},
+		{TenantId: 1, Status: "draft"},
+		{TenantId: 2, Status: "published"},
+	})
+	assert.NoError(t, err)
+
+	tenant := func(tenantID int64) func(*xorm.Session) *xorm.Session {
+		return func(s *xorm.Session) *xorm.Session {
+			return s.Where("`tenant_id` = ?", tenantID)
+		}
+	}
+	testEngine.DefineScope("published", func(s *xorm.Session) *xorm.Session {
+		return s.Where("`status` = ?", "published")
+	})
+
+	cnt, err := testEngine.Apply(tenant(1)).Count(new(ScopeArticle))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Scope("published").Count(new(ScopeArticle))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var articles []ScopeArticle
+	err = testEngine.Apply(tenant(1)).Scope("published").Find(&articles)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(articles))
+	assert.EqualValues(t, "published", articles[0].Status)
+
+	_, err = testEngine.Scope("unknown").Count(new(ScopeArticle))
+	assert.True(t, errors.Is(err, xorm.ErrUnknownScope))
+}
+
+func TestDefaultScope(t *testing.T) {
+	type DefaultScopePost struct {
+		Id        int64
+		Title     string
+		Published bool
+		DeletedAt time.Time `xorm:"deleted"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(DefaultScopePost))
+
+	_, err := testEngine.Insert([]DefaultScopePost{
+		{Title: "a", Published: true},
+		{Title: "b", Published: false},
+		{Title: "c", Published: true},
+	})
+	assert.NoError(t, err)
+
+	testEngine.AddDefaultScope(new(DefaultScopePost), builder.Eq{"published": true})
+
+	cnt, err := testEngine.Count(new(DefaultScopePost))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var posts []DefaultScopePost
+	assert.NoError(t, testEngine.Asc("id").Find(&posts))
+	assert.EqualValues(t, 2, len(posts))
+	assert.EqualValues(t, "a", posts[0].Title)
+	assert.EqualValues(t, "c", posts[1].Title)
+
+	posts = posts[:0]
+	total, err := testEngine.Limit(1).FindAndCount(&posts)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.EqualValues(t, 1, len(posts))
+
+	var post DefaultScopePost
+	has, err := testEngine.Where("`title` = ?", "b").Get(&post)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	has, err = testEngine.Unscoped().Where("`title` = ?", "b").Get(&post)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	has, err = testEngine.Where("`title` = ?", "b").Exist(new(DefaultScopePost))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	cnt, err = testEngine.Where("`title` = ?", "b").Cols("title").Update(&DefaultScopePost{Title: "bb"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	cnt, err = testEngine.Where("`title` <> ?", "").Cols("title").Update(&DefaultScopePost{Title: "x"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	// the soft delete only deletes the published posts, and the deleted ones are hidden as before
+	cnt, err = testEngine.Where("`title` <> ?", "").Delete(new(DefaultScopePost))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Count(new(DefaultScopePost))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	posts = posts[:0]
+	total, err = testEngine.Unscoped().FindAndCount(&posts)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	assert.EqualValues(t, 3, len(posts))
+}
+
+func TestDeletedCond(t *testing.T) {
+	type DeletedCondOrder struct {
+		Id        int64
+		Title     string
+		Purged    int
+		DeletedAt time.Time `xorm:"deleted"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(DeletedCondOrder))
+
+	_, err := testEngine.Insert([]DeletedCondOrder{
+		{Title: "a"},
+		{Title: "b", Purged: 1},
+		{Title: "c"},
+	})
+	assert.NoError(t, err)
+
+	testEngine.SetDeletedCond(new(DeletedCondOrder), func(col *schemas.Column) builder.Cond {
+		return builder.IsNull{col.Name}.And(builder.Eq{"purged": 0})
+	})
+	defer testEngine.SetDeletedCond(new(DeletedCondOrder), nil)
+
+	cnt, err := testEngine.Count(new(DeletedCondOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var orders []DeletedCondOrder
+	assert.NoError(t, testEngine.Asc("id").Find(&orders))
+	if assert.Len(t, orders, 2) {
+		assert.EqualValues(t, "a", orders[0].Title)
+		assert.EqualValues(t, "c", orders[1].Title)
+	}
+
+	var order DeletedCondOrder
+	has, err := testEngine.Where("`title` = ?", "b").Get(&order)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	cnt, err = testEngine.Where("`title` = ?", "c").Delete(new(DeletedCondOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Count(new(DeletedCondOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Unscoped().Count(new(DeletedCondOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	// the default condition only checks the deleted column
+	testEngine.SetDeletedCond(new(DeletedCondOrder), nil)
+	cnt, err = testEngine.Count(new(DeletedCondOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+}
+
+func TestWhereNull(t *testing.T) {
+	type WhereNullStruct struct {
+		Id   int64
+		Name *string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(WhereNullStruct))
+
+	a, b := "a", "b"
+	_, err := testEngine.Insert([]*WhereNullStruct{{Name: &a}, {Name: &b}, {}})
+	assert.NoError(t, err)
+
+	var res []WhereNullStruct
+	assert.NoError(t, testEngine.WhereNull("name").Find(&res))
+	assert.Len(t, res, 1)
+	assert.EqualValues(t, 3, res[0].Id)
+
+	res = nil
+	assert.NoError(t, testEngine.WhereNotNull("name").Asc("id").Find(&res))
+	assert.Len(t, res, 2)
+
+	res = nil
+	assert.NoError(t, testEngine.WhereNullSafeEq("name", "b").Find(&res))
+	assert.Len(t, res, 1)
+	assert.EqualValues(t, 2, res[0].Id)
+
+	res = nil
+	assert.NoError(t, testEngine.WhereNullSafeEq("name", nil).Find(&res))
+	assert.Len(t, res, 1)
+	assert.EqualValues(t, 3, res[0].Id)
+
+	cnt, err := testEngine.WhereNull("name").Or("id = ?", 1).Count(new(WhereNullStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+}
+
+func TestLikeHelpers(t *testing.T) {
+	type LikeHelpersStruct struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(LikeHelpersStruct))
+
+	names := []string{"Apple", "apple pie", "100% juice", "100 juice", "a_b", "axb", "[x]"}
+	for _, name := range names {
+		_, err := testEngine.Insert(&LikeHelpersStruct{Name: name})
+		assert.NoError(t, err)
+	}
+
+	find := func(session *xorm.Session) []string {
+		var res []LikeHelpersStruct
+		assert.NoError(t, session.Asc("id").Find(&res))
+		founds := make([]string, 0, len(res))
+		for _, r := range res {
+			founds = append(founds, r.Name)
+		}
+		return founds
+	}
+
+	assert.EqualValues(t, []string{"Apple", "apple pie"}, find(testEngine.ILike("name", "APPLE%")))
+	assert.EqualValues(t, []string{"100% juice"}, find(testEngine.StartsWith("name", "100%")))
+	assert.EqualValues(t, []string{"a_b"}, find(testEngine.EndsWith("name", "_b")))
+	assert.EqualValues(t, []string{"a_b"}, find(testEngine.Contains("name", "_")))
+	assert.EqualValues(t, []string{"[x]"}, find(testEngine.Contains("name", "[x")))
+	assert.EqualValues(t, []string{"apple pie"}, find(testEngine.Contains("name", "le p")))
+}
+
+func TestSortByFilterBy(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type FilterProduct struct {
+		Id      int64
+		Name    string
+		Price   int
+		Deleted *time.Time
+	}
+	assert.NoError(t, testEngine.Sync(new(FilterProduct)))
+
+	now := time.Now()
+	_, err := testEngine.Insert([]FilterProduct{
+		{Name: "apple", Price: 5},
+		{Name: "banana", Price: 3},
+		{Name: "cherry", Price: 10, Deleted: &now},
+	})
+	assert.NoError(t, err)
+
+	var products []FilterProduct
+	err = testEngine.Table(new(FilterProduct)).OrderBySafe("-price,name", nil).Find(&products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 3)
+	assert.EqualValues(t, "cherry", products[0].Name)
+	assert.EqualValues(t, "banana", products[2].Name)
+
+	products = nil
+	err = testEngine.Table(new(FilterProduct)).OrderBySafe("price; DROP TABLE filter_product", nil).Find(&products)
+	assert.True(t, errors.Is(err, xorm.ErrUnknownSortKey))
+
+	err = testEngine.OrderBySafe("cost", map[string]string{"cost": "price"}).Find(&products)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "banana", products[0].Name)
+
+	spec := xorm.FilterSpec{
+		"name":    {Ops: []string{xorm.FilterEq, xorm.FilterLike, xorm.FilterIn}},
+		"cost":    {Column: "price", Ops: []string{xorm.FilterGte, xorm.FilterLt}},
+		"deleted": {Ops: []string{xorm.FilterNull}},
+	}
+	products = nil
+	err = testEngine.FilterBy(map[string][]string{
+		"cost[gte]":     {"4"},
+		"deleted[null]": {"true"},
+		"page":          {"1"},
+	}, spec).Find(&products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.EqualValues(t, "apple", products[0].Name)
+
+	products = nil
+	err = testEngine.FilterBy(map[string][]string{"name[in]": {"apple,banana"}}, spec).Asc("id").Find(&products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+
+	products = nil
+	err = testEngine.FilterBy(map[string][]string{"name": {"banana", "cherry"}}, spec).Find(&products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+
+	// the wildcards of the like filter are matched literally
+	products = nil
+	err = testEngine.FilterBy(map[string][]string{"name[like]": {"an"}}, spec).Find(&products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	products = nil
+	err = testEngine.FilterBy(map[string][]string{"name[like]": {"%"}}, spec).Find(&products)
+	assert.NoError(t, err)
+	assert.Len(t, products, 0)
+
+	// the spec is required
+	err = testEngine.Table(new(FilterProduct)).FilterBy(map[string][]string{"name": {"apple"}}, nil).Find(&products)
+	assert.Error(t, err)
+
+	err = testEngine.FilterBy(map[string][]string{"cost[ne]": {"3"}}, spec).Find(&products)
+	assert.True(t, errors.Is(err, xorm.ErrUnknownFilterKey))
+}
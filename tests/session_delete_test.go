@@ -6,9 +6,11 @@ package tests
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/caches"
 	"github.com/imkos/xorm/schemas"
 
@@ -74,7 +76,7 @@ func TestDelete(t *testing.T) {
 func TestDeleteLimit(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 
-	if testEngine.Dialect().URI().DBType == schemas.MSSQL || os.Getenv("IGNORE_TEST_DELETE_LIMIT") == "true" {
+	if os.Getenv("IGNORE_TEST_DELETE_LIMIT") == "true" {
 		t.Skip()
 		return
 	}
@@ -349,3 +351,119 @@ func TestTruncate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, has)
 }
+
+func TestTruncateWithOptions(t *testing.T) {
+	type TruncateOption struct {
+		Id   int64 `xorm:"pk autoincr"`
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TruncateOption))
+
+	_, err := testEngine.Insert([]TruncateOption{{Name: "a"}, {Name: "b"}})
+	assert.NoError(t, err)
+
+	// without RestartIdentity the ids continue
+	_, err = testEngine.Truncate(new(TruncateOption), xorm.TruncateOptions{})
+	assert.NoError(t, err)
+	cnt, err := testEngine.Count(new(TruncateOption))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	bean := TruncateOption{Name: "c"}
+	_, err = testEngine.Insert(&bean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, bean.Id)
+
+	_, err = testEngine.Truncate(new(TruncateOption), &xorm.TruncateOptions{RestartIdentity: true})
+	assert.NoError(t, err)
+	cnt, err = testEngine.Count(new(TruncateOption))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	bean = TruncateOption{Name: "d"}
+	_, err = testEngine.Insert(&bean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, bean.Id)
+
+	_, err = testEngine.Where("id = ?", 1).Truncate(new(TruncateOption), xorm.TruncateOptions{})
+	assert.Error(t, err)
+
+	if testEngine.Dialect().URI().DBType != schemas.POSTGRES {
+		_, err = testEngine.Truncate(new(TruncateOption), xorm.TruncateOptions{Cascade: true})
+		assert.ErrorIs(t, err, xorm.ErrUnsupportedTruncateOption)
+	}
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		// there is no sqlite_sequence if no table has AUTOINCREMENT
+		engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "truncate.db"))
+		assert.NoError(t, err)
+		defer engine.Close()
+		_, err = engine.Exec("CREATE TABLE truncate_rowid (id INTEGER PRIMARY KEY, name TEXT)")
+		assert.NoError(t, err)
+		_, err = engine.Exec("INSERT INTO truncate_rowid (name) VALUES ('a')")
+		assert.NoError(t, err)
+		affected, err := engine.Table("truncate_rowid").Truncate(xorm.TruncateOptions{RestartIdentity: true})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, affected)
+	}
+}
+
+func TestDeletedFlag(t *testing.T) {
+	type DeletedFlagBool struct {
+		Id        int64
+		Name      string
+		IsDeleted bool `xorm:"deleted_flag"`
+	}
+
+	type DeletedFlagInt struct {
+		Id        int64
+		Name      string
+		IsDeleted int `xorm:"deleted(bool) notnull default 0"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(DeletedFlagBool), new(DeletedFlagInt))
+
+	for _, beans := range [][2]interface{}{
+		{&DeletedFlagBool{Name: "a"}, &DeletedFlagBool{Name: "b"}},
+		{&DeletedFlagInt{Name: "a"}, &DeletedFlagInt{Name: "b"}},
+	} {
+		_, err := testEngine.Insert(beans[0], beans[1])
+		assert.NoError(t, err)
+	}
+
+	var boolBean DeletedFlagBool
+	cnt, err := testEngine.Where("name = ?", "a").Delete(&boolBean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.True(t, boolBean.IsDeleted)
+
+	var boolBeans []DeletedFlagBool
+	assert.NoError(t, testEngine.Find(&boolBeans))
+	assert.Len(t, boolBeans, 1)
+	assert.EqualValues(t, "b", boolBeans[0].Name)
+
+	boolBeans = nil
+	assert.NoError(t, testEngine.Unscoped().Where("name = ?", "a").Find(&boolBeans))
+	if assert.Len(t, boolBeans, 1) {
+		assert.True(t, boolBeans[0].IsDeleted)
+	}
+
+	var intBean DeletedFlagInt
+	cnt, err = testEngine.Where("name = ?", "b").Delete(&intBean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.EqualValues(t, 1, intBean.IsDeleted)
+
+	total, err := testEngine.Count(new(DeletedFlagInt))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	var deleted int
+	has, err := testEngine.Table(new(DeletedFlagInt)).Where("name = ?", "b").Cols("is_deleted").Get(&deleted)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 1, deleted)
+}
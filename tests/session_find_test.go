@@ -5,6 +5,8 @@
 package tests
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -273,6 +275,46 @@ func TestHaving(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGroupByHavingArgs(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type GroupByHaving struct {
+		Id   int64
+		Name string
+		Age  int
+	}
+	assertSync(t, new(GroupByHaving))
+
+	_, err := testEngine.Insert([]GroupByHaving{
+		{Name: "lunny", Age: 18},
+		{Name: "lunny", Age: 20},
+		{Name: "xlw", Age: 30},
+	})
+	assert.NoError(t, err)
+
+	var names []string
+	err = testEngine.Table(new(GroupByHaving)).Cols("name").GroupBy("name").
+		Having("count(*) > ?", 1).Find(&names)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"lunny"}, names)
+
+	names = nil
+	err = testEngine.Table(new(GroupByHaving)).Cols("name").GroupBy("name").
+		Having(builder.Expr("sum(age) > ?", 25)).Asc("name").Find(&names)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"lunny", "xlw"}, names)
+
+	var ages []int
+	err = testEngine.Table(new(GroupByHaving)).Select("age / 10 * 10").
+		GroupBy(builder.Expr("age / ? * ?", 10, 10)).Find(&ages)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{10, 20, 30}, ages)
+
+	names = nil
+	err = testEngine.Table(new(GroupByHaving)).Cols("name").GroupBy(1).Find(&names)
+	assert.Error(t, err)
+}
+
 func TestOrderSameMapper(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 	testEngine.UnMapType(utils.ReflectValue(new(Userinfo)).Type())
@@ -1306,3 +1348,226 @@ func TestFindInMaxID(t *testing.T) {
 	err := testEngine.In("id", builder.Select("max(id)").From(testEngine.Quote(tableName))).Find(&res)
 	assert.NoError(t, err)
 }
+
+func TestOrderBySafe(t *testing.T) {
+	type OrderBySafeUser struct {
+		Id   int64
+		Name string
+		Age  int
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(OrderBySafeUser))
+
+	_, err := testEngine.Insert([]OrderBySafeUser{
+		{Name: "a", Age: 20},
+		{Name: "b", Age: 30},
+		{Name: "c", Age: 20},
+	})
+	assert.NoError(t, err)
+
+	allowed := map[string]string{
+		"name": "name",
+		"age":  "age DESC",
+	}
+
+	var users []OrderBySafeUser
+	assert.NoError(t, testEngine.OrderBySafe("-name", allowed).Find(&users))
+	assert.EqualValues(t, []string{"c", "b", "a"}, []string{users[0].Name, users[1].Name, users[2].Name})
+
+	users = nil
+	assert.NoError(t, testEngine.OrderBySafe("age, name desc", allowed).Find(&users))
+	assert.EqualValues(t, []string{"b", "c", "a"}, []string{users[0].Name, users[1].Name, users[2].Name})
+
+	users = nil
+	assert.NoError(t, testEngine.OrderBySafe("+age,+name", allowed).Find(&users))
+	assert.EqualValues(t, []string{"a", "c", "b"}, []string{users[0].Name, users[1].Name, users[2].Name})
+
+	users = nil
+	assert.NoError(t, testEngine.OrderBySafe("", allowed).Find(&users))
+	assert.EqualValues(t, 3, len(users))
+
+	for _, input := range []string{"id", "name; DROP TABLE order_by_safe_user", "name sideways", "-age desc"} {
+		err = testEngine.OrderBySafe(input, allowed).Find(&users)
+		assert.ErrorIs(t, err, xorm.ErrUnknownSortKey)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	type PluckUser struct {
+		Id        int64
+		Name      string
+		Age       int
+		CreatedAt time.Time `xorm:"created"`
+		DeletedAt time.Time `xorm:"deleted"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(PluckUser))
+
+	_, err := testEngine.Insert([]PluckUser{
+		{Name: "a", Age: 10},
+		{Name: "b", Age: 20},
+		{Name: "c", Age: 20},
+		{Name: "d", Age: 30},
+	})
+	assert.NoError(t, err)
+	_, err = testEngine.Where("`name` = ?", "d").Delete(new(PluckUser))
+	assert.NoError(t, err)
+
+	var names []string
+	err = testEngine.Table(new(PluckUser)).Where("`age` >= ?", 20).Asc("id").Pluck("name", &names)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"b", "c"}, names)
+
+	var ages []int
+	err = testEngine.Table(new(PluckUser)).Asc("age").PluckDistinct("age", &ages)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{10, 20}, ages)
+
+	var ids []int64
+	err = testEngine.Table(new(PluckUser)).Cols("name").Desc("id").Limit(2).Pluck("id", &ids)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(ids))
+	assert.True(t, ids[0] > ids[1])
+
+	var times []time.Time
+	err = testEngine.Table(new(PluckUser)).Unscoped().Pluck("created_at", &times)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, len(times))
+	assert.False(t, times[0].IsZero())
+
+	err = testEngine.Table(new(PluckUser)).Pluck("name", names)
+	assert.Error(t, err)
+}
+
+func TestPrepareQuery(t *testing.T) {
+	type PrepareQueryUser struct {
+		Id      int64
+		Name    string
+		Age     int
+		Deleted time.Time `xorm:"deleted"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(PrepareQueryUser))
+
+	users := []PrepareQueryUser{{Name: "a", Age: 10}, {Name: "b", Age: 20}, {Name: "c", Age: 30}, {Name: "d", Age: 40}}
+	_, err := testEngine.Insert(users)
+	assert.NoError(t, err)
+	_, err = testEngine.Where("name = ?", "d").Delete(new(PrepareQueryUser))
+	assert.NoError(t, err)
+
+	q := testEngine.PrepareQuery(func(session *xorm.Session) *xorm.Session {
+		return session.Table(new(PrepareQueryUser)).
+			Where("age > ?", xorm.Param("age")).
+			And(builder.Neq{"name": xorm.Param("name")}).
+			Asc("age")
+	})
+	sql, args := q.SQL()
+	assert.NotEmpty(t, sql)
+	// the last argument is of the condition of the deleted column
+	assert.Len(t, args, 3)
+	assert.EqualValues(t, []interface{}{xorm.Param("age"), xorm.Param("name")}, args[:2])
+
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			var res []PrepareQueryUser
+			if err := q.Find(context.Background(), map[string]interface{}{"age": 15, "name": "b"}, &res); err != nil {
+				errs <- err
+				return
+			}
+			if len(res) != 1 || res[0].Name != "c" {
+				errs <- fmt.Errorf("unexpected result %v", res)
+				return
+			}
+			errs <- nil
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, <-errs)
+	}
+
+	var res []PrepareQueryUser
+	assert.NoError(t, q.Find(context.Background(), map[string]interface{}{"age": 0, "name": ""}, &res))
+	assert.Len(t, res, 3)
+
+	err = q.Find(context.Background(), map[string]interface{}{"age": 0}, &res)
+	assert.ErrorIs(t, err, xorm.ErrMissingParam)
+
+	q = testEngine.PrepareQuery(func(session *xorm.Session) *xorm.Session {
+		return session.Where("age > ?", xorm.Param("age"))
+	})
+	assert.Error(t, q.Find(context.Background(), map[string]interface{}{"age": 0}, &res))
+}
+
+func TestStrictMode(t *testing.T) {
+	type StrictMode struct {
+		Id    int64
+		Name  string
+		Title string
+	}
+	type StrictModeName struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(StrictMode))
+	_, err := testEngine.Insert(&StrictMode{Name: "a", Title: "b"})
+	assert.NoError(t, err)
+
+	testEngine.SetStrictMode(xorm.StrictError)
+	defer testEngine.SetStrictMode(xorm.StrictOff)
+
+	var beans []StrictMode
+	assert.NoError(t, testEngine.Find(&beans))
+	assert.Len(t, beans, 1)
+
+	// the result set has a column which is not a field
+	var names []StrictModeName
+	err = testEngine.Table(new(StrictMode)).Select("id, name, title").Find(&names)
+	var mappingErr xorm.ErrStrictMapping
+	if assert.ErrorAs(t, err, &mappingErr) {
+		assert.EqualValues(t, []string{"title"}, mappingErr.UnmappedColumns)
+		assert.Empty(t, mappingErr.MissingColumns)
+	}
+
+	// the columns chosen by Cols are not reported as missing
+	var bean StrictMode
+	has, err := testEngine.Cols("id", "name").Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// the columns chosen by a raw SQL are not reported as missing
+	tableName := testEngine.Quote(testEngine.TableName(new(StrictMode), true))
+	has, err = testEngine.SQL("SELECT id, name FROM " + tableName).Get(new(StrictMode))
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// the struct has a field which is not in the result set of SELECT *
+	type StrictModeTag struct {
+		NameId int64
+	}
+	assertSync(t, new(StrictModeName), new(StrictModeTag))
+	_, err = testEngine.Insert(&StrictModeName{Id: 1, Name: "a"}, &StrictModeTag{NameId: 1})
+	assert.NoError(t, err)
+	nameTable := testEngine.Quote(testEngine.TableName(new(StrictModeName), true))
+	tagTable := testEngine.Quote(testEngine.TableName(new(StrictModeTag), true))
+	joined := func() *xorm.Session {
+		return testEngine.Table(new(StrictModeName)).
+			Join("INNER", new(StrictModeTag), tagTable+".name_id = "+nameTable+".id")
+	}
+	has, err = joined().Get(new(StrictMode))
+	assert.True(t, has)
+	if assert.ErrorAs(t, err, &mappingErr) {
+		assert.EqualValues(t, []string{"name_id"}, mappingErr.UnmappedColumns)
+		assert.EqualValues(t, []string{"title"}, mappingErr.MissingColumns)
+	}
+
+	testEngine.SetStrictMode(xorm.StrictLog)
+	has, err = joined().Get(new(StrictMode))
+	assert.NoError(t, err)
+	assert.True(t, has)
+}
@@ -1021,3 +1021,96 @@ func TestGetBytesVars(t *testing.T) {
 	assert.True(t, has)
 	assert.EqualValues(t, gbv.Id, myID)
 }
+
+type GetScalarsLevel int
+
+func TestGetNullableScalars(t *testing.T) {
+	type GetNullableScalars struct {
+		Id      int64
+		Name    *string
+		Level   *int
+		Score   *float64
+		Created *time.Time
+		Data    []byte
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(GetNullableScalars))
+
+	_, err := testEngine.Insert(&GetNullableScalars{})
+	assert.NoError(t, err)
+
+	var (
+		id      int64
+		name    = new(string)
+		level   = GetScalarsLevel(-1)
+		score   sql.NullFloat64
+		created = new(time.Time)
+		data    []byte
+	)
+	has, err := testEngine.Table("get_nullable_scalars").Cols("id", "name", "level", "score", "created", "data").
+		Get(&id, &name, &level, &score, &created, &data)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 1, id)
+	assert.Nil(t, name)
+	assert.EqualValues(t, -1, level)
+	assert.False(t, score.Valid)
+	assert.Nil(t, created)
+	assert.Nil(t, data)
+
+	n, s, l, f := "a", 1.5, 2, time.Now().Truncate(time.Second)
+	_, err = testEngine.Insert(&GetNullableScalars{Name: &n, Level: &l, Score: &s, Created: &f, Data: []byte("b")})
+	assert.NoError(t, err)
+
+	var level8 uint8
+	has, err = testEngine.Table("get_nullable_scalars").Cols("id", "name", "level", "score", "created", "data").
+		Where("id = ?", 2).Get(&id, &name, &level8, &score, &created, &data)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 2, id)
+	if assert.NotNil(t, name) {
+		assert.EqualValues(t, "a", *name)
+	}
+	assert.EqualValues(t, 2, level8)
+	assert.True(t, score.Valid)
+	assert.EqualValues(t, 1.5, score.Float64)
+	if assert.NotNil(t, created) {
+		assert.EqualValues(t, f.Unix(), created.Unix())
+	}
+	assert.EqualValues(t, "b", string(data))
+
+	_, err = testEngine.Table("get_nullable_scalars").Cols("id", "name").Get(&id)
+	assert.ErrorIs(t, err, xorm.ErrScanCountMismatch)
+}
+
+func TestOne(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type GetOne struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, testEngine.Sync(new(GetOne)))
+	_, err := testEngine.Insert([]GetOne{{Name: "a"}, {Name: "b"}, {Name: "b"}})
+	assert.NoError(t, err)
+
+	var one GetOne
+	assert.NoError(t, testEngine.Where("name = ?", "a").One(&one))
+	assert.EqualValues(t, "a", one.Name)
+
+	one = GetOne{}
+	assert.NoError(t, testEngine.Where("name = ?", "a").One(&one, true))
+	assert.EqualValues(t, "a", one.Name)
+
+	err = testEngine.Where("name = ?", "c").One(&GetOne{})
+	assert.ErrorIs(t, err, xorm.ErrNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, testEngine.Where("name = ?", "b").One(&GetOne{}))
+	assert.ErrorIs(t, testEngine.Where("name = ?", "b").One(&GetOne{}, true), xorm.ErrMultipleRows)
+
+	var name string
+	assert.ErrorIs(t, testEngine.SQL("SELECT name FROM "+testEngine.Quote(testEngine.TableName("get_one", true))).One(&name, true), xorm.ErrMultipleRows)
+}
@@ -5,6 +5,8 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -14,6 +16,7 @@ import (
 	"github.com/imkos/xorm/schemas"
 
 	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
 )
 
 func TestInsertOne(t *testing.T) {
@@ -1372,3 +1375,202 @@ func TestAutoTimeFields(t *testing.T) {
 	assert.NoError(t, testEngine.Find(&res))
 	assert.EqualValues(t, 2, len(res))
 }
+
+func TestInsertSelect(t *testing.T) {
+	type InsertSelectUser struct {
+		Id      int64
+		Name    string
+		Status  int
+		Deleted time.Time `xorm:"deleted"`
+	}
+
+	type InsertSelectArchive struct {
+		Id       int64
+		UserId   int64
+		UserName string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(InsertSelectUser), new(InsertSelectArchive))
+
+	_, err := testEngine.Insert([]InsertSelectUser{
+		{Name: "a", Status: 1},
+		{Name: "b", Status: 0},
+		{Name: "c", Status: 0},
+		{Name: "d", Status: 0},
+	})
+	assert.NoError(t, err)
+	_, err = testEngine.Where("name = ?", "d").Delete(new(InsertSelectUser))
+	assert.NoError(t, err)
+
+	cnt, err := testEngine.Table(new(InsertSelectArchive)).Cols("user_id", "user_name").
+		InsertSelect(testEngine.Table(new(InsertSelectUser)).Cols("id", "name").Where("status = ?", 0))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var archives []InsertSelectArchive
+	assert.NoError(t, testEngine.Asc("user_id").Find(&archives))
+	assert.EqualValues(t, 2, len(archives))
+	assert.EqualValues(t, "b", archives[0].UserName)
+	assert.EqualValues(t, "c", archives[1].UserName)
+
+	cnt, err = testEngine.Table(new(InsertSelectArchive)).
+		InsertSelect(builder.Select("`id`", "`name`").From("`insert_select_user`").Where(builder.Eq{"`status`": 1}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	_, err = testEngine.Table(new(InsertSelectArchive)).Cols("user_id", "user_name").
+		InsertSelect(testEngine.Table(new(InsertSelectUser)).Cols("id"))
+	assert.Error(t, err)
+
+	_, err = testEngine.Table(new(InsertSelectArchive)).InsertSelect("SELECT 1")
+	assert.Error(t, err)
+}
+
+func TestInsertMultipleInTx(t *testing.T) {
+	type MultiInsertTx struct {
+		Id   int64
+		Name string `xorm:"unique"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(MultiInsertTx))
+
+	testEngine.SetMultiInsertTx(true)
+	defer testEngine.SetMultiInsertTx(false)
+
+	_, err := testEngine.Insert(&MultiInsertTx{Name: "a"}, &[]MultiInsertTx{{Name: "b"}, {Name: "c"}}, &MultiInsertTx{Name: "a"})
+	assert.Error(t, err)
+	cnt, err := testEngine.Count(new(MultiInsertTx))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	affected, err := testEngine.Insert(&MultiInsertTx{Name: "a"}, &[]MultiInsertTx{{Name: "b"}, {Name: "c"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, affected)
+
+	// an explicit transaction will not be committed by Insert
+	session := testEngine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+	_, err = session.Insert(&MultiInsertTx{Name: "d"}, &MultiInsertTx{Name: "e"})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Rollback())
+
+	testEngine.SetMultiInsertTx(false)
+	_, err = testEngine.Insert(&MultiInsertTx{Name: "f"}, &MultiInsertTx{Name: "a"})
+	assert.Error(t, err)
+	cnt, err = testEngine.Count(new(MultiInsertTx))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, cnt)
+}
+
+type ValidatedUser struct {
+	Id   int64
+	Name string
+	Age  int
+}
+
+func (u *ValidatedUser) Validate(ctx context.Context) error {
+	if u.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ValidatedUser))
+
+	_, err := testEngine.Insert(&ValidatedUser{Age: 10})
+	var validationErr xorm.ErrValidation
+	assert.True(t, errors.As(err, &validationErr))
+	assert.EqualError(t, validationErr.Err, "name is required")
+
+	_, err = testEngine.Insert([]*ValidatedUser{{Name: "a"}, {Age: 10}})
+	assert.True(t, errors.As(err, &validationErr))
+
+	// the pointer receiver Validate is invoked on the elements of []T as well
+	_, err = testEngine.Insert([]ValidatedUser{{Name: "a"}, {Age: 10}})
+	assert.True(t, errors.As(err, &validationErr))
+
+	cnt, err := testEngine.Count(new(ValidatedUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	user := ValidatedUser{Name: "a", Age: 10}
+	_, err = testEngine.Insert(&user)
+	assert.NoError(t, err)
+
+	// the partial updates are not validated
+	_, err = testEngine.ID(user.Id).Update(&ValidatedUser{Age: 11})
+	assert.NoError(t, err)
+
+	_, err = testEngine.ID(user.Id).AllCols().Update(&ValidatedUser{Age: 11})
+	assert.True(t, errors.As(err, &validationErr))
+
+	// maps are not validated
+	_, err = testEngine.Table(new(ValidatedUser)).ID(user.Id).Update(map[string]interface{}{"age": 12})
+	assert.NoError(t, err)
+
+	errTooOld := errors.New("too old")
+	testEngine.SetValidator(func(ctx context.Context, bean interface{}) error {
+		if u, ok := bean.(*ValidatedUser); ok && u.Age > 100 {
+			return errTooOld
+		}
+		return nil
+	})
+	defer testEngine.SetValidator(nil)
+
+	_, err = testEngine.ID(user.Id).AllCols().Update(&ValidatedUser{Name: "b", Age: 101})
+	assert.True(t, errors.Is(err, errTooOld))
+
+	_, err = testEngine.ID(user.Id).AllCols().Update(&ValidatedUser{Name: "b", Age: 99})
+	assert.NoError(t, err)
+}
+
+func TestBulkInsert(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type BulkInsertUser struct {
+		Id      int64
+		Name    string
+		Created time.Time `xorm:"created"`
+	}
+
+	assert.NoError(t, testEngine.Sync(new(BulkInsertUser)))
+
+	users := make([]BulkInsertUser, 0, 25)
+	for i := 0; i < 25; i++ {
+		users = append(users, BulkInsertUser{Name: fmt.Sprintf("user%d", i)})
+	}
+
+	cnt, err := testEngine.BulkInsert(users, xorm.BulkOptions{BatchSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 25, cnt)
+
+	var results []BulkInsertUser
+	assert.NoError(t, testEngine.Asc("id").Find(&results))
+	assert.Len(t, results, 25)
+	assert.EqualValues(t, "user24", results[24].Name)
+	assert.False(t, results[24].Created.IsZero())
+
+	_, err = testEngine.BulkInsert([]BulkInsertUser{}, xorm.BulkOptions{})
+	assert.ErrorIs(t, err, xorm.ErrNoElementsOnSlice)
+
+	// all the records should be rolled back if one batch fails
+	testEngine.SetValidator(func(ctx context.Context, bean interface{}) error {
+		if user, ok := bean.(*BulkInsertUser); ok && user.Name == "invalid" {
+			return errors.New("invalid name")
+		}
+		return nil
+	})
+	defer testEngine.SetValidator(nil)
+
+	_, err = testEngine.BulkInsert([]*BulkInsertUser{{Name: "valid"}, {Name: "valid"}, {Name: "invalid"}}, xorm.BulkOptions{BatchSize: 2})
+	assert.Error(t, err)
+
+	total, err := testEngine.Count(new(BulkInsertUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 25, total)
+}
@@ -5,8 +5,14 @@
 package tests
 
 import (
+	"context"
+	"errors"
+	"math"
+	"sync"
 	"testing"
 
+	"github.com/imkos/xorm"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -133,3 +139,151 @@ func TestBufferIterate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, beans, 2)
 }
+
+func TestChunk(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type UserChunk struct {
+		Id    int64
+		IsMan bool
+	}
+
+	assert.NoError(t, testEngine.Sync(new(UserChunk)))
+
+	for i := 0; i < 20; i++ {
+		cnt, err := testEngine.Insert(&UserChunk{IsMan: true})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, cnt)
+	}
+
+	var sizes []int
+	var ids []int64
+	err := testEngine.Where("`id` <= ?", 17).Desc("id").Chunk(new(UserChunk), 7, func(batch interface{}) error {
+		users := *batch.(*[]UserChunk)
+		sizes = append(sizes, len(users))
+		for _, user := range users {
+			ids = append(ids, user.Id)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{7, 7, 3}, sizes)
+	assert.Len(t, ids, 17)
+	for i, id := range ids {
+		assert.EqualValues(t, i+1, id)
+	}
+
+	// the batches modified in fn should not affect the paging
+	errBreak := errors.New("break")
+	var batches int
+	err = testEngine.Where("`is_man` = ?", true).ChunkInTx(new(UserChunk), 5, func(tx *xorm.Session, batch interface{}) error {
+		batches++
+		if batches == 3 {
+			return errBreak
+		}
+		for _, user := range *batch.(*[]UserChunk) {
+			_, err := tx.ID(user.Id).Cols("is_man").Update(&UserChunk{IsMan: false})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errBreak)
+	assert.EqualValues(t, 3, batches)
+
+	cnt, err := testEngine.Where("`is_man` = ?", false).Count(new(UserChunk))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, cnt)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches = 0
+	err = testEngine.Context(ctx).Chunk(new(UserChunk), 5, func(batch interface{}) error {
+		batches++
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualValues(t, 1, batches)
+
+	type UserChunkNoPK struct {
+		Name string
+	}
+	assert.NoError(t, testEngine.Sync(new(UserChunkNoPK)))
+	err = testEngine.Chunk(new(UserChunkNoPK), 5, func(batch interface{}) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, xorm.ErrChunkNeedSinglePK)
+}
+
+func TestParallelFind(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type UserParallelFind struct {
+		Id    int64
+		Group int
+	}
+
+	assert.NoError(t, testEngine.Sync(new(UserParallelFind)))
+
+	err := testEngine.ParallelFind(context.Background(), new(UserParallelFind), xorm.RangeSpec{Column: "id", Workers: 4}, func(worker int, bean interface{}) error {
+		return errors.New("should not be called on an empty table")
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		_, err := testEngine.Insert(&UserParallelFind{Group: i%2 + 1})
+		assert.NoError(t, err)
+	}
+
+	var lock sync.Mutex
+	ids := make(map[int64]int)
+	workers := make(map[int]bool)
+	err = testEngine.ParallelFind(context.Background(), &UserParallelFind{Group: 1}, xorm.RangeSpec{Column: "id", Workers: 4}, func(worker int, bean interface{}) error {
+		lock.Lock()
+		defer lock.Unlock()
+		ids[bean.(*UserParallelFind).Id]++
+		workers[worker] = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 25)
+	for id, cnt := range ids {
+		assert.EqualValues(t, 1, id%2)
+		assert.EqualValues(t, 1, cnt)
+	}
+	assert.Len(t, workers, 4)
+
+	errBreak := errors.New("break")
+	err = testEngine.ParallelFind(context.Background(), new(UserParallelFind), xorm.RangeSpec{Column: "id", Workers: 4}, func(worker int, bean interface{}) error {
+		return errBreak
+	})
+	assert.ErrorIs(t, err, errBreak)
+
+	// the range between the limits of int64 doesn't overflow
+	_, err = testEngine.Insert([]*UserParallelFind{{Id: math.MinInt64, Group: 3}, {Id: math.MaxInt64, Group: 3}})
+	assert.NoError(t, err)
+	for _, workers := range []int{1, 3} {
+		ids = make(map[int64]int)
+		err = testEngine.ParallelFind(context.Background(), &UserParallelFind{Group: 3}, xorm.RangeSpec{Column: "id", Workers: workers}, func(worker int, bean interface{}) error {
+			lock.Lock()
+			defer lock.Unlock()
+			ids[bean.(*UserParallelFind).Id]++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.EqualValues(t, map[int64]int{math.MinInt64: 1, math.MaxInt64: 1}, ids)
+	}
+
+	// the range is probed with the conditions of the bean, so the records are still split
+	workers = make(map[int]bool)
+	err = testEngine.ParallelFind(context.Background(), &UserParallelFind{Group: 1}, xorm.RangeSpec{Column: "id", Workers: 4}, func(worker int, bean interface{}) error {
+		lock.Lock()
+		defer lock.Unlock()
+		workers[worker] = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, workers, 4)
+}
@@ -5,6 +5,7 @@
 package tests
 
 import (
+	"database/sql/driver"
 	"sort"
 	"testing"
 	"time"
@@ -729,3 +730,59 @@ func TestNoPKIdQueryUpdate(t *testing.T) {
 	assert.Error(t, err)
 	assert.EqualValues(t, 0, cnt)
 }
+
+type IDBeanUserID int64
+
+type IDBeanValuer struct {
+	id int64
+}
+
+func (v IDBeanValuer) Value() (driver.Value, error) {
+	return v.id, nil
+}
+
+func TestIDBean(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type IdBeanUser struct {
+		Id   int64
+		Name string
+	}
+
+	assertSync(t, new(IdBeanUser), new(CompositeKey))
+
+	user := IdBeanUser{Name: "lunny"}
+	_, err := testEngine.Insert(&user)
+	assert.NoError(t, err)
+
+	var u1 IdBeanUser
+	has, err := testEngine.ID(&IdBeanUser{Id: user.Id}).Get(&u1)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "lunny", u1.Name)
+
+	var u2 IdBeanUser
+	has, err = testEngine.ID(IDBeanUserID(user.Id)).Get(&u2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	var u3 IdBeanUser
+	has, err = testEngine.ID(IDBeanValuer{id: user.Id}).Get(&u3)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = testEngine.Insert(&CompositeKey{Id1: 11, Id2: 22, UpdateStr: "composite"})
+	assert.NoError(t, err)
+
+	var ck CompositeKey
+	has, err = testEngine.ID(CompositeKey{Id1: 11, Id2: 22}).Get(&ck)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "composite", ck.UpdateStr)
+
+	type IdBeanNoPK struct {
+		Name string
+	}
+	_, err = testEngine.ID(&IdBeanNoPK{}).Get(&u1)
+	assert.Error(t, err)
+}
@@ -12,6 +12,7 @@ import (
 
 	"xorm.io/builder"
 
+	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/schemas"
 
 	"github.com/stretchr/testify/assert"
@@ -113,6 +114,62 @@ func TestQueryInterface(t *testing.T) {
 	assert.EqualValues(t, 1.5, records[0]["money"])
 }
 
+func TestQueryInterfaceNormalization(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type QueryNormalization struct {
+		Id      int64
+		Amount  string `xorm:"decimal(10,2)"`
+		Data    []byte `xorm:"blob"`
+		Created time.Time
+		Deleted *time.Time
+	}
+
+	assert.NoError(t, testEngine.Sync(new(QueryNormalization)))
+
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, testEngine.GetTZLocation())
+	_, err := testEngine.Insert(&QueryNormalization{
+		Amount:  "12.50",
+		Data:    []byte("xorm"),
+		Created: created,
+	})
+	assert.NoError(t, err)
+
+	defer testEngine.SetValueNormalization(xorm.ValueNormalization{})
+	sqlStr := "select * from " + testEngine.Quote(testEngine.TableName("query_normalization", true))
+
+	testEngine.SetValueNormalization(xorm.ValueNormalization{
+		Decimal: xorm.DecimalAsString,
+		Time:    xorm.TimeAsTime,
+		Blob:    xorm.BlobAsString,
+	})
+	records, err := testEngine.QueryInterface(sqlStr)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	amount, ok := records[0]["amount"].(string)
+	assert.True(t, ok)
+	f, err := strconv.ParseFloat(amount, 64)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 12.5, f)
+	assert.Equal(t, "xorm", records[0]["data"])
+	createdValue, ok := records[0]["created"].(time.Time)
+	assert.True(t, ok)
+	assert.True(t, created.Equal(createdValue))
+	assert.Nil(t, records[0]["deleted"])
+
+	testEngine.SetValueNormalization(xorm.ValueNormalization{
+		Decimal: xorm.DecimalAsFloat,
+		Time:    xorm.TimeAsString,
+		Blob:    xorm.BlobAsBytes,
+	})
+	records, err = testEngine.QueryInterface(sqlStr)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.EqualValues(t, 12.5, records[0]["amount"])
+	assert.EqualValues(t, []byte("xorm"), records[0]["data"])
+	assert.EqualValues(t, "2023-01-02 03:04:05", records[0]["created"])
+}
+
 func TestQueryNoParams(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 
@@ -5,10 +5,12 @@
 package tests
 
 import (
+	"bytes"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/convert"
 
 	"github.com/stretchr/testify/assert"
@@ -112,3 +114,112 @@ func TestExecCustomTypes(t *testing.T) {
 	assert.Equal(t, "user", results[0]["name"])
 	assert.EqualValues(t, "data", results[0]["data"])
 }
+
+func TestExpandSliceArgs(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type UserinfoExpand struct {
+		Uid  int64
+		Name string
+	}
+
+	assert.NoError(t, testEngine.Sync(new(UserinfoExpand)))
+	_, err := testEngine.Insert([]UserinfoExpand{{1, "a"}, {2, "b"}, {3, "c"}})
+	assert.NoError(t, err)
+
+	var users []UserinfoExpand
+	assert.NoError(t, testEngine.SQL("SELECT * FROM "+testEngine.Quote(testEngine.TableName("userinfo_expand", true))+
+		" WHERE `uid` IN (?) AND `name` <> ? ORDER BY `uid`", []int64{1, 3}, "").Find(&users))
+	assert.EqualValues(t, []UserinfoExpand{{1, "a"}, {3, "c"}}, users)
+
+	cnt, err := testEngine.Where("`name` IN (?)", []string{"a", "b"}).Count(new(UserinfoExpand))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	res, err := testEngine.Exec("DELETE FROM "+testEngine.Quote(testEngine.TableName("userinfo_expand", true))+" WHERE `uid` IN (?)", []int64{2, 3})
+	assert.NoError(t, err)
+	affected, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, affected)
+
+	_, err = testEngine.Where("`uid` IN (?)", []int64{}).Count(new(UserinfoExpand))
+	assert.ErrorIs(t, err, xorm.ErrEmptySliceArg)
+}
+
+func TestScanStructs(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ScanUser struct {
+		Id   int64
+		Name string
+	}
+	type ScanOrder struct {
+		Id     int64
+		UserId int64
+		Amount int
+	}
+	type ScanUserOrder struct {
+		ScanUser  `xorm:"extends"`
+		ScanOrder `xorm:"extends"`
+	}
+
+	assertSync(t, new(ScanUser), new(ScanOrder))
+	user := ScanUser{Name: "lunny"}
+	_, err := testEngine.Insert(&user)
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&ScanOrder{UserId: user.Id, Amount: 10}, &ScanOrder{UserId: user.Id, Amount: 20})
+	assert.NoError(t, err)
+
+	var results []ScanUserOrder
+	err = testEngine.SQL(`SELECT o.id AS "scan_order.id", o.amount AS "scan_order.amount", u.id AS "scan_user.id", ` +
+		`u.name AS "ScanUser.name" FROM ` + testEngine.TableName(new(ScanOrder), true) +
+		" o JOIN " + testEngine.TableName(new(ScanUser), true) + " u ON u.id = o.user_id ORDER BY o.id").ScanStructs(&results)
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 2, len(results)) {
+		assert.EqualValues(t, user.Id, results[0].ScanUser.Id)
+		assert.EqualValues(t, "lunny", results[0].Name)
+		assert.EqualValues(t, 2, results[1].ScanOrder.Id)
+		assert.EqualValues(t, 20, results[1].Amount)
+	}
+
+	orders, err := xorm.QueryStructs[ScanOrder](testEngine.SQL("SELECT * FROM "+
+		testEngine.TableName(new(ScanOrder), true)+" WHERE amount > ?", 10))
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 1, len(orders)) {
+		assert.EqualValues(t, 20, orders[0].Amount)
+	}
+
+	assert.ErrorIs(t, testEngine.NewSession().ScanStructs(&results), xorm.ErrNeedRawSQL)
+}
+
+func TestWriteJSON(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type JsonExport struct {
+		Id   int64
+		Name string
+		Age  int
+	}
+
+	assert.NoError(t, testEngine.Sync(new(JsonExport)))
+	_, err := testEngine.Insert([]JsonExport{
+		{Name: "lunny", Age: 18},
+		{Name: "<xlw>", Age: 20},
+	})
+	assert.NoError(t, err)
+
+	tableName := testEngine.Quote(testEngine.TableName("json_export", true))
+	sqlStr := "SELECT id, name, age FROM " + tableName + " ORDER BY id"
+
+	var buf bytes.Buffer
+	assert.NoError(t, testEngine.SQL(sqlStr).WriteJSON(&buf, xorm.JSONOptions{}))
+	assert.EqualValues(t, `[{"id":1,"name":"lunny","age":18},{"id":2,"name":"<xlw>","age":20}]`+"\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, testEngine.SQL(sqlStr).WriteJSON(&buf, xorm.JSONOptions{NDJSON: true}))
+	assert.EqualValues(t, `{"id":1,"name":"lunny","age":18}`+"\n"+`{"id":2,"name":"<xlw>","age":20}`+"\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, testEngine.Table(new(JsonExport)).Where("id > ?", 2).WriteJSON(&buf, xorm.JSONOptions{}))
+	assert.EqualValues(t, "[]\n", buf.String())
+}
@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/imkos/xorm"
+	"github.com/imkos/xorm/convert"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -151,3 +154,140 @@ func TestSumCustomColumn(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, 3, int(sumInt))
 }
+
+func TestSumDecimal(t *testing.T) {
+	type SumDecimalStruct struct {
+		Id     int64
+		Amount convert.Decimal
+		Fee    *convert.Decimal `xorm:"decimal(20,4)"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SumDecimalStruct))
+
+	var amounts []string
+	var beans []SumDecimalStruct
+	for _, s := range []string{"0.1", "0.2", "1234.56"} {
+		d, err := convert.ParseDecimal(s)
+		assert.NoError(t, err)
+		amounts = append(amounts, s)
+		beans = append(beans, SumDecimalStruct{Amount: d, Fee: &d})
+	}
+	cnt, err := testEngine.Insert(beans)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	var res []SumDecimalStruct
+	assert.NoError(t, testEngine.Asc("id").Find(&res))
+	assert.EqualValues(t, 3, len(res))
+	for i, bean := range res {
+		expected, _ := convert.ParseDecimal(amounts[i])
+		assert.EqualValues(t, 0, expected.Cmp(bean.Amount))
+		assert.NotNil(t, bean.Fee)
+		assert.EqualValues(t, 0, expected.Cmp(*bean.Fee))
+	}
+
+	expected, _ := convert.ParseDecimal("1234.86")
+	sum, err := testEngine.SumDecimal(new(SumDecimalStruct), "amount")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, expected.Cmp(sum), sum.String())
+
+	sums, err := testEngine.SumsDecimal(new(SumDecimalStruct), "amount", "fee")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(sums))
+	assert.EqualValues(t, 0, expected.Cmp(sums[0]), sums[0].String())
+	assert.EqualValues(t, 0, expected.Cmp(sums[1]), sums[1].String())
+}
+
+func TestMinMaxAvg(t *testing.T) {
+	type MinMaxAvgStruct struct {
+		Id      int64
+		Int     int
+		Float   float64
+		Created time.Time
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(MinMaxAvgStruct))
+
+	min, max := -1, -1
+	assert.NoError(t, testEngine.Min(new(MinMaxAvgStruct), "int", &min))
+	assert.EqualValues(t, -1, min)
+	var emptyCreated time.Time
+	assert.NoError(t, testEngine.Max(new(MinMaxAvgStruct), "created", &emptyCreated))
+	assert.True(t, emptyCreated.IsZero())
+	avg, err := testEngine.Avg(new(MinMaxAvgStruct), "int")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, avg)
+
+	now := time.Now().Truncate(time.Second)
+	cases := []MinMaxAvgStruct{
+		{Int: 1, Float: 1.5, Created: now.Add(-time.Hour)},
+		{Int: 5, Float: 2.5, Created: now},
+		{Int: 3, Float: -1, Created: now.Add(time.Hour)},
+	}
+	_, err = testEngine.Insert(cases)
+	assert.NoError(t, err)
+
+	assert.NoError(t, testEngine.Min(new(MinMaxAvgStruct), "int", &min))
+	assert.EqualValues(t, 1, min)
+	assert.NoError(t, testEngine.Max(new(MinMaxAvgStruct), "int", &max))
+	assert.EqualValues(t, 5, max)
+	assert.NoError(t, testEngine.Where("`int` < ?", 5).Max(new(MinMaxAvgStruct), "int", &max))
+	assert.EqualValues(t, 3, max)
+
+	var maxFloat float64
+	assert.NoError(t, testEngine.Max(new(MinMaxAvgStruct), "float", &maxFloat))
+	assert.EqualValues(t, 2.5, maxFloat)
+
+	var minCreated time.Time
+	assert.NoError(t, testEngine.Min(new(MinMaxAvgStruct), "created", &minCreated))
+	assert.EqualValues(t, now.Add(-time.Hour).Unix(), minCreated.Unix())
+
+	avg, err = testEngine.Avg(new(MinMaxAvgStruct), "int")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, avg)
+}
+
+func TestAggregate(t *testing.T) {
+	type AggregateOrder struct {
+		Id     int64
+		UserId int64
+		Amount int
+	}
+
+	type AggregateResult struct {
+		UserId int64
+		Total  int
+		Cnt    int
+		MaxAmt int
+		AvgAmt float64
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(AggregateOrder))
+
+	_, err := testEngine.Insert([]AggregateOrder{
+		{UserId: 1, Amount: 10},
+		{UserId: 1, Amount: 20},
+		{UserId: 2, Amount: 5},
+	})
+	assert.NoError(t, err)
+
+	var results []AggregateResult
+	err = testEngine.Table(new(AggregateOrder)).GroupBy("user_id").Asc("user_id").
+		Aggregate(&results, []xorm.Agg{
+			{Func: xorm.AggSum, Column: "amount", As: "total"},
+			{Func: xorm.AggCount, As: "cnt"},
+			{Func: xorm.AggMax, Column: "amount", As: "max_amt"},
+			{Func: xorm.AggAvg, Column: "amount", As: "avg_amt"},
+		})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []AggregateResult{
+		{UserId: 1, Total: 30, Cnt: 2, MaxAmt: 20, AvgAmt: 15},
+		{UserId: 2, Total: 5, Cnt: 1, MaxAmt: 5, AvgAmt: 5},
+	}, results)
+
+	err = testEngine.Table(new(AggregateOrder)).Aggregate(&results, []xorm.Agg{{Func: xorm.AggSum}})
+	assert.Error(t, err)
+}
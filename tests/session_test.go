@@ -5,9 +5,13 @@
 package tests
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/imkos/xorm"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,3 +90,270 @@ func TestIndexHint(t *testing.T) {
 		Get(new(Userinfo))
 	assert.NoError(t, err)
 }
+
+func TestSessionStats(t *testing.T) {
+	type SessionStatsUser struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SessionStatsUser))
+
+	session := testEngine.NewSession()
+	defer session.Close()
+
+	stats := session.Stats()
+	assert.EqualValues(t, 0, stats.Queries)
+
+	_, err := session.Insert([]SessionStatsUser{{Name: "a"}, {Name: "b"}})
+	assert.NoError(t, err)
+	_, err = session.Where("name = ?", "a").Update(&SessionStatsUser{Name: "c"})
+	assert.NoError(t, err)
+
+	sql, args := session.LastSQL()
+	assert.Contains(t, sql, "UPDATE")
+	assert.EqualValues(t, []interface{}{"c", "a"}, args)
+
+	var users []SessionStatsUser
+	assert.NoError(t, session.Find(&users))
+	assert.EqualValues(t, 2, len(users))
+
+	stats = session.Stats()
+	assert.EqualValues(t, 3, stats.Queries)
+	assert.EqualValues(t, 3, stats.RowsAffected)
+	assert.True(t, stats.Duration > 0)
+
+	// the duration of a query includes fetching its rows
+	assert.NoError(t, session.Iterate(new(SessionStatsUser), func(i int, bean interface{}) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}))
+	duration := session.Stats().Duration - stats.Duration
+	assert.EqualValues(t, 4, session.Stats().Queries)
+	assert.True(t, duration >= 40*time.Millisecond, duration)
+}
+
+func TestSessionClone(t *testing.T) {
+	type SessionCloneUser struct {
+		Id   int64
+		Name string
+		Age  int
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SessionCloneUser))
+
+	_, err := testEngine.Insert([]SessionCloneUser{{Name: "a", Age: 10}, {Name: "b", Age: 20}, {Name: "c", Age: 30}})
+	assert.NoError(t, err)
+
+	base := testEngine.NewSession()
+	defer base.Close()
+	base.Where("age > ?", 15)
+
+	list := base.Clone()
+	defer list.Close()
+
+	cnt, err := base.Count(new(SessionCloneUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var users []SessionCloneUser
+	assert.NoError(t, list.Clone().Desc("age").Limit(1).Find(&users))
+	assert.Len(t, users, 1)
+	assert.EqualValues(t, "c", users[0].Name)
+
+	// the conditions of the clone are kept after the query of the other clone
+	users = nil
+	assert.NoError(t, list.And("name = ?", "b").Find(&users))
+	assert.Len(t, users, 1)
+	assert.EqualValues(t, "b", users[0].Name)
+
+	// the clone shares the transaction
+	assert.NoError(t, base.Begin())
+	tx := base.Clone()
+	_, err = tx.Insert(&SessionCloneUser{Name: "d", Age: 40})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Close())
+	assert.NoError(t, base.Rollback())
+
+	cnt, err = testEngine.Count(new(SessionCloneUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+}
+
+func TestNewSessionContext(t *testing.T) {
+	type SessionContextUser struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SessionContextUser))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := testEngine.NewSessionContext(ctx)
+	assert.NoError(t, session.Begin())
+	_, err := session.Insert(&SessionContextUser{Name: "a"})
+	assert.NoError(t, err)
+	cancel()
+
+	// the expired session is closed by its next call
+	assert.False(t, session.IsClosed())
+	_, err = session.Insert(&SessionContextUser{Name: "a"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, session.IsClosed())
+	cnt, err := testEngine.Count(new(SessionContextUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	// closing a session before its context is done
+	session = testEngine.NewSessionContext(context.Background())
+	_, err = session.Insert(&SessionContextUser{Name: "b"})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Close())
+	assert.True(t, session.IsClosed())
+
+	testEngine.SetMaxSessionLifetime(50 * time.Millisecond)
+	defer testEngine.SetMaxSessionLifetime(0)
+	session = testEngine.NewSessionContext(context.Background())
+	_, err = session.Insert(&SessionContextUser{Name: "c"})
+	assert.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = session.Insert(&SessionContextUser{Name: "d"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, session.IsClosed())
+}
+
+func TestSessionGuard(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type SessionGuardItem struct {
+		Id   int64
+		Name string
+	}
+	assertSync(t, new(SessionGuardItem))
+	_, err := testEngine.Insert(&SessionGuardItem{Name: "a"}, &SessionGuardItem{Name: "b"})
+	assert.NoError(t, err)
+
+	engine, ok := testEngine.(*xorm.Engine)
+	if !ok {
+		t.Skip("session guard is only tested on the engine")
+	}
+	engine.EnableSessionGuard(xorm.SessionGuardError)
+	defer engine.EnableSessionGuard(xorm.SessionGuardOff)
+
+	session := engine.NewSession()
+	defer session.Close()
+
+	var concurrentErr error
+	err = session.Iterate(new(SessionGuardItem), func(i int, bean interface{}) error {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, concurrentErr = session.Count(new(SessionGuardItem))
+		}()
+		<-done
+		return nil
+	})
+	assert.NoError(t, err)
+	var guardErr xorm.ErrConcurrentSessionUse
+	assert.True(t, errors.As(concurrentErr, &guardErr))
+
+	// the session could be used by another goroutine after it's returned
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cnt, err := session.Count(new(SessionGuardItem))
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, cnt)
+	}()
+	<-done
+
+	// the statement could be built by a goroutine and executed by another one after the handoff
+	session.Where("name = ?", "a")
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		cnt, err := session.Count(new(SessionGuardItem))
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, cnt)
+	}()
+	<-done
+
+	// the statement methods called while another goroutine is executing are reported by the
+	// next executing method, and the statement built concurrently is dropped
+	err = session.Iterate(new(SessionGuardItem), func(i int, bean interface{}) error {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			session.Where("name = ?", "a")
+		}()
+		<-done
+		return nil
+	})
+	assert.NoError(t, err)
+	_, err = session.Count(new(SessionGuardItem))
+	assert.True(t, errors.As(err, &guardErr))
+	cnt, err := session.Count(new(SessionGuardItem))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	engine.EnableSessionGuard(xorm.SessionGuardPanic)
+	var recovered interface{}
+	_ = session.Iterate(new(SessionGuardItem), func(i int, bean interface{}) error {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() { recovered = recover() }()
+			session.Where("name = ?", "a")
+		}()
+		<-done
+		return nil
+	})
+	assert.IsType(t, xorm.ErrConcurrentSessionUse{}, recovered)
+	cnt, err = session.Where("name = ?", "a").Count(new(SessionGuardItem))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.Panics(t, func() {
+		_ = session.Iterate(new(SessionGuardItem), func(i int, bean interface{}) error {
+			var recovered interface{}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				defer func() { recovered = recover() }()
+				_, _ = session.Count(new(SessionGuardItem))
+			}()
+			<-done
+			panic(recovered)
+		})
+	})
+}
+
+func TestEngineWithContext(t *testing.T) {
+	type WithContextUser struct {
+		Id   int64
+		Name string
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(WithContextUser))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	engine := testEngine.WithContext(ctx)
+	_, err := engine.Insert(&WithContextUser{Name: "a"})
+	assert.NoError(t, err)
+	cnt, err := engine.Where("name = ?", "a").Count(new(WithContextUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cancel()
+	_, err = engine.Insert(&WithContextUser{Name: "b"})
+	assert.ErrorIs(t, err, context.Canceled)
+	var users []WithContextUser
+	assert.ErrorIs(t, engine.Find(&users), context.Canceled)
+
+	cnt, err = testEngine.Count(new(WithContextUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/imkos/xorm/internal/utils"
 	"github.com/imkos/xorm/names"
+	"github.com/imkos/xorm/schemas"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -217,3 +218,49 @@ func TestInsertMulti2InterfaceTransaction(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestUseSingleConn(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.MSSQL, schemas.ORACLE, schemas.DAMENG:
+		t.Skip("the temporary tables of the database are not created by CREATE TEMPORARY TABLE")
+	}
+
+	session := testEngine.NewSession()
+	defer session.Close()
+
+	assert.NoError(t, session.UseSingleConn())
+	assert.NotNil(t, session.Conn())
+
+	_, err := session.Exec("CREATE TEMPORARY TABLE `single_conn_tmp` (`id` INTEGER)")
+	assert.NoError(t, err)
+
+	assert.NoError(t, session.Begin())
+	_, err = session.Exec("INSERT INTO `single_conn_tmp` (`id`) VALUES (1), (2)")
+	assert.NoError(t, err)
+	assert.NoError(t, session.Commit())
+
+	session.Prepare()
+	var ids []int64
+	assert.NoError(t, session.Table("single_conn_tmp").Cols("id").Find(&ids))
+	assert.EqualValues(t, []int64{1, 2}, ids)
+
+	cnt, err := session.Table("single_conn_tmp").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	// the clone keeps the connection after the session is closed
+	clone := session.Clone()
+	defer clone.Close()
+	assert.True(t, clone.Conn() == session.Conn())
+
+	assert.NoError(t, session.Close())
+	assert.Nil(t, session.Conn())
+
+	cnt, err = clone.Table("single_conn_tmp").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	assert.NoError(t, clone.Close())
+	assert.Nil(t, clone.Conn())
+}
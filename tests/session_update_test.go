@@ -1505,3 +1505,54 @@ func TestUpdateWithJoin(t *testing.T) {
 		Update(&TestUpdateWithJoin{Name: "test2"})
 	assert.NoError(t, err)
 }
+
+func TestUpdateJSONSet(t *testing.T) {
+	type JSONSetTags struct {
+		Name string
+		Tags []string
+	}
+
+	type TestUpdateJSONSet struct {
+		Id   int64
+		Name string
+		Doc  JSONSetTags `xorm:"json"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TestUpdateJSONSet))
+
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.POSTGRES, schemas.MYSQL, schemas.SQLITE, schemas.MSSQL:
+	default:
+		t.Skip()
+		return
+	}
+
+	bean := TestUpdateJSONSet{
+		Name: "json",
+		Doc: JSONSetTags{
+			Name: "doc",
+			Tags: []string{"a", "b"},
+		},
+	}
+	_, err := testEngine.Insert(&bean)
+	assert.NoError(t, err)
+
+	cnt, err := testEngine.ID(bean.Id).
+		JSONSet("doc", "Name", "doc2").
+		JSONSet("doc", "Tags.1", "c").
+		Update(new(TestUpdateJSONSet))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var res TestUpdateJSONSet
+	has, err := testEngine.ID(bean.Id).Get(&res)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "json", res.Name)
+	assert.EqualValues(t, "doc2", res.Doc.Name)
+	assert.EqualValues(t, []string{"a", "c"}, res.Doc.Tags)
+
+	_, err = testEngine.ID(bean.Id).JSONSet("doc", "", "x").Update(new(TestUpdateJSONSet))
+	assert.Error(t, err)
+}
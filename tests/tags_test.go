@@ -1454,3 +1454,164 @@ func TestTableIndices(t *testing.T) {
 		assert.EqualValues(t, []string{"f_two", "f_one"}, index.Cols)
 	}
 }
+
+func TestTagInvisible(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type TagInvisible struct {
+		Id     int64
+		Name   string
+		Secret string `xorm:"invisible"`
+	}
+
+	assertSync(t, new(TagInvisible))
+
+	tb, err := testEngine.TableInfo(new(TagInvisible))
+	assert.NoError(t, err)
+	assert.True(t, tb.GetColumn("secret").IsInvisible)
+
+	_, err = testEngine.Insert(&TagInvisible{Name: "lunny", Secret: "xorm"})
+	assert.NoError(t, err)
+
+	var beans []TagInvisible
+	assert.NoError(t, testEngine.Find(&beans))
+	assert.EqualValues(t, 1, len(beans))
+	assert.EqualValues(t, "lunny", beans[0].Name)
+	assert.EqualValues(t, "", beans[0].Secret)
+
+	var bean TagInvisible
+	has, err := testEngine.Cols("id", "secret").Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "xorm", bean.Secret)
+}
+
+func TestPolymorphic(t *testing.T) {
+	type PolyPost struct {
+		Id    int64
+		Title string
+	}
+	type PolyPhoto struct {
+		Id  int64
+		Url string
+	}
+	type PolyComment struct {
+		Id      int64
+		Content string
+		Owner   schemas.Polymorphic `xorm:"polymorphic(owner)"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(PolyPost), new(PolyPhoto), new(PolyComment))
+
+	table, err := testEngine.TableInfo(new(PolyComment))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"id", "content", "owner_type", "owner_id"}, table.ColumnsSeq())
+	assert.EqualValues(t, []string{"owner_type", "owner_id"}, table.Indexes["owner"].Cols)
+
+	post := PolyPost{Title: "post"}
+	photo := PolyPhoto{Url: "photo"}
+	_, err = testEngine.Insert(&post, &photo)
+	assert.NoError(t, err)
+
+	postRef, err := testEngine.Polymorphic(&post)
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.Polymorphic{Type: "poly_post", ID: post.Id}, postRef)
+	photoRef, err := testEngine.Polymorphic(&photo)
+	assert.NoError(t, err)
+
+	_, err = testEngine.Insert([]PolyComment{
+		{Content: "a", Owner: postRef},
+		{Content: "b", Owner: photoRef},
+		{Content: "c", Owner: postRef},
+	})
+	assert.NoError(t, err)
+
+	var comments []PolyComment
+	assert.NoError(t, testEngine.OwnedBy(&post).Asc("id").Find(&comments))
+	if assert.Len(t, comments, 2) {
+		assert.EqualValues(t, "a", comments[0].Content)
+		assert.EqualValues(t, postRef, comments[0].Owner)
+		assert.EqualValues(t, "c", comments[1].Content)
+	}
+
+	cnt, err := testEngine.OwnedBy(&photo, "owner").Count(new(PolyComment))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.OwnedBy(&post).Delete(new(PolyComment))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	_, err = testEngine.OwnedBy(&post).Count(new(PolyPost))
+	assert.Error(t, err)
+}
+
+type StiCar struct {
+	Id    int64
+	Kind  string `xorm:"varchar(20) discriminator(car)"`
+	Name  string
+	Doors int
+}
+
+func (StiCar) TableName() string {
+	return "sti_vehicle"
+}
+
+type StiTruck struct {
+	Id      int64
+	Type    string `xorm:"varchar(20) discriminator(kind, truck)"`
+	Name    string
+	Payload int
+}
+
+func (StiTruck) TableName() string {
+	return "sti_vehicle"
+}
+
+func TestSingleTableInheritance(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(StiCar))
+	assert.NoError(t, testEngine.Sync(new(StiTruck)))
+
+	table, err := testEngine.TableInfo(new(StiTruck))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "kind", table.Discriminator)
+	assert.EqualValues(t, "truck", table.DiscriminatorColumn().Discriminator)
+
+	car := StiCar{Name: "a", Doors: 4}
+	_, err = testEngine.Insert(&car)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "car", car.Kind)
+
+	trucks := []StiTruck{{Name: "b", Payload: 10}, {Name: "c", Payload: 20}}
+	_, err = testEngine.Insert(&trucks)
+	assert.NoError(t, err)
+
+	var cars []StiCar
+	assert.NoError(t, testEngine.Find(&cars))
+	if assert.Len(t, cars, 1) {
+		assert.EqualValues(t, "a", cars[0].Name)
+	}
+
+	cnt, err := testEngine.Count(new(StiTruck))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var truck StiTruck
+	has, err := testEngine.ID(car.Id).Get(&truck)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	cnt, err = testEngine.Cols("name").Update(&StiCar{Name: "d"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Where("payload > ?", 0).Unscoped().Delete(new(StiTruck))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Table("sti_vehicle").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
@@ -678,3 +678,128 @@ func TestString2Time(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, timeTmp1.In(time.UTC).Equal(*dt))
 }
+
+func TestSetClock(t *testing.T) {
+	type ClockUser struct {
+		Id      int64
+		Name    string
+		Created time.Time `xorm:"created"`
+		Updated time.Time `xorm:"updated"`
+		Deleted time.Time `xorm:"deleted"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ClockUser))
+
+	now := time.Date(2023, 5, 6, 7, 8, 9, 0, testEngine.GetTZLocation())
+	testEngine.SetClock(func() time.Time {
+		return now
+	})
+	defer testEngine.SetClock(nil)
+
+	user := ClockUser{Name: "clock"}
+	_, err := testEngine.Insert(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, now.Unix(), user.Created.Unix())
+	assert.EqualValues(t, now.Unix(), user.Updated.Unix())
+
+	now = now.Add(time.Hour)
+	user.Name = "clock2"
+	_, err = testEngine.ID(user.Id).Update(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, now.Unix(), user.Updated.Unix())
+
+	now = now.Add(time.Hour)
+	_, err = testEngine.ID(user.Id).Delete(&user)
+	assert.NoError(t, err)
+
+	var deleted ClockUser
+	has, err := testEngine.ID(user.Id).Unscoped().Get(&deleted)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, now.Add(-2*time.Hour).Unix(), deleted.Created.Unix())
+	assert.EqualValues(t, now.Add(-time.Hour).Unix(), deleted.Updated.Unix())
+	assert.EqualValues(t, now.Unix(), deleted.Deleted.Unix())
+}
+
+func TestWithTime(t *testing.T) {
+	type WithTimeUser struct {
+		Id      int64
+		Name    string
+		Created time.Time `xorm:"created"`
+		Updated time.Time `xorm:"updated"`
+		Deleted time.Time `xorm:"deleted"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(WithTimeUser))
+
+	backfill := time.Date(2020, 1, 2, 3, 4, 5, 0, testEngine.GetTZLocation())
+	user := WithTimeUser{Name: "backfill"}
+	_, err := testEngine.WithTime(backfill).Insert(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, backfill.Unix(), user.Created.Unix())
+	assert.EqualValues(t, backfill.Unix(), user.Updated.Unix())
+
+	session := testEngine.NewSession()
+	defer session.Close()
+
+	// the time is only used by the next operation
+	updated := backfill.Add(time.Hour)
+	user.Name = "backfill2"
+	_, err = session.WithTime(updated).ID(user.Id).Update(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, updated.Unix(), user.Updated.Unix())
+
+	user.Name = "now"
+	_, err = session.ID(user.Id).Update(&user)
+	assert.NoError(t, err)
+	assert.True(t, user.Updated.After(updated.Add(time.Hour)))
+
+	deleted := backfill.Add(2 * time.Hour)
+	_, err = session.WithTime(deleted).ID(user.Id).Delete(&user)
+	assert.NoError(t, err)
+
+	var result WithTimeUser
+	has, err := session.ID(user.Id).Unscoped().Get(&result)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, backfill.Unix(), result.Created.Unix())
+	assert.EqualValues(t, deleted.Unix(), result.Deleted.Unix())
+}
+
+func TestEpochUnit(t *testing.T) {
+	type EpochUnitUser struct {
+		Id      int64
+		Name    string
+		Created int64 `xorm:"created(ms)"`
+		Updated int64 `xorm:"updated(us)"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(EpochUnitUser))
+
+	now := time.Date(2023, 5, 6, 7, 8, 9, 123456000, time.UTC)
+	user := EpochUnitUser{Name: "epoch"}
+	_, err := testEngine.WithTime(now).Insert(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, now.UnixNano()/int64(time.Millisecond), user.Created)
+	assert.EqualValues(t, now.UnixNano()/int64(time.Microsecond), user.Updated)
+
+	var result EpochUnitUser
+	has, err := testEngine.ID(user.Id).Get(&result)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, user.Created, result.Created)
+	assert.EqualValues(t, user.Updated, result.Updated)
+
+	updated := now.Add(time.Second)
+	_, err = testEngine.WithTime(updated).ID(user.Id).Cols("name").Update(&EpochUnitUser{Name: "epoch2"})
+	assert.NoError(t, err)
+	var result2 EpochUnitUser
+	has, err = testEngine.ID(user.Id).Get(&result2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, user.Created, result2.Created)
+	assert.EqualValues(t, updated.UnixNano()/int64(time.Microsecond), result2.Updated)
+}
@@ -9,11 +9,14 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"net/netip"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/imkos/xorm"
 	"github.com/imkos/xorm/convert"
+	"github.com/imkos/xorm/geo"
 	"github.com/imkos/xorm/internal/json"
 	"github.com/imkos/xorm/schemas"
 
@@ -711,3 +714,201 @@ func TestZDecimal(t *testing.T) {
 	assert.True(t, m3.Amount.IsZero())
 	assert.Equal(t, "0", m3.Amount.String())
 }
+
+func TestGeometry(t *testing.T) {
+	type GeometryStruct struct {
+		Id    int64
+		Name  string
+		Loc   geo.Point `xorm:"spatial"`
+		Area  geo.Polygon
+		Route *geo.LineString
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(GeometryStruct))
+
+	table, err := testEngine.TableInfo(new(GeometryStruct))
+	assert.NoError(t, err)
+	assert.True(t, table.GetColumn("loc").SQLType.IsSpatial())
+	assert.EqualValues(t, schemas.SpatialType, table.Indexes["loc"].Type)
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		// SQLite has no spatial index, a regular index is created and it should be synced without changes
+		assert.NoError(t, testEngine.Sync(new(GeometryStruct)))
+	}
+
+	area := geo.Polygon{Rings: [][]geo.Coord{{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 0}}}}
+	g := GeometryStruct{
+		Name: "a",
+		Loc:  geo.Point{X: 1.5, Y: 2.5},
+		Area: area,
+	}
+	_, err = testEngine.Insert(&g)
+	assert.NoError(t, err)
+
+	var g2 GeometryStruct
+	has, err := testEngine.ID(g.Id).Get(&g2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, g.Loc, g2.Loc)
+	assert.EqualValues(t, area, g2.Area)
+	assert.Nil(t, g2.Route)
+
+	route := &geo.LineString{Coords: []geo.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}}}
+	_, err = testEngine.ID(g.Id).Update(&GeometryStruct{Loc: geo.Point{X: 3, Y: 4}, Route: route})
+	assert.NoError(t, err)
+
+	var gs []GeometryStruct
+	assert.NoError(t, testEngine.Find(&gs))
+	assert.Len(t, gs, 1)
+	assert.EqualValues(t, geo.Point{X: 3, Y: 4}, gs[0].Loc)
+	assert.EqualValues(t, route, gs[0].Route)
+	assert.EqualValues(t, area, gs[0].Area)
+}
+
+func TestNetipFields(t *testing.T) {
+	type NetipStruct struct {
+		Id      int64
+		Addr    netip.Addr
+		Network netip.Prefix
+		Gateway *netip.Addr
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(NetipStruct))
+
+	table, err := testEngine.TableInfo(new(NetipStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.Inet, table.GetColumn("addr").SQLType.Name)
+	assert.EqualValues(t, schemas.Cidr, table.GetColumn("network").SQLType.Name)
+
+	gateway := netip.MustParseAddr("10.1.0.1")
+	_, err = testEngine.Insert([]NetipStruct{
+		{Addr: netip.MustParseAddr("10.1.2.3"), Network: netip.MustParsePrefix("10.1.0.0/16"), Gateway: &gateway},
+		{Addr: netip.MustParseAddr("2001:db8::1"), Network: netip.MustParsePrefix("2001:db8::/32")},
+	})
+	assert.NoError(t, err)
+
+	var n NetipStruct
+	has, err := testEngine.Where("network = ?", "10.1.0.0/16").Get(&n)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, netip.MustParseAddr("10.1.2.3"), n.Addr)
+	assert.EqualValues(t, netip.MustParsePrefix("10.1.0.0/16"), n.Network)
+	assert.EqualValues(t, &gateway, n.Gateway)
+
+	var ns []NetipStruct
+	assert.NoError(t, testEngine.WhereContainsIP("network", netip.MustParseAddr("2001:db8:1::5")).Find(&ns))
+	assert.Len(t, ns, 1)
+	assert.EqualValues(t, netip.MustParseAddr("2001:db8::1"), ns[0].Addr)
+	assert.Nil(t, ns[0].Gateway)
+
+	ns = nil
+	assert.NoError(t, testEngine.WhereContainsIP("network", netip.MustParseAddr("192.168.0.1")).Find(&ns))
+	assert.Len(t, ns, 0)
+
+	has, err = testEngine.Get(&NetipStruct{Addr: netip.MustParseAddr("10.1.2.3")})
+	assert.NoError(t, err)
+	assert.True(t, has)
+}
+
+func TestDurationTag(t *testing.T) {
+	type DurationStruct struct {
+		Id      int64
+		Elapsed time.Duration  `xorm:"duration"`
+		Timeout time.Duration  `xorm:"duration(ms)"`
+		Wait    *time.Duration `xorm:"duration(s)"`
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(DurationStruct))
+
+	wait := 90 * time.Second
+	d := DurationStruct{
+		Elapsed: 26*time.Hour + 3*time.Minute + 4*time.Second + 500*time.Millisecond,
+		Timeout: 1500 * time.Millisecond,
+		Wait:    &wait,
+	}
+	_, err := testEngine.Insert(&d)
+	assert.NoError(t, err)
+
+	var d2 DurationStruct
+	has, err := testEngine.ID(d.Id).Get(&d2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, d, d2)
+
+	var timeout, waitSeconds int64
+	has, err = testEngine.Table(new(DurationStruct)).ID(d.Id).Cols("timeout", "wait").Get(&timeout, &waitSeconds)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 1500, timeout)
+	assert.EqualValues(t, 90, waitSeconds)
+
+	_, err = testEngine.ID(d.Id).Update(&DurationStruct{Timeout: 2 * time.Second})
+	assert.NoError(t, err)
+	has, err = testEngine.Get(&DurationStruct{Timeout: 2 * time.Second})
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	type BadDurationStruct struct {
+		Id      int64
+		Elapsed string `xorm:"duration"`
+	}
+	_, err = testEngine.TableInfo(new(BadDurationStruct))
+	assert.Error(t, err)
+}
+
+func TestBitSet(t *testing.T) {
+	type BitSetStruct struct {
+		Id    int64
+		Flags convert.BitSet `xorm:"bitset(10)"`
+		Mask  []byte         `xorm:"bitset(12)"`
+		Perms *convert.BitSet
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(BitSetStruct))
+
+	table, err := testEngine.TableInfo(new(BitSetStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.BitSet, table.GetColumn("flags").SQLType.Name)
+	assert.EqualValues(t, 10, table.GetColumn("flags").Length)
+	assert.EqualValues(t, 64, table.GetColumn("perms").Length)
+
+	flags, err := convert.ParseBitSet("1010000001")
+	assert.NoError(t, err)
+	perms := convert.BitSetFromUint64(6, 64)
+	_, err = testEngine.Insert([]BitSetStruct{
+		{Flags: flags, Mask: []byte{0xff, 0xf0}, Perms: &perms},
+		{Flags: convert.BitSetFromUint64(2, 10), Mask: []byte{0x00, 0x10}},
+	})
+	assert.NoError(t, err)
+
+	var b BitSetStruct
+	has, err := testEngine.ID(1).Get(&b)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, flags, b.Flags)
+	assert.EqualValues(t, []byte{0xff, 0xf0}, b.Mask)
+	assert.EqualValues(t, &perms, b.Perms)
+
+	var bs []BitSetStruct
+	assert.NoError(t, testEngine.WhereBitsAll("flags", convert.BitSetFromUint64(1, 10)).Find(&bs))
+	assert.Len(t, bs, 1)
+	assert.EqualValues(t, 1, bs[0].Id)
+
+	bs = nil
+	assert.NoError(t, testEngine.WhereBitsAny("flags", convert.BitSetFromUint64(3, 10)).Asc("id").Find(&bs))
+	assert.Len(t, bs, 2)
+	assert.Nil(t, bs[1].Perms)
+
+	has, err = testEngine.Get(&BitSetStruct{Flags: convert.BitSetFromUint64(2, 10)})
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = testEngine.Insert(&BitSetStruct{Flags: convert.NewBitSet(8)})
+	assert.Error(t, err)
+	_, err = testEngine.Insert(&BitSetStruct{Flags: flags, Mask: []byte{0xff}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Validator represents a bean which validates itself, Validate will be invoked before
+// the bean is inserted or updated with AllCols, the operation will be aborted if an error is
+// returned. The partial updates are not validated since the bean holds only the changed fields
+type Validator interface {
+	Validate(ctx context.Context) error
+}
+
+// ValidateFunc represents an external validation function which will be invoked before
+// a struct bean is inserted or updated with AllCols, i.e. a wrapper of go-playground/validator
+//
+//	validate := validator.New()
+//	engine.SetValidator(func(ctx context.Context, bean interface{}) error {
+//		return validate.StructCtx(ctx, bean)
+//	})
+type ValidateFunc func(ctx context.Context, bean interface{}) error
+
+// ErrValidation represents an error when a bean failed to be validated before Insert or Update
+type ErrValidation struct {
+	Bean interface{}
+	Err  error
+}
+
+func (err ErrValidation) Error() string {
+	return fmt.Sprintf("validate %T failed: %v", err.Bean, err.Err)
+}
+
+// Unwrap returns the error returned by the validator
+func (err ErrValidation) Unwrap() error {
+	return err.Err
+}
+
+// SetValidator sets the validation function which will be invoked before a struct bean
+// is inserted or updated with AllCols, it runs after the bean's own Validate method
+func (engine *Engine) SetValidator(validate ValidateFunc) {
+	engine.validate = validate
+}
+
+// SetValidator sets the validation function for all the engines of the group
+func (eg *EngineGroup) SetValidator(validate ValidateFunc) {
+	eg.Engine.SetValidator(validate)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetValidator(validate)
+	}
+}
+
+// validate validates the bean by its Validate method and the engine's validation function
+func (session *Session) validate(bean interface{}) error {
+	if validator, ok := bean.(Validator); ok {
+		if err := validator.Validate(session.ctx); err != nil {
+			return ErrValidation{Bean: bean, Err: err}
+		}
+	}
+	if session.engine.validate == nil {
+		return nil
+	}
+	if v := reflect.Indirect(reflect.ValueOf(bean)); v.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := session.engine.validate(session.ctx, bean); err != nil {
+		return ErrValidation{Bean: bean, Err: err}
+	}
+	return nil
+}
+
+// validateElem validates an element of the inserted slice, the elements of []T are validated
+// by their addresses so that the Validate methods with pointer receivers are invoked
+func (session *Session) validateElem(elem reflect.Value) error {
+	if elem.Kind() == reflect.Struct && elem.CanAddr() {
+		return session.validate(elem.Addr().Interface())
+	}
+	return session.validate(elem.Interface())
+}